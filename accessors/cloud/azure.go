@@ -0,0 +1,267 @@
+package cloud
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	"www.velocidex.com/golang/velociraptor/accessors"
+	"www.velocidex.com/golang/velociraptor/constants"
+	"www.velocidex.com/golang/velociraptor/vql/networking"
+	"www.velocidex.com/golang/vfilter"
+)
+
+// We talk to the Blob service REST API directly using a Shared Access
+// Signature so we do not need the Azure SDK.
+// https://docs.microsoft.com/en-us/rest/api/storageservices/blob-service-rest-api
+
+const (
+	AZURE_API_VERSION = "2020-04-08"
+)
+
+type AzureConfig struct {
+	Account  string `vfilter:"optional,field=account,doc=The storage account name"`
+	SasToken string `vfilter:"optional,field=sas_token,doc=A Shared Access Signature token for the account or container"`
+	Endpoint string `vfilter:"optional,field=endpoint,doc=The blob service endpoint (default https://<account>.blob.core.windows.net)"`
+}
+
+type azureProperties struct {
+	LastModified  string `xml:"Last-Modified"`
+	ContentLength int64  `xml:"Content-Length"`
+	ContentType   string `xml:"Content-Type"`
+	Etag          string `xml:"Etag"`
+	AccessTier    string `xml:"AccessTier"`
+}
+
+type azureEnumerationResults struct {
+	Containers []struct {
+		Name       string          `xml:"Name"`
+		Properties azureProperties `xml:"Properties"`
+	} `xml:"Containers>Container"`
+	Blobs []struct {
+		Name       string          `xml:"Name"`
+		Properties azureProperties `xml:"Properties"`
+	} `xml:"Blobs>Blob"`
+	BlobPrefixes []struct {
+		Name string `xml:"Name"`
+	} `xml:"Blobs>BlobPrefix"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+type AzureStore struct {
+	client   *http.Client
+	endpoint string
+	sas      url.Values
+}
+
+func (self *AzureStore) request(ctx context.Context, method string,
+	path []string, params url.Values, headers map[string]string) (
+	*http.Response, error) {
+
+	escaped := make([]string, 0, len(path))
+	for _, component := range path {
+		escaped = append(escaped, url.PathEscape(component))
+	}
+
+	query := url.Values{}
+	for k, v := range self.sas {
+		query[k] = v
+	}
+	for k, v := range params {
+		query[k] = v
+	}
+
+	url := self.endpoint + "/" + strings.Join(escaped, "/") +
+		"?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("x-ms-version", AZURE_API_VERSION)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := self.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusPartialContent:
+		return resp, nil
+
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, fmt.Errorf("%v: %w", strings.Join(path, "/"), os.ErrNotExist)
+	}
+
+	// Errors are returned as an XML document with a message.
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+
+	return nil, fmt.Errorf("Azure: %v: %v", resp.Status, string(body))
+}
+
+// Run a list operation following the NextMarker to get all pages.
+func (self *AzureStore) list(ctx context.Context, path []string,
+	params url.Values, cb func(results *azureEnumerationResults)) error {
+
+	for {
+		resp, err := self.request(ctx, "GET", path, params, nil)
+		if err != nil {
+			return err
+		}
+
+		results := &azureEnumerationResults{}
+		err = xml.NewDecoder(resp.Body).Decode(results)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		cb(results)
+
+		if results.NextMarker == "" {
+			return nil
+		}
+		params.Set("marker", results.NextMarker)
+	}
+}
+
+func parseAzureTime(value string) time.Time {
+	result, _ := time.Parse(time.RFC1123, value)
+	return result
+}
+
+func (self *AzureStore) ListBuckets(ctx context.Context) ([]*Object, error) {
+	var result []*Object
+
+	err := self.list(ctx, nil, url.Values{"comp": {"list"}},
+		func(results *azureEnumerationResults) {
+			for _, container := range results.Containers {
+				result = append(result, &Object{
+					Key:   container.Name,
+					IsDir: true,
+					Mtime: parseAzureTime(container.Properties.LastModified),
+				})
+			}
+		})
+
+	return result, err
+}
+
+func (self *AzureStore) List(
+	ctx context.Context, bucket, prefix string) ([]*Object, error) {
+	var result []*Object
+
+	err := self.list(ctx, []string{bucket}, url.Values{
+		"restype":   {"container"},
+		"comp":      {"list"},
+		"prefix":    {prefix},
+		"delimiter": {"/"},
+	}, func(results *azureEnumerationResults) {
+		for _, blob_prefix := range results.BlobPrefixes {
+			result = append(result, &Object{
+				Key:   blob_prefix.Name,
+				IsDir: true,
+			})
+		}
+
+		for _, blob := range results.Blobs {
+			result = append(result, &Object{
+				Key:   blob.Name,
+				Size:  blob.Properties.ContentLength,
+				Mtime: parseAzureTime(blob.Properties.LastModified),
+				Data: ordereddict.NewDict().
+					Set("ETag", blob.Properties.Etag).
+					Set("ContentType", blob.Properties.ContentType).
+					Set("AccessTier", blob.Properties.AccessTier),
+			})
+		}
+	})
+
+	return result, err
+}
+
+func (self *AzureStore) Stat(
+	ctx context.Context, bucket, key string) (*Object, error) {
+	resp, err := self.request(ctx, "HEAD",
+		append([]string{bucket}, strings.Split(key, "/")...), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	size, _ := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+
+	return &Object{
+		Key:   key,
+		Size:  size,
+		Mtime: parseAzureTime(resp.Header.Get("Last-Modified")),
+		Data: ordereddict.NewDict().
+			Set("ETag", resp.Header.Get("ETag")).
+			Set("ContentType", resp.Header.Get("Content-Type")).
+			Set("AccessTier", resp.Header.Get("x-ms-access-tier")),
+	}, nil
+}
+
+func (self *AzureStore) Open(ctx context.Context,
+	bucket, key string, offset int64) (io.ReadCloser, error) {
+	resp, err := self.request(ctx, "GET",
+		append([]string{bucket}, strings.Split(key, "/")...), nil,
+		map[string]string{"Range": fmt.Sprintf("bytes=%d-", offset)})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func NewAzureStore(ctx context.Context, scope vfilter.Scope) (ObjectStore, error) {
+	config := &AzureConfig{}
+	err := accessors.GetAccessorConfig(ctx, scope, constants.AZURE_CREDENTIALS, config)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		if config.Account == "" {
+			return nil, errors.New(
+				"AZURE_CREDENTIALS must specify the account or endpoint")
+		}
+		endpoint = fmt.Sprintf("https://%s.blob.core.windows.net",
+			config.Account)
+	}
+
+	sas, err := url.ParseQuery(strings.TrimPrefix(config.SasToken, "?"))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid sas_token: %w", err)
+	}
+
+	return &AzureStore{
+		client: &http.Client{
+			Transport: &http.Transport{
+				Proxy: networking.GetProxy(),
+			},
+		},
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		sas:      sas,
+	}, nil
+}
+
+func init() {
+	accessors.Register("azure", NewObjectStoreAccessor(NewAzureStore),
+		`Access blobs in Azure Storage containers. Credentials are set in the AZURE_CREDENTIALS variable.`)
+}
@@ -0,0 +1,213 @@
+package cloud
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"www.velocidex.com/golang/velociraptor/accessors"
+	"www.velocidex.com/golang/velociraptor/constants"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+)
+
+var (
+	testBlobs = map[string]string{
+		"AWSLogs/123/CloudTrail/2022/01/a.json.gz": "0123456789",
+		"AWSLogs/123/CloudTrail/2022/01/b.json.gz": "Hello world",
+		"AWSLogs/readme.txt":                       "readme",
+	}
+	testMtime = time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+)
+
+// A minimal implementation of the Blob service with a single
+// container "logs".
+func fakeBlobService(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		assert.Equal(t, "secret", query.Get("sig"))
+
+		path := strings.TrimPrefix(r.URL.Path, "/")
+		if path == "" && query.Get("comp") == "list" {
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="utf-8"?>
+<EnumerationResults><Containers><Container><Name>logs</Name>
+<Properties><Last-Modified>%s</Last-Modified></Properties>
+</Container></Containers><NextMarker/></EnumerationResults>`,
+				testMtime.Format(http.TimeFormat))
+			return
+		}
+
+		components := strings.SplitN(path, "/", 2)
+		if components[0] != "logs" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if len(components) == 1 {
+			listBlobs(w, query.Get("prefix"), query.Get("marker"))
+			return
+		}
+
+		data, pres := testBlobs[components[1]]
+		if !pres {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Last-Modified", testMtime.Format(http.TimeFormat))
+		w.Header().Set("ETag", "0x8D9")
+		http.ServeContent(w, r, "", testMtime, strings.NewReader(data))
+	}
+}
+
+// Return the blobs and prefixes directly under the prefix. Each page
+// has a single entry to exercise the paging.
+func listBlobs(w http.ResponseWriter, prefix, marker string) {
+	entries := make(map[string]bool)
+	for name := range testBlobs {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(name, prefix)
+		idx := strings.Index(rest, "/")
+		if idx >= 0 {
+			entries[prefix+rest[:idx+1]] = true
+		} else {
+			entries[name] = false
+		}
+	}
+
+	var names []string
+	for name := range entries {
+		if name > marker {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="utf-8"?><EnumerationResults><Blobs>`)
+	next_marker := ""
+	if len(names) > 0 {
+		name := names[0]
+		if entries[name] {
+			fmt.Fprintf(w, `<BlobPrefix><Name>%s</Name></BlobPrefix>`, name)
+		} else {
+			fmt.Fprintf(w, `<Blob><Name>%s</Name><Properties>
+<Last-Modified>%s</Last-Modified><Content-Length>%d</Content-Length>
+</Properties></Blob>`, name, testMtime.Format(http.TimeFormat),
+				len(testBlobs[name]))
+		}
+		if len(names) > 1 {
+			next_marker = name
+		}
+	}
+	fmt.Fprintf(w, `</Blobs><NextMarker>%s</NextMarker></EnumerationResults>`,
+		next_marker)
+}
+
+func getAzureAccessor(t *testing.T) accessors.FileSystemAccessor {
+	server := httptest.NewServer(fakeBlobService(t))
+	t.Cleanup(server.Close)
+
+	scope := vql_subsystem.MakeScope().AppendVars(ordereddict.NewDict().
+		Set(vql_subsystem.ACL_MANAGER_VAR, vql_subsystem.NullACLManager{}).
+		Set(constants.AZURE_CREDENTIALS, ordereddict.NewDict().
+			Set("endpoint", server.URL).
+			Set("sas_token", "?sv=2020-08-04&sig=secret")))
+	t.Cleanup(func() { scope.Close() })
+
+	accessor, err := accessors.GetAccessor("azure", scope)
+	require.NoError(t, err)
+
+	return accessor
+}
+
+func readDirNames(t *testing.T,
+	accessor accessors.FileSystemAccessor, path string) []string {
+	children, err := accessor.ReadDir(path)
+	require.NoError(t, err)
+
+	var result []string
+	for _, child := range children {
+		name := child.Name()
+		if child.IsDir() {
+			name += "/"
+		}
+		result = append(result, name)
+	}
+	return result
+}
+
+func TestAzureReadDir(t *testing.T) {
+	accessor := getAzureAccessor(t)
+
+	assert.Equal(t, []string{"logs/"}, readDirNames(t, accessor, "/"))
+	assert.Equal(t, []string{"AWSLogs/"}, readDirNames(t, accessor, "/logs"))
+	assert.Equal(t, []string{"123/", "readme.txt"},
+		readDirNames(t, accessor, "/logs/AWSLogs"))
+	assert.Equal(t, []string{"a.json.gz", "b.json.gz"},
+		readDirNames(t, accessor, "/logs/AWSLogs/123/CloudTrail/2022/01"))
+
+	children, err := accessor.ReadDir("/logs/AWSLogs")
+	require.NoError(t, err)
+	assert.Equal(t, "/logs/AWSLogs/readme.txt",
+		children[1].OSPath().String())
+	assert.Equal(t, int64(6), children[1].Size())
+	assert.Equal(t, testMtime, children[1].ModTime().UTC())
+}
+
+func TestAzureLstat(t *testing.T) {
+	accessor := getAzureAccessor(t)
+
+	stat, err := accessor.Lstat("/logs/AWSLogs/readme.txt")
+	require.NoError(t, err)
+	assert.False(t, stat.IsDir())
+	assert.Equal(t, int64(6), stat.Size())
+
+	// Directories only exist as common prefixes.
+	stat, err = accessor.Lstat("/logs/AWSLogs/123")
+	require.NoError(t, err)
+	assert.True(t, stat.IsDir())
+
+	_, err = accessor.Lstat("/logs/AWSLogs/missing")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestAzureOpen(t *testing.T) {
+	accessor := getAzureAccessor(t)
+
+	fd, err := accessor.Open("/logs/AWSLogs/123/CloudTrail/2022/01/b.json.gz")
+	require.NoError(t, err)
+	defer fd.Close()
+
+	data, err := ioutil.ReadAll(fd)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello world", string(data))
+
+	// Seeking reopens the blob at the new offset.
+	_, err = fd.Seek(6, os.SEEK_SET)
+	require.NoError(t, err)
+
+	buf := make([]byte, 3)
+	n, err := fd.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "wor", string(buf[:n]))
+
+	_, err = fd.Seek(-2, os.SEEK_END)
+	require.NoError(t, err)
+
+	data, err = ioutil.ReadAll(fd)
+	require.NoError(t, err)
+	assert.Equal(t, "ld", string(data))
+
+	_, err = accessor.Open("/logs/AWSLogs/missing")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,12 @@
+// Accessors for cloud object stores (S3, GCS and Azure Blob Storage).
+//
+// Each accessor presents the object store as a filesystem: the top
+// level directories are the buckets (or containers) and object keys
+// are split on / into directories. Credentials are provided by
+// setting a scope variable (e.g. S3_CREDENTIALS) to a dict of
+// options.
+//
+// The S3 and GCS accessors depend on the vendor SDKs and are only
+// built with the extras tag.
+
+package cloud
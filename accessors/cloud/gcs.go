@@ -0,0 +1,148 @@
+//+build extras
+
+package cloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"cloud.google.com/go/storage"
+	"github.com/Velocidex/ordereddict"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+	"www.velocidex.com/golang/velociraptor/accessors"
+	"www.velocidex.com/golang/velociraptor/constants"
+	"www.velocidex.com/golang/vfilter"
+)
+
+type GCSConfig struct {
+	Project     string `vfilter:"optional,field=project,doc=The project to list buckets from"`
+	Credentials string `vfilter:"optional,field=credentials,doc=The service account credentials (JSON) to use"`
+}
+
+type GCSStore struct {
+	client  *storage.Client
+	project string
+}
+
+func (self *GCSStore) ListBuckets(ctx context.Context) ([]*Object, error) {
+	if self.project == "" {
+		return nil, errors.New("Listing buckets requires the project to be set")
+	}
+
+	var result []*Object
+	it := self.client.Buckets(ctx, self.project)
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, &Object{
+			Key:   attrs.Name,
+			IsDir: true,
+			Mtime: attrs.Created,
+			Data: ordereddict.NewDict().
+				Set("Location", attrs.Location).
+				Set("StorageClass", attrs.StorageClass),
+		})
+	}
+}
+
+func (self *GCSStore) List(
+	ctx context.Context, bucket, prefix string) ([]*Object, error) {
+	var result []*Object
+
+	it := self.client.Bucket(bucket).Objects(ctx, &storage.Query{
+		Prefix:    prefix,
+		Delimiter: "/",
+	})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// Common prefixes only have the Prefix set.
+		if attrs.Prefix != "" {
+			result = append(result, &Object{
+				Key:   attrs.Prefix,
+				IsDir: true,
+			})
+			continue
+		}
+
+		result = append(result, objectFromGCSAttrs(attrs))
+	}
+}
+
+func objectFromGCSAttrs(attrs *storage.ObjectAttrs) *Object {
+	return &Object{
+		Key:   attrs.Name,
+		Size:  attrs.Size,
+		Mtime: attrs.Updated,
+		Data: ordereddict.NewDict().
+			Set("ETag", attrs.Etag).
+			Set("ContentType", attrs.ContentType).
+			Set("StorageClass", attrs.StorageClass),
+	}
+}
+
+func (self *GCSStore) Stat(
+	ctx context.Context, bucket, key string) (*Object, error) {
+	attrs, err := self.client.Bucket(bucket).Object(key).Attrs(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, fmt.Errorf("%v/%v: %w", bucket, key, os.ErrNotExist)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return objectFromGCSAttrs(attrs), nil
+}
+
+func (self *GCSStore) Open(ctx context.Context,
+	bucket, key string, offset int64) (io.ReadCloser, error) {
+	return self.client.Bucket(bucket).Object(key).NewRangeReader(
+		ctx, offset, -1)
+}
+
+func NewGCSStore(ctx context.Context, scope vfilter.Scope) (ObjectStore, error) {
+	config := &GCSConfig{}
+	err := accessors.GetAccessorConfig(ctx, scope, constants.GCS_CREDENTIALS, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without explicit credentials use the application default
+	// credentials.
+	var options []option.ClientOption
+	if config.Credentials != "" {
+		options = append(options,
+			option.WithCredentialsJSON([]byte(config.Credentials)))
+	}
+
+	client, err := storage.NewClient(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GCSStore{
+		client:  client,
+		project: config.Project,
+	}, nil
+}
+
+func init() {
+	accessors.Register("gcs", NewObjectStoreAccessor(NewGCSStore),
+		`Access objects in Google Cloud Storage buckets. Credentials are set in the GCS_CREDENTIALS variable.`)
+}
@@ -0,0 +1,91 @@
+package cloud
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+// Open the object for reading from the offset to the end.
+type RangeOpener func(offset int64) (io.ReadCloser, error)
+
+// A ReadSeekCloser over an object store. Object stores only support
+// reading ranges of objects so we stream from the current offset and
+// reopen the object when the caller seeks.
+type RangeReader struct {
+	open   RangeOpener
+	size   int64
+	offset int64
+	body   io.ReadCloser
+}
+
+func (self *RangeReader) Read(buf []byte) (int, error) {
+	if self.offset >= self.size {
+		return 0, io.EOF
+	}
+
+	if self.body == nil {
+		body, err := self.open(self.offset)
+		if err != nil {
+			return 0, err
+		}
+		self.body = body
+	}
+
+	n, err := self.body.Read(buf)
+	self.offset += int64(n)
+
+	if errors.Is(err, io.EOF) {
+		self.body.Close()
+		self.body = nil
+
+		// The object may be truncated.
+		if n == 0 || self.offset >= self.size {
+			return n, io.EOF
+		}
+		return n, nil
+	}
+
+	return n, err
+}
+
+func (self *RangeReader) Seek(offset int64, whence int) (int64, error) {
+	new_offset := offset
+	switch whence {
+	case os.SEEK_SET:
+	case os.SEEK_CUR:
+		new_offset += self.offset
+	case os.SEEK_END:
+		new_offset += self.size
+	default:
+		return 0, errors.New("Invalid whence")
+	}
+
+	if new_offset < 0 {
+		return 0, errors.New("Negative seek")
+	}
+
+	if new_offset != self.offset && self.body != nil {
+		self.body.Close()
+		self.body = nil
+	}
+	self.offset = new_offset
+
+	return self.offset, nil
+}
+
+func (self *RangeReader) Close() error {
+	if self.body != nil {
+		err := self.body.Close()
+		self.body = nil
+		return err
+	}
+	return nil
+}
+
+func NewRangeReader(size int64, open RangeOpener) *RangeReader {
+	return &RangeReader{
+		open: open,
+		size: size,
+	}
+}
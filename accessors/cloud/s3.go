@@ -0,0 +1,173 @@
+//+build extras
+
+package cloud
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/Velocidex/ordereddict"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"www.velocidex.com/golang/velociraptor/accessors"
+	"www.velocidex.com/golang/velociraptor/constants"
+	"www.velocidex.com/golang/velociraptor/vql/networking"
+	"www.velocidex.com/golang/vfilter"
+)
+
+// The options are the same as for upload_s3()
+type S3Config struct {
+	Region            string `vfilter:"optional,field=region,doc=The region the buckets are in"`
+	CredentialsKey    string `vfilter:"optional,field=credentialskey,doc=The AWS key credentials to use"`
+	CredentialsSecret string `vfilter:"optional,field=credentialssecret,doc=The AWS secret credentials to use"`
+	SessionToken      string `vfilter:"optional,field=sessiontoken,doc=The AWS session token to use with temporary credentials"`
+	Endpoint          string `vfilter:"optional,field=endpoint,doc=The Endpoint to use"`
+	NoVerifyCert      bool   `vfilter:"optional,field=noverifycert,doc=Skip TLS Verification"`
+}
+
+type S3Store struct {
+	client *s3.S3
+}
+
+func (self *S3Store) ListBuckets(ctx context.Context) ([]*Object, error) {
+	output, err := self.client.ListBucketsWithContext(ctx, &s3.ListBucketsInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*Object
+	for _, bucket := range output.Buckets {
+		result = append(result, &Object{
+			Key:   aws.StringValue(bucket.Name),
+			IsDir: true,
+			Mtime: aws.TimeValue(bucket.CreationDate),
+		})
+	}
+	return result, nil
+}
+
+func (self *S3Store) List(
+	ctx context.Context, bucket, prefix string) ([]*Object, error) {
+	var result []*Object
+
+	err := self.client.ListObjectsV2PagesWithContext(ctx,
+		&s3.ListObjectsV2Input{
+			Bucket:    aws.String(bucket),
+			Prefix:    aws.String(prefix),
+			Delimiter: aws.String("/"),
+		}, func(page *s3.ListObjectsV2Output, last bool) bool {
+			for _, common_prefix := range page.CommonPrefixes {
+				result = append(result, &Object{
+					Key:   aws.StringValue(common_prefix.Prefix),
+					IsDir: true,
+				})
+			}
+
+			for _, object := range page.Contents {
+				result = append(result, &Object{
+					Key:   aws.StringValue(object.Key),
+					Size:  aws.Int64Value(object.Size),
+					Mtime: aws.TimeValue(object.LastModified),
+					Data: ordereddict.NewDict().
+						Set("ETag", aws.StringValue(object.ETag)).
+						Set("StorageClass",
+							aws.StringValue(object.StorageClass)),
+				})
+			}
+			return true
+		})
+
+	return result, err
+}
+
+func (self *S3Store) Stat(
+	ctx context.Context, bucket, key string) (*Object, error) {
+	output, err := self.client.HeadObjectWithContext(ctx,
+		&s3.HeadObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+	if err != nil {
+		req_err, ok := err.(awserr.RequestFailure)
+		if ok && req_err.StatusCode() == http.StatusNotFound {
+			return nil, fmt.Errorf("%v/%v: %w", bucket, key, os.ErrNotExist)
+		}
+		return nil, err
+	}
+
+	return &Object{
+		Key:   key,
+		Size:  aws.Int64Value(output.ContentLength),
+		Mtime: aws.TimeValue(output.LastModified),
+		Data: ordereddict.NewDict().
+			Set("ETag", aws.StringValue(output.ETag)).
+			Set("ContentType", aws.StringValue(output.ContentType)).
+			Set("StorageClass", aws.StringValue(output.StorageClass)),
+	}, nil
+}
+
+func (self *S3Store) Open(ctx context.Context,
+	bucket, key string, offset int64) (io.ReadCloser, error) {
+	output, err := self.client.GetObjectWithContext(ctx,
+		&s3.GetObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+			Range:  aws.String(fmt.Sprintf("bytes=%d-", offset)),
+		})
+	if err != nil {
+		return nil, err
+	}
+	return output.Body, nil
+}
+
+func NewS3Store(ctx context.Context, scope vfilter.Scope) (ObjectStore, error) {
+	config := &S3Config{}
+	err := accessors.GetAccessorConfig(ctx, scope, constants.S3_CREDENTIALS, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without explicit credentials the SDK uses the usual
+	// environment variables, shared config or instance role.
+	conf := aws.NewConfig()
+	if config.Region != "" {
+		conf = conf.WithRegion(config.Region)
+	}
+
+	if config.CredentialsKey != "" && config.CredentialsSecret != "" {
+		conf = conf.WithCredentials(credentials.NewStaticCredentials(
+			config.CredentialsKey, config.CredentialsSecret,
+			config.SessionToken))
+	}
+
+	if config.Endpoint != "" {
+		conf = conf.WithEndpoint(config.Endpoint).WithS3ForcePathStyle(true)
+		if config.NoVerifyCert {
+			conf = conf.WithHTTPClient(&http.Client{
+				Transport: &http.Transport{
+					Proxy:           networking.GetProxy(),
+					TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+				},
+			})
+		}
+	}
+
+	sess, err := session.NewSession(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &S3Store{client: s3.New(sess)}, nil
+}
+
+func init() {
+	accessors.Register("s3", NewObjectStoreAccessor(NewS3Store),
+		`Access objects in S3 buckets. Credentials are set in the S3_CREDENTIALS variable.`)
+}
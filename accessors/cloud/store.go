@@ -0,0 +1,246 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	"www.velocidex.com/golang/velociraptor/accessors"
+	"www.velocidex.com/golang/velociraptor/acls"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/vfilter"
+)
+
+// An object (or common prefix) in a bucket.
+type Object struct {
+	// The full key of the object within the bucket (or the name of
+	// the bucket).
+	Key   string
+	IsDir bool
+	Size  int64
+	Mtime time.Time
+
+	// Provider specific metadata (e.g. ETag, StorageClass).
+	Data *ordereddict.Dict
+}
+
+// The operations needed from an object store to present it as a
+// filesystem.
+type ObjectStore interface {
+	ListBuckets(ctx context.Context) ([]*Object, error)
+
+	// List the objects and common prefixes directly under the prefix
+	// (i.e. using / as a delimiter).
+	List(ctx context.Context, bucket, prefix string) ([]*Object, error)
+
+	// Stat an object. Returns an error wrapping os.ErrNotExist if
+	// the object does not exist.
+	Stat(ctx context.Context, bucket, key string) (*Object, error)
+
+	Open(ctx context.Context, bucket, key string, offset int64) (
+		io.ReadCloser, error)
+}
+
+// Create an ObjectStore from the scope (usually by reading
+// credentials from a scope variable).
+type ObjectStoreFactory func(
+	ctx context.Context, scope vfilter.Scope) (ObjectStore, error)
+
+type ObjectStoreAccessor struct {
+	factory ObjectStoreFactory
+
+	ctx   context.Context
+	store ObjectStore
+	root  *accessors.OSPath
+}
+
+func (self *ObjectStoreAccessor) New(
+	scope vfilter.Scope) (accessors.FileSystemAccessor, error) {
+
+	err := vql_subsystem.CheckAccess(scope, acls.FILESYSTEM_READ)
+	if err != nil {
+		return nil, err
+	}
+
+	// Abort outstanding requests when the query is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	err = vql_subsystem.GetRootScope(scope).AddDestructor(cancel)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	store, err := self.factory(ctx, scope)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return &ObjectStoreAccessor{
+		factory: self.factory,
+		ctx:     ctx,
+		store:   store,
+		root:    self.root,
+	}, nil
+}
+
+func (self *ObjectStoreAccessor) ParsePath(
+	path string) (*accessors.OSPath, error) {
+	return self.root.Parse(path)
+}
+
+func (self *ObjectStoreAccessor) fileInfo(
+	full_path *accessors.OSPath, object *Object) accessors.FileInfo {
+	return &accessors.VirtualFileInfo{
+		Path:   full_path,
+		IsDir_: object.IsDir,
+		Size_:  object.Size,
+		Mtime_: object.Mtime,
+		Data_:  object.Data,
+	}
+}
+
+func splitPath(full_path *accessors.OSPath) (bucket, key string) {
+	if len(full_path.Components) == 0 {
+		return "", ""
+	}
+	return full_path.Components[0], strings.Join(full_path.Components[1:], "/")
+}
+
+func (self *ObjectStoreAccessor) ReadDir(
+	path string) ([]accessors.FileInfo, error) {
+	full_path, err := self.ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return self.ReadDirWithOSPath(full_path)
+}
+
+func (self *ObjectStoreAccessor) ReadDirWithOSPath(
+	full_path *accessors.OSPath) ([]accessors.FileInfo, error) {
+	var result []accessors.FileInfo
+
+	// The top level lists the buckets.
+	if len(full_path.Components) == 0 {
+		buckets, err := self.store.ListBuckets(self.ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, bucket := range buckets {
+			result = append(result,
+				self.fileInfo(full_path.Append(bucket.Key), bucket))
+		}
+		return result, nil
+	}
+
+	bucket, prefix := splitPath(full_path)
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	objects, err := self.store.List(self.ctx, bucket, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, object := range objects {
+		name := strings.TrimSuffix(
+			strings.TrimPrefix(object.Key, prefix), "/")
+
+		// Skip placeholder objects created by some tools to
+		// represent the directory itself.
+		if name == "" {
+			continue
+		}
+
+		result = append(result,
+			self.fileInfo(full_path.Append(name), object))
+	}
+
+	return result, nil
+}
+
+func (self *ObjectStoreAccessor) Lstat(
+	filename string) (accessors.FileInfo, error) {
+	full_path, err := self.ParsePath(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return self.LstatWithOSPath(full_path)
+}
+
+func (self *ObjectStoreAccessor) LstatWithOSPath(
+	full_path *accessors.OSPath) (accessors.FileInfo, error) {
+
+	// Buckets and the root are directories.
+	if len(full_path.Components) <= 1 {
+		return self.fileInfo(full_path, &Object{IsDir: true}), nil
+	}
+
+	bucket, key := splitPath(full_path)
+	object, err := self.store.Stat(self.ctx, bucket, key)
+	if err == nil {
+		return self.fileInfo(full_path, object), nil
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	// Object stores do not really have directories - a directory
+	// exists if there are any objects with the prefix.
+	objects, err := self.store.List(self.ctx, bucket, key+"/")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("%v: %w", full_path.String(), os.ErrNotExist)
+	}
+
+	return self.fileInfo(full_path, &Object{IsDir: true}), nil
+}
+
+func (self *ObjectStoreAccessor) Open(
+	path string) (accessors.ReadSeekCloser, error) {
+	full_path, err := self.ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return self.OpenWithOSPath(full_path)
+}
+
+func (self *ObjectStoreAccessor) OpenWithOSPath(
+	full_path *accessors.OSPath) (accessors.ReadSeekCloser, error) {
+
+	if len(full_path.Components) <= 1 {
+		return nil, errors.New("Can not open a bucket")
+	}
+
+	bucket, key := splitPath(full_path)
+	object, err := self.store.Stat(self.ctx, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRangeReader(object.Size, func(offset int64) (io.ReadCloser, error) {
+		return self.store.Open(self.ctx, bucket, key, offset)
+	}), nil
+}
+
+func NewObjectStoreAccessor(factory ObjectStoreFactory) *ObjectStoreAccessor {
+	root_path, _ := accessors.NewLinuxOSPath("")
+	return &ObjectStoreAccessor{
+		factory: factory,
+		root:    root_path,
+	}
+}
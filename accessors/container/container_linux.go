@@ -0,0 +1,367 @@
+// +build linux
+
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Velocidex/ordereddict"
+	"www.velocidex.com/golang/velociraptor/accessors"
+	"www.velocidex.com/golang/velociraptor/accessors/file"
+	"www.velocidex.com/golang/velociraptor/acls"
+	"www.velocidex.com/golang/velociraptor/utils"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/vfilter"
+	"www.velocidex.com/golang/vfilter/arg_parser"
+)
+
+type ContainerFileSystemAccessor struct {
+	root       *accessors.OSPath
+	containers []*ContainerInfo
+}
+
+func (self *ContainerFileSystemAccessor) New(
+	scope vfilter.Scope) (accessors.FileSystemAccessor, error) {
+
+	err := vql_subsystem.CheckAccess(scope, acls.FILESYSTEM_READ)
+	if err != nil {
+		return nil, err
+	}
+
+	root_path, _ := accessors.NewLinuxOSPath("")
+	result := &ContainerFileSystemAccessor{root: root_path}
+
+	// Only running containers have a root filesystem we can reach.
+	for _, info := range GetContainers("/") {
+		if info.Running {
+			result.containers = append(result.containers, info)
+		}
+	}
+
+	return result, nil
+}
+
+func (self *ContainerFileSystemAccessor) ParsePath(
+	path string) (*accessors.OSPath, error) {
+	return self.root.Parse(path)
+}
+
+// Containers may be addressed by ID or name.
+func (self *ContainerFileSystemAccessor) getContainer(
+	name string) (*ContainerInfo, error) {
+	for _, info := range self.containers {
+		if info.ID == name || info.Name == name {
+			return info, nil
+		}
+	}
+	return nil, fmt.Errorf("Container %v not found: %w", name, os.ErrNotExist)
+}
+
+func (self *ContainerFileSystemAccessor) containerFileInfo(
+	info *ContainerInfo) accessors.FileInfo {
+	return &accessors.VirtualFileInfo{
+		IsDir_: true,
+		Path:   self.root.Append(info.ID),
+		Data_:  info.ToDict(),
+		Mtime_: info.Created,
+		Btime_: info.Created,
+	}
+}
+
+// Find the path on the host corresponding to the path in the
+// container.
+func (self *ContainerFileSystemAccessor) getHostPath(
+	full_path *accessors.OSPath, follow_last bool) (string, error) {
+	if len(full_path.Components) == 0 {
+		return "", errors.New("Path is not inside a container")
+	}
+
+	info, err := self.getContainer(full_path.Components[0])
+	if err != nil {
+		return "", err
+	}
+
+	root := fmt.Sprintf("/proc/%d/root", info.Pid)
+	return resolveInRoot(root, full_path.Components[1:], follow_last)
+}
+
+func (self *ContainerFileSystemAccessor) ReadDir(
+	path string) ([]accessors.FileInfo, error) {
+	full_path, err := self.ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return self.ReadDirWithOSPath(full_path)
+}
+
+func (self *ContainerFileSystemAccessor) ReadDirWithOSPath(
+	full_path *accessors.OSPath) ([]accessors.FileInfo, error) {
+
+	// The top level lists the containers.
+	if len(full_path.Components) == 0 {
+		var result []accessors.FileInfo
+		for _, info := range self.containers {
+			result = append(result, self.containerFileInfo(info))
+		}
+		return result, nil
+	}
+
+	host_path, err := self.getHostPath(full_path, true)
+	if err != nil {
+		return nil, err
+	}
+
+	lstat, err := os.Lstat(host_path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !lstat.IsDir() {
+		return nil, nil
+	}
+
+	files, err := utils.ReadDir(host_path)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []accessors.FileInfo
+	for _, f := range files {
+		result = append(result,
+			file.NewOSFileInfo(f, full_path.Append(f.Name())))
+	}
+
+	return result, nil
+}
+
+func (self *ContainerFileSystemAccessor) Lstat(
+	filename string) (accessors.FileInfo, error) {
+	full_path, err := self.ParsePath(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return self.LstatWithOSPath(full_path)
+}
+
+func (self *ContainerFileSystemAccessor) LstatWithOSPath(
+	full_path *accessors.OSPath) (accessors.FileInfo, error) {
+
+	switch len(full_path.Components) {
+	case 0:
+		return &accessors.VirtualFileInfo{
+			IsDir_: true,
+			Path:   full_path.Copy(),
+		}, nil
+
+	case 1:
+		info, err := self.getContainer(full_path.Components[0])
+		if err != nil {
+			return nil, err
+		}
+		return self.containerFileInfo(info), nil
+	}
+
+	host_path, err := self.getHostPath(full_path, false)
+	if err != nil {
+		return nil, err
+	}
+
+	lstat, err := os.Lstat(host_path)
+	if err != nil {
+		return nil, err
+	}
+
+	return file.NewOSFileInfo(lstat, full_path.Copy()), nil
+}
+
+func (self *ContainerFileSystemAccessor) Open(
+	path string) (accessors.ReadSeekCloser, error) {
+	full_path, err := self.ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return self.OpenWithOSPath(full_path)
+}
+
+func (self *ContainerFileSystemAccessor) OpenWithOSPath(
+	full_path *accessors.OSPath) (accessors.ReadSeekCloser, error) {
+
+	host_path, err := self.getHostPath(full_path, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Same as the file accessor we do not allow reading devices.
+	stat, err := os.Stat(host_path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !stat.Mode().IsRegular() {
+		return nil, fmt.Errorf(
+			"Only regular files supported (not %v)", full_path.String())
+	}
+
+	return os.Open(host_path)
+}
+
+type ContainerProcessesPluginArgs struct {
+	Container string `vfilter:"optional,field=container,doc=Only show processes in this container (ID or name)."`
+}
+
+type ContainerProcessesPlugin struct{}
+
+func (self ContainerProcessesPlugin) Call(
+	ctx context.Context,
+	scope vfilter.Scope,
+	args *ordereddict.Dict) <-chan vfilter.Row {
+	output_chan := make(chan vfilter.Row)
+
+	go func() {
+		defer close(output_chan)
+
+		err := vql_subsystem.CheckAccess(scope, acls.MACHINE_STATE)
+		if err != nil {
+			scope.Log("container_processes: %s", err)
+			return
+		}
+
+		arg := &ContainerProcessesPluginArgs{}
+		err = arg_parser.ExtractArgsWithContext(ctx, scope, args, arg)
+		if err != nil {
+			scope.Log("container_processes: %s", err)
+			return
+		}
+
+		containers := make(map[string]*ContainerInfo)
+		for _, info := range GetContainers("/") {
+			containers[info.ID] = info
+		}
+
+		pids, err := utils.ReadDirNames("/proc")
+		if err != nil {
+			scope.Log("container_processes: %s", err)
+			return
+		}
+
+		for _, name := range pids {
+			pid, err := strconv.Atoi(name)
+			if err != nil {
+				continue
+			}
+
+			cgroup, err := ioutil.ReadFile(
+				filepath.Join("/proc", name, "cgroup"))
+			if err != nil {
+				continue
+			}
+
+			id := GetContainerIDFromCgroup(string(cgroup))
+			if id == "" {
+				continue
+			}
+
+			info, pres := containers[id]
+			if !pres {
+				// The process is in a container we do not know
+				// about (e.g. from an unsupported runtime).
+				info = &ContainerInfo{ID: id}
+			}
+
+			if arg.Container != "" &&
+				arg.Container != info.ID && arg.Container != info.Name {
+				continue
+			}
+
+			comm, _ := ioutil.ReadFile(filepath.Join("/proc", name, "comm"))
+
+			select {
+			case <-ctx.Done():
+				return
+
+			case output_chan <- ordereddict.NewDict().
+				Set("Pid", pid).
+				Set("ContainerPid", getNamespacePid(name)).
+				Set("Name", strings.TrimSpace(string(comm))).
+				Set("ContainerID", info.ID).
+				Set("ContainerName", info.Name).
+				Set("Image", info.Image).
+				Set("PodName", info.PodName).
+				Set("PodNamespace", info.PodNamespace):
+			}
+		}
+	}()
+
+	return output_chan
+}
+
+// The pid of the process as seen inside its pid namespace. The NSpid
+// line lists the pid in each nested namespace.
+func getNamespacePid(pid string) int {
+	status, err := ioutil.ReadFile(filepath.Join("/proc", pid, "status"))
+	if err != nil {
+		return 0
+	}
+
+	for _, line := range strings.Split(string(status), "\n") {
+		if strings.HasPrefix(line, "NSpid:") {
+			fields := strings.Fields(line)
+			ns_pid, _ := strconv.Atoi(fields[len(fields)-1])
+			return ns_pid
+		}
+	}
+	return 0
+}
+
+func (self ContainerProcessesPlugin) Info(
+	scope vfilter.Scope, type_map *vfilter.TypeMap) *vfilter.PluginInfo {
+	return &vfilter.PluginInfo{
+		Name: "container_processes",
+		Doc: "Map host processes to the containers (and Kubernetes pods) " +
+			"they run in.",
+		ArgType: type_map.AddType(scope, &ContainerProcessesPluginArgs{}),
+	}
+}
+
+func init() {
+	root_path, _ := accessors.NewLinuxOSPath("")
+	accessors.Register("container", &ContainerFileSystemAccessor{root: root_path},
+		`Access files inside running containers. Each container appears as a top level directory named after its ID.`)
+
+	vql_subsystem.RegisterPlugin(
+		&vfilter.GenericListPlugin{
+			PluginName: "containers",
+			Function: func(
+				ctx context.Context,
+				scope vfilter.Scope,
+				args *ordereddict.Dict) []vfilter.Row {
+				var result []vfilter.Row
+
+				err := vql_subsystem.CheckAccess(scope, acls.MACHINE_STATE)
+				if err != nil {
+					scope.Log("containers: %s", err)
+					return result
+				}
+
+				for _, info := range GetContainers("/") {
+					result = append(result, info.ToDict())
+				}
+				return result
+			},
+			Doc: "List the containers managed by Docker, containerd and " +
+				"CRI-O. Files within running containers may be accessed " +
+				"using the container accessor.",
+		})
+
+	vql_subsystem.RegisterPlugin(&ContainerProcessesPlugin{})
+}
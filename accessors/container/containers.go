@@ -0,0 +1,339 @@
+package container
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+)
+
+const (
+	// Same as the kernel's limit.
+	MAX_SYMLINKS = 40
+)
+
+var (
+	containerIdRegex = regexp.MustCompile("[0-9a-f]{64}")
+
+	// Where the container runtimes keep their state. The containerd
+	// directories hold one directory per namespace (e.g. moby for
+	// Docker and k8s.io for Kubernetes).
+	containerdTaskDirs = []string{
+		"run/containerd/io.containerd.runtime.v2.task",
+		"run/containerd/io.containerd.runtime.v1.linux",
+	}
+	crioContainersDir  = "run/containers/storage/overlay-containers"
+	dockerContainerDir = "var/lib/docker/containers"
+)
+
+type ContainerInfo struct {
+	ID        string
+	Name      string
+	Image     string
+	Runtime   string
+	Namespace string
+
+	// For Kubernetes either "container" or "sandbox" (the pause
+	// container holding the pod's namespaces).
+	Type string
+
+	PodName      string
+	PodNamespace string
+	PodUID       string
+
+	Pid     int
+	Running bool
+	Created time.Time
+
+	// The directory where the runtime keeps this container's state.
+	StateDir string
+}
+
+func (self *ContainerInfo) ToDict() *ordereddict.Dict {
+	return ordereddict.NewDict().
+		Set("ID", self.ID).
+		Set("Name", self.Name).
+		Set("Image", self.Image).
+		Set("Runtime", self.Runtime).
+		Set("Namespace", self.Namespace).
+		Set("Type", self.Type).
+		Set("PodName", self.PodName).
+		Set("PodNamespace", self.PodNamespace).
+		Set("PodUID", self.PodUID).
+		Set("Pid", self.Pid).
+		Set("Running", self.Running).
+		Set("Created", self.Created).
+		Set("StateDir", self.StateDir)
+}
+
+// The subset of the OCI runtime spec we care about.
+type ociConfig struct {
+	Annotations map[string]string `json:"annotations"`
+}
+
+// The subset of Docker's config.v2.json we care about.
+type dockerConfig struct {
+	ID      string    `json:"ID"`
+	Name    string    `json:"Name"`
+	Created time.Time `json:"Created"`
+	Config  struct {
+		Image string `json:"Image"`
+	} `json:"Config"`
+	State struct {
+		Running bool `json:"Running"`
+		Pid     int  `json:"Pid"`
+	} `json:"State"`
+}
+
+// Annotations that containerd's CRI plugin and CRI-O place in the
+// OCI spec of Kubernetes containers.
+var annotationMap = map[string]func(info *ContainerInfo, value string){
+	"io.kubernetes.cri.container-name":    func(i *ContainerInfo, v string) { i.Name = v },
+	"io.kubernetes.cri.image-name":        func(i *ContainerInfo, v string) { i.Image = v },
+	"io.kubernetes.cri.container-type":    func(i *ContainerInfo, v string) { i.Type = v },
+	"io.kubernetes.cri.sandbox-name":      func(i *ContainerInfo, v string) { i.PodName = v },
+	"io.kubernetes.cri.sandbox-namespace": func(i *ContainerInfo, v string) { i.PodNamespace = v },
+	"io.kubernetes.cri.sandbox-uid":       func(i *ContainerInfo, v string) { i.PodUID = v },
+	"io.kubernetes.container.name":        func(i *ContainerInfo, v string) { i.Name = v },
+	"io.kubernetes.cri-o.ImageName":       func(i *ContainerInfo, v string) { i.Image = v },
+	"io.kubernetes.cri-o.ContainerType":   func(i *ContainerInfo, v string) { i.Type = v },
+	"io.kubernetes.pod.name":              func(i *ContainerInfo, v string) { i.PodName = v },
+	"io.kubernetes.pod.namespace":         func(i *ContainerInfo, v string) { i.PodNamespace = v },
+	"io.kubernetes.pod.uid":               func(i *ContainerInfo, v string) { i.PodUID = v },
+}
+
+func readPidFile(filename string) int {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return 0
+	}
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	return pid
+}
+
+// Read an OCI bundle as used by containerd and CRI-O.
+func readBundle(dir, pid_file string, info *ContainerInfo) {
+	info.StateDir = dir
+	info.Pid = readPidFile(filepath.Join(dir, pid_file))
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return
+	}
+
+	config := &ociConfig{}
+	if json.Unmarshal(data, config) != nil {
+		return
+	}
+
+	for k, v := range config.Annotations {
+		setter, pres := annotationMap[k]
+		if pres {
+			setter(info, v)
+		}
+	}
+
+	stat, err := os.Stat(dir)
+	if err == nil {
+		info.Created = stat.ModTime()
+	}
+}
+
+// Enumerate the containers known to the container runtimes. The root
+// is the root of the host's filesystem (usually /).
+func GetContainers(root string) []*ContainerInfo {
+	var result []*ContainerInfo
+	by_id := make(map[string]*ContainerInfo)
+
+	add := func(info *ContainerInfo) {
+		existing, pres := by_id[info.ID]
+		if !pres {
+			by_id[info.ID] = info
+			result = append(result, info)
+			return
+		}
+
+		// Docker containers are also visible as containerd tasks
+		// but Docker has the more useful metadata.
+		if info.Name != "" {
+			existing.Name = info.Name
+		}
+		if info.Image != "" {
+			existing.Image = info.Image
+		}
+		if existing.Pid == 0 {
+			existing.Pid = info.Pid
+		}
+		if !info.Created.IsZero() {
+			existing.Created = info.Created
+		}
+		existing.Runtime = info.Runtime
+	}
+
+	for _, task_dir := range containerdTaskDirs {
+		dirs, _ := filepath.Glob(filepath.Join(root, task_dir, "*", "*"))
+		for _, dir := range dirs {
+			info := &ContainerInfo{
+				ID:        filepath.Base(dir),
+				Runtime:   "containerd",
+				Namespace: filepath.Base(filepath.Dir(dir)),
+			}
+			readBundle(dir, "init.pid", info)
+			add(info)
+		}
+	}
+
+	dirs, _ := filepath.Glob(filepath.Join(root, crioContainersDir, "*", "userdata"))
+	for _, dir := range dirs {
+		info := &ContainerInfo{
+			ID:      filepath.Base(filepath.Dir(dir)),
+			Runtime: "cri-o",
+		}
+		readBundle(dir, "pidfile", info)
+		add(info)
+	}
+
+	configs, _ := filepath.Glob(
+		filepath.Join(root, dockerContainerDir, "*", "config.v2.json"))
+	for _, config_path := range configs {
+		data, err := ioutil.ReadFile(config_path)
+		if err != nil {
+			continue
+		}
+
+		config := &dockerConfig{}
+		if json.Unmarshal(data, config) != nil || config.ID == "" {
+			continue
+		}
+
+		info := &ContainerInfo{
+			ID:       config.ID,
+			Name:     strings.TrimPrefix(config.Name, "/"),
+			Image:    config.Config.Image,
+			Runtime:  "docker",
+			Created:  config.Created,
+			StateDir: filepath.Dir(config_path),
+		}
+		if config.State.Running {
+			info.Pid = config.State.Pid
+		}
+		add(info)
+	}
+
+	// A container is running if its init process is still around.
+	for _, info := range result {
+		if info.Pid > 0 {
+			_, err := os.Stat(filepath.Join(
+				root, "proc", strconv.Itoa(info.Pid)))
+			info.Running = err == nil
+		}
+	}
+
+	return result
+}
+
+// Extract the container ID from the content of /proc/<pid>/cgroup
+// or return "" if the process is not in a container.
+func GetContainerIDFromCgroup(data string) string {
+	for _, line := range strings.Split(data, "\n") {
+		// Each line is hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) < 3 {
+			continue
+		}
+
+		// Runtimes name the cgroup after the container ID in
+		// different ways, e.g. /docker/<id>,
+		// /system.slice/docker-<id>.scope or
+		// /kubepods/.../cri-containerd-<id>.scope
+		matches := containerIdRegex.FindAllString(parts[2], -1)
+		if len(matches) > 0 {
+			return matches[len(matches)-1]
+		}
+	}
+	return ""
+}
+
+func splitPath(p string) []string {
+	var result []string
+	for _, c := range strings.Split(p, "/") {
+		if c != "" {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// Resolve the path components inside the root directory, following
+// symlinks relative to the root rather than the host. This prevents
+// absolute symlinks inside the container from escaping to the host's
+// filesystem. If follow_last is false a symlink in the final
+// component is not followed.
+func resolveInRoot(root string, components []string, follow_last bool) (
+	string, error) {
+
+	var resolved []string
+	pending := append([]string{}, components...)
+	links := 0
+
+	for len(pending) > 0 {
+		component := pending[0]
+		pending = pending[1:]
+
+		switch component {
+		case "", ".":
+			continue
+		case "..":
+			if len(resolved) > 0 {
+				resolved = resolved[:len(resolved)-1]
+			}
+			continue
+		}
+
+		candidate := make([]string, 0, len(resolved)+1)
+		candidate = append(candidate, resolved...)
+		candidate = append(candidate, component)
+
+		if len(pending) == 0 && !follow_last {
+			resolved = candidate
+			continue
+		}
+
+		host_path := filepath.Join(root, path.Join(candidate...))
+		lstat, err := os.Lstat(host_path)
+		if err != nil {
+			return "", err
+		}
+
+		if lstat.Mode()&os.ModeSymlink == 0 {
+			resolved = candidate
+			continue
+		}
+
+		links++
+		if links > MAX_SYMLINKS {
+			return "", errors.New("Too many levels of symbolic links")
+		}
+
+		target, err := os.Readlink(host_path)
+		if err != nil {
+			return "", err
+		}
+
+		// Absolute links restart from the container's root.
+		if strings.HasPrefix(target, "/") {
+			resolved = nil
+		}
+		pending = append(splitPath(target), pending...)
+	}
+
+	return filepath.Join(root, path.Join(resolved...)), nil
+}
@@ -0,0 +1,148 @@
+package container
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	dockerID = strings.Repeat("a1", 32)
+	k8sID    = strings.Repeat("b2", 32)
+	crioID   = strings.Repeat("c3", 32)
+)
+
+func writeFile(t *testing.T, root, name, content string) {
+	path := filepath.Join(root, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+}
+
+func TestGetContainers(t *testing.T) {
+	root, err := ioutil.TempDir("", "container")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	// A Docker container is visible both as a containerd task and in
+	// Docker's own state.
+	writeFile(t, root, "run/containerd/io.containerd.runtime.v2.task/moby/"+
+		dockerID+"/init.pid", "1234")
+	writeFile(t, root, "run/containerd/io.containerd.runtime.v2.task/moby/"+
+		dockerID+"/config.json", `{"ociVersion": "1.0.2"}`)
+	writeFile(t, root, "var/lib/docker/containers/"+dockerID+"/config.v2.json",
+		`{"ID": "`+dockerID+`", "Name": "/web",
+          "Created": "2022-05-01T10:00:00Z",
+          "Config": {"Image": "nginx:latest"},
+          "State": {"Running": true, "Pid": 1234}}`)
+	writeFile(t, root, "proc/1234/cmdline", "nginx")
+
+	// A Kubernetes container run by containerd.
+	writeFile(t, root, "run/containerd/io.containerd.runtime.v2.task/k8s.io/"+
+		k8sID+"/init.pid", "5678")
+	writeFile(t, root, "run/containerd/io.containerd.runtime.v2.task/k8s.io/"+
+		k8sID+"/config.json", `{"annotations": {
+          "io.kubernetes.cri.container-type": "container",
+          "io.kubernetes.cri.container-name": "api",
+          "io.kubernetes.cri.image-name": "example/api:1.0",
+          "io.kubernetes.cri.sandbox-name": "api-6d4cf56db6-x7k2p",
+          "io.kubernetes.cri.sandbox-namespace": "prod"}}`)
+
+	// A Kubernetes container run by CRI-O which has exited.
+	writeFile(t, root, "run/containers/storage/overlay-containers/"+
+		crioID+"/userdata/pidfile", "9999")
+	writeFile(t, root, "run/containers/storage/overlay-containers/"+
+		crioID+"/userdata/config.json", `{"annotations": {
+          "io.kubernetes.container.name": "worker",
+          "io.kubernetes.pod.name": "worker-0",
+          "io.kubernetes.pod.namespace": "batch"}}`)
+
+	containers := make(map[string]*ContainerInfo)
+	for _, info := range GetContainers(root) {
+		containers[info.ID] = info
+	}
+	require.Equal(t, 3, len(containers))
+
+	docker := containers[dockerID]
+	assert.Equal(t, "web", docker.Name)
+	assert.Equal(t, "nginx:latest", docker.Image)
+	assert.Equal(t, "docker", docker.Runtime)
+	assert.Equal(t, "moby", docker.Namespace)
+	assert.Equal(t, 1234, docker.Pid)
+	assert.True(t, docker.Running)
+
+	k8s := containers[k8sID]
+	assert.Equal(t, "api", k8s.Name)
+	assert.Equal(t, "example/api:1.0", k8s.Image)
+	assert.Equal(t, "containerd", k8s.Runtime)
+	assert.Equal(t, "api-6d4cf56db6-x7k2p", k8s.PodName)
+	assert.Equal(t, "prod", k8s.PodNamespace)
+	assert.Equal(t, 5678, k8s.Pid)
+	assert.False(t, k8s.Running)
+
+	crio := containers[crioID]
+	assert.Equal(t, "worker", crio.Name)
+	assert.Equal(t, "cri-o", crio.Runtime)
+	assert.Equal(t, "worker-0", crio.PodName)
+	assert.Equal(t, "batch", crio.PodNamespace)
+}
+
+func TestGetContainerIDFromCgroup(t *testing.T) {
+	for _, test := range []struct {
+		cgroup, id string
+	}{
+		{"0::/init.scope\n", ""},
+		{"0::/user.slice/user-1000.slice/session-2.scope\n", ""},
+		{"12:pids:/docker/" + dockerID + "\n1:name=systemd:/docker/" +
+			dockerID + "\n", dockerID},
+		{"0::/system.slice/docker-" + dockerID + ".scope\n", dockerID},
+		{"0::/kubepods.slice/kubepods-besteffort.slice/" +
+			"kubepods-besteffort-pod0f4a7a2c_5e1b_4b7e_9c53_1b0c8c2a9d11.slice/" +
+			"cri-containerd-" + k8sID + ".scope\n", k8sID},
+		{"0::/kubepods/burstable/pod0f4a7a2c-5e1b-4b7e-9c53-1b0c8c2a9d11/" +
+			crioID + "\n", crioID},
+		{"0::/machine.slice/crio-" + crioID + ".scope\n", crioID},
+	} {
+		assert.Equal(t, test.id, GetContainerIDFromCgroup(test.cgroup),
+			test.cgroup)
+	}
+}
+
+func TestResolveInRoot(t *testing.T) {
+	root, err := ioutil.TempDir("", "container")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	writeFile(t, root, "usr/share/zoneinfo/UTC", "TZif")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "etc"), 0700))
+
+	// Absolute links must be resolved inside the root.
+	require.NoError(t, os.Symlink("/usr/share/zoneinfo/UTC",
+		filepath.Join(root, "etc/localtime")))
+	require.NoError(t, os.Symlink("../../..",
+		filepath.Join(root, "usr/share/up")))
+	require.NoError(t, os.Symlink("loop",
+		filepath.Join(root, "etc/loop")))
+
+	resolved, err := resolveInRoot(root, []string{"etc", "localtime"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "usr/share/zoneinfo/UTC"), resolved)
+
+	// Do not follow the last component.
+	resolved, err = resolveInRoot(root, []string{"etc", "localtime"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "etc/localtime"), resolved)
+
+	// Relative links can not go above the root.
+	resolved, err = resolveInRoot(root, []string{
+		"usr", "share", "up", "..", "..", "etc", "localtime"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "usr/share/zoneinfo/UTC"), resolved)
+
+	_, err = resolveInRoot(root, []string{"etc", "loop"}, true)
+	assert.Error(t, err)
+}
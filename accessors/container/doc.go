@@ -0,0 +1,9 @@
+// An accessor for files inside running containers.
+//
+// The container accessor exposes each running container as a top
+// level directory named after its container ID. Files within the
+// container are read through the container's root filesystem as seen
+// by its init process (i.e. /proc/<pid>/root), so this works for any
+// storage driver.
+
+package container
@@ -257,45 +257,46 @@ func init() {
 }
 
 var (
-	maps_regexp = regexp.MustCompile("(?P<Start>^[^-]+)-(?P<End>[^\\s]+)\\s+(?P<Perm>[^\\s]+)\\s+(?P<Size>[^\\s]+)\\s+[^\\s]+\\s+(?P<PermInt>[^\\s]+)\\s+(?P<Filename>.+?)(?P<Deleted> \\(deleted\\))?$")
+	// Anonymous mappings (e.g. the heap) have no filename.
+	maps_regexp = regexp.MustCompile("(?P<Start>^[^-]+)-(?P<End>[^\\s]+)\\s+(?P<Perm>[^\\s]+)\\s+(?P<Size>[^\\s]+)\\s+[^\\s]+\\s+(?P<PermInt>[^\\s]+)\\s*(?P<Filename>.*?)(?P<Deleted> \\(deleted\\))?$")
 )
 
-func GetVads(pid uint64) ([]*uploads.Range, error) {
+// A memory region from /proc/pid/maps
+type MemoryRegion struct {
+	Address     uint64
+	Size        uint64
+	MappingName string
+	Protection  string
+}
+
+func GetMemoryRegions(pid uint64) ([]*MemoryRegion, error) {
 	maps_fd, err := os.Open(fmt.Sprintf("/proc/%d/maps", pid))
 	if err != nil {
 		return nil, err
 	}
 	defer maps_fd.Close()
 
-	var result []*uploads.Range
+	var result []*MemoryRegion
 
 	scanner := bufio.NewScanner(maps_fd)
 	for scanner.Scan() {
 		hits := maps_regexp.FindStringSubmatch(scanner.Text())
 		if len(hits) > 0 {
-			protection := hits[3]
-			// Only include readable ranges.
-			if len(protection) < 2 || protection[0] != 'r' {
-				continue
-			}
-
-			start, err := strconv.ParseInt(hits[1], 16, 64)
+			start, err := strconv.ParseUint(hits[1], 16, 64)
 			if err != nil {
 				continue
 			}
 
-			end, err := strconv.ParseInt(hits[2], 16, 64)
-			if err != nil {
-				continue
-			}
-
-			// We can not read kernel memory
-			if start < 0 || end < 0 {
+			end, err := strconv.ParseUint(hits[2], 16, 64)
+			if err != nil || end < start {
 				continue
 			}
 
-			result = append(result, &uploads.Range{
-				Offset: start, Length: end - start,
+			result = append(result, &MemoryRegion{
+				Address:     start,
+				Size:        end - start,
+				Protection:  hits[3],
+				MappingName: hits[6],
 			})
 		}
 	}
@@ -307,3 +308,29 @@ func GetVads(pid uint64) ([]*uploads.Range, error) {
 
 	return result, nil
 }
+
+func GetVads(pid uint64) ([]*uploads.Range, error) {
+	regions, err := GetMemoryRegions(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*uploads.Range
+	for _, region := range regions {
+		// Only include readable ranges.
+		if len(region.Protection) < 2 || region.Protection[0] != 'r' {
+			continue
+		}
+
+		// We can not read kernel memory
+		if int64(region.Address) < 0 || int64(region.Address+region.Size) < 0 {
+			continue
+		}
+
+		result = append(result, &uploads.Range{
+			Offset: int64(region.Address), Length: int64(region.Size),
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,82 @@
+// +build linux
+
+package process
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRegions(t *testing.T) {
+	buf := []byte(strings.Repeat("Hello world ", 100))
+	address := uint64(uintptr(unsafe.Pointer(&buf[0])))
+	pid := uint64(os.Getpid())
+
+	// The buffer is in a readable and writable region.
+	regions, err := GetMemoryRegions(pid)
+	assert.NoError(t, err)
+
+	var found *MemoryRegion
+	for _, region := range regions {
+		if region.Address <= address && address < region.Address+region.Size {
+			found = region
+		}
+	}
+	assert.NotNil(t, found)
+	assert.True(t, strings.HasPrefix(found.Protection, "rw"), found.Protection)
+
+	// Our own executable is mapped too.
+	exe, err := os.Executable()
+	assert.NoError(t, err)
+
+	mapped := false
+	for _, region := range regions {
+		if region.MappingName == exe {
+			mapped = true
+		}
+	}
+	assert.True(t, mapped)
+
+	// Only readable regions are read by the accessor.
+	vads, err := GetVads(pid)
+	assert.NoError(t, err)
+	assert.True(t, len(vads) <= len(regions))
+
+	accessor := &ProcessAccessor{}
+	fd, err := accessor.Open(fmt.Sprintf("/%d", pid))
+	assert.NoError(t, err)
+	defer fd.Close()
+
+	_, err = fd.Seek(int64(address), io.SeekStart)
+	assert.NoError(t, err)
+
+	data := make([]byte, len(buf))
+	_, err = io.ReadFull(fd, data)
+	assert.NoError(t, err)
+	assert.Equal(t, buf, data)
+}
+
+func TestMapsRegexp(t *testing.T) {
+	for _, test := range []struct {
+		line, filename, deleted string
+	}{
+		{"7fed77fc1000-7fed77fe7000 r--p 00000000 fe:00 700582                     /usr/lib/libc.so.6",
+			"/usr/lib/libc.so.6", ""},
+		{"5572040cd000-5572040ee000 rw-p 00000000 00:00 0                          [heap]",
+			"[heap]", ""},
+		{"7fed77efa000-7fed77fc1000 rw-p 00000000 00:00 0 ", "", ""},
+		{"7fed77efa000-7fed77fc1000 r-xp 00000000 fe:00 1234                       /tmp/x (deleted)",
+			"/tmp/x", " (deleted)"},
+	} {
+		hits := maps_regexp.FindStringSubmatch(test.line)
+		assert.Equal(t, 8, len(hits), test.line)
+		assert.Equal(t, test.filename, hits[6])
+		assert.Equal(t, test.deleted, hits[7])
+	}
+}
@@ -1 +1,42 @@
 package accessors
+
+import (
+	"context"
+	"fmt"
+
+	"www.velocidex.com/golang/velociraptor/utils"
+	"www.velocidex.com/golang/vfilter"
+	"www.velocidex.com/golang/vfilter/arg_parser"
+	"www.velocidex.com/golang/vfilter/types"
+)
+
+// Some accessors are configured by setting a scope variable to a
+// dict of options (e.g. credentials). Parse the options into the
+// config struct - it is not an error for the variable to be unset.
+func GetAccessorConfig(ctx context.Context, scope vfilter.Scope,
+	variable string, config interface{}) error {
+
+	value, pres := scope.Resolve(variable)
+	if !pres {
+		return nil
+	}
+
+	switch t := value.(type) {
+	case *vfilter.StoredExpression:
+		value = t.Reduce(ctx, scope)
+
+	case types.LazyExpr:
+		value = t.Reduce(ctx)
+	}
+
+	if utils.IsNil(value) {
+		return nil
+	}
+
+	err := arg_parser.ExtractArgsWithContext(ctx, scope,
+		vfilter.RowToDict(ctx, scope, value), config)
+	if err != nil {
+		return fmt.Errorf("%v: %w", variable, err)
+	}
+	return nil
+}
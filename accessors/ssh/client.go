@@ -0,0 +1,171 @@
+//+build extras
+
+package ssh
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pkg/sftp"
+	crypto_ssh "golang.org/x/crypto/ssh"
+	"www.velocidex.com/golang/velociraptor/accessors"
+	"www.velocidex.com/golang/velociraptor/constants"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/vfilter"
+)
+
+type SSHConfig struct {
+	Hostname   string `vfilter:"required,field=hostname,doc=The host to connect to including port number (default port 22)"`
+	Username   string `vfilter:"required,field=username,doc=The username to connect with"`
+	Password   string `vfilter:"optional,field=password,doc=The password to authenticate with"`
+	PrivateKey string `vfilter:"optional,field=private_key,doc=The private key to authenticate with (PEM encoded)"`
+	HostKey    string `vfilter:"optional,field=hostkey,doc=The host key to verify (e.g. ssh-ed25519 AAAA...). Blank to disable verification"`
+	Timeout    int64  `vfilter:"optional,field=timeout,doc=Connection timeout in seconds (default 30)"`
+}
+
+// A connection to a remote host. The SFTP subsystem is only started
+// when a file is first accessed.
+type SSHClient struct {
+	mu sync.Mutex
+
+	Hostname string
+	client   *crypto_ssh.Client
+	sftp     *sftp.Client
+}
+
+func (self *SSHClient) SFTP() (*sftp.Client, error) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	if self.sftp == nil {
+		client, err := sftp.NewClient(self.client)
+		if err != nil {
+			return nil, fmt.Errorf("%v: starting sftp: %w", self.Hostname, err)
+		}
+		self.sftp = client
+	}
+	return self.sftp, nil
+}
+
+func (self *SSHClient) NewSession() (*crypto_ssh.Session, error) {
+	return self.client.NewSession()
+}
+
+func (self *SSHClient) Close() {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	if self.sftp != nil {
+		self.sftp.Close()
+	}
+	self.client.Close()
+}
+
+func keyString(k crypto_ssh.PublicKey) string {
+	return k.Type() + " " + base64.StdEncoding.EncodeToString(k.Marshal())
+}
+
+func hostKeyCallback(config *SSHConfig) crypto_ssh.HostKeyCallback {
+	if config.HostKey == "" {
+		return crypto_ssh.InsecureIgnoreHostKey()
+	}
+
+	return func(_ string, _ net.Addr, k crypto_ssh.PublicKey) error {
+		ks := keyString(k)
+		if config.HostKey != ks {
+			return fmt.Errorf(
+				"SSH-key verification: expected %s but got %s",
+				config.HostKey, ks)
+		}
+		return nil
+	}
+}
+
+func dial(config *SSHConfig) (*SSHClient, error) {
+	var auth []crypto_ssh.AuthMethod
+	if config.PrivateKey != "" {
+		signer, err := crypto_ssh.ParsePrivateKey([]byte(config.PrivateKey))
+		if err != nil {
+			return nil, err
+		}
+		auth = append(auth, crypto_ssh.PublicKeys(signer))
+	}
+
+	if config.Password != "" {
+		auth = append(auth, crypto_ssh.Password(config.Password))
+	}
+
+	if len(auth) == 0 {
+		return nil, errors.New("Either password or private_key must be set")
+	}
+
+	hostname := config.Hostname
+	_, _, err := net.SplitHostPort(hostname)
+	if err != nil {
+		hostname = net.JoinHostPort(hostname, "22")
+	}
+
+	if config.Timeout == 0 {
+		config.Timeout = 30
+	}
+
+	client, err := crypto_ssh.Dial("tcp", hostname, &crypto_ssh.ClientConfig{
+		User:            config.Username,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback(config),
+		Timeout:         time.Duration(config.Timeout) * time.Second,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SSHClient{
+		Hostname: config.Hostname,
+		client:   client,
+	}, nil
+}
+
+// Get a connection to the host specified in the SSH_CONFIG
+// variable. Connections are cached in the root scope so all users in
+// the query share the same connection, which is closed when the query
+// is done.
+func GetSSHClient(ctx context.Context, scope vfilter.Scope) (*SSHClient, error) {
+	config := &SSHConfig{}
+	err := accessors.GetAccessorConfig(ctx, scope, constants.SSH_CONFIG, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Hostname == "" {
+		return nil, errors.New("SSH_CONFIG must be set to connect over ssh")
+	}
+
+	cache_key := fmt.Sprintf("ssh %s@%s", config.Username, config.Hostname)
+	switch t := vql_subsystem.CacheGet(scope, cache_key).(type) {
+	case error:
+		return nil, t
+	case *SSHClient:
+		return t, nil
+	}
+
+	client, err := dial(config)
+	if err != nil {
+		err = fmt.Errorf("ssh: %v: %w", config.Hostname, err)
+		vql_subsystem.CacheSet(scope, cache_key, err)
+		return nil, err
+	}
+
+	err = vql_subsystem.GetRootScope(scope).AddDestructor(client.Close)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	vql_subsystem.CacheSet(scope, cache_key, client)
+	return client, nil
+}
@@ -0,0 +1,13 @@
+// An accessor and executor for hosts reached over SSH.
+//
+// This allows server artifacts to collect from hosts which can not
+// run a client (e.g. network devices and appliances). The ssh
+// accessor reads files using SFTP and ssh_execve() runs commands on
+// the remote host. Both are configured by setting the SSH_CONFIG
+// variable to a dict of connection options and share a single
+// connection for the query.
+//
+// This package depends on the SFTP library and so is only built with
+// the extras tag.
+
+package ssh
@@ -0,0 +1,170 @@
+//+build extras
+
+package ssh
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Velocidex/ordereddict"
+	"github.com/sirupsen/logrus"
+	crypto_ssh "golang.org/x/crypto/ssh"
+	"www.velocidex.com/golang/velociraptor/acls"
+	"www.velocidex.com/golang/velociraptor/artifacts"
+	"www.velocidex.com/golang/velociraptor/utils"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/velociraptor/vql/common"
+	"www.velocidex.com/golang/vfilter"
+	"www.velocidex.com/golang/vfilter/arg_parser"
+)
+
+type SSHExecvePluginArgs struct {
+	Argv    []string `vfilter:"optional,field=argv,doc=Argv to run the command with. The arguments are quoted for a POSIX shell."`
+	Command string   `vfilter:"optional,field=command,doc=A raw command line to run (e.g. for devices without a POSIX shell)."`
+	Length  int64    `vfilter:"optional,field=length,doc=Maximum size of stdout and stderr to capture (default 10Mb)."`
+}
+
+func shellQuote(argv []string) string {
+	result := make([]string, 0, len(argv))
+	for _, arg := range argv {
+		result = append(result,
+			"'"+strings.Replace(arg, "'", `'\''`, -1)+"'")
+	}
+	return strings.Join(result, " ")
+}
+
+type SSHExecvePlugin struct{}
+
+func (self SSHExecvePlugin) Call(
+	ctx context.Context,
+	scope vfilter.Scope,
+	args *ordereddict.Dict) <-chan vfilter.Row {
+	output_chan := make(chan vfilter.Row)
+
+	go func() {
+		defer close(output_chan)
+
+		err := vql_subsystem.CheckAccess(scope, acls.EXECVE)
+		if err != nil {
+			scope.Log("ssh_execve: %v", err)
+			return
+		}
+
+		// Check the config if we are allowed to execve at all.
+		config_obj, ok := artifacts.GetConfig(scope)
+		if ok && config_obj.PreventExecve {
+			scope.Log("ssh_execve: Not allowed to execve by configuration.")
+			return
+		}
+
+		arg := &SSHExecvePluginArgs{}
+		err = arg_parser.ExtractArgsWithContext(ctx, scope, args, arg)
+		if err != nil {
+			scope.Log("ssh_execve: %v", err)
+			return
+		}
+
+		command := arg.Command
+		if len(arg.Argv) > 0 {
+			command = shellQuote(arg.Argv)
+		}
+
+		if command == "" {
+			scope.Log("ssh_execve: no command to run")
+			return
+		}
+
+		if arg.Length == 0 {
+			arg.Length = 10 * 1024 * 1024
+		}
+
+		client, err := GetSSHClient(ctx, scope)
+		if err != nil {
+			scope.Log("ssh_execve: %v", err)
+			return
+		}
+
+		// Report the command we ran for auditing
+		// purposes. This will be collected in the flow logs.
+		scope.Log("ssh_execve: Running external command on %v: %v",
+			client.Hostname, command)
+		vql_subsystem.AuditLog(scope, "ssh_execve", logrus.Fields{
+			"host":    client.Hostname,
+			"command": command,
+		})
+
+		response, err := runCommand(ctx, client, command, int(arg.Length))
+		if err != nil {
+			scope.Log("ssh_execve: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+		case output_chan <- response:
+		}
+	}()
+
+	return output_chan
+}
+
+func runCommand(ctx context.Context, client *SSHClient,
+	command string, length int) (*common.ShellResult, error) {
+	session, err := client.NewSession()
+	if err != nil {
+		return &common.ShellResult{
+			ReturnCode: 1,
+			Stderr:     err.Error(),
+		}, err
+	}
+	defer session.Close()
+
+	stdout := utils.NewLimitedBuffer(length)
+	stderr := utils.NewLimitedBuffer(length)
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	// Close the session to abort the command when the query is
+	// cancelled.
+	done := make(chan bool)
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			session.Close()
+		case <-done:
+		}
+	}()
+
+	response := &common.ShellResult{}
+	err = session.Run(command)
+	if err != nil {
+		response.ReturnCode = -1
+
+		exit_err := &crypto_ssh.ExitError{}
+		if errors.As(err, &exit_err) {
+			response.ReturnCode = int64(exit_err.ExitStatus())
+			err = nil
+		}
+	}
+
+	response.Stdout = stdout.String()
+	response.Stderr = stderr.String()
+	response.Complete = err == nil
+
+	return response, err
+}
+
+func (self SSHExecvePlugin) Info(
+	scope vfilter.Scope, type_map *vfilter.TypeMap) *vfilter.PluginInfo {
+	return &vfilter.PluginInfo{
+		Name:    "ssh_execve",
+		Doc:     "Run a command on a remote host over SSH. The connection is configured with the SSH_CONFIG variable.",
+		ArgType: type_map.AddType(scope, &SSHExecvePluginArgs{}),
+	}
+}
+
+func init() {
+	vql_subsystem.RegisterPlugin(&SSHExecvePlugin{})
+}
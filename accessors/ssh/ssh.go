@@ -0,0 +1,216 @@
+//+build extras
+
+package ssh
+
+import (
+	"context"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	"github.com/pkg/sftp"
+	"www.velocidex.com/golang/velociraptor/accessors"
+	"www.velocidex.com/golang/velociraptor/acls"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/vfilter"
+)
+
+// The path on the remote host. Components are not escaped since SFTP
+// takes the raw path.
+func remotePath(full_path *accessors.OSPath) string {
+	return "/" + strings.Join(full_path.Components, "/")
+}
+
+type SSHFileInfo struct {
+	os.FileInfo
+
+	full_path *accessors.OSPath
+	client    *sftp.Client
+}
+
+func (self *SSHFileInfo) OSPath() *accessors.OSPath {
+	return self.full_path
+}
+
+func (self *SSHFileInfo) FullPath() string {
+	return self.full_path.String()
+}
+
+// SFTP only reports the modified and access times.
+func (self *SSHFileInfo) Mtime() time.Time {
+	return self.ModTime()
+}
+
+func (self *SSHFileInfo) Atime() time.Time {
+	stat, ok := self.Sys().(*sftp.FileStat)
+	if !ok {
+		return time.Time{}
+	}
+	return time.Unix(int64(stat.Atime), 0)
+}
+
+func (self *SSHFileInfo) Ctime() time.Time {
+	return time.Time{}
+}
+
+func (self *SSHFileInfo) Btime() time.Time {
+	return time.Time{}
+}
+
+func (self *SSHFileInfo) Data() *ordereddict.Dict {
+	result := ordereddict.NewDict()
+	stat, ok := self.Sys().(*sftp.FileStat)
+	if ok {
+		result.Set("Uid", stat.UID).Set("Gid", stat.GID)
+	}
+
+	if self.IsLink() {
+		target, err := self.client.ReadLink(remotePath(self.full_path))
+		if err == nil {
+			result.Set("Link", target)
+		}
+	}
+
+	return result
+}
+
+func (self *SSHFileInfo) IsLink() bool {
+	return self.Mode()&os.ModeSymlink != 0
+}
+
+func (self *SSHFileInfo) GetLink() (*accessors.OSPath, error) {
+	target, err := self.client.ReadLink(remotePath(self.full_path))
+	if err != nil {
+		return nil, err
+	}
+
+	// Relative links are relative to the directory containing the
+	// link.
+	if !path.IsAbs(target) {
+		target = path.Join(remotePath(self.full_path.Dirname()), target)
+	}
+
+	return self.full_path.Parse(target)
+}
+
+// Access files on a remote host using SFTP. Paths are interpreted as
+// Unix paths on the remote host.
+type SSHFileSystemAccessor struct {
+	root   *accessors.OSPath
+	client *SSHClient
+}
+
+func (self *SSHFileSystemAccessor) New(
+	scope vfilter.Scope) (accessors.FileSystemAccessor, error) {
+
+	err := vql_subsystem.CheckAccess(scope, acls.FILESYSTEM_READ)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := GetSSHClient(context.Background(), scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SSHFileSystemAccessor{
+		root:   self.root,
+		client: client,
+	}, nil
+}
+
+func (self *SSHFileSystemAccessor) ParsePath(
+	path string) (*accessors.OSPath, error) {
+	return self.root.Parse(path)
+}
+
+func (self *SSHFileSystemAccessor) ReadDir(
+	path string) ([]accessors.FileInfo, error) {
+	full_path, err := self.ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return self.ReadDirWithOSPath(full_path)
+}
+
+func (self *SSHFileSystemAccessor) ReadDirWithOSPath(
+	full_path *accessors.OSPath) ([]accessors.FileInfo, error) {
+	client, err := self.client.SFTP()
+	if err != nil {
+		return nil, err
+	}
+
+	children, err := client.ReadDir(remotePath(full_path))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]accessors.FileInfo, 0, len(children))
+	for _, child := range children {
+		result = append(result, &SSHFileInfo{
+			FileInfo:  child,
+			full_path: full_path.Append(child.Name()),
+			client:    client,
+		})
+	}
+
+	return result, nil
+}
+
+func (self *SSHFileSystemAccessor) Lstat(
+	filename string) (accessors.FileInfo, error) {
+	full_path, err := self.ParsePath(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return self.LstatWithOSPath(full_path)
+}
+
+func (self *SSHFileSystemAccessor) LstatWithOSPath(
+	full_path *accessors.OSPath) (accessors.FileInfo, error) {
+	client, err := self.client.SFTP()
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := client.Lstat(remotePath(full_path))
+	if err != nil {
+		return nil, err
+	}
+
+	return &SSHFileInfo{
+		FileInfo:  stat,
+		full_path: full_path,
+		client:    client,
+	}, nil
+}
+
+func (self *SSHFileSystemAccessor) Open(
+	path string) (accessors.ReadSeekCloser, error) {
+	full_path, err := self.ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return self.OpenWithOSPath(full_path)
+}
+
+func (self *SSHFileSystemAccessor) OpenWithOSPath(
+	full_path *accessors.OSPath) (accessors.ReadSeekCloser, error) {
+	client, err := self.client.SFTP()
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Open(remotePath(full_path))
+}
+
+func init() {
+	root_path, _ := accessors.NewLinuxOSPath("")
+	accessors.Register("ssh", &SSHFileSystemAccessor{root: root_path},
+		`Access files on a remote host over SSH (SFTP). The connection is configured with the SSH_CONFIG variable.`)
+}
@@ -0,0 +1,231 @@
+//+build extras
+
+package ssh
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/Velocidex/ordereddict"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	crypto_ssh "golang.org/x/crypto/ssh"
+	"www.velocidex.com/golang/velociraptor/accessors"
+	"www.velocidex.com/golang/velociraptor/constants"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/velociraptor/vql/common"
+	"www.velocidex.com/golang/vfilter"
+)
+
+// Start a minimal ssh server supporting the sftp subsystem and exec
+// requests.
+func startServer(t *testing.T) (address string, host_key string) {
+	_, private_key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	signer, err := crypto_ssh.NewSignerFromKey(private_key)
+	require.NoError(t, err)
+
+	config := &crypto_ssh.ServerConfig{
+		PasswordCallback: func(c crypto_ssh.ConnMetadata, pass []byte) (
+			*crypto_ssh.Permissions, error) {
+			if c.User() == "test" && string(pass) == "secret" {
+				return nil, nil
+			}
+			return nil, os.ErrPermission
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveConn(conn, config)
+		}
+	}()
+
+	return listener.Addr().String(), keyString(signer.PublicKey())
+}
+
+func serveConn(conn net.Conn, config *crypto_ssh.ServerConfig) {
+	_, channels, requests, err := crypto_ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go crypto_ssh.DiscardRequests(requests)
+
+	for new_channel := range channels {
+		channel, requests, err := new_channel.Accept()
+		if err != nil {
+			continue
+		}
+
+		go func() {
+			defer channel.Close()
+
+			for req := range requests {
+				// Payloads are a single ssh string.
+				payload := ""
+				if len(req.Payload) > 4 {
+					payload = string(req.Payload[4:])
+				}
+
+				switch {
+				case req.Type == "subsystem" && payload == "sftp":
+					req.Reply(true, nil)
+					server, err := sftp.NewServer(channel)
+					if err == nil {
+						server.Serve()
+					}
+					return
+
+				case req.Type == "exec":
+					req.Reply(true, nil)
+					cmd := exec.Command("/bin/sh", "-c", payload)
+					cmd.Stdout = channel
+					cmd.Stderr = channel.Stderr()
+					status := make([]byte, 4)
+					if cmd.Run() != nil {
+						binary.BigEndian.PutUint32(
+							status, uint32(cmd.ProcessState.ExitCode()))
+					}
+					channel.SendRequest("exit-status", false, status)
+					return
+
+				default:
+					req.Reply(false, nil)
+				}
+			}
+		}()
+	}
+}
+
+func makeScope(t *testing.T, config *ordereddict.Dict) vfilter.Scope {
+	scope := vql_subsystem.MakeScope().AppendVars(ordereddict.NewDict().
+		Set(vql_subsystem.ACL_MANAGER_VAR, vql_subsystem.NullACLManager{}).
+		Set(constants.SSH_CONFIG, config))
+	t.Cleanup(func() { scope.Close() })
+	return scope
+}
+
+func TestSSHAccessor(t *testing.T) {
+	address, host_key := startServer(t)
+
+	dir, err := ioutil.TempDir("", "ssh_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "subdir"), 0700))
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(dir, "hello.txt"), []byte("Hello world"), 0600))
+	require.NoError(t, os.Symlink("hello.txt", filepath.Join(dir, "link")))
+
+	scope := makeScope(t, ordereddict.NewDict().
+		Set("hostname", address).
+		Set("username", "test").
+		Set("password", "secret").
+		Set("hostkey", host_key))
+
+	accessor, err := accessors.GetAccessor("ssh", scope)
+	require.NoError(t, err)
+
+	children, err := accessor.ReadDir(dir)
+	require.NoError(t, err)
+
+	var names []string
+	for _, child := range children {
+		names = append(names, child.Name())
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{"hello.txt", "link", "subdir"}, names)
+
+	stat, err := accessor.Lstat(filepath.Join(dir, "link"))
+	require.NoError(t, err)
+	assert.True(t, stat.IsLink())
+
+	target, err := stat.GetLink()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "hello.txt"), target.String())
+
+	fd, err := accessor.Open(filepath.Join(dir, "hello.txt"))
+	require.NoError(t, err)
+	defer fd.Close()
+
+	_, err = fd.Seek(6, os.SEEK_SET)
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadAll(fd)
+	require.NoError(t, err)
+	assert.Equal(t, "world", string(data))
+
+	_, err = accessor.Lstat(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+
+	// The root of the glob is the remote root directory.
+	stat, err = accessor.Lstat("")
+	require.NoError(t, err)
+	assert.True(t, stat.IsDir())
+}
+
+func TestSSHHostKeyMismatch(t *testing.T) {
+	address, _ := startServer(t)
+
+	scope := makeScope(t, ordereddict.NewDict().
+		Set("hostname", address).
+		Set("username", "test").
+		Set("password", "secret").
+		Set("hostkey", "ssh-ed25519 AAAA"))
+
+	_, err := accessors.GetAccessor("ssh", scope)
+	assert.Error(t, err)
+}
+
+func TestSSHExecve(t *testing.T) {
+	address, _ := startServer(t)
+
+	scope := makeScope(t, ordereddict.NewDict().
+		Set("hostname", address).
+		Set("username", "test").
+		Set("password", "secret"))
+
+	client, err := GetSSHClient(context.Background(), scope)
+	require.NoError(t, err)
+
+	response, err := runCommand(context.Background(), client,
+		shellQuote([]string{"echo", "it's here"}), 1024)
+	require.NoError(t, err)
+	assert.Equal(t, &common.ShellResult{
+		Stdout:   "it's here\n",
+		Complete: true,
+	}, response)
+
+	response, err = runCommand(context.Background(), client, "echo error >&2; exit 3", 1024)
+	require.NoError(t, err)
+	assert.Equal(t, &common.ShellResult{
+		Stderr:     "error\n",
+		ReturnCode: 3,
+		Complete:   true,
+	}, response)
+
+	// Output is truncated to the length.
+	response, err = runCommand(context.Background(), client, "echo 0123456789", 4)
+	require.NoError(t, err)
+	assert.Equal(t, "0123", response.Stdout)
+}
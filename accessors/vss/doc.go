@@ -0,0 +1,8 @@
+// An accessor for Volume Shadow Copies.
+//
+// The vss accessor exposes only the shadow copies on the system. Each
+// shadow copy appears as a top level directory named after its device
+// (e.g. \\?\GLOBALROOT\Device\HarddiskVolumeShadowCopy1) and is
+// parsed using the NTFS parser.
+
+package vss
@@ -0,0 +1,190 @@
+// +build windows
+
+package vss
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	"www.velocidex.com/golang/velociraptor/accessors"
+	"www.velocidex.com/golang/velociraptor/accessors/ntfs"
+	"www.velocidex.com/golang/velociraptor/acls"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/velociraptor/vql/constants"
+	"www.velocidex.com/golang/velociraptor/vql/windows/wmi"
+	"www.velocidex.com/golang/vfilter"
+)
+
+const (
+	VSS_TAG = "$__VSS_Accessor"
+)
+
+// Map volume GUID paths to drive letters so we can tell which drive
+// a shadow copy was taken of.
+func getDriveLetters() map[string]string {
+	result := make(map[string]string)
+
+	volumes, err := wmi.Query(
+		"SELECT DeviceID, DriveLetter FROM Win32_Volume", "ROOT\\CIMV2")
+	if err != nil {
+		return result
+	}
+
+	for _, row := range volumes {
+		device_id, _ := row.GetString("DeviceID")
+		drive_letter, _ := row.GetString("DriveLetter")
+		if device_id != "" && drive_letter != "" {
+			result[strings.ToLower(device_id)] = drive_letter
+		}
+	}
+	return result
+}
+
+// Enumerate the shadow copies on the system.
+func GetShadowCopies() ([]*ordereddict.Dict, error) {
+	shadow_copies, err := wmi.Query(
+		"SELECT ID, SetID, DeviceObject, VolumeName, InstallDate, "+
+			"OriginatingMachine, ServiceMachine, ClientAccessible, "+
+			"Persistent FROM Win32_ShadowCopy",
+		"ROOT\\CIMV2")
+	if err != nil {
+		return nil, err
+	}
+
+	drive_letters := getDriveLetters()
+
+	result := make([]*ordereddict.Dict, 0, len(shadow_copies))
+	for _, row := range shadow_copies {
+		device_object, pres := row.GetString("DeviceObject")
+		if !pres {
+			continue
+		}
+
+		id, _ := row.GetString("ID")
+		set_id, _ := row.GetString("SetID")
+		volume_name, _ := row.GetString("VolumeName")
+		install_date, _ := row.GetString("InstallDate")
+		originating_machine, _ := row.GetString("OriginatingMachine")
+		service_machine, _ := row.GetString("ServiceMachine")
+		client_accessible, _ := row.GetBool("ClientAccessible")
+		persistent, _ := row.GetBool("Persistent")
+
+		created, _ := ParseWMIDateTime(install_date)
+
+		result = append(result, ordereddict.NewDict().
+			Set("ID", id).
+			Set("SetID", set_id).
+			Set("DeviceObject", device_object).
+			Set("VolumeName", volume_name).
+			Set("DriveLetter", drive_letters[strings.ToLower(volume_name)]).
+			Set("Created", created).
+			Set("OriginatingMachine", originating_machine).
+			Set("ServiceMachine", service_machine).
+			Set("ClientAccessible", client_accessible).
+			Set("Persistent", persistent))
+	}
+
+	return result, nil
+}
+
+type VSSFileSystemAccessor struct {
+	*accessors.MountFileSystemAccessor
+	age time.Time
+}
+
+func (self *VSSFileSystemAccessor) New(
+	scope vfilter.Scope) (accessors.FileSystemAccessor, error) {
+
+	// Cache the accessor for the life of the query.
+	cache_time := constants.GetNTFSCacheTime(context.Background(), scope)
+	root_scope := vql_subsystem.GetRootScope(scope)
+	cached_accessor, ok := vql_subsystem.CacheGet(
+		root_scope, VSS_TAG).(*VSSFileSystemAccessor)
+
+	// Ignore the cache if it is too old - shadow copies may have
+	// been added or removed.
+	if ok && cached_accessor.age.Add(cache_time).After(time.Now()) {
+		return cached_accessor, nil
+	}
+
+	// Build a virtual filesystem that mounts each shadow copy on it.
+	root_path, _ := accessors.NewWindowsNTFSPath("")
+	root_fs := accessors.NewVirtualFilesystemAccessor(root_path)
+
+	result := &VSSFileSystemAccessor{
+		MountFileSystemAccessor: accessors.NewMountFileSystemAccessor(
+			root_path, root_fs),
+		age: time.Now(),
+	}
+
+	shadow_copies, err := GetShadowCopies()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range shadow_copies {
+		device_object, _ := row.GetString("DeviceObject")
+		device_path, err := accessors.NewWindowsNTFSPath(device_object)
+		if err != nil {
+			continue
+		}
+
+		created, _ := row.Get("Created")
+		created_time, _ := created.(time.Time)
+
+		fi := &accessors.VirtualFileInfo{
+			IsDir_: true,
+			Path:   device_path,
+			Data_:  row,
+			Mtime_: created_time,
+			Btime_: created_time,
+		}
+
+		root_fs.SetVirtualFileInfo(fi)
+		result.AddMapping(
+			root_path, // Mount at the root of the filesystem
+			fi.OSPath(),
+			ntfs.NewNTFSFileSystemAccessor(
+				root_scope, root_path, fi.OSPath(), "file"))
+	}
+
+	vql_subsystem.CacheSet(root_scope, VSS_TAG, result)
+	return result, nil
+}
+
+func init() {
+	accessors.Register("vss", &VSSFileSystemAccessor{},
+		`Access Volume Shadow Copies by parsing NTFS structures. Each shadow copy appears as a top level directory.`)
+
+	vql_subsystem.RegisterPlugin(
+		&vfilter.GenericListPlugin{
+			PluginName: "shadow_copies",
+			Function: func(
+				ctx context.Context,
+				scope vfilter.Scope,
+				args *ordereddict.Dict) []vfilter.Row {
+				var result []vfilter.Row
+
+				err := vql_subsystem.CheckAccess(scope, acls.MACHINE_STATE)
+				if err != nil {
+					scope.Log("shadow_copies: %s", err)
+					return result
+				}
+
+				shadow_copies, err := GetShadowCopies()
+				if err != nil {
+					scope.Log("shadow_copies: %v", err)
+					return result
+				}
+
+				for _, row := range shadow_copies {
+					result = append(result, row)
+				}
+				return result
+			},
+			Doc: "List the Volume Shadow Copies on the system. Files " +
+				"within a shadow copy may be accessed using the vss accessor.",
+		})
+}
@@ -0,0 +1,29 @@
+package vss
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// Parse a CIM_DATETIME string as returned by WMI. The format is
+// yyyymmddHHMMSS.mmmmmmsUUU where sUUU is the offset from UTC in
+// minutes.
+// https://docs.microsoft.com/en-us/windows/win32/wmisdk/cim-datetime
+func ParseWMIDateTime(value string) (time.Time, error) {
+	if len(value) != 25 || value[14] != '.' {
+		return time.Time{}, errors.New("Invalid CIM_DATETIME")
+	}
+
+	result, err := time.Parse("20060102150405.000000", value[:21])
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	offset, err := strconv.Atoi(value[21:])
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return result.Add(-time.Duration(offset) * time.Minute).UTC(), nil
+}
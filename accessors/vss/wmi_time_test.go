@@ -0,0 +1,21 @@
+package vss
+
+import (
+	"testing"
+	"time"
+
+	"www.velocidex.com/golang/velociraptor/vtesting/assert"
+)
+
+func TestParseWMIDateTime(t *testing.T) {
+	ts, err := ParseWMIDateTime("20220315103000.123456+060")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2022, 3, 15, 9, 30, 0, 123456000, time.UTC), ts)
+
+	ts, err = ParseWMIDateTime("20220315103000.000000-300")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2022, 3, 15, 15, 30, 0, 0, time.UTC), ts)
+
+	_, err = ParseWMIDateTime("2022")
+	assert.Error(t, err)
+}
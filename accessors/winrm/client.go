@@ -0,0 +1,245 @@
+package winrm
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"www.velocidex.com/golang/velociraptor/accessors"
+	"www.velocidex.com/golang/velociraptor/constants"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/velociraptor/vql/networking"
+	"www.velocidex.com/golang/vfilter"
+)
+
+type WinRMConfig struct {
+	Hostname     string `vfilter:"required,field=hostname,doc=The host to connect to (optionally with a port)"`
+	Username     string `vfilter:"required,field=username,doc=The username to connect with (e.g. DOMAIN\\user)"`
+	Password     string `vfilter:"required,field=password,doc=The password to authenticate with"`
+	Auth         string `vfilter:"optional,field=auth,doc=The authentication method: ntlm (default) or basic"`
+	UseHTTP      bool   `vfilter:"optional,field=use_http,doc=Connect over HTTP (port 5985) instead of HTTPS (port 5986). The server must allow unencrypted connections"`
+	NoVerifyCert bool   `vfilter:"optional,field=noverifycert,doc=Skip TLS Verification"`
+	Timeout      int64  `vfilter:"optional,field=timeout,doc=Timeout in seconds for each request (default 60)"`
+}
+
+// A WS-Management client for a single host.
+type WinRMClient struct {
+	// NTLM authenticates the connection so the handshake and request
+	// must not be interleaved with other requests.
+	mu sync.Mutex
+
+	Hostname string
+	endpoint string
+	username string
+	password string
+	auth     string
+	timeout  time.Duration
+	client   *http.Client
+}
+
+// Post a SOAP message to the endpoint and return the response
+// body. SOAP faults are returned with a 500 status and are parsed by
+// the caller.
+func (self *WinRMClient) Post(ctx context.Context, body []byte) ([]byte, error) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	var resp *http.Response
+	var err error
+
+	switch self.auth {
+	case "basic":
+		resp, err = self.do(ctx, body, func(req *http.Request) {
+			req.SetBasicAuth(self.username, self.password)
+		})
+
+	default:
+		resp, err = self.postNTLM(ctx, body)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusInternalServerError:
+		return data, nil
+
+	case http.StatusUnauthorized:
+		return nil, fmt.Errorf("%v: Authentication failed", self.Hostname)
+	}
+
+	return nil, fmt.Errorf("%v: %v", self.Hostname, resp.Status)
+}
+
+func (self *WinRMClient) do(ctx context.Context, body []byte,
+	authenticate func(req *http.Request)) (*http.Response, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, self.timeout)
+	req, err := http.NewRequestWithContext(
+		ctx, "POST", self.endpoint, bytes.NewReader(body))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/soap+xml;charset=UTF-8")
+	authenticate(req)
+
+	resp, err := self.client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// Release the timeout when the body is closed.
+	resp.Body = &cancelReadCloser{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+type cancelReadCloser struct {
+	io.ReadCloser
+	cancel func()
+}
+
+func (self *cancelReadCloser) Close() error {
+	defer self.cancel()
+	return self.ReadCloser.Close()
+}
+
+func (self *WinRMClient) postNTLM(
+	ctx context.Context, body []byte) (*http.Response, error) {
+
+	negotiate := "Negotiate " + base64.StdEncoding.EncodeToString(
+		negotiateMessage())
+	resp, err := self.do(ctx, nil, func(req *http.Request) {
+		req.Header.Set("Authorization", negotiate)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Drain the body so the connection can be reused for the next
+	// leg of the handshake.
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+
+	var challenge []byte
+	for _, header := range resp.Header.Values("WWW-Authenticate") {
+		if strings.HasPrefix(header, "Negotiate ") {
+			challenge, err = base64.StdEncoding.DecodeString(
+				strings.TrimPrefix(header, "Negotiate "))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if resp.StatusCode != http.StatusUnauthorized || challenge == nil {
+		return nil, fmt.Errorf(
+			"%v: Server did not offer NTLM authentication (%v)",
+			self.Hostname, resp.Status)
+	}
+
+	authenticate, err := authenticateMessage(
+		challenge, self.username, self.password)
+	if err != nil {
+		return nil, err
+	}
+
+	return self.do(ctx, body, func(req *http.Request) {
+		req.Header.Set("Authorization", "Negotiate "+
+			base64.StdEncoding.EncodeToString(authenticate))
+	})
+}
+
+func newClient(config *WinRMConfig) (*WinRMClient, error) {
+	scheme, port := "https", "5986"
+	if config.UseHTTP {
+		scheme, port = "http", "5985"
+	}
+
+	hostname := config.Hostname
+	_, _, err := net.SplitHostPort(hostname)
+	if err != nil {
+		hostname = net.JoinHostPort(hostname, port)
+	}
+
+	switch config.Auth {
+	case "", "ntlm", "basic":
+	default:
+		return nil, fmt.Errorf("Unsupported auth method %v", config.Auth)
+	}
+
+	if config.Timeout == 0 {
+		config.Timeout = 60
+	}
+
+	return &WinRMClient{
+		Hostname: config.Hostname,
+		endpoint: fmt.Sprintf("%s://%s/wsman", scheme, hostname),
+		username: config.Username,
+		password: config.Password,
+		auth:     config.Auth,
+		timeout:  time.Duration(config.Timeout) * time.Second,
+		client: &http.Client{
+			Transport: &http.Transport{
+				Proxy: networking.GetProxy(),
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: config.NoVerifyCert,
+				},
+				// NTLM authenticates the connection so we
+				// always use the same one.
+				MaxConnsPerHost: 1,
+			},
+		},
+	}, nil
+}
+
+// Get a client for the host specified in the WINRM_CONFIG
+// variable. Clients are cached in the root scope so all users in the
+// query share the same connection.
+func GetWinRMClient(ctx context.Context, scope vfilter.Scope) (*WinRMClient, error) {
+	config := &WinRMConfig{}
+	err := accessors.GetAccessorConfig(ctx, scope, constants.WINRM_CONFIG, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Hostname == "" {
+		return nil, errors.New("WINRM_CONFIG must be set to connect over WinRM")
+	}
+
+	cache_key := fmt.Sprintf("winrm %s@%s", config.Username, config.Hostname)
+	switch t := vql_subsystem.CacheGet(scope, cache_key).(type) {
+	case error:
+		return nil, t
+	case *WinRMClient:
+		return t, nil
+	}
+
+	client, err := newClient(config)
+	if err != nil {
+		err = fmt.Errorf("winrm: %v: %w", config.Hostname, err)
+		vql_subsystem.CacheSet(scope, cache_key, err)
+		return nil, err
+	}
+
+	vql_subsystem.CacheSet(scope, cache_key, client)
+	return client, nil
+}
@@ -0,0 +1,14 @@
+// An accessor and executor for Windows hosts reached over WinRM.
+//
+// This allows server artifacts to collect from Windows hosts where a
+// client can not be installed yet. Commands are run in a remote
+// shell using the WS-Management protocol and the winrm accessor
+// reads files by running small PowerShell scripts. Both are
+// configured by setting the WINRM_CONFIG variable to a dict of
+// connection options.
+//
+// We support NTLM and Basic authentication. Message encryption is
+// not implemented so connections should use HTTPS - plain HTTP only
+// works when the server allows unencrypted traffic.
+
+package winrm
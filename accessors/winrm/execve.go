@@ -0,0 +1,179 @@
+package winrm
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Velocidex/ordereddict"
+	"github.com/sirupsen/logrus"
+	"www.velocidex.com/golang/velociraptor/acls"
+	"www.velocidex.com/golang/velociraptor/artifacts"
+	"www.velocidex.com/golang/velociraptor/utils"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/velociraptor/vql/common"
+	"www.velocidex.com/golang/vfilter"
+	"www.velocidex.com/golang/vfilter/arg_parser"
+)
+
+type WinRMExecvePluginArgs struct {
+	Argv       []string `vfilter:"optional,field=argv,doc=Argv to run the command with. The arguments are quoted using the Windows command line rules."`
+	Command    string   `vfilter:"optional,field=command,doc=A raw command line to run through cmd.exe."`
+	PowerShell string   `vfilter:"optional,field=powershell,doc=A PowerShell script to run."`
+	Length     int64    `vfilter:"optional,field=length,doc=Maximum size of stdout and stderr to capture (default 10Mb)."`
+}
+
+// Quote an argument so CommandLineToArgvW() parses it back to the
+// same string (the same rules as syscall.EscapeArg on Windows).
+func escapeArg(arg string) string {
+	if arg == "" {
+		return `""`
+	}
+
+	if !strings.ContainsAny(arg, " \t\"") {
+		return arg
+	}
+
+	result := &strings.Builder{}
+	result.WriteByte('"')
+	slashes := 0
+	for i := 0; i < len(arg); i++ {
+		c := arg[i]
+		switch c {
+		case '\\':
+			slashes++
+		case '"':
+			// Backslashes before a quote must be escaped.
+			for ; slashes > 0; slashes-- {
+				result.WriteByte('\\')
+			}
+			result.WriteByte('\\')
+		default:
+			slashes = 0
+		}
+		result.WriteByte(c)
+	}
+
+	// Backslashes before the closing quote must be escaped.
+	for ; slashes > 0; slashes-- {
+		result.WriteByte('\\')
+	}
+	result.WriteByte('"')
+	return result.String()
+}
+
+type WinRMExecvePlugin struct{}
+
+func (self WinRMExecvePlugin) Call(
+	ctx context.Context,
+	scope vfilter.Scope,
+	args *ordereddict.Dict) <-chan vfilter.Row {
+	output_chan := make(chan vfilter.Row)
+
+	go func() {
+		defer close(output_chan)
+
+		err := vql_subsystem.CheckAccess(scope, acls.EXECVE)
+		if err != nil {
+			scope.Log("winrm_execve: %v", err)
+			return
+		}
+
+		// Check the config if we are allowed to execve at all.
+		config_obj, ok := artifacts.GetConfig(scope)
+		if ok && config_obj.PreventExecve {
+			scope.Log("winrm_execve: Not allowed to execve by configuration.")
+			return
+		}
+
+		arg := &WinRMExecvePluginArgs{}
+		err = arg_parser.ExtractArgsWithContext(ctx, scope, args, arg)
+		if err != nil {
+			scope.Log("winrm_execve: %v", err)
+			return
+		}
+
+		// The command is given to the remote shell as an executable
+		// and a list of already quoted arguments.
+		var command string
+		var command_args []string
+		skip_cmd_shell := true
+		description := ""
+
+		switch {
+		case len(arg.Argv) > 0:
+			command = escapeArg(arg.Argv[0])
+			for _, a := range arg.Argv[1:] {
+				command_args = append(command_args, escapeArg(a))
+			}
+			description = strings.Join(append([]string{command}, command_args...), " ")
+
+		case arg.PowerShell != "":
+			command = "powershell.exe"
+			command_args = powershellArgs(arg.PowerShell)
+			description = "powershell: " + arg.PowerShell
+
+		case arg.Command != "":
+			command = arg.Command
+			skip_cmd_shell = false
+			description = arg.Command
+
+		default:
+			scope.Log("winrm_execve: no command to run")
+			return
+		}
+
+		if arg.Length == 0 {
+			arg.Length = 10 * 1024 * 1024
+		}
+
+		client, err := GetWinRMClient(ctx, scope)
+		if err != nil {
+			scope.Log("winrm_execve: %v", err)
+			return
+		}
+
+		// Report the command we ran for auditing
+		// purposes. This will be collected in the flow logs.
+		scope.Log("winrm_execve: Running external command on %v: %v",
+			client.Hostname, description)
+		vql_subsystem.AuditLog(scope, "winrm_execve", logrus.Fields{
+			"host":    client.Hostname,
+			"command": description,
+		})
+
+		stdout := utils.NewLimitedBuffer(int(arg.Length))
+		stderr := utils.NewLimitedBuffer(int(arg.Length))
+		response := &common.ShellResult{}
+
+		response.ReturnCode, err = client.Run(ctx, command, command_args,
+			skip_cmd_shell, stdout, stderr)
+		if err != nil {
+			scope.Log("winrm_execve: %v", err)
+			response.ReturnCode = -1
+		}
+
+		response.Stdout = stdout.String()
+		response.Stderr = stderr.String()
+		response.Complete = err == nil
+
+		select {
+		case <-ctx.Done():
+		case output_chan <- response:
+		}
+	}()
+
+	return output_chan
+}
+
+func (self WinRMExecvePlugin) Info(
+	scope vfilter.Scope, type_map *vfilter.TypeMap) *vfilter.PluginInfo {
+	return &vfilter.PluginInfo{
+		Name:    "winrm_execve",
+		Doc:     "Run a command on a remote Windows host over WinRM. The connection is configured with the WINRM_CONFIG variable.",
+		ArgType: type_map.AddType(scope, &WinRMExecvePluginArgs{}),
+	}
+}
+
+func init() {
+	vql_subsystem.RegisterPlugin(&WinRMExecvePlugin{})
+}
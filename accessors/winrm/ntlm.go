@@ -0,0 +1,228 @@
+package winrm
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"golang.org/x/crypto/md4"
+)
+
+// A minimal NTLMv2 client (MS-NLMP). We only authenticate the
+// connection - message signing and sealing are not supported so
+// WinRM must be used over HTTPS (or with AllowUnencrypted).
+
+const (
+	NTLMSSP_NEGOTIATE_UNICODE                  = 0x00000001
+	NTLMSSP_REQUEST_TARGET                     = 0x00000004
+	NTLMSSP_NEGOTIATE_NTLM                     = 0x00000200
+	NTLMSSP_NEGOTIATE_ALWAYS_SIGN              = 0x00008000
+	NTLMSSP_NEGOTIATE_EXTENDED_SESSIONSECURITY = 0x00080000
+	NTLMSSP_NEGOTIATE_TARGET_INFO              = 0x00800000
+	NTLMSSP_NEGOTIATE_128                      = 0x20000000
+	NTLMSSP_NEGOTIATE_56                       = 0x80000000
+
+	negotiateFlags = NTLMSSP_NEGOTIATE_UNICODE |
+		NTLMSSP_REQUEST_TARGET |
+		NTLMSSP_NEGOTIATE_NTLM |
+		NTLMSSP_NEGOTIATE_ALWAYS_SIGN |
+		NTLMSSP_NEGOTIATE_EXTENDED_SESSIONSECURITY |
+		NTLMSSP_NEGOTIATE_TARGET_INFO |
+		NTLMSSP_NEGOTIATE_128 |
+		NTLMSSP_NEGOTIATE_56
+
+	msvAvEOL       = 0
+	msvAvTimestamp = 7
+)
+
+var (
+	ntlmSignature = []byte("NTLMSSP\x00")
+)
+
+func toUnicode(s string) []byte {
+	encoded := utf16.Encode([]rune(s))
+	result := make([]byte, 2*len(encoded))
+	for i, c := range encoded {
+		binary.LittleEndian.PutUint16(result[2*i:], c)
+	}
+	return result
+}
+
+func hmacMD5(key []byte, data ...[]byte) []byte {
+	mac := hmac.New(md5.New, key)
+	for _, d := range data {
+		mac.Write(d)
+	}
+	return mac.Sum(nil)
+}
+
+// NTOWFv2 from MS-NLMP 3.3.2
+func ntowfv2(user, password, domain string) []byte {
+	hash := md4.New()
+	hash.Write(toUnicode(password))
+	return hmacMD5(hash.Sum(nil), toUnicode(strings.ToUpper(user)+domain))
+}
+
+// Split a username of the form DOMAIN\user. User principal names
+// (user@domain) are sent as is with an empty domain.
+func splitUsername(username string) (user, domain string) {
+	parts := strings.SplitN(username, "\\", 2)
+	if len(parts) == 2 {
+		return parts[1], parts[0]
+	}
+	return username, ""
+}
+
+func negotiateMessage() []byte {
+	result := make([]byte, 32)
+	copy(result, ntlmSignature)
+	binary.LittleEndian.PutUint32(result[8:], 1)
+	binary.LittleEndian.PutUint32(result[12:], negotiateFlags)
+	return result
+}
+
+type challengeMessage struct {
+	flags           uint32
+	serverChallenge []byte
+	targetInfo      []byte
+}
+
+func readField(message []byte, offset int) ([]byte, error) {
+	if len(message) < offset+8 {
+		return nil, errors.New("NTLM message too short")
+	}
+	length := int(binary.LittleEndian.Uint16(message[offset:]))
+	start := int(binary.LittleEndian.Uint32(message[offset+4:]))
+	if start+length > len(message) {
+		return nil, errors.New("NTLM field out of range")
+	}
+	return message[start : start+length], nil
+}
+
+func parseChallengeMessage(message []byte) (*challengeMessage, error) {
+	if len(message) < 48 || !bytes.Equal(message[:8], ntlmSignature) ||
+		binary.LittleEndian.Uint32(message[8:]) != 2 {
+		return nil, errors.New("Invalid NTLM challenge message")
+	}
+
+	target_info, err := readField(message, 40)
+	if err != nil {
+		return nil, err
+	}
+
+	return &challengeMessage{
+		flags:           binary.LittleEndian.Uint32(message[20:]),
+		serverChallenge: message[24:32],
+		targetInfo:      target_info,
+	}, nil
+}
+
+// Find the server's timestamp in the target info AV pairs.
+func getTimestamp(target_info []byte) []byte {
+	for len(target_info) >= 4 {
+		av_id := binary.LittleEndian.Uint16(target_info)
+		av_len := int(binary.LittleEndian.Uint16(target_info[2:]))
+		if av_id == msvAvEOL || len(target_info) < 4+av_len {
+			break
+		}
+		if av_id == msvAvTimestamp {
+			return target_info[4 : 4+av_len]
+		}
+		target_info = target_info[4+av_len:]
+	}
+	return nil
+}
+
+// Compute the NTLMv2 responses (MS-NLMP 3.3.2).
+func ntlmv2Response(response_key, server_challenge, client_challenge,
+	timestamp, target_info []byte) (nt_response, lm_response []byte) {
+
+	temp := &bytes.Buffer{}
+	temp.Write([]byte{1, 1, 0, 0, 0, 0, 0, 0})
+	temp.Write(timestamp)
+	temp.Write(client_challenge)
+	temp.Write([]byte{0, 0, 0, 0})
+	temp.Write(target_info)
+	temp.Write([]byte{0, 0, 0, 0})
+
+	nt_proof := hmacMD5(response_key, server_challenge, temp.Bytes())
+	nt_response = append(nt_proof, temp.Bytes()...)
+
+	lm_response = append(
+		hmacMD5(response_key, server_challenge, client_challenge),
+		client_challenge...)
+
+	return nt_response, lm_response
+}
+
+// Convert a time to a Windows FILETIME.
+func fileTime(t time.Time) []byte {
+	result := make([]byte, 8)
+	binary.LittleEndian.PutUint64(result,
+		uint64(t.UnixNano()/100+116444736000000000))
+	return result
+}
+
+func authenticateMessage(challenge_message []byte,
+	username, password string) ([]byte, error) {
+	challenge, err := parseChallengeMessage(challenge_message)
+	if err != nil {
+		return nil, err
+	}
+
+	user, domain := splitUsername(username)
+
+	client_challenge := make([]byte, 8)
+	_, err = rand.Read(client_challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	// When the server provides a timestamp we must use it and the
+	// LM response is not sent.
+	timestamp := getTimestamp(challenge.targetInfo)
+	server_timestamp := timestamp != nil
+	if !server_timestamp {
+		timestamp = fileTime(time.Now())
+	}
+
+	nt_response, lm_response := ntlmv2Response(
+		ntowfv2(user, password, domain),
+		challenge.serverChallenge, client_challenge,
+		timestamp, challenge.targetInfo)
+	if server_timestamp {
+		lm_response = make([]byte, 24)
+	}
+
+	fields := [][]byte{
+		lm_response,
+		nt_response,
+		toUnicode(domain),
+		toUnicode(user),
+		toUnicode(""), // Workstation
+		nil,           // EncryptedRandomSessionKey
+	}
+
+	header := make([]byte, 64)
+	copy(header, ntlmSignature)
+	binary.LittleEndian.PutUint32(header[8:], 3)
+
+	payload := &bytes.Buffer{}
+	for i, field := range fields {
+		offset := 12 + i*8
+		binary.LittleEndian.PutUint16(header[offset:], uint16(len(field)))
+		binary.LittleEndian.PutUint16(header[offset+2:], uint16(len(field)))
+		binary.LittleEndian.PutUint32(header[offset+4:],
+			uint32(len(header)+payload.Len()))
+		payload.Write(field)
+	}
+	binary.LittleEndian.PutUint32(header[60:], challenge.flags&negotiateFlags)
+
+	return append(header, payload.Bytes()...), nil
+}
@@ -0,0 +1,71 @@
+package winrm
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func unhex(s string) []byte {
+	result, _ := hex.DecodeString(s)
+	return result
+}
+
+// Test vectors from MS-NLMP 4.2.4 (NTLMv2 Authentication)
+func TestNTLMv2(t *testing.T) {
+	response_key := ntowfv2("User", "Password", "Domain")
+	assert.Equal(t, "0c868a403bfd7a93a3001ef22ef02e3f",
+		hex.EncodeToString(response_key))
+
+	target_info := unhex("02000c0044006f006d00610069006e00" +
+		"01000c005300650072007600650072000000" + "0000")
+
+	nt_response, lm_response := ntlmv2Response(response_key,
+		unhex("0123456789abcdef"), unhex("aaaaaaaaaaaaaaaa"),
+		make([]byte, 8), target_info)
+
+	assert.Equal(t, "86c35097ac9cec102554764a57cccc19aaaaaaaaaaaaaaaa",
+		hex.EncodeToString(lm_response))
+	assert.Equal(t, "68cd0ab851e51c96aabc927bebef6a1c",
+		hex.EncodeToString(nt_response[:16]))
+}
+
+func TestNTLMMessages(t *testing.T) {
+	// A challenge with a timestamp in the target info.
+	target_info := unhex("02000c0044006f006d00610069006e00" +
+		"070008000102030405060708" + "00000000")
+
+	challenge := make([]byte, 48)
+	copy(challenge, ntlmSignature)
+	challenge[8] = 2
+	copy(challenge[20:], []byte{0x05, 0x82, 0x89, 0xa2})
+	copy(challenge[24:], unhex("0123456789abcdef"))
+	challenge[40] = byte(len(target_info))
+	challenge[42] = byte(len(target_info))
+	challenge[44] = 48
+	challenge = append(challenge, target_info...)
+
+	message, err := authenticateMessage(challenge, `DOMAIN\user`, "password")
+	require.NoError(t, err)
+
+	// The LM response is empty when the server sends a timestamp.
+	lm, err := readField(message, 12)
+	require.NoError(t, err)
+	assert.Equal(t, make([]byte, 24), lm)
+
+	// The NT response echoes the server timestamp and target info.
+	nt, err := readField(message, 20)
+	require.NoError(t, err)
+	assert.Equal(t, unhex("0102030405060708"), nt[24:32])
+	assert.Equal(t, target_info, nt[44:44+len(target_info)])
+
+	domain, err := readField(message, 28)
+	require.NoError(t, err)
+	assert.Equal(t, toUnicode("DOMAIN"), domain)
+
+	user, err := readField(message, 36)
+	require.NoError(t, err)
+	assert.Equal(t, toUnicode("user"), user)
+}
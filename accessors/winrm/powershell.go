@@ -0,0 +1,129 @@
+package winrm
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Exit codes used by our scripts to report errors.
+const (
+	psExitError    = 1
+	psExitNotFound = 2
+)
+
+// Quote a string for PowerShell. PowerShell also treats the unicode
+// single quotation marks as quotes so they must be doubled too.
+func psQuote(s string) string {
+	result := &strings.Builder{}
+	result.WriteString("'")
+	for _, c := range s {
+		switch c {
+		case '\'', '‘', '’', '‚', '‛':
+			result.WriteRune(c)
+		}
+		result.WriteRune(c)
+	}
+	result.WriteString("'")
+	return result.String()
+}
+
+// The arguments to run an encoded PowerShell script.
+func powershellArgs(script string) []string {
+	return []string{"-NoProfile", "-NonInteractive",
+		"-ExecutionPolicy", "Bypass", "-EncodedCommand",
+		base64.StdEncoding.EncodeToString(toUnicode(
+			"$ProgressPreference='SilentlyContinue'\n" + script))}
+}
+
+// Run a script which reports errors using our exit codes and return
+// its output.
+func (self *WinRMClient) runScript(
+	ctx context.Context, script string) ([]byte, error) {
+	script = `try {
+` + script + `
+} catch [System.Management.Automation.ItemNotFoundException],
+        [System.IO.FileNotFoundException],
+        [System.IO.DirectoryNotFoundException] {
+  [Console]::Error.WriteLine($_.Exception.Message)
+  exit 2
+} catch {
+  [Console]::Error.WriteLine($_.Exception.Message)
+  exit 1
+}`
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	exit_code, err := self.Run(ctx, "powershell.exe",
+		powershellArgs(script), true, stdout, stderr)
+	if err != nil {
+		return nil, err
+	}
+
+	message := strings.TrimSpace(stderr.String())
+	switch exit_code {
+	case 0:
+		return stdout.Bytes(), nil
+
+	case psExitNotFound:
+		return nil, fmt.Errorf("%v: %w", message, os.ErrNotExist)
+
+	default:
+		return nil, fmt.Errorf("%v: PowerShell failed (%v): %v",
+			self.Hostname, exit_code, message)
+	}
+}
+
+// Produce a JSON object describing a file or directory.
+const psInfoFunction = `function Info($i) {
+  [ordered]@{
+    Name=$i.Name;
+    IsDir=$i.PSIsContainer;
+    Size=$(if ($i.PSIsContainer) {0} else {$i.Length});
+    Mtime=$i.LastWriteTimeUtc.ToString('o');
+    Atime=$i.LastAccessTimeUtc.ToString('o');
+    Btime=$i.CreationTimeUtc.ToString('o');
+    Attributes=$i.Attributes.ToString();
+    IsLink=[bool]($i.Attributes -band [IO.FileAttributes]::ReparsePoint)
+  }
+}
+`
+
+func listDrivesScript() string {
+	return `$r = @(Get-PSDrive -PSProvider FileSystem | ForEach-Object {
+  [ordered]@{Name=$_.Name + ':'; IsDir=$true}
+})
+ConvertTo-Json -Compress -InputObject $r`
+}
+
+func listDirectoryScript(path string) string {
+	return psInfoFunction + fmt.Sprintf(`$r = @(Get-ChildItem -Force -ErrorAction Stop -LiteralPath %s |
+  ForEach-Object { Info $_ })
+ConvertTo-Json -Compress -InputObject $r`, psQuote(path))
+}
+
+func statScript(path string) string {
+	return psInfoFunction + fmt.Sprintf(
+		`ConvertTo-Json -Compress -InputObject (Info (Get-Item -Force -ErrorAction Stop -LiteralPath %s))`,
+		psQuote(path))
+}
+
+func readScript(path string, offset, length int64) string {
+	return fmt.Sprintf(`$f = [IO.File]::Open(%s, 'Open', 'Read', 'ReadWrite,Delete')
+try {
+  $f.Seek(%d, 'Begin') | Out-Null
+  $b = New-Object byte[] %d
+  $n = 0
+  while ($n -lt $b.Length) {
+    $r = $f.Read($b, $n, $b.Length - $n)
+    if ($r -eq 0) { break }
+    $n += $r
+  }
+  [Console]::Out.Write([Convert]::ToBase64String($b, 0, $n))
+} finally {
+  $f.Close()
+}`, psQuote(path), offset, length)
+}
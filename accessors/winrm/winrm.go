@@ -0,0 +1,288 @@
+package winrm
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	"www.velocidex.com/golang/velociraptor/accessors"
+	"www.velocidex.com/golang/velociraptor/acls"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/vfilter"
+)
+
+const (
+	// Files are read in chunks with a separate command each.
+	readChunkSize = 1024 * 1024
+)
+
+type psFileInfo struct {
+	Name       string
+	IsDir      bool
+	Size       int64
+	Mtime      string
+	Atime      string
+	Btime      string
+	Attributes string
+	IsLink     bool
+}
+
+func parseTime(value string) time.Time {
+	result, _ := time.Parse(time.RFC3339Nano, value)
+	return result
+}
+
+func (self *psFileInfo) fileInfo(
+	full_path *accessors.OSPath) accessors.FileInfo {
+	return &accessors.VirtualFileInfo{
+		Path:   full_path,
+		IsDir_: self.IsDir,
+		Size_:  self.Size,
+		Mtime_: parseTime(self.Mtime),
+		Atime_: parseTime(self.Atime),
+		Btime_: parseTime(self.Btime),
+		Data_: ordereddict.NewDict().
+			Set("Attributes", self.Attributes).
+			Set("IsLink", self.IsLink),
+	}
+}
+
+// The path on the remote host.
+func remotePath(full_path *accessors.OSPath) string {
+	result := strings.Join(full_path.Components, "\\")
+
+	// Drive roots need a trailing \ (C: is the current directory
+	// on the drive).
+	if len(full_path.Components) == 1 {
+		result += "\\"
+	}
+	return result
+}
+
+// Access files on a remote Windows host using PowerShell over WinRM.
+type WinRMFileSystemAccessor struct {
+	root   *accessors.OSPath
+	ctx    context.Context
+	client *WinRMClient
+}
+
+func (self *WinRMFileSystemAccessor) New(
+	scope vfilter.Scope) (accessors.FileSystemAccessor, error) {
+
+	err := vql_subsystem.CheckAccess(scope, acls.FILESYSTEM_READ)
+	if err != nil {
+		return nil, err
+	}
+
+	// Abort outstanding requests when the query is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	err = vql_subsystem.GetRootScope(scope).AddDestructor(cancel)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	client, err := GetWinRMClient(ctx, scope)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return &WinRMFileSystemAccessor{
+		root:   self.root,
+		ctx:    ctx,
+		client: client,
+	}, nil
+}
+
+func (self *WinRMFileSystemAccessor) ParsePath(
+	path string) (*accessors.OSPath, error) {
+	return self.root.Parse(path)
+}
+
+func (self *WinRMFileSystemAccessor) ReadDir(
+	path string) ([]accessors.FileInfo, error) {
+	full_path, err := self.ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return self.ReadDirWithOSPath(full_path)
+}
+
+func (self *WinRMFileSystemAccessor) ReadDirWithOSPath(
+	full_path *accessors.OSPath) ([]accessors.FileInfo, error) {
+
+	// The top level lists the drives.
+	script := listDrivesScript()
+	if len(full_path.Components) > 0 {
+		script = listDirectoryScript(remotePath(full_path))
+	}
+
+	output, err := self.client.runScript(self.ctx, script)
+	if err != nil {
+		return nil, err
+	}
+
+	var children []*psFileInfo
+	err = json.Unmarshal(output, &children)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]accessors.FileInfo, 0, len(children))
+	for _, child := range children {
+		result = append(result, child.fileInfo(full_path.Append(child.Name)))
+	}
+	return result, nil
+}
+
+func (self *WinRMFileSystemAccessor) Lstat(
+	filename string) (accessors.FileInfo, error) {
+	full_path, err := self.ParsePath(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return self.LstatWithOSPath(full_path)
+}
+
+func (self *WinRMFileSystemAccessor) LstatWithOSPath(
+	full_path *accessors.OSPath) (accessors.FileInfo, error) {
+
+	if len(full_path.Components) == 0 {
+		return &accessors.VirtualFileInfo{
+			Path:   full_path,
+			IsDir_: true,
+		}, nil
+	}
+
+	output, err := self.client.runScript(self.ctx,
+		statScript(remotePath(full_path)))
+	if err != nil {
+		return nil, err
+	}
+
+	info := &psFileInfo{}
+	err = json.Unmarshal(output, info)
+	if err != nil {
+		return nil, err
+	}
+
+	return info.fileInfo(full_path), nil
+}
+
+func (self *WinRMFileSystemAccessor) Open(
+	path string) (accessors.ReadSeekCloser, error) {
+	full_path, err := self.ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return self.OpenWithOSPath(full_path)
+}
+
+func (self *WinRMFileSystemAccessor) OpenWithOSPath(
+	full_path *accessors.OSPath) (accessors.ReadSeekCloser, error) {
+
+	stat, err := self.LstatWithOSPath(full_path)
+	if err != nil {
+		return nil, err
+	}
+
+	if stat.IsDir() {
+		return nil, errors.New("Can not open a directory")
+	}
+
+	return &fileReader{
+		ctx:    self.ctx,
+		client: self.client,
+		path:   remotePath(full_path),
+		size:   stat.Size(),
+	}, nil
+}
+
+// Read a remote file in chunks. We keep the last chunk so small
+// sequential reads do not each need a round trip.
+type fileReader struct {
+	ctx    context.Context
+	client *WinRMClient
+	path   string
+	size   int64
+	offset int64
+
+	chunk        []byte
+	chunk_offset int64
+}
+
+func (self *fileReader) Read(buf []byte) (int, error) {
+	if self.offset >= self.size {
+		return 0, io.EOF
+	}
+
+	if self.offset < self.chunk_offset ||
+		self.offset >= self.chunk_offset+int64(len(self.chunk)) {
+		length := int64(readChunkSize)
+		if int64(len(buf)) > length {
+			length = int64(len(buf))
+		}
+		if self.offset+length > self.size {
+			length = self.size - self.offset
+		}
+
+		output, err := self.client.runScript(self.ctx,
+			readScript(self.path, self.offset, length))
+		if err != nil {
+			return 0, err
+		}
+
+		self.chunk, err = base64.StdEncoding.DecodeString(string(output))
+		if err != nil {
+			return 0, err
+		}
+		self.chunk_offset = self.offset
+
+		// The file was truncated.
+		if len(self.chunk) == 0 {
+			return 0, io.EOF
+		}
+	}
+
+	n := copy(buf, self.chunk[self.offset-self.chunk_offset:])
+	self.offset += int64(n)
+	return n, nil
+}
+
+func (self *fileReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case os.SEEK_SET:
+	case os.SEEK_CUR:
+		offset += self.offset
+	case os.SEEK_END:
+		offset += self.size
+	default:
+		return 0, errors.New("Invalid whence")
+	}
+
+	if offset < 0 {
+		return 0, errors.New("Negative seek")
+	}
+	self.offset = offset
+	return offset, nil
+}
+
+func (self *fileReader) Close() error {
+	return nil
+}
+
+func init() {
+	root_path, _ := accessors.NewWindowsOSPath("")
+	accessors.Register("winrm", &WinRMFileSystemAccessor{root: root_path},
+		`Access files on a remote Windows host over WinRM. The connection is configured with the WINRM_CONFIG variable.`)
+}
@@ -0,0 +1,367 @@
+package winrm
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/Velocidex/ordereddict"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"www.velocidex.com/golang/velociraptor/accessors"
+	"www.velocidex.com/golang/velociraptor/constants"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/velociraptor/vql/common"
+	"www.velocidex.com/golang/vfilter"
+)
+
+type fakeRequest struct {
+	Header struct {
+		Action string `xml:"Action"`
+	} `xml:"Header"`
+	Body struct {
+		CommandLine struct {
+			Command   string   `xml:"Command"`
+			Arguments []string `xml:"Arguments"`
+		} `xml:"CommandLine"`
+		Receive struct {
+			DesiredStream struct {
+				CommandId string `xml:"CommandId,attr"`
+			} `xml:"DesiredStream"`
+		} `xml:"Receive"`
+	} `xml:"Body"`
+}
+
+type fakeResult struct {
+	stdout, stderr string
+	exit_code      int
+}
+
+// A minimal WinRM server which runs commands using a handler.
+type fakeServer struct {
+	mu       sync.Mutex
+	auth     string
+	handler  func(command string, args []string) fakeResult
+	results  map[string]fakeResult
+	shells   int
+	timeouts int
+}
+
+func (self *fakeServer) authenticate(
+	w http.ResponseWriter, r *http.Request) bool {
+	switch self.auth {
+	case "basic":
+		user, pass, ok := r.BasicAuth()
+		if ok && user == `DOMAIN\test` && pass == "secret" {
+			return true
+		}
+
+	case "ntlm":
+		header := r.Header.Get("Authorization")
+		message, _ := base64.StdEncoding.DecodeString(
+			strings.TrimPrefix(header, "Negotiate "))
+
+		switch {
+		case len(message) > 8 && message[8] == 1:
+			challenge := make([]byte, 48)
+			copy(challenge, ntlmSignature)
+			challenge[8] = 2
+			copy(challenge[20:], []byte{0x05, 0x82, 0x89, 0xa2})
+			copy(challenge[24:], unhex("0123456789abcdef"))
+			challenge[44] = 48
+
+			w.Header().Set("WWW-Authenticate", "Negotiate "+
+				base64.StdEncoding.EncodeToString(challenge))
+			w.WriteHeader(http.StatusUnauthorized)
+			return false
+
+		case len(message) > 8 && message[8] == 3:
+			// Check the NTProofStr against the password.
+			nt, err := readField(message, 20)
+			if err == nil && len(nt) > 16 {
+				key := ntowfv2("test", "secret", "DOMAIN")
+				if bytes.Equal(nt[:16], hmacMD5(
+					key, unhex("0123456789abcdef"), nt[16:])) {
+					return true
+				}
+			}
+		}
+	}
+
+	w.WriteHeader(http.StatusUnauthorized)
+	return false
+}
+
+func (self *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !self.authenticate(w, r) {
+		return
+	}
+
+	data, _ := ioutil.ReadAll(r.Body)
+	request := &fakeRequest{}
+	err := xml.Unmarshal(data, request)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	body := ""
+	switch request.Header.Action {
+	case actionCreate:
+		self.shells++
+		body = `<rsp:Shell><rsp:ShellId>SHELL</rsp:ShellId></rsp:Shell>`
+
+	case actionCommand:
+		id := fmt.Sprintf("CMD%d", len(self.results))
+		self.results[id] = self.handler(request.Body.CommandLine.Command,
+			request.Body.CommandLine.Arguments)
+		body = `<rsp:CommandResponse><rsp:CommandId>` + id +
+			`</rsp:CommandId></rsp:CommandResponse>`
+
+	case actionReceive:
+		// Time out the first receive to check we retry.
+		if self.timeouts == 0 {
+			self.timeouts++
+			w.WriteHeader(http.StatusInternalServerError)
+			writeEnvelope(w, `<s:Fault><s:Code><s:Subcode><s:Value>w:TimedOut</s:Value></s:Subcode></s:Code>`+
+				`<s:Reason><s:Text>The operation timed out</s:Text></s:Reason>`+
+				`<s:Detail><f:WSManFault xmlns:f="http://schemas.microsoft.com/wbem/wsman/1/wsmanfault" Code="2150858793">`+
+				`<f:Message>Timed out</f:Message></f:WSManFault></s:Detail></s:Fault>`)
+			return
+		}
+
+		id := request.Body.Receive.DesiredStream.CommandId
+		result := self.results[id]
+		body = fmt.Sprintf(`<rsp:ReceiveResponse>`+
+			`<rsp:Stream Name="stdout" CommandId="%s">%s</rsp:Stream>`+
+			`<rsp:Stream Name="stderr" CommandId="%s">%s</rsp:Stream>`+
+			`<rsp:CommandState CommandId="%s" State="%s">`+
+			`<rsp:ExitCode>%d</rsp:ExitCode></rsp:CommandState>`+
+			`</rsp:ReceiveResponse>`,
+			id, base64.StdEncoding.EncodeToString([]byte(result.stdout)),
+			id, base64.StdEncoding.EncodeToString([]byte(result.stderr)),
+			id, commandStateDone, result.exit_code)
+
+	case actionDelete:
+		self.shells--
+	}
+
+	writeEnvelope(w, body)
+}
+
+func writeEnvelope(w http.ResponseWriter, body string) {
+	fmt.Fprintf(w, `<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope" `+
+		`xmlns:w="http://schemas.dmtf.org/wbem/wsman/1/wsman.xsd" `+
+		`xmlns:rsp="http://schemas.microsoft.com/wbem/wsman/1/windows/shell">`+
+		`<s:Header/><s:Body>%s</s:Body></s:Envelope>`, body)
+}
+
+func startServer(t *testing.T, auth string,
+	handler func(command string, args []string) fakeResult) (
+	*fakeServer, string) {
+	server := &fakeServer{
+		auth:    auth,
+		handler: handler,
+		results: make(map[string]fakeResult),
+	}
+	http_server := httptest.NewServer(server)
+	t.Cleanup(http_server.Close)
+
+	return server, strings.TrimPrefix(http_server.URL, "http://")
+}
+
+func makeScope(t *testing.T, config *ordereddict.Dict) vfilter.Scope {
+	scope := vql_subsystem.MakeScope().AppendVars(ordereddict.NewDict().
+		Set(vql_subsystem.ACL_MANAGER_VAR, vql_subsystem.NullACLManager{}).
+		Set(constants.WINRM_CONFIG, config))
+	t.Cleanup(func() { scope.Close() })
+	return scope
+}
+
+func echoHandler(command string, args []string) fakeResult {
+	if command == "fail" {
+		return fakeResult{stderr: "failed", exit_code: 3}
+	}
+	return fakeResult{stdout: strings.Join(append([]string{command}, args...), "|")}
+}
+
+func TestWinRMExecve(t *testing.T) {
+	for _, auth := range []string{"basic", "ntlm"} {
+		server, address := startServer(t, auth, echoHandler)
+
+		scope := makeScope(t, ordereddict.NewDict().
+			Set("hostname", address).
+			Set("username", `DOMAIN\test`).
+			Set("password", "secret").
+			Set("auth", auth).
+			Set("use_http", true))
+
+		var rows []vfilter.Row
+		for row := range (WinRMExecvePlugin{}).Call(context.Background(),
+			scope, ordereddict.NewDict().
+				Set("argv", []string{"cmd", "/c", "echo a b"})) {
+			rows = append(rows, row)
+		}
+
+		assert.Equal(t, []vfilter.Row{&common.ShellResult{
+			Stdout:   `cmd|/c|"echo a b"`,
+			Complete: true,
+		}}, rows, auth)
+
+		rows = nil
+		for row := range (WinRMExecvePlugin{}).Call(context.Background(),
+			scope, ordereddict.NewDict().Set("command", "fail")) {
+			rows = append(rows, row)
+		}
+
+		assert.Equal(t, []vfilter.Row{&common.ShellResult{
+			Stderr:     "failed",
+			ReturnCode: 3,
+			Complete:   true,
+		}}, rows, auth)
+
+		// All shells are cleaned up.
+		assert.Equal(t, 0, server.shells)
+	}
+}
+
+func TestWinRMBadPassword(t *testing.T) {
+	for _, auth := range []string{"basic", "ntlm"} {
+		_, address := startServer(t, auth, echoHandler)
+
+		scope := makeScope(t, ordereddict.NewDict().
+			Set("hostname", address).
+			Set("username", `DOMAIN\test`).
+			Set("password", "wrong").
+			Set("auth", auth).
+			Set("use_http", true))
+
+		client, err := GetWinRMClient(context.Background(), scope)
+		require.NoError(t, err)
+
+		_, err = client.Run(context.Background(), "cmd", nil, true,
+			&bytes.Buffer{}, &bytes.Buffer{})
+		assert.Error(t, err, auth)
+	}
+}
+
+func TestEscapeArg(t *testing.T) {
+	for _, test := range []struct{ in, out string }{
+		{"", `""`},
+		{`C:\Windows`, `C:\Windows`},
+		{`C:\Program Files\`, `"C:\Program Files\\"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{`a\"b c`, `"a\\\"b c"`},
+	} {
+		assert.Equal(t, test.out, escapeArg(test.in))
+	}
+
+	assert.Equal(t, `'it''s'`, psQuote("it's"))
+	assert.Equal(t, "'it’’s'", psQuote("it’s"))
+}
+
+// Decode the script from the PowerShell command line.
+func decodeScript(args []string) string {
+	data, _ := base64.StdEncoding.DecodeString(args[len(args)-1])
+	u16 := make([]uint16, len(data)/2)
+	for i := range u16 {
+		u16[i] = binary.LittleEndian.Uint16(data[i*2:])
+	}
+	return string(utf16.Decode(u16))
+}
+
+// Emulate the scripts used by the accessor.
+func powershellHandler(command string, args []string) fakeResult {
+	if command != "powershell.exe" {
+		return fakeResult{exit_code: 1}
+	}
+	script := decodeScript(args)
+	content := "Hello world"
+
+	switch {
+	case strings.Contains(script, "Get-PSDrive"):
+		return fakeResult{stdout: `[{"Name":"C:","IsDir":true}]`}
+
+	case strings.Contains(script, `Get-ChildItem -Force -ErrorAction Stop -LiteralPath 'C:\Windows'`):
+		return fakeResult{stdout: `[{"Name":"hello.txt","IsDir":false,"Size":11,` +
+			`"Mtime":"2022-01-02T03:04:05.0000000Z","Attributes":"Archive"},` +
+			`{"Name":"System32","IsDir":true,"Size":0,"Attributes":"Directory"}]`}
+
+	case strings.Contains(script, `Get-Item -Force -ErrorAction Stop -LiteralPath 'C:\Windows\hello.txt'`):
+		return fakeResult{stdout: `{"Name":"hello.txt","IsDir":false,"Size":11}`}
+
+	case strings.Contains(script, `[IO.File]::Open('C:\Windows\hello.txt'`):
+		var offset, length int
+		fmt.Sscanf(script[strings.Index(script, "$f.Seek("):], "$f.Seek(%d", &offset)
+		fmt.Sscanf(script[strings.Index(script, "New-Object byte[] "):],
+			"New-Object byte[] %d", &length)
+		end := offset + length
+		if end > len(content) {
+			end = len(content)
+		}
+		if offset > end {
+			offset = end
+		}
+		return fakeResult{stdout: base64.StdEncoding.EncodeToString(
+			[]byte(content[offset:end]))}
+	}
+
+	return fakeResult{stderr: "Cannot find path", exit_code: psExitNotFound}
+}
+
+func TestWinRMAccessor(t *testing.T) {
+	_, address := startServer(t, "basic", powershellHandler)
+
+	scope := makeScope(t, ordereddict.NewDict().
+		Set("hostname", address).
+		Set("username", `DOMAIN\test`).
+		Set("password", "secret").
+		Set("auth", "basic").
+		Set("use_http", true))
+
+	accessor, err := accessors.GetAccessor("winrm", scope)
+	require.NoError(t, err)
+
+	children, err := accessor.ReadDir("")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(children))
+	assert.Equal(t, "C:", children[0].Name())
+	assert.True(t, children[0].IsDir())
+
+	children, err = accessor.ReadDir(`C:\Windows`)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(children))
+	assert.Equal(t, `C:\Windows\hello.txt`, children[0].OSPath().String())
+	assert.Equal(t, int64(11), children[0].Size())
+	assert.Equal(t, int64(1641092645), children[0].Mtime().Unix())
+	assert.True(t, children[1].IsDir())
+
+	fd, err := accessor.Open(`C:\Windows\hello.txt`)
+	require.NoError(t, err)
+	defer fd.Close()
+
+	_, err = fd.Seek(6, os.SEEK_SET)
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadAll(fd)
+	require.NoError(t, err)
+	assert.Equal(t, "world", string(data))
+
+	_, err = accessor.Lstat(`C:\Windows\missing`)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
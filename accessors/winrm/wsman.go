@@ -0,0 +1,251 @@
+package winrm
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The subset of the WS-Management Remote Shell protocol (MS-WSMV)
+// needed to run commands.
+
+const (
+	actionCreate  = "http://schemas.xmlsoap.org/ws/2004/09/transfer/Create"
+	actionDelete  = "http://schemas.xmlsoap.org/ws/2004/09/transfer/Delete"
+	actionCommand = "http://schemas.microsoft.com/wbem/wsman/1/windows/shell/Command"
+	actionReceive = "http://schemas.microsoft.com/wbem/wsman/1/windows/shell/Receive"
+	actionSignal  = "http://schemas.microsoft.com/wbem/wsman/1/windows/shell/Signal"
+
+	resourceURICmd = "http://schemas.microsoft.com/wbem/wsman/1/windows/shell/cmd"
+
+	commandStateDone = "http://schemas.microsoft.com/wbem/wsman/1/windows/shell/CommandState/Done"
+	signalTerminate  = "http://schemas.microsoft.com/wbem/wsman/1/windows/shell/signal/terminate"
+
+	// Receive returns this fault when there is no output within the
+	// operation timeout. We just try again.
+	faultTimedOut = "2150858793"
+)
+
+type wsmanFault struct {
+	Code   string `xml:"Code>Subcode>Value"`
+	Reason string `xml:"Reason>Text"`
+	Detail struct {
+		WSManFault struct {
+			Code    string `xml:"Code,attr"`
+			Message string `xml:"Message"`
+		} `xml:"WSManFault"`
+	} `xml:"Detail"`
+}
+
+func (self *wsmanFault) Error() string {
+	message := strings.TrimSpace(self.Reason)
+	if message == "" {
+		message = strings.TrimSpace(self.Detail.WSManFault.Message)
+	}
+	return fmt.Sprintf("WinRM fault %v: %v", self.Code, message)
+}
+
+type wsmanStream struct {
+	Name      string `xml:"Name,attr"`
+	CommandId string `xml:"CommandId,attr"`
+	End       bool   `xml:"End,attr"`
+	Data      string `xml:",chardata"`
+}
+
+type wsmanEnvelope struct {
+	Body struct {
+		Fault     *wsmanFault `xml:"Fault"`
+		ShellId   string      `xml:"Shell>ShellId"`
+		CommandId string      `xml:"CommandResponse>CommandId"`
+		Receive   struct {
+			Streams      []wsmanStream `xml:"Stream"`
+			CommandState struct {
+				State    string `xml:"State,attr"`
+				ExitCode int64  `xml:"ExitCode"`
+			} `xml:"CommandState"`
+		} `xml:"ReceiveResponse"`
+	} `xml:"Body"`
+}
+
+type wsmanOption struct {
+	Name, Value string
+}
+
+func escape(s string) string {
+	buf := &bytes.Buffer{}
+	_ = xml.EscapeText(buf, []byte(s))
+	return buf.String()
+}
+
+func (self *WinRMClient) call(ctx context.Context,
+	action, shell_id string, options []wsmanOption, body string) (
+	*wsmanEnvelope, error) {
+
+	message := &strings.Builder{}
+	fmt.Fprintf(message, `<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope" `+
+		`xmlns:a="http://schemas.xmlsoap.org/ws/2004/08/addressing" `+
+		`xmlns:w="http://schemas.dmtf.org/wbem/wsman/1/wsman.xsd" `+
+		`xmlns:rsp="http://schemas.microsoft.com/wbem/wsman/1/windows/shell">`+
+		`<s:Header>`+
+		`<a:To>%s</a:To>`+
+		`<a:ReplyTo><a:Address s:mustUnderstand="true">http://schemas.xmlsoap.org/ws/2004/08/addressing/role/anonymous</a:Address></a:ReplyTo>`+
+		`<w:MaxEnvelopeSize s:mustUnderstand="true">153600</w:MaxEnvelopeSize>`+
+		`<a:MessageID>uuid:%s</a:MessageID>`+
+		`<w:Locale xml:lang="en-US" s:mustUnderstand="false"/>`+
+		`<w:OperationTimeout>PT20S</w:OperationTimeout>`+
+		`<w:ResourceURI s:mustUnderstand="true">%s</w:ResourceURI>`+
+		`<a:Action s:mustUnderstand="true">%s</a:Action>`,
+		escape(self.endpoint), uuid.New().String(), resourceURICmd, action)
+
+	if shell_id != "" {
+		fmt.Fprintf(message, `<w:SelectorSet><w:Selector Name="ShellId">%s</w:Selector></w:SelectorSet>`,
+			escape(shell_id))
+	}
+
+	if len(options) > 0 {
+		message.WriteString(`<w:OptionSet>`)
+		for _, option := range options {
+			fmt.Fprintf(message, `<w:Option Name="%s">%s</w:Option>`,
+				option.Name, option.Value)
+		}
+		message.WriteString(`</w:OptionSet>`)
+	}
+
+	fmt.Fprintf(message, `</s:Header><s:Body>%s</s:Body></s:Envelope>`, body)
+
+	response, err := self.Post(ctx, []byte(message.String()))
+	if err != nil {
+		return nil, err
+	}
+
+	envelope := &wsmanEnvelope{}
+	err = xml.Unmarshal(response, envelope)
+	if err != nil {
+		return nil, fmt.Errorf("WinRM: invalid response: %w", err)
+	}
+
+	if envelope.Body.Fault != nil {
+		return nil, envelope.Body.Fault
+	}
+
+	return envelope, nil
+}
+
+func (self *WinRMClient) createShell(ctx context.Context) (string, error) {
+	envelope, err := self.call(ctx, actionCreate, "", []wsmanOption{
+		{"WINRS_NOPROFILE", "TRUE"},
+		{"WINRS_CODEPAGE", "65001"},
+	}, `<rsp:Shell><rsp:InputStreams>stdin</rsp:InputStreams>`+
+		`<rsp:OutputStreams>stdout stderr</rsp:OutputStreams></rsp:Shell>`)
+	if err != nil {
+		return "", err
+	}
+
+	if envelope.Body.ShellId == "" {
+		return "", errors.New("WinRM: no shell id in response")
+	}
+	return envelope.Body.ShellId, nil
+}
+
+func (self *WinRMClient) deleteShell(shell_id string) {
+	// The query may already be cancelled so use our own context.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, _ = self.call(ctx, actionDelete, shell_id, nil, "")
+}
+
+// Run a command on the remote host, copying its output to stdout and
+// stderr, and return its exit code. If skip_cmd_shell is set the
+// command is run directly rather than through cmd.exe.
+func (self *WinRMClient) Run(ctx context.Context,
+	command string, args []string, skip_cmd_shell bool,
+	stdout, stderr io.Writer) (int64, error) {
+
+	shell_id, err := self.createShell(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer self.deleteShell(shell_id)
+
+	skip := "FALSE"
+	if skip_cmd_shell {
+		skip = "TRUE"
+	}
+
+	command_line := &strings.Builder{}
+	fmt.Fprintf(command_line, `<rsp:CommandLine><rsp:Command>%s</rsp:Command>`,
+		escape(command))
+	for _, arg := range args {
+		fmt.Fprintf(command_line, `<rsp:Arguments>%s</rsp:Arguments>`,
+			escape(arg))
+	}
+	command_line.WriteString(`</rsp:CommandLine>`)
+
+	envelope, err := self.call(ctx, actionCommand, shell_id, []wsmanOption{
+		{"WINRS_CONSOLEMODE_STDIN", "TRUE"},
+		{"WINRS_SKIP_CMD_SHELL", skip},
+	}, command_line.String())
+	if err != nil {
+		return 0, err
+	}
+
+	command_id := envelope.Body.CommandId
+	for {
+		envelope, err := self.call(ctx, actionReceive, shell_id, nil,
+			fmt.Sprintf(`<rsp:Receive><rsp:DesiredStream CommandId="%s">`+
+				`stdout stderr</rsp:DesiredStream></rsp:Receive>`,
+				escape(command_id)))
+		if err != nil {
+			fault, ok := err.(*wsmanFault)
+			if ok && fault.Detail.WSManFault.Code == faultTimedOut {
+				continue
+			}
+
+			// Kill the command if we were cancelled.
+			if ctx.Err() != nil {
+				self.signalTerminate(shell_id, command_id)
+			}
+			return 0, err
+		}
+
+		for _, stream := range envelope.Body.Receive.Streams {
+			data, err := base64.StdEncoding.DecodeString(stream.Data)
+			if err != nil {
+				return 0, err
+			}
+
+			switch stream.Name {
+			case "stdout":
+				_, err = stdout.Write(data)
+			case "stderr":
+				_, err = stderr.Write(data)
+			}
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		state := envelope.Body.Receive.CommandState
+		if state.State == commandStateDone {
+			return state.ExitCode, nil
+		}
+	}
+}
+
+func (self *WinRMClient) signalTerminate(shell_id, command_id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, _ = self.call(ctx, actionSignal, shell_id, nil,
+		fmt.Sprintf(`<rsp:Signal CommandId="%s"><rsp:Code>%s</rsp:Code></rsp:Signal>`,
+			escape(command_id), signalTerminate))
+}
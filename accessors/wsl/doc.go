@@ -0,0 +1,10 @@
+// An accessor for Windows Subsystem for Linux distributions.
+//
+// The wsl accessor exposes each registered distribution as a top
+// level directory named after the distribution. WSL1 distributions
+// are read directly from their rootfs directory on the host while
+// WSL2 distributions (which live in a virtual disk) are read through
+// the \\wsl$ share and so are only available while the distribution
+// is running.
+
+package wsl
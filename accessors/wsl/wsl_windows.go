@@ -0,0 +1,254 @@
+// +build windows
+
+package wsl
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Velocidex/ordereddict"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+	"www.velocidex.com/golang/velociraptor/accessors"
+	"www.velocidex.com/golang/velociraptor/acls"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/vfilter"
+)
+
+const (
+	LXSS_KEY = `Software\Microsoft\Windows\CurrentVersion\Lxss`
+
+	// Set in the Flags value of distributions running in a VM
+	// (i.e. WSL2).
+	LXSS_DISTRO_FLAGS_VM_MODE = 8
+)
+
+var (
+	distributionState = map[uint64]string{
+		1: "Installed",
+		2: "Installing",
+		3: "Uninstalling",
+		4: "Converting",
+	}
+)
+
+func getUsername(sid string) string {
+	sid_obj, err := windows.StringToSid(sid)
+	if err != nil {
+		return ""
+	}
+
+	account, domain, _, err := sid_obj.LookupAccount("")
+	if err != nil {
+		return ""
+	}
+
+	if domain != "" {
+		return domain + "\\" + account
+	}
+	return account
+}
+
+// Enumerate the WSL distributions registered by each user. WSL keeps
+// its registrations in the user's hive so only users with a loaded
+// hive (e.g. logged in users) are visible.
+func GetDistributions() ([]*ordereddict.Dict, error) {
+	users, err := registry.OpenKey(registry.USERS, "",
+		registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		return nil, err
+	}
+	defer users.Close()
+
+	sids, err := users.ReadSubKeyNames(-1)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*ordereddict.Dict
+	for _, sid := range sids {
+		if strings.HasSuffix(sid, "_Classes") {
+			continue
+		}
+
+		lxss, err := registry.OpenKey(registry.USERS, sid+`\`+LXSS_KEY,
+			registry.ENUMERATE_SUB_KEYS|registry.QUERY_VALUE)
+		if err != nil {
+			continue
+		}
+
+		default_distribution, _, _ := lxss.GetStringValue("DefaultDistribution")
+		guids, _ := lxss.ReadSubKeyNames(-1)
+		username := getUsername(sid)
+
+		for _, guid := range guids {
+			row := getDistribution(lxss, guid)
+			if row == nil {
+				continue
+			}
+
+			distribution := ordereddict.NewDict().
+				Set("SID", sid).
+				Set("Username", username).
+				Set("GUID", guid).
+				Set("Default", strings.EqualFold(guid, default_distribution))
+			distribution.MergeFrom(row)
+			result = append(result, distribution)
+		}
+		lxss.Close()
+	}
+
+	return result, nil
+}
+
+func getDistribution(lxss registry.Key, guid string) *ordereddict.Dict {
+	key, err := registry.OpenKey(lxss, guid, registry.QUERY_VALUE)
+	if err != nil {
+		return nil
+	}
+	defer key.Close()
+
+	name, _, err := key.GetStringValue("DistributionName")
+	if err != nil {
+		return nil
+	}
+
+	base_path, _, _ := key.GetStringValue("BasePath")
+	base_path = strings.TrimPrefix(base_path, `\\?\`)
+
+	package_family_name, _, _ := key.GetStringValue("PackageFamilyName")
+	default_uid, _, _ := key.GetIntegerValue("DefaultUid")
+	state, _, _ := key.GetIntegerValue("State")
+	flags, _, _ := key.GetIntegerValue("Flags")
+
+	version, _, err := key.GetIntegerValue("Version")
+	if err != nil || version < 2 {
+		version = 1
+		if flags&LXSS_DISTRO_FLAGS_VM_MODE != 0 {
+			version = 2
+		}
+	}
+
+	// WSL1 stores files directly on the host while WSL2 uses a
+	// virtual disk which is only reachable through the \\wsl$ share.
+	vhd_path := ""
+	filesystem := filepath.Join(base_path, "rootfs")
+	if version == 2 {
+		filesystem = `\\wsl$\` + name
+		vhd_path = filepath.Join(base_path, "ext4.vhdx")
+		_, err := os.Stat(vhd_path)
+		if err != nil {
+			vhd_path = ""
+		}
+	}
+
+	state_name, pres := distributionState[state]
+	if !pres {
+		state_name = "Unknown"
+	}
+
+	return ordereddict.NewDict().
+		Set("Name", name).
+		Set("Version", version).
+		Set("State", state_name).
+		Set("DefaultUid", default_uid).
+		Set("PackageFamilyName", package_family_name).
+		Set("BasePath", base_path).
+		Set("VhdPath", vhd_path).
+		Set("Filesystem", filesystem)
+}
+
+type WSLFileSystemAccessor struct {
+	*accessors.MountFileSystemAccessor
+}
+
+func (self *WSLFileSystemAccessor) New(
+	scope vfilter.Scope) (accessors.FileSystemAccessor, error) {
+
+	file_accessor, err := accessors.GetAccessor("file", scope)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build a virtual filesystem that mounts each distribution on it.
+	root_path, _ := accessors.NewLinuxOSPath("")
+	root_fs := accessors.NewVirtualFilesystemAccessor(root_path)
+
+	result := &WSLFileSystemAccessor{
+		MountFileSystemAccessor: accessors.NewMountFileSystemAccessor(
+			root_path, root_fs),
+	}
+
+	distributions, err := GetDistributions()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, row := range distributions {
+		name, _ := row.GetString("Name")
+		filesystem, _ := row.GetString("Filesystem")
+
+		// Different users may register distributions with the same
+		// name.
+		if seen[strings.ToLower(name)] {
+			sid, _ := row.GetString("SID")
+			name += "@" + sid
+		}
+		seen[strings.ToLower(name)] = true
+
+		source, err := accessors.NewWindowsOSPath(filesystem)
+		if err != nil {
+			continue
+		}
+
+		fi := &accessors.VirtualFileInfo{
+			IsDir_: true,
+			Path:   root_path.Append(name),
+			Data_:  row,
+		}
+
+		root_fs.SetVirtualFileInfo(fi)
+		result.AddMapping(source, fi.OSPath(), file_accessor)
+	}
+
+	return result, nil
+}
+
+func init() {
+	accessors.Register("wsl", &WSLFileSystemAccessor{},
+		`Access files inside WSL distributions. Each distribution appears as a top level directory.`)
+
+	vql_subsystem.RegisterPlugin(
+		&vfilter.GenericListPlugin{
+			PluginName: "wsl_distributions",
+			Function: func(
+				ctx context.Context,
+				scope vfilter.Scope,
+				args *ordereddict.Dict) []vfilter.Row {
+				var result []vfilter.Row
+
+				err := vql_subsystem.CheckAccess(scope, acls.MACHINE_STATE)
+				if err != nil {
+					scope.Log("wsl_distributions: %s", err)
+					return result
+				}
+
+				distributions, err := GetDistributions()
+				if err != nil {
+					scope.Log("wsl_distributions: %v", err)
+					return result
+				}
+
+				for _, row := range distributions {
+					result = append(result, row)
+				}
+				return result
+			},
+			Doc: "List the WSL distributions registered by each logged in " +
+				"user. Files within a distribution may be accessed using " +
+				"the wsl accessor.",
+		})
+}
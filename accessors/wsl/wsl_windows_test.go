@@ -0,0 +1,77 @@
+// +build windows
+
+package wsl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/windows/registry"
+	"www.velocidex.com/golang/velociraptor/utils"
+)
+
+const testKey = `Software\Velociraptor\WSLTest`
+
+func setDistribution(t *testing.T, lxss registry.Key,
+	guid, name string, version, flags uint32) {
+	key, _, err := registry.CreateKey(lxss, guid, registry.SET_VALUE)
+	require.NoError(t, err)
+	defer key.Close()
+
+	require.NoError(t, key.SetStringValue("DistributionName", name))
+	require.NoError(t, key.SetStringValue("BasePath",
+		`\\?\C:\Users\test\AppData\Local\Packages\`+name))
+	require.NoError(t, key.SetDWordValue("State", 1))
+	require.NoError(t, key.SetDWordValue("DefaultUid", 1000))
+	require.NoError(t, key.SetDWordValue("Flags", flags))
+	if version > 0 {
+		require.NoError(t, key.SetDWordValue("Version", version))
+	}
+}
+
+func TestGetDistribution(t *testing.T) {
+	lxss, _, err := registry.CreateKey(registry.CURRENT_USER, testKey,
+		registry.ALL_ACCESS)
+	require.NoError(t, err)
+	defer func() {
+		lxss.Close()
+		for _, guid := range []string{"{1}", "{2}", "{3}"} {
+			registry.DeleteKey(registry.CURRENT_USER, testKey+`\`+guid)
+		}
+		registry.DeleteKey(registry.CURRENT_USER, testKey)
+	}()
+
+	setDistribution(t, lxss, "{1}", "Ubuntu", 2, 0)
+	setDistribution(t, lxss, "{2}", "Debian", 0, 0)
+
+	// Older WSL versions only set the VM mode flag.
+	setDistribution(t, lxss, "{3}", "Alpine", 0, LXSS_DISTRO_FLAGS_VM_MODE)
+
+	row := getDistribution(lxss, "{1}")
+	require.NotNil(t, row)
+	assert.Equal(t, "Ubuntu", utils.GetString(row, "Name"))
+	assert.Equal(t, "Installed", utils.GetString(row, "State"))
+	assert.Equal(t, `C:\Users\test\AppData\Local\Packages\Ubuntu`,
+		utils.GetString(row, "BasePath"))
+
+	// WSL2 files are only reachable through the share and the
+	// virtual disk does not exist.
+	assert.Equal(t, `\\wsl$\Ubuntu`, utils.GetString(row, "Filesystem"))
+	assert.Equal(t, "", utils.GetString(row, "VhdPath"))
+
+	row = getDistribution(lxss, "{2}")
+	require.NotNil(t, row)
+	version, _ := row.Get("Version")
+	assert.Equal(t, uint64(1), version)
+	assert.Equal(t, `C:\Users\test\AppData\Local\Packages\Debian\rootfs`,
+		utils.GetString(row, "Filesystem"))
+
+	row = getDistribution(lxss, "{3}")
+	require.NotNil(t, row)
+	version, _ = row.Get("Version")
+	assert.Equal(t, uint64(2), version)
+
+	// Keys without a name are not distributions.
+	assert.Nil(t, getDistribution(lxss, "{4}"))
+}
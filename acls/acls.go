@@ -27,6 +27,11 @@ below. ACL_PERMISSION represents what the code wants to do.
 A Role is a collection of permissions that are granted to anyone in
 that role.
 
+Besides the built in roles, administrators may define custom roles
+for each org (see custom_roles.go). The gRPC API checks the
+permissions required by each method before calling its handler (see
+api/rbac.go).
+
 Note that the interaction of different permission may be used to
 bypass the RBACS - for example:
 
@@ -115,6 +120,13 @@ const (
 	// Allowed raw datastore access
 	DATASTORE_ACCESS
 
+	// Allowed to read secrets from endpoints (e.g. disk encryption
+	// recovery keys).
+	READ_SECRETS
+
+	// Allowed to capture network traffic on endpoints.
+	NETWORK_CAPTURE
+
 	// When adding new permission - update CheckAccess,
 	// GetRolePermissions and acl.proto
 )
@@ -157,6 +169,10 @@ func (self ACL_PERMISSION) String() string {
 		return "PREPARE_RESULTS"
 	case DATASTORE_ACCESS:
 		return "DATASTORE_ACCESS"
+	case READ_SECRETS:
+		return "READ_SECRETS"
+	case NETWORK_CAPTURE:
+		return "NETWORK_CAPTURE"
 
 	}
 	return fmt.Sprintf("%d", self)
@@ -201,6 +217,10 @@ func GetPermission(name string) ACL_PERMISSION {
 		return PREPARE_RESULTS
 	case "DATASTORE_ACCESS":
 		return DATASTORE_ACCESS
+	case "READ_SECRETS":
+		return READ_SECRETS
+	case "NETWORK_CAPTURE":
+		return NETWORK_CAPTURE
 
 	}
 	return NO_PERMISSIONS
@@ -248,6 +268,7 @@ func (self ACLManager) GetEffectivePolicy(
 		if err != nil {
 			return nil, err
 		}
+		applyReadOnlyMode(config_obj, acl_obj)
 		return acl_obj, nil
 	}
 
@@ -262,6 +283,7 @@ func (self ACLManager) GetEffectivePolicy(
 		return nil, err
 	}
 
+	applyReadOnlyMode(config_obj, acl_obj)
 	return acl_obj, nil
 }
 
@@ -283,6 +305,14 @@ func (self ACLManager) CheckAccess(
 	principal string,
 	permissions ...ACL_PERMISSION) (bool, error) {
 
+	// Nobody may modify endpoints in read only mode - not even the
+	// server itself.
+	for _, permission := range permissions {
+		if DeniedByReadOnlyMode(config_obj, permission) {
+			return false, nil
+		}
+	}
+
 	// Internal calls from the server are allowed to do anything.
 	if config_obj.Client != nil && principal == config_obj.Client.PinnedServerName {
 		return true, nil
@@ -371,6 +401,12 @@ func (self ACLManager) CheckAccessWithToken(
 	case DATASTORE_ACCESS:
 		return token.DatastoreAccess, nil
 
+	case READ_SECRETS:
+		return token.ReadSecrets, nil
+
+	case NETWORK_CAPTURE:
+		return token.NetworkCapture, nil
+
 	}
 
 	return false, nil
@@ -384,7 +420,7 @@ func (self ACLManager) GrantRoles(
 
 	for _, role := range roles {
 		if !utils.InString(new_policy.Roles, role) {
-			if !ValidateRole(role) {
+			if !IsValidRole(config_obj, role) {
 				return errors.Errorf("Invalid role %v", role)
 			}
 			new_policy.Roles = append(new_policy.Roles, role)
@@ -0,0 +1,172 @@
+package acls
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	acl_proto "www.velocidex.com/golang/velociraptor/acls/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/datastore"
+	"www.velocidex.com/golang/velociraptor/paths"
+	"www.velocidex.com/golang/velociraptor/utils"
+)
+
+var (
+	roleNameRegex = regexp.MustCompile("^[a-z0-9_]+$")
+)
+
+// Custom roles are named sets of permissions stored in the datastore
+// of each org. They may be granted like the built in roles.
+func GetCustomRole(
+	config_obj *config_proto.Config, name string) (*acl_proto.Role, error) {
+	if !roleNameRegex.MatchString(name) {
+		return nil, errors.Errorf("Invalid role name %v", name)
+	}
+
+	db, err := datastore.GetDB(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &acl_proto.Role{}
+	err = db.GetSubject(config_obj, paths.ROLES_ROOT.AddChild(name), role)
+	if err != nil {
+		return nil, err
+	}
+
+	if role.Name != name || role.Permissions == nil {
+		return nil, errors.Errorf("Unknown role %v", name)
+	}
+
+	return role, nil
+}
+
+func SetCustomRole(config_obj *config_proto.Config, role *acl_proto.Role) error {
+	if !roleNameRegex.MatchString(role.Name) {
+		return errors.Errorf(
+			"Invalid role name %v: may only contain a-z, 0-9 and _", role.Name)
+	}
+
+	if ValidateRole(role.Name) {
+		return errors.Errorf("Built in role %v can not be changed", role.Name)
+	}
+
+	if role.Permissions == nil {
+		role.Permissions = &acl_proto.ApiClientACL{}
+	}
+
+	// Roles may not refer to other roles.
+	if len(role.Permissions.Roles) > 0 {
+		return errors.New("Roles can not include other roles")
+	}
+
+	db, err := datastore.GetDB(config_obj)
+	if err != nil {
+		return err
+	}
+
+	return db.SetSubject(config_obj, paths.ROLES_ROOT.AddChild(role.Name), role)
+}
+
+func DeleteCustomRole(config_obj *config_proto.Config, name string) error {
+	if ValidateRole(name) {
+		return errors.Errorf("Built in role %v can not be deleted", name)
+	}
+
+	// Make sure the role exists.
+	_, err := GetCustomRole(config_obj, name)
+	if err != nil {
+		return err
+	}
+
+	db, err := datastore.GetDB(config_obj)
+	if err != nil {
+		return err
+	}
+
+	return db.DeleteSubject(config_obj, paths.ROLES_ROOT.AddChild(name))
+}
+
+// List the built in roles followed by the custom roles.
+func ListRoles(config_obj *config_proto.Config) ([]*acl_proto.Role, error) {
+	result := []*acl_proto.Role{}
+	for _, name := range BUILTIN_ROLES {
+		permissions := &acl_proto.ApiClientACL{}
+		err := GetRolePermissions(nil, []string{name}, permissions)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &acl_proto.Role{
+			Name:        name,
+			Permissions: permissions,
+		})
+	}
+
+	db, err := datastore.GetDB(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	children, err := db.ListChildren(config_obj, paths.ROLES_ROOT)
+	if err != nil {
+		return nil, err
+	}
+
+	custom := []*acl_proto.Role{}
+	for _, child := range children {
+		if child.IsDir() {
+			continue
+		}
+
+		role, err := GetCustomRole(config_obj, child.Base())
+		if err == nil {
+			custom = append(custom, role)
+		}
+	}
+
+	sort.Slice(custom, func(i, j int) bool {
+		return custom[i].Name < custom[j].Name
+	})
+
+	return append(result, custom...), nil
+}
+
+// Accepts both built in and custom roles.
+func IsValidRole(config_obj *config_proto.Config, role string) bool {
+	if ValidateRole(role) {
+		return true
+	}
+
+	_, err := GetCustomRole(config_obj, role)
+	return err == nil
+}
+
+// Grant all the permissions in src to dst.
+func mergePermissions(dst, src *acl_proto.ApiClientACL) {
+	if src == nil {
+		return
+	}
+
+	src = proto.Clone(src).(*acl_proto.ApiClientACL)
+	src.Roles = nil
+
+	dst_reflect := dst.ProtoReflect()
+	src.ProtoReflect().Range(func(field protoreflect.FieldDescriptor,
+		value protoreflect.Value) bool {
+		switch {
+		case field.Kind() == protoreflect.BoolKind && value.Bool():
+			dst_reflect.Set(field, value)
+
+		case field.Name() == "publish_queues":
+			for _, queue := range src.PublishQueues {
+				if !utils.InString(dst.PublishQueues, queue) {
+					dst.PublishQueues = append(dst.PublishQueues, queue)
+				}
+			}
+		}
+		return true
+	})
+}
@@ -0,0 +1,39 @@
+package acls
+
+import (
+	acl_proto "www.velocidex.com/golang/velociraptor/acls/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+)
+
+// Permissions which allow users to modify endpoints. When the server
+// is in read only mode nobody has these permissions.
+var read_only_denied = []ACL_PERMISSION{EXECVE, FILESYSTEM_WRITE}
+
+func IsReadOnlyMode(config_obj *config_proto.Config) bool {
+	return config_obj != nil && config_obj.ReadOnlyMode
+}
+
+// Is the permission denied because the server is in read only mode?
+func DeniedByReadOnlyMode(
+	config_obj *config_proto.Config, permission ACL_PERMISSION) bool {
+	if !IsReadOnlyMode(config_obj) {
+		return false
+	}
+
+	for _, denied := range read_only_denied {
+		if permission == denied {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove the endpoint modifying permissions from the policy in read
+// only mode.
+func applyReadOnlyMode(
+	config_obj *config_proto.Config, acl_obj *acl_proto.ApiClientACL) {
+	if IsReadOnlyMode(config_obj) {
+		acl_obj.Execve = false
+		acl_obj.FilesystemWrite = false
+	}
+}
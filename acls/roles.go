@@ -6,15 +6,20 @@ import (
 
 	acl_proto "www.velocidex.com/golang/velociraptor/acls/proto"
 	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/utils"
 )
 
-func ValidateRole(role string) bool {
-	switch role {
-	case "administrator", "reader", "analyst", "investigator", "artifact_writer", "api":
-		return true
+var (
+	BUILTIN_ROLES = []string{
+		"administrator", "reader", "analyst", "investigator",
+		"artifact_writer", "api",
 	}
+)
 
-	return false
+// Only checks the built in roles. Use IsValidRole to also accept
+// custom roles.
+func ValidateRole(role string) bool {
+	return utils.InString(BUILTIN_ROLES, role)
 }
 
 func SetTokenPermission(
@@ -53,6 +58,10 @@ func SetTokenPermission(
 			token.PrepareResults = true
 		case "DATASTORE_ACCESS":
 			token.DatastoreAccess = true
+		case "READ_SECRETS":
+			token.ReadSecrets = true
+		case "NETWORK_CAPTURE":
+			token.NetworkCapture = true
 
 		default:
 			return errors.New("Unknown permission")
@@ -86,6 +95,8 @@ func GetRolePermissions(
 			result.FilesystemWrite = true
 			result.MachineState = true
 			result.PrepareResults = true
+			result.ReadSecrets = true
+			result.NetworkCapture = true
 
 			// Readers can view results but not edit or
 			// modify anything.
@@ -126,7 +137,16 @@ func GetRolePermissions(
 			result.ArtifactWriter = true
 
 		default:
-			return errors.New("Unknown role")
+			// Custom roles are defined in the datastore.
+			if config_obj == nil {
+				return errors.New("Unknown role")
+			}
+
+			custom, err := GetCustomRole(config_obj, role)
+			if err != nil {
+				return errors.New("Unknown role")
+			}
+			mergePermissions(result, custom.Permissions)
 		}
 	}
 
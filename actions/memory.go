@@ -0,0 +1,59 @@
+package actions
+
+import (
+	"runtime"
+	"time"
+)
+
+const (
+	MEMORY_CHECK_PERIOD = time.Second
+)
+
+var (
+	// Returns the bytes allocated on the heap. Tests may replace it.
+	GetHeapUsage = func() uint64 {
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		return m.Alloc
+	}
+)
+
+// Go can not account memory to a goroutine so the query is charged
+// with the growth of the heap since it started. Queries running at the
+// same time are therefore counted together, which is still enough to
+// stop a runaway aggregation before the nanny kills the whole client.
+type memoryMonitor struct {
+	limit    uint64
+	baseline uint64
+}
+
+func newMemoryMonitor(limit uint64) *memoryMonitor {
+	return &memoryMonitor{
+		limit:    limit,
+		baseline: GetHeapUsage(),
+	}
+}
+
+// Returns the memory used by the query and if it exceeds the limit.
+func (self *memoryMonitor) Check() (uint64, bool) {
+	used := self.used()
+	if used <= self.limit {
+		return used, false
+	}
+
+	// The heap includes garbage which was not collected yet, so
+	// only abort if the memory is really in use.
+	runtime.GC()
+	used = self.used()
+	return used, used > self.limit
+}
+
+func (self *memoryMonitor) used() uint64 {
+	current := GetHeapUsage()
+
+	// Memory used before the query started was freed.
+	if current < self.baseline {
+		self.baseline = current
+	}
+	return current - self.baseline
+}
@@ -30,6 +30,7 @@ import (
 
 	"github.com/Velocidex/ordereddict"
 	humanize "github.com/dustin/go-humanize"
+	"google.golang.org/protobuf/proto"
 	actions_proto "www.velocidex.com/golang/velociraptor/actions/proto"
 	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
 	crypto_proto "www.velocidex.com/golang/velociraptor/crypto/proto"
@@ -99,6 +100,11 @@ func (self VQLClientAction) StartQuery(
 		heartbeat = 30
 	}
 
+	max_memory := arg.MaxMemory
+	if max_memory == 0 {
+		max_memory = config_obj.Client.GetMaxQueryMemory()
+	}
+
 	// Cancel the query after this deadline
 	deadline := time.After(time.Second * time.Duration(timeout))
 	started := time.Now().Unix()
@@ -133,13 +139,24 @@ func (self VQLClientAction) StartQuery(
 		Responder: responder,
 	}
 
+	// When the server is in read only mode we may not run programs
+	// or write to the endpoint.
+	client_config := config_obj.Client
+	if arg.ReadOnly {
+		client_config = &config_proto.ClientConfig{}
+		if config_obj.Client != nil {
+			client_config = proto.Clone(config_obj.Client).(*config_proto.ClientConfig)
+		}
+		client_config.PreventExecve = true
+	}
+
 	builder := services.ScopeBuilder{
 		Config: &config_proto.Config{
 			Remappings: config_obj.Remappings,
 		},
 		// Only provide the client config since we are running in
 		// client context.
-		ClientConfig: config_obj.Client,
+		ClientConfig: client_config,
 		// Disable ACLs on the client.
 		ACLManager: vql_subsystem.NullACLManager{},
 		Env:        ordereddict.NewDict(),
@@ -195,6 +212,16 @@ func (self VQLClientAction) StartQuery(
 		return
 	}
 
+	// Abort the query if it uses too much memory.
+	var memory_monitor *memoryMonitor
+	var memory_check <-chan time.Time
+	if max_memory > 0 {
+		memory_monitor = newMemoryMonitor(max_memory)
+		ticker := time.NewTicker(MEMORY_CHECK_PERIOD)
+		defer ticker.Stop()
+		memory_check = ticker.C
+	}
+
 	start_row := 0
 
 	// All the queries will use the same scope. This allows one
@@ -236,6 +263,23 @@ func (self VQLClientAction) StartQuery(
 				// Try again after a while to prevent spinning here.
 				deadline = time.After(time.Second * time.Duration(timeout))
 
+			case <-memory_check:
+				used, exceeded := memory_monitor.Check()
+				if !exceeded {
+					continue
+				}
+
+				msg := fmt.Sprintf(
+					"Query exceeded memory limit: used %v (limit %v)",
+					humanize.Bytes(used), humanize.Bytes(max_memory))
+				scope.Log(msg)
+				responder.RaiseError(ctx, msg)
+
+				// Wait for the query to finish as for the timeout.
+				cancel()
+				scope.Close()
+				memory_check = nil
+
 			case <-time.After(time.Second * time.Duration(heartbeat)):
 				responder.Log(ctx, logging.DEFAULT,
 					"Time %v: %s: Waiting for rows.",
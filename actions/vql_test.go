@@ -2,12 +2,14 @@ package actions_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/alecthomas/assert"
 	"github.com/stretchr/testify/suite"
 	"www.velocidex.com/golang/velociraptor/actions"
 	actions_proto "www.velocidex.com/golang/velociraptor/actions/proto"
 	artifacts_proto "www.velocidex.com/golang/velociraptor/artifacts/proto"
+	crypto_proto "www.velocidex.com/golang/velociraptor/crypto/proto"
 	"www.velocidex.com/golang/velociraptor/file_store/test_utils"
 	"www.velocidex.com/golang/velociraptor/responder"
 )
@@ -38,6 +40,54 @@ func (self *ClientVQLTestSuite) TestCPUThrottler() {
 	assert.Contains(self.T(), getLogs(resp), "Will throttle query")
 }
 
+func (self *ClientVQLTestSuite) TestMemoryLimit() {
+	// The heap grows by 1gb as soon as the query starts.
+	heap := uint64(0)
+	old_get_heap_usage := actions.GetHeapUsage
+	defer func() {
+		actions.GetHeapUsage = old_get_heap_usage
+	}()
+
+	actions.GetHeapUsage = func() uint64 {
+		result := heap
+		heap = 1024 * 1024 * 1024
+		return result
+	}
+
+	request := &actions_proto.VQLCollectorArgs{
+		Query: []*actions_proto.VQLRequest{
+			{
+				Name: "Query",
+				VQL:  "SELECT sleep(time=20) FROM scope()",
+			},
+		},
+		MaxMemory: 100 * 1024 * 1024,
+	}
+
+	start := time.Now()
+	resp := responder.TestResponder()
+	actions.VQLClientAction{}.StartQuery(self.ConfigObj, self.Sm.Ctx, resp, request)
+
+	// The query is aborted and the error is reported to the server.
+	assert.True(self.T(), time.Now().Sub(start) < 10*time.Second)
+
+	logs := ""
+	var status *crypto_proto.VeloStatus
+	for _, item := range responder.GetTestResponses(resp) {
+		if item.LogMessage != nil {
+			logs += item.LogMessage.Message + "\n"
+		}
+
+		if item.Status != nil &&
+			item.Status.Status == crypto_proto.VeloStatus_GENERIC_ERROR {
+			status = item.Status
+		}
+	}
+	assert.Contains(self.T(), logs, "Query exceeded memory limit")
+	assert.NotNil(self.T(), status)
+	assert.Contains(self.T(), status.ErrorMessage, "limit 105 MB")
+}
+
 // Make sure that dependent artifacts are properly used
 func (self *ClientVQLTestSuite) TestDependentArtifacts() {
 	resp := responder.TestResponder()
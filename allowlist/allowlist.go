@@ -0,0 +1,136 @@
+// The artifact allowlist restricts which artifacts may be collected
+// from sensitive clients. For example, production database servers
+// may be labeled and only allowed to receive low impact collections.
+//
+// Rules are specified in the config file and match clients by label
+// and/or org. The allowlist is enforced when flows are launched and
+// when hunts are created or scheduled on each client. Internal
+// collections (e.g. interrogation and client monitoring) are not
+// restricted.
+
+package allowlist
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+var (
+	// Callers can check for this with errors.Is()
+	ArtifactNotAllowedError = errors.New("Artifact is not allowed by policy")
+)
+
+// Rules which apply to the org the config object belongs to.
+func getOrgRules(
+	config_obj *config_proto.Config) []*config_proto.ArtifactAllowlistRule {
+	result := []*config_proto.ArtifactAllowlistRule{}
+	for _, rule := range config_obj.ArtifactAllowlist {
+		if rule.OrgId == "" || rule.OrgId == config_obj.OrgId {
+			result = append(result, rule)
+		}
+	}
+	return result
+}
+
+// Check that all the artifacts are allowed by the rule.
+func checkRule(rule *config_proto.ArtifactAllowlistRule,
+	artifacts []string) error {
+	for _, artifact := range artifacts {
+		if !isAllowed(rule, artifact) {
+			target := "all clients"
+			if rule.Label != "" {
+				target = "clients labeled " + rule.Label
+			}
+			return fmt.Errorf("%w: %v may not be collected from %v",
+				ArtifactNotAllowedError, artifact, target)
+		}
+	}
+	return nil
+}
+
+func isAllowed(rule *config_proto.ArtifactAllowlistRule, artifact string) bool {
+	artifact = strings.ToLower(artifact)
+	for _, pattern := range rule.Artifacts {
+		matched, err := path.Match(strings.ToLower(pattern), artifact)
+		if err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Check if the artifacts may be collected from the client.
+func CheckClient(config_obj *config_proto.Config,
+	client_id string, artifacts []string) error {
+
+	// Server artifacts are not subject to the allowlist.
+	if client_id == "server" {
+		return nil
+	}
+
+	rules := getOrgRules(config_obj)
+	if len(rules) == 0 {
+		return nil
+	}
+
+	labeler := services.GetLabeler(config_obj)
+	for _, rule := range rules {
+		if rule.Label != "" {
+			// Fail closed if we can not tell if the rule applies.
+			if labeler == nil {
+				return fmt.Errorf("%w: Labeler not available",
+					ArtifactNotAllowedError)
+			}
+
+			if !labeler.IsLabelSet(config_obj, client_id, rule.Label) {
+				continue
+			}
+		}
+
+		err := checkRule(rule, artifacts)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Check a hunt before it is created. Hunts which target a restricted
+// label (or an org where all clients are restricted) may only
+// collect allowed artifacts. Other hunts may still include restricted
+// clients - these are checked when the hunt is scheduled on them.
+func CheckHunt(config_obj *config_proto.Config, hunt *api_proto.Hunt) error {
+	if hunt.StartRequest == nil {
+		return nil
+	}
+
+	target_labels := hunt.Condition.GetLabels().GetLabel()
+	for _, rule := range getOrgRules(config_obj) {
+		if rule.Label != "" && !inLabels(target_labels, rule.Label) {
+			continue
+		}
+
+		err := checkRule(rule, hunt.StartRequest.Artifacts)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func inLabels(labels []string, label string) bool {
+	for _, l := range labels {
+		if strings.EqualFold(l, label) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package allowlist_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	actions_proto "www.velocidex.com/golang/velociraptor/actions/proto"
+	"www.velocidex.com/golang/velociraptor/allowlist"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/datastore"
+	"www.velocidex.com/golang/velociraptor/file_store/test_utils"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/paths"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+type AllowlistTestSuite struct {
+	test_utils.TestSuite
+}
+
+func (self *AllowlistTestSuite) SetupTest() {
+	self.ConfigObj = self.TestSuite.LoadConfig()
+	self.ConfigObj.ArtifactAllowlist = []*config_proto.ArtifactAllowlistRule{{
+		Label:     "Production",
+		Artifacts: []string{"Generic.Client.*", "Windows.System.Pslist"},
+	}, {
+		// Does not apply to the root org.
+		OrgId:     "O123",
+		Artifacts: []string{"Generic.Client.Info"},
+	}}
+
+	self.TestSuite.SetupTest()
+
+	db, err := datastore.GetDB(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	for _, client_id := range []string{"C.1", "C.2"} {
+		err = db.SetSubject(self.ConfigObj,
+			paths.NewClientPathManager(client_id).Path(),
+			&actions_proto.ClientInfo{ClientId: client_id})
+		assert.NoError(self.T(), err)
+	}
+
+	labeler := services.GetLabeler(self.ConfigObj)
+	err = labeler.SetClientLabel(self.ConfigObj, "C.1", "production")
+	assert.NoError(self.T(), err)
+}
+
+func (self *AllowlistTestSuite) TestCheckClient() {
+	// Allowed artifacts may be collected from the labeled client.
+	assert.NoError(self.T(), allowlist.CheckClient(self.ConfigObj, "C.1",
+		[]string{"Generic.Client.Info", "windows.system.pslist"}))
+
+	err := allowlist.CheckClient(self.ConfigObj, "C.1",
+		[]string{"Generic.Client.Info", "Windows.System.PowerShell"})
+	assert.True(self.T(), errors.Is(err, allowlist.ArtifactNotAllowedError))
+	assert.Contains(self.T(), err.Error(), "Windows.System.PowerShell")
+
+	// Other clients are not restricted.
+	assert.NoError(self.T(), allowlist.CheckClient(self.ConfigObj, "C.2",
+		[]string{"Windows.System.PowerShell"}))
+
+	// Neither is the server.
+	assert.NoError(self.T(), allowlist.CheckClient(self.ConfigObj, "server",
+		[]string{"Server.Utils.DeleteFlow"}))
+}
+
+func (self *AllowlistTestSuite) TestCheckHunt() {
+	request := &flows_proto.ArtifactCollectorArgs{
+		Artifacts: []string{"Windows.System.PowerShell"},
+	}
+
+	// Hunts targeting the restricted label are rejected.
+	err := allowlist.CheckHunt(self.ConfigObj, &api_proto.Hunt{
+		StartRequest: request,
+		Condition: &api_proto.HuntCondition{
+			UnionField: &api_proto.HuntCondition_Labels{
+				Labels: &api_proto.HuntLabelCondition{
+					Label: []string{"production"},
+				},
+			},
+		},
+	})
+	assert.True(self.T(), errors.Is(err, allowlist.ArtifactNotAllowedError))
+
+	// Hunts targeting all clients are allowed, restricted clients
+	// are checked when the hunt is scheduled.
+	assert.NoError(self.T(), allowlist.CheckHunt(self.ConfigObj,
+		&api_proto.Hunt{StartRequest: request}))
+}
+
+func TestAllowlist(t *testing.T) {
+	suite.Run(t, &AllowlistTestSuite{})
+}
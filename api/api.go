@@ -43,8 +43,10 @@ import (
 	"google.golang.org/protobuf/types/known/emptypb"
 	"www.velocidex.com/golang/velociraptor/acls"
 	actions_proto "www.velocidex.com/golang/velociraptor/actions/proto"
+	"www.velocidex.com/golang/velociraptor/allowlist"
 	"www.velocidex.com/golang/velociraptor/api/proto"
 	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/approvals"
 	artifacts_proto "www.velocidex.com/golang/velociraptor/artifacts/proto"
 	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
 	"www.velocidex.com/golang/velociraptor/file_store/api"
@@ -55,6 +57,7 @@ import (
 	"www.velocidex.com/golang/velociraptor/paths"
 	"www.velocidex.com/golang/velociraptor/server"
 	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/services/launcher"
 	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
 	"www.velocidex.com/golang/vfilter"
 )
@@ -65,53 +68,8 @@ type ApiServer struct {
 	ca_pool            *x509.CertPool
 	wg                 *sync.WaitGroup
 	api_client_factory grpc_client.APIClientFactory
-}
-
-func (self *ApiServer) CancelFlow(
-	ctx context.Context,
-	in *api_proto.ApiFlowRequest) (*api_proto.StartFlowResponse, error) {
-
-	defer Instrument("CancelFlow")()
-
-	users := services.GetUserManager()
-	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	user_name := user_record.Name
-
-	permissions := acls.COLLECT_CLIENT
-	if in.ClientId == "server" {
-		permissions = acls.COLLECT_SERVER
-	}
-
-	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
-	if !perm || err != nil {
-		return nil, status.Error(codes.PermissionDenied,
-			"User is not allowed to cancel flows.")
-	}
-
-	launcher, err := services.GetLauncher(org_config_obj)
-	if err != nil {
-		return nil, err
-	}
-	result, err := launcher.CancelFlow(
-		ctx, org_config_obj, in.ClientId, in.FlowId, user_name)
-	if err != nil {
-		return nil, err
-	}
-
-	// Log this event as and Audit event.
-	logging.GetLogger(org_config_obj, &logging.Audit).
-		WithFields(logrus.Fields{
-			"user":    user_name,
-			"client":  in.ClientId,
-			"flow_id": in.FlowId,
-			"details": fmt.Sprintf("%v", in),
-		}).Info("CancelFlow")
-
-	return result, nil
+	cache              *ApiCache
+	audit              *auditLogger
 }
 
 func (self *ApiServer) GetReport(
@@ -146,7 +104,8 @@ func (self *ApiServer) GetReport(
 		return nil, err
 	}
 
-	return getReport(ctx, org_config_obj, acl_manager, global_repo, in)
+	return getReport(ctx, org_config_obj, acl_manager, global_repo,
+		getUserLang(user_name), in)
 }
 
 func (self *ApiServer) CollectArtifact(
@@ -170,6 +129,11 @@ func (self *ApiServer) CollectArtifact(
 	if creator != org_config_obj.Client.PinnedServerName {
 		in.Creator = creator
 
+		// Pre-compiled requests are sent to the client as they
+		// are so only the server may provide them. Users must
+		// have their artifacts compiled and checked.
+		in.CompiledCollectorArgs = nil
+
 		permissions := acls.COLLECT_CLIENT
 		if in.ClientId == "server" {
 			permissions = acls.COLLECT_SERVER
@@ -185,6 +149,25 @@ func (self *ApiServer) CollectArtifact(
 		}
 	}
 
+	err = allowlist.CheckClient(org_config_obj, in.ClientId, in.Artifacts)
+	if err != nil {
+		return nil, apiError(err)
+	}
+
+	// Fail early rather than scheduling a collection which will
+	// never run.
+	if in.ClientId != "" && in.ClientId != "server" {
+		client_info_manager, err := services.GetClientInfoManager(org_config_obj)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = client_info_manager.Get(in.ClientId)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, clientNotFoundError(in.ClientId)
+		}
+	}
+
 	manager, err := services.GetRepositoryManager(org_config_obj)
 	if err != nil {
 		return nil, err
@@ -199,10 +182,25 @@ func (self *ApiServer) CollectArtifact(
 		return nil, err
 	}
 
+	// A dry run performs the same validation as a real launch but
+	// returns the compiled requests instead of scheduling them.
+	if in.DryRun {
+		return self.dryRunCollection(ctx, org_config_obj, acl_manager,
+			repository, creator, in, result)
+	}
+
+	// Internal launches by the server do not require approval.
+	if creator != org_config_obj.Client.PinnedServerName {
+		err = approvals.CheckCollection(org_config_obj, repository, creator, in)
+		if err != nil {
+			return nil, approvalError(err)
+		}
+	}
+
 	flow_id, err := launcher.ScheduleArtifactCollection(
 		ctx, org_config_obj, acl_manager, repository, in, nil)
 	if err != nil {
-		return nil, err
+		return nil, apiError(err)
 	}
 
 	result.FlowId = flow_id
@@ -219,6 +217,52 @@ func (self *ApiServer) CollectArtifact(
 	return result, nil
 }
 
+// Check the approval and the client's capabilities without using up
+// the approval, then compile the requests without scheduling them.
+func (self *ApiServer) dryRunCollection(
+	ctx context.Context,
+	config_obj *config_proto.Config,
+	acl_manager vql_subsystem.ACLManager,
+	repository services.Repository,
+	creator string,
+	in *flows_proto.ArtifactCollectorArgs,
+	result *flows_proto.ArtifactCollectorResponse) (
+	*flows_proto.ArtifactCollectorResponse, error) {
+
+	if in.ClientId == "" {
+		return nil, status.Error(codes.InvalidArgument,
+			"Client id not provided.")
+	}
+
+	if creator != config_obj.Client.PinnedServerName {
+		err := approvals.VerifyCollection(config_obj, repository, creator, in)
+		if err != nil {
+			return nil, approvalError(err)
+		}
+	}
+
+	err := launcher.CheckClientCapabilities(config_obj, in)
+	if err != nil {
+		return nil, apiError(err)
+	}
+
+	launcher_service, err := services.GetLauncher(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled, err := launcher_service.CompileCollectorArgs(
+		ctx, config_obj, acl_manager, repository,
+		services.CompilerOptions{
+			ObfuscateNames: true,
+		}, in)
+	if err != nil {
+		return nil, apiError(err)
+	}
+	in.CompiledCollectorArgs = compiled
+	return result, nil
+}
+
 func (self *ApiServer) ListClients(
 	ctx context.Context,
 	in *api_proto.SearchClientsRequest) (*api_proto.SearchClientsResponse, error) {
@@ -244,10 +288,34 @@ func (self *ApiServer) ListClients(
 		return nil, err
 	}
 
-	result, err := indexer.SearchClients(ctx, org_config_obj, in, user_name)
+	// The fingerprint needs the metadata values, not the pointers.
+	metadata := []string{}
+	for _, item := range in.Filters.GetMetadata() {
+		metadata = append(metadata, item.Key+"="+item.Value)
+	}
+
+	page, err := newPager(in.PageToken, in.Offset, in.Limit,
+		DEFAULT_CLIENT_PAGE_SIZE, MAX_PAGE_SIZE,
+		"ListClients", in.Query, in.NameOnly, in.Sort, in.Filter,
+		in.Filters.GetOs(), in.Filters.GetMinVersion(),
+		in.Filters.GetMaxVersion(), in.Filters.GetLastSeenBefore(),
+		in.Filters.GetLastSeenAfter(), in.Filters.GetLabels(),
+		in.Filters.GetIpCidr(), metadata)
 	if err != nil {
 		return nil, err
 	}
+	in.Offset = page.Offset
+	in.Limit = page.Size
+	if page.Cursor != "" {
+		in.Cursor = page.Cursor
+	}
+
+	// The indexer returns a cursor when there are more clients.
+	result, err := indexer.SearchClients(ctx, org_config_obj, in, user_name)
+	if err != nil {
+		return nil, apiError(err)
+	}
+	result.NextPageToken = page.CursorPage(result.NextCursor)
 
 	// Warm up the cache pre-emptively so we have fresh connected
 	// status
@@ -342,68 +410,12 @@ func (self *ApiServer) LabelClients(
 				}, err
 			}
 		}
+		self.cache.InvalidateClient(org_config_obj, client_id)
 	}
 
 	return &api_proto.APIResponse{}, nil
 }
 
-func (self *ApiServer) GetFlowDetails(
-	ctx context.Context,
-	in *api_proto.ApiFlowRequest) (*api_proto.FlowDetails, error) {
-
-	defer Instrument("GetFlowDetails")()
-
-	users := services.GetUserManager()
-	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	user_name := user_record.Name
-	permissions := acls.READ_RESULTS
-	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
-	if !perm || err != nil {
-		return nil, status.Error(codes.PermissionDenied,
-			"User is not allowed to launch flows.")
-	}
-
-	launcher, err := services.GetLauncher(org_config_obj)
-	if err != nil {
-		return nil, err
-	}
-	result, err := launcher.GetFlowDetails(org_config_obj, in.ClientId, in.FlowId)
-	return result, err
-}
-
-func (self *ApiServer) GetFlowRequests(
-	ctx context.Context,
-	in *api_proto.ApiFlowRequest) (*api_proto.ApiFlowRequestDetails, error) {
-
-	defer Instrument("GetFlowRequests")()
-
-	users := services.GetUserManager()
-	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	user_name := user_record.Name
-	permissions := acls.READ_RESULTS
-	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
-	if !perm || err != nil {
-		return nil, status.Error(codes.PermissionDenied,
-			"User is not allowed to view flows.")
-	}
-
-	launcher, err := services.GetLauncher(org_config_obj)
-	if err != nil {
-		return nil, err
-	}
-	result, err := launcher.GetFlowRequests(org_config_obj, in.ClientId, in.FlowId,
-		in.Offset, in.Count)
-	return result, err
-}
-
 func (self *ApiServer) GetUserUITraits(
 	ctx context.Context,
 	in *emptypb.Empty) (*api_proto.ApiUser, error) {
@@ -651,13 +663,34 @@ func (self *ApiServer) GetTable(
 		result, err = getEventTable(ctx, org_config_obj, in)
 
 	} else {
+		// Only collected result sets are paged by row.
+		var page *pager
+		page, err = newPager(in.PageToken, in.StartRow, in.Rows,
+			DEFAULT_TABLE_ROWS, MAX_TABLE_ROWS,
+			"GetTable", in.Type, in.ClientId, in.FlowId, in.Artifact,
+			in.HuntId, in.NotebookId, in.CellId, in.TableId,
+			in.SortColumn, in.SortDirection, in.FilterColumn, in.FilterRegex)
+		if err != nil {
+			return nil, err
+		}
+		in.StartRow = page.Offset
+		in.Rows = page.Size
+
+		// getTable returns an extra row when there are more.
 		result, err = getTable(ctx, org_config_obj, in)
+		if err == nil {
+			length, token := page.Page(len(result.Rows))
+			result.Rows = result.Rows[:length]
+			result.NextPageToken = token
+		}
 	}
 
 	if err != nil {
 		return nil, err
 	}
 
+	self.addSamplingMetadata(org_config_obj, in, result)
+
 	if in.Artifact != "" {
 		manager, err := services.GetRepositoryManager(org_config_obj)
 		if err != nil {
@@ -993,6 +1026,11 @@ func (self *ApiServer) CreateDownloadFile(ctx context.Context,
 			"User is not allowed to create downloads (%v).", permissions))
 	}
 
+	if in.Justification == "" {
+		return nil, status.Error(codes.InvalidArgument,
+			"A justification is required to export data.")
+	}
+
 	// Log an audit event.
 	logging.GetLogger(org_config_obj, &logging.Audit).
 		WithFields(logrus.Fields{
@@ -1011,17 +1049,29 @@ func (self *ApiServer) CreateDownloadFile(ctx context.Context,
 	env := ordereddict.NewDict()
 	if in.FlowId != "" && in.ClientId != "" {
 		query = `SELECT create_flow_download(password=Password,
-      client_id=ClientId, flow_id=FlowId, type=DownloadType) AS VFSPath
+      client_id=ClientId, flow_id=FlowId, type=DownloadType,
+      template=Template, template_parameters=TemplateParameters,
+      max_rows=MaxRows, max_bytes=MaxBytes, start=StartTime, end=EndTime,
+      time_field=TimeField, justification=Justification, wait=TRUE) AS VFSPath
       FROM scope()`
 
+		template_parameters := ordereddict.NewDict()
+		for _, param := range in.TemplateParameters {
+			template_parameters.Set(param.Key, param.Value)
+		}
+
 		env.Set("ClientId", in.ClientId).
 			Set("FlowId", in.FlowId).
 			Set("Password", in.Password).
-			Set("DownloadType", in.DownloadType)
+			Set("DownloadType", in.DownloadType).
+			Set("Template", in.Template).
+			Set("TemplateParameters", template_parameters)
 
 	} else if in.HuntId != "" {
 		query = `SELECT create_hunt_download(password=Password,
-      hunt_id=HuntId, only_combined=OnlyCombined, format=Format) AS VFSPath
+      hunt_id=HuntId, only_combined=OnlyCombined, format=Format,
+      max_rows=MaxRows, max_bytes=MaxBytes, start=StartTime, end=EndTime,
+      time_field=TimeField, justification=Justification, wait=TRUE) AS VFSPath
       FROM scope()`
 
 		env.Set("HuntId", in.HuntId).
@@ -1030,34 +1080,79 @@ func (self *ApiServer) CreateDownloadFile(ctx context.Context,
 			Set("OnlyCombined", in.OnlyCombinedHunt)
 	}
 
-	manager, err := services.GetRepositoryManager(org_config_obj)
+	// Unset times are passed as NULL so they are ignored.
+	env.Set("MaxRows", in.MaxRows).
+		Set("MaxBytes", in.MaxBytes).
+		Set("StartTime", vfilter.Null{}).
+		Set("EndTime", vfilter.Null{}).
+		Set("TimeField", in.TimeField).
+		Set("Justification", in.Justification)
+
+	if in.StartTime > 0 {
+		env.Set("StartTime", in.StartTime)
+	}
+
+	if in.EndTime > 0 {
+		env.Set("EndTime", in.EndTime)
+	}
+
+	result := &api_proto.CreateDownloadResponse{}
+	if query == "" {
+		return result, nil
+	}
+
+	vql, err := vfilter.Parse(query)
 	if err != nil {
 		return nil, err
 	}
 
-	scope := manager.BuildScope(
-		services.ScopeBuilder{
-			Config:     org_config_obj,
-			Env:        env,
-			ACLManager: vql_subsystem.NewServerACLManager(org_config_obj, user_name),
-			Logger:     logging.NewPlainLogger(org_config_obj, &logging.FrontendComponent),
-		})
-	defer scope.Close()
+	manager, err := services.GetRepositoryManager(org_config_obj)
+	if err != nil {
+		return nil, err
+	}
 
-	vql, err := vfilter.Parse(query)
+	job_manager, err := services.GetJobManager(org_config_obj)
 	if err != nil {
 		return nil, err
 	}
 
-	sub_ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
+	description := fmt.Sprintf("Export hunt %v", in.HuntId)
+	if in.HuntId == "" {
+		description = fmt.Sprintf("Export flow %v of %v", in.FlowId, in.ClientId)
+	}
+
+	// Exports may take a long time so we create them in the
+	// background.
+	job, err := job_manager.StartJob(org_config_obj, &api_proto.Job{
+		Type:        "export",
+		Description: description,
+		Principal:   user_name,
+	}, func(ctx context.Context, progress services.JobProgress) (string, error) {
+		scope := manager.BuildScope(
+			services.ScopeBuilder{
+				Config:     org_config_obj,
+				Env:        env,
+				ACLManager: vql_subsystem.NewServerACLManager(org_config_obj, user_name),
+				Logger:     logging.NewPlainLogger(org_config_obj, &logging.FrontendComponent),
+			})
+		defer scope.Close()
+
+		vfs_path := ""
+		for row := range vql.Eval(ctx, scope) {
+			vfs_path = vql_subsystem.GetStringFromRow(scope, row, "VFSPath")
+		}
 
-	result := &api_proto.CreateDownloadResponse{}
-	for row := range vql.Eval(sub_ctx, scope) {
-		result.VfsPath = vql_subsystem.GetStringFromRow(scope, row, "VFSPath")
+		if vfs_path == "" {
+			return "", errors.New("Unable to create export")
+		}
+		return vfs_path, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return result, err
+	result.JobId = job.JobId
+	return result, nil
 }
 
 func startAPIServer(
@@ -1105,7 +1200,14 @@ func startAPIServer(
 		ClientCAs:    CA_Pool,
 	})
 
-	grpcServer := grpc.NewServer(grpc.Creds(creds))
+	// Audit first so rejected calls are also recorded.
+	audit := newAuditLogger(ctx, wg, config_obj, CA_Pool)
+	rbac := newRBACEnforcer(config_obj, CA_Pool)
+	grpcServer := grpc.NewServer(grpc.Creds(creds),
+		grpc.ChainUnaryInterceptor(audit.UnaryInterceptor,
+			rbac.UnaryInterceptor, validationInterceptor),
+		grpc.ChainStreamInterceptor(audit.StreamInterceptor,
+			rbac.StreamInterceptor))
 	api_proto.RegisterAPIServer(
 		grpcServer,
 		&ApiServer{
@@ -1113,6 +1215,8 @@ func startAPIServer(
 			ca_pool:            CA_Pool,
 			api_client_factory: grpc_client.GRPCAPIClient{},
 			wg:                 wg,
+			cache:              NewApiCache(ctx, wg, config_obj),
+			audit:              audit,
 		},
 	)
 	// Register reflection service.
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"www.velocidex.com/golang/velociraptor/acls"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/apikeys"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/constants"
+	"www.velocidex.com/golang/velociraptor/json"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+func (self *ApiServer) CreateApiKey(
+	ctx context.Context,
+	in *api_proto.CreateApiKeyRequest) (*api_proto.CreateApiKeyResponse, error) {
+
+	defer Instrument("CreateApiKey")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_record.Name
+	permissions := acls.SERVER_ADMIN
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to issue API keys.")
+	}
+
+	root_config_obj, err := getRootConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := apikeys.Create(root_config_obj, org_config_obj, in, user_name)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return result, nil
+}
+
+func (self *ApiServer) ListApiKeys(
+	ctx context.Context,
+	in *api_proto.ListApiKeysRequest) (*api_proto.ApiKeys, error) {
+
+	defer Instrument("ListApiKeys")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_record.Name
+	permissions := acls.SERVER_ADMIN
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to view API keys.")
+	}
+
+	root_config_obj, err := getRootConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return apikeys.List(root_config_obj, org_config_obj.OrgId, in.Principal)
+}
+
+func (self *ApiServer) RevokeApiKey(
+	ctx context.Context,
+	in *api_proto.RevokeApiKeyRequest) (*emptypb.Empty, error) {
+
+	defer Instrument("RevokeApiKey")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_record.Name
+	permissions := acls.SERVER_ADMIN
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to revoke API keys.")
+	}
+
+	root_config_obj, err := getRootConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	err = apikeys.Revoke(root_config_obj, org_config_obj.OrgId, in.Id, user_name)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// Requests carrying an API key are authenticated by the key and
+// passed straight to the handler. Other requests go through the
+// usual authenticator. API keys are not sent by browsers so these
+// requests do not need CSRF protection.
+func apiKeyHandler(config_obj *config_proto.Config,
+	authenticated http.Handler, parent http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := getBearerToken(r)
+		if !ok || !strings.HasPrefix(token, apikeys.TOKEN_PREFIX) {
+			authenticated.ServeHTTP(w, r)
+			return
+		}
+
+		key, err := apikeys.Verify(config_obj, token)
+		if err != nil {
+			logging.GetLogger(config_obj, &logging.Audit).
+				WithFields(logrus.Fields{
+					"remote": r.RemoteAddr,
+					"status": http.StatusUnauthorized,
+					"error":  err.Error(),
+				}).Error("Invalid API key")
+
+			http.Error(w, "authorization failed", http.StatusUnauthorized)
+			return
+		}
+
+		// The key is only valid in the org it was issued in so the
+		// caller may not select another org.
+		r.Header.Del("Grpc-Metadata-OrgId")
+		user_info := &api_proto.VelociraptorUser{
+			Name:       key.Principal,
+			CurrentOrg: key.OrgId,
+		}
+
+		// Must use json encoding because grpc can not handle
+		// binary data in metadata.
+		serialized, _ := json.Marshal(user_info)
+		ctx := context.WithValue(
+			r.Context(), constants.GRPC_USER_CONTEXT, string(serialized))
+
+		GetLoggingHandler(config_obj)(parent).ServeHTTP(
+			w, r.WithContext(ctx))
+	})
+}
+
+func getBearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimPrefix(header, "Bearer ")), true
+}
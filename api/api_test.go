@@ -0,0 +1,370 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"www.velocidex.com/golang/velociraptor/acls"
+	actions_proto "www.velocidex.com/golang/velociraptor/actions/proto"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/approvals"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/file_store/test_utils"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/vtesting/assert"
+)
+
+type testUserKey struct{}
+
+// Takes the user from the context instead of the gRPC peer
+// certificate so tests can call the API handlers directly.
+type testUserManager struct {
+	services.UserManager
+	config_obj *config_proto.Config
+}
+
+func (self testUserManager) GetUserFromContext(ctx context.Context) (
+	*api_proto.VelociraptorUser, *config_proto.Config, error) {
+	name, _ := ctx.Value(testUserKey{}).(string)
+	return &api_proto.VelociraptorUser{Name: name}, self.config_obj, nil
+}
+
+var apiTestArtifacts = []string{`
+name: Custom.Safe
+sources:
+- query: SELECT * FROM info()
+`, `
+name: Custom.Dangerous
+sources:
+- query: SELECT * FROM execve(argv=["ls"])
+`, `
+name: Custom.RawDisk
+required_capabilities:
+- raw_disk
+sources:
+- query: SELECT * FROM info()
+`}
+
+type ApiTestSuite struct {
+	test_utils.TestSuite
+	server    *ApiServer
+	client_id string
+}
+
+func (self *ApiTestSuite) SetupTest() {
+	self.ConfigObj = self.LoadConfig()
+	self.LoadArtifacts(apiTestArtifacts)
+	self.TestSuite.SetupTest()
+
+	services.RegisterUserManager(testUserManager{
+		UserManager: services.GetUserManager(),
+		config_obj:  self.ConfigObj,
+	})
+
+	self.server = &ApiServer{
+		wg:    &sync.WaitGroup{},
+		cache: NewApiCache(self.Ctx, &sync.WaitGroup{}, self.ConfigObj),
+	}
+
+	self.client_id = "C.1234"
+	client_info_manager, err := services.GetClientInfoManager(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	err = client_info_manager.Set(&services.ClientInfo{actions_proto.ClientInfo{
+		ClientId: self.client_id,
+	}})
+	assert.NoError(self.T(), err)
+
+	for _, user := range []string{"alice", "bob"} {
+		err = acls.GrantRoles(self.ConfigObj, user, []string{"administrator"})
+		assert.NoError(self.T(), err)
+	}
+}
+
+// A context for calls made by the user.
+func (self *ApiTestSuite) userContext(name string) context.Context {
+	return context.WithValue(self.Ctx, testUserKey{}, name)
+}
+
+// The requests which were scheduled for the client.
+func (self *ApiTestSuite) getRequests(flow_id string) []*actions_proto.VQLCollectorArgs {
+	launcher, err := services.GetLauncher(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	requests, err := launcher.GetFlowRequests(
+		self.ConfigObj, self.client_id, flow_id, 0, 100)
+	assert.NoError(self.T(), err)
+
+	result := []*actions_proto.VQLCollectorArgs{}
+	for _, item := range requests.Items {
+		result = append(result, item.VQLClientAction)
+	}
+	return result
+}
+
+// An approval requested by alice and granted by bob.
+func (self *ApiTestSuite) grantApproval(
+	client_id string, artifacts ...string) *api_proto.Approval {
+	manager, err := services.GetRepositoryManager(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	repository, err := manager.GetGlobalRepository(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	approval, err := approvals.RequestApproval(self.ConfigObj, repository,
+		&api_proto.Approval{
+			ClientId:  client_id,
+			Artifacts: artifacts,
+		}, "alice")
+	assert.NoError(self.T(), err)
+
+	approval, err = approvals.GrantApproval(
+		self.ConfigObj, approval.ApprovalId, "bob")
+	assert.NoError(self.T(), err)
+
+	return approval
+}
+
+func (self *ApiTestSuite) TestCompiledCollectorArgs() {
+	injected := &actions_proto.VQLCollectorArgs{
+		Query: []*actions_proto.VQLRequest{{
+			VQL: `SELECT * FROM execve(argv=["rm", "-rf", "/"])`,
+		}},
+	}
+
+	// Users may not provide their own compiled requests.
+	result, err := self.server.CollectArtifact(self.userContext("alice"),
+		&flows_proto.ArtifactCollectorArgs{
+			ClientId:              self.client_id,
+			Artifacts:             []string{"Custom.Safe"},
+			CompiledCollectorArgs: []*actions_proto.VQLCollectorArgs{injected},
+		})
+	assert.NoError(self.T(), err)
+
+	for _, request := range self.getRequests(result.FlowId) {
+		for _, query := range request.Query {
+			assert.True(self.T(), !strings.Contains(query.VQL, "execve"))
+		}
+	}
+
+	// The server's own requests are used as they are.
+	result, err = self.server.CollectArtifact(
+		self.userContext(self.ConfigObj.Client.PinnedServerName),
+		&flows_proto.ArtifactCollectorArgs{
+			ClientId:              self.client_id,
+			Artifacts:             []string{"Custom.Safe"},
+			CompiledCollectorArgs: []*actions_proto.VQLCollectorArgs{injected},
+		})
+	assert.NoError(self.T(), err)
+
+	requests := self.getRequests(result.FlowId)
+	assert.Equal(self.T(), 1, len(requests))
+	assert.Equal(self.T(), injected.Query[0].VQL, requests[0].Query[0].VQL)
+}
+
+func (self *ApiTestSuite) TestDryRun() {
+	self.ConfigObj.TwoPersonIntegrity = &config_proto.TwoPersonIntegrityConfig{
+		Enabled: true,
+	}
+	defer func() { self.ConfigObj.TwoPersonIntegrity = nil }()
+
+	ctx := self.userContext("alice")
+	request := func(artifact string) *flows_proto.ArtifactCollectorArgs {
+		return &flows_proto.ArtifactCollectorArgs{
+			ClientId:  self.client_id,
+			Artifacts: []string{artifact},
+			DryRun:    true,
+		}
+	}
+
+	// A dry run compiles the requests without scheduling them.
+	result, err := self.server.CollectArtifact(ctx, request("Custom.Safe"))
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), "", result.FlowId)
+	assert.True(self.T(), len(result.Request.CompiledCollectorArgs) > 0)
+
+	_, err = self.server.CollectArtifact(ctx, &flows_proto.ArtifactCollectorArgs{
+		Artifacts: []string{"Custom.Safe"},
+		DryRun:    true,
+	})
+	assert.Equal(self.T(), codes.InvalidArgument, status.Code(err))
+
+	// Dangerous collections need an approval.
+	_, err = self.server.CollectArtifact(ctx, request("Custom.Dangerous"))
+	assert.Equal(self.T(), codes.FailedPrecondition, status.Code(err))
+
+	approval := self.grantApproval(self.client_id, "Custom.Dangerous")
+
+	// The dry run does not use up the approval.
+	dry_run := request("Custom.Dangerous")
+	dry_run.ApprovalId = approval.ApprovalId
+	_, err = self.server.CollectArtifact(ctx, dry_run)
+	assert.NoError(self.T(), err)
+
+	approval, err = approvals.GetApproval(self.ConfigObj, approval.ApprovalId)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), approvals.STATE_APPROVED, approval.State)
+
+	launch := request("Custom.Dangerous")
+	launch.DryRun = false
+	launch.ApprovalId = approval.ApprovalId
+	result, err = self.server.CollectArtifact(ctx, launch)
+	assert.NoError(self.T(), err)
+	assert.True(self.T(), result.FlowId != "")
+
+	approval, err = approvals.GetApproval(self.ConfigObj, approval.ApprovalId)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), approvals.STATE_USED, approval.State)
+}
+
+func (self *ApiTestSuite) TestDryRunCapabilities() {
+	ctx := self.userContext("alice")
+
+	// The client's capabilities are checked.
+	client_info_manager, err := services.GetClientInfoManager(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	err = client_info_manager.Set(&services.ClientInfo{actions_proto.ClientInfo{
+		ClientId:               self.client_id,
+		CapabilitiesAdvertised: true,
+	}})
+	assert.NoError(self.T(), err)
+
+	_, err = self.server.CollectArtifact(ctx, &flows_proto.ArtifactCollectorArgs{
+		ClientId:  self.client_id,
+		Artifacts: []string{"Custom.RawDisk"},
+		DryRun:    true,
+	})
+	assert.Equal(self.T(), codes.FailedPrecondition, status.Code(err))
+}
+
+func (self *ApiTestSuite) TestCollectArtifactMulti() {
+	self.ConfigObj.TwoPersonIntegrity = &config_proto.TwoPersonIntegrityConfig{
+		Enabled: true,
+	}
+	defer func() { self.ConfigObj.TwoPersonIntegrity = nil }()
+
+	client_info_manager, err := services.GetClientInfoManager(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	for _, client_id := range []string{"C.1", "C.2", "C.3"} {
+		err = client_info_manager.Set(&services.ClientInfo{actions_proto.ClientInfo{
+			ClientId: client_id,
+		}})
+		assert.NoError(self.T(), err)
+	}
+
+	collect := func(in *api_proto.CollectArtifactMultiRequest) map[string]*api_proto.CollectArtifactMultiResult {
+		response, err := self.server.CollectArtifactMulti(
+			self.userContext("alice"), in)
+		assert.NoError(self.T(), err)
+
+		result := make(map[string]*api_proto.CollectArtifactMultiResult)
+		for _, item := range response.Items {
+			result[item.ClientId] = item
+		}
+		return result
+	}
+
+	// One client failing does not stop the others.
+	result := collect(&api_proto.CollectArtifactMultiRequest{
+		Request: &flows_proto.ArtifactCollectorArgs{
+			Artifacts: []string{"Custom.Safe"},
+		},
+		ClientIds: []string{"C.1", "C.Missing", "C.2"},
+	})
+	assert.Equal(self.T(), 3, len(result))
+	assert.True(self.T(), result["C.1"].FlowId != "")
+	assert.True(self.T(), result["C.2"].FlowId != "")
+	assert.Equal(self.T(), "", result["C.Missing"].FlowId)
+	assert.Equal(self.T(), codes.NotFound.String(), result["C.Missing"].Code)
+	assert.True(self.T(), result["C.Missing"].Error != "")
+
+	// Each client needs its own approval.
+	approval_1 := self.grantApproval("C.1", "Custom.Dangerous")
+	approval_2 := self.grantApproval("C.2", "Custom.Dangerous")
+
+	result = collect(&api_proto.CollectArtifactMultiRequest{
+		Request: &flows_proto.ArtifactCollectorArgs{
+			Artifacts: []string{"Custom.Dangerous"},
+		},
+		ClientIds: []string{"C.1", "C.2", "C.3"},
+		ApprovalIds: map[string]string{
+			"C.1": approval_1.ApprovalId,
+			"C.2": approval_2.ApprovalId,
+		},
+	})
+	assert.True(self.T(), result["C.1"].FlowId != "")
+	assert.True(self.T(), result["C.2"].FlowId != "")
+	assert.Equal(self.T(), "", result["C.3"].FlowId)
+	assert.Equal(self.T(), codes.FailedPrecondition.String(), result["C.3"].Code)
+
+	// An approval can not be used for another client.
+	approval_3 := self.grantApproval("C.3", "Custom.Dangerous")
+	result = collect(&api_proto.CollectArtifactMultiRequest{
+		Request: &flows_proto.ArtifactCollectorArgs{
+			Artifacts:  []string{"Custom.Dangerous"},
+			ApprovalId: approval_3.ApprovalId,
+		},
+		ClientIds: []string{"C.1", "C.3"},
+	})
+	assert.Equal(self.T(), codes.FailedPrecondition.String(), result["C.1"].Code)
+	assert.True(self.T(), result["C.3"].FlowId != "")
+}
+
+func (self *ApiTestSuite) TestSamplingMetadata() {
+	ctx := self.userContext("alice")
+	result, err := self.server.CollectArtifact(ctx,
+		&flows_proto.ArtifactCollectorArgs{
+			ClientId:   self.client_id,
+			Artifacts:  []string{"Custom.Safe"},
+			SampleRows: 5,
+		})
+	assert.NoError(self.T(), err)
+
+	request := &api_proto.GetTableRequest{
+		ClientId: self.client_id,
+		FlowId:   result.FlowId,
+		Artifact: "Custom.Safe",
+	}
+
+	table, err := self.server.GetTable(ctx, request)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), uint64(5), table.SampleRows)
+
+	// Cached flow details are used when available.
+	self.server.cache.SetFlowDetails(self.ConfigObj, self.client_id,
+		result.FlowId, &api_proto.FlowDetails{
+			Context: &flows_proto.ArtifactCollectorContext{
+				State: flows_proto.ArtifactCollectorContext_FINISHED,
+				Request: &flows_proto.ArtifactCollectorArgs{
+					SampleRows: 7,
+				},
+			},
+		})
+
+	table, err = self.server.GetTable(ctx, request)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), uint64(7), table.SampleRows)
+}
+
+func (self *ApiTestSuite) TestDownloadJustification() {
+	ctx := self.userContext("alice")
+	_, err := self.server.CreateDownloadFile(ctx,
+		&api_proto.CreateDownloadRequest{
+			ClientId: self.client_id,
+			FlowId:   "F.1234",
+		})
+	assert.Error(self.T(), err)
+	assert.Equal(self.T(), codes.InvalidArgument, status.Code(err))
+}
+
+func TestApiServer(t *testing.T) {
+	suite.Run(t, &ApiTestSuite{})
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"errors"
+
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"www.velocidex.com/golang/velociraptor/acls"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/approvals"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+// Any investigator may request an approval for a dangerous
+// collection, but it must be granted by a different investigator.
+func (self *ApiServer) RequestApproval(
+	ctx context.Context,
+	in *api_proto.Approval) (*api_proto.Approval, error) {
+
+	defer Instrument("RequestApproval")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_record.Name
+	permissions := acls.COLLECT_CLIENT
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to request approvals.")
+	}
+
+	manager, err := services.GetRepositoryManager(org_config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	repository, err := manager.GetGlobalRepository(org_config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	approval, err := approvals.RequestApproval(
+		org_config_obj, repository, in, user_name)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return approval, nil
+}
+
+func (self *ApiServer) GrantApproval(
+	ctx context.Context,
+	in *api_proto.Approval) (*api_proto.Approval, error) {
+
+	defer Instrument("GrantApproval")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_record.Name
+	permissions := acls.COLLECT_CLIENT
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to grant approvals.")
+	}
+
+	approval, err := approvals.GrantApproval(
+		org_config_obj, in.ApprovalId, user_name)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return approval, nil
+}
+
+func (self *ApiServer) GetApprovals(
+	ctx context.Context,
+	in *api_proto.GetApprovalsRequest) (*api_proto.ApprovalList, error) {
+
+	defer Instrument("GetApprovals")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_record.Name
+	permissions := acls.READ_RESULTS
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to view approvals.")
+	}
+
+	return approvals.GetApprovals(org_config_obj, in.State)
+}
+
+// Launching a dangerous collection without an approval is a failed
+// precondition.
+func approvalError(err error) error {
+	if errors.Is(err, approvals.ApprovalRequiredError) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return err
+}
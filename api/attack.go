@@ -0,0 +1,325 @@
+package api
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"www.velocidex.com/golang/velociraptor/acls"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/utils"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+)
+
+const (
+	DEFAULT_ATTACK_HUNT_DAYS = 30
+)
+
+// Accumulates the coverage of each technique. Clients are tracked by
+// id so clients reached by several labels or hunts are only counted
+// once.
+type attackCoverage struct {
+	total_clients uint64
+
+	// Artifact name -> techniques it is tagged with.
+	artifacts map[string][]string
+
+	techniques map[string]*api_proto.AttackTechniqueCoverage
+
+	// Technique -> monitored client ids. Techniques monitored on
+	// all clients are in global instead.
+	monitored map[string]map[string]bool
+	global    map[string]bool
+}
+
+func newAttackCoverage(total_clients uint64) *attackCoverage {
+	return &attackCoverage{
+		total_clients: total_clients,
+		artifacts:     make(map[string][]string),
+		techniques:    make(map[string]*api_proto.AttackTechniqueCoverage),
+		monitored:     make(map[string]map[string]bool),
+		global:        make(map[string]bool),
+	}
+}
+
+func (self *attackCoverage) addArtifact(name string, techniques []string) {
+	for _, technique := range techniques {
+		coverage := self.getTechnique(technique)
+		coverage.Artifacts = append(coverage.Artifacts, name)
+	}
+	self.artifacts[name] = techniques
+}
+
+func (self *attackCoverage) getTechnique(
+	technique string) *api_proto.AttackTechniqueCoverage {
+	coverage, pres := self.techniques[technique]
+	if !pres {
+		coverage = &api_proto.AttackTechniqueCoverage{Technique: technique}
+		self.techniques[technique] = coverage
+	}
+	return coverage
+}
+
+// Record a client monitoring artifact deployed on the clients with
+// the label (or all clients if label is empty).
+func (self *attackCoverage) addMonitoring(
+	artifact, label string, client_ids []string) {
+	for _, technique := range self.artifacts[artifact] {
+		coverage := self.getTechnique(technique)
+		if !utils.InString(coverage.MonitoringArtifacts, artifact) {
+			coverage.MonitoringArtifacts = append(
+				coverage.MonitoringArtifacts, artifact)
+		}
+
+		if label == "" {
+			self.global[technique] = true
+			continue
+		}
+
+		if !utils.InString(coverage.MonitoredLabels, label) {
+			coverage.MonitoredLabels = append(coverage.MonitoredLabels, label)
+		}
+
+		clients, pres := self.monitored[technique]
+		if !pres {
+			clients = make(map[string]bool)
+			self.monitored[technique] = clients
+		}
+		for _, client_id := range client_ids {
+			clients[client_id] = true
+		}
+	}
+}
+
+func (self *attackCoverage) addServerMonitoring(artifact string) {
+	for _, technique := range self.artifacts[artifact] {
+		coverage := self.getTechnique(technique)
+		coverage.ServerMonitored = true
+		if !utils.InString(coverage.MonitoringArtifacts, artifact) {
+			coverage.MonitoringArtifacts = append(
+				coverage.MonitoringArtifacts, artifact)
+		}
+	}
+}
+
+// Hunts do not record which clients they reached so a technique is
+// considered collected on the largest number of clients scheduled by
+// any of its hunts.
+func (self *attackCoverage) addHunt(hunt *api_proto.Hunt) {
+	var scheduled uint64
+	if hunt.Stats != nil {
+		scheduled = hunt.Stats.TotalClientsScheduled
+	}
+
+	for _, artifact := range hunt.StartRequest.Artifacts {
+		for _, technique := range self.artifacts[artifact] {
+			coverage := self.getTechnique(technique)
+			if !utils.InString(coverage.Hunts, hunt.HuntId) {
+				coverage.Hunts = append(coverage.Hunts, hunt.HuntId)
+			}
+			if scheduled > coverage.HuntedClients {
+				coverage.HuntedClients = scheduled
+			}
+		}
+	}
+}
+
+func (self *attackCoverage) result() *api_proto.AttackCoverage {
+	result := &api_proto.AttackCoverage{
+		Timestamp:    uint64(time.Now().Unix()),
+		TotalClients: self.total_clients,
+	}
+
+	for technique, coverage := range self.techniques {
+		if self.global[technique] {
+			coverage.MonitoredClients = self.total_clients
+		} else {
+			coverage.MonitoredClients = uint64(len(self.monitored[technique]))
+		}
+
+		if coverage.HuntedClients > self.total_clients {
+			coverage.HuntedClients = self.total_clients
+		}
+
+		if self.total_clients > 0 {
+			coverage.DetectionCoverage = float64(coverage.MonitoredClients) *
+				100 / float64(self.total_clients)
+			coverage.CollectionCoverage = float64(coverage.HuntedClients) *
+				100 / float64(self.total_clients)
+		}
+
+		sort.Strings(coverage.Artifacts)
+		sort.Strings(coverage.MonitoringArtifacts)
+		sort.Strings(coverage.MonitoredLabels)
+		sort.Strings(coverage.Hunts)
+		result.Techniques = append(result.Techniques, coverage)
+	}
+
+	sort.Slice(result.Techniques, func(i, j int) bool {
+		return result.Techniques[i].Technique < result.Techniques[j].Technique
+	})
+
+	return result
+}
+
+func (self *ApiServer) GetAttackCoverage(
+	ctx context.Context,
+	in *api_proto.AttackCoverageRequest) (*api_proto.AttackCoverage, error) {
+
+	defer Instrument("GetAttackCoverage")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_record.Name
+	permissions := acls.READ_RESULTS
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to view ATT&CK coverage.")
+	}
+
+	all_clients, err := getLabelClients(ctx, org_config_obj, "")
+	if err != nil {
+		return nil, err
+	}
+
+	coverage := newAttackCoverage(uint64(len(all_clients)))
+
+	manager, err := services.GetRepositoryManager(org_config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	repository, err := manager.GetGlobalRepository(org_config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := repository.List(ctx, org_config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		artifact, pres := repository.Get(org_config_obj, name)
+		if pres && len(artifact.MitreAttack) > 0 {
+			coverage.addArtifact(artifact.Name, artifact.MitreAttack)
+		}
+	}
+
+	// Detection coverage from the deployed monitoring artifacts.
+	client_event_manager, err := services.ClientEventManager(org_config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	table := client_event_manager.GetClientMonitoringState()
+	if table.Artifacts != nil {
+		for _, artifact := range table.Artifacts.Artifacts {
+			coverage.addMonitoring(artifact, "", nil)
+		}
+	}
+
+	for _, label_events := range table.LabelEvents {
+		if label_events.Artifacts == nil ||
+			len(label_events.Artifacts.Artifacts) == 0 {
+			continue
+		}
+
+		client_ids, err := getLabelClients(ctx, org_config_obj,
+			label_events.Label)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, artifact := range label_events.Artifacts.Artifacts {
+			coverage.addMonitoring(artifact, label_events.Label, client_ids)
+		}
+	}
+
+	server_state, err := getServerMonitoringState(org_config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, artifact := range server_state.Artifacts {
+		coverage.addServerMonitoring(artifact)
+	}
+
+	// Collection coverage from recent hunts.
+	hunt_days := in.HuntDays
+	if hunt_days == 0 {
+		hunt_days = DEFAULT_ATTACK_HUNT_DAYS
+	}
+	cutoff := time.Now().Add(
+		-time.Duration(hunt_days) * 24 * time.Hour)
+
+	hunt_dispatcher, err := services.GetHuntDispatcher(org_config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	err = hunt_dispatcher.ApplyFuncOnHunts(func(hunt *api_proto.Hunt) error {
+		if hunt.State == api_proto.Hunt_ARCHIVED || hunt.StartRequest == nil ||
+			int64(hunt.CreateTime) < cutoff.UnixNano()/1000 {
+			return nil
+		}
+		coverage.addHunt(hunt)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return coverage.result(), nil
+}
+
+// List the ids of the clients with the label, or all clients if the
+// label is empty.
+func getLabelClients(ctx context.Context,
+	config_obj *config_proto.Config, label string) ([]string, error) {
+	indexer, err := services.GetIndexer(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	search_term := "all"
+	if label != "" {
+		search_term = "label:" + label
+	}
+
+	scope := vql_subsystem.MakeScope()
+	search_chan, err := indexer.SearchClientsChan(
+		ctx, scope, config_obj, search_term, "")
+	if err != nil {
+		return nil, err
+	}
+
+	result := []string{}
+	for api_client := range search_chan {
+		// The index search matches label prefixes.
+		if label != "" && !hasLabel(api_client, label) {
+			continue
+		}
+		result = append(result, api_client.ClientId)
+	}
+	return result, nil
+}
+
+func hasLabel(api_client *api_proto.ApiClient, label string) bool {
+	for _, l := range api_client.Labels {
+		if strings.EqualFold(l, label) {
+			return true
+		}
+	}
+	return false
+}
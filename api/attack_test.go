@@ -0,0 +1,51 @@
+package api
+
+import (
+	"testing"
+
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/vtesting/assert"
+)
+
+func TestAttackCoverage(t *testing.T) {
+	coverage := newAttackCoverage(10)
+	coverage.addArtifact("Windows.Events.ServiceCreation", []string{"T1543.003"})
+	coverage.addArtifact("Windows.Detection.PsexecService",
+		[]string{"T1543.003", "T1569.002"})
+	coverage.addArtifact("Windows.Detection.TemplateInjection", []string{"T1221"})
+
+	// Clients overlapping between labels are only counted once.
+	coverage.addMonitoring("Windows.Events.ServiceCreation", "Servers",
+		[]string{"C.1", "C.2", "C.3"})
+	coverage.addMonitoring("Windows.Detection.PsexecService", "DCs",
+		[]string{"C.3", "C.4"})
+
+	coverage.addHunt(&api_proto.Hunt{
+		HuntId: "H.1",
+		StartRequest: &flows_proto.ArtifactCollectorArgs{
+			Artifacts: []string{"Windows.Detection.TemplateInjection"},
+		},
+		Stats: &api_proto.HuntStats{TotalClientsScheduled: 5},
+	})
+
+	result := coverage.result()
+	assert.Equal(t, uint64(10), result.TotalClients)
+	assert.Equal(t, 3, len(result.Techniques))
+
+	t1221 := result.Techniques[0]
+	assert.Equal(t, "T1221", t1221.Technique)
+	assert.Equal(t, []string{"H.1"}, t1221.Hunts)
+	assert.Equal(t, uint64(0), t1221.MonitoredClients)
+	assert.Equal(t, float64(50), t1221.CollectionCoverage)
+
+	t1543 := result.Techniques[1]
+	assert.Equal(t, "T1543.003", t1543.Technique)
+	assert.Equal(t, []string{"DCs", "Servers"}, t1543.MonitoredLabels)
+	assert.Equal(t, uint64(4), t1543.MonitoredClients)
+	assert.Equal(t, float64(40), t1543.DetectionCoverage)
+
+	t1569 := result.Techniques[2]
+	assert.Equal(t, "T1569.002", t1569.Technique)
+	assert.Equal(t, uint64(2), t1569.MonitoredClients)
+}
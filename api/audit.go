@@ -0,0 +1,389 @@
+package api
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"www.velocidex.com/golang/velociraptor/acls"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/file_store"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/paths/artifacts"
+	"www.velocidex.com/golang/velociraptor/result_sets"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/services/users"
+	"www.velocidex.com/golang/velociraptor/utils"
+)
+
+const (
+	AUDIT_ARTIFACT = "Server.Internal.APIAudit"
+
+	// Requests larger than this are truncated in the log.
+	MAX_AUDIT_REQUEST_SIZE = 4096
+
+	DEFAULT_AUDIT_LOG_LIMIT = 1000
+
+	// Entries waiting to be written before API calls block.
+	AUDIT_QUEUE_SIZE = 1000
+)
+
+var (
+	// Calls between server components rather than user actions.
+	unaudited_methods = []string{
+		"GetSubject", "SetSubject", "DeleteSubject", "ListChildren",
+		"PushEvents", "WriteEvent", "WatchEvent", "Check",
+	}
+
+	// String fields with these names are never logged.
+	secret_fields = []string{
+		"password", "secret", "token", "private_key", "api_key",
+		"credentials",
+	}
+)
+
+// Records API calls in the Server.Internal.APIAudit event
+// artifact. Entries are hash chained so deleting or changing an
+// entry can be detected.
+type auditLogger struct {
+	config_obj *config_proto.Config
+	ca_pool    *x509.CertPool
+
+	// Entries are chained and written by a single goroutine so API
+	// calls do not wait for each other's writes.
+	queue chan *api_proto.AuditLogEntry
+
+	// Only accessed by the writer goroutine.
+	loaded    bool
+	last_hash string
+}
+
+func newAuditLogger(
+	ctx context.Context, wg *sync.WaitGroup,
+	config_obj *config_proto.Config, ca_pool *x509.CertPool) *auditLogger {
+	self := &auditLogger{
+		config_obj: config_obj,
+		ca_pool:    ca_pool,
+		queue:      make(chan *api_proto.AuditLogEntry, AUDIT_QUEUE_SIZE),
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		logger := logging.GetLogger(config_obj, &logging.FrontendComponent)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case entry := <-self.queue:
+				err := self.write(entry)
+				if err != nil {
+					logger.Error("Audit log: %v", err)
+				}
+			}
+		}
+	}()
+
+	return self
+}
+
+func (self *auditLogger) UnaryInterceptor(ctx context.Context, req interface{},
+	info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	self.record(ctx, info.FullMethod, req, start, err)
+	return resp, err
+}
+
+func (self *auditLogger) StreamInterceptor(srv interface{},
+	stream grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	start := time.Now()
+	wrapped := &auditServerStream{ServerStream: stream}
+	err := handler(srv, wrapped)
+	self.record(stream.Context(), info.FullMethod, wrapped.request, start, err)
+	return err
+}
+
+// Remember the first message so streaming calls can be logged with
+// their request.
+type auditServerStream struct {
+	grpc.ServerStream
+	request interface{}
+}
+
+func (self *auditServerStream) RecvMsg(m interface{}) error {
+	err := self.ServerStream.RecvMsg(m)
+	if err == nil && self.request == nil {
+		self.request = m
+	}
+	return err
+}
+
+func (self *auditLogger) record(ctx context.Context,
+	method string, req interface{}, start time.Time, call_err error) {
+	if utils.InString(unaudited_methods, path.Base(method)) {
+		return
+	}
+
+	entry := &api_proto.AuditLogEntry{
+		Timestamp:  uint64(start.UnixNano() / 1000),
+		Method:     method,
+		Status:     status.Code(call_err).String(),
+		DurationMs: uint64(time.Since(start) / time.Millisecond),
+	}
+
+	if call_err != nil {
+		entry.Error = status.Convert(call_err).Message()
+	}
+
+	message, ok := req.(proto.Message)
+	if ok {
+		entry.Request = sanitizeRequest(message)
+	}
+
+	// Record the caller even when they are not a known user.
+	entry.Principal = users.GetGRPCUserInfo(
+		self.config_obj, ctx, self.ca_pool).Name
+
+	select {
+	case <-ctx.Done():
+		logger := logging.GetLogger(self.config_obj, &logging.FrontendComponent)
+		logger.Error("Audit log: Unable to record %v: %v", method, ctx.Err())
+
+	case self.queue <- entry:
+	}
+}
+
+func (self *auditLogger) write(entry *api_proto.AuditLogEntry) error {
+	journal, err := services.GetJournal(self.config_obj)
+	if err != nil {
+		return err
+	}
+
+	// Continue the chain from the previous run of the server.
+	if !self.loaded {
+		self.last_hash = self.loadLastHash()
+		self.loaded = true
+	}
+
+	entry.PrevHash = self.last_hash
+	entry.Hash = auditEntryHash(entry)
+
+	err = journal.PushRowsToArtifact(self.config_obj,
+		[]*ordereddict.Dict{auditEntryToRow(entry)},
+		AUDIT_ARTIFACT, "server", "")
+	if err != nil {
+		return err
+	}
+
+	self.last_hash = entry.Hash
+	return nil
+}
+
+func (self *auditLogger) loadLastHash() string {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	path_manager, err := artifacts.NewArtifactPathManager(
+		self.config_obj, "server", "", AUDIT_ARTIFACT)
+	if err != nil {
+		return ""
+	}
+
+	reader, err := result_sets.NewTimedResultSetReader(ctx,
+		file_store.GetFileStore(self.config_obj), path_manager)
+	if err != nil {
+		return ""
+	}
+	defer reader.Close()
+
+	files := reader.GetAvailableFiles(ctx)
+	if len(files) == 0 {
+		return ""
+	}
+
+	err = reader.SeekToTime(files[len(files)-1].StartTime)
+	if err != nil {
+		return ""
+	}
+
+	last_hash := ""
+	for row := range reader.Rows(ctx) {
+		last_hash, _ = row.GetString("Hash")
+	}
+	return last_hash
+}
+
+func (self *ApiServer) GetAuditLog(
+	ctx context.Context,
+	in *api_proto.GetAuditLogRequest) (*api_proto.GetAuditLogResponse, error) {
+
+	defer Instrument("GetAuditLog")()
+
+	users := services.GetUserManager()
+	user_record, _, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The audit log covers all orgs.
+	config_obj := self.audit.config_obj
+	perm, err := acls.CheckAccess(config_obj, user_record.Name, acls.SERVER_ADMIN)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to view the audit log.")
+	}
+
+	path_manager, err := artifacts.NewArtifactPathManager(
+		config_obj, "server", "", AUDIT_ARTIFACT)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := result_sets.NewTimedResultSetReader(ctx,
+		file_store.GetFileStore(config_obj), path_manager)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	err = reader.SeekToTime(time.Unix(in.StartTime, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	if in.EndTime != 0 {
+		reader.SetMaxTime(time.Unix(in.EndTime, 0))
+	}
+
+	limit := in.Limit
+	if limit == 0 {
+		limit = DEFAULT_AUDIT_LOG_LIMIT
+	}
+
+	// The chain is verified over all the entries read, including
+	// those which are filtered out.
+	result := &api_proto.GetAuditLogResponse{Verified: true}
+	last_hash := ""
+	first := true
+
+	for row := range reader.Rows(ctx) {
+		entry := auditEntryFromRow(row)
+		if entry.Hash != auditEntryHash(entry) ||
+			(!first && entry.PrevHash != last_hash) {
+			result.Verified = false
+		}
+		last_hash = entry.Hash
+		first = false
+
+		if (in.Principal != "" && entry.Principal != in.Principal) ||
+			(in.Method != "" && path.Base(entry.Method) != in.Method) {
+			continue
+		}
+
+		result.Items = append(result.Items, entry)
+		if uint64(len(result.Items)) >= limit {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// The hash covers every field of the entry and the previous hash.
+func auditEntryHash(entry *api_proto.AuditLogEntry) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%d\x00%s\x00%s\x00%s\x00%s\x00%s\x00%d\x00%s",
+		entry.Timestamp, entry.Principal, entry.Method, entry.Request,
+		entry.Status, entry.Error, entry.DurationMs, entry.PrevHash)))
+	return hex.EncodeToString(hash[:])
+}
+
+func auditEntryToRow(entry *api_proto.AuditLogEntry) *ordereddict.Dict {
+	return ordereddict.NewDict().
+		Set("Timestamp", entry.Timestamp).
+		Set("Principal", entry.Principal).
+		Set("Method", entry.Method).
+		Set("Request", entry.Request).
+		Set("Status", entry.Status).
+		Set("Error", entry.Error).
+		Set("DurationMs", entry.DurationMs).
+		Set("PrevHash", entry.PrevHash).
+		Set("Hash", entry.Hash)
+}
+
+func auditEntryFromRow(row *ordereddict.Dict) *api_proto.AuditLogEntry {
+	result := &api_proto.AuditLogEntry{
+		Timestamp:  uint64(utils.GetInt64(row, "Timestamp")),
+		DurationMs: uint64(utils.GetInt64(row, "DurationMs")),
+	}
+	result.Principal, _ = row.GetString("Principal")
+	result.Method, _ = row.GetString("Method")
+	result.Request, _ = row.GetString("Request")
+	result.Status, _ = row.GetString("Status")
+	result.Error, _ = row.GetString("Error")
+	result.PrevHash, _ = row.GetString("PrevHash")
+	result.Hash, _ = row.GetString("Hash")
+	return result
+}
+
+// Serialize the request without secrets such as passwords.
+func sanitizeRequest(message proto.Message) string {
+	message = proto.Clone(message)
+	redactSecrets(message.ProtoReflect())
+
+	serialized, err := protojson.MarshalOptions{
+		UseProtoNames: true}.Marshal(message)
+	if err != nil {
+		return ""
+	}
+
+	if len(serialized) > MAX_AUDIT_REQUEST_SIZE {
+		return string(serialized[:MAX_AUDIT_REQUEST_SIZE]) + "..."
+	}
+	return string(serialized)
+}
+
+func redactSecrets(message protoreflect.Message) {
+	secrets := []protoreflect.FieldDescriptor{}
+
+	message.Range(func(field protoreflect.FieldDescriptor,
+		value protoreflect.Value) bool {
+		switch {
+		case field.IsMap():
+
+		case field.Kind() == protoreflect.StringKind && !field.IsList() &&
+			utils.InString(secret_fields, string(field.Name())):
+			secrets = append(secrets, field)
+
+		case field.Kind() == protoreflect.MessageKind && field.IsList():
+			list := value.List()
+			for i := 0; i < list.Len(); i++ {
+				redactSecrets(list.Get(i).Message())
+			}
+
+		case field.Kind() == protoreflect.MessageKind:
+			redactSecrets(value.Message())
+		}
+		return true
+	})
+
+	for _, field := range secrets {
+		message.Set(field, protoreflect.ValueOfString("<redacted>"))
+	}
+}
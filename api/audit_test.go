@@ -0,0 +1,51 @@
+package api
+
+import (
+	"testing"
+
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/vtesting/assert"
+)
+
+func TestAuditSanitizeRequest(t *testing.T) {
+	request := &api_proto.CreateDownloadRequest{
+		FlowId:   "F.1234",
+		Password: "hunter2",
+	}
+
+	serialized := sanitizeRequest(request)
+	assert.Equal(t, `{"flow_id":"F.1234","password":"<redacted>"}`,
+		removeSpaces(serialized))
+
+	// The original request is not modified.
+	assert.Equal(t, "hunter2", request.Password)
+}
+
+func TestAuditEntryHash(t *testing.T) {
+	entry := &api_proto.AuditLogEntry{
+		Timestamp: 1600000000000000,
+		Principal: "admin",
+		Method:    "/proto.API/CollectArtifact",
+		Status:    "OK",
+		PrevHash:  "abcd",
+	}
+	entry.Hash = auditEntryHash(entry)
+
+	// Entries survive the round trip through the result set.
+	read := auditEntryFromRow(auditEntryToRow(entry))
+	assert.Equal(t, entry.Hash, auditEntryHash(read))
+
+	// Any change breaks the hash.
+	read.Principal = "someone else"
+	assert.True(t, entry.Hash != auditEntryHash(read))
+}
+
+func removeSpaces(in string) string {
+	result := []rune{}
+	for _, c := range in {
+		if c != ' ' {
+			result = append(result, c)
+		}
+	}
+	return string(result)
+}
@@ -85,6 +85,10 @@ func (self *BasicAuthenticator) AuthenticateUserHandler(
 			return
 		}
 
+		// Only users with a local account are synced so failed
+		// logins do not create users.
+		syncLDAPUser(self.config_obj, username)
+
 		// Must have at least reader.
 		perm, err := acls.CheckAccess(self.config_obj, username, acls.READ_RESULTS)
 		if !perm || err != nil || user_record.Locked || user_record.Name != username {
@@ -336,6 +336,9 @@ func authenticateUserHandle(
 
 		picture, _ := claims["picture"].(string)
 
+		// Refresh the user's roles from their directory groups.
+		syncLDAPUser(config_obj, username)
+
 		// Now check if the user is allowed to log in.
 		users := services.GetUserManager()
 		user_record, err := users.GetUser(username)
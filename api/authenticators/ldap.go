@@ -0,0 +1,17 @@
+package authenticators
+
+import (
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/ldap"
+	"www.velocidex.com/golang/velociraptor/logging"
+)
+
+// If the directory is unavailable the user keeps the roles they
+// already have.
+func syncLDAPUser(config_obj *config_proto.Config, username string) {
+	err := ldap.SyncUser(config_obj, username)
+	if err != nil {
+		logger := logging.GetLogger(config_obj, &logging.FrontendComponent)
+		logger.Error("Unable to sync %v from LDAP: %v", username, err)
+	}
+}
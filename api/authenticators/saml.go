@@ -104,6 +104,7 @@ func (self *SamlAuthenticator) AuthenticateUserHandler(
 		}
 
 		username := sa.GetAttributes().Get(self.user_attribute)
+		syncLDAPUser(self.config_obj, username)
 
 		users := services.GetUserManager()
 		user_record, err := users.GetUser(username)
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"sort"
+	"strings"
+
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"www.velocidex.com/golang/velociraptor/acls"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/constants"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/services"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+)
+
+// Report the bandwidth used by a client, a hunt or the clients which
+// used the most bandwidth. This allows deployments on constrained
+// links to verify the network footprint of the collections.
+func (self *ApiServer) GetBandwidthUsage(
+	ctx context.Context,
+	in *api_proto.BandwidthUsageRequest) (*api_proto.BandwidthUsageResponse, error) {
+
+	defer Instrument("GetBandwidthUsage")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_record.Name
+	permissions := acls.READ_RESULTS
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to view bandwidth usage.")
+	}
+
+	count := in.Count
+	if count == 0 {
+		count = DEFAULT_PAGE_SIZE
+	}
+	if count > MAX_PAGE_SIZE {
+		count = MAX_PAGE_SIZE
+	}
+
+	switch {
+	case in.HuntId != "":
+		return getHuntBandwidthUsage(ctx, org_config_obj, in.HuntId, count)
+
+	case in.ClientId != "":
+		return getClientBandwidthUsage(org_config_obj, in.ClientId, count)
+	}
+
+	return getTopClientsBandwidthUsage(ctx, org_config_obj, count)
+}
+
+func flowBandwidthUsage(
+	flow *flows_proto.ArtifactCollectorContext) *api_proto.BandwidthUsage {
+	result := &api_proto.BandwidthUsage{
+		ClientId:      flow.ClientId,
+		FlowId:        flow.SessionId,
+		BytesSent:     flow.TotalSentBytes,
+		BytesReceived: flow.TotalWireBytes,
+		PayloadBytes:  flow.TotalPayloadBytes,
+	}
+
+	creator := flow.Request.GetCreator()
+	if strings.HasPrefix(creator, constants.HUNT_PREFIX) {
+		result.HuntId = creator
+	}
+	return result
+}
+
+// The client's totals and its most recent collections.
+func getClientBandwidthUsage(
+	config_obj *config_proto.Config,
+	client_id string, count uint64) (*api_proto.BandwidthUsageResponse, error) {
+
+	client_info_manager, err := services.GetClientInfoManager(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	client_info, err := client_info_manager.Get(client_id)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "Client not found.")
+	}
+
+	result := &api_proto.BandwidthUsageResponse{
+		Total: &api_proto.BandwidthUsage{
+			ClientId:      client_id,
+			BytesSent:     client_info.BytesSent,
+			BytesReceived: client_info.BytesReceived,
+		},
+	}
+
+	launcher, err := services.GetLauncher(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	flows, err := launcher.GetFlows(config_obj, client_id,
+		true /* include_archived */, nil, 0, count)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, flow := range flows.Items {
+		result.Items = append(result.Items, flowBandwidthUsage(flow))
+	}
+
+	return result, nil
+}
+
+// The hunt's totals only include completed collections while the
+// items also show collections which are still running.
+func getHuntBandwidthUsage(
+	ctx context.Context,
+	config_obj *config_proto.Config,
+	hunt_id string, count uint64) (*api_proto.BandwidthUsageResponse, error) {
+
+	hunt_dispatcher, err := services.GetHuntDispatcher(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	hunt, pres := hunt_dispatcher.GetHunt(hunt_id)
+	if !pres {
+		return nil, status.Error(codes.NotFound, "Hunt not found.")
+	}
+
+	result := &api_proto.BandwidthUsageResponse{
+		Total: &api_proto.BandwidthUsage{
+			HuntId:        hunt_id,
+			BytesSent:     hunt.Stats.GetTotalSentBytes(),
+			BytesReceived: hunt.Stats.GetTotalWireBytes(),
+			PayloadBytes:  hunt.Stats.GetTotalPayloadBytes(),
+		},
+	}
+
+	sub_ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	scope := vql_subsystem.MakeScope()
+	defer scope.Close()
+
+	for flow := range hunt_dispatcher.GetFlows(
+		sub_ctx, config_obj, scope, hunt_id, 0) {
+		if flow.Context == nil {
+			continue
+		}
+
+		result.Items = append(result.Items, flowBandwidthUsage(flow.Context))
+		if uint64(len(result.Items)) >= count {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// The totals over all clients and the clients which used the most
+// bandwidth.
+func getTopClientsBandwidthUsage(
+	ctx context.Context,
+	config_obj *config_proto.Config,
+	count uint64) (*api_proto.BandwidthUsageResponse, error) {
+
+	indexer, err := services.GetIndexer(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &api_proto.BandwidthUsageResponse{
+		Total: &api_proto.BandwidthUsage{},
+	}
+
+	seen := make(map[string]bool)
+	for hit := range indexer.SearchIndexWithPrefix(ctx, config_obj, "all") {
+		if hit == nil || seen[hit.Entity] {
+			continue
+		}
+		seen[hit.Entity] = true
+
+		api_client, err := indexer.FastGetApiClient(ctx, config_obj, hit.Entity)
+		if err != nil {
+			continue
+		}
+
+		result.Total.BytesSent += api_client.BytesSent
+		result.Total.BytesReceived += api_client.BytesReceived
+		result.Items = append(result.Items, &api_proto.BandwidthUsage{
+			ClientId:      api_client.ClientId,
+			BytesSent:     api_client.BytesSent,
+			BytesReceived: api_client.BytesReceived,
+		})
+	}
+
+	sort.Slice(result.Items, func(i, j int) bool {
+		return result.Items[i].BytesSent+result.Items[i].BytesReceived >
+			result.Items[j].BytesSent+result.Items[j].BytesReceived
+	})
+
+	if uint64(len(result.Items)) > count {
+		result.Items = result.Items[:count]
+	}
+
+	return result, nil
+}
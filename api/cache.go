@@ -0,0 +1,296 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	"github.com/Velocidex/ttlcache/v2"
+	"google.golang.org/protobuf/proto"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/services/journal"
+)
+
+// The GUI requests the same client record and flow details many
+// times for each page load. The ApiCache keeps these in memory for a
+// short time to avoid hitting the datastore for each request.
+//
+// Entries are invalidated when the API server modifies them (for
+// example launching or cancelling a flow), and when the journal
+// reports a change (e.g. labels, interrogation, client deletion,
+// flow completion or a flow being deleted or archived). Only
+// completed flows are cached since running flows are updated all the
+// time by the frontend.
+type ApiCache struct {
+	mu sync.Mutex
+
+	ctx context.Context
+	wg  *sync.WaitGroup
+
+	// Keys are org_id/client_id for client records and
+	// org_id/client_id/flow_id for flow details.
+	lru *ttlcache.Cache
+
+	// The cache keys for each org_id/client_id so a client's
+	// entries can be removed without scanning the whole cache.
+	client_keys map[string]map[string]bool
+
+	// Orgs we are already watching for invalidation events.
+	watched map[string]bool
+
+	disabled bool
+}
+
+func (self *ApiCache) GetClient(
+	config_obj *config_proto.Config, client_id string) (*api_proto.ApiClient, bool) {
+	if self.disabled {
+		return nil, false
+	}
+
+	item, err := self.lru.Get(clientCacheKey(config_obj.OrgId, client_id))
+	if err != nil {
+		return nil, false
+	}
+
+	client, ok := item.(*api_proto.ApiClient)
+	if !ok {
+		return nil, false
+	}
+
+	// Callers may modify the result so give them a copy.
+	return proto.Clone(client).(*api_proto.ApiClient), true
+}
+
+func (self *ApiCache) SetClient(
+	config_obj *config_proto.Config, client *api_proto.ApiClient) {
+	if self.disabled {
+		return
+	}
+
+	if !self.watchOrg(config_obj) {
+		return
+	}
+	key := clientCacheKey(config_obj.OrgId, client.ClientId)
+	self.set(key, key, proto.Clone(client))
+}
+
+func (self *ApiCache) GetFlowDetails(
+	config_obj *config_proto.Config,
+	client_id, flow_id string) (*api_proto.FlowDetails, bool) {
+	if self.disabled {
+		return nil, false
+	}
+
+	item, err := self.lru.Get(
+		flowCacheKey(config_obj.OrgId, client_id, flow_id))
+	if err != nil {
+		return nil, false
+	}
+
+	details, ok := item.(*api_proto.FlowDetails)
+	if !ok {
+		return nil, false
+	}
+
+	return proto.Clone(details).(*api_proto.FlowDetails), true
+}
+
+func (self *ApiCache) SetFlowDetails(
+	config_obj *config_proto.Config,
+	client_id, flow_id string, details *api_proto.FlowDetails) {
+	if self.disabled || details.Context == nil {
+		return
+	}
+
+	// Running flows change all the time so there is no point
+	// caching them.
+	switch details.Context.State {
+	case flows_proto.ArtifactCollectorContext_FINISHED,
+		flows_proto.ArtifactCollectorContext_ERROR:
+	default:
+		return
+	}
+
+	if !self.watchOrg(config_obj) {
+		return
+	}
+	self.set(clientCacheKey(config_obj.OrgId, client_id),
+		flowCacheKey(config_obj.OrgId, client_id, flow_id),
+		proto.Clone(details))
+}
+
+func (self *ApiCache) set(client_key, key string, value proto.Message) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	keys, pres := self.client_keys[client_key]
+	if !pres {
+		keys = make(map[string]bool)
+		self.client_keys[client_key] = keys
+	}
+	keys[key] = true
+
+	self.lru.Set(key, value)
+}
+
+// Called when an entry expires or is removed.
+func (self *ApiCache) forget(key string) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	// The entry was set again since.
+	_, err := self.lru.Get(key)
+	if err == nil {
+		return
+	}
+
+	// Keys start with org_id/client_id
+	parts := strings.SplitN(key, "/", 3)
+	if len(parts) < 2 {
+		return
+	}
+	client_key := parts[0] + "/" + parts[1]
+
+	keys, pres := self.client_keys[client_key]
+	if !pres {
+		return
+	}
+
+	delete(keys, key)
+	if len(keys) == 0 {
+		delete(self.client_keys, client_key)
+	}
+}
+
+// Remove the client record and all the client's flows from the
+// cache.
+func (self *ApiCache) InvalidateClient(
+	config_obj *config_proto.Config, client_id string) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	client_key := clientCacheKey(config_obj.OrgId, client_id)
+	for key := range self.client_keys[client_key] {
+		self.lru.Remove(key)
+	}
+	delete(self.client_keys, client_key)
+}
+
+func (self *ApiCache) InvalidateFlow(
+	config_obj *config_proto.Config, client_id, flow_id string) {
+	self.lru.Remove(flowCacheKey(config_obj.OrgId, client_id, flow_id))
+}
+
+// Start watching the org's journal for events that invalidate our
+// cached entries. Orgs may be created at any time so we start
+// watching them the first time we cache anything for them. Returns
+// false if we are unable to watch the org, in which case we can not
+// safely cache anything for it.
+func (self *ApiCache) watchOrg(config_obj *config_proto.Config) bool {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	if self.watched[config_obj.OrgId] {
+		return true
+	}
+
+	invalidate_client := func(ctx context.Context,
+		config_obj *config_proto.Config, row *ordereddict.Dict) error {
+		client_id, pres := row.GetString("ClientId")
+		if !pres {
+			client_id, pres = row.GetString("client_id")
+		}
+		if pres {
+			self.InvalidateClient(config_obj, client_id)
+		}
+		return nil
+	}
+
+	invalidate_flow := func(ctx context.Context,
+		config_obj *config_proto.Config, row *ordereddict.Dict) error {
+		client_id, _ := row.GetString("ClientId")
+		flow_id, _ := row.GetString("FlowId")
+		self.InvalidateFlow(config_obj, client_id, flow_id)
+		return nil
+	}
+
+	for _, queue := range []string{
+		"Server.Internal.ClientDelete",
+		"Server.Internal.Interrogation",
+		"Server.Internal.Label"} {
+		err := journal.WatchQueueWithCB(self.ctx, config_obj, self.wg,
+			queue, "ApiCache", invalidate_client)
+		if err != nil {
+			return false
+		}
+	}
+
+	for _, queue := range []string{
+		"System.Flow.Completion",
+		"Server.Internal.FlowModification"} {
+		err := journal.WatchQueueWithCB(self.ctx, config_obj, self.wg,
+			queue, "ApiCache", invalidate_flow)
+		if err != nil {
+			return false
+		}
+	}
+
+	self.watched[config_obj.OrgId] = true
+	return true
+}
+
+func clientCacheKey(org_id, client_id string) string {
+	return org_id + "/" + client_id
+}
+
+func flowCacheKey(org_id, client_id, flow_id string) string {
+	return org_id + "/" + client_id + "/" + flow_id
+}
+
+func NewApiCache(
+	ctx context.Context,
+	wg *sync.WaitGroup,
+	config_obj *config_proto.Config) *ApiCache {
+	ttl := int64(10)
+	expected_clients := int64(1000)
+	if config_obj.Frontend != nil &&
+		config_obj.Frontend.Resources != nil {
+		if config_obj.Frontend.Resources.ApiCacheTtl != 0 {
+			ttl = config_obj.Frontend.Resources.ApiCacheTtl
+		}
+
+		if config_obj.Frontend.Resources.ExpectedClients > 0 {
+			expected_clients = config_obj.Frontend.Resources.ExpectedClients
+		}
+	}
+
+	result := &ApiCache{
+		ctx:         ctx,
+		wg:          wg,
+		lru:         ttlcache.NewCache(),
+		client_keys: make(map[string]map[string]bool),
+		watched:     make(map[string]bool),
+		disabled:    ttl < 0,
+	}
+
+	result.lru.SetExpirationCallback(func(key string, value interface{}) {
+		result.forget(key)
+	})
+
+	result.lru.SetCacheSizeLimit(int(expected_clients))
+	result.lru.SkipTTLExtensionOnHit(true)
+	if ttl > 0 {
+		result.lru.SetTTL(time.Duration(ttl) * time.Second)
+	}
+
+	go func() {
+		<-ctx.Done()
+		result.lru.Close()
+	}()
+
+	return result
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/protobuf/proto"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/file_store/test_utils"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/services/launcher"
+	"www.velocidex.com/golang/velociraptor/vtesting"
+	"www.velocidex.com/golang/velociraptor/vtesting/assert"
+)
+
+type CacheTestSuite struct {
+	test_utils.TestSuite
+}
+
+func (self *CacheTestSuite) SetupTest() {
+	self.ConfigObj = self.LoadConfig()
+	self.LoadArtifacts([]string{`
+name: Server.Internal.FlowModification
+type: INTERNAL
+`, `
+name: Server.Internal.ClientDelete
+type: SERVER_EVENT
+`})
+	self.TestSuite.SetupTest()
+}
+
+func (self *CacheTestSuite) newCache(
+	config_obj *config_proto.Config) *ApiCache {
+	return NewApiCache(self.Ctx, &sync.WaitGroup{}, config_obj)
+}
+
+func (self *CacheTestSuite) flowDetails(
+	flow_id string,
+	state flows_proto.ArtifactCollectorContext_State) *api_proto.FlowDetails {
+	return &api_proto.FlowDetails{
+		Context: &flows_proto.ArtifactCollectorContext{
+			ClientId:  "C.1",
+			SessionId: flow_id,
+			State:     state,
+		},
+	}
+}
+
+func (self *CacheTestSuite) TestCacheHit() {
+	cache := self.newCache(self.ConfigObj)
+
+	cache.SetClient(self.ConfigObj, &api_proto.ApiClient{
+		ClientId: "C.1",
+		Labels:   []string{"Foo"},
+	})
+
+	client, pres := cache.GetClient(self.ConfigObj, "C.1")
+	assert.True(self.T(), pres)
+	assert.Equal(self.T(), []string{"Foo"}, client.Labels)
+
+	// Callers get a copy.
+	client.Labels = nil
+	client, _ = cache.GetClient(self.ConfigObj, "C.1")
+	assert.Equal(self.T(), []string{"Foo"}, client.Labels)
+
+	// Only completed flows are cached.
+	cache.SetFlowDetails(self.ConfigObj, "C.1", "F.1", self.flowDetails(
+		"F.1", flows_proto.ArtifactCollectorContext_FINISHED))
+	cache.SetFlowDetails(self.ConfigObj, "C.1", "F.2", self.flowDetails(
+		"F.2", flows_proto.ArtifactCollectorContext_RUNNING))
+
+	details, pres := cache.GetFlowDetails(self.ConfigObj, "C.1", "F.1")
+	assert.True(self.T(), pres)
+	assert.True(self.T(), proto.Equal(self.flowDetails(
+		"F.1", flows_proto.ArtifactCollectorContext_FINISHED), details))
+
+	_, pres = cache.GetFlowDetails(self.ConfigObj, "C.1", "F.2")
+	assert.True(self.T(), !pres)
+
+	// Invalidating the client removes its flows too.
+	cache.InvalidateClient(self.ConfigObj, "C.1")
+
+	_, pres = cache.GetClient(self.ConfigObj, "C.1")
+	assert.True(self.T(), !pres)
+
+	_, pres = cache.GetFlowDetails(self.ConfigObj, "C.1", "F.1")
+	assert.True(self.T(), !pres)
+
+	cache.mu.Lock()
+	assert.Equal(self.T(), 0, len(cache.client_keys))
+	cache.mu.Unlock()
+}
+
+func (self *CacheTestSuite) TestJournalInvalidation() {
+	cache := self.newCache(self.ConfigObj)
+
+	cache.SetClient(self.ConfigObj, &api_proto.ApiClient{ClientId: "C.1"})
+	for _, flow_id := range []string{"F.1", "F.2"} {
+		cache.SetFlowDetails(self.ConfigObj, "C.1", flow_id, self.flowDetails(
+			flow_id, flows_proto.ArtifactCollectorContext_FINISHED))
+	}
+
+	// Deleting or archiving a flow outside the API drops it.
+	launcher.NotifyFlowModification(self.ConfigObj, "C.1", "F.1", "Delete")
+
+	vtesting.WaitUntil(5*time.Second, self.T(), func() bool {
+		_, pres := cache.GetFlowDetails(self.ConfigObj, "C.1", "F.1")
+		return !pres
+	})
+
+	_, pres := cache.GetFlowDetails(self.ConfigObj, "C.1", "F.2")
+	assert.True(self.T(), pres)
+
+	// Deleting the client drops all its entries.
+	journal, err := services.GetJournal(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	err = journal.PushRowsToArtifact(self.ConfigObj,
+		[]*ordereddict.Dict{ordereddict.NewDict().
+			Set("ClientId", "C.1").
+			Set("Principal", "admin")},
+		"Server.Internal.ClientDelete", "server", "")
+	assert.NoError(self.T(), err)
+
+	vtesting.WaitUntil(5*time.Second, self.T(), func() bool {
+		_, pres := cache.GetClient(self.ConfigObj, "C.1")
+		return !pres
+	})
+
+	_, pres = cache.GetFlowDetails(self.ConfigObj, "C.1", "F.2")
+	assert.True(self.T(), !pres)
+}
+
+func (self *CacheTestSuite) TestExpiry() {
+	config_obj := proto.Clone(self.ConfigObj).(*config_proto.Config)
+	config_obj.Frontend.Resources = &config_proto.FrontendResourceControl{
+		ApiCacheTtl: 1,
+	}
+
+	cache := self.newCache(config_obj)
+	cache.SetClient(config_obj, &api_proto.ApiClient{ClientId: "C.1"})
+
+	_, pres := cache.GetClient(config_obj, "C.1")
+	assert.True(self.T(), pres)
+
+	vtesting.WaitUntil(5*time.Second, self.T(), func() bool {
+		_, pres := cache.GetClient(config_obj, "C.1")
+		return !pres
+	})
+
+	// Expired entries are removed from the client index.
+	vtesting.WaitUntil(5*time.Second, self.T(), func() bool {
+		cache.mu.Lock()
+		defer cache.mu.Unlock()
+		return len(cache.client_keys) == 0
+	})
+
+	// A negative TTL disables the cache.
+	config_obj.Frontend.Resources.ApiCacheTtl = -1
+	cache = self.newCache(config_obj)
+	cache.SetClient(config_obj, &api_proto.ApiClient{ClientId: "C.1"})
+
+	_, pres = cache.GetClient(config_obj, "C.1")
+	assert.True(self.T(), !pres)
+}
+
+func TestApiCache(t *testing.T) {
+	suite.Run(t, &CacheTestSuite{})
+}
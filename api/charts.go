@@ -0,0 +1,425 @@
+package api
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"www.velocidex.com/golang/velociraptor/acls"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	file_store "www.velocidex.com/golang/velociraptor/file_store"
+	"www.velocidex.com/golang/velociraptor/paths/artifacts"
+	"www.velocidex.com/golang/velociraptor/result_sets"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/utils"
+)
+
+const (
+	defaultChartBuckets = 60
+	defaultChartLimit   = 10
+
+	// Protect the server from requests which would produce huge
+	// responses.
+	maxChartBuckets = 10000
+)
+
+func (self *ApiServer) GetChartData(
+	ctx context.Context,
+	in *api_proto.GetChartDataRequest) (*api_proto.GetChartDataResponse, error) {
+
+	defer Instrument("GetChartData")()
+	users := services.GetUserManager()
+	user_info, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_info.Name
+	permissions := acls.READ_RESULTS
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to view results.")
+	}
+
+	if in.Table == nil || in.Column == "" {
+		return nil, status.Error(codes.InvalidArgument,
+			"A table and a column must be specified")
+	}
+
+	switch in.Type {
+	case "histogram":
+		return getHistogram(ctx, org_config_obj, in)
+
+	case "top":
+		return getTopValues(ctx, org_config_obj, in)
+
+	default:
+		return nil, status.Error(codes.InvalidArgument,
+			"Chart type must be histogram or top")
+	}
+}
+
+// Produces a time histogram of the result set. When no interval is
+// given we need to make two passes over the data - the first to find
+// the time range and the second to fill the buckets.
+func getHistogram(
+	ctx context.Context,
+	config_obj *config_proto.Config,
+	in *api_proto.GetChartDataRequest) (*api_proto.GetChartDataResponse, error) {
+
+	interval := in.Interval
+	if interval < 0 {
+		return nil, status.Error(codes.InvalidArgument,
+			"Interval must be positive")
+	}
+
+	if interval == 0 {
+		buckets := int64(in.Buckets)
+		if buckets == 0 {
+			buckets = defaultChartBuckets
+		}
+
+		min_time, max_time, err := getTimeRange(ctx, config_obj, in)
+		if err != nil {
+			return nil, err
+		}
+
+		interval = (max_time - min_time + buckets) / buckets
+		if interval < 1 {
+			interval = 1
+		}
+	}
+
+	aggregator := newHistogramAggregator(interval, in.ValueColumn)
+	err := forEachChartRow(ctx, config_obj, in.Table,
+		func(row *ordereddict.Dict) {
+			aggregator.Add(row, in.Column)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return aggregator.Result()
+}
+
+func getTimeRange(
+	ctx context.Context,
+	config_obj *config_proto.Config,
+	in *api_proto.GetChartDataRequest) (min_time, max_time int64, err error) {
+
+	min_time = math.MaxInt64
+	err = forEachChartRow(ctx, config_obj, in.Table,
+		func(row *ordereddict.Dict) {
+			value, _ := row.Get(in.Column)
+			ts, ok := chartTime(value)
+			if !ok {
+				return
+			}
+
+			sec := ts.Unix()
+			if sec < min_time {
+				min_time = sec
+			}
+			if sec > max_time {
+				max_time = sec
+			}
+		})
+
+	// No valid timestamps at all.
+	if min_time > max_time {
+		return 0, 0, err
+	}
+	return min_time, max_time, err
+}
+
+func getTopValues(
+	ctx context.Context,
+	config_obj *config_proto.Config,
+	in *api_proto.GetChartDataRequest) (*api_proto.GetChartDataResponse, error) {
+
+	limit := int(in.Limit)
+	if limit == 0 {
+		limit = defaultChartLimit
+	}
+
+	aggregator := newTopAggregator(in.ValueColumn)
+	err := forEachChartRow(ctx, config_obj, in.Table,
+		func(row *ordereddict.Dict) {
+			aggregator.Add(row, in.Column)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return aggregator.Result(limit), nil
+}
+
+// Call cb on every row in the result set selected by the table
+// request. Unlike GetTable we do not limit the number of rows since
+// only the aggregate is sent back to the caller.
+func forEachChartRow(
+	ctx context.Context,
+	config_obj *config_proto.Config,
+	in *api_proto.GetTableRequest,
+	cb func(row *ordereddict.Dict)) error {
+
+	file_store_factory := file_store.GetFileStore(config_obj)
+
+	if in.Type == "CLIENT_EVENT" || in.Type == "SERVER_EVENT" {
+		path_manager, err := artifacts.NewArtifactPathManager(
+			config_obj, in.ClientId, in.FlowId, in.Artifact)
+		if err != nil {
+			return err
+		}
+
+		rs_reader, err := result_sets.NewTimedResultSetReader(ctx,
+			file_store_factory, path_manager)
+		if err != nil {
+			return err
+		}
+		defer rs_reader.Close()
+
+		err = rs_reader.SeekToTime(time.Unix(int64(in.StartTime), 0))
+		if err != nil {
+			return err
+		}
+
+		if in.EndTime != 0 {
+			rs_reader.SetMaxTime(time.Unix(int64(in.EndTime), 0))
+		}
+
+		for row := range rs_reader.Rows(ctx) {
+			cb(row)
+		}
+		return nil
+	}
+
+	path_spec, err := getPathSpec(config_obj, in)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	options := result_sets.ResultSetOptions{}
+	if in.FilterColumn != "" &&
+		in.FilterRegex != "" {
+		options.FilterColumn = in.FilterColumn
+		options.FilterRegex, err = regexp.Compile("(?i)" + in.FilterRegex)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	rs_reader, err := result_sets.NewResultSetReaderWithOptions(
+		ctx, config_obj, file_store_factory, path_spec, options)
+	if err != nil {
+		// Same as GetTable: a missing result set is just empty.
+		return nil
+	}
+	defer rs_reader.Close()
+
+	for row := range rs_reader.Rows(ctx) {
+		cb(row)
+	}
+	return nil
+}
+
+type histogramAggregator struct {
+	interval     int64
+	value_column string
+
+	buckets      map[int64]float64
+	total_rows   int64
+	skipped_rows int64
+}
+
+func newHistogramAggregator(
+	interval int64, value_column string) *histogramAggregator {
+	return &histogramAggregator{
+		interval:     interval,
+		value_column: value_column,
+		buckets:      make(map[int64]float64),
+	}
+}
+
+func (self *histogramAggregator) Add(row *ordereddict.Dict, column string) {
+	self.total_rows++
+
+	value, _ := row.Get(column)
+	ts, ok := chartTime(value)
+	if !ok {
+		self.skipped_rows++
+		return
+	}
+
+	weight, ok := chartWeight(row, self.value_column)
+	if !ok {
+		self.skipped_rows++
+		return
+	}
+
+	sec := ts.Unix()
+	bucket := sec - sec%self.interval
+	if sec < 0 && sec%self.interval != 0 {
+		bucket -= self.interval
+	}
+	self.buckets[bucket] += weight
+}
+
+// Emit a continuous series with empty buckets filled with 0 so the
+// GUI can draw it directly.
+func (self *histogramAggregator) Result() (*api_proto.GetChartDataResponse, error) {
+	result := &api_proto.GetChartDataResponse{
+		TotalRows:   self.total_rows,
+		SkippedRows: self.skipped_rows,
+		Interval:    self.interval,
+	}
+
+	if len(self.buckets) == 0 {
+		return result, nil
+	}
+
+	first := int64(math.MaxInt64)
+	last := int64(math.MinInt64)
+	for k := range self.buckets {
+		if k < first {
+			first = k
+		}
+		if k > last {
+			last = k
+		}
+	}
+
+	if (last-first)/self.interval >= maxChartBuckets {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"Interval %v is too small for the time range (more than %v buckets)",
+			self.interval, maxChartBuckets)
+	}
+
+	for ts := first; ts <= last; ts += self.interval {
+		result.Points = append(result.Points, &api_proto.ChartPoint{
+			Label:     time.Unix(ts, 0).UTC().Format(time.RFC3339),
+			Timestamp: ts,
+			Value:     self.buckets[ts],
+		})
+	}
+
+	return result, nil
+}
+
+type topAggregator struct {
+	value_column string
+
+	counts       map[string]float64
+	total_rows   int64
+	skipped_rows int64
+}
+
+func newTopAggregator(value_column string) *topAggregator {
+	return &topAggregator{
+		value_column: value_column,
+		counts:       make(map[string]float64),
+	}
+}
+
+func (self *topAggregator) Add(row *ordereddict.Dict, column string) {
+	self.total_rows++
+
+	value, pres := row.Get(column)
+	if !pres {
+		self.skipped_rows++
+		return
+	}
+
+	weight, ok := chartWeight(row, self.value_column)
+	if !ok {
+		self.skipped_rows++
+		return
+	}
+
+	self.counts[utils.ToString(value)] += weight
+}
+
+func (self *topAggregator) Result(limit int) *api_proto.GetChartDataResponse {
+	result := &api_proto.GetChartDataResponse{
+		TotalRows:   self.total_rows,
+		SkippedRows: self.skipped_rows,
+	}
+
+	for k, v := range self.counts {
+		result.Points = append(result.Points, &api_proto.ChartPoint{
+			Label: k,
+			Value: v,
+		})
+	}
+
+	// Largest first, ties are broken by label to keep the output
+	// stable.
+	sort.Slice(result.Points, func(i, j int) bool {
+		a, b := result.Points[i], result.Points[j]
+		if a.Value != b.Value {
+			return a.Value > b.Value
+		}
+		return a.Label < b.Label
+	})
+
+	if len(result.Points) > limit {
+		for _, p := range result.Points[limit:] {
+			result.Other += p.Value
+		}
+		result.Points = result.Points[:limit]
+	}
+
+	return result
+}
+
+// Each row counts as 1 unless a value column is given.
+func chartWeight(row *ordereddict.Dict, value_column string) (float64, bool) {
+	if value_column == "" {
+		return 1, true
+	}
+
+	value, pres := row.Get(value_column)
+	if !pres {
+		return 0, false
+	}
+
+	switch t := value.(type) {
+	case float64:
+		return t, true
+	case string:
+		f, err := strconv.ParseFloat(t, 64)
+		return f, err == nil
+	}
+
+	i, ok := utils.ToInt64(value)
+	return float64(i), ok
+}
+
+// Result sets store times as RFC3339 strings but some artifacts emit
+// epoch times instead.
+func chartTime(value interface{}) (time.Time, bool) {
+	switch t := value.(type) {
+	case time.Time:
+		return t, !t.IsZero()
+
+	case string:
+		ts, err := time.Parse(time.RFC3339Nano, t)
+		if err == nil {
+			return ts, true
+		}
+	}
+
+	i, ok := utils.ToInt64(value)
+	if !ok || i <= 0 {
+		return time.Time{}, false
+	}
+	return utils.ParseTimeFromInt64(i), true
+}
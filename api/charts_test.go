@@ -0,0 +1,58 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/Velocidex/ordereddict"
+	"www.velocidex.com/golang/velociraptor/vtesting/assert"
+)
+
+func TestHistogramAggregator(t *testing.T) {
+	aggregator := newHistogramAggregator(60, "")
+	for _, ts := range []interface{}{
+		"2021-01-01T00:00:10Z",
+		"2021-01-01T00:00:50Z",
+		"2021-01-01T00:02:00Z",
+		int64(1609459330), // 2021-01-01T00:02:10Z
+		"not a time",
+	} {
+		aggregator.Add(ordereddict.NewDict().Set("Time", ts), "Time")
+	}
+
+	result, err := aggregator.Result()
+	assert.NoError(t, err)
+
+	assert.Equal(t, int64(5), result.TotalRows)
+	assert.Equal(t, int64(1), result.SkippedRows)
+
+	// The empty bucket in the middle is filled in.
+	assert.Equal(t, 3, len(result.Points))
+	assert.Equal(t, "2021-01-01T00:00:00Z", result.Points[0].Label)
+	assert.Equal(t, float64(2), result.Points[0].Value)
+	assert.Equal(t, float64(0), result.Points[1].Value)
+	assert.Equal(t, float64(2), result.Points[2].Value)
+}
+
+func TestTopAggregator(t *testing.T) {
+	aggregator := newTopAggregator("Size")
+	for _, item := range []struct {
+		name string
+		size interface{}
+	}{
+		{"a", int64(10)}, {"b", "5"}, {"a", float64(1)},
+		{"c", int64(3)}, {"d", int64(1)}, {"e", "x"},
+	} {
+		aggregator.Add(ordereddict.NewDict().
+			Set("Name", item.name).
+			Set("Size", item.size), "Name")
+	}
+
+	result := aggregator.Result(2)
+	assert.Equal(t, int64(6), result.TotalRows)
+	assert.Equal(t, int64(1), result.SkippedRows)
+	assert.Equal(t, 2, len(result.Points))
+	assert.Equal(t, "a", result.Points[0].Label)
+	assert.Equal(t, float64(11), result.Points[0].Value)
+	assert.Equal(t, "b", result.Points[1].Label)
+	assert.Equal(t, float64(4), result.Other)
+}
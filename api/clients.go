@@ -29,10 +29,9 @@ import (
 	"google.golang.org/protobuf/types/known/emptypb"
 	"www.velocidex.com/golang/velociraptor/acls"
 	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
-	"www.velocidex.com/golang/velociraptor/datastore"
 	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
-	"www.velocidex.com/golang/velociraptor/paths"
 	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/services/client_info"
 )
 
 func (self *ApiServer) GetClientMetadata(
@@ -57,19 +56,7 @@ func (self *ApiServer) GetClientMetadata(
 			"User is not allowed to view clients.")
 	}
 
-	client_path_manager := paths.NewClientPathManager(in.ClientId)
-	db, err := datastore.GetDB(org_config_obj)
-	if err != nil {
-		return nil, err
-	}
-
-	result := &api_proto.ClientMetadata{}
-	err = db.GetSubject(org_config_obj, client_path_manager.Metadata(), result)
-	if errors.Is(err, os.ErrNotExist) {
-		// Metadata not set, start with empty set.
-		err = nil
-	}
-	return result, err
+	return client_info.GetMetadata(org_config_obj, in.ClientId)
 }
 
 func (self *ApiServer) SetClientMetadata(
@@ -90,13 +77,12 @@ func (self *ApiServer) SetClientMetadata(
 			"User is not allowed to modify client labels.")
 	}
 
-	client_path_manager := paths.NewClientPathManager(in.ClientId)
-	db, err := datastore.GetDB(org_config_obj)
-	if err != nil {
-		return nil, err
+	if in.ClientId == "" {
+		return nil, status.Error(codes.InvalidArgument,
+			"A client id is required.")
 	}
 
-	err = db.SetSubject(org_config_obj, client_path_manager.Metadata(), in)
+	err = client_info.SetMetadata(org_config_obj, in)
 	return &emptypb.Empty{}, err
 }
 
@@ -131,9 +117,16 @@ func (self *ApiServer) GetClient(
 		}
 	}
 
-	api_client, err := indexer.FastGetApiClient(ctx, org_config_obj, in.ClientId)
-	if err != nil {
-		return &api_proto.ApiClient{}, nil
+	api_client, pres := self.cache.GetClient(org_config_obj, in.ClientId)
+	if !pres {
+		api_client, err = indexer.FastGetApiClient(ctx, org_config_obj, in.ClientId)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, clientNotFoundError(in.ClientId)
+		}
+		if err != nil {
+			return &api_proto.ApiClient{}, nil
+		}
+		self.cache.SetClient(org_config_obj, api_client)
 	}
 
 	if self.server_obj != nil {
@@ -171,35 +164,89 @@ func (self *ApiServer) GetClientFlows(
 			"User is not allowed to view flows.")
 	}
 
-	filter := func(flow *flows_proto.ArtifactCollectorContext) bool {
-		return true
+	filter, err := clientFlowsFilter(in)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	launcher, err := services.GetLauncher(org_config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := newPager(in.PageToken, in.Offset, in.Count,
+		DEFAULT_PAGE_SIZE, MAX_PAGE_SIZE,
+		"GetClientFlows", in.ClientId, in.IncludeArchived, in.Artifact,
+		in.State, in.Creator, in.StartTime, in.EndTime, in.Ascending)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := launcher.SearchFlows(org_config_obj, in.ClientId,
+		services.FlowSearchOptions{
+			IncludeArchived: in.IncludeArchived,
+			StartTime:       in.StartTime,
+			EndTime:         in.EndTime,
+			Ascending:       in.Ascending,
+			Filter:          filter,
+		}, page.Offset, page.FetchSize())
+	if err != nil {
+		return nil, err
 	}
 
+	length, token := page.Page(len(result.Items))
+	result.Items = result.Items[:length]
+	result.NextPageToken = token
+
+	return result, nil
+}
+
+// The filters which need the flow record. The time range is applied
+// by the launcher before loading the flows.
+func clientFlowsFilter(in *api_proto.ApiFlowRequest) (
+	func(flow *flows_proto.ArtifactCollectorContext) bool, error) {
+
+	var regex *regexp.Regexp
 	if in.Artifact != "" {
-		regex, err := regexp.Compile(in.Artifact)
+		var err error
+		regex, err = regexp.Compile(in.Artifact)
 		if err != nil {
 			return nil, err
 		}
+	}
 
-		filter = func(flow *flows_proto.ArtifactCollectorContext) bool {
-			if flow.Request == nil {
-				return false
-			}
+	if in.StartTime > 0 && in.EndTime > 0 && in.StartTime > in.EndTime {
+		return nil, errors.New("start_time must be before end_time")
+	}
 
-			for _, name := range flow.Request.Artifacts {
-				if regex.MatchString(name) {
-					return true
-				}
-			}
+	return func(flow *flows_proto.ArtifactCollectorContext) bool {
+		if len(in.State) > 0 && !hasFlowState(in.State, flow.State) {
 			return false
 		}
-	}
 
-	launcher, err := services.GetLauncher(org_config_obj)
-	if err != nil {
-		return nil, err
-	}
+		if in.Creator != "" && flow.Request.GetCreator() != in.Creator {
+			return false
+		}
+
+		if regex == nil {
+			return true
+		}
 
-	return launcher.GetFlows(org_config_obj, in.ClientId,
-		in.IncludeArchived, filter, in.Offset, in.Count)
+		for _, name := range flow.Request.GetArtifacts() {
+			if regex.MatchString(name) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func hasFlowState(states []flows_proto.ArtifactCollectorContext_State,
+	state flows_proto.ArtifactCollectorContext_State) bool {
+	for _, s := range states {
+		if s == state {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"fmt"
+
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"www.velocidex.com/golang/velociraptor/acls"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/services"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+)
+
+const (
+	DEFAULT_MULTI_COLLECTION_LIMIT = 1000
+)
+
+// Launch the same collection on a set of clients. Each client is
+// scheduled through CollectArtifact so it gets the same allow list,
+// approval and audit treatment as a single collection. Approvals are
+// per client and are taken from in.ApprovalIds. Failures are
+// reported per client and do not stop the other launches.
+func (self *ApiServer) CollectArtifactMulti(
+	ctx context.Context,
+	in *api_proto.CollectArtifactMultiRequest) (
+	*api_proto.CollectArtifactMultiResponse, error) {
+
+	defer Instrument("CollectArtifactMulti")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fail once for the whole request rather than for every client.
+	permissions := acls.COLLECT_CLIENT
+	perm, err := acls.CheckAccess(org_config_obj, user_record.Name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to launch flows.")
+	}
+
+	if in.Request == nil {
+		return nil, status.Error(codes.InvalidArgument,
+			"No collection request provided.")
+	}
+
+	if len(in.ClientIds) == 0 && in.Query == "" {
+		return nil, status.Error(codes.InvalidArgument,
+			"No client ids or client query provided.")
+	}
+
+	limit := in.Limit
+	if limit == 0 {
+		limit = DEFAULT_MULTI_COLLECTION_LIMIT
+	}
+
+	client_ids, err := getMultiCollectionClients(
+		ctx, org_config_obj, user_record.Name, in, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &api_proto.CollectArtifactMultiResponse{}
+	for _, client_id := range client_ids {
+		request := proto.Clone(in.Request).(*flows_proto.ArtifactCollectorArgs)
+		request.ClientId = client_id
+
+		approval_id, pres := in.ApprovalIds[client_id]
+		if pres {
+			request.ApprovalId = approval_id
+		}
+
+		item := &api_proto.CollectArtifactMultiResult{ClientId: client_id}
+		response, err := self.CollectArtifact(ctx, request)
+		if err != nil {
+			item.Error = status.Convert(err).Message()
+			item.Code = status.Code(err).String()
+		} else {
+			item.FlowId = response.FlowId
+		}
+		result.Items = append(result.Items, item)
+	}
+
+	return result, nil
+}
+
+// Combine the explicit client ids with the clients matching the
+// query, without duplicates.
+func getMultiCollectionClients(
+	ctx context.Context, config_obj *config_proto.Config,
+	principal string, in *api_proto.CollectArtifactMultiRequest,
+	limit uint64) ([]string, error) {
+	result := []string{}
+	seen := make(map[string]bool)
+
+	add := func(client_id string) error {
+		if seen[client_id] {
+			return nil
+		}
+		if uint64(len(result)) >= limit {
+			return status.Error(codes.InvalidArgument,
+				fmt.Sprintf("Request matches more than %v clients.", limit))
+		}
+		seen[client_id] = true
+		result = append(result, client_id)
+		return nil
+	}
+
+	for _, client_id := range in.ClientIds {
+		err := add(client_id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if in.Query == "" {
+		return result, nil
+	}
+
+	indexer, err := services.GetIndexer(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	sub_ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	scope := vql_subsystem.MakeScope()
+	defer scope.Close()
+
+	search_chan, err := indexer.SearchClientsChan(
+		sub_ctx, scope, config_obj, in.Query, principal)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	for api_client := range search_chan {
+		err := add(api_client.ClientId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"www.velocidex.com/golang/velociraptor/acls"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+func (self *ApiServer) GetComplianceReport(
+	ctx context.Context,
+	in *emptypb.Empty) (*api_proto.ComplianceReport, error) {
+
+	defer Instrument("GetComplianceReport")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_record.Name
+	permissions := acls.READ_RESULTS
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to view compliance reports.")
+	}
+
+	compliance_manager, err := services.GetComplianceManager(org_config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return compliance_manager.GetReport(ctx, org_config_obj)
+}
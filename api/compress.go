@@ -0,0 +1,193 @@
+package api
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+)
+
+const (
+	// Small responses are not worth compressing.
+	MIN_COMPRESS_SIZE = 1400
+)
+
+// Pick the encoding from the Accept-Encoding header. We prefer gzip
+// when both are acceptable.
+func negotiateEncoding(accept_encoding string) string {
+	result := ""
+	for _, item := range strings.Split(accept_encoding, ",") {
+		parts := strings.SplitN(item, ";", 2)
+		encoding := strings.ToLower(strings.TrimSpace(parts[0]))
+
+		// Encodings with q=0 are not acceptable.
+		if len(parts) > 1 {
+			q := strings.TrimSpace(parts[1])
+			if strings.HasPrefix(q, "q=") {
+				value, err := strconv.ParseFloat(q[2:], 64)
+				if err == nil && value == 0 {
+					continue
+				}
+			}
+		}
+
+		switch encoding {
+		case "gzip":
+			return "gzip"
+		case "deflate":
+			result = "deflate"
+		}
+	}
+	return result
+}
+
+type compressWriteCloser interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// Buffers the start of the response so small responses are sent
+// uncompressed. Once the response grows past MIN_COMPRESS_SIZE it is
+// compressed on the fly.
+type compressResponseWriter struct {
+	http.ResponseWriter
+
+	encoding string
+	status   int
+	buffer   []byte
+
+	// Set once we decided whether to compress.
+	started bool
+	writer  compressWriteCloser
+}
+
+func (self *compressResponseWriter) WriteHeader(status int) {
+	self.status = status
+}
+
+func (self *compressResponseWriter) Write(data []byte) (int, error) {
+	if !self.started {
+		self.buffer = append(self.buffer, data...)
+		if len(self.buffer) < MIN_COMPRESS_SIZE {
+			return len(data), nil
+		}
+
+		err := self.start(true)
+		if err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+
+	if self.writer != nil {
+		return self.writer.Write(data)
+	}
+	return self.ResponseWriter.Write(data)
+}
+
+// Send the headers and the buffered data.
+func (self *compressResponseWriter) start(compress bool) error {
+	self.started = true
+
+	headers := self.Header()
+
+	// Do not compress twice.
+	if headers.Get("Content-Encoding") != "" {
+		compress = false
+	}
+
+	if compress {
+		headers.Set("Content-Encoding", self.encoding)
+		headers.Del("Content-Length")
+
+		switch self.encoding {
+		case "gzip":
+			self.writer = gzip.NewWriter(self.ResponseWriter)
+		case "deflate":
+			writer, err := flate.NewWriter(self.ResponseWriter, flate.DefaultCompression)
+			if err != nil {
+				return err
+			}
+			self.writer = writer
+		}
+	}
+	headers.Add("Vary", "Accept-Encoding")
+
+	if self.status != 0 {
+		self.ResponseWriter.WriteHeader(self.status)
+	}
+
+	buffer := self.buffer
+	self.buffer = nil
+
+	if len(buffer) == 0 {
+		return nil
+	}
+
+	var err error
+	if self.writer != nil {
+		_, err = self.writer.Write(buffer)
+	} else {
+		_, err = self.ResponseWriter.Write(buffer)
+	}
+	return err
+}
+
+// Streaming responses are flushed as they are produced.
+func (self *compressResponseWriter) Flush() {
+	if !self.started {
+		_ = self.start(len(self.buffer) >= MIN_COMPRESS_SIZE)
+	}
+
+	if self.writer != nil {
+		_ = self.writer.Flush()
+	}
+
+	flusher, ok := self.ResponseWriter.(http.Flusher)
+	if ok {
+		flusher.Flush()
+	}
+}
+
+func (self *compressResponseWriter) Close() error {
+	if !self.started {
+		err := self.start(false)
+		if err != nil {
+			return err
+		}
+	}
+
+	if self.writer != nil {
+		return self.writer.Close()
+	}
+	return nil
+}
+
+// Compress gateway responses for clients which accept it. Flow and
+// hunt results may be very large JSON documents which compress well.
+func compressHandler(
+	config_obj *config_proto.Config, parent http.Handler) http.Handler {
+	if config_obj.GUI != nil && config_obj.GUI.DisableCompression {
+		return parent
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == "HEAD" {
+			parent.ServeHTTP(w, r)
+			return
+		}
+
+		writer := &compressResponseWriter{
+			ResponseWriter: w,
+			encoding:       encoding,
+		}
+		defer writer.Close()
+
+		parent.ServeHTTP(writer, r)
+	})
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/vtesting/assert"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	assert.Equal(t, "", negotiateEncoding(""))
+	assert.Equal(t, "gzip", negotiateEncoding("gzip, deflate, br"))
+	assert.Equal(t, "gzip", negotiateEncoding("deflate, gzip"))
+	assert.Equal(t, "deflate", negotiateEncoding("deflate, gzip;q=0"))
+	assert.Equal(t, "", negotiateEncoding("identity"))
+}
+
+func TestCompressHandler(t *testing.T) {
+	large := strings.Repeat(`{"Hostname": "DESKTOP-1"}`, 1000)
+	handler := compressHandler(&config_proto.Config{}, http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Path == "/large" {
+				_, _ = w.Write([]byte(large))
+			} else {
+				_, _ = w.Write([]byte("{}"))
+			}
+		}))
+
+	request := httptest.NewRequest("GET", "/large", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "gzip", recorder.Header().Get("Content-Encoding"))
+	assert.True(t, recorder.Body.Len() < len(large))
+
+	reader, err := gzip.NewReader(recorder.Body)
+	assert.NoError(t, err)
+	data, err := ioutil.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, large, string(data))
+
+	// Small responses are not compressed.
+	request = httptest.NewRequest("GET", "/small", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, "", recorder.Header().Get("Content-Encoding"))
+	assert.Equal(t, "{}", recorder.Body.String())
+
+	// Clients which do not accept compression get plain responses.
+	request = httptest.NewRequest("GET", "/large", nil)
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, "", recorder.Header().Get("Content-Encoding"))
+	assert.Equal(t, large, recorder.Body.String())
+}
@@ -201,6 +201,9 @@ func getPathSpec(
 	} else if in.HuntId != "" && in.Type == "hunt_status" {
 		return paths.NewHuntPathManager(in.HuntId).ClientErrors(), nil
 
+	} else if in.NotebookId != "" && in.Type == "activity" {
+		return paths.NewNotebookPathManager(in.NotebookId).ActivityLog(), nil
+
 	} else if in.NotebookId != "" && in.CellId != "" {
 		return paths.NewNotebookPathManager(in.NotebookId).Cell(
 			in.CellId).QueryStorage(in.TableId).Path(), nil
@@ -12,6 +12,7 @@ import (
 	"www.velocidex.com/golang/velociraptor/datastore"
 	"www.velocidex.com/golang/velociraptor/file_store/api"
 	"www.velocidex.com/golang/velociraptor/file_store/path_specs"
+	"www.velocidex.com/golang/velociraptor/legal_hold"
 	"www.velocidex.com/golang/velociraptor/services"
 )
 
@@ -160,7 +161,13 @@ func (self *ApiServer) DeleteSubject(
 		return nil, err
 	}
 
-	return &emptypb.Empty{}, db.DeleteSubject(org_config_obj, getURN(in))
+	urn := getURN(in)
+	err = legal_hold.CheckPathHold(org_config_obj, urn.Components())
+	if err != nil {
+		return nil, legalHoldError(err)
+	}
+
+	return &emptypb.Empty{}, db.DeleteSubject(org_config_obj, urn)
 }
 
 func getURN(in *api_proto.DataRequest) api.DSPathSpec {
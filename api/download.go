@@ -26,6 +26,7 @@
 package api
 
 import (
+	"fmt"
 	"html"
 	"io"
 	"io/ioutil"
@@ -33,7 +34,6 @@ import (
 	"net/url"
 	"os"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/Velocidex/ordereddict"
@@ -61,14 +61,6 @@ import (
 	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
 )
 
-var (
-	pool = sync.Pool{
-		New: func() interface{} {
-			return make([]byte, 32*1024)
-		},
-	}
-)
-
 func returnError(w http.ResponseWriter, code int, message string) {
 	w.WriteHeader(code)
 	_, _ = w.Write([]byte(html.EscapeString(message)))
@@ -83,10 +75,13 @@ type vfsFileDownloadRequest struct {
 	Encoding   string   `schema:"encoding"`
 }
 
-// URL format: /api/v1/DownloadVFSFile
+// URL format: /api/v1/DownloadVFSFile or
+// /api/v1/DownloadVFSFile/{client_id}
 
 // This URL allows the caller to download **any** member of the
-// filestore (providing they have at least read permissions).
+// filestore (providing they have at least read permissions). Range
+// requests are supported so large files can be fetched in parts or
+// resumed.
 func vfsFileDownloadHandler(
 	config_obj *config_proto.Config) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -98,6 +93,17 @@ func vfsFileDownloadHandler(
 			return
 		}
 
+		idx := strings.Index(r.URL.Path, "DownloadVFSFile/")
+		if idx >= 0 {
+			request.ClientId = strings.Trim(
+				r.URL.Path[idx+len("DownloadVFSFile/"):], "/")
+		}
+
+		if request.ClientId != "" && !client_id_regex.MatchString(request.ClientId) {
+			returnError(w, 400, "Invalid client id")
+			return
+		}
+
 		var path_spec api.FSPathSpec
 		client_path_manager := paths.NewClientPathManager(request.ClientId)
 
@@ -139,10 +145,12 @@ func vfsFileDownloadHandler(
 		}
 		defer file.Close()
 
-		if r.Method == "HEAD" {
-			returnError(w, 200, "Ok")
+		stat, err := file.Stat()
+		if err != nil {
+			returnError(w, 404, err.Error())
 			return
 		}
+		size := stat.Size()
 
 		var reader_at io.ReaderAt = &utils.ReaderAtter{Reader: file}
 
@@ -154,48 +162,46 @@ func vfsFileDownloadHandler(
 				ReaderAt: reader_at,
 				Index:    index,
 			}
+
+			last := index.Ranges[len(index.Ranges)-1]
+			size = last.OriginalOffset + last.Length
 		}
 
+		// The offset and length parameters select a part of the
+		// file which is then served as if it was the whole file.
 		offset := request.Offset
+		if offset < 0 || offset > size {
+			offset = size
+		}
+		length := size - offset
+		if request.Length > 0 && int64(request.Length) < length {
+			length = int64(request.Length)
+		}
 
-		// From here on we sent the headers and we can not
-		// really report an error to the client.
-		filename := strings.Replace(path_spec.Base(), "\"", "_", -1)
-		w.Header().Set("Content-Disposition", "attachment; filename="+
-			url.PathEscape(filename))
+		w.Header().Set("Content-Disposition",
+			contentDisposition(path_spec.Base()))
 		w.Header().Set("Content-Type", "binary/octet-stream")
-		w.WriteHeader(200)
-
-		length_sent := 0
-		buf := pool.Get().([]byte)
-		defer pool.Put(buf)
-
-		for {
-			n, _ := reader_at.ReadAt(buf, offset)
-			if n > 0 {
-				if request.Length != 0 {
-					length_to_send := request.Length - length_sent
-					if n > length_to_send {
-						n = length_to_send
-					}
-				}
-				if n == 0 {
-					return
-				}
 
-				_, err := w.Write(buf[:n])
-				if err != nil {
-					return
-				}
-				length_sent += n
-				offset += int64(n)
-			} else {
-				return
-			}
-		}
+		// Handles HEAD and Range requests.
+		http.ServeContent(w, r, "", stat.ModTime(),
+			io.NewSectionReader(reader_at, offset, length))
 	})
 }
 
+// Browsers which do not understand the RFC 5987 filename* form use
+// the plain filename so keep it to safe ASCII.
+func contentDisposition(filename string) string {
+	ascii := strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, filename)
+
+	return fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`,
+		ascii, strings.Replace(url.QueryEscape(filename), "+", "%20", -1))
+}
+
 func getRows(
 	ctx context.Context,
 	config_obj *config_proto.Config,
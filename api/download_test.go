@@ -0,0 +1,19 @@
+package api
+
+import (
+	"testing"
+
+	"www.velocidex.com/golang/velociraptor/vtesting/assert"
+)
+
+func TestContentDisposition(t *testing.T) {
+	assert.Equal(t,
+		`attachment; filename="hello world.txt"; filename*=UTF-8''hello%20world.txt`,
+		contentDisposition("hello world.txt"))
+
+	// Quotes and non ASCII characters are replaced in the plain
+	// filename but preserved in the encoded one.
+	assert.Equal(t,
+		`attachment; filename="a_b_.txt"; filename*=UTF-8''a%22b%C3%BC.txt`,
+		contentDisposition(`a"bü.txt`))
+}
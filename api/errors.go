@@ -0,0 +1,139 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	context "golang.org/x/net/context"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"www.velocidex.com/golang/velociraptor/allowlist"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+const (
+	API_ERROR_DOMAIN = "velociraptor.velocidex.com"
+)
+
+// Build a status carrying a stable error code in its details. The
+// message is kept in English and the format is stored in the details
+// so the gateway can translate it.
+func newApiError(code codes.Code,
+	error_code api_proto.ApiErrorDetails_ErrorCode,
+	format string, args ...interface{}) error {
+	return newApiErrorWithMetadata(code, error_code, nil, format, args...)
+}
+
+// The status also carries a google.rpc.ErrorInfo so generic gRPC
+// clients can read the reason without knowing our details message.
+func newApiErrorWithMetadata(code codes.Code,
+	error_code api_proto.ApiErrorDetails_ErrorCode,
+	metadata map[string]string,
+	format string, args ...interface{}) error {
+	message := fmt.Sprintf(format, args...)
+	details := &api_proto.ApiErrorDetails{
+		ErrorCode:     error_code,
+		MessageFormat: format,
+	}
+	for _, arg := range args {
+		details.MessageArgs = append(details.MessageArgs, fmt.Sprintf("%v", arg))
+	}
+
+	info := &errdetails.ErrorInfo{
+		Reason:   error_code.String(),
+		Domain:   API_ERROR_DOMAIN,
+		Metadata: metadata,
+	}
+
+	st, err := status.New(code, message).WithDetails(details, info)
+	if err != nil {
+		return status.Error(code, message)
+	}
+	return st.Err()
+}
+
+func clientNotFoundError(client_id string) error {
+	return newApiError(codes.NotFound, api_proto.ApiErrorDetails_CLIENT_NOT_FOUND,
+		"Client %v not found", client_id)
+}
+
+// Attach error codes to errors returned by the services. Other
+// errors are returned unchanged.
+func apiError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+
+	case errors.Is(err, services.FlowInvalidStateError):
+		return newApiError(codes.FailedPrecondition,
+			api_proto.ApiErrorDetails_FLOW_INVALID_STATE, "%v", err)
+
+	case errors.Is(err, services.ArtifactPermissionDeniedError),
+		errors.Is(err, allowlist.ArtifactNotAllowedError):
+		return newApiError(codes.PermissionDenied,
+			api_proto.ApiErrorDetails_PERMISSION_DENIED_ARTIFACT, "%v", err)
+
+	case errors.Is(err, services.ClientCapabilityMissingError):
+		return newApiError(codes.FailedPrecondition,
+			api_proto.ApiErrorDetails_CLIENT_CAPABILITY_MISSING, "%v", err)
+
+	case errors.Is(err, services.InvalidSearchFilterError):
+		return newApiError(codes.InvalidArgument,
+			api_proto.ApiErrorDetails_INVALID_SEARCH_FILTER, "%v", err)
+	}
+
+	return err
+}
+
+func getErrorDetails(st *status.Status) *api_proto.ApiErrorDetails {
+	for _, detail := range st.Details() {
+		details, ok := detail.(*api_proto.ApiErrorDetails)
+		if ok {
+			return details
+		}
+	}
+	return nil
+}
+
+func getErrorCode(st *status.Status) api_proto.ApiErrorDetails_ErrorCode {
+	details := getErrorDetails(st)
+	if details != nil {
+		return details.ErrorCode
+	}
+
+	// Resource limits are always reported as exhausted resources.
+	if st.Code() == codes.ResourceExhausted {
+		return api_proto.ApiErrorDetails_QUOTA_EXCEEDED
+	}
+
+	return api_proto.ApiErrorDetails_UNKNOWN
+}
+
+// The gateway reports errors as an ApiErrorResponse so HTTP clients
+// get the error code without decoding the status details.
+func errorHandler(ctx context.Context, mux *runtime.ServeMux,
+	marshaler runtime.Marshaler,
+	w http.ResponseWriter, r *http.Request, err error) {
+	st := status.Convert(err)
+
+	body := &api_proto.ApiErrorResponse{
+		Code:      int32(st.Code()),
+		ErrorCode: strings.ToLower(getErrorCode(st).String()),
+		Message:   localizeStatus(st, getRequestLang(r)),
+	}
+
+	serialized, err := marshaler.Marshal(body)
+	if err != nil {
+		http.Error(w, st.Message(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", marshaler.ContentType(body))
+	w.WriteHeader(runtime.HTTPStatusFromCode(st.Code()))
+	_, _ = w.Write(serialized)
+}
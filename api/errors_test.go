@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/vtesting/assert"
+)
+
+func TestApiErrors(t *testing.T) {
+	// Service errors are given a code.
+	err := apiError(fmt.Errorf("%w: Flow F.1234 is still running",
+		services.FlowInvalidStateError))
+	st := status.Convert(err)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+	assert.Equal(t, api_proto.ApiErrorDetails_FLOW_INVALID_STATE, getErrorCode(st))
+
+	// Other errors are unchanged.
+	other := errors.New("Other")
+	assert.Equal(t, other, apiError(other))
+	assert.Equal(t, api_proto.ApiErrorDetails_UNKNOWN,
+		getErrorCode(status.Convert(other)))
+
+	assert.Equal(t, api_proto.ApiErrorDetails_QUOTA_EXCEEDED,
+		getErrorCode(status.New(codes.ResourceExhausted, "Too big")))
+
+	// The gateway writes the code in the body.
+	marshaler := &runtime.JSONPb{
+		MarshalOptions: protojson.MarshalOptions{UseProtoNames: true},
+	}
+	recorder := httptest.NewRecorder()
+	errorHandler(context.Background(), nil, marshaler, recorder, nil,
+		clientNotFoundError("C.1234"))
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	body := make(map[string]interface{})
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	assert.Equal(t, "client_not_found", body["error_code"])
+	assert.Equal(t, "Client C.1234 not found", body["message"])
+
+	// Messages are translated for the browser's language.
+	request := httptest.NewRequest("GET", "/api/v1/GetClient", nil)
+	request.Header.Set("Accept-Language", "de-DE,de;q=0.9")
+	recorder = httptest.NewRecorder()
+	errorHandler(context.Background(), nil, marshaler, recorder, request,
+		clientNotFoundError("C.1234"))
+
+	body = make(map[string]interface{})
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	assert.Equal(t, "Client C.1234 nicht gefunden", body["message"])
+}
+
+func getErrorInfo(st *status.Status) *errdetails.ErrorInfo {
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if ok {
+			return info
+		}
+	}
+	return nil
+}
+
+func TestFlowErrors(t *testing.T) {
+	// A missing flow is reported as not found.
+	st := status.Convert(flowError(fmt.Errorf("Unknown flow C.1234 F.1234: %w",
+		os.ErrNotExist), "C.1234", "F.1234"))
+	assert.Equal(t, codes.NotFound, st.Code())
+	assert.Equal(t, api_proto.ApiErrorDetails_FLOW_NOT_FOUND, getErrorCode(st))
+
+	info := getErrorInfo(st)
+	assert.NotNil(t, info)
+	assert.Equal(t, "FLOW_NOT_FOUND", info.Reason)
+	assert.Equal(t, API_ERROR_DOMAIN, info.Domain)
+	assert.Equal(t, "F.1234", info.Metadata["flow_id"])
+	assert.Equal(t, "C.1234", info.Metadata["client_id"])
+
+	// Other errors reading the flow are from the datastore.
+	st = status.Convert(flowError(errors.New("disk error"), "C.1234", "F.1234"))
+	assert.Equal(t, codes.Unavailable, st.Code())
+	assert.Equal(t, "DATASTORE_UNAVAILABLE", getErrorInfo(st).Reason)
+
+	// Service errors keep their code.
+	st = status.Convert(flowError(fmt.Errorf("%w: Flow F.1234 is not running",
+		services.FlowInvalidStateError), "C.1234", "F.1234"))
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+	assert.Equal(t, "FLOW_INVALID_STATE", getErrorInfo(st).Reason)
+
+	st = status.Convert(permissionDeniedError("User is not allowed to view flows."))
+	assert.Equal(t, codes.PermissionDenied, st.Code())
+	assert.Equal(t, "PERMISSION_DENIED", getErrorInfo(st).Reason)
+}
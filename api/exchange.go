@@ -0,0 +1,118 @@
+package api
+
+import (
+	"github.com/sirupsen/logrus"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"www.velocidex.com/golang/velociraptor/acls"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+func (self *ApiServer) ListExchangeArtifacts(
+	ctx context.Context,
+	in *api_proto.ExchangeArtifactsRequest) (*api_proto.ExchangeArtifacts, error) {
+
+	defer Instrument("ListExchangeArtifacts")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_record.Name
+	permissions := acls.ARTIFACT_WRITER
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to browse the artifact exchange.")
+	}
+
+	exchange, err := services.GetArtifactExchange(org_config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return exchange.ListArtifacts(ctx, org_config_obj, in.Filter)
+}
+
+// Exchange artifacts may be of any type so installing them needs the
+// same permission as the Server.Import.ArtifactExchange artifact.
+func (self *ApiServer) InstallExchangeArtifact(
+	ctx context.Context,
+	in *api_proto.InstallExchangeArtifactRequest) (*api_proto.ExchangeArtifact, error) {
+
+	defer Instrument("InstallExchangeArtifact")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_record.Name
+	permissions := acls.SERVER_ADMIN
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to install exchange artifacts.")
+	}
+
+	if in.Name == "" {
+		return nil, status.Error(codes.InvalidArgument,
+			"An artifact name must be specified")
+	}
+
+	exchange, err := services.GetArtifactExchange(org_config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := exchange.InstallArtifact(
+		ctx, org_config_obj, user_name, in.Name, in.Version)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// Log this event as an Audit event.
+	logging.GetLogger(org_config_obj, &logging.Audit).
+		WithFields(logrus.Fields{
+			"user":     user_name,
+			"artifact": result.InstalledName,
+			"version":  result.InstalledVersion,
+			"sha256":   result.Sha256,
+		}).Info("InstallExchangeArtifact")
+
+	return result, nil
+}
+
+func (self *ApiServer) CheckExchangeUpdates(
+	ctx context.Context,
+	in *api_proto.ExchangeArtifactsRequest) (*api_proto.ExchangeArtifacts, error) {
+
+	defer Instrument("CheckExchangeUpdates")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_record.Name
+	permissions := acls.ARTIFACT_WRITER
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to browse the artifact exchange.")
+	}
+
+	exchange, err := services.GetArtifactExchange(org_config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return exchange.CheckForUpdates(ctx, org_config_obj, in.Filter)
+}
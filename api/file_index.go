@@ -0,0 +1,42 @@
+package api
+
+import (
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"www.velocidex.com/golang/velociraptor/acls"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+func (self *ApiServer) SearchFiles(
+	ctx context.Context,
+	in *api_proto.SearchFilesRequest) (*api_proto.SearchFilesResponse, error) {
+
+	defer Instrument("SearchFiles")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_record.Name
+	permissions := acls.READ_RESULTS
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to search collected files.")
+	}
+
+	file_indexer, err := services.GetFileIndexer(org_config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := file_indexer.Search(ctx, org_config_obj, in)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return result, nil
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"errors"
+
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"www.velocidex.com/golang/velociraptor/acls"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/flow_archive"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+func (self *ApiServer) ArchiveFlow(
+	ctx context.Context,
+	in *api_proto.ApiFlowRequest) (*api_proto.FlowDetails, error) {
+
+	defer Instrument("ArchiveFlow")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_record.Name
+	permissions := acls.COLLECT_CLIENT
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to archive flows.")
+	}
+
+	_, err = flow_archive.ArchiveFlow(
+		org_config_obj, in.ClientId, in.FlowId, user_name)
+	if err != nil {
+		return nil, archiveError(err)
+	}
+
+	return self.getUncachedFlowDetails(org_config_obj, in.ClientId, in.FlowId)
+}
+
+// The GUI rehydrates archived flows when they are opened so any user
+// able to read the results may do so.
+func (self *ApiServer) RehydrateFlow(
+	ctx context.Context,
+	in *api_proto.ApiFlowRequest) (*api_proto.FlowDetails, error) {
+
+	defer Instrument("RehydrateFlow")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_record.Name
+	permissions := acls.READ_RESULTS
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to rehydrate flows.")
+	}
+
+	_, err = flow_archive.RehydrateFlow(
+		org_config_obj, in.ClientId, in.FlowId, user_name)
+	if err != nil {
+		return nil, archiveError(err)
+	}
+
+	return self.getUncachedFlowDetails(org_config_obj, in.ClientId, in.FlowId)
+}
+
+// The flow's context changed so the cached copy is stale.
+func (self *ApiServer) getUncachedFlowDetails(
+	config_obj *config_proto.Config,
+	client_id, flow_id string) (*api_proto.FlowDetails, error) {
+	self.cache.InvalidateFlow(config_obj, client_id, flow_id)
+
+	launcher, err := services.GetLauncher(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return launcher.GetFlowDetails(config_obj, client_id, flow_id)
+}
+
+func archiveError(err error) error {
+	switch {
+	case errors.Is(err, flow_archive.NotConfiguredError):
+		return status.Error(codes.FailedPrecondition, err.Error())
+
+	case errors.Is(err, services.FlowInvalidStateError):
+		return apiError(err)
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
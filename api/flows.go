@@ -0,0 +1,179 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"www.velocidex.com/golang/velociraptor/acls"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+func permissionDeniedError(message string) error {
+	return newApiError(codes.PermissionDenied,
+		api_proto.ApiErrorDetails_PERMISSION_DENIED, "%s", message)
+}
+
+// Classify errors from the launcher so callers can tell a missing
+// flow from an unavailable datastore without matching the message.
+func flowError(err error, client_id, flow_id string) error {
+	metadata := map[string]string{
+		"client_id": client_id,
+		"flow_id":   flow_id,
+	}
+
+	switch {
+	case err == nil:
+		return nil
+
+	case errors.Is(err, os.ErrNotExist):
+		return newApiErrorWithMetadata(codes.NotFound,
+			api_proto.ApiErrorDetails_FLOW_NOT_FOUND, metadata,
+			"Flow %v not found for client %v", flow_id, client_id)
+
+	// Errors already classified by the services.
+	case errors.Is(err, services.FlowInvalidStateError):
+		return apiError(err)
+
+	// A status error was already built.
+	case isStatusError(err):
+		return err
+	}
+
+	return newApiErrorWithMetadata(codes.Unavailable,
+		api_proto.ApiErrorDetails_DATASTORE_UNAVAILABLE, metadata,
+		"Unable to read flow %v: %v", flow_id, err)
+}
+
+func isStatusError(err error) bool {
+	_, ok := status.FromError(err)
+	return ok
+}
+
+func (self *ApiServer) CancelFlow(
+	ctx context.Context,
+	in *api_proto.ApiFlowRequest) (*api_proto.StartFlowResponse, error) {
+
+	defer Instrument("CancelFlow")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_record.Name
+
+	permissions := acls.COLLECT_CLIENT
+	if in.ClientId == "server" {
+		permissions = acls.COLLECT_SERVER
+	}
+
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, permissionDeniedError("User is not allowed to cancel flows.")
+	}
+
+	// The launcher silently ignores requests without a flow.
+	if in.ClientId == "" || in.FlowId == "" {
+		return nil, status.Error(codes.InvalidArgument,
+			"A client id and flow id must be specified")
+	}
+
+	launcher, err := services.GetLauncher(org_config_obj)
+	if err != nil {
+		return nil, err
+	}
+	result, err := launcher.CancelFlow(
+		ctx, org_config_obj, in.ClientId, in.FlowId, user_name)
+	if err != nil {
+		return nil, flowError(err, in.ClientId, in.FlowId)
+	}
+	self.cache.InvalidateFlow(org_config_obj, in.ClientId, in.FlowId)
+
+	// Log this event as and Audit event.
+	logging.GetLogger(org_config_obj, &logging.Audit).
+		WithFields(logrus.Fields{
+			"user":    user_name,
+			"client":  in.ClientId,
+			"flow_id": in.FlowId,
+			"details": fmt.Sprintf("%v", in),
+		}).Info("CancelFlow")
+
+	return result, nil
+}
+
+func (self *ApiServer) GetFlowDetails(
+	ctx context.Context,
+	in *api_proto.ApiFlowRequest) (*api_proto.FlowDetails, error) {
+
+	defer Instrument("GetFlowDetails")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_record.Name
+	permissions := acls.READ_RESULTS
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, permissionDeniedError("User is not allowed to view flows.")
+	}
+
+	result, pres := self.cache.GetFlowDetails(
+		org_config_obj, in.ClientId, in.FlowId)
+	if pres {
+		return result, nil
+	}
+
+	launcher, err := services.GetLauncher(org_config_obj)
+	if err != nil {
+		return nil, err
+	}
+	result, err = launcher.GetFlowDetails(org_config_obj, in.ClientId, in.FlowId)
+	if err != nil {
+		return nil, flowError(err, in.ClientId, in.FlowId)
+	}
+
+	self.cache.SetFlowDetails(org_config_obj, in.ClientId, in.FlowId, result)
+	return result, nil
+}
+
+func (self *ApiServer) GetFlowRequests(
+	ctx context.Context,
+	in *api_proto.ApiFlowRequest) (*api_proto.ApiFlowRequestDetails, error) {
+
+	defer Instrument("GetFlowRequests")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_record.Name
+	permissions := acls.READ_RESULTS
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, permissionDeniedError("User is not allowed to view flows.")
+	}
+
+	launcher, err := services.GetLauncher(org_config_obj)
+	if err != nil {
+		return nil, err
+	}
+	result, err := launcher.GetFlowRequests(org_config_obj, in.ClientId, in.FlowId,
+		in.Offset, in.Count)
+	if err != nil {
+		return nil, flowError(err, in.ClientId, in.FlowId)
+	}
+	return result, nil
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/services/launcher"
+	"www.velocidex.com/golang/velociraptor/vtesting/assert"
+)
+
+func (self *ApiTestSuite) TestCancelFlow() {
+	ctx := self.userContext("alice")
+
+	// Requests must specify the flow.
+	for _, request := range []*api_proto.ApiFlowRequest{
+		{}, {ClientId: self.client_id}, {FlowId: "F.1234"},
+	} {
+		_, err := self.server.CancelFlow(ctx, request)
+		assert.Error(self.T(), err)
+		assert.Equal(self.T(), codes.InvalidArgument, status.Code(err))
+	}
+
+	result, err := self.server.CollectArtifact(ctx,
+		&flows_proto.ArtifactCollectorArgs{
+			ClientId:  self.client_id,
+			Artifacts: []string{"Custom.Safe"},
+		})
+	assert.NoError(self.T(), err)
+
+	client_info_manager, err := services.GetClientInfoManager(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	tasks, err := client_info_manager.PeekClientTasks(self.client_id)
+	assert.NoError(self.T(), err)
+	assert.True(self.T(), len(tasks) > 0)
+
+	_, err = self.server.CancelFlow(ctx, &api_proto.ApiFlowRequest{
+		ClientId: self.client_id,
+		FlowId:   result.FlowId,
+	})
+	assert.NoError(self.T(), err)
+
+	// The flow is marked as cancelled.
+	collection_context, err := launcher.LoadCollectionContext(
+		self.ConfigObj, self.client_id, result.FlowId)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), flows_proto.ArtifactCollectorContext_ERROR,
+		collection_context.State)
+	assert.Equal(self.T(), "Cancelled by alice", collection_context.Status)
+
+	// Only the cancellation message is left for the client.
+	tasks, err = client_info_manager.PeekClientTasks(self.client_id)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), 1, len(tasks))
+	assert.True(self.T(), tasks[0].Cancel != nil)
+
+	// The flow is no longer running.
+	_, err = self.server.CancelFlow(ctx, &api_proto.ApiFlowRequest{
+		ClientId: self.client_id,
+		FlowId:   result.FlowId,
+	})
+	assert.Error(self.T(), err)
+	assert.Equal(self.T(), codes.FailedPrecondition, status.Code(err))
+}
@@ -16,6 +16,7 @@ import (
 	"www.velocidex.com/golang/velociraptor/file_store/csv"
 	"www.velocidex.com/golang/velociraptor/logging"
 	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/services/client_info"
 	"www.velocidex.com/golang/velociraptor/services/hunt_dispatcher"
 	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
 )
@@ -48,6 +49,12 @@ func (self *ApiServer) GetHuntFlows(
 		return nil, status.Error(codes.InvalidArgument, "No hunt known")
 	}
 
+	page, err := newPager(in.PageToken, in.StartRow, in.Rows,
+		DEFAULT_TABLE_ROWS, MAX_TABLE_ROWS, "GetHuntFlows", in.HuntId)
+	if err != nil {
+		return nil, err
+	}
+
 	total_scheduled := int64(-1)
 	if hunt.Stats != nil {
 		total_scheduled = int64(hunt.Stats.TotalClientsScheduled)
@@ -62,7 +69,7 @@ func (self *ApiServer) GetHuntFlows(
 
 	scope := vql_subsystem.MakeScope()
 	for flow := range hunt_dispatcher.GetFlows(ctx, org_config_obj, scope,
-		in.HuntId, int(in.StartRow)) {
+		in.HuntId, int(page.Offset)) {
 		if flow.Context == nil {
 			continue
 		}
@@ -79,10 +86,15 @@ func (self *ApiServer) GetHuntFlows(
 
 		result.Rows = append(result.Rows, &api_proto.Row{Cell: row_data})
 
-		if uint64(len(result.Rows)) > in.Rows {
+		if uint64(len(result.Rows)) >= page.FetchSize() {
 			break
 		}
 	}
+
+	length, token := page.Page(len(result.Rows))
+	result.Rows = result.Rows[:length]
+	result.NextPageToken = token
+
 	return result, nil
 }
 
@@ -111,6 +123,11 @@ func (self *ApiServer) CreateHunt(
 			"User is not allowed to launch hunts.")
 	}
 
+	// The hunt's requests are always compiled from the artifacts.
+	if in.StartRequest != nil {
+		in.StartRequest.CompiledCollectorArgs = nil
+	}
+
 	logging.GetLogger(org_config_obj, &logging.Audit).
 		WithFields(logrus.Fields{
 			"user":    in.Creator,
@@ -124,10 +141,11 @@ func (self *ApiServer) CreateHunt(
 		return nil, err
 	}
 
+	// The hunt dispatcher checks the approval.
 	hunt_id, err := hunt_dispatcher.CreateHunt(
 		ctx, org_config_obj, acl_manager, in)
 	if err != nil {
-		return nil, err
+		return nil, approvalError(err)
 	}
 
 	result.FlowId = hunt_id
@@ -202,15 +220,30 @@ func (self *ApiServer) ListHunts(
 		return nil, err
 	}
 
+	page, err := newPager(in.PageToken, in.Offset, in.Count,
+		DEFAULT_PAGE_SIZE, MAX_PAGE_SIZE,
+		"ListHunts", in.IncludeArchived)
+	if err != nil {
+		return nil, err
+	}
+	in.Offset = page.Offset
+	in.Count = page.FetchSize()
+
 	result, err := hunt_dispatcher.ListHunts(
 		ctx, org_config_obj, in)
 	if err != nil {
 		return nil, err
 	}
 
+	length, token := page.Page(len(result.Items))
+	result.Items = result.Items[:length]
+	result.NextPageToken = token
+
 	// Provide only a summary for list hunts GUI
 	if in.Summary {
-		summary := &api_proto.ListHuntsResponse{}
+		summary := &api_proto.ListHuntsResponse{
+			NextPageToken: result.NextPageToken,
+		}
 		for _, item := range result.Items {
 			summary.Items = append(summary.Items, &api_proto.Hunt{
 				HuntId:          item.HuntId,
@@ -288,14 +321,15 @@ func (self *ApiServer) GetHuntResults(
 
 	env := ordereddict.NewDict().
 		Set("HuntID", in.HuntId).
-		Set("ArtifactName", in.Artifact)
+		Set("ArtifactName", in.Artifact).
+		Set("ClientInfo", in.ClientInfo)
 
 	// More than 100 results are not very useful in the GUI -
 	// users should just download the json file for post
 	// processing or process in the notebook.
 	result, err := RunVQL(ctx, org_config_obj, user_name, env,
 		"SELECT * FROM hunt_results(hunt_id=HuntID, "+
-			"artifact=ArtifactName) LIMIT 100")
+			"artifact=ArtifactName, client_info=ClientInfo) LIMIT 100")
 	if err != nil {
 		return nil, err
 	}
@@ -333,8 +367,19 @@ func (self *ApiServer) EstimateHunt(
 	}
 
 	now := uint64(time.Now().UnixNano() / 1000)
+	metadata_condition := in.Condition.GetMetadata()
 
 	is_client_recent := func(client_id string, seen map[string]bool) {
+		// The metadata condition applies to all the other
+		// conditions.
+		if len(metadata_condition) > 0 {
+			metadata, err := client_info.GetMetadata(org_config_obj, client_id)
+			if err != nil ||
+				!client_info.MatchMetadata(metadata, metadata_condition) {
+				return
+			}
+		}
+
 		// We dont care about last active status
 		if in.LastActive == 0 {
 			seen[client_id] = true
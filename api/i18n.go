@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/status"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/constants"
+	"www.velocidex.com/golang/velociraptor/i18n"
+	"www.velocidex.com/golang/velociraptor/json"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+// The user's language as set in their GUI options.
+func getUserLang(username string) string {
+	users := services.GetUserManager()
+	if users == nil || username == "" {
+		return i18n.DEFAULT_LANG
+	}
+
+	options, _ := users.GetUserOptions(username)
+	if options == nil || options.Lang == "" {
+		return i18n.DEFAULT_LANG
+	}
+	return i18n.Normalize(options.Lang)
+}
+
+// Prefer the user's GUI setting and fall back to the browser's
+// preference for requests without one.
+func getRequestLang(r *http.Request) string {
+	if r == nil {
+		return i18n.DEFAULT_LANG
+	}
+
+	user_info, ok := r.Context().Value(constants.GRPC_USER_CONTEXT).(string)
+	if ok {
+		user_record := &api_proto.VelociraptorUser{}
+		err := json.Unmarshal([]byte(user_info), user_record)
+		if err == nil {
+			lang := getUserLang(user_record.Name)
+			if lang != i18n.DEFAULT_LANG {
+				return lang
+			}
+		}
+	}
+
+	return i18n.FromAcceptLanguage(r.Header.Get("Accept-Language"))
+}
+
+// Translate the status message. Api errors carry their format so
+// formatted messages can be translated too.
+func localizeStatus(st *status.Status, lang string) string {
+	details := getErrorDetails(st)
+	if details == nil || details.MessageFormat == "" {
+		return i18n.T(lang, st.Message())
+	}
+
+	args := make([]interface{}, 0, len(details.MessageArgs))
+	for _, arg := range details.MessageArgs {
+		args = append(args, arg)
+	}
+	return i18n.Sprintf(lang, details.MessageFormat, args...)
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"archive/zip"
+	"errors"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"www.velocidex.com/golang/velociraptor/acls"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/flows"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/paths"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+// Import an offline collection so it lives alongside the collections
+// made by networked clients.
+func (self *ApiServer) ImportCollection(
+	ctx context.Context,
+	in *api_proto.ImportCollectionRequest) (*api_proto.FlowDetails, error) {
+
+	defer Instrument("ImportCollection")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The container is read from the server's filesystem so the
+	// user needs to be able to read it as well as collect server
+	// artifacts.
+	user_name := user_record.Name
+	for _, permission := range []acls.ACL_PERMISSION{
+		acls.COLLECT_SERVER, acls.FILESYSTEM_READ} {
+		perm, err := acls.CheckAccess(org_config_obj, user_name, permission)
+		if !perm || err != nil {
+			return nil, status.Error(codes.PermissionDenied,
+				"User is not allowed to import collections.")
+		}
+	}
+
+	if in.Filename == "" {
+		return nil, status.Error(codes.InvalidArgument,
+			"A container filename must be specified")
+	}
+
+	// KAPE can also write VHDX containers which we can not read
+	// directly.
+	if strings.HasSuffix(strings.ToLower(in.Filename), ".vhdx") {
+		return nil, status.Error(codes.InvalidArgument,
+			"VHDX containers are not supported: please collect to a zip instead")
+	}
+
+	zipfile, err := zip.OpenReader(in.Filename)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	job_manager, err := services.GetJobManager(org_config_obj)
+	if err != nil {
+		zipfile.Close()
+		return nil, err
+	}
+
+	var new_flow *flows_proto.ArtifactCollectorContext
+
+	job, err := job_manager.StartJob(org_config_obj, &api_proto.Job{
+		Type:        "import",
+		Description: "Import " + in.Filename,
+		Principal:   user_name,
+	}, func(ctx context.Context, progress services.JobProgress) (string, error) {
+		defer zipfile.Close()
+
+		logger := logging.GetLogger(org_config_obj, &logging.GUIComponent)
+		flow, err := flows.ImportCollection(ctx, org_config_obj,
+			&zipfile.Reader, in.Filename, flows.ImportCollectionOptions{
+				ClientId: in.ClientId,
+				Hostname: in.Hostname,
+				Creator:  user_name,
+				Format:   in.Format,
+				Log: func(format string, args ...interface{}) {
+					logger.Debug(format, args...)
+				},
+			})
+		if err != nil {
+			return "", err
+		}
+
+		// Log this event as an Audit event.
+		logging.GetLogger(org_config_obj, &logging.Audit).
+			WithFields(logrus.Fields{
+				"user":     user_name,
+				"client":   flow.ClientId,
+				"flow_id":  flow.SessionId,
+				"filename": in.Filename,
+			}).Info("ImportCollection")
+
+		new_flow = flow
+		return paths.NewFlowPathManager(
+			flow.ClientId, flow.SessionId).Path().AsClientPath(), nil
+	})
+	if err != nil {
+		zipfile.Close()
+		return nil, err
+	}
+
+	if in.Background {
+		return &api_proto.FlowDetails{JobId: job.JobId}, nil
+	}
+
+	job, err = job_manager.WaitForJob(ctx, job.JobId)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.State != api_proto.Job_FINISHED {
+		return nil, errors.New(job.Error)
+	}
+
+	return &api_proto.FlowDetails{Context: new_flow, JobId: job.JobId}, nil
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"io"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"www.velocidex.com/golang/velociraptor/acls"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/flows"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+// IngestRows lets external tools (e.g. third party scanners) push
+// their output into the server. The rows are stored as a new flow on
+// the client so they can be queried with the rest of the collections.
+func (self *ApiServer) IngestRows(stream api_proto.API_IngestRowsServer) error {
+	defer Instrument("IngestRows")()
+
+	ctx := stream.Context()
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Ingested rows are stored as a collection on the client.
+	user_name := user_record.Name
+	permissions := acls.COLLECT_CLIENT
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return status.Error(codes.PermissionDenied,
+			"User is not allowed to ingest rows.")
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	if first.ClientId == "" || first.Artifact == "" {
+		return status.Error(codes.InvalidArgument,
+			"The first message must specify a client id and artifact")
+	}
+
+	// Feed the rows from the stream into the flow as they arrive.
+	reader, writer := io.Pipe()
+	go func() {
+		_, err := writer.Write([]byte(first.Jsonl))
+		for err == nil {
+			var in *api_proto.IngestRowsRequest
+			in, err = stream.Recv()
+			if err == nil {
+				_, err = writer.Write([]byte(in.Jsonl))
+			}
+		}
+
+		// io.EOF means the client closed the stream so the
+		// reader sees the end of the rows.
+		if err == io.EOF {
+			err = nil
+		}
+		writer.CloseWithError(err)
+	}()
+	defer reader.Close()
+
+	logger := logging.GetLogger(org_config_obj, &logging.GUIComponent)
+	flow, err := flows.IngestRows(ctx, org_config_obj, reader,
+		flows.IngestRowsOptions{
+			ClientId: first.ClientId,
+			Artifact: first.Artifact,
+			Creator:  user_name,
+			Log: func(format string, args ...interface{}) {
+				logger.Debug(format, args...)
+			},
+		})
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// Log this event as an Audit event.
+	logging.GetLogger(org_config_obj, &logging.Audit).
+		WithFields(logrus.Fields{
+			"user":     user_name,
+			"client":   flow.ClientId,
+			"flow_id":  flow.SessionId,
+			"artifact": first.Artifact,
+			"rows":     flow.TotalCollectedRows,
+		}).Info("IngestRows")
+
+	return stream.SendAndClose(&api_proto.FlowDetails{Context: flow})
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"www.velocidex.com/golang/velociraptor/acls"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/utils"
+)
+
+func (self *ApiServer) GetJob(
+	ctx context.Context,
+	in *api_proto.GetJobRequest) (*api_proto.Job, error) {
+
+	defer Instrument("GetJob")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_record.Name
+	permissions := acls.READ_RESULTS
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to view jobs.")
+	}
+
+	job_manager, err := services.GetJobManager(org_config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := job_manager.GetJob(ctx, in.JobId)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	return job, nil
+}
+
+func (self *ApiServer) ListJobs(
+	ctx context.Context,
+	in *api_proto.ListJobsRequest) (*api_proto.ListJobsResponse, error) {
+
+	defer Instrument("ListJobs")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_record.Name
+	permissions := acls.READ_RESULTS
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to view jobs.")
+	}
+
+	page, err := newPager(in.PageToken, in.Offset, in.Count,
+		DEFAULT_PAGE_SIZE, MAX_PAGE_SIZE, "ListJobs", in.Type, in.Principal)
+	if err != nil {
+		return nil, err
+	}
+
+	job_manager, err := services.GetJobManager(org_config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, err := job_manager.ListJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []*api_proto.Job{}
+	for _, job := range jobs {
+		if (in.Type != "" && job.Type != in.Type) ||
+			(in.Principal != "" && job.Principal != in.Principal) {
+			continue
+		}
+		items = append(items, job)
+	}
+
+	start, end := utils.PageBounds(page.Offset, page.FetchSize(), len(items))
+	length, token := page.Page(end - start)
+
+	return &api_proto.ListJobsResponse{
+		Items:         items[start : start+length],
+		NextPageToken: token,
+	}, nil
+}
+
+// Users may cancel their own jobs but only administrators may cancel
+// other users' jobs.
+func (self *ApiServer) CancelJob(
+	ctx context.Context,
+	in *api_proto.GetJobRequest) (*api_proto.Job, error) {
+
+	defer Instrument("CancelJob")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_record.Name
+	job_manager, err := services.GetJobManager(org_config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := job_manager.GetJob(ctx, in.JobId)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	permissions := acls.READ_RESULTS
+	if job.Principal != user_name {
+		permissions = acls.SERVER_ADMIN
+	}
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to cancel this job.")
+	}
+
+	job, err = job_manager.CancelJob(ctx, in.JobId, user_name)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	return job, nil
+}
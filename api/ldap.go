@@ -0,0 +1,85 @@
+package api
+
+import (
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"www.velocidex.com/golang/velociraptor/acls"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/ldap"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+func (self *ApiServer) GetLDAPMappings(
+	ctx context.Context,
+	in *emptypb.Empty) (*api_proto.LDAPGroupMappings, error) {
+
+	defer Instrument("GetLDAPMappings")()
+
+	users := services.GetUserManager()
+	user_record, _, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	root_config_obj, err := getRootConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_record.Name
+	permissions := acls.SERVER_ADMIN
+	perm, err := acls.CheckAccess(root_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to view LDAP mappings.")
+	}
+
+	return ldap.GetMappings(root_config_obj)
+}
+
+// The mappings may grant access to any org so only administrators of
+// the root org may change them.
+func (self *ApiServer) SetLDAPMappings(
+	ctx context.Context,
+	in *api_proto.LDAPGroupMappings) (*api_proto.LDAPGroupMappings, error) {
+
+	defer Instrument("SetLDAPMappings")()
+
+	users := services.GetUserManager()
+	user_record, _, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	root_config_obj, err := getRootConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_record.Name
+	permissions := acls.SERVER_ADMIN
+	perm, err := acls.CheckAccess(root_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to set LDAP mappings.")
+	}
+
+	err = ldap.SetMappings(root_config_obj, in, user_name)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return ldap.GetMappings(root_config_obj)
+}
+
+// The mappings are stored in the root org.
+func getRootConfig() (*config_proto.Config, error) {
+	org_manager, err := services.GetOrgManager()
+	if err != nil {
+		return nil, err
+	}
+	return org_manager.GetOrgConfig("")
+}
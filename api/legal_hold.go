@@ -0,0 +1,99 @@
+package api
+
+import (
+	"errors"
+
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"www.velocidex.com/golang/velociraptor/acls"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/legal_hold"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+// Investigators may place holds on evidence but only an
+// administrator may release them.
+func (self *ApiServer) SetLegalHold(
+	ctx context.Context,
+	in *api_proto.LegalHoldRequest) (*api_proto.LegalHold, error) {
+
+	defer Instrument("SetLegalHold")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_record.Name
+	permissions := acls.COLLECT_CLIENT
+	if in.Release {
+		permissions = acls.SERVER_ADMIN
+	}
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to manage legal holds.")
+	}
+
+	if in.Hold == nil {
+		return nil, status.Error(codes.InvalidArgument, "No hold specified")
+	}
+
+	if in.Release {
+		err = legal_hold.ReleaseHold(org_config_obj, in.Hold, user_name)
+	} else {
+		err = legal_hold.SetHold(org_config_obj, in.Hold, user_name)
+	}
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return in.Hold, nil
+}
+
+func (self *ApiServer) GetLegalHolds(
+	ctx context.Context,
+	in *api_proto.GetLegalHoldsRequest) (*api_proto.LegalHolds, error) {
+
+	defer Instrument("GetLegalHolds")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_record.Name
+	permissions := acls.READ_RESULTS
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to view legal holds.")
+	}
+
+	holds, err := legal_hold.GetHolds(org_config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &api_proto.LegalHolds{}
+	for _, hold := range holds.Items {
+		if (in.ClientId != "" && hold.ClientId != in.ClientId) ||
+			(in.HuntId != "" && hold.HuntId != in.HuntId) {
+			continue
+		}
+		result.Items = append(result.Items, hold)
+	}
+
+	return result, nil
+}
+
+// Deletion APIs report held evidence as a failed precondition.
+func legalHoldError(err error) error {
+	if errors.Is(err, legal_hold.LegalHoldError) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return err
+}
@@ -12,6 +12,7 @@ import (
 	grpc "google.golang.org/grpc"
 	metadata "google.golang.org/grpc/metadata"
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	proto3 "www.velocidex.com/golang/velociraptor/acls/proto"
 	proto "www.velocidex.com/golang/velociraptor/actions/proto"
 	proto0 "www.velocidex.com/golang/velociraptor/api/proto"
 	proto1 "www.velocidex.com/golang/velociraptor/artifacts/proto"
@@ -41,6 +42,26 @@ func (m *MockAPIClient) EXPECT() *MockAPIClientMockRecorder {
 	return m.recorder
 }
 
+// ArchiveFlow mocks base method.
+func (m *MockAPIClient) ArchiveFlow(arg0 context.Context, arg1 *proto0.ApiFlowRequest, arg2 ...grpc.CallOption) (*proto0.FlowDetails, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ArchiveFlow", varargs...)
+	ret0, _ := ret[0].(*proto0.FlowDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ArchiveFlow indicates an expected call of ArchiveFlow.
+func (mr *MockAPIClientMockRecorder) ArchiveFlow(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveFlow", reflect.TypeOf((*MockAPIClient)(nil).ArchiveFlow), varargs...)
+}
+
 // CancelFlow mocks base method.
 func (m *MockAPIClient) CancelFlow(arg0 context.Context, arg1 *proto0.ApiFlowRequest, arg2 ...grpc.CallOption) (*proto0.StartFlowResponse, error) {
 	m.ctrl.T.Helper()
@@ -61,6 +82,26 @@ func (mr *MockAPIClientMockRecorder) CancelFlow(arg0, arg1 interface{}, arg2 ...
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelFlow", reflect.TypeOf((*MockAPIClient)(nil).CancelFlow), varargs...)
 }
 
+// CancelJob mocks base method.
+func (m *MockAPIClient) CancelJob(arg0 context.Context, arg1 *proto0.GetJobRequest, arg2 ...grpc.CallOption) (*proto0.Job, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CancelJob", varargs...)
+	ret0, _ := ret[0].(*proto0.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelJob indicates an expected call of CancelJob.
+func (mr *MockAPIClientMockRecorder) CancelJob(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelJob", reflect.TypeOf((*MockAPIClient)(nil).CancelJob), varargs...)
+}
+
 // CancelNotebookCell mocks base method.
 func (m *MockAPIClient) CancelNotebookCell(arg0 context.Context, arg1 *proto0.NotebookCellRequest, arg2 ...grpc.CallOption) (*emptypb.Empty, error) {
 	m.ctrl.T.Helper()
@@ -101,6 +142,26 @@ func (mr *MockAPIClientMockRecorder) Check(arg0, arg1 interface{}, arg2 ...inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockAPIClient)(nil).Check), varargs...)
 }
 
+// CheckExchangeUpdates mocks base method.
+func (m *MockAPIClient) CheckExchangeUpdates(arg0 context.Context, arg1 *proto0.ExchangeArtifactsRequest, arg2 ...grpc.CallOption) (*proto0.ExchangeArtifacts, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CheckExchangeUpdates", varargs...)
+	ret0, _ := ret[0].(*proto0.ExchangeArtifacts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckExchangeUpdates indicates an expected call of CheckExchangeUpdates.
+func (mr *MockAPIClientMockRecorder) CheckExchangeUpdates(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckExchangeUpdates", reflect.TypeOf((*MockAPIClient)(nil).CheckExchangeUpdates), varargs...)
+}
+
 // CollectArtifact mocks base method.
 func (m *MockAPIClient) CollectArtifact(arg0 context.Context, arg1 *proto2.ArtifactCollectorArgs, arg2 ...grpc.CallOption) (*proto2.ArtifactCollectorResponse, error) {
 	m.ctrl.T.Helper()
@@ -121,6 +182,46 @@ func (mr *MockAPIClientMockRecorder) CollectArtifact(arg0, arg1 interface{}, arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CollectArtifact", reflect.TypeOf((*MockAPIClient)(nil).CollectArtifact), varargs...)
 }
 
+// CollectArtifactMulti mocks base method.
+func (m *MockAPIClient) CollectArtifactMulti(arg0 context.Context, arg1 *proto0.CollectArtifactMultiRequest, arg2 ...grpc.CallOption) (*proto0.CollectArtifactMultiResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CollectArtifactMulti", varargs...)
+	ret0, _ := ret[0].(*proto0.CollectArtifactMultiResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CollectArtifactMulti indicates an expected call of CollectArtifactMulti.
+func (mr *MockAPIClientMockRecorder) CollectArtifactMulti(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CollectArtifactMulti", reflect.TypeOf((*MockAPIClient)(nil).CollectArtifactMulti), varargs...)
+}
+
+// CreateApiKey mocks base method.
+func (m *MockAPIClient) CreateApiKey(arg0 context.Context, arg1 *proto0.CreateApiKeyRequest, arg2 ...grpc.CallOption) (*proto0.CreateApiKeyResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateApiKey", varargs...)
+	ret0, _ := ret[0].(*proto0.CreateApiKeyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateApiKey indicates an expected call of CreateApiKey.
+func (mr *MockAPIClientMockRecorder) CreateApiKey(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateApiKey", reflect.TypeOf((*MockAPIClient)(nil).CreateApiKey), varargs...)
+}
+
 // CreateDownloadFile mocks base method.
 func (m *MockAPIClient) CreateDownloadFile(arg0 context.Context, arg1 *proto0.CreateDownloadRequest, arg2 ...grpc.CallOption) (*proto0.CreateDownloadResponse, error) {
 	m.ctrl.T.Helper()
@@ -181,6 +282,46 @@ func (mr *MockAPIClientMockRecorder) CreateNotebookDownloadFile(arg0, arg1 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNotebookDownloadFile", reflect.TypeOf((*MockAPIClient)(nil).CreateNotebookDownloadFile), varargs...)
 }
 
+// DeleteClient mocks base method.
+func (m *MockAPIClient) DeleteClient(arg0 context.Context, arg1 *proto0.DeleteClientRequest, arg2 ...grpc.CallOption) (*proto0.GetTableResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeleteClient", varargs...)
+	ret0, _ := ret[0].(*proto0.GetTableResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteClient indicates an expected call of DeleteClient.
+func (mr *MockAPIClientMockRecorder) DeleteClient(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteClient", reflect.TypeOf((*MockAPIClient)(nil).DeleteClient), varargs...)
+}
+
+// DeleteRole mocks base method.
+func (m *MockAPIClient) DeleteRole(arg0 context.Context, arg1 *proto0.DeleteRoleRequest, arg2 ...grpc.CallOption) (*emptypb.Empty, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeleteRole", varargs...)
+	ret0, _ := ret[0].(*emptypb.Empty)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteRole indicates an expected call of DeleteRole.
+func (mr *MockAPIClientMockRecorder) DeleteRole(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRole", reflect.TypeOf((*MockAPIClient)(nil).DeleteRole), varargs...)
+}
+
 // DeleteSubject mocks base method.
 func (m *MockAPIClient) DeleteSubject(arg0 context.Context, arg1 *proto0.DataRequest, arg2 ...grpc.CallOption) (*emptypb.Empty, error) {
 	m.ctrl.T.Helper()
@@ -221,6 +362,46 @@ func (mr *MockAPIClientMockRecorder) EstimateHunt(arg0, arg1 interface{}, arg2 .
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EstimateHunt", reflect.TypeOf((*MockAPIClient)(nil).EstimateHunt), varargs...)
 }
 
+// GetApiVersions mocks base method.
+func (m *MockAPIClient) GetApiVersions(arg0 context.Context, arg1 *emptypb.Empty, arg2 ...grpc.CallOption) (*proto0.ApiVersions, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetApiVersions", varargs...)
+	ret0, _ := ret[0].(*proto0.ApiVersions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApiVersions indicates an expected call of GetApiVersions.
+func (mr *MockAPIClientMockRecorder) GetApiVersions(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApiVersions", reflect.TypeOf((*MockAPIClient)(nil).GetApiVersions), varargs...)
+}
+
+// GetApprovals mocks base method.
+func (m *MockAPIClient) GetApprovals(arg0 context.Context, arg1 *proto0.GetApprovalsRequest, arg2 ...grpc.CallOption) (*proto0.ApprovalList, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetApprovals", varargs...)
+	ret0, _ := ret[0].(*proto0.ApprovalList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApprovals indicates an expected call of GetApprovals.
+func (mr *MockAPIClientMockRecorder) GetApprovals(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApprovals", reflect.TypeOf((*MockAPIClient)(nil).GetApprovals), varargs...)
+}
+
 // GetArtifactFile mocks base method.
 func (m *MockAPIClient) GetArtifactFile(arg0 context.Context, arg1 *proto0.GetArtifactRequest, arg2 ...grpc.CallOption) (*proto0.GetArtifactResponse, error) {
 	m.ctrl.T.Helper()
@@ -261,6 +442,86 @@ func (mr *MockAPIClientMockRecorder) GetArtifacts(arg0, arg1 interface{}, arg2 .
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArtifacts", reflect.TypeOf((*MockAPIClient)(nil).GetArtifacts), varargs...)
 }
 
+// GetAttackCoverage mocks base method.
+func (m *MockAPIClient) GetAttackCoverage(arg0 context.Context, arg1 *proto0.AttackCoverageRequest, arg2 ...grpc.CallOption) (*proto0.AttackCoverage, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetAttackCoverage", varargs...)
+	ret0, _ := ret[0].(*proto0.AttackCoverage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAttackCoverage indicates an expected call of GetAttackCoverage.
+func (mr *MockAPIClientMockRecorder) GetAttackCoverage(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAttackCoverage", reflect.TypeOf((*MockAPIClient)(nil).GetAttackCoverage), varargs...)
+}
+
+// GetAuditLog mocks base method.
+func (m *MockAPIClient) GetAuditLog(arg0 context.Context, arg1 *proto0.GetAuditLogRequest, arg2 ...grpc.CallOption) (*proto0.GetAuditLogResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetAuditLog", varargs...)
+	ret0, _ := ret[0].(*proto0.GetAuditLogResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditLog indicates an expected call of GetAuditLog.
+func (mr *MockAPIClientMockRecorder) GetAuditLog(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditLog", reflect.TypeOf((*MockAPIClient)(nil).GetAuditLog), varargs...)
+}
+
+// GetBandwidthUsage mocks base method.
+func (m *MockAPIClient) GetBandwidthUsage(arg0 context.Context, arg1 *proto0.BandwidthUsageRequest, arg2 ...grpc.CallOption) (*proto0.BandwidthUsageResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetBandwidthUsage", varargs...)
+	ret0, _ := ret[0].(*proto0.BandwidthUsageResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBandwidthUsage indicates an expected call of GetBandwidthUsage.
+func (mr *MockAPIClientMockRecorder) GetBandwidthUsage(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBandwidthUsage", reflect.TypeOf((*MockAPIClient)(nil).GetBandwidthUsage), varargs...)
+}
+
+// GetChartData mocks base method.
+func (m *MockAPIClient) GetChartData(arg0 context.Context, arg1 *proto0.GetChartDataRequest, arg2 ...grpc.CallOption) (*proto0.GetChartDataResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetChartData", varargs...)
+	ret0, _ := ret[0].(*proto0.GetChartDataResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChartData indicates an expected call of GetChartData.
+func (mr *MockAPIClientMockRecorder) GetChartData(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChartData", reflect.TypeOf((*MockAPIClient)(nil).GetChartData), varargs...)
+}
+
 // GetClient mocks base method.
 func (m *MockAPIClient) GetClient(arg0 context.Context, arg1 *proto0.GetClientRequest, arg2 ...grpc.CallOption) (*proto0.ApiClient, error) {
 	m.ctrl.T.Helper()
@@ -341,6 +602,26 @@ func (mr *MockAPIClientMockRecorder) GetClientMonitoringState(arg0, arg1 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClientMonitoringState", reflect.TypeOf((*MockAPIClient)(nil).GetClientMonitoringState), varargs...)
 }
 
+// GetComplianceReport mocks base method.
+func (m *MockAPIClient) GetComplianceReport(arg0 context.Context, arg1 *emptypb.Empty, arg2 ...grpc.CallOption) (*proto0.ComplianceReport, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetComplianceReport", varargs...)
+	ret0, _ := ret[0].(*proto0.ComplianceReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetComplianceReport indicates an expected call of GetComplianceReport.
+func (mr *MockAPIClientMockRecorder) GetComplianceReport(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetComplianceReport", reflect.TypeOf((*MockAPIClient)(nil).GetComplianceReport), varargs...)
+}
+
 // GetFlowDetails mocks base method.
 func (m *MockAPIClient) GetFlowDetails(arg0 context.Context, arg1 *proto0.ApiFlowRequest, arg2 ...grpc.CallOption) (*proto0.FlowDetails, error) {
 	m.ctrl.T.Helper()
@@ -441,6 +722,26 @@ func (mr *MockAPIClientMockRecorder) GetHuntResults(arg0, arg1 interface{}, arg2
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHuntResults", reflect.TypeOf((*MockAPIClient)(nil).GetHuntResults), varargs...)
 }
 
+// GetJob mocks base method.
+func (m *MockAPIClient) GetJob(arg0 context.Context, arg1 *proto0.GetJobRequest, arg2 ...grpc.CallOption) (*proto0.Job, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetJob", varargs...)
+	ret0, _ := ret[0].(*proto0.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJob indicates an expected call of GetJob.
+func (mr *MockAPIClientMockRecorder) GetJob(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJob", reflect.TypeOf((*MockAPIClient)(nil).GetJob), varargs...)
+}
+
 // GetKeywordCompletions mocks base method.
 func (m *MockAPIClient) GetKeywordCompletions(arg0 context.Context, arg1 *emptypb.Empty, arg2 ...grpc.CallOption) (*proto0.KeywordCompletions, error) {
 	m.ctrl.T.Helper()
@@ -461,6 +762,46 @@ func (mr *MockAPIClientMockRecorder) GetKeywordCompletions(arg0, arg1 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeywordCompletions", reflect.TypeOf((*MockAPIClient)(nil).GetKeywordCompletions), varargs...)
 }
 
+// GetLDAPMappings mocks base method.
+func (m *MockAPIClient) GetLDAPMappings(arg0 context.Context, arg1 *emptypb.Empty, arg2 ...grpc.CallOption) (*proto0.LDAPGroupMappings, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetLDAPMappings", varargs...)
+	ret0, _ := ret[0].(*proto0.LDAPGroupMappings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLDAPMappings indicates an expected call of GetLDAPMappings.
+func (mr *MockAPIClientMockRecorder) GetLDAPMappings(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLDAPMappings", reflect.TypeOf((*MockAPIClient)(nil).GetLDAPMappings), varargs...)
+}
+
+// GetLegalHolds mocks base method.
+func (m *MockAPIClient) GetLegalHolds(arg0 context.Context, arg1 *proto0.GetLegalHoldsRequest, arg2 ...grpc.CallOption) (*proto0.LegalHolds, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetLegalHolds", varargs...)
+	ret0, _ := ret[0].(*proto0.LegalHolds)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLegalHolds indicates an expected call of GetLegalHolds.
+func (mr *MockAPIClientMockRecorder) GetLegalHolds(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLegalHolds", reflect.TypeOf((*MockAPIClient)(nil).GetLegalHolds), varargs...)
+}
+
 // GetNotebookCell mocks base method.
 func (m *MockAPIClient) GetNotebookCell(arg0 context.Context, arg1 *proto0.NotebookCellRequest, arg2 ...grpc.CallOption) (*proto0.NotebookCell, error) {
 	m.ctrl.T.Helper()
@@ -521,6 +862,26 @@ func (mr *MockAPIClientMockRecorder) GetReport(arg0, arg1 interface{}, arg2 ...i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReport", reflect.TypeOf((*MockAPIClient)(nil).GetReport), varargs...)
 }
 
+// GetRoles mocks base method.
+func (m *MockAPIClient) GetRoles(arg0 context.Context, arg1 *emptypb.Empty, arg2 ...grpc.CallOption) (*proto0.Roles, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetRoles", varargs...)
+	ret0, _ := ret[0].(*proto0.Roles)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoles indicates an expected call of GetRoles.
+func (mr *MockAPIClientMockRecorder) GetRoles(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoles", reflect.TypeOf((*MockAPIClient)(nil).GetRoles), varargs...)
+}
+
 // GetServerMonitoringState mocks base method.
 func (m *MockAPIClient) GetServerMonitoringState(arg0 context.Context, arg1 *emptypb.Empty, arg2 ...grpc.CallOption) (*proto2.ArtifactCollectorArgs, error) {
 	m.ctrl.T.Helper()
@@ -661,51 +1022,151 @@ func (mr *MockAPIClientMockRecorder) GetUsers(arg0, arg1 interface{}, arg2 ...in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockAPIClient)(nil).GetUsers), varargs...)
 }
 
-// LabelClients mocks base method.
-func (m *MockAPIClient) LabelClients(arg0 context.Context, arg1 *proto0.LabelClientsRequest, arg2 ...grpc.CallOption) (*proto0.APIResponse, error) {
+// GrantApproval mocks base method.
+func (m *MockAPIClient) GrantApproval(arg0 context.Context, arg1 *proto0.Approval, arg2 ...grpc.CallOption) (*proto0.Approval, error) {
 	m.ctrl.T.Helper()
 	varargs := []interface{}{arg0, arg1}
 	for _, a := range arg2 {
 		varargs = append(varargs, a)
 	}
-	ret := m.ctrl.Call(m, "LabelClients", varargs...)
-	ret0, _ := ret[0].(*proto0.APIResponse)
+	ret := m.ctrl.Call(m, "GrantApproval", varargs...)
+	ret0, _ := ret[0].(*proto0.Approval)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// LabelClients indicates an expected call of LabelClients.
-func (mr *MockAPIClientMockRecorder) LabelClients(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+// GrantApproval indicates an expected call of GrantApproval.
+func (mr *MockAPIClientMockRecorder) GrantApproval(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	varargs := append([]interface{}{arg0, arg1}, arg2...)
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LabelClients", reflect.TypeOf((*MockAPIClient)(nil).LabelClients), varargs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantApproval", reflect.TypeOf((*MockAPIClient)(nil).GrantApproval), varargs...)
 }
 
-// ListAvailableEventResults mocks base method.
-func (m *MockAPIClient) ListAvailableEventResults(arg0 context.Context, arg1 *proto0.ListAvailableEventResultsRequest, arg2 ...grpc.CallOption) (*proto0.ListAvailableEventResultsResponse, error) {
+// ImportCollection mocks base method.
+func (m *MockAPIClient) ImportCollection(arg0 context.Context, arg1 *proto0.ImportCollectionRequest, arg2 ...grpc.CallOption) (*proto0.FlowDetails, error) {
 	m.ctrl.T.Helper()
 	varargs := []interface{}{arg0, arg1}
 	for _, a := range arg2 {
 		varargs = append(varargs, a)
 	}
-	ret := m.ctrl.Call(m, "ListAvailableEventResults", varargs...)
-	ret0, _ := ret[0].(*proto0.ListAvailableEventResultsResponse)
+	ret := m.ctrl.Call(m, "ImportCollection", varargs...)
+	ret0, _ := ret[0].(*proto0.FlowDetails)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListAvailableEventResults indicates an expected call of ListAvailableEventResults.
-func (mr *MockAPIClientMockRecorder) ListAvailableEventResults(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+// ImportCollection indicates an expected call of ImportCollection.
+func (mr *MockAPIClientMockRecorder) ImportCollection(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	varargs := append([]interface{}{arg0, arg1}, arg2...)
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAvailableEventResults", reflect.TypeOf((*MockAPIClient)(nil).ListAvailableEventResults), varargs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportCollection", reflect.TypeOf((*MockAPIClient)(nil).ImportCollection), varargs...)
 }
 
-// ListChildren mocks base method.
-func (m *MockAPIClient) ListChildren(arg0 context.Context, arg1 *proto0.DataRequest, arg2 ...grpc.CallOption) (*proto0.ListChildrenResponse, error) {
+// IngestRows mocks base method.
+func (m *MockAPIClient) IngestRows(arg0 context.Context, arg1 ...grpc.CallOption) (proto0.API_IngestRowsClient, error) {
 	m.ctrl.T.Helper()
-	varargs := []interface{}{arg0, arg1}
-	for _, a := range arg2 {
+	varargs := []interface{}{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "IngestRows", varargs...)
+	ret0, _ := ret[0].(proto0.API_IngestRowsClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IngestRows indicates an expected call of IngestRows.
+func (mr *MockAPIClientMockRecorder) IngestRows(arg0 interface{}, arg1 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IngestRows", reflect.TypeOf((*MockAPIClient)(nil).IngestRows), varargs...)
+}
+
+// InstallExchangeArtifact mocks base method.
+func (m *MockAPIClient) InstallExchangeArtifact(arg0 context.Context, arg1 *proto0.InstallExchangeArtifactRequest, arg2 ...grpc.CallOption) (*proto0.ExchangeArtifact, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "InstallExchangeArtifact", varargs...)
+	ret0, _ := ret[0].(*proto0.ExchangeArtifact)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InstallExchangeArtifact indicates an expected call of InstallExchangeArtifact.
+func (mr *MockAPIClientMockRecorder) InstallExchangeArtifact(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallExchangeArtifact", reflect.TypeOf((*MockAPIClient)(nil).InstallExchangeArtifact), varargs...)
+}
+
+// LabelClients mocks base method.
+func (m *MockAPIClient) LabelClients(arg0 context.Context, arg1 *proto0.LabelClientsRequest, arg2 ...grpc.CallOption) (*proto0.APIResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "LabelClients", varargs...)
+	ret0, _ := ret[0].(*proto0.APIResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LabelClients indicates an expected call of LabelClients.
+func (mr *MockAPIClientMockRecorder) LabelClients(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LabelClients", reflect.TypeOf((*MockAPIClient)(nil).LabelClients), varargs...)
+}
+
+// ListApiKeys mocks base method.
+func (m *MockAPIClient) ListApiKeys(arg0 context.Context, arg1 *proto0.ListApiKeysRequest, arg2 ...grpc.CallOption) (*proto0.ApiKeys, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListApiKeys", varargs...)
+	ret0, _ := ret[0].(*proto0.ApiKeys)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListApiKeys indicates an expected call of ListApiKeys.
+func (mr *MockAPIClientMockRecorder) ListApiKeys(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListApiKeys", reflect.TypeOf((*MockAPIClient)(nil).ListApiKeys), varargs...)
+}
+
+// ListAvailableEventResults mocks base method.
+func (m *MockAPIClient) ListAvailableEventResults(arg0 context.Context, arg1 *proto0.ListAvailableEventResultsRequest, arg2 ...grpc.CallOption) (*proto0.ListAvailableEventResultsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListAvailableEventResults", varargs...)
+	ret0, _ := ret[0].(*proto0.ListAvailableEventResultsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAvailableEventResults indicates an expected call of ListAvailableEventResults.
+func (mr *MockAPIClientMockRecorder) ListAvailableEventResults(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAvailableEventResults", reflect.TypeOf((*MockAPIClient)(nil).ListAvailableEventResults), varargs...)
+}
+
+// ListChildren mocks base method.
+func (m *MockAPIClient) ListChildren(arg0 context.Context, arg1 *proto0.DataRequest, arg2 ...grpc.CallOption) (*proto0.ListChildrenResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
 		varargs = append(varargs, a)
 	}
 	ret := m.ctrl.Call(m, "ListChildren", varargs...)
@@ -741,6 +1202,26 @@ func (mr *MockAPIClientMockRecorder) ListClients(arg0, arg1 interface{}, arg2 ..
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListClients", reflect.TypeOf((*MockAPIClient)(nil).ListClients), varargs...)
 }
 
+// ListExchangeArtifacts mocks base method.
+func (m *MockAPIClient) ListExchangeArtifacts(arg0 context.Context, arg1 *proto0.ExchangeArtifactsRequest, arg2 ...grpc.CallOption) (*proto0.ExchangeArtifacts, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListExchangeArtifacts", varargs...)
+	ret0, _ := ret[0].(*proto0.ExchangeArtifacts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExchangeArtifacts indicates an expected call of ListExchangeArtifacts.
+func (mr *MockAPIClientMockRecorder) ListExchangeArtifacts(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExchangeArtifacts", reflect.TypeOf((*MockAPIClient)(nil).ListExchangeArtifacts), varargs...)
+}
+
 // ListHunts mocks base method.
 func (m *MockAPIClient) ListHunts(arg0 context.Context, arg1 *proto0.ListHuntsRequest, arg2 ...grpc.CallOption) (*proto0.ListHuntsResponse, error) {
 	m.ctrl.T.Helper()
@@ -761,6 +1242,26 @@ func (mr *MockAPIClientMockRecorder) ListHunts(arg0, arg1 interface{}, arg2 ...i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHunts", reflect.TypeOf((*MockAPIClient)(nil).ListHunts), varargs...)
 }
 
+// ListJobs mocks base method.
+func (m *MockAPIClient) ListJobs(arg0 context.Context, arg1 *proto0.ListJobsRequest, arg2 ...grpc.CallOption) (*proto0.ListJobsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListJobs", varargs...)
+	ret0, _ := ret[0].(*proto0.ListJobsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJobs indicates an expected call of ListJobs.
+func (mr *MockAPIClientMockRecorder) ListJobs(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobs", reflect.TypeOf((*MockAPIClient)(nil).ListJobs), varargs...)
+}
+
 // LoadArtifactPack mocks base method.
 func (m *MockAPIClient) LoadArtifactPack(arg0 context.Context, arg1 *proto0.VFSFileBuffer, arg2 ...grpc.CallOption) (*proto0.LoadArtifactPackResponse, error) {
 	m.ctrl.T.Helper()
@@ -881,6 +1382,26 @@ func (mr *MockAPIClientMockRecorder) PushEvents(arg0, arg1 interface{}, arg2 ...
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PushEvents", reflect.TypeOf((*MockAPIClient)(nil).PushEvents), varargs...)
 }
 
+// QuarantineClient mocks base method.
+func (m *MockAPIClient) QuarantineClient(arg0 context.Context, arg1 *proto0.QuarantineClientRequest, arg2 ...grpc.CallOption) (*proto2.ArtifactCollectorResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "QuarantineClient", varargs...)
+	ret0, _ := ret[0].(*proto2.ArtifactCollectorResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QuarantineClient indicates an expected call of QuarantineClient.
+func (mr *MockAPIClientMockRecorder) QuarantineClient(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuarantineClient", reflect.TypeOf((*MockAPIClient)(nil).QuarantineClient), varargs...)
+}
+
 // Query mocks base method.
 func (m *MockAPIClient) Query(arg0 context.Context, arg1 *proto.VQLCollectorArgs, arg2 ...grpc.CallOption) (proto0.API_QueryClient, error) {
 	m.ctrl.T.Helper()
@@ -901,6 +1422,86 @@ func (mr *MockAPIClientMockRecorder) Query(arg0, arg1 interface{}, arg2 ...inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockAPIClient)(nil).Query), varargs...)
 }
 
+// RehydrateFlow mocks base method.
+func (m *MockAPIClient) RehydrateFlow(arg0 context.Context, arg1 *proto0.ApiFlowRequest, arg2 ...grpc.CallOption) (*proto0.FlowDetails, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RehydrateFlow", varargs...)
+	ret0, _ := ret[0].(*proto0.FlowDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RehydrateFlow indicates an expected call of RehydrateFlow.
+func (mr *MockAPIClientMockRecorder) RehydrateFlow(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RehydrateFlow", reflect.TypeOf((*MockAPIClient)(nil).RehydrateFlow), varargs...)
+}
+
+// RequestApproval mocks base method.
+func (m *MockAPIClient) RequestApproval(arg0 context.Context, arg1 *proto0.Approval, arg2 ...grpc.CallOption) (*proto0.Approval, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RequestApproval", varargs...)
+	ret0, _ := ret[0].(*proto0.Approval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestApproval indicates an expected call of RequestApproval.
+func (mr *MockAPIClientMockRecorder) RequestApproval(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestApproval", reflect.TypeOf((*MockAPIClient)(nil).RequestApproval), varargs...)
+}
+
+// RevokeApiKey mocks base method.
+func (m *MockAPIClient) RevokeApiKey(arg0 context.Context, arg1 *proto0.RevokeApiKeyRequest, arg2 ...grpc.CallOption) (*emptypb.Empty, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RevokeApiKey", varargs...)
+	ret0, _ := ret[0].(*emptypb.Empty)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeApiKey indicates an expected call of RevokeApiKey.
+func (mr *MockAPIClientMockRecorder) RevokeApiKey(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeApiKey", reflect.TypeOf((*MockAPIClient)(nil).RevokeApiKey), varargs...)
+}
+
+// SearchFiles mocks base method.
+func (m *MockAPIClient) SearchFiles(arg0 context.Context, arg1 *proto0.SearchFilesRequest, arg2 ...grpc.CallOption) (*proto0.SearchFilesResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SearchFiles", varargs...)
+	ret0, _ := ret[0].(*proto0.SearchFilesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchFiles indicates an expected call of SearchFiles.
+func (mr *MockAPIClientMockRecorder) SearchFiles(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchFiles", reflect.TypeOf((*MockAPIClient)(nil).SearchFiles), varargs...)
+}
+
 // SetArtifactFile mocks base method.
 func (m *MockAPIClient) SetArtifactFile(arg0 context.Context, arg1 *proto0.SetArtifactRequest, arg2 ...grpc.CallOption) (*proto0.APIResponse, error) {
 	m.ctrl.T.Helper()
@@ -981,6 +1582,66 @@ func (mr *MockAPIClientMockRecorder) SetGUIOptions(arg0, arg1 interface{}, arg2
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetGUIOptions", reflect.TypeOf((*MockAPIClient)(nil).SetGUIOptions), varargs...)
 }
 
+// SetLDAPMappings mocks base method.
+func (m *MockAPIClient) SetLDAPMappings(arg0 context.Context, arg1 *proto0.LDAPGroupMappings, arg2 ...grpc.CallOption) (*proto0.LDAPGroupMappings, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SetLDAPMappings", varargs...)
+	ret0, _ := ret[0].(*proto0.LDAPGroupMappings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetLDAPMappings indicates an expected call of SetLDAPMappings.
+func (mr *MockAPIClientMockRecorder) SetLDAPMappings(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLDAPMappings", reflect.TypeOf((*MockAPIClient)(nil).SetLDAPMappings), varargs...)
+}
+
+// SetLegalHold mocks base method.
+func (m *MockAPIClient) SetLegalHold(arg0 context.Context, arg1 *proto0.LegalHoldRequest, arg2 ...grpc.CallOption) (*proto0.LegalHold, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SetLegalHold", varargs...)
+	ret0, _ := ret[0].(*proto0.LegalHold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetLegalHold indicates an expected call of SetLegalHold.
+func (mr *MockAPIClientMockRecorder) SetLegalHold(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLegalHold", reflect.TypeOf((*MockAPIClient)(nil).SetLegalHold), varargs...)
+}
+
+// SetRole mocks base method.
+func (m *MockAPIClient) SetRole(arg0 context.Context, arg1 *proto3.Role, arg2 ...grpc.CallOption) (*emptypb.Empty, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SetRole", varargs...)
+	ret0, _ := ret[0].(*emptypb.Empty)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetRole indicates an expected call of SetRole.
+func (mr *MockAPIClientMockRecorder) SetRole(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRole", reflect.TypeOf((*MockAPIClient)(nil).SetRole), varargs...)
+}
+
 // SetServerMonitoringState mocks base method.
 func (m *MockAPIClient) SetServerMonitoringState(arg0 context.Context, arg1 *proto2.ArtifactCollectorArgs, arg2 ...grpc.CallOption) (*proto2.ArtifactCollectorArgs, error) {
 	m.ctrl.T.Helper()
@@ -1041,6 +1702,86 @@ func (mr *MockAPIClientMockRecorder) SetToolInfo(arg0, arg1 interface{}, arg2 ..
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetToolInfo", reflect.TypeOf((*MockAPIClient)(nil).SetToolInfo), varargs...)
 }
 
+// StreamTable mocks base method.
+func (m *MockAPIClient) StreamTable(arg0 context.Context, arg1 *proto0.GetTableRequest, arg2 ...grpc.CallOption) (proto0.API_StreamTableClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "StreamTable", varargs...)
+	ret0, _ := ret[0].(proto0.API_StreamTableClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StreamTable indicates an expected call of StreamTable.
+func (mr *MockAPIClientMockRecorder) StreamTable(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamTable", reflect.TypeOf((*MockAPIClient)(nil).StreamTable), varargs...)
+}
+
+// TranslateSigma mocks base method.
+func (m *MockAPIClient) TranslateSigma(arg0 context.Context, arg1 *proto0.SigmaTranslationRequest, arg2 ...grpc.CallOption) (*proto0.SigmaTranslationResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "TranslateSigma", varargs...)
+	ret0, _ := ret[0].(*proto0.SigmaTranslationResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TranslateSigma indicates an expected call of TranslateSigma.
+func (mr *MockAPIClientMockRecorder) TranslateSigma(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TranslateSigma", reflect.TypeOf((*MockAPIClient)(nil).TranslateSigma), varargs...)
+}
+
+// UninstallClient mocks base method.
+func (m *MockAPIClient) UninstallClient(arg0 context.Context, arg1 *proto0.UninstallClientRequest, arg2 ...grpc.CallOption) (*proto2.ArtifactCollectorResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UninstallClient", varargs...)
+	ret0, _ := ret[0].(*proto2.ArtifactCollectorResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UninstallClient indicates an expected call of UninstallClient.
+func (mr *MockAPIClientMockRecorder) UninstallClient(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UninstallClient", reflect.TypeOf((*MockAPIClient)(nil).UninstallClient), varargs...)
+}
+
+// UnquarantineClient mocks base method.
+func (m *MockAPIClient) UnquarantineClient(arg0 context.Context, arg1 *proto0.UnquarantineClientRequest, arg2 ...grpc.CallOption) (*proto2.ArtifactCollectorResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UnquarantineClient", varargs...)
+	ret0, _ := ret[0].(*proto2.ArtifactCollectorResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnquarantineClient indicates an expected call of UnquarantineClient.
+func (mr *MockAPIClientMockRecorder) UnquarantineClient(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnquarantineClient", reflect.TypeOf((*MockAPIClient)(nil).UnquarantineClient), varargs...)
+}
+
 // UpdateNotebook mocks base method.
 func (m *MockAPIClient) UpdateNotebook(arg0 context.Context, arg1 *proto0.NotebookMetadata, arg2 ...grpc.CallOption) (*proto0.NotebookMetadata, error) {
 	m.ctrl.T.Helper()
@@ -1201,6 +1942,66 @@ func (mr *MockAPIClientMockRecorder) VFSStatDownload(arg0, arg1 interface{}, arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VFSStatDownload", reflect.TypeOf((*MockAPIClient)(nil).VFSStatDownload), varargs...)
 }
 
+// VQLComplete mocks base method.
+func (m *MockAPIClient) VQLComplete(arg0 context.Context, arg1 *proto0.VQLLanguageRequest, arg2 ...grpc.CallOption) (*proto0.KeywordCompletions, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "VQLComplete", varargs...)
+	ret0, _ := ret[0].(*proto0.KeywordCompletions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VQLComplete indicates an expected call of VQLComplete.
+func (mr *MockAPIClientMockRecorder) VQLComplete(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VQLComplete", reflect.TypeOf((*MockAPIClient)(nil).VQLComplete), varargs...)
+}
+
+// VQLDiagnose mocks base method.
+func (m *MockAPIClient) VQLDiagnose(arg0 context.Context, arg1 *proto0.VQLLanguageRequest, arg2 ...grpc.CallOption) (*proto0.VQLDiagnostics, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "VQLDiagnose", varargs...)
+	ret0, _ := ret[0].(*proto0.VQLDiagnostics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VQLDiagnose indicates an expected call of VQLDiagnose.
+func (mr *MockAPIClientMockRecorder) VQLDiagnose(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VQLDiagnose", reflect.TypeOf((*MockAPIClient)(nil).VQLDiagnose), varargs...)
+}
+
+// VQLHover mocks base method.
+func (m *MockAPIClient) VQLHover(arg0 context.Context, arg1 *proto0.VQLLanguageRequest, arg2 ...grpc.CallOption) (*proto0.Completion, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "VQLHover", varargs...)
+	ret0, _ := ret[0].(*proto0.Completion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VQLHover indicates an expected call of VQLHover.
+func (mr *MockAPIClientMockRecorder) VQLHover(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VQLHover", reflect.TypeOf((*MockAPIClient)(nil).VQLHover), varargs...)
+}
+
 // WatchEvent mocks base method.
 func (m *MockAPIClient) WatchEvent(arg0 context.Context, arg1 *proto0.EventRequest, arg2 ...grpc.CallOption) (proto0.API_WatchEventClient, error) {
 	m.ctrl.T.Helper()
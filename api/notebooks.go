@@ -21,6 +21,7 @@ import (
 	"www.velocidex.com/golang/velociraptor/paths"
 	"www.velocidex.com/golang/velociraptor/reporting"
 	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/utils"
 )
 
 // Get all the current user's notebooks and those notebooks shared
@@ -69,7 +70,7 @@ func (self *ApiServer) GetNotebooks(
 		}
 
 		// Document not owned or collaborated with.
-		if !notebook_manager.CheckNotebookAccess(notebook_metadata,
+		if !notebook_manager.CheckNotebookReadAccess(notebook_metadata,
 			user_record.Name) {
 			logging.GetLogger(
 				org_config_obj, &logging.Audit).WithFields(
@@ -86,13 +87,21 @@ func (self *ApiServer) GetNotebooks(
 		return result, nil
 	}
 
+	page, err := newPager(in.PageToken, in.Offset, in.Count,
+		DEFAULT_PAGE_SIZE, MAX_PAGE_SIZE, "GetNotebooks")
+	if err != nil {
+		return nil, err
+	}
+
 	notebooks, err := notebook_manager.GetSharedNotebooks(ctx,
-		user_record.Name, in.Offset, in.Count)
+		user_record.Name, page.Offset, page.FetchSize())
 	if err != nil {
 		return nil, err
 	}
 
-	result.Items = notebooks
+	length, token := page.Page(len(notebooks))
+	result.Items = notebooks[:length]
+	result.NextPageToken = token
 	return result, nil
 }
 
@@ -120,7 +129,15 @@ func (self *ApiServer) NewNotebook(
 		return nil, err
 	}
 
-	return notebook_manager.NewNotebook(ctx, user_record.Name, in)
+	notebook, err := notebook_manager.NewNotebook(ctx, user_record.Name, in)
+	if err != nil {
+		return nil, err
+	}
+
+	logNotebookActivity(ctx, org_config_obj, notebook.NotebookId,
+		user_record.Name, "", "CreateNotebook")
+
+	return notebook, nil
 }
 
 func (self *ApiServer) NewNotebookCell(
@@ -151,7 +168,22 @@ func (self *ApiServer) NewNotebookCell(
 	if err != nil {
 		return nil, err
 	}
-	return notebook_manager.NewNotebookCell(ctx, in, user_record.Name)
+
+	err = checkNotebookEditAccess(ctx, notebook_manager,
+		in.NotebookId, user_record.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	notebook, err := notebook_manager.NewNotebookCell(ctx, in, user_record.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	logNotebookActivity(ctx, org_config_obj, in.NotebookId,
+		user_record.Name, notebook.LatestCellId, "NewCell")
+
+	return notebook, nil
 }
 
 func (self *ApiServer) UpdateNotebook(
@@ -197,6 +229,17 @@ func (self *ApiServer) UpdateNotebook(
 		return nil, errors.New("Edit clash detected.")
 	}
 
+	// Only the creator may change who the notebook is shared with.
+	sharing_changed := notebookSharingChanged(old_notebook, in)
+	if sharing_changed && old_notebook.Creator != user_record.Name {
+		perm, err := acls.CheckAccess(
+			org_config_obj, user_record.Name, acls.SERVER_ADMIN)
+		if !perm || err != nil {
+			return nil, status.Error(codes.PermissionDenied,
+				"Only the notebook creator may change sharing.")
+		}
+	}
+
 	// When updating an existing notebook only certain fields may
 	// be changed by the user - definitely not the creator, created time or notebookId.
 	in.ModifiedTime = time.Now().Unix()
@@ -214,7 +257,19 @@ func (self *ApiServer) UpdateNotebook(
 	}
 	in.CellMetadata = cell_metadata
 
-	return in, notebook_manager.UpdateNotebook(ctx, in)
+	err = notebook_manager.UpdateNotebook(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	action := "UpdateNotebook"
+	if sharing_changed {
+		action = "UpdateSharing"
+	}
+	logNotebookActivity(ctx, org_config_obj, in.NotebookId,
+		user_record.Name, "", action)
+
+	return in, nil
 }
 
 func (self *ApiServer) GetNotebookCell(
@@ -254,7 +309,7 @@ func (self *ApiServer) GetNotebookCell(
 		return nil, err
 	}
 
-	if !notebook_manager.CheckNotebookAccess(notebook_metadata, user_record.Name) {
+	if !notebook_manager.CheckNotebookReadAccess(notebook_metadata, user_record.Name) {
 		return nil, errors.New("Notebook is not shared with user.")
 	}
 
@@ -303,6 +358,9 @@ func (self *ApiServer) UpdateNotebookCell(
 		return nil, errors.New("Notebook is not shared with user.")
 	}
 
+	logNotebookActivity(ctx, org_config_obj, in.NotebookId,
+		user_record.Name, in.CellId, "UpdateCell")
+
 	return notebook_manager.UpdateNotebookCell(
 		ctx, notebook_metadata, user_record.Name, in)
 }
@@ -339,6 +397,15 @@ func (self *ApiServer) CancelNotebookCell(
 		return nil, err
 	}
 
+	err = checkNotebookEditAccess(ctx, notebook_manager,
+		in.NotebookId, user_record.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	logNotebookActivity(ctx, org_config_obj, in.NotebookId,
+		user_record.Name, in.CellId, "CancelCell")
+
 	return &emptypb.Empty{}, notebook_manager.CancelNotebookCell(
 		ctx, in.NotebookId, in.CellId)
 }
@@ -366,9 +433,56 @@ func (self *ApiServer) UploadNotebookAttachment(
 	if err != nil {
 		return nil, err
 	}
+
+	err = checkNotebookEditAccess(ctx, notebook_manager,
+		in.NotebookId, user_record.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	logNotebookActivity(ctx, org_config_obj, in.NotebookId,
+		user_record.Name, "", "UploadAttachment")
+
 	return notebook_manager.UploadNotebookAttachment(ctx, in)
 }
 
+func checkNotebookEditAccess(
+	ctx context.Context, notebook_manager services.NotebookManager,
+	notebook_id, user_name string) error {
+	notebook_metadata, err := notebook_manager.GetNotebook(ctx, notebook_id)
+	if err != nil {
+		return err
+	}
+
+	if !notebook_manager.CheckNotebookAccess(notebook_metadata, user_name) {
+		return errors.New("Notebook is not shared with user.")
+	}
+	return nil
+}
+
+func notebookSharingChanged(
+	old_notebook, new_notebook *api_proto.NotebookMetadata) bool {
+	return old_notebook.Public != new_notebook.Public ||
+		old_notebook.PublicRead != new_notebook.PublicRead ||
+		!utils.SlicesEqual(old_notebook.Collaborators,
+			new_notebook.Collaborators)
+}
+
+// Failing to write the activity log should not fail the edit.
+func logNotebookActivity(
+	ctx context.Context, config_obj *config_proto.Config,
+	notebook_id, user_name, cell_id, action string) {
+	notebook_manager, err := services.GetNotebookManager(config_obj)
+	if err == nil {
+		err = notebook_manager.LogNotebookActivity(
+			ctx, notebook_id, user_name, cell_id, action)
+	}
+	if err != nil {
+		logging.GetLogger(config_obj, &logging.FrontendComponent).
+			Error("Unable to log notebook activity: %v", err)
+	}
+}
+
 func (self *ApiServer) CreateNotebookDownloadFile(
 	ctx context.Context,
 	in *api_proto.NotebookExportRequest) (*emptypb.Empty, error) {
@@ -418,7 +532,7 @@ func exportZipNotebook(
 	if err != nil {
 		return err
 	}
-	if !notebook_manager.CheckNotebookAccess(notebook, principal) {
+	if !notebook_manager.CheckNotebookReadAccess(notebook, principal) {
 		return errors.New("Notebook is not shared with user.")
 	}
 
@@ -476,7 +590,7 @@ func exportHTMLNotebook(config_obj *config_proto.Config,
 	if err != nil {
 		return err
 	}
-	if !notebook_manager.CheckNotebookAccess(notebook, principal) {
+	if !notebook_manager.CheckNotebookReadAccess(notebook, principal) {
 		return errors.New("Notebook is not shared with user.")
 	}
 
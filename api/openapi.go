@@ -0,0 +1,439 @@
+package api
+
+// Produce an OpenAPI v3 document for the REST API from the
+// google.api.http annotations in api.proto. The document is built
+// from the compiled descriptors so it always matches the gateway
+// routes.
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/Velocidex/ordereddict"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/json"
+)
+
+const (
+	OPENAPI_VERSION = "3.0.3"
+
+	// Nested messages are exposed as query parameters with dotted
+	// names up to this depth.
+	MAX_QUERY_PARAMETER_DEPTH = 3
+)
+
+var (
+	pathParameterRegex = regexp.MustCompile(`\{([^}=]+)(=[^}]*)?\}`)
+)
+
+type openAPIGenerator struct {
+	// Rewrites the gateway's /api/v1/ routes for other versions.
+	prefix string
+
+	schemas *ordereddict.Dict
+
+	// Messages which are already in (or being added to) schemas.
+	seen map[string]bool
+}
+
+type openAPIRoute struct {
+	method string
+	path   string
+	body   string
+}
+
+// Build the document for one API version. Paths are relative to the
+// GUI's base path.
+func generateOpenAPI(
+	config_obj *config_proto.Config,
+	version *api_proto.ApiVersion) *ordereddict.Dict {
+	self := &openAPIGenerator{
+		prefix:  version.Prefix,
+		schemas: ordereddict.NewDict(),
+		seen:    make(map[string]bool),
+	}
+
+	base := ""
+	if config_obj.GUI != nil {
+		base = config_obj.GUI.BasePath
+	}
+
+	service := api_proto.File_api_proto.Services().ByName("API")
+	paths := ordereddict.NewDict()
+	methods := service.Methods()
+	for i := 0; i < methods.Len(); i++ {
+		method := methods.Get(i)
+		for _, route := range getRoutes(method) {
+			path := self.prefix + strings.TrimPrefix(route.path, "/api/v1/")
+			path = pathParameterRegex.ReplaceAllString(path, "{$1}")
+
+			item, pres := paths.Get(path)
+			if !pres {
+				item = ordereddict.NewDict()
+				paths.Set(path, item)
+			}
+			item.(*ordereddict.Dict).Set(strings.ToLower(route.method),
+				self.operation(method, route))
+		}
+	}
+
+	security_schemes := ordereddict.NewDict().
+		Set("bearerAuth", ordereddict.NewDict().
+			Set("type", "http").
+			Set("scheme", "bearer").
+			Set("description", "An API key issued by CreateApiKey."))
+	security := []*ordereddict.Dict{
+		ordereddict.NewDict().Set("bearerAuth", []string{}),
+	}
+
+	if config_obj.GUI != nil && config_obj.GUI.Authenticator != nil &&
+		strings.ToLower(config_obj.GUI.Authenticator.Type) == "basic" {
+		security_schemes.Set("basicAuth", ordereddict.NewDict().
+			Set("type", "http").
+			Set("scheme", "basic"))
+		security = append(security,
+			ordereddict.NewDict().Set("basicAuth", []string{}))
+	}
+
+	return ordereddict.NewDict().
+		Set("openapi", OPENAPI_VERSION).
+		Set("info", ordereddict.NewDict().
+			Set("title", "Velociraptor API").
+			Set("version", version.Version).
+			Set("description", "Generated from the server's gRPC gateway. "+
+				"Select an API version with the "+API_VERSION_HEADER+
+				" header or the path prefix.")).
+		Set("servers", []*ordereddict.Dict{
+			ordereddict.NewDict().Set("url", base),
+		}).
+		Set("paths", paths).
+		Set("components", ordereddict.NewDict().
+			Set("schemas", self.schemas).
+			Set("securitySchemes", security_schemes)).
+		Set("security", security)
+}
+
+func getRoutes(method protoreflect.MethodDescriptor) []openAPIRoute {
+	rule, ok := proto.GetExtension(
+		method.Options(), annotations.E_Http).(*annotations.HttpRule)
+	if !ok || rule == nil {
+		return nil
+	}
+
+	result := []openAPIRoute{}
+	for _, r := range append([]*annotations.HttpRule{rule},
+		rule.AdditionalBindings...) {
+		route := openAPIRoute{body: r.Body}
+		switch pattern := r.Pattern.(type) {
+		case *annotations.HttpRule_Get:
+			route.method, route.path = "GET", pattern.Get
+		case *annotations.HttpRule_Post:
+			route.method, route.path = "POST", pattern.Post
+		case *annotations.HttpRule_Put:
+			route.method, route.path = "PUT", pattern.Put
+		case *annotations.HttpRule_Delete:
+			route.method, route.path = "DELETE", pattern.Delete
+		case *annotations.HttpRule_Patch:
+			route.method, route.path = "PATCH", pattern.Patch
+		case *annotations.HttpRule_Custom:
+			route.method = strings.ToUpper(pattern.Custom.Kind)
+			route.path = pattern.Custom.Path
+		default:
+			continue
+		}
+		result = append(result, route)
+	}
+	return result
+}
+
+func (self *openAPIGenerator) operation(
+	method protoreflect.MethodDescriptor,
+	route openAPIRoute) *ordereddict.Dict {
+	name := string(method.Name())
+	input := method.Input()
+
+	operation_id := name
+	if route.method == "HEAD" {
+		operation_id += "Head"
+	}
+
+	result := ordereddict.NewDict().
+		Set("operationId", operation_id).
+		Set("tags", []string{"API"})
+
+	// Fields bound to the path are not query parameters.
+	bound := make(map[string]bool)
+	parameters := []*ordereddict.Dict{}
+	for _, match := range pathParameterRegex.FindAllStringSubmatch(route.path, -1) {
+		bound[match[1]] = true
+		parameters = append(parameters, ordereddict.NewDict().
+			Set("name", match[1]).
+			Set("in", "path").
+			Set("required", true).
+			Set("schema", self.fieldPathSchema(input, match[1])))
+	}
+
+	switch route.body {
+	case "*":
+		result.Set("requestBody", self.requestBody(input))
+
+	default:
+		// The gateway fills the remaining fields from the query
+		// string.
+		if route.body != "" {
+			field := input.Fields().ByName(protoreflect.Name(route.body))
+			if field != nil {
+				bound[route.body] = true
+				result.Set("requestBody", ordereddict.NewDict().
+					Set("content", ordereddict.NewDict().
+						Set("application/json", ordereddict.NewDict().
+							Set("schema", self.fieldSchema(field)))))
+			}
+		}
+		parameters = append(parameters,
+			self.queryParameters(name, input, "", bound, 0)...)
+	}
+
+	if len(parameters) > 0 {
+		result.Set("parameters", parameters)
+	}
+
+	response_description := "A successful response."
+	if method.IsStreamingServer() {
+		response_description = "A stream of newline delimited results."
+	}
+
+	return result.Set("responses", ordereddict.NewDict().
+		Set("200", ordereddict.NewDict().
+			Set("description", response_description).
+			Set("content", ordereddict.NewDict().
+				Set("application/json", ordereddict.NewDict().
+					Set("schema", self.messageSchema(method.Output()))))).
+		Set("default", ordereddict.NewDict().
+			Set("description", "An error response.").
+			Set("content", ordereddict.NewDict().
+				Set("application/json", ordereddict.NewDict().
+					Set("schema", self.messageSchema(
+						(&api_proto.ApiErrorResponse{}).ProtoReflect().Descriptor()))))))
+}
+
+func (self *openAPIGenerator) requestBody(
+	message protoreflect.MessageDescriptor) *ordereddict.Dict {
+	return ordereddict.NewDict().
+		Set("required", true).
+		Set("content", ordereddict.NewDict().
+			Set("application/json", ordereddict.NewDict().
+				Set("schema", self.messageSchema(message))))
+}
+
+// Resolve a possibly dotted path parameter to its field schema.
+func (self *openAPIGenerator) fieldPathSchema(
+	message protoreflect.MessageDescriptor, path string) *ordereddict.Dict {
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		field := message.Fields().ByName(protoreflect.Name(part))
+		if field == nil {
+			break
+		}
+		if i == len(parts)-1 {
+			return self.fieldSchema(field)
+		}
+		if field.Message() == nil {
+			break
+		}
+		message = field.Message()
+	}
+	return ordereddict.NewDict().Set("type", "string")
+}
+
+func (self *openAPIGenerator) queryParameters(
+	method string, message protoreflect.MessageDescriptor,
+	prefix string, bound map[string]bool,
+	depth int) []*ordereddict.Dict {
+	result := []*ordereddict.Dict{}
+	fields := message.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		name := prefix + string(field.Name())
+		if bound[name] || field.IsMap() {
+			continue
+		}
+
+		if field.Message() != nil && !isWellKnownType(field.Message()) {
+			if field.IsList() || depth >= MAX_QUERY_PARAMETER_DEPTH {
+				continue
+			}
+			result = append(result, self.queryParameters(
+				method, field.Message(), name+".", bound, depth+1)...)
+			continue
+		}
+
+		parameter := ordereddict.NewDict().
+			Set("name", name).
+			Set("in", "query").
+			Set("schema", self.fieldSchema(field))
+
+		if field.IsList() {
+			parameter.Set("explode", true)
+		}
+
+		if isDeprecated(method, name) {
+			parameter.Set("deprecated", true)
+		}
+		result = append(result, parameter)
+	}
+	return result
+}
+
+func isDeprecated(method, field string) bool {
+	for _, deprecation := range api_deprecations {
+		if deprecation.Method == method && deprecation.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns a reference to the message's schema, adding it to the
+// components if needed.
+func (self *openAPIGenerator) messageSchema(
+	message protoreflect.MessageDescriptor) *ordereddict.Dict {
+	if isWellKnownType(message) {
+		return wellKnownSchema(message)
+	}
+
+	name := string(message.FullName())
+	if !self.seen[name] {
+		self.seen[name] = true
+
+		properties := ordereddict.NewDict()
+		schema := ordereddict.NewDict().Set("type", "object")
+
+		// Add the schema before the fields so recursive messages
+		// refer to it.
+		self.schemas.Set(name, schema)
+
+		fields := message.Fields()
+		for i := 0; i < fields.Len(); i++ {
+			field := fields.Get(i)
+			properties.Set(string(field.Name()), self.fieldSchema(field))
+		}
+		schema.Set("properties", properties)
+	}
+
+	return ordereddict.NewDict().Set("$ref", "#/components/schemas/"+name)
+}
+
+func (self *openAPIGenerator) fieldSchema(
+	field protoreflect.FieldDescriptor) *ordereddict.Dict {
+	if field.IsMap() {
+		return ordereddict.NewDict().
+			Set("type", "object").
+			Set("additionalProperties", self.singularSchema(field.MapValue()))
+	}
+
+	schema := self.singularSchema(field)
+	if field.IsList() {
+		return ordereddict.NewDict().
+			Set("type", "array").
+			Set("items", schema)
+	}
+	return schema
+}
+
+// Types follow the protojson encoding used by the gateway.
+func (self *openAPIGenerator) singularSchema(
+	field protoreflect.FieldDescriptor) *ordereddict.Dict {
+	result := ordereddict.NewDict()
+
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		result.Set("type", "boolean")
+
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind,
+		protoreflect.Sfixed32Kind, protoreflect.Uint32Kind,
+		protoreflect.Fixed32Kind:
+		result.Set("type", "integer").Set("format", "int32")
+
+	// 64 bit integers are encoded as strings.
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind,
+		protoreflect.Sfixed64Kind, protoreflect.Uint64Kind,
+		protoreflect.Fixed64Kind:
+		result.Set("type", "string").Set("format", "int64")
+
+	case protoreflect.FloatKind:
+		result.Set("type", "number").Set("format", "float")
+
+	case protoreflect.DoubleKind:
+		result.Set("type", "number").Set("format", "double")
+
+	case protoreflect.StringKind:
+		result.Set("type", "string")
+
+	case protoreflect.BytesKind:
+		result.Set("type", "string").Set("format", "byte")
+
+	case protoreflect.EnumKind:
+		values := field.Enum().Values()
+		names := make([]string, 0, values.Len())
+		for i := 0; i < values.Len(); i++ {
+			names = append(names, string(values.Get(i).Name()))
+		}
+		result.Set("type", "string").Set("enum", names)
+
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return self.messageSchema(field.Message())
+	}
+
+	return result
+}
+
+func isWellKnownType(message protoreflect.MessageDescriptor) bool {
+	return message.ParentFile().Package() == "google.protobuf"
+}
+
+func wellKnownSchema(message protoreflect.MessageDescriptor) *ordereddict.Dict {
+	switch message.Name() {
+	case "Timestamp":
+		return ordereddict.NewDict().
+			Set("type", "string").Set("format", "date-time")
+	case "Duration", "FieldMask":
+		return ordereddict.NewDict().Set("type", "string")
+	case "BoolValue":
+		return ordereddict.NewDict().Set("type", "boolean")
+	case "StringValue", "BytesValue", "Int64Value", "UInt64Value":
+		return ordereddict.NewDict().Set("type", "string")
+	case "Int32Value", "UInt32Value":
+		return ordereddict.NewDict().Set("type", "integer")
+	case "FloatValue", "DoubleValue":
+		return ordereddict.NewDict().Set("type", "number")
+	case "ListValue":
+		return ordereddict.NewDict().Set("type", "array").
+			Set("items", ordereddict.NewDict())
+	case "Value":
+		return ordereddict.NewDict()
+	}
+	return ordereddict.NewDict().Set("type", "object")
+}
+
+func openAPIHandler(
+	config_obj *config_proto.Config,
+	version *api_proto.ApiVersion) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serialized, err := json.MarshalIndent(
+			generateOpenAPI(config_obj, version))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache")
+		_, _ = w.Write(serialized)
+	})
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/vtesting/assert"
+)
+
+type openAPIParameter struct {
+	Name       string                 `json:"name"`
+	In         string                 `json:"in"`
+	Deprecated bool                   `json:"deprecated"`
+	Schema     map[string]interface{} `json:"schema"`
+}
+
+type openAPIOperation struct {
+	OperationId string             `json:"operationId"`
+	Parameters  []openAPIParameter `json:"parameters"`
+	RequestBody struct {
+		Content map[string]struct {
+			Schema map[string]interface{} `json:"schema"`
+		} `json:"content"`
+	} `json:"requestBody"`
+}
+
+type openAPIDocument struct {
+	OpenAPI    string                                 `json:"openapi"`
+	Paths      map[string]map[string]openAPIOperation `json:"paths"`
+	Components struct {
+		Schemas         map[string]interface{} `json:"schemas"`
+		SecuritySchemes map[string]interface{} `json:"securitySchemes"`
+	} `json:"components"`
+}
+
+func getOpenAPIDocument(t *testing.T, config_obj *config_proto.Config,
+	version_index int) *openAPIDocument {
+	recorder := httptest.NewRecorder()
+	openAPIHandler(config_obj, api_versions[version_index]).ServeHTTP(
+		recorder, httptest.NewRequest("GET", "/api/v1/openapi.json", nil))
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	doc := &openAPIDocument{}
+	err := json.Unmarshal(recorder.Body.Bytes(), doc)
+	assert.NoError(t, err)
+	return doc
+}
+
+func TestOpenAPI(t *testing.T) {
+	config_obj := &config_proto.Config{
+		GUI: &config_proto.GUIConfig{
+			Authenticator: &config_proto.Authenticator{Type: "Basic"},
+		},
+	}
+
+	doc := getOpenAPIDocument(t, config_obj, 0)
+	assert.Equal(t, OPENAPI_VERSION, doc.OpenAPI)
+
+	// Both auth schemes are declared.
+	_, pres := doc.Components.SecuritySchemes["bearerAuth"]
+	assert.True(t, pres)
+	_, pres = doc.Components.SecuritySchemes["basicAuth"]
+	assert.True(t, pres)
+
+	// Path parameters are declared, other fields are query
+	// parameters.
+	op, pres := doc.Paths["/api/v1/GetClientFlows/{client_id}"]["get"]
+	assert.True(t, pres)
+	assert.Equal(t, "GetClientFlows", op.OperationId)
+
+	params := make(map[string]openAPIParameter)
+	for _, p := range op.Parameters {
+		params[p.Name] = p
+	}
+	assert.Equal(t, "path", params["client_id"].In)
+	assert.Equal(t, "query", params["count"].In)
+
+	// 64 bit integers are strings in protojson.
+	assert.Equal(t, "string", params["count"].Schema["type"])
+
+	// Deprecated fields are flagged.
+	assert.True(t, params["offset"].Deprecated)
+
+	// Additional bindings are included.
+	_, pres = doc.Paths["/api/v1/GetClientFlows/{client_id}"]["head"]
+	assert.True(t, pres)
+
+	// Posts with body "*" reference the request message.
+	op, pres = doc.Paths["/api/v1/CollectArtifact"]["post"]
+	assert.True(t, pres)
+	assert.Equal(t, 0, len(op.Parameters))
+	assert.Equal(t, "#/components/schemas/proto.ArtifactCollectorArgs",
+		op.RequestBody.Content["application/json"].Schema["$ref"])
+	_, pres = doc.Components.Schemas["proto.ArtifactCollectorArgs"]
+	assert.True(t, pres)
+
+	// Methods without http annotations are not exposed.
+	_, pres = doc.Paths["/api/v1/Query"]
+	assert.True(t, !pres)
+
+	// Other versions use their own prefix.
+	doc = getOpenAPIDocument(t, &config_proto.Config{}, 1)
+	_, pres = doc.Paths["/api/v2/CollectArtifact"]
+	assert.True(t, pres)
+	_, pres = doc.Components.SecuritySchemes["basicAuth"]
+	assert.True(t, !pres)
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// All list RPCs page their results the same way: The request's
+// offset and count (or limit/rows) select the first page and every
+// response carries a next_page_token while there are more
+// results. Passing the token back continues the listing from where
+// the previous page stopped.
+//
+// Tokens are opaque to callers. They record the offset of the next
+// page and a fingerprint of the request parameters which select the
+// listing so a token can not be used to continue a different
+// listing. Listings are always sorted so iterating over the tokens
+// visits each item once as long as the list does not change.
+const (
+	DEFAULT_PAGE_SIZE = 100
+	MAX_PAGE_SIZE     = 1000
+
+	DEFAULT_CLIENT_PAGE_SIZE = 50
+
+	DEFAULT_TABLE_ROWS = 500
+	MAX_TABLE_ROWS     = 10000
+)
+
+var invalidPageTokenError = status.Error(
+	codes.InvalidArgument, "Invalid page token")
+
+type pageToken struct {
+	Offset      uint64 `json:"offset"`
+	Cursor      string `json:"cursor,omitempty"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+type pager struct {
+	Offset uint64
+	Size   uint64
+
+	// Listings which support cursors continue from the cursor
+	// instead of the offset.
+	Cursor string
+
+	fingerprint string
+}
+
+func newPager(page_token string, offset, size, default_size, max_size uint64,
+	request ...interface{}) (*pager, error) {
+	if size == 0 {
+		size = default_size
+	}
+
+	if size > max_size {
+		size = max_size
+	}
+
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%q", request)))
+	result := &pager{
+		Offset:      offset,
+		Size:        size,
+		fingerprint: hex.EncodeToString(hash[:8]),
+	}
+
+	if page_token == "" {
+		return result, nil
+	}
+
+	serialized, err := base64.RawURLEncoding.DecodeString(page_token)
+	if err != nil {
+		return nil, invalidPageTokenError
+	}
+
+	token := &pageToken{}
+	err = json.Unmarshal(serialized, token)
+	if err != nil || token.Fingerprint != result.fingerprint {
+		return nil, invalidPageTokenError
+	}
+
+	result.Offset = token.Offset
+	result.Cursor = token.Cursor
+	return result, nil
+}
+
+// We fetch one more item than the page size to tell if there is
+// another page.
+func (self *pager) FetchSize() uint64 {
+	return self.Size + 1
+}
+
+// Returns the number of fetched items which belong in this page and
+// the token for the next page (empty on the last page).
+func (self *pager) Page(fetched int) (int, string) {
+	if uint64(fetched) <= self.Size {
+		return fetched, ""
+	}
+
+	serialized, _ := json.Marshal(&pageToken{
+		Offset:      self.Offset + self.Size,
+		Fingerprint: self.fingerprint,
+	})
+	return int(self.Size), base64.RawURLEncoding.EncodeToString(serialized)
+}
+
+// Returns the token for the page after the cursor (empty on the last
+// page).
+func (self *pager) CursorPage(cursor string) string {
+	if cursor == "" {
+		return ""
+	}
+
+	serialized, _ := json.Marshal(&pageToken{
+		Offset:      self.Offset + self.Size,
+		Cursor:      cursor,
+		Fingerprint: self.fingerprint,
+	})
+	return base64.RawURLEncoding.EncodeToString(serialized)
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"testing"
+
+	"www.velocidex.com/golang/velociraptor/vtesting/assert"
+)
+
+func TestPager(t *testing.T) {
+	// Page sizes are defaulted and clamped.
+	page, err := newPager("", 0, 0, 10, 100, "C.1234")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), page.Size)
+	assert.Equal(t, uint64(11), page.FetchSize())
+
+	page, err = newPager("", 0, 1000, 10, 100, "C.1234")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(100), page.Size)
+
+	// A short page is the last page.
+	page, err = newPager("", 5, 10, 10, 100, "C.1234")
+	assert.NoError(t, err)
+	length, token := page.Page(10)
+	assert.Equal(t, 10, length)
+	assert.Equal(t, "", token)
+
+	// Otherwise the token continues after this page.
+	length, token = page.Page(11)
+	assert.Equal(t, 10, length)
+	assert.True(t, token != "")
+
+	next, err := newPager(token, 0, 10, 10, 100, "C.1234")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(15), next.Offset)
+
+	// Tokens only continue the same listing.
+	_, err = newPager(token, 0, 10, 10, 100, "C.5678")
+	assert.Error(t, err)
+
+	_, err = newPager("garbage", 0, 10, 10, 100, "C.1234")
+	assert.Error(t, err)
+}
@@ -8,8 +8,9 @@ import (
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
-	proto2 "www.velocidex.com/golang/velociraptor/actions/proto"
-	proto1 "www.velocidex.com/golang/velociraptor/artifacts/proto"
+	proto1 "www.velocidex.com/golang/velociraptor/acls/proto"
+	proto3 "www.velocidex.com/golang/velociraptor/actions/proto"
+	proto2 "www.velocidex.com/golang/velociraptor/artifacts/proto"
 	proto "www.velocidex.com/golang/velociraptor/flows/proto"
 )
 
@@ -35,16 +36,35 @@ type APIClient interface {
 	// Clients.
 	NotifyClients(ctx context.Context, in *NotificationRequest, opts ...grpc.CallOption) (*empty.Empty, error)
 	LabelClients(ctx context.Context, in *LabelClientsRequest, opts ...grpc.CallOption) (*APIResponse, error)
+	// Remove Velociraptor from the endpoint and mark the client as
+	// decommissioned.
+	UninstallClient(ctx context.Context, in *UninstallClientRequest, opts ...grpc.CallOption) (*proto.ArtifactCollectorResponse, error)
+	// Isolate the endpoint from the network while keeping its
+	// connection to the server.
+	QuarantineClient(ctx context.Context, in *QuarantineClientRequest, opts ...grpc.CallOption) (*proto.ArtifactCollectorResponse, error)
+	UnquarantineClient(ctx context.Context, in *UnquarantineClientRequest, opts ...grpc.CallOption) (*proto.ArtifactCollectorResponse, error)
+	// Remove all the client's records, collections and uploads from
+	// the server. Returns the removed items.
+	DeleteClient(ctx context.Context, in *DeleteClientRequest, opts ...grpc.CallOption) (*GetTableResponse, error)
 	ListClients(ctx context.Context, in *SearchClientsRequest, opts ...grpc.CallOption) (*SearchClientsResponse, error)
 	GetClient(ctx context.Context, in *GetClientRequest, opts ...grpc.CallOption) (*ApiClient, error)
 	GetClientMetadata(ctx context.Context, in *GetClientRequest, opts ...grpc.CallOption) (*ClientMetadata, error)
 	SetClientMetadata(ctx context.Context, in *ClientMetadata, opts ...grpc.CallOption) (*empty.Empty, error)
+	GetBandwidthUsage(ctx context.Context, in *BandwidthUsageRequest, opts ...grpc.CallOption) (*BandwidthUsageResponse, error)
 	GetClientFlows(ctx context.Context, in *ApiFlowRequest, opts ...grpc.CallOption) (*ApiFlowResponse, error)
 	// Users
 	GetUserUITraits(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ApiUser, error)
 	SetGUIOptions(ctx context.Context, in *SetGUIOptionsRequest, opts ...grpc.CallOption) (*empty.Empty, error)
 	// List all the GUI users known on this server.
 	GetUsers(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*Users, error)
+	// Role definitions. Only custom roles may be changed.
+	GetRoles(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*Roles, error)
+	SetRole(ctx context.Context, in *proto1.Role, opts ...grpc.CallOption) (*empty.Empty, error)
+	DeleteRole(ctx context.Context, in *DeleteRoleRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	// API keys for service accounts.
+	CreateApiKey(ctx context.Context, in *CreateApiKeyRequest, opts ...grpc.CallOption) (*CreateApiKeyResponse, error)
+	ListApiKeys(ctx context.Context, in *ListApiKeysRequest, opts ...grpc.CallOption) (*ApiKeys, error)
+	RevokeApiKey(ctx context.Context, in *RevokeApiKeyRequest, opts ...grpc.CallOption) (*empty.Empty, error)
 	GetUserFavorites(ctx context.Context, in *Favorite, opts ...grpc.CallOption) (*Favorites, error)
 	// VFS
 	VFSListDirectory(ctx context.Context, in *VFSListRequest, opts ...grpc.CallOption) (*VFSListResponse, error)
@@ -52,20 +72,69 @@ type APIClient interface {
 	VFSStatDirectory(ctx context.Context, in *VFSListRequest, opts ...grpc.CallOption) (*VFSListResponse, error)
 	VFSStatDownload(ctx context.Context, in *VFSStatDownloadRequest, opts ...grpc.CallOption) (*proto.VFSDownloadInfo, error)
 	GetTable(ctx context.Context, in *GetTableRequest, opts ...grpc.CallOption) (*GetTableResponse, error)
+	// Stream all the rows of a table (e.g. flow results) as JSONL
+	// without paging.
+	StreamTable(ctx context.Context, in *GetTableRequest, opts ...grpc.CallOption) (API_StreamTableClient, error)
+	GetChartData(ctx context.Context, in *GetChartDataRequest, opts ...grpc.CallOption) (*GetChartDataResponse, error)
 	// Flows
 	CollectArtifact(ctx context.Context, in *proto.ArtifactCollectorArgs, opts ...grpc.CallOption) (*proto.ArtifactCollectorResponse, error)
+	CollectArtifactMulti(ctx context.Context, in *CollectArtifactMultiRequest, opts ...grpc.CallOption) (*CollectArtifactMultiResponse, error)
 	CancelFlow(ctx context.Context, in *ApiFlowRequest, opts ...grpc.CallOption) (*StartFlowResponse, error)
 	GetFlowDetails(ctx context.Context, in *ApiFlowRequest, opts ...grpc.CallOption) (*FlowDetails, error)
 	GetFlowRequests(ctx context.Context, in *ApiFlowRequest, opts ...grpc.CallOption) (*ApiFlowRequestDetails, error)
+	// Move the flow's results and uploads to the flow archive. The
+	// flow's metadata remains available.
+	ArchiveFlow(ctx context.Context, in *ApiFlowRequest, opts ...grpc.CallOption) (*FlowDetails, error)
+	// Restore an archived flow's data into the file store.
+	RehydrateFlow(ctx context.Context, in *ApiFlowRequest, opts ...grpc.CallOption) (*FlowDetails, error)
+	ImportCollection(ctx context.Context, in *ImportCollectionRequest, opts ...grpc.CallOption) (*FlowDetails, error)
+	// Store rows pushed by external tools as a new flow. This is
+	// only available over gRPC.
+	IngestRows(ctx context.Context, opts ...grpc.CallOption) (API_IngestRowsClient, error)
+	// Legal holds
+	SetLegalHold(ctx context.Context, in *LegalHoldRequest, opts ...grpc.CallOption) (*LegalHold, error)
+	GetLegalHolds(ctx context.Context, in *GetLegalHoldsRequest, opts ...grpc.CallOption) (*LegalHolds, error)
+	// The audit log of API calls.
+	GetAuditLog(ctx context.Context, in *GetAuditLogRequest, opts ...grpc.CallOption) (*GetAuditLogResponse, error)
+	// Active Directory group to role mappings.
+	GetLDAPMappings(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*LDAPGroupMappings, error)
+	SetLDAPMappings(ctx context.Context, in *LDAPGroupMappings, opts ...grpc.CallOption) (*LDAPGroupMappings, error)
+	// Describe the API versions served and deprecated fields.
+	GetApiVersions(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ApiVersions, error)
+	// Two person integrity approvals.
+	RequestApproval(ctx context.Context, in *Approval, opts ...grpc.CallOption) (*Approval, error)
+	GrantApproval(ctx context.Context, in *Approval, opts ...grpc.CallOption) (*Approval, error)
+	GetApprovals(ctx context.Context, in *GetApprovalsRequest, opts ...grpc.CallOption) (*ApprovalList, error)
+	// Background jobs.
+	GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error)
+	ListJobs(ctx context.Context, in *ListJobsRequest, opts ...grpc.CallOption) (*ListJobsResponse, error)
+	CancelJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error)
+	// Search the text of collected files.
+	SearchFiles(ctx context.Context, in *SearchFilesRequest, opts ...grpc.CallOption) (*SearchFilesResponse, error)
 	GetKeywordCompletions(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*KeywordCompletions, error)
+	// Language server style support for VQL editors.
+	VQLComplete(ctx context.Context, in *VQLLanguageRequest, opts ...grpc.CallOption) (*KeywordCompletions, error)
+	VQLHover(ctx context.Context, in *VQLLanguageRequest, opts ...grpc.CallOption) (*Completion, error)
+	VQLDiagnose(ctx context.Context, in *VQLLanguageRequest, opts ...grpc.CallOption) (*VQLDiagnostics, error)
 	// Artifacts
-	GetArtifacts(ctx context.Context, in *GetArtifactsRequest, opts ...grpc.CallOption) (*proto1.ArtifactDescriptors, error)
+	GetArtifacts(ctx context.Context, in *GetArtifactsRequest, opts ...grpc.CallOption) (*proto2.ArtifactDescriptors, error)
 	GetArtifactFile(ctx context.Context, in *GetArtifactRequest, opts ...grpc.CallOption) (*GetArtifactResponse, error)
 	SetArtifactFile(ctx context.Context, in *SetArtifactRequest, opts ...grpc.CallOption) (*APIResponse, error)
+	// Translate Sigma rules into artifacts. The artifacts are only
+	// returned and not added to the repository.
+	TranslateSigma(ctx context.Context, in *SigmaTranslationRequest, opts ...grpc.CallOption) (*SigmaTranslationResponse, error)
+	// Fleet coverage of MITRE ATT&CK techniques by the tagged
+	// artifacts which are deployed or recently hunted.
+	GetAttackCoverage(ctx context.Context, in *AttackCoverageRequest, opts ...grpc.CallOption) (*AttackCoverage, error)
 	LoadArtifactPack(ctx context.Context, in *VFSFileBuffer, opts ...grpc.CallOption) (*LoadArtifactPackResponse, error)
+	// Artifact exchange
+	ListExchangeArtifacts(ctx context.Context, in *ExchangeArtifactsRequest, opts ...grpc.CallOption) (*ExchangeArtifacts, error)
+	InstallExchangeArtifact(ctx context.Context, in *InstallExchangeArtifactRequest, opts ...grpc.CallOption) (*ExchangeArtifact, error)
+	// Report installed exchange artifacts which have newer versions.
+	CheckExchangeUpdates(ctx context.Context, in *ExchangeArtifactsRequest, opts ...grpc.CallOption) (*ExchangeArtifacts, error)
 	// Tools
-	GetToolInfo(ctx context.Context, in *proto1.Tool, opts ...grpc.CallOption) (*proto1.Tool, error)
-	SetToolInfo(ctx context.Context, in *proto1.Tool, opts ...grpc.CallOption) (*proto1.Tool, error)
+	GetToolInfo(ctx context.Context, in *proto2.Tool, opts ...grpc.CallOption) (*proto2.Tool, error)
+	SetToolInfo(ctx context.Context, in *proto2.Tool, opts ...grpc.CallOption) (*proto2.Tool, error)
 	// Reporting and post processing.
 	GetReport(ctx context.Context, in *GetReportRequest, opts ...grpc.CallOption) (*GetReportResponse, error)
 	// Server Monitoring Artifacts - manage the Server Monitoring
@@ -80,6 +149,9 @@ type APIClient interface {
 	// Client Monitoring Artifacts - manage the Client Monitoring
 	// Service.
 	SetClientMonitoringState(ctx context.Context, in *proto.ClientEventTable, opts ...grpc.CallOption) (*empty.Empty, error)
+	// Report how well labeled clients comply with the configured
+	// compliance policies.
+	GetComplianceReport(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ComplianceReport, error)
 	ListAvailableEventResults(ctx context.Context, in *ListAvailableEventResultsRequest, opts ...grpc.CallOption) (*ListAvailableEventResultsResponse, error)
 	// Schedule downloads.
 	CreateDownloadFile(ctx context.Context, in *CreateDownloadRequest, opts ...grpc.CallOption) (*CreateDownloadResponse, error)
@@ -96,13 +168,13 @@ type APIClient interface {
 	// This can be used by API clients to fetch file content.
 	VFSGetBuffer(ctx context.Context, in *VFSFileBuffer, opts ...grpc.CallOption) (*VFSFileBuffer, error)
 	// Streaming free form VQL.
-	Query(ctx context.Context, in *proto2.VQLCollectorArgs, opts ...grpc.CallOption) (API_QueryClient, error)
+	Query(ctx context.Context, in *proto3.VQLCollectorArgs, opts ...grpc.CallOption) (API_QueryClient, error)
 	// Watch for events from the master.
 	WatchEvent(ctx context.Context, in *EventRequest, opts ...grpc.CallOption) (API_WatchEventClient, error)
 	// Push the events to the master
 	PushEvents(ctx context.Context, in *PushEventRequest, opts ...grpc.CallOption) (*empty.Empty, error)
 	// Push monitoring event to the server.
-	WriteEvent(ctx context.Context, in *proto2.VQLResponse, opts ...grpc.CallOption) (*empty.Empty, error)
+	WriteEvent(ctx context.Context, in *proto3.VQLResponse, opts ...grpc.CallOption) (*empty.Empty, error)
 	// Remote data store access.
 	GetSubject(ctx context.Context, in *DataRequest, opts ...grpc.CallOption) (*DataResponse, error)
 	SetSubject(ctx context.Context, in *DataRequest, opts ...grpc.CallOption) (*DataResponse, error)
@@ -201,6 +273,42 @@ func (c *aPIClient) LabelClients(ctx context.Context, in *LabelClientsRequest, o
 	return out, nil
 }
 
+func (c *aPIClient) UninstallClient(ctx context.Context, in *UninstallClientRequest, opts ...grpc.CallOption) (*proto.ArtifactCollectorResponse, error) {
+	out := new(proto.ArtifactCollectorResponse)
+	err := c.cc.Invoke(ctx, "/proto.API/UninstallClient", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) QuarantineClient(ctx context.Context, in *QuarantineClientRequest, opts ...grpc.CallOption) (*proto.ArtifactCollectorResponse, error) {
+	out := new(proto.ArtifactCollectorResponse)
+	err := c.cc.Invoke(ctx, "/proto.API/QuarantineClient", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) UnquarantineClient(ctx context.Context, in *UnquarantineClientRequest, opts ...grpc.CallOption) (*proto.ArtifactCollectorResponse, error) {
+	out := new(proto.ArtifactCollectorResponse)
+	err := c.cc.Invoke(ctx, "/proto.API/UnquarantineClient", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) DeleteClient(ctx context.Context, in *DeleteClientRequest, opts ...grpc.CallOption) (*GetTableResponse, error) {
+	out := new(GetTableResponse)
+	err := c.cc.Invoke(ctx, "/proto.API/DeleteClient", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *aPIClient) ListClients(ctx context.Context, in *SearchClientsRequest, opts ...grpc.CallOption) (*SearchClientsResponse, error) {
 	out := new(SearchClientsResponse)
 	err := c.cc.Invoke(ctx, "/proto.API/ListClients", in, out, opts...)
@@ -237,6 +345,15 @@ func (c *aPIClient) SetClientMetadata(ctx context.Context, in *ClientMetadata, o
 	return out, nil
 }
 
+func (c *aPIClient) GetBandwidthUsage(ctx context.Context, in *BandwidthUsageRequest, opts ...grpc.CallOption) (*BandwidthUsageResponse, error) {
+	out := new(BandwidthUsageResponse)
+	err := c.cc.Invoke(ctx, "/proto.API/GetBandwidthUsage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *aPIClient) GetClientFlows(ctx context.Context, in *ApiFlowRequest, opts ...grpc.CallOption) (*ApiFlowResponse, error) {
 	out := new(ApiFlowResponse)
 	err := c.cc.Invoke(ctx, "/proto.API/GetClientFlows", in, out, opts...)
@@ -273,6 +390,60 @@ func (c *aPIClient) GetUsers(ctx context.Context, in *empty.Empty, opts ...grpc.
 	return out, nil
 }
 
+func (c *aPIClient) GetRoles(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*Roles, error) {
+	out := new(Roles)
+	err := c.cc.Invoke(ctx, "/proto.API/GetRoles", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) SetRole(ctx context.Context, in *proto1.Role, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/proto.API/SetRole", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) DeleteRole(ctx context.Context, in *DeleteRoleRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/proto.API/DeleteRole", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) CreateApiKey(ctx context.Context, in *CreateApiKeyRequest, opts ...grpc.CallOption) (*CreateApiKeyResponse, error) {
+	out := new(CreateApiKeyResponse)
+	err := c.cc.Invoke(ctx, "/proto.API/CreateApiKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) ListApiKeys(ctx context.Context, in *ListApiKeysRequest, opts ...grpc.CallOption) (*ApiKeys, error) {
+	out := new(ApiKeys)
+	err := c.cc.Invoke(ctx, "/proto.API/ListApiKeys", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) RevokeApiKey(ctx context.Context, in *RevokeApiKeyRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/proto.API/RevokeApiKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *aPIClient) GetUserFavorites(ctx context.Context, in *Favorite, opts ...grpc.CallOption) (*Favorites, error) {
 	out := new(Favorites)
 	err := c.cc.Invoke(ctx, "/proto.API/GetUserFavorites", in, out, opts...)
@@ -327,6 +498,47 @@ func (c *aPIClient) GetTable(ctx context.Context, in *GetTableRequest, opts ...g
 	return out, nil
 }
 
+func (c *aPIClient) StreamTable(ctx context.Context, in *GetTableRequest, opts ...grpc.CallOption) (API_StreamTableClient, error) {
+	stream, err := c.cc.NewStream(ctx, &API_ServiceDesc.Streams[0], "/proto.API/StreamTable", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aPIStreamTableClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type API_StreamTableClient interface {
+	Recv() (*TableChunk, error)
+	grpc.ClientStream
+}
+
+type aPIStreamTableClient struct {
+	grpc.ClientStream
+}
+
+func (x *aPIStreamTableClient) Recv() (*TableChunk, error) {
+	m := new(TableChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *aPIClient) GetChartData(ctx context.Context, in *GetChartDataRequest, opts ...grpc.CallOption) (*GetChartDataResponse, error) {
+	out := new(GetChartDataResponse)
+	err := c.cc.Invoke(ctx, "/proto.API/GetChartData", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *aPIClient) CollectArtifact(ctx context.Context, in *proto.ArtifactCollectorArgs, opts ...grpc.CallOption) (*proto.ArtifactCollectorResponse, error) {
 	out := new(proto.ArtifactCollectorResponse)
 	err := c.cc.Invoke(ctx, "/proto.API/CollectArtifact", in, out, opts...)
@@ -336,6 +548,15 @@ func (c *aPIClient) CollectArtifact(ctx context.Context, in *proto.ArtifactColle
 	return out, nil
 }
 
+func (c *aPIClient) CollectArtifactMulti(ctx context.Context, in *CollectArtifactMultiRequest, opts ...grpc.CallOption) (*CollectArtifactMultiResponse, error) {
+	out := new(CollectArtifactMultiResponse)
+	err := c.cc.Invoke(ctx, "/proto.API/CollectArtifactMulti", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *aPIClient) CancelFlow(ctx context.Context, in *ApiFlowRequest, opts ...grpc.CallOption) (*StartFlowResponse, error) {
 	out := new(StartFlowResponse)
 	err := c.cc.Invoke(ctx, "/proto.API/CancelFlow", in, out, opts...)
@@ -363,271 +584,530 @@ func (c *aPIClient) GetFlowRequests(ctx context.Context, in *ApiFlowRequest, opt
 	return out, nil
 }
 
-func (c *aPIClient) GetKeywordCompletions(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*KeywordCompletions, error) {
-	out := new(KeywordCompletions)
-	err := c.cc.Invoke(ctx, "/proto.API/GetKeywordCompletions", in, out, opts...)
+func (c *aPIClient) ArchiveFlow(ctx context.Context, in *ApiFlowRequest, opts ...grpc.CallOption) (*FlowDetails, error) {
+	out := new(FlowDetails)
+	err := c.cc.Invoke(ctx, "/proto.API/ArchiveFlow", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *aPIClient) GetArtifacts(ctx context.Context, in *GetArtifactsRequest, opts ...grpc.CallOption) (*proto1.ArtifactDescriptors, error) {
-	out := new(proto1.ArtifactDescriptors)
-	err := c.cc.Invoke(ctx, "/proto.API/GetArtifacts", in, out, opts...)
+func (c *aPIClient) RehydrateFlow(ctx context.Context, in *ApiFlowRequest, opts ...grpc.CallOption) (*FlowDetails, error) {
+	out := new(FlowDetails)
+	err := c.cc.Invoke(ctx, "/proto.API/RehydrateFlow", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *aPIClient) GetArtifactFile(ctx context.Context, in *GetArtifactRequest, opts ...grpc.CallOption) (*GetArtifactResponse, error) {
-	out := new(GetArtifactResponse)
-	err := c.cc.Invoke(ctx, "/proto.API/GetArtifactFile", in, out, opts...)
+func (c *aPIClient) ImportCollection(ctx context.Context, in *ImportCollectionRequest, opts ...grpc.CallOption) (*FlowDetails, error) {
+	out := new(FlowDetails)
+	err := c.cc.Invoke(ctx, "/proto.API/ImportCollection", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *aPIClient) SetArtifactFile(ctx context.Context, in *SetArtifactRequest, opts ...grpc.CallOption) (*APIResponse, error) {
-	out := new(APIResponse)
-	err := c.cc.Invoke(ctx, "/proto.API/SetArtifactFile", in, out, opts...)
+func (c *aPIClient) IngestRows(ctx context.Context, opts ...grpc.CallOption) (API_IngestRowsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &API_ServiceDesc.Streams[1], "/proto.API/IngestRows", opts...)
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
+	x := &aPIIngestRowsClient{stream}
+	return x, nil
 }
 
-func (c *aPIClient) LoadArtifactPack(ctx context.Context, in *VFSFileBuffer, opts ...grpc.CallOption) (*LoadArtifactPackResponse, error) {
-	out := new(LoadArtifactPackResponse)
-	err := c.cc.Invoke(ctx, "/proto.API/LoadArtifactPack", in, out, opts...)
-	if err != nil {
+type API_IngestRowsClient interface {
+	Send(*IngestRowsRequest) error
+	CloseAndRecv() (*FlowDetails, error)
+	grpc.ClientStream
+}
+
+type aPIIngestRowsClient struct {
+	grpc.ClientStream
+}
+
+func (x *aPIIngestRowsClient) Send(m *IngestRowsRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *aPIIngestRowsClient) CloseAndRecv() (*FlowDetails, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
 		return nil, err
 	}
-	return out, nil
+	m := new(FlowDetails)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
 }
 
-func (c *aPIClient) GetToolInfo(ctx context.Context, in *proto1.Tool, opts ...grpc.CallOption) (*proto1.Tool, error) {
-	out := new(proto1.Tool)
-	err := c.cc.Invoke(ctx, "/proto.API/GetToolInfo", in, out, opts...)
+func (c *aPIClient) SetLegalHold(ctx context.Context, in *LegalHoldRequest, opts ...grpc.CallOption) (*LegalHold, error) {
+	out := new(LegalHold)
+	err := c.cc.Invoke(ctx, "/proto.API/SetLegalHold", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *aPIClient) SetToolInfo(ctx context.Context, in *proto1.Tool, opts ...grpc.CallOption) (*proto1.Tool, error) {
-	out := new(proto1.Tool)
-	err := c.cc.Invoke(ctx, "/proto.API/SetToolInfo", in, out, opts...)
+func (c *aPIClient) GetLegalHolds(ctx context.Context, in *GetLegalHoldsRequest, opts ...grpc.CallOption) (*LegalHolds, error) {
+	out := new(LegalHolds)
+	err := c.cc.Invoke(ctx, "/proto.API/GetLegalHolds", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *aPIClient) GetReport(ctx context.Context, in *GetReportRequest, opts ...grpc.CallOption) (*GetReportResponse, error) {
-	out := new(GetReportResponse)
-	err := c.cc.Invoke(ctx, "/proto.API/GetReport", in, out, opts...)
+func (c *aPIClient) GetAuditLog(ctx context.Context, in *GetAuditLogRequest, opts ...grpc.CallOption) (*GetAuditLogResponse, error) {
+	out := new(GetAuditLogResponse)
+	err := c.cc.Invoke(ctx, "/proto.API/GetAuditLog", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *aPIClient) GetServerMonitoringState(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*proto.ArtifactCollectorArgs, error) {
-	out := new(proto.ArtifactCollectorArgs)
-	err := c.cc.Invoke(ctx, "/proto.API/GetServerMonitoringState", in, out, opts...)
+func (c *aPIClient) GetLDAPMappings(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*LDAPGroupMappings, error) {
+	out := new(LDAPGroupMappings)
+	err := c.cc.Invoke(ctx, "/proto.API/GetLDAPMappings", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *aPIClient) SetServerMonitoringState(ctx context.Context, in *proto.ArtifactCollectorArgs, opts ...grpc.CallOption) (*proto.ArtifactCollectorArgs, error) {
-	out := new(proto.ArtifactCollectorArgs)
-	err := c.cc.Invoke(ctx, "/proto.API/SetServerMonitoringState", in, out, opts...)
+func (c *aPIClient) SetLDAPMappings(ctx context.Context, in *LDAPGroupMappings, opts ...grpc.CallOption) (*LDAPGroupMappings, error) {
+	out := new(LDAPGroupMappings)
+	err := c.cc.Invoke(ctx, "/proto.API/SetLDAPMappings", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *aPIClient) GetClientMonitoringState(ctx context.Context, in *proto.GetClientMonitoringStateRequest, opts ...grpc.CallOption) (*proto.ClientEventTable, error) {
-	out := new(proto.ClientEventTable)
-	err := c.cc.Invoke(ctx, "/proto.API/GetClientMonitoringState", in, out, opts...)
+func (c *aPIClient) GetApiVersions(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ApiVersions, error) {
+	out := new(ApiVersions)
+	err := c.cc.Invoke(ctx, "/proto.API/GetApiVersions", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *aPIClient) SetClientMonitoringState(ctx context.Context, in *proto.ClientEventTable, opts ...grpc.CallOption) (*empty.Empty, error) {
-	out := new(empty.Empty)
-	err := c.cc.Invoke(ctx, "/proto.API/SetClientMonitoringState", in, out, opts...)
+func (c *aPIClient) RequestApproval(ctx context.Context, in *Approval, opts ...grpc.CallOption) (*Approval, error) {
+	out := new(Approval)
+	err := c.cc.Invoke(ctx, "/proto.API/RequestApproval", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *aPIClient) ListAvailableEventResults(ctx context.Context, in *ListAvailableEventResultsRequest, opts ...grpc.CallOption) (*ListAvailableEventResultsResponse, error) {
-	out := new(ListAvailableEventResultsResponse)
-	err := c.cc.Invoke(ctx, "/proto.API/ListAvailableEventResults", in, out, opts...)
+func (c *aPIClient) GrantApproval(ctx context.Context, in *Approval, opts ...grpc.CallOption) (*Approval, error) {
+	out := new(Approval)
+	err := c.cc.Invoke(ctx, "/proto.API/GrantApproval", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *aPIClient) CreateDownloadFile(ctx context.Context, in *CreateDownloadRequest, opts ...grpc.CallOption) (*CreateDownloadResponse, error) {
-	out := new(CreateDownloadResponse)
-	err := c.cc.Invoke(ctx, "/proto.API/CreateDownloadFile", in, out, opts...)
+func (c *aPIClient) GetApprovals(ctx context.Context, in *GetApprovalsRequest, opts ...grpc.CallOption) (*ApprovalList, error) {
+	out := new(ApprovalList)
+	err := c.cc.Invoke(ctx, "/proto.API/GetApprovals", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *aPIClient) GetNotebooks(ctx context.Context, in *NotebookCellRequest, opts ...grpc.CallOption) (*Notebooks, error) {
-	out := new(Notebooks)
-	err := c.cc.Invoke(ctx, "/proto.API/GetNotebooks", in, out, opts...)
+func (c *aPIClient) GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error) {
+	out := new(Job)
+	err := c.cc.Invoke(ctx, "/proto.API/GetJob", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *aPIClient) NewNotebook(ctx context.Context, in *NotebookMetadata, opts ...grpc.CallOption) (*NotebookMetadata, error) {
-	out := new(NotebookMetadata)
-	err := c.cc.Invoke(ctx, "/proto.API/NewNotebook", in, out, opts...)
+func (c *aPIClient) ListJobs(ctx context.Context, in *ListJobsRequest, opts ...grpc.CallOption) (*ListJobsResponse, error) {
+	out := new(ListJobsResponse)
+	err := c.cc.Invoke(ctx, "/proto.API/ListJobs", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *aPIClient) UpdateNotebook(ctx context.Context, in *NotebookMetadata, opts ...grpc.CallOption) (*NotebookMetadata, error) {
-	out := new(NotebookMetadata)
-	err := c.cc.Invoke(ctx, "/proto.API/UpdateNotebook", in, out, opts...)
+func (c *aPIClient) CancelJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error) {
+	out := new(Job)
+	err := c.cc.Invoke(ctx, "/proto.API/CancelJob", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *aPIClient) NewNotebookCell(ctx context.Context, in *NotebookCellRequest, opts ...grpc.CallOption) (*NotebookMetadata, error) {
-	out := new(NotebookMetadata)
-	err := c.cc.Invoke(ctx, "/proto.API/NewNotebookCell", in, out, opts...)
+func (c *aPIClient) SearchFiles(ctx context.Context, in *SearchFilesRequest, opts ...grpc.CallOption) (*SearchFilesResponse, error) {
+	out := new(SearchFilesResponse)
+	err := c.cc.Invoke(ctx, "/proto.API/SearchFiles", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *aPIClient) GetNotebookCell(ctx context.Context, in *NotebookCellRequest, opts ...grpc.CallOption) (*NotebookCell, error) {
-	out := new(NotebookCell)
-	err := c.cc.Invoke(ctx, "/proto.API/GetNotebookCell", in, out, opts...)
+func (c *aPIClient) GetKeywordCompletions(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*KeywordCompletions, error) {
+	out := new(KeywordCompletions)
+	err := c.cc.Invoke(ctx, "/proto.API/GetKeywordCompletions", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *aPIClient) UpdateNotebookCell(ctx context.Context, in *NotebookCellRequest, opts ...grpc.CallOption) (*NotebookCell, error) {
-	out := new(NotebookCell)
-	err := c.cc.Invoke(ctx, "/proto.API/UpdateNotebookCell", in, out, opts...)
+func (c *aPIClient) VQLComplete(ctx context.Context, in *VQLLanguageRequest, opts ...grpc.CallOption) (*KeywordCompletions, error) {
+	out := new(KeywordCompletions)
+	err := c.cc.Invoke(ctx, "/proto.API/VQLComplete", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *aPIClient) CancelNotebookCell(ctx context.Context, in *NotebookCellRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
-	out := new(empty.Empty)
-	err := c.cc.Invoke(ctx, "/proto.API/CancelNotebookCell", in, out, opts...)
+func (c *aPIClient) VQLHover(ctx context.Context, in *VQLLanguageRequest, opts ...grpc.CallOption) (*Completion, error) {
+	out := new(Completion)
+	err := c.cc.Invoke(ctx, "/proto.API/VQLHover", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *aPIClient) CreateNotebookDownloadFile(ctx context.Context, in *NotebookExportRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
-	out := new(empty.Empty)
-	err := c.cc.Invoke(ctx, "/proto.API/CreateNotebookDownloadFile", in, out, opts...)
+func (c *aPIClient) VQLDiagnose(ctx context.Context, in *VQLLanguageRequest, opts ...grpc.CallOption) (*VQLDiagnostics, error) {
+	out := new(VQLDiagnostics)
+	err := c.cc.Invoke(ctx, "/proto.API/VQLDiagnose", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *aPIClient) UploadNotebookAttachment(ctx context.Context, in *NotebookFileUploadRequest, opts ...grpc.CallOption) (*NotebookFileUploadResponse, error) {
-	out := new(NotebookFileUploadResponse)
-	err := c.cc.Invoke(ctx, "/proto.API/UploadNotebookAttachment", in, out, opts...)
+func (c *aPIClient) GetArtifacts(ctx context.Context, in *GetArtifactsRequest, opts ...grpc.CallOption) (*proto2.ArtifactDescriptors, error) {
+	out := new(proto2.ArtifactDescriptors)
+	err := c.cc.Invoke(ctx, "/proto.API/GetArtifacts", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *aPIClient) VFSGetBuffer(ctx context.Context, in *VFSFileBuffer, opts ...grpc.CallOption) (*VFSFileBuffer, error) {
-	out := new(VFSFileBuffer)
-	err := c.cc.Invoke(ctx, "/proto.API/VFSGetBuffer", in, out, opts...)
+func (c *aPIClient) GetArtifactFile(ctx context.Context, in *GetArtifactRequest, opts ...grpc.CallOption) (*GetArtifactResponse, error) {
+	out := new(GetArtifactResponse)
+	err := c.cc.Invoke(ctx, "/proto.API/GetArtifactFile", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *aPIClient) Query(ctx context.Context, in *proto2.VQLCollectorArgs, opts ...grpc.CallOption) (API_QueryClient, error) {
-	stream, err := c.cc.NewStream(ctx, &API_ServiceDesc.Streams[0], "/proto.API/Query", opts...)
+func (c *aPIClient) SetArtifactFile(ctx context.Context, in *SetArtifactRequest, opts ...grpc.CallOption) (*APIResponse, error) {
+	out := new(APIResponse)
+	err := c.cc.Invoke(ctx, "/proto.API/SetArtifactFile", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &aPIQueryClient{stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
+	return out, nil
+}
+
+func (c *aPIClient) TranslateSigma(ctx context.Context, in *SigmaTranslationRequest, opts ...grpc.CallOption) (*SigmaTranslationResponse, error) {
+	out := new(SigmaTranslationResponse)
+	err := c.cc.Invoke(ctx, "/proto.API/TranslateSigma", in, out, opts...)
+	if err != nil {
 		return nil, err
 	}
-	if err := x.ClientStream.CloseSend(); err != nil {
+	return out, nil
+}
+
+func (c *aPIClient) GetAttackCoverage(ctx context.Context, in *AttackCoverageRequest, opts ...grpc.CallOption) (*AttackCoverage, error) {
+	out := new(AttackCoverage)
+	err := c.cc.Invoke(ctx, "/proto.API/GetAttackCoverage", in, out, opts...)
+	if err != nil {
 		return nil, err
 	}
-	return x, nil
+	return out, nil
 }
 
-type API_QueryClient interface {
-	Recv() (*proto2.VQLResponse, error)
-	grpc.ClientStream
+func (c *aPIClient) LoadArtifactPack(ctx context.Context, in *VFSFileBuffer, opts ...grpc.CallOption) (*LoadArtifactPackResponse, error) {
+	out := new(LoadArtifactPackResponse)
+	err := c.cc.Invoke(ctx, "/proto.API/LoadArtifactPack", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-type aPIQueryClient struct {
-	grpc.ClientStream
+func (c *aPIClient) ListExchangeArtifacts(ctx context.Context, in *ExchangeArtifactsRequest, opts ...grpc.CallOption) (*ExchangeArtifacts, error) {
+	out := new(ExchangeArtifacts)
+	err := c.cc.Invoke(ctx, "/proto.API/ListExchangeArtifacts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (x *aPIQueryClient) Recv() (*proto2.VQLResponse, error) {
-	m := new(proto2.VQLResponse)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
+func (c *aPIClient) InstallExchangeArtifact(ctx context.Context, in *InstallExchangeArtifactRequest, opts ...grpc.CallOption) (*ExchangeArtifact, error) {
+	out := new(ExchangeArtifact)
+	err := c.cc.Invoke(ctx, "/proto.API/InstallExchangeArtifact", in, out, opts...)
+	if err != nil {
 		return nil, err
 	}
-	return m, nil
+	return out, nil
 }
 
-func (c *aPIClient) WatchEvent(ctx context.Context, in *EventRequest, opts ...grpc.CallOption) (API_WatchEventClient, error) {
-	stream, err := c.cc.NewStream(ctx, &API_ServiceDesc.Streams[1], "/proto.API/WatchEvent", opts...)
+func (c *aPIClient) CheckExchangeUpdates(ctx context.Context, in *ExchangeArtifactsRequest, opts ...grpc.CallOption) (*ExchangeArtifacts, error) {
+	out := new(ExchangeArtifacts)
+	err := c.cc.Invoke(ctx, "/proto.API/CheckExchangeUpdates", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &aPIWatchEventClient{stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
+	return out, nil
+}
+
+func (c *aPIClient) GetToolInfo(ctx context.Context, in *proto2.Tool, opts ...grpc.CallOption) (*proto2.Tool, error) {
+	out := new(proto2.Tool)
+	err := c.cc.Invoke(ctx, "/proto.API/GetToolInfo", in, out, opts...)
+	if err != nil {
 		return nil, err
 	}
-	if err := x.ClientStream.CloseSend(); err != nil {
+	return out, nil
+}
+
+func (c *aPIClient) SetToolInfo(ctx context.Context, in *proto2.Tool, opts ...grpc.CallOption) (*proto2.Tool, error) {
+	out := new(proto2.Tool)
+	err := c.cc.Invoke(ctx, "/proto.API/SetToolInfo", in, out, opts...)
+	if err != nil {
 		return nil, err
 	}
-	return x, nil
+	return out, nil
 }
 
-type API_WatchEventClient interface {
-	Recv() (*EventResponse, error)
+func (c *aPIClient) GetReport(ctx context.Context, in *GetReportRequest, opts ...grpc.CallOption) (*GetReportResponse, error) {
+	out := new(GetReportResponse)
+	err := c.cc.Invoke(ctx, "/proto.API/GetReport", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) GetServerMonitoringState(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*proto.ArtifactCollectorArgs, error) {
+	out := new(proto.ArtifactCollectorArgs)
+	err := c.cc.Invoke(ctx, "/proto.API/GetServerMonitoringState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) SetServerMonitoringState(ctx context.Context, in *proto.ArtifactCollectorArgs, opts ...grpc.CallOption) (*proto.ArtifactCollectorArgs, error) {
+	out := new(proto.ArtifactCollectorArgs)
+	err := c.cc.Invoke(ctx, "/proto.API/SetServerMonitoringState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) GetClientMonitoringState(ctx context.Context, in *proto.GetClientMonitoringStateRequest, opts ...grpc.CallOption) (*proto.ClientEventTable, error) {
+	out := new(proto.ClientEventTable)
+	err := c.cc.Invoke(ctx, "/proto.API/GetClientMonitoringState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) SetClientMonitoringState(ctx context.Context, in *proto.ClientEventTable, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/proto.API/SetClientMonitoringState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) GetComplianceReport(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ComplianceReport, error) {
+	out := new(ComplianceReport)
+	err := c.cc.Invoke(ctx, "/proto.API/GetComplianceReport", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) ListAvailableEventResults(ctx context.Context, in *ListAvailableEventResultsRequest, opts ...grpc.CallOption) (*ListAvailableEventResultsResponse, error) {
+	out := new(ListAvailableEventResultsResponse)
+	err := c.cc.Invoke(ctx, "/proto.API/ListAvailableEventResults", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) CreateDownloadFile(ctx context.Context, in *CreateDownloadRequest, opts ...grpc.CallOption) (*CreateDownloadResponse, error) {
+	out := new(CreateDownloadResponse)
+	err := c.cc.Invoke(ctx, "/proto.API/CreateDownloadFile", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) GetNotebooks(ctx context.Context, in *NotebookCellRequest, opts ...grpc.CallOption) (*Notebooks, error) {
+	out := new(Notebooks)
+	err := c.cc.Invoke(ctx, "/proto.API/GetNotebooks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) NewNotebook(ctx context.Context, in *NotebookMetadata, opts ...grpc.CallOption) (*NotebookMetadata, error) {
+	out := new(NotebookMetadata)
+	err := c.cc.Invoke(ctx, "/proto.API/NewNotebook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) UpdateNotebook(ctx context.Context, in *NotebookMetadata, opts ...grpc.CallOption) (*NotebookMetadata, error) {
+	out := new(NotebookMetadata)
+	err := c.cc.Invoke(ctx, "/proto.API/UpdateNotebook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) NewNotebookCell(ctx context.Context, in *NotebookCellRequest, opts ...grpc.CallOption) (*NotebookMetadata, error) {
+	out := new(NotebookMetadata)
+	err := c.cc.Invoke(ctx, "/proto.API/NewNotebookCell", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) GetNotebookCell(ctx context.Context, in *NotebookCellRequest, opts ...grpc.CallOption) (*NotebookCell, error) {
+	out := new(NotebookCell)
+	err := c.cc.Invoke(ctx, "/proto.API/GetNotebookCell", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) UpdateNotebookCell(ctx context.Context, in *NotebookCellRequest, opts ...grpc.CallOption) (*NotebookCell, error) {
+	out := new(NotebookCell)
+	err := c.cc.Invoke(ctx, "/proto.API/UpdateNotebookCell", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) CancelNotebookCell(ctx context.Context, in *NotebookCellRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/proto.API/CancelNotebookCell", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) CreateNotebookDownloadFile(ctx context.Context, in *NotebookExportRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/proto.API/CreateNotebookDownloadFile", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) UploadNotebookAttachment(ctx context.Context, in *NotebookFileUploadRequest, opts ...grpc.CallOption) (*NotebookFileUploadResponse, error) {
+	out := new(NotebookFileUploadResponse)
+	err := c.cc.Invoke(ctx, "/proto.API/UploadNotebookAttachment", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) VFSGetBuffer(ctx context.Context, in *VFSFileBuffer, opts ...grpc.CallOption) (*VFSFileBuffer, error) {
+	out := new(VFSFileBuffer)
+	err := c.cc.Invoke(ctx, "/proto.API/VFSGetBuffer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) Query(ctx context.Context, in *proto3.VQLCollectorArgs, opts ...grpc.CallOption) (API_QueryClient, error) {
+	stream, err := c.cc.NewStream(ctx, &API_ServiceDesc.Streams[2], "/proto.API/Query", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aPIQueryClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type API_QueryClient interface {
+	Recv() (*proto3.VQLResponse, error)
+	grpc.ClientStream
+}
+
+type aPIQueryClient struct {
+	grpc.ClientStream
+}
+
+func (x *aPIQueryClient) Recv() (*proto3.VQLResponse, error) {
+	m := new(proto3.VQLResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *aPIClient) WatchEvent(ctx context.Context, in *EventRequest, opts ...grpc.CallOption) (API_WatchEventClient, error) {
+	stream, err := c.cc.NewStream(ctx, &API_ServiceDesc.Streams[3], "/proto.API/WatchEvent", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aPIWatchEventClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type API_WatchEventClient interface {
+	Recv() (*EventResponse, error)
 	grpc.ClientStream
 }
 
@@ -652,7 +1132,7 @@ func (c *aPIClient) PushEvents(ctx context.Context, in *PushEventRequest, opts .
 	return out, nil
 }
 
-func (c *aPIClient) WriteEvent(ctx context.Context, in *proto2.VQLResponse, opts ...grpc.CallOption) (*empty.Empty, error) {
+func (c *aPIClient) WriteEvent(ctx context.Context, in *proto3.VQLResponse, opts ...grpc.CallOption) (*empty.Empty, error) {
 	out := new(empty.Empty)
 	err := c.cc.Invoke(ctx, "/proto.API/WriteEvent", in, out, opts...)
 	if err != nil {
@@ -723,16 +1203,35 @@ type APIServer interface {
 	// Clients.
 	NotifyClients(context.Context, *NotificationRequest) (*empty.Empty, error)
 	LabelClients(context.Context, *LabelClientsRequest) (*APIResponse, error)
+	// Remove Velociraptor from the endpoint and mark the client as
+	// decommissioned.
+	UninstallClient(context.Context, *UninstallClientRequest) (*proto.ArtifactCollectorResponse, error)
+	// Isolate the endpoint from the network while keeping its
+	// connection to the server.
+	QuarantineClient(context.Context, *QuarantineClientRequest) (*proto.ArtifactCollectorResponse, error)
+	UnquarantineClient(context.Context, *UnquarantineClientRequest) (*proto.ArtifactCollectorResponse, error)
+	// Remove all the client's records, collections and uploads from
+	// the server. Returns the removed items.
+	DeleteClient(context.Context, *DeleteClientRequest) (*GetTableResponse, error)
 	ListClients(context.Context, *SearchClientsRequest) (*SearchClientsResponse, error)
 	GetClient(context.Context, *GetClientRequest) (*ApiClient, error)
 	GetClientMetadata(context.Context, *GetClientRequest) (*ClientMetadata, error)
 	SetClientMetadata(context.Context, *ClientMetadata) (*empty.Empty, error)
+	GetBandwidthUsage(context.Context, *BandwidthUsageRequest) (*BandwidthUsageResponse, error)
 	GetClientFlows(context.Context, *ApiFlowRequest) (*ApiFlowResponse, error)
 	// Users
 	GetUserUITraits(context.Context, *empty.Empty) (*ApiUser, error)
 	SetGUIOptions(context.Context, *SetGUIOptionsRequest) (*empty.Empty, error)
 	// List all the GUI users known on this server.
 	GetUsers(context.Context, *empty.Empty) (*Users, error)
+	// Role definitions. Only custom roles may be changed.
+	GetRoles(context.Context, *empty.Empty) (*Roles, error)
+	SetRole(context.Context, *proto1.Role) (*empty.Empty, error)
+	DeleteRole(context.Context, *DeleteRoleRequest) (*empty.Empty, error)
+	// API keys for service accounts.
+	CreateApiKey(context.Context, *CreateApiKeyRequest) (*CreateApiKeyResponse, error)
+	ListApiKeys(context.Context, *ListApiKeysRequest) (*ApiKeys, error)
+	RevokeApiKey(context.Context, *RevokeApiKeyRequest) (*empty.Empty, error)
 	GetUserFavorites(context.Context, *Favorite) (*Favorites, error)
 	// VFS
 	VFSListDirectory(context.Context, *VFSListRequest) (*VFSListResponse, error)
@@ -740,20 +1239,69 @@ type APIServer interface {
 	VFSStatDirectory(context.Context, *VFSListRequest) (*VFSListResponse, error)
 	VFSStatDownload(context.Context, *VFSStatDownloadRequest) (*proto.VFSDownloadInfo, error)
 	GetTable(context.Context, *GetTableRequest) (*GetTableResponse, error)
+	// Stream all the rows of a table (e.g. flow results) as JSONL
+	// without paging.
+	StreamTable(*GetTableRequest, API_StreamTableServer) error
+	GetChartData(context.Context, *GetChartDataRequest) (*GetChartDataResponse, error)
 	// Flows
 	CollectArtifact(context.Context, *proto.ArtifactCollectorArgs) (*proto.ArtifactCollectorResponse, error)
+	CollectArtifactMulti(context.Context, *CollectArtifactMultiRequest) (*CollectArtifactMultiResponse, error)
 	CancelFlow(context.Context, *ApiFlowRequest) (*StartFlowResponse, error)
 	GetFlowDetails(context.Context, *ApiFlowRequest) (*FlowDetails, error)
 	GetFlowRequests(context.Context, *ApiFlowRequest) (*ApiFlowRequestDetails, error)
+	// Move the flow's results and uploads to the flow archive. The
+	// flow's metadata remains available.
+	ArchiveFlow(context.Context, *ApiFlowRequest) (*FlowDetails, error)
+	// Restore an archived flow's data into the file store.
+	RehydrateFlow(context.Context, *ApiFlowRequest) (*FlowDetails, error)
+	ImportCollection(context.Context, *ImportCollectionRequest) (*FlowDetails, error)
+	// Store rows pushed by external tools as a new flow. This is
+	// only available over gRPC.
+	IngestRows(API_IngestRowsServer) error
+	// Legal holds
+	SetLegalHold(context.Context, *LegalHoldRequest) (*LegalHold, error)
+	GetLegalHolds(context.Context, *GetLegalHoldsRequest) (*LegalHolds, error)
+	// The audit log of API calls.
+	GetAuditLog(context.Context, *GetAuditLogRequest) (*GetAuditLogResponse, error)
+	// Active Directory group to role mappings.
+	GetLDAPMappings(context.Context, *empty.Empty) (*LDAPGroupMappings, error)
+	SetLDAPMappings(context.Context, *LDAPGroupMappings) (*LDAPGroupMappings, error)
+	// Describe the API versions served and deprecated fields.
+	GetApiVersions(context.Context, *empty.Empty) (*ApiVersions, error)
+	// Two person integrity approvals.
+	RequestApproval(context.Context, *Approval) (*Approval, error)
+	GrantApproval(context.Context, *Approval) (*Approval, error)
+	GetApprovals(context.Context, *GetApprovalsRequest) (*ApprovalList, error)
+	// Background jobs.
+	GetJob(context.Context, *GetJobRequest) (*Job, error)
+	ListJobs(context.Context, *ListJobsRequest) (*ListJobsResponse, error)
+	CancelJob(context.Context, *GetJobRequest) (*Job, error)
+	// Search the text of collected files.
+	SearchFiles(context.Context, *SearchFilesRequest) (*SearchFilesResponse, error)
 	GetKeywordCompletions(context.Context, *empty.Empty) (*KeywordCompletions, error)
+	// Language server style support for VQL editors.
+	VQLComplete(context.Context, *VQLLanguageRequest) (*KeywordCompletions, error)
+	VQLHover(context.Context, *VQLLanguageRequest) (*Completion, error)
+	VQLDiagnose(context.Context, *VQLLanguageRequest) (*VQLDiagnostics, error)
 	// Artifacts
-	GetArtifacts(context.Context, *GetArtifactsRequest) (*proto1.ArtifactDescriptors, error)
+	GetArtifacts(context.Context, *GetArtifactsRequest) (*proto2.ArtifactDescriptors, error)
 	GetArtifactFile(context.Context, *GetArtifactRequest) (*GetArtifactResponse, error)
 	SetArtifactFile(context.Context, *SetArtifactRequest) (*APIResponse, error)
+	// Translate Sigma rules into artifacts. The artifacts are only
+	// returned and not added to the repository.
+	TranslateSigma(context.Context, *SigmaTranslationRequest) (*SigmaTranslationResponse, error)
+	// Fleet coverage of MITRE ATT&CK techniques by the tagged
+	// artifacts which are deployed or recently hunted.
+	GetAttackCoverage(context.Context, *AttackCoverageRequest) (*AttackCoverage, error)
 	LoadArtifactPack(context.Context, *VFSFileBuffer) (*LoadArtifactPackResponse, error)
+	// Artifact exchange
+	ListExchangeArtifacts(context.Context, *ExchangeArtifactsRequest) (*ExchangeArtifacts, error)
+	InstallExchangeArtifact(context.Context, *InstallExchangeArtifactRequest) (*ExchangeArtifact, error)
+	// Report installed exchange artifacts which have newer versions.
+	CheckExchangeUpdates(context.Context, *ExchangeArtifactsRequest) (*ExchangeArtifacts, error)
 	// Tools
-	GetToolInfo(context.Context, *proto1.Tool) (*proto1.Tool, error)
-	SetToolInfo(context.Context, *proto1.Tool) (*proto1.Tool, error)
+	GetToolInfo(context.Context, *proto2.Tool) (*proto2.Tool, error)
+	SetToolInfo(context.Context, *proto2.Tool) (*proto2.Tool, error)
 	// Reporting and post processing.
 	GetReport(context.Context, *GetReportRequest) (*GetReportResponse, error)
 	// Server Monitoring Artifacts - manage the Server Monitoring
@@ -768,6 +1316,9 @@ type APIServer interface {
 	// Client Monitoring Artifacts - manage the Client Monitoring
 	// Service.
 	SetClientMonitoringState(context.Context, *proto.ClientEventTable) (*empty.Empty, error)
+	// Report how well labeled clients comply with the configured
+	// compliance policies.
+	GetComplianceReport(context.Context, *empty.Empty) (*ComplianceReport, error)
 	ListAvailableEventResults(context.Context, *ListAvailableEventResultsRequest) (*ListAvailableEventResultsResponse, error)
 	// Schedule downloads.
 	CreateDownloadFile(context.Context, *CreateDownloadRequest) (*CreateDownloadResponse, error)
@@ -784,13 +1335,13 @@ type APIServer interface {
 	// This can be used by API clients to fetch file content.
 	VFSGetBuffer(context.Context, *VFSFileBuffer) (*VFSFileBuffer, error)
 	// Streaming free form VQL.
-	Query(*proto2.VQLCollectorArgs, API_QueryServer) error
+	Query(*proto3.VQLCollectorArgs, API_QueryServer) error
 	// Watch for events from the master.
 	WatchEvent(*EventRequest, API_WatchEventServer) error
 	// Push the events to the master
 	PushEvents(context.Context, *PushEventRequest) (*empty.Empty, error)
 	// Push monitoring event to the server.
-	WriteEvent(context.Context, *proto2.VQLResponse) (*empty.Empty, error)
+	WriteEvent(context.Context, *proto3.VQLResponse) (*empty.Empty, error)
 	// Remote data store access.
 	GetSubject(context.Context, *DataRequest) (*DataResponse, error)
 	SetSubject(context.Context, *DataRequest) (*DataResponse, error)
@@ -832,6 +1383,18 @@ func (UnimplementedAPIServer) NotifyClients(context.Context, *NotificationReques
 func (UnimplementedAPIServer) LabelClients(context.Context, *LabelClientsRequest) (*APIResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method LabelClients not implemented")
 }
+func (UnimplementedAPIServer) UninstallClient(context.Context, *UninstallClientRequest) (*proto.ArtifactCollectorResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UninstallClient not implemented")
+}
+func (UnimplementedAPIServer) QuarantineClient(context.Context, *QuarantineClientRequest) (*proto.ArtifactCollectorResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QuarantineClient not implemented")
+}
+func (UnimplementedAPIServer) UnquarantineClient(context.Context, *UnquarantineClientRequest) (*proto.ArtifactCollectorResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnquarantineClient not implemented")
+}
+func (UnimplementedAPIServer) DeleteClient(context.Context, *DeleteClientRequest) (*GetTableResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteClient not implemented")
+}
 func (UnimplementedAPIServer) ListClients(context.Context, *SearchClientsRequest) (*SearchClientsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListClients not implemented")
 }
@@ -844,6 +1407,9 @@ func (UnimplementedAPIServer) GetClientMetadata(context.Context, *GetClientReque
 func (UnimplementedAPIServer) SetClientMetadata(context.Context, *ClientMetadata) (*empty.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SetClientMetadata not implemented")
 }
+func (UnimplementedAPIServer) GetBandwidthUsage(context.Context, *BandwidthUsageRequest) (*BandwidthUsageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBandwidthUsage not implemented")
+}
 func (UnimplementedAPIServer) GetClientFlows(context.Context, *ApiFlowRequest) (*ApiFlowResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetClientFlows not implemented")
 }
@@ -856,6 +1422,24 @@ func (UnimplementedAPIServer) SetGUIOptions(context.Context, *SetGUIOptionsReque
 func (UnimplementedAPIServer) GetUsers(context.Context, *empty.Empty) (*Users, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUsers not implemented")
 }
+func (UnimplementedAPIServer) GetRoles(context.Context, *empty.Empty) (*Roles, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRoles not implemented")
+}
+func (UnimplementedAPIServer) SetRole(context.Context, *proto1.Role) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetRole not implemented")
+}
+func (UnimplementedAPIServer) DeleteRole(context.Context, *DeleteRoleRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteRole not implemented")
+}
+func (UnimplementedAPIServer) CreateApiKey(context.Context, *CreateApiKeyRequest) (*CreateApiKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateApiKey not implemented")
+}
+func (UnimplementedAPIServer) ListApiKeys(context.Context, *ListApiKeysRequest) (*ApiKeys, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListApiKeys not implemented")
+}
+func (UnimplementedAPIServer) RevokeApiKey(context.Context, *RevokeApiKeyRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeApiKey not implemented")
+}
 func (UnimplementedAPIServer) GetUserFavorites(context.Context, *Favorite) (*Favorites, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUserFavorites not implemented")
 }
@@ -874,9 +1458,18 @@ func (UnimplementedAPIServer) VFSStatDownload(context.Context, *VFSStatDownloadR
 func (UnimplementedAPIServer) GetTable(context.Context, *GetTableRequest) (*GetTableResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetTable not implemented")
 }
+func (UnimplementedAPIServer) StreamTable(*GetTableRequest, API_StreamTableServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamTable not implemented")
+}
+func (UnimplementedAPIServer) GetChartData(context.Context, *GetChartDataRequest) (*GetChartDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChartData not implemented")
+}
 func (UnimplementedAPIServer) CollectArtifact(context.Context, *proto.ArtifactCollectorArgs) (*proto.ArtifactCollectorResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CollectArtifact not implemented")
 }
+func (UnimplementedAPIServer) CollectArtifactMulti(context.Context, *CollectArtifactMultiRequest) (*CollectArtifactMultiResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CollectArtifactMulti not implemented")
+}
 func (UnimplementedAPIServer) CancelFlow(context.Context, *ApiFlowRequest) (*StartFlowResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CancelFlow not implemented")
 }
@@ -886,10 +1479,70 @@ func (UnimplementedAPIServer) GetFlowDetails(context.Context, *ApiFlowRequest) (
 func (UnimplementedAPIServer) GetFlowRequests(context.Context, *ApiFlowRequest) (*ApiFlowRequestDetails, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetFlowRequests not implemented")
 }
+func (UnimplementedAPIServer) ArchiveFlow(context.Context, *ApiFlowRequest) (*FlowDetails, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ArchiveFlow not implemented")
+}
+func (UnimplementedAPIServer) RehydrateFlow(context.Context, *ApiFlowRequest) (*FlowDetails, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RehydrateFlow not implemented")
+}
+func (UnimplementedAPIServer) ImportCollection(context.Context, *ImportCollectionRequest) (*FlowDetails, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportCollection not implemented")
+}
+func (UnimplementedAPIServer) IngestRows(API_IngestRowsServer) error {
+	return status.Errorf(codes.Unimplemented, "method IngestRows not implemented")
+}
+func (UnimplementedAPIServer) SetLegalHold(context.Context, *LegalHoldRequest) (*LegalHold, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLegalHold not implemented")
+}
+func (UnimplementedAPIServer) GetLegalHolds(context.Context, *GetLegalHoldsRequest) (*LegalHolds, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLegalHolds not implemented")
+}
+func (UnimplementedAPIServer) GetAuditLog(context.Context, *GetAuditLogRequest) (*GetAuditLogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAuditLog not implemented")
+}
+func (UnimplementedAPIServer) GetLDAPMappings(context.Context, *empty.Empty) (*LDAPGroupMappings, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLDAPMappings not implemented")
+}
+func (UnimplementedAPIServer) SetLDAPMappings(context.Context, *LDAPGroupMappings) (*LDAPGroupMappings, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLDAPMappings not implemented")
+}
+func (UnimplementedAPIServer) GetApiVersions(context.Context, *empty.Empty) (*ApiVersions, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetApiVersions not implemented")
+}
+func (UnimplementedAPIServer) RequestApproval(context.Context, *Approval) (*Approval, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestApproval not implemented")
+}
+func (UnimplementedAPIServer) GrantApproval(context.Context, *Approval) (*Approval, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GrantApproval not implemented")
+}
+func (UnimplementedAPIServer) GetApprovals(context.Context, *GetApprovalsRequest) (*ApprovalList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetApprovals not implemented")
+}
+func (UnimplementedAPIServer) GetJob(context.Context, *GetJobRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetJob not implemented")
+}
+func (UnimplementedAPIServer) ListJobs(context.Context, *ListJobsRequest) (*ListJobsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListJobs not implemented")
+}
+func (UnimplementedAPIServer) CancelJob(context.Context, *GetJobRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelJob not implemented")
+}
+func (UnimplementedAPIServer) SearchFiles(context.Context, *SearchFilesRequest) (*SearchFilesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchFiles not implemented")
+}
 func (UnimplementedAPIServer) GetKeywordCompletions(context.Context, *empty.Empty) (*KeywordCompletions, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetKeywordCompletions not implemented")
 }
-func (UnimplementedAPIServer) GetArtifacts(context.Context, *GetArtifactsRequest) (*proto1.ArtifactDescriptors, error) {
+func (UnimplementedAPIServer) VQLComplete(context.Context, *VQLLanguageRequest) (*KeywordCompletions, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VQLComplete not implemented")
+}
+func (UnimplementedAPIServer) VQLHover(context.Context, *VQLLanguageRequest) (*Completion, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VQLHover not implemented")
+}
+func (UnimplementedAPIServer) VQLDiagnose(context.Context, *VQLLanguageRequest) (*VQLDiagnostics, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VQLDiagnose not implemented")
+}
+func (UnimplementedAPIServer) GetArtifacts(context.Context, *GetArtifactsRequest) (*proto2.ArtifactDescriptors, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetArtifacts not implemented")
 }
 func (UnimplementedAPIServer) GetArtifactFile(context.Context, *GetArtifactRequest) (*GetArtifactResponse, error) {
@@ -898,13 +1551,28 @@ func (UnimplementedAPIServer) GetArtifactFile(context.Context, *GetArtifactReque
 func (UnimplementedAPIServer) SetArtifactFile(context.Context, *SetArtifactRequest) (*APIResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SetArtifactFile not implemented")
 }
+func (UnimplementedAPIServer) TranslateSigma(context.Context, *SigmaTranslationRequest) (*SigmaTranslationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TranslateSigma not implemented")
+}
+func (UnimplementedAPIServer) GetAttackCoverage(context.Context, *AttackCoverageRequest) (*AttackCoverage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAttackCoverage not implemented")
+}
 func (UnimplementedAPIServer) LoadArtifactPack(context.Context, *VFSFileBuffer) (*LoadArtifactPackResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method LoadArtifactPack not implemented")
 }
-func (UnimplementedAPIServer) GetToolInfo(context.Context, *proto1.Tool) (*proto1.Tool, error) {
+func (UnimplementedAPIServer) ListExchangeArtifacts(context.Context, *ExchangeArtifactsRequest) (*ExchangeArtifacts, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListExchangeArtifacts not implemented")
+}
+func (UnimplementedAPIServer) InstallExchangeArtifact(context.Context, *InstallExchangeArtifactRequest) (*ExchangeArtifact, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InstallExchangeArtifact not implemented")
+}
+func (UnimplementedAPIServer) CheckExchangeUpdates(context.Context, *ExchangeArtifactsRequest) (*ExchangeArtifacts, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckExchangeUpdates not implemented")
+}
+func (UnimplementedAPIServer) GetToolInfo(context.Context, *proto2.Tool) (*proto2.Tool, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetToolInfo not implemented")
 }
-func (UnimplementedAPIServer) SetToolInfo(context.Context, *proto1.Tool) (*proto1.Tool, error) {
+func (UnimplementedAPIServer) SetToolInfo(context.Context, *proto2.Tool) (*proto2.Tool, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SetToolInfo not implemented")
 }
 func (UnimplementedAPIServer) GetReport(context.Context, *GetReportRequest) (*GetReportResponse, error) {
@@ -922,6 +1590,9 @@ func (UnimplementedAPIServer) GetClientMonitoringState(context.Context, *proto.G
 func (UnimplementedAPIServer) SetClientMonitoringState(context.Context, *proto.ClientEventTable) (*empty.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SetClientMonitoringState not implemented")
 }
+func (UnimplementedAPIServer) GetComplianceReport(context.Context, *empty.Empty) (*ComplianceReport, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetComplianceReport not implemented")
+}
 func (UnimplementedAPIServer) ListAvailableEventResults(context.Context, *ListAvailableEventResultsRequest) (*ListAvailableEventResultsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListAvailableEventResults not implemented")
 }
@@ -958,7 +1629,7 @@ func (UnimplementedAPIServer) UploadNotebookAttachment(context.Context, *Noteboo
 func (UnimplementedAPIServer) VFSGetBuffer(context.Context, *VFSFileBuffer) (*VFSFileBuffer, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method VFSGetBuffer not implemented")
 }
-func (UnimplementedAPIServer) Query(*proto2.VQLCollectorArgs, API_QueryServer) error {
+func (UnimplementedAPIServer) Query(*proto3.VQLCollectorArgs, API_QueryServer) error {
 	return status.Errorf(codes.Unimplemented, "method Query not implemented")
 }
 func (UnimplementedAPIServer) WatchEvent(*EventRequest, API_WatchEventServer) error {
@@ -967,7 +1638,7 @@ func (UnimplementedAPIServer) WatchEvent(*EventRequest, API_WatchEventServer) er
 func (UnimplementedAPIServer) PushEvents(context.Context, *PushEventRequest) (*empty.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method PushEvents not implemented")
 }
-func (UnimplementedAPIServer) WriteEvent(context.Context, *proto2.VQLResponse) (*empty.Empty, error) {
+func (UnimplementedAPIServer) WriteEvent(context.Context, *proto3.VQLResponse) (*empty.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method WriteEvent not implemented")
 }
 func (UnimplementedAPIServer) GetSubject(context.Context, *DataRequest) (*DataResponse, error) {
@@ -1103,479 +1774,1192 @@ func _API_GetHuntFlows_Handler(srv interface{}, ctx context.Context, dec func(in
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
 		return srv.(APIServer).GetHuntFlows(ctx, req.(*GetTableRequest))
 	}
-	return interceptor(ctx, in, info, handler)
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_GetHuntResults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHuntResultsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetHuntResults(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/GetHuntResults",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetHuntResults(ctx, req.(*GetHuntResultsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_NotifyClients_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NotificationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).NotifyClients(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/NotifyClients",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).NotifyClients(ctx, req.(*NotificationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_LabelClients_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LabelClientsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).LabelClients(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/LabelClients",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).LabelClients(ctx, req.(*LabelClientsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_UninstallClient_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UninstallClientRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).UninstallClient(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/UninstallClient",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).UninstallClient(ctx, req.(*UninstallClientRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_QuarantineClient_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QuarantineClientRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).QuarantineClient(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/QuarantineClient",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).QuarantineClient(ctx, req.(*QuarantineClientRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_UnquarantineClient_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnquarantineClientRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).UnquarantineClient(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/UnquarantineClient",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).UnquarantineClient(ctx, req.(*UnquarantineClientRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_DeleteClient_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteClientRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).DeleteClient(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/DeleteClient",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).DeleteClient(ctx, req.(*DeleteClientRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_ListClients_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchClientsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).ListClients(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/ListClients",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).ListClients(ctx, req.(*SearchClientsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_GetClient_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetClientRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetClient(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/GetClient",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetClient(ctx, req.(*GetClientRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_GetClientMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetClientRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetClientMetadata(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/GetClientMetadata",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetClientMetadata(ctx, req.(*GetClientRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_SetClientMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClientMetadata)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).SetClientMetadata(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/SetClientMetadata",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).SetClientMetadata(ctx, req.(*ClientMetadata))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_GetBandwidthUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BandwidthUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetBandwidthUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/GetBandwidthUsage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetBandwidthUsage(ctx, req.(*BandwidthUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_GetClientFlows_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApiFlowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetClientFlows(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/GetClientFlows",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetClientFlows(ctx, req.(*ApiFlowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_GetUserUITraits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetUserUITraits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/GetUserUITraits",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetUserUITraits(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_SetGUIOptions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetGUIOptionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).SetGUIOptions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/SetGUIOptions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).SetGUIOptions(ctx, req.(*SetGUIOptionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_GetUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/GetUsers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetUsers(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_GetRoles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetRoles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/GetRoles",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetRoles(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_SetRole_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(proto1.Role)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).SetRole(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/SetRole",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).SetRole(ctx, req.(*proto1.Role))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_DeleteRole_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRoleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).DeleteRole(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/DeleteRole",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).DeleteRole(ctx, req.(*DeleteRoleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_CreateApiKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateApiKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).CreateApiKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/CreateApiKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).CreateApiKey(ctx, req.(*CreateApiKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_ListApiKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListApiKeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).ListApiKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/ListApiKeys",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).ListApiKeys(ctx, req.(*ListApiKeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_RevokeApiKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeApiKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).RevokeApiKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/RevokeApiKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).RevokeApiKey(ctx, req.(*RevokeApiKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_GetUserFavorites_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Favorite)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetUserFavorites(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/GetUserFavorites",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetUserFavorites(ctx, req.(*Favorite))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_VFSListDirectory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VFSListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).VFSListDirectory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/VFSListDirectory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).VFSListDirectory(ctx, req.(*VFSListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_VFSRefreshDirectory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VFSRefreshDirectoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).VFSRefreshDirectory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/VFSRefreshDirectory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).VFSRefreshDirectory(ctx, req.(*VFSRefreshDirectoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_VFSStatDirectory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VFSListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).VFSStatDirectory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/VFSStatDirectory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).VFSStatDirectory(ctx, req.(*VFSListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_VFSStatDownload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VFSStatDownloadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).VFSStatDownload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/VFSStatDownload",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).VFSStatDownload(ctx, req.(*VFSStatDownloadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_GetTable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTableRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetTable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/GetTable",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetTable(ctx, req.(*GetTableRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_StreamTable_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetTableRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(APIServer).StreamTable(m, &aPIStreamTableServer{stream})
+}
+
+type API_StreamTableServer interface {
+	Send(*TableChunk) error
+	grpc.ServerStream
+}
+
+type aPIStreamTableServer struct {
+	grpc.ServerStream
+}
+
+func (x *aPIStreamTableServer) Send(m *TableChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _API_GetChartData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetChartDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetChartData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/GetChartData",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetChartData(ctx, req.(*GetChartDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_CollectArtifact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(proto.ArtifactCollectorArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).CollectArtifact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/CollectArtifact",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).CollectArtifact(ctx, req.(*proto.ArtifactCollectorArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_CollectArtifactMulti_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CollectArtifactMultiRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).CollectArtifactMulti(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/CollectArtifactMulti",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).CollectArtifactMulti(ctx, req.(*CollectArtifactMultiRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_CancelFlow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApiFlowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).CancelFlow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/CancelFlow",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).CancelFlow(ctx, req.(*ApiFlowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_GetFlowDetails_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApiFlowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetFlowDetails(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/GetFlowDetails",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetFlowDetails(ctx, req.(*ApiFlowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_GetFlowRequests_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApiFlowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetFlowRequests(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/GetFlowRequests",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetFlowRequests(ctx, req.(*ApiFlowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_ArchiveFlow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApiFlowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).ArchiveFlow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/ArchiveFlow",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).ArchiveFlow(ctx, req.(*ApiFlowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_RehydrateFlow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApiFlowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).RehydrateFlow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/RehydrateFlow",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).RehydrateFlow(ctx, req.(*ApiFlowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_ImportCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportCollectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).ImportCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/ImportCollection",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).ImportCollection(ctx, req.(*ImportCollectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_IngestRows_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(APIServer).IngestRows(&aPIIngestRowsServer{stream})
+}
+
+type API_IngestRowsServer interface {
+	SendAndClose(*FlowDetails) error
+	Recv() (*IngestRowsRequest, error)
+	grpc.ServerStream
+}
+
+type aPIIngestRowsServer struct {
+	grpc.ServerStream
+}
+
+func (x *aPIIngestRowsServer) SendAndClose(m *FlowDetails) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *aPIIngestRowsServer) Recv() (*IngestRowsRequest, error) {
+	m := new(IngestRowsRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
 }
 
-func _API_GetHuntResults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetHuntResultsRequest)
+func _API_SetLegalHold_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LegalHoldRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(APIServer).GetHuntResults(ctx, in)
+		return srv.(APIServer).SetLegalHold(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.API/GetHuntResults",
+		FullMethod: "/proto.API/SetLegalHold",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).GetHuntResults(ctx, req.(*GetHuntResultsRequest))
+		return srv.(APIServer).SetLegalHold(ctx, req.(*LegalHoldRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _API_NotifyClients_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(NotificationRequest)
+func _API_GetLegalHolds_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLegalHoldsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(APIServer).NotifyClients(ctx, in)
+		return srv.(APIServer).GetLegalHolds(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.API/NotifyClients",
+		FullMethod: "/proto.API/GetLegalHolds",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).NotifyClients(ctx, req.(*NotificationRequest))
+		return srv.(APIServer).GetLegalHolds(ctx, req.(*GetLegalHoldsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _API_LabelClients_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(LabelClientsRequest)
+func _API_GetAuditLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAuditLogRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(APIServer).LabelClients(ctx, in)
+		return srv.(APIServer).GetAuditLog(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.API/LabelClients",
+		FullMethod: "/proto.API/GetAuditLog",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).LabelClients(ctx, req.(*LabelClientsRequest))
+		return srv.(APIServer).GetAuditLog(ctx, req.(*GetAuditLogRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _API_ListClients_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(SearchClientsRequest)
+func _API_GetLDAPMappings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(APIServer).ListClients(ctx, in)
+		return srv.(APIServer).GetLDAPMappings(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.API/ListClients",
+		FullMethod: "/proto.API/GetLDAPMappings",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).ListClients(ctx, req.(*SearchClientsRequest))
+		return srv.(APIServer).GetLDAPMappings(ctx, req.(*empty.Empty))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _API_GetClient_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetClientRequest)
+func _API_SetLDAPMappings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LDAPGroupMappings)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(APIServer).GetClient(ctx, in)
+		return srv.(APIServer).SetLDAPMappings(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.API/GetClient",
+		FullMethod: "/proto.API/SetLDAPMappings",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).GetClient(ctx, req.(*GetClientRequest))
+		return srv.(APIServer).SetLDAPMappings(ctx, req.(*LDAPGroupMappings))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _API_GetClientMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetClientRequest)
+func _API_GetApiVersions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(APIServer).GetClientMetadata(ctx, in)
+		return srv.(APIServer).GetApiVersions(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.API/GetClientMetadata",
+		FullMethod: "/proto.API/GetApiVersions",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).GetClientMetadata(ctx, req.(*GetClientRequest))
+		return srv.(APIServer).GetApiVersions(ctx, req.(*empty.Empty))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _API_SetClientMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ClientMetadata)
+func _API_RequestApproval_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Approval)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(APIServer).SetClientMetadata(ctx, in)
+		return srv.(APIServer).RequestApproval(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.API/SetClientMetadata",
+		FullMethod: "/proto.API/RequestApproval",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).SetClientMetadata(ctx, req.(*ClientMetadata))
+		return srv.(APIServer).RequestApproval(ctx, req.(*Approval))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _API_GetClientFlows_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ApiFlowRequest)
+func _API_GrantApproval_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Approval)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(APIServer).GetClientFlows(ctx, in)
+		return srv.(APIServer).GrantApproval(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.API/GetClientFlows",
+		FullMethod: "/proto.API/GrantApproval",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).GetClientFlows(ctx, req.(*ApiFlowRequest))
+		return srv.(APIServer).GrantApproval(ctx, req.(*Approval))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _API_GetUserUITraits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(empty.Empty)
+func _API_GetApprovals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetApprovalsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(APIServer).GetUserUITraits(ctx, in)
+		return srv.(APIServer).GetApprovals(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.API/GetUserUITraits",
+		FullMethod: "/proto.API/GetApprovals",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).GetUserUITraits(ctx, req.(*empty.Empty))
+		return srv.(APIServer).GetApprovals(ctx, req.(*GetApprovalsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _API_SetGUIOptions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(SetGUIOptionsRequest)
+func _API_GetJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(APIServer).SetGUIOptions(ctx, in)
+		return srv.(APIServer).GetJob(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.API/SetGUIOptions",
+		FullMethod: "/proto.API/GetJob",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).SetGUIOptions(ctx, req.(*SetGUIOptionsRequest))
+		return srv.(APIServer).GetJob(ctx, req.(*GetJobRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _API_GetUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(empty.Empty)
+func _API_ListJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListJobsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(APIServer).GetUsers(ctx, in)
+		return srv.(APIServer).ListJobs(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.API/GetUsers",
+		FullMethod: "/proto.API/ListJobs",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).GetUsers(ctx, req.(*empty.Empty))
+		return srv.(APIServer).ListJobs(ctx, req.(*ListJobsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _API_GetUserFavorites_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Favorite)
+func _API_CancelJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(APIServer).GetUserFavorites(ctx, in)
+		return srv.(APIServer).CancelJob(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.API/GetUserFavorites",
+		FullMethod: "/proto.API/CancelJob",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).GetUserFavorites(ctx, req.(*Favorite))
+		return srv.(APIServer).CancelJob(ctx, req.(*GetJobRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _API_VFSListDirectory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(VFSListRequest)
+func _API_SearchFiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchFilesRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(APIServer).VFSListDirectory(ctx, in)
+		return srv.(APIServer).SearchFiles(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.API/VFSListDirectory",
+		FullMethod: "/proto.API/SearchFiles",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).VFSListDirectory(ctx, req.(*VFSListRequest))
+		return srv.(APIServer).SearchFiles(ctx, req.(*SearchFilesRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _API_VFSRefreshDirectory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(VFSRefreshDirectoryRequest)
+func _API_GetKeywordCompletions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(APIServer).VFSRefreshDirectory(ctx, in)
+		return srv.(APIServer).GetKeywordCompletions(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.API/VFSRefreshDirectory",
+		FullMethod: "/proto.API/GetKeywordCompletions",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).VFSRefreshDirectory(ctx, req.(*VFSRefreshDirectoryRequest))
+		return srv.(APIServer).GetKeywordCompletions(ctx, req.(*empty.Empty))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _API_VFSStatDirectory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(VFSListRequest)
+func _API_VQLComplete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VQLLanguageRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(APIServer).VFSStatDirectory(ctx, in)
+		return srv.(APIServer).VQLComplete(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.API/VFSStatDirectory",
+		FullMethod: "/proto.API/VQLComplete",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).VFSStatDirectory(ctx, req.(*VFSListRequest))
+		return srv.(APIServer).VQLComplete(ctx, req.(*VQLLanguageRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _API_VFSStatDownload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(VFSStatDownloadRequest)
+func _API_VQLHover_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VQLLanguageRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(APIServer).VFSStatDownload(ctx, in)
+		return srv.(APIServer).VQLHover(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.API/VFSStatDownload",
+		FullMethod: "/proto.API/VQLHover",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).VFSStatDownload(ctx, req.(*VFSStatDownloadRequest))
+		return srv.(APIServer).VQLHover(ctx, req.(*VQLLanguageRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _API_GetTable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetTableRequest)
+func _API_VQLDiagnose_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VQLLanguageRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(APIServer).GetTable(ctx, in)
+		return srv.(APIServer).VQLDiagnose(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.API/GetTable",
+		FullMethod: "/proto.API/VQLDiagnose",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).GetTable(ctx, req.(*GetTableRequest))
+		return srv.(APIServer).VQLDiagnose(ctx, req.(*VQLLanguageRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _API_CollectArtifact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(proto.ArtifactCollectorArgs)
+func _API_GetArtifacts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetArtifactsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(APIServer).CollectArtifact(ctx, in)
+		return srv.(APIServer).GetArtifacts(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.API/CollectArtifact",
+		FullMethod: "/proto.API/GetArtifacts",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).CollectArtifact(ctx, req.(*proto.ArtifactCollectorArgs))
+		return srv.(APIServer).GetArtifacts(ctx, req.(*GetArtifactsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _API_CancelFlow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ApiFlowRequest)
+func _API_GetArtifactFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetArtifactRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(APIServer).CancelFlow(ctx, in)
+		return srv.(APIServer).GetArtifactFile(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.API/CancelFlow",
+		FullMethod: "/proto.API/GetArtifactFile",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).CancelFlow(ctx, req.(*ApiFlowRequest))
+		return srv.(APIServer).GetArtifactFile(ctx, req.(*GetArtifactRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _API_GetFlowDetails_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ApiFlowRequest)
+func _API_SetArtifactFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetArtifactRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(APIServer).GetFlowDetails(ctx, in)
+		return srv.(APIServer).SetArtifactFile(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.API/GetFlowDetails",
+		FullMethod: "/proto.API/SetArtifactFile",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).GetFlowDetails(ctx, req.(*ApiFlowRequest))
+		return srv.(APIServer).SetArtifactFile(ctx, req.(*SetArtifactRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _API_GetFlowRequests_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ApiFlowRequest)
+func _API_TranslateSigma_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SigmaTranslationRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(APIServer).GetFlowRequests(ctx, in)
+		return srv.(APIServer).TranslateSigma(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.API/GetFlowRequests",
+		FullMethod: "/proto.API/TranslateSigma",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).GetFlowRequests(ctx, req.(*ApiFlowRequest))
+		return srv.(APIServer).TranslateSigma(ctx, req.(*SigmaTranslationRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _API_GetKeywordCompletions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(empty.Empty)
+func _API_GetAttackCoverage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttackCoverageRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(APIServer).GetKeywordCompletions(ctx, in)
+		return srv.(APIServer).GetAttackCoverage(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.API/GetKeywordCompletions",
+		FullMethod: "/proto.API/GetAttackCoverage",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).GetKeywordCompletions(ctx, req.(*empty.Empty))
+		return srv.(APIServer).GetAttackCoverage(ctx, req.(*AttackCoverageRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _API_GetArtifacts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetArtifactsRequest)
+func _API_LoadArtifactPack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VFSFileBuffer)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(APIServer).GetArtifacts(ctx, in)
+		return srv.(APIServer).LoadArtifactPack(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.API/GetArtifacts",
+		FullMethod: "/proto.API/LoadArtifactPack",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).GetArtifacts(ctx, req.(*GetArtifactsRequest))
+		return srv.(APIServer).LoadArtifactPack(ctx, req.(*VFSFileBuffer))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _API_GetArtifactFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetArtifactRequest)
+func _API_ListExchangeArtifacts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExchangeArtifactsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(APIServer).GetArtifactFile(ctx, in)
+		return srv.(APIServer).ListExchangeArtifacts(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.API/GetArtifactFile",
+		FullMethod: "/proto.API/ListExchangeArtifacts",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).GetArtifactFile(ctx, req.(*GetArtifactRequest))
+		return srv.(APIServer).ListExchangeArtifacts(ctx, req.(*ExchangeArtifactsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _API_SetArtifactFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(SetArtifactRequest)
+func _API_InstallExchangeArtifact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InstallExchangeArtifactRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(APIServer).SetArtifactFile(ctx, in)
+		return srv.(APIServer).InstallExchangeArtifact(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.API/SetArtifactFile",
+		FullMethod: "/proto.API/InstallExchangeArtifact",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).SetArtifactFile(ctx, req.(*SetArtifactRequest))
+		return srv.(APIServer).InstallExchangeArtifact(ctx, req.(*InstallExchangeArtifactRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _API_LoadArtifactPack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(VFSFileBuffer)
+func _API_CheckExchangeUpdates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExchangeArtifactsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(APIServer).LoadArtifactPack(ctx, in)
+		return srv.(APIServer).CheckExchangeUpdates(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.API/LoadArtifactPack",
+		FullMethod: "/proto.API/CheckExchangeUpdates",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).LoadArtifactPack(ctx, req.(*VFSFileBuffer))
+		return srv.(APIServer).CheckExchangeUpdates(ctx, req.(*ExchangeArtifactsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
 func _API_GetToolInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(proto1.Tool)
+	in := new(proto2.Tool)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -1587,13 +2971,13 @@ func _API_GetToolInfo_Handler(srv interface{}, ctx context.Context, dec func(int
 		FullMethod: "/proto.API/GetToolInfo",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).GetToolInfo(ctx, req.(*proto1.Tool))
+		return srv.(APIServer).GetToolInfo(ctx, req.(*proto2.Tool))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
 func _API_SetToolInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(proto1.Tool)
+	in := new(proto2.Tool)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -1605,7 +2989,7 @@ func _API_SetToolInfo_Handler(srv interface{}, ctx context.Context, dec func(int
 		FullMethod: "/proto.API/SetToolInfo",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).SetToolInfo(ctx, req.(*proto1.Tool))
+		return srv.(APIServer).SetToolInfo(ctx, req.(*proto2.Tool))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -1700,6 +3084,24 @@ func _API_SetClientMonitoringState_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _API_GetComplianceReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetComplianceReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.API/GetComplianceReport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetComplianceReport(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _API_ListAvailableEventResults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListAvailableEventResultsRequest)
 	if err := dec(in); err != nil {
@@ -1917,7 +3319,7 @@ func _API_VFSGetBuffer_Handler(srv interface{}, ctx context.Context, dec func(in
 }
 
 func _API_Query_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(proto2.VQLCollectorArgs)
+	m := new(proto3.VQLCollectorArgs)
 	if err := stream.RecvMsg(m); err != nil {
 		return err
 	}
@@ -1925,7 +3327,7 @@ func _API_Query_Handler(srv interface{}, stream grpc.ServerStream) error {
 }
 
 type API_QueryServer interface {
-	Send(*proto2.VQLResponse) error
+	Send(*proto3.VQLResponse) error
 	grpc.ServerStream
 }
 
@@ -1933,7 +3335,7 @@ type aPIQueryServer struct {
 	grpc.ServerStream
 }
 
-func (x *aPIQueryServer) Send(m *proto2.VQLResponse) error {
+func (x *aPIQueryServer) Send(m *proto3.VQLResponse) error {
 	return x.ServerStream.SendMsg(m)
 }
 
@@ -1977,7 +3379,7 @@ func _API_PushEvents_Handler(srv interface{}, ctx context.Context, dec func(inte
 }
 
 func _API_WriteEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(proto2.VQLResponse)
+	in := new(proto3.VQLResponse)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -1989,7 +3391,7 @@ func _API_WriteEvent_Handler(srv interface{}, ctx context.Context, dec func(inte
 		FullMethod: "/proto.API/WriteEvent",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).WriteEvent(ctx, req.(*proto2.VQLResponse))
+		return srv.(APIServer).WriteEvent(ctx, req.(*proto3.VQLResponse))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -2127,6 +3529,22 @@ var API_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "LabelClients",
 			Handler:    _API_LabelClients_Handler,
 		},
+		{
+			MethodName: "UninstallClient",
+			Handler:    _API_UninstallClient_Handler,
+		},
+		{
+			MethodName: "QuarantineClient",
+			Handler:    _API_QuarantineClient_Handler,
+		},
+		{
+			MethodName: "UnquarantineClient",
+			Handler:    _API_UnquarantineClient_Handler,
+		},
+		{
+			MethodName: "DeleteClient",
+			Handler:    _API_DeleteClient_Handler,
+		},
 		{
 			MethodName: "ListClients",
 			Handler:    _API_ListClients_Handler,
@@ -2143,6 +3561,10 @@ var API_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SetClientMetadata",
 			Handler:    _API_SetClientMetadata_Handler,
 		},
+		{
+			MethodName: "GetBandwidthUsage",
+			Handler:    _API_GetBandwidthUsage_Handler,
+		},
 		{
 			MethodName: "GetClientFlows",
 			Handler:    _API_GetClientFlows_Handler,
@@ -2159,6 +3581,30 @@ var API_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetUsers",
 			Handler:    _API_GetUsers_Handler,
 		},
+		{
+			MethodName: "GetRoles",
+			Handler:    _API_GetRoles_Handler,
+		},
+		{
+			MethodName: "SetRole",
+			Handler:    _API_SetRole_Handler,
+		},
+		{
+			MethodName: "DeleteRole",
+			Handler:    _API_DeleteRole_Handler,
+		},
+		{
+			MethodName: "CreateApiKey",
+			Handler:    _API_CreateApiKey_Handler,
+		},
+		{
+			MethodName: "ListApiKeys",
+			Handler:    _API_ListApiKeys_Handler,
+		},
+		{
+			MethodName: "RevokeApiKey",
+			Handler:    _API_RevokeApiKey_Handler,
+		},
 		{
 			MethodName: "GetUserFavorites",
 			Handler:    _API_GetUserFavorites_Handler,
@@ -2183,10 +3629,18 @@ var API_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetTable",
 			Handler:    _API_GetTable_Handler,
 		},
+		{
+			MethodName: "GetChartData",
+			Handler:    _API_GetChartData_Handler,
+		},
 		{
 			MethodName: "CollectArtifact",
 			Handler:    _API_CollectArtifact_Handler,
 		},
+		{
+			MethodName: "CollectArtifactMulti",
+			Handler:    _API_CollectArtifactMulti_Handler,
+		},
 		{
 			MethodName: "CancelFlow",
 			Handler:    _API_CancelFlow_Handler,
@@ -2199,10 +3653,86 @@ var API_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetFlowRequests",
 			Handler:    _API_GetFlowRequests_Handler,
 		},
+		{
+			MethodName: "ArchiveFlow",
+			Handler:    _API_ArchiveFlow_Handler,
+		},
+		{
+			MethodName: "RehydrateFlow",
+			Handler:    _API_RehydrateFlow_Handler,
+		},
+		{
+			MethodName: "ImportCollection",
+			Handler:    _API_ImportCollection_Handler,
+		},
+		{
+			MethodName: "SetLegalHold",
+			Handler:    _API_SetLegalHold_Handler,
+		},
+		{
+			MethodName: "GetLegalHolds",
+			Handler:    _API_GetLegalHolds_Handler,
+		},
+		{
+			MethodName: "GetAuditLog",
+			Handler:    _API_GetAuditLog_Handler,
+		},
+		{
+			MethodName: "GetLDAPMappings",
+			Handler:    _API_GetLDAPMappings_Handler,
+		},
+		{
+			MethodName: "SetLDAPMappings",
+			Handler:    _API_SetLDAPMappings_Handler,
+		},
+		{
+			MethodName: "GetApiVersions",
+			Handler:    _API_GetApiVersions_Handler,
+		},
+		{
+			MethodName: "RequestApproval",
+			Handler:    _API_RequestApproval_Handler,
+		},
+		{
+			MethodName: "GrantApproval",
+			Handler:    _API_GrantApproval_Handler,
+		},
+		{
+			MethodName: "GetApprovals",
+			Handler:    _API_GetApprovals_Handler,
+		},
+		{
+			MethodName: "GetJob",
+			Handler:    _API_GetJob_Handler,
+		},
+		{
+			MethodName: "ListJobs",
+			Handler:    _API_ListJobs_Handler,
+		},
+		{
+			MethodName: "CancelJob",
+			Handler:    _API_CancelJob_Handler,
+		},
+		{
+			MethodName: "SearchFiles",
+			Handler:    _API_SearchFiles_Handler,
+		},
 		{
 			MethodName: "GetKeywordCompletions",
 			Handler:    _API_GetKeywordCompletions_Handler,
 		},
+		{
+			MethodName: "VQLComplete",
+			Handler:    _API_VQLComplete_Handler,
+		},
+		{
+			MethodName: "VQLHover",
+			Handler:    _API_VQLHover_Handler,
+		},
+		{
+			MethodName: "VQLDiagnose",
+			Handler:    _API_VQLDiagnose_Handler,
+		},
 		{
 			MethodName: "GetArtifacts",
 			Handler:    _API_GetArtifacts_Handler,
@@ -2215,10 +3745,30 @@ var API_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SetArtifactFile",
 			Handler:    _API_SetArtifactFile_Handler,
 		},
+		{
+			MethodName: "TranslateSigma",
+			Handler:    _API_TranslateSigma_Handler,
+		},
+		{
+			MethodName: "GetAttackCoverage",
+			Handler:    _API_GetAttackCoverage_Handler,
+		},
 		{
 			MethodName: "LoadArtifactPack",
 			Handler:    _API_LoadArtifactPack_Handler,
 		},
+		{
+			MethodName: "ListExchangeArtifacts",
+			Handler:    _API_ListExchangeArtifacts_Handler,
+		},
+		{
+			MethodName: "InstallExchangeArtifact",
+			Handler:    _API_InstallExchangeArtifact_Handler,
+		},
+		{
+			MethodName: "CheckExchangeUpdates",
+			Handler:    _API_CheckExchangeUpdates_Handler,
+		},
 		{
 			MethodName: "GetToolInfo",
 			Handler:    _API_GetToolInfo_Handler,
@@ -2247,6 +3797,10 @@ var API_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SetClientMonitoringState",
 			Handler:    _API_SetClientMonitoringState_Handler,
 		},
+		{
+			MethodName: "GetComplianceReport",
+			Handler:    _API_GetComplianceReport_Handler,
+		},
 		{
 			MethodName: "ListAvailableEventResults",
 			Handler:    _API_ListAvailableEventResults_Handler,
@@ -2325,6 +3879,16 @@ var API_ServiceDesc = grpc.ServiceDesc{
 		},
 	},
 	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamTable",
+			Handler:       _API_StreamTable_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "IngestRows",
+			Handler:       _API_IngestRows_Handler,
+			ClientStreams: true,
+		},
 		{
 			StreamName:    "Query",
 			Handler:       _API_Query_Handler,
@@ -1121,6 +1121,201 @@ func (x *SetMonitoringStateRequest) GetRequest() *proto1.ArtifactCollectorArgs {
 	return nil
 }
 
+type SigmaTranslationRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// One or more Sigma rules as YAML documents.
+	Pack string `protobuf:"bytes,1,opt,name=pack,proto3" json:"pack,omitempty"`
+	// Prefix for the generated artifact names (default
+	// Custom.Sigma.)
+	Prefix string `protobuf:"bytes,2,opt,name=prefix,proto3" json:"prefix,omitempty"`
+}
+
+func (x *SigmaTranslationRequest) Reset() {
+	*x = SigmaTranslationRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_artifacts_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SigmaTranslationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SigmaTranslationRequest) ProtoMessage() {}
+
+func (x *SigmaTranslationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_artifacts_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SigmaTranslationRequest.ProtoReflect.Descriptor instead.
+func (*SigmaTranslationRequest) Descriptor() ([]byte, []int) {
+	return file_artifacts_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *SigmaTranslationRequest) GetPack() string {
+	if x != nil {
+		return x.Pack
+	}
+	return ""
+}
+
+func (x *SigmaTranslationRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+type SigmaTranslation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Title string `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	Id    string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	// The generated artifact. Empty if the rule could not be
+	// translated.
+	Name     string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Artifact string `protobuf:"bytes,4,opt,name=artifact,proto3" json:"artifact,omitempty"`
+	// Parts of the rule which were approximated.
+	Warnings []string `protobuf:"bytes,5,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	Error    string   `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *SigmaTranslation) Reset() {
+	*x = SigmaTranslation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_artifacts_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SigmaTranslation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SigmaTranslation) ProtoMessage() {}
+
+func (x *SigmaTranslation) ProtoReflect() protoreflect.Message {
+	mi := &file_artifacts_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SigmaTranslation.ProtoReflect.Descriptor instead.
+func (*SigmaTranslation) Descriptor() ([]byte, []int) {
+	return file_artifacts_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *SigmaTranslation) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *SigmaTranslation) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SigmaTranslation) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SigmaTranslation) GetArtifact() string {
+	if x != nil {
+		return x.Artifact
+	}
+	return ""
+}
+
+func (x *SigmaTranslation) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *SigmaTranslation) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type SigmaTranslationResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Items []*SigmaTranslation `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+}
+
+func (x *SigmaTranslationResponse) Reset() {
+	*x = SigmaTranslationResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_artifacts_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SigmaTranslationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SigmaTranslationResponse) ProtoMessage() {}
+
+func (x *SigmaTranslationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_artifacts_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SigmaTranslationResponse.ProtoReflect.Descriptor instead.
+func (*SigmaTranslationResponse) Descriptor() ([]byte, []int) {
+	return file_artifacts_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SigmaTranslationResponse) GetItems() []*SigmaTranslation {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
 var File_artifacts_proto protoreflect.FileDescriptor
 
 var file_artifacts_proto_rawDesc = []byte{
@@ -1292,10 +1487,29 @@ var file_artifacts_proto_rawDesc = []byte{
 	0x71, 0x75, 0x65, 0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x70, 0x72,
 	0x6f, 0x74, 0x6f, 0x2e, 0x41, 0x72, 0x74, 0x69, 0x66, 0x61, 0x63, 0x74, 0x43, 0x6f, 0x6c, 0x6c,
 	0x65, 0x63, 0x74, 0x6f, 0x72, 0x41, 0x72, 0x67, 0x73, 0x52, 0x07, 0x72, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x42, 0x31, 0x5a, 0x2f, 0x77, 0x77, 0x77, 0x2e, 0x76, 0x65, 0x6c, 0x6f, 0x63, 0x69,
-	0x64, 0x65, 0x78, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x6f, 0x6c, 0x61, 0x6e, 0x67, 0x2f, 0x76,
-	0x65, 0x6c, 0x6f, 0x63, 0x69, 0x72, 0x61, 0x70, 0x74, 0x6f, 0x72, 0x2f, 0x61, 0x70, 0x69, 0x2f,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x73, 0x74, 0x22, 0x45, 0x0a, 0x17, 0x53, 0x69, 0x67, 0x6d, 0x61, 0x54, 0x72, 0x61, 0x6e, 0x73,
+	0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a,
+	0x04, 0x70, 0x61, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x63,
+	0x6b, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x22, 0x9a, 0x01, 0x0a, 0x10, 0x53, 0x69,
+	0x67, 0x6d, 0x61, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x14,
+	0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74,
+	0x69, 0x74, 0x6c, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x72, 0x74, 0x69,
+	0x66, 0x61, 0x63, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x61, 0x72, 0x74, 0x69,
+	0x66, 0x61, 0x63, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x77, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x73,
+	0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x77, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x73,
+	0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x49, 0x0a, 0x18, 0x53, 0x69, 0x67, 0x6d, 0x61, 0x54,
+	0x72, 0x61, 0x6e, 0x73, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x2d, 0x0a, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x17, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x53, 0x69, 0x67, 0x6d, 0x61, 0x54,
+	0x72, 0x61, 0x6e, 0x73, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d,
+	0x73, 0x42, 0x31, 0x5a, 0x2f, 0x77, 0x77, 0x77, 0x2e, 0x76, 0x65, 0x6c, 0x6f, 0x63, 0x69, 0x64,
+	0x65, 0x78, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x6f, 0x6c, 0x61, 0x6e, 0x67, 0x2f, 0x76, 0x65,
+	0x6c, 0x6f, 0x63, 0x69, 0x72, 0x61, 0x70, 0x74, 0x6f, 0x72, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1311,7 +1525,7 @@ func file_artifacts_proto_rawDescGZIP() []byte {
 }
 
 var file_artifacts_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_artifacts_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_artifacts_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
 var file_artifacts_proto_goTypes = []interface{}{
 	(SetArtifactRequest_Operation)(0),         // 0: proto.SetArtifactRequest.Operation
 	(*FieldSelector)(nil),                     // 1: proto.FieldSelector
@@ -1331,24 +1545,28 @@ var file_artifacts_proto_goTypes = []interface{}{
 	(*GetMonitoringStateRequest)(nil),         // 15: proto.GetMonitoringStateRequest
 	(*GetMonitoringStateResponse)(nil),        // 16: proto.GetMonitoringStateResponse
 	(*SetMonitoringStateRequest)(nil),         // 17: proto.SetMonitoringStateRequest
-	(*proto.ArtifactParameter)(nil),           // 18: proto.ArtifactParameter
-	(*proto.Artifact)(nil),                    // 19: proto.Artifact
-	(*proto1.ArtifactCollectorArgs)(nil),      // 20: proto.ArtifactCollectorArgs
+	(*SigmaTranslationRequest)(nil),           // 18: proto.SigmaTranslationRequest
+	(*SigmaTranslation)(nil),                  // 19: proto.SigmaTranslation
+	(*SigmaTranslationResponse)(nil),          // 20: proto.SigmaTranslationResponse
+	(*proto.ArtifactParameter)(nil),           // 21: proto.ArtifactParameter
+	(*proto.Artifact)(nil),                    // 22: proto.Artifact
+	(*proto1.ArtifactCollectorArgs)(nil),      // 23: proto.ArtifactCollectorArgs
 }
 var file_artifacts_proto_depIdxs = []int32{
 	1,  // 0: proto.GetArtifactsRequest.fields:type_name -> proto.FieldSelector
 	0,  // 1: proto.SetArtifactRequest.op:type_name -> proto.SetArtifactRequest.Operation
 	6,  // 2: proto.LoadArtifactPackResponse.errors:type_name -> proto.LoadArtifactError
-	18, // 3: proto.GetReportRequest.parameters:type_name -> proto.ArtifactParameter
-	19, // 4: proto.AvailableEvent.definition:type_name -> proto.Artifact
+	21, // 3: proto.GetReportRequest.parameters:type_name -> proto.ArtifactParameter
+	22, // 4: proto.AvailableEvent.definition:type_name -> proto.Artifact
 	13, // 5: proto.ListAvailableEventResultsResponse.logs:type_name -> proto.AvailableEvent
 	17, // 6: proto.GetMonitoringStateResponse.requests:type_name -> proto.SetMonitoringStateRequest
-	20, // 7: proto.SetMonitoringStateRequest.request:type_name -> proto.ArtifactCollectorArgs
-	8,  // [8:8] is the sub-list for method output_type
-	8,  // [8:8] is the sub-list for method input_type
-	8,  // [8:8] is the sub-list for extension type_name
-	8,  // [8:8] is the sub-list for extension extendee
-	0,  // [0:8] is the sub-list for field type_name
+	23, // 7: proto.SetMonitoringStateRequest.request:type_name -> proto.ArtifactCollectorArgs
+	19, // 8: proto.SigmaTranslationResponse.items:type_name -> proto.SigmaTranslation
+	9,  // [9:9] is the sub-list for method output_type
+	9,  // [9:9] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_artifacts_proto_init() }
@@ -1561,6 +1779,42 @@ func file_artifacts_proto_init() {
 				return nil
 			}
 		}
+		file_artifacts_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SigmaTranslationRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_artifacts_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SigmaTranslation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_artifacts_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SigmaTranslationResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -1568,7 +1822,7 @@ func file_artifacts_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_artifacts_proto_rawDesc,
 			NumEnums:      1,
-			NumMessages:   17,
+			NumMessages:   20,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
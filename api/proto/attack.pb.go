@@ -0,0 +1,399 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        v3.12.4
+// source: attack.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AttackCoverageRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Only hunts created within this many days are considered
+	// (default 30).
+	HuntDays uint64 `protobuf:"varint,1,opt,name=hunt_days,json=huntDays,proto3" json:"hunt_days,omitempty"`
+}
+
+func (x *AttackCoverageRequest) Reset() {
+	*x = AttackCoverageRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attack_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AttackCoverageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttackCoverageRequest) ProtoMessage() {}
+
+func (x *AttackCoverageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_attack_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttackCoverageRequest.ProtoReflect.Descriptor instead.
+func (*AttackCoverageRequest) Descriptor() ([]byte, []int) {
+	return file_attack_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AttackCoverageRequest) GetHuntDays() uint64 {
+	if x != nil {
+		return x.HuntDays
+	}
+	return 0
+}
+
+// Coverage of a single ATT&CK technique by the artifacts tagged with
+// it.
+type AttackTechniqueCoverage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Technique string `protobuf:"bytes,1,opt,name=technique,proto3" json:"technique,omitempty"`
+	// All artifacts tagged with the technique.
+	Artifacts []string `protobuf:"bytes,2,rep,name=artifacts,proto3" json:"artifacts,omitempty"`
+	// Detection coverage: tagged event artifacts currently deployed
+	// in the client or server monitoring tables.
+	MonitoringArtifacts []string `protobuf:"bytes,3,rep,name=monitoring_artifacts,json=monitoringArtifacts,proto3" json:"monitoring_artifacts,omitempty"`
+	MonitoredLabels     []string `protobuf:"bytes,4,rep,name=monitored_labels,json=monitoredLabels,proto3" json:"monitored_labels,omitempty"`
+	MonitoredClients    uint64   `protobuf:"varint,5,opt,name=monitored_clients,json=monitoredClients,proto3" json:"monitored_clients,omitempty"`
+	ServerMonitored     bool     `protobuf:"varint,6,opt,name=server_monitored,json=serverMonitored,proto3" json:"server_monitored,omitempty"`
+	// Collection coverage: recent hunts collecting tagged artifacts.
+	Hunts         []string `protobuf:"bytes,7,rep,name=hunts,proto3" json:"hunts,omitempty"`
+	HuntedClients uint64   `protobuf:"varint,8,opt,name=hunted_clients,json=huntedClients,proto3" json:"hunted_clients,omitempty"`
+	// Percentage of the fleet covered by monitoring or hunting.
+	DetectionCoverage  float64 `protobuf:"fixed64,9,opt,name=detection_coverage,json=detectionCoverage,proto3" json:"detection_coverage,omitempty"`
+	CollectionCoverage float64 `protobuf:"fixed64,10,opt,name=collection_coverage,json=collectionCoverage,proto3" json:"collection_coverage,omitempty"`
+}
+
+func (x *AttackTechniqueCoverage) Reset() {
+	*x = AttackTechniqueCoverage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attack_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AttackTechniqueCoverage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttackTechniqueCoverage) ProtoMessage() {}
+
+func (x *AttackTechniqueCoverage) ProtoReflect() protoreflect.Message {
+	mi := &file_attack_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttackTechniqueCoverage.ProtoReflect.Descriptor instead.
+func (*AttackTechniqueCoverage) Descriptor() ([]byte, []int) {
+	return file_attack_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AttackTechniqueCoverage) GetTechnique() string {
+	if x != nil {
+		return x.Technique
+	}
+	return ""
+}
+
+func (x *AttackTechniqueCoverage) GetArtifacts() []string {
+	if x != nil {
+		return x.Artifacts
+	}
+	return nil
+}
+
+func (x *AttackTechniqueCoverage) GetMonitoringArtifacts() []string {
+	if x != nil {
+		return x.MonitoringArtifacts
+	}
+	return nil
+}
+
+func (x *AttackTechniqueCoverage) GetMonitoredLabels() []string {
+	if x != nil {
+		return x.MonitoredLabels
+	}
+	return nil
+}
+
+func (x *AttackTechniqueCoverage) GetMonitoredClients() uint64 {
+	if x != nil {
+		return x.MonitoredClients
+	}
+	return 0
+}
+
+func (x *AttackTechniqueCoverage) GetServerMonitored() bool {
+	if x != nil {
+		return x.ServerMonitored
+	}
+	return false
+}
+
+func (x *AttackTechniqueCoverage) GetHunts() []string {
+	if x != nil {
+		return x.Hunts
+	}
+	return nil
+}
+
+func (x *AttackTechniqueCoverage) GetHuntedClients() uint64 {
+	if x != nil {
+		return x.HuntedClients
+	}
+	return 0
+}
+
+func (x *AttackTechniqueCoverage) GetDetectionCoverage() float64 {
+	if x != nil {
+		return x.DetectionCoverage
+	}
+	return 0
+}
+
+func (x *AttackTechniqueCoverage) GetCollectionCoverage() float64 {
+	if x != nil {
+		return x.CollectionCoverage
+	}
+	return 0
+}
+
+type AttackCoverage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Timestamp    uint64                     `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	TotalClients uint64                     `protobuf:"varint,2,opt,name=total_clients,json=totalClients,proto3" json:"total_clients,omitempty"`
+	Techniques   []*AttackTechniqueCoverage `protobuf:"bytes,3,rep,name=techniques,proto3" json:"techniques,omitempty"`
+}
+
+func (x *AttackCoverage) Reset() {
+	*x = AttackCoverage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attack_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AttackCoverage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttackCoverage) ProtoMessage() {}
+
+func (x *AttackCoverage) ProtoReflect() protoreflect.Message {
+	mi := &file_attack_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttackCoverage.ProtoReflect.Descriptor instead.
+func (*AttackCoverage) Descriptor() ([]byte, []int) {
+	return file_attack_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AttackCoverage) GetTimestamp() uint64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *AttackCoverage) GetTotalClients() uint64 {
+	if x != nil {
+		return x.TotalClients
+	}
+	return 0
+}
+
+func (x *AttackCoverage) GetTechniques() []*AttackTechniqueCoverage {
+	if x != nil {
+		return x.Techniques
+	}
+	return nil
+}
+
+var File_attack_proto protoreflect.FileDescriptor
+
+var file_attack_proto_rawDesc = []byte{
+	0x0a, 0x0c, 0x61, 0x74, 0x74, 0x61, 0x63, 0x6b, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x05,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x34, 0x0a, 0x15, 0x41, 0x74, 0x74, 0x61, 0x63, 0x6b, 0x43,
+	0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b,
+	0x0a, 0x09, 0x68, 0x75, 0x6e, 0x74, 0x5f, 0x64, 0x61, 0x79, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x08, 0x68, 0x75, 0x6e, 0x74, 0x44, 0x61, 0x79, 0x73, 0x22, 0xa8, 0x03, 0x0a, 0x17,
+	0x41, 0x74, 0x74, 0x61, 0x63, 0x6b, 0x54, 0x65, 0x63, 0x68, 0x6e, 0x69, 0x71, 0x75, 0x65, 0x43,
+	0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x65, 0x63, 0x68, 0x6e,
+	0x69, 0x71, 0x75, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x74, 0x65, 0x63, 0x68,
+	0x6e, 0x69, 0x71, 0x75, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x72, 0x74, 0x69, 0x66, 0x61, 0x63,
+	0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x61, 0x72, 0x74, 0x69, 0x66, 0x61,
+	0x63, 0x74, 0x73, 0x12, 0x31, 0x0a, 0x14, 0x6d, 0x6f, 0x6e, 0x69, 0x74, 0x6f, 0x72, 0x69, 0x6e,
+	0x67, 0x5f, 0x61, 0x72, 0x74, 0x69, 0x66, 0x61, 0x63, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x13, 0x6d, 0x6f, 0x6e, 0x69, 0x74, 0x6f, 0x72, 0x69, 0x6e, 0x67, 0x41, 0x72, 0x74,
+	0x69, 0x66, 0x61, 0x63, 0x74, 0x73, 0x12, 0x29, 0x0a, 0x10, 0x6d, 0x6f, 0x6e, 0x69, 0x74, 0x6f,
+	0x72, 0x65, 0x64, 0x5f, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x0f, 0x6d, 0x6f, 0x6e, 0x69, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x4c, 0x61, 0x62, 0x65, 0x6c,
+	0x73, 0x12, 0x2b, 0x0a, 0x11, 0x6d, 0x6f, 0x6e, 0x69, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x5f, 0x63,
+	0x6c, 0x69, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x10, 0x6d, 0x6f,
+	0x6e, 0x69, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x29,
+	0x0a, 0x10, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x6d, 0x6f, 0x6e, 0x69, 0x74, 0x6f, 0x72,
+	0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72,
+	0x4d, 0x6f, 0x6e, 0x69, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x68, 0x75, 0x6e,
+	0x74, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x68, 0x75, 0x6e, 0x74, 0x73, 0x12,
+	0x25, 0x0a, 0x0e, 0x68, 0x75, 0x6e, 0x74, 0x65, 0x64, 0x5f, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0d, 0x68, 0x75, 0x6e, 0x74, 0x65, 0x64, 0x43,
+	0x6c, 0x69, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x2d, 0x0a, 0x12, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x5f, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x18, 0x09, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x11, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x76,
+	0x65, 0x72, 0x61, 0x67, 0x65, 0x12, 0x2f, 0x0a, 0x13, 0x63, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x5f, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x18, 0x0a, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x12, 0x63, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x6f,
+	0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x22, 0x93, 0x01, 0x0a, 0x0e, 0x41, 0x74, 0x74, 0x61, 0x63,
+	0x6b, 0x43, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x6f, 0x74, 0x61, 0x6c,
+	0x5f, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c,
+	0x74, 0x6f, 0x74, 0x61, 0x6c, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x3e, 0x0a, 0x0a,
+	0x74, 0x65, 0x63, 0x68, 0x6e, 0x69, 0x71, 0x75, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x1e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x41, 0x74, 0x74, 0x61, 0x63, 0x6b, 0x54,
+	0x65, 0x63, 0x68, 0x6e, 0x69, 0x71, 0x75, 0x65, 0x43, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65,
+	0x52, 0x0a, 0x74, 0x65, 0x63, 0x68, 0x6e, 0x69, 0x71, 0x75, 0x65, 0x73, 0x42, 0x31, 0x5a, 0x2f,
+	0x77, 0x77, 0x77, 0x2e, 0x76, 0x65, 0x6c, 0x6f, 0x63, 0x69, 0x64, 0x65, 0x78, 0x2e, 0x63, 0x6f,
+	0x6d, 0x2f, 0x67, 0x6f, 0x6c, 0x61, 0x6e, 0x67, 0x2f, 0x76, 0x65, 0x6c, 0x6f, 0x63, 0x69, 0x72,
+	0x61, 0x70, 0x74, 0x6f, 0x72, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_attack_proto_rawDescOnce sync.Once
+	file_attack_proto_rawDescData = file_attack_proto_rawDesc
+)
+
+func file_attack_proto_rawDescGZIP() []byte {
+	file_attack_proto_rawDescOnce.Do(func() {
+		file_attack_proto_rawDescData = protoimpl.X.CompressGZIP(file_attack_proto_rawDescData)
+	})
+	return file_attack_proto_rawDescData
+}
+
+var file_attack_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_attack_proto_goTypes = []interface{}{
+	(*AttackCoverageRequest)(nil),   // 0: proto.AttackCoverageRequest
+	(*AttackTechniqueCoverage)(nil), // 1: proto.AttackTechniqueCoverage
+	(*AttackCoverage)(nil),          // 2: proto.AttackCoverage
+}
+var file_attack_proto_depIdxs = []int32{
+	1, // 0: proto.AttackCoverage.techniques:type_name -> proto.AttackTechniqueCoverage
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_attack_proto_init() }
+func file_attack_proto_init() {
+	if File_attack_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_attack_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AttackCoverageRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attack_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AttackTechniqueCoverage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attack_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AttackCoverage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_attack_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_attack_proto_goTypes,
+		DependencyIndexes: file_attack_proto_depIdxs,
+		MessageInfos:      file_attack_proto_msgTypes,
+	}.Build()
+	File_attack_proto = out.File
+	file_attack_proto_rawDesc = nil
+	file_attack_proto_goTypes = nil
+	file_attack_proto_depIdxs = nil
+}
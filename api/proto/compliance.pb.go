@@ -0,0 +1,382 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        v3.12.4
+// source: compliance.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// A client which has not collected all the required artifacts in
+// time.
+type ComplianceStraggler struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ClientId         string   `protobuf:"bytes,1,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	Hostname         string   `protobuf:"bytes,2,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	MissingArtifacts []string `protobuf:"bytes,3,rep,name=missing_artifacts,json=missingArtifacts,proto3" json:"missing_artifacts,omitempty"`
+	LastSeenAt       uint64   `protobuf:"varint,4,opt,name=last_seen_at,json=lastSeenAt,proto3" json:"last_seen_at,omitempty"`
+}
+
+func (x *ComplianceStraggler) Reset() {
+	*x = ComplianceStraggler{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_compliance_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ComplianceStraggler) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComplianceStraggler) ProtoMessage() {}
+
+func (x *ComplianceStraggler) ProtoReflect() protoreflect.Message {
+	mi := &file_compliance_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComplianceStraggler.ProtoReflect.Descriptor instead.
+func (*ComplianceStraggler) Descriptor() ([]byte, []int) {
+	return file_compliance_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ComplianceStraggler) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *ComplianceStraggler) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *ComplianceStraggler) GetMissingArtifacts() []string {
+	if x != nil {
+		return x.MissingArtifacts
+	}
+	return nil
+}
+
+func (x *ComplianceStraggler) GetLastSeenAt() uint64 {
+	if x != nil {
+		return x.LastSeenAt
+	}
+	return 0
+}
+
+type ComplianceCoverage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Label            string   `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
+	Artifacts        []string `protobuf:"bytes,2,rep,name=artifacts,proto3" json:"artifacts,omitempty"`
+	IntervalSeconds  uint64   `protobuf:"varint,3,opt,name=interval_seconds,json=intervalSeconds,proto3" json:"interval_seconds,omitempty"`
+	TotalClients     uint64   `protobuf:"varint,4,opt,name=total_clients,json=totalClients,proto3" json:"total_clients,omitempty"`
+	CompliantClients uint64   `protobuf:"varint,5,opt,name=compliant_clients,json=compliantClients,proto3" json:"compliant_clients,omitempty"`
+	// Percentage of clients which collected all the artifacts in
+	// time.
+	Coverage   float64                `protobuf:"fixed64,6,opt,name=coverage,proto3" json:"coverage,omitempty"`
+	Stragglers []*ComplianceStraggler `protobuf:"bytes,7,rep,name=stragglers,proto3" json:"stragglers,omitempty"`
+}
+
+func (x *ComplianceCoverage) Reset() {
+	*x = ComplianceCoverage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_compliance_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ComplianceCoverage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComplianceCoverage) ProtoMessage() {}
+
+func (x *ComplianceCoverage) ProtoReflect() protoreflect.Message {
+	mi := &file_compliance_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComplianceCoverage.ProtoReflect.Descriptor instead.
+func (*ComplianceCoverage) Descriptor() ([]byte, []int) {
+	return file_compliance_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ComplianceCoverage) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *ComplianceCoverage) GetArtifacts() []string {
+	if x != nil {
+		return x.Artifacts
+	}
+	return nil
+}
+
+func (x *ComplianceCoverage) GetIntervalSeconds() uint64 {
+	if x != nil {
+		return x.IntervalSeconds
+	}
+	return 0
+}
+
+func (x *ComplianceCoverage) GetTotalClients() uint64 {
+	if x != nil {
+		return x.TotalClients
+	}
+	return 0
+}
+
+func (x *ComplianceCoverage) GetCompliantClients() uint64 {
+	if x != nil {
+		return x.CompliantClients
+	}
+	return 0
+}
+
+func (x *ComplianceCoverage) GetCoverage() float64 {
+	if x != nil {
+		return x.Coverage
+	}
+	return 0
+}
+
+func (x *ComplianceCoverage) GetStragglers() []*ComplianceStraggler {
+	if x != nil {
+		return x.Stragglers
+	}
+	return nil
+}
+
+type ComplianceReport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Timestamp uint64                `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Policies  []*ComplianceCoverage `protobuf:"bytes,2,rep,name=policies,proto3" json:"policies,omitempty"`
+}
+
+func (x *ComplianceReport) Reset() {
+	*x = ComplianceReport{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_compliance_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ComplianceReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComplianceReport) ProtoMessage() {}
+
+func (x *ComplianceReport) ProtoReflect() protoreflect.Message {
+	mi := &file_compliance_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComplianceReport.ProtoReflect.Descriptor instead.
+func (*ComplianceReport) Descriptor() ([]byte, []int) {
+	return file_compliance_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ComplianceReport) GetTimestamp() uint64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *ComplianceReport) GetPolicies() []*ComplianceCoverage {
+	if x != nil {
+		return x.Policies
+	}
+	return nil
+}
+
+var File_compliance_proto protoreflect.FileDescriptor
+
+var file_compliance_proto_rawDesc = []byte{
+	0x0a, 0x10, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x12, 0x05, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x9d, 0x01, 0x0a, 0x13, 0x43, 0x6f,
+	0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x53, 0x74, 0x72, 0x61, 0x67, 0x67, 0x6c, 0x65,
+	0x72, 0x12, 0x1b, 0x0a, 0x09, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x1a,
+	0x0a, 0x08, 0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x2b, 0x0a, 0x11, 0x6d, 0x69,
+	0x73, 0x73, 0x69, 0x6e, 0x67, 0x5f, 0x61, 0x72, 0x74, 0x69, 0x66, 0x61, 0x63, 0x74, 0x73, 0x18,
+	0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x10, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x41, 0x72,
+	0x74, 0x69, 0x66, 0x61, 0x63, 0x74, 0x73, 0x12, 0x20, 0x0a, 0x0c, 0x6c, 0x61, 0x73, 0x74, 0x5f,
+	0x73, 0x65, 0x65, 0x6e, 0x5f, 0x61, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x6c,
+	0x61, 0x73, 0x74, 0x53, 0x65, 0x65, 0x6e, 0x41, 0x74, 0x22, 0x9d, 0x02, 0x0a, 0x12, 0x43, 0x6f,
+	0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x43, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65,
+	0x12, 0x14, 0x0a, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x72, 0x74, 0x69, 0x66, 0x61,
+	0x63, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x61, 0x72, 0x74, 0x69, 0x66,
+	0x61, 0x63, 0x74, 0x73, 0x12, 0x29, 0x0a, 0x10, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c,
+	0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0f,
+	0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x12,
+	0x23, 0x0a, 0x0d, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x73,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x43, 0x6c, 0x69,
+	0x65, 0x6e, 0x74, 0x73, 0x12, 0x2b, 0x0a, 0x11, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e,
+	0x74, 0x5f, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x10, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x74, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x73, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x08, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x12, 0x3a, 0x0a,
+	0x0a, 0x73, 0x74, 0x72, 0x61, 0x67, 0x67, 0x6c, 0x65, 0x72, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x1a, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x69,
+	0x61, 0x6e, 0x63, 0x65, 0x53, 0x74, 0x72, 0x61, 0x67, 0x67, 0x6c, 0x65, 0x72, 0x52, 0x0a, 0x73,
+	0x74, 0x72, 0x61, 0x67, 0x67, 0x6c, 0x65, 0x72, 0x73, 0x22, 0x67, 0x0a, 0x10, 0x43, 0x6f, 0x6d,
+	0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x1c, 0x0a,
+	0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x35, 0x0a, 0x08, 0x70,
+	0x6f, 0x6c, 0x69, 0x63, 0x69, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65,
+	0x43, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x52, 0x08, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x69,
+	0x65, 0x73, 0x42, 0x31, 0x5a, 0x2f, 0x77, 0x77, 0x77, 0x2e, 0x76, 0x65, 0x6c, 0x6f, 0x63, 0x69,
+	0x64, 0x65, 0x78, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x6f, 0x6c, 0x61, 0x6e, 0x67, 0x2f, 0x76,
+	0x65, 0x6c, 0x6f, 0x63, 0x69, 0x72, 0x61, 0x70, 0x74, 0x6f, 0x72, 0x2f, 0x61, 0x70, 0x69, 0x2f,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_compliance_proto_rawDescOnce sync.Once
+	file_compliance_proto_rawDescData = file_compliance_proto_rawDesc
+)
+
+func file_compliance_proto_rawDescGZIP() []byte {
+	file_compliance_proto_rawDescOnce.Do(func() {
+		file_compliance_proto_rawDescData = protoimpl.X.CompressGZIP(file_compliance_proto_rawDescData)
+	})
+	return file_compliance_proto_rawDescData
+}
+
+var file_compliance_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_compliance_proto_goTypes = []interface{}{
+	(*ComplianceStraggler)(nil), // 0: proto.ComplianceStraggler
+	(*ComplianceCoverage)(nil),  // 1: proto.ComplianceCoverage
+	(*ComplianceReport)(nil),    // 2: proto.ComplianceReport
+}
+var file_compliance_proto_depIdxs = []int32{
+	0, // 0: proto.ComplianceCoverage.stragglers:type_name -> proto.ComplianceStraggler
+	1, // 1: proto.ComplianceReport.policies:type_name -> proto.ComplianceCoverage
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_compliance_proto_init() }
+func file_compliance_proto_init() {
+	if File_compliance_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_compliance_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ComplianceStraggler); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_compliance_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ComplianceCoverage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_compliance_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ComplianceReport); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_compliance_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_compliance_proto_goTypes,
+		DependencyIndexes: file_compliance_proto_depIdxs,
+		MessageInfos:      file_compliance_proto_msgTypes,
+	}.Build()
+	File_compliance_proto = out.File
+	file_compliance_proto_rawDesc = nil
+	file_compliance_proto_goTypes = nil
+	file_compliance_proto_depIdxs = nil
+}
@@ -0,0 +1,372 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        v3.12.4
+// source: errors.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ApiErrorDetails_ErrorCode int32
+
+const (
+	ApiErrorDetails_UNKNOWN          ApiErrorDetails_ErrorCode = 0
+	ApiErrorDetails_CLIENT_NOT_FOUND ApiErrorDetails_ErrorCode = 1
+	// e.g. cancelling a flow which is not running.
+	ApiErrorDetails_FLOW_INVALID_STATE ApiErrorDetails_ErrorCode = 2
+	// The user is missing a permission an artifact requires or
+	// the artifact is not allowed by policy.
+	ApiErrorDetails_PERMISSION_DENIED_ARTIFACT ApiErrorDetails_ErrorCode = 3
+	ApiErrorDetails_QUOTA_EXCEEDED             ApiErrorDetails_ErrorCode = 4
+	// Malformed ids are rejected before the request is handled.
+	ApiErrorDetails_INVALID_CLIENT_ID ApiErrorDetails_ErrorCode = 5
+	ApiErrorDetails_INVALID_FLOW_ID   ApiErrorDetails_ErrorCode = 6
+	// The client asked for an API version the server does not
+	// serve.
+	ApiErrorDetails_UNSUPPORTED_API_VERSION ApiErrorDetails_ErrorCode = 7
+	// The artifact needs capabilities the client does not
+	// advertise (e.g. a low privilege client).
+	ApiErrorDetails_CLIENT_CAPABILITY_MISSING ApiErrorDetails_ErrorCode = 8
+	ApiErrorDetails_FLOW_NOT_FOUND            ApiErrorDetails_ErrorCode = 9
+	// The datastore could not be read - the request may be
+	// retried later.
+	ApiErrorDetails_DATASTORE_UNAVAILABLE ApiErrorDetails_ErrorCode = 10
+	// The user is missing a permission the API call requires.
+	ApiErrorDetails_PERMISSION_DENIED ApiErrorDetails_ErrorCode = 11
+	// The structured client search filters could not be parsed.
+	ApiErrorDetails_INVALID_SEARCH_FILTER ApiErrorDetails_ErrorCode = 12
+)
+
+// Enum value maps for ApiErrorDetails_ErrorCode.
+var (
+	ApiErrorDetails_ErrorCode_name = map[int32]string{
+		0:  "UNKNOWN",
+		1:  "CLIENT_NOT_FOUND",
+		2:  "FLOW_INVALID_STATE",
+		3:  "PERMISSION_DENIED_ARTIFACT",
+		4:  "QUOTA_EXCEEDED",
+		5:  "INVALID_CLIENT_ID",
+		6:  "INVALID_FLOW_ID",
+		7:  "UNSUPPORTED_API_VERSION",
+		8:  "CLIENT_CAPABILITY_MISSING",
+		9:  "FLOW_NOT_FOUND",
+		10: "DATASTORE_UNAVAILABLE",
+		11: "PERMISSION_DENIED",
+		12: "INVALID_SEARCH_FILTER",
+	}
+	ApiErrorDetails_ErrorCode_value = map[string]int32{
+		"UNKNOWN":                    0,
+		"CLIENT_NOT_FOUND":           1,
+		"FLOW_INVALID_STATE":         2,
+		"PERMISSION_DENIED_ARTIFACT": 3,
+		"QUOTA_EXCEEDED":             4,
+		"INVALID_CLIENT_ID":          5,
+		"INVALID_FLOW_ID":            6,
+		"UNSUPPORTED_API_VERSION":    7,
+		"CLIENT_CAPABILITY_MISSING":  8,
+		"FLOW_NOT_FOUND":             9,
+		"DATASTORE_UNAVAILABLE":      10,
+		"PERMISSION_DENIED":          11,
+		"INVALID_SEARCH_FILTER":      12,
+	}
+)
+
+func (x ApiErrorDetails_ErrorCode) Enum() *ApiErrorDetails_ErrorCode {
+	p := new(ApiErrorDetails_ErrorCode)
+	*p = x
+	return p
+}
+
+func (x ApiErrorDetails_ErrorCode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ApiErrorDetails_ErrorCode) Descriptor() protoreflect.EnumDescriptor {
+	return file_errors_proto_enumTypes[0].Descriptor()
+}
+
+func (ApiErrorDetails_ErrorCode) Type() protoreflect.EnumType {
+	return &file_errors_proto_enumTypes[0]
+}
+
+func (x ApiErrorDetails_ErrorCode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ApiErrorDetails_ErrorCode.Descriptor instead.
+func (ApiErrorDetails_ErrorCode) EnumDescriptor() ([]byte, []int) {
+	return file_errors_proto_rawDescGZIP(), []int{0, 0}
+}
+
+// Automation should rely on these codes rather than parsing error
+// messages. They are attached to the details of the gRPC status.
+type ApiErrorDetails struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ErrorCode ApiErrorDetails_ErrorCode `protobuf:"varint,1,opt,name=error_code,json=errorCode,proto3,enum=proto.ApiErrorDetails_ErrorCode" json:"error_code,omitempty"`
+	// The untranslated message format and its args so the message
+	// can be localized for the user.
+	MessageFormat string   `protobuf:"bytes,2,opt,name=message_format,json=messageFormat,proto3" json:"message_format,omitempty"`
+	MessageArgs   []string `protobuf:"bytes,3,rep,name=message_args,json=messageArgs,proto3" json:"message_args,omitempty"`
+}
+
+func (x *ApiErrorDetails) Reset() {
+	*x = ApiErrorDetails{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_errors_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ApiErrorDetails) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApiErrorDetails) ProtoMessage() {}
+
+func (x *ApiErrorDetails) ProtoReflect() protoreflect.Message {
+	mi := &file_errors_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApiErrorDetails.ProtoReflect.Descriptor instead.
+func (*ApiErrorDetails) Descriptor() ([]byte, []int) {
+	return file_errors_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ApiErrorDetails) GetErrorCode() ApiErrorDetails_ErrorCode {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ApiErrorDetails_UNKNOWN
+}
+
+func (x *ApiErrorDetails) GetMessageFormat() string {
+	if x != nil {
+		return x.MessageFormat
+	}
+	return ""
+}
+
+func (x *ApiErrorDetails) GetMessageArgs() []string {
+	if x != nil {
+		return x.MessageArgs
+	}
+	return nil
+}
+
+// The body of error responses from the HTTP gateway.
+type ApiErrorResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The gRPC status code.
+	Code int32 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	// The ErrorCode in lower case (e.g. client_not_found).
+	ErrorCode string `protobuf:"bytes,2,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	Message   string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+}
+
+func (x *ApiErrorResponse) Reset() {
+	*x = ApiErrorResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_errors_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ApiErrorResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApiErrorResponse) ProtoMessage() {}
+
+func (x *ApiErrorResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_errors_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApiErrorResponse.ProtoReflect.Descriptor instead.
+func (*ApiErrorResponse) Descriptor() ([]byte, []int) {
+	return file_errors_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ApiErrorResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *ApiErrorResponse) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+func (x *ApiErrorResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+var File_errors_proto protoreflect.FileDescriptor
+
+var file_errors_proto_rawDesc = []byte{
+	0x0a, 0x0c, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x05,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xe2, 0x03, 0x0a, 0x0f, 0x41, 0x70, 0x69, 0x45, 0x72, 0x72,
+	0x6f, 0x72, 0x44, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x73, 0x12, 0x3f, 0x0a, 0x0a, 0x65, 0x72, 0x72,
+	0x6f, 0x72, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x20, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x41, 0x70, 0x69, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x44, 0x65,
+	0x74, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x43, 0x6f, 0x64, 0x65, 0x52,
+	0x09, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x25, 0x0a, 0x0e, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x5f, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0d, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x46, 0x6f, 0x72, 0x6d, 0x61,
+	0x74, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x5f, 0x61, 0x72, 0x67,
+	0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x41, 0x72, 0x67, 0x73, 0x22, 0xc3, 0x02, 0x0a, 0x09, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x43, 0x6f,
+	0x64, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12,
+	0x14, 0x0a, 0x10, 0x43, 0x4c, 0x49, 0x45, 0x4e, 0x54, 0x5f, 0x4e, 0x4f, 0x54, 0x5f, 0x46, 0x4f,
+	0x55, 0x4e, 0x44, 0x10, 0x01, 0x12, 0x16, 0x0a, 0x12, 0x46, 0x4c, 0x4f, 0x57, 0x5f, 0x49, 0x4e,
+	0x56, 0x41, 0x4c, 0x49, 0x44, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x45, 0x10, 0x02, 0x12, 0x1e, 0x0a,
+	0x1a, 0x50, 0x45, 0x52, 0x4d, 0x49, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x5f, 0x44, 0x45, 0x4e, 0x49,
+	0x45, 0x44, 0x5f, 0x41, 0x52, 0x54, 0x49, 0x46, 0x41, 0x43, 0x54, 0x10, 0x03, 0x12, 0x12, 0x0a,
+	0x0e, 0x51, 0x55, 0x4f, 0x54, 0x41, 0x5f, 0x45, 0x58, 0x43, 0x45, 0x45, 0x44, 0x45, 0x44, 0x10,
+	0x04, 0x12, 0x15, 0x0a, 0x11, 0x49, 0x4e, 0x56, 0x41, 0x4c, 0x49, 0x44, 0x5f, 0x43, 0x4c, 0x49,
+	0x45, 0x4e, 0x54, 0x5f, 0x49, 0x44, 0x10, 0x05, 0x12, 0x13, 0x0a, 0x0f, 0x49, 0x4e, 0x56, 0x41,
+	0x4c, 0x49, 0x44, 0x5f, 0x46, 0x4c, 0x4f, 0x57, 0x5f, 0x49, 0x44, 0x10, 0x06, 0x12, 0x1b, 0x0a,
+	0x17, 0x55, 0x4e, 0x53, 0x55, 0x50, 0x50, 0x4f, 0x52, 0x54, 0x45, 0x44, 0x5f, 0x41, 0x50, 0x49,
+	0x5f, 0x56, 0x45, 0x52, 0x53, 0x49, 0x4f, 0x4e, 0x10, 0x07, 0x12, 0x1d, 0x0a, 0x19, 0x43, 0x4c,
+	0x49, 0x45, 0x4e, 0x54, 0x5f, 0x43, 0x41, 0x50, 0x41, 0x42, 0x49, 0x4c, 0x49, 0x54, 0x59, 0x5f,
+	0x4d, 0x49, 0x53, 0x53, 0x49, 0x4e, 0x47, 0x10, 0x08, 0x12, 0x12, 0x0a, 0x0e, 0x46, 0x4c, 0x4f,
+	0x57, 0x5f, 0x4e, 0x4f, 0x54, 0x5f, 0x46, 0x4f, 0x55, 0x4e, 0x44, 0x10, 0x09, 0x12, 0x19, 0x0a,
+	0x15, 0x44, 0x41, 0x54, 0x41, 0x53, 0x54, 0x4f, 0x52, 0x45, 0x5f, 0x55, 0x4e, 0x41, 0x56, 0x41,
+	0x49, 0x4c, 0x41, 0x42, 0x4c, 0x45, 0x10, 0x0a, 0x12, 0x15, 0x0a, 0x11, 0x50, 0x45, 0x52, 0x4d,
+	0x49, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x5f, 0x44, 0x45, 0x4e, 0x49, 0x45, 0x44, 0x10, 0x0b, 0x12,
+	0x19, 0x0a, 0x15, 0x49, 0x4e, 0x56, 0x41, 0x4c, 0x49, 0x44, 0x5f, 0x53, 0x45, 0x41, 0x52, 0x43,
+	0x48, 0x5f, 0x46, 0x49, 0x4c, 0x54, 0x45, 0x52, 0x10, 0x0c, 0x22, 0x5f, 0x0a, 0x10, 0x41, 0x70,
+	0x69, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12,
+	0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x63, 0x6f,
+	0x64, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x63, 0x6f, 0x64, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x43, 0x6f, 0x64,
+	0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x42, 0x31, 0x5a, 0x2f, 0x77,
+	0x77, 0x77, 0x2e, 0x76, 0x65, 0x6c, 0x6f, 0x63, 0x69, 0x64, 0x65, 0x78, 0x2e, 0x63, 0x6f, 0x6d,
+	0x2f, 0x67, 0x6f, 0x6c, 0x61, 0x6e, 0x67, 0x2f, 0x76, 0x65, 0x6c, 0x6f, 0x63, 0x69, 0x72, 0x61,
+	0x70, 0x74, 0x6f, 0x72, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_errors_proto_rawDescOnce sync.Once
+	file_errors_proto_rawDescData = file_errors_proto_rawDesc
+)
+
+func file_errors_proto_rawDescGZIP() []byte {
+	file_errors_proto_rawDescOnce.Do(func() {
+		file_errors_proto_rawDescData = protoimpl.X.CompressGZIP(file_errors_proto_rawDescData)
+	})
+	return file_errors_proto_rawDescData
+}
+
+var file_errors_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_errors_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_errors_proto_goTypes = []interface{}{
+	(ApiErrorDetails_ErrorCode)(0), // 0: proto.ApiErrorDetails.ErrorCode
+	(*ApiErrorDetails)(nil),        // 1: proto.ApiErrorDetails
+	(*ApiErrorResponse)(nil),       // 2: proto.ApiErrorResponse
+}
+var file_errors_proto_depIdxs = []int32{
+	0, // 0: proto.ApiErrorDetails.error_code:type_name -> proto.ApiErrorDetails.ErrorCode
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_errors_proto_init() }
+func file_errors_proto_init() {
+	if File_errors_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_errors_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ApiErrorDetails); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_errors_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ApiErrorResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_errors_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_errors_proto_goTypes,
+		DependencyIndexes: file_errors_proto_depIdxs,
+		EnumInfos:         file_errors_proto_enumTypes,
+		MessageInfos:      file_errors_proto_msgTypes,
+	}.Build()
+	File_errors_proto = out.File
+	file_errors_proto_rawDesc = nil
+	file_errors_proto_goTypes = nil
+	file_errors_proto_depIdxs = nil
+}
@@ -41,6 +41,7 @@ import (
 	file_store "www.velocidex.com/golang/velociraptor/file_store"
 	"www.velocidex.com/golang/velociraptor/grpc_client"
 	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/scim"
 )
 
 // A Mux for the reverse proxy feature.
@@ -108,7 +109,10 @@ func PrepareGUIMux(
 		return nil, errors.New("GUI not configured")
 	}
 
-	h, err := GetAPIHandler(ctx, config_obj)
+	// The gateway and the handlers below share the same buckets.
+	limiter := newRateLimiter(ctx, config_obj)
+
+	h, err := getAPIHandler(ctx, config_obj, limiter)
 	if err != nil {
 		return nil, err
 	}
@@ -137,24 +141,45 @@ func PrepareGUIMux(
 
 	base := config_obj.GUI.BasePath
 
-	mux.Handle(base+"/api/", csrfProtect(config_obj,
-		auther.AuthenticateUserHandler(h)))
+	// API endpoints also accept API keys.
+	api_handler := func(h http.Handler) http.Handler {
+		return apiKeyHandler(config_obj, csrfProtect(config_obj,
+			auther.AuthenticateUserHandler(h)), h)
+	}
 
-	mux.Handle(base+"/api/v1/DownloadTable", csrfProtect(config_obj,
-		auther.AuthenticateUserHandler(
-			downloadTable(config_obj))))
+	limited_handler := func(h http.Handler) http.Handler {
+		return api_handler(rateLimitHandler(limiter, h))
+	}
 
-	mux.Handle(base+"/api/v1/DownloadVFSFile", csrfProtect(config_obj,
-		auther.AuthenticateUserHandler(
-			vfsFileDownloadHandler(config_obj))))
+	mux.Handle(base+"/api/", api_handler(h))
 
-	mux.Handle(base+"/api/v1/UploadTool", csrfProtect(config_obj,
-		auther.AuthenticateUserHandler(
-			toolUploadHandler(config_obj))))
+	for _, version := range api_versions {
+		prefix := base + version.Prefix
 
-	mux.Handle(base+"/api/v1/UploadFormFile", csrfProtect(config_obj,
-		auther.AuthenticateUserHandler(
-			formUploadHandler(config_obj))))
+		mux.Handle(prefix+"DownloadTable",
+			limited_handler(downloadTable(config_obj)))
+
+		mux.Handle(prefix+"GetTable",
+			api_handler(tableFormatHandler(config_obj, limiter, h)))
+
+		mux.Handle(prefix+"DownloadVFSFile",
+			limited_handler(vfsFileDownloadHandler(config_obj)))
+
+		mux.Handle(prefix+"DownloadVFSFile/",
+			limited_handler(vfsFileDownloadHandler(config_obj)))
+
+		mux.Handle(prefix+"UploadTool",
+			limited_handler(toolUploadHandler(config_obj)))
+
+		mux.Handle(prefix+"UploadFormFile",
+			limited_handler(formUploadHandler(config_obj)))
+
+		mux.Handle(prefix+"Watch",
+			limited_handler(watchHandler(config_obj)))
+
+		mux.Handle(prefix+"openapi.json",
+			api_handler(openAPIHandler(config_obj, version)))
+	}
 
 	// Serve prepared zip files.
 	mux.Handle(base+"/downloads/", csrfProtect(config_obj,
@@ -192,6 +217,12 @@ func PrepareGUIMux(
 					file_store.GetFileStore(config_obj),
 					"/clients/")))))))
 
+	// Identity providers authenticate to the SCIM endpoint with a
+	// bearer token.
+	if config_obj.Scim != nil && config_obj.Scim.BearerToken != "" {
+		mux.Handle(base+"/scim/v2/", scim.NewHandler(config_obj, base+"/scim/v2/"))
+	}
+
 	// Assets etc do not need auth.
 	install_static_assets(config_obj, mux)
 
@@ -220,6 +251,13 @@ func PrepareGUIMux(
 func GetAPIHandler(
 	ctx context.Context,
 	config_obj *config_proto.Config) (http.Handler, error) {
+	return getAPIHandler(ctx, config_obj, newRateLimiter(ctx, config_obj))
+}
+
+func getAPIHandler(
+	ctx context.Context,
+	config_obj *config_proto.Config,
+	limiter *rateLimiter) (http.Handler, error) {
 
 	if config_obj.Client == nil ||
 		config_obj.GUI == nil ||
@@ -252,6 +290,7 @@ func GetAPIHandler(
 
 				return metadata.New(md)
 			}),
+		runtime.WithErrorHandler(errorHandler),
 	)
 
 	// We use a dedicated gw certificate. The gRPC server will
@@ -303,11 +342,16 @@ func GetAPIHandler(
 
 	base := config_obj.GUI.BasePath
 
+	// Every version is served from the same gateway so integrations
+	// keep working while clients move to newer versions.
 	reverse_proxy_mux := http.NewServeMux()
-	reverse_proxy_mux.Handle(base+"/api/v1/",
-		http.StripPrefix(base, grpc_proxy_mux))
+	for _, version := range api_versions {
+		reverse_proxy_mux.Handle(base+version.Prefix,
+			http.StripPrefix(base, versionHandler(version.Version,
+				rateLimitHandler(limiter, grpc_proxy_mux))))
+	}
 
-	return reverse_proxy_mux, nil
+	return compressHandler(config_obj, reverse_proxy_mux), nil
 }
 
 // Force mime type to binary stream.
@@ -0,0 +1,375 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"www.velocidex.com/golang/velociraptor/acls"
+	actions_proto "www.velocidex.com/golang/velociraptor/actions/proto"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+const (
+	WINDOWS_QUARANTINE_ARTIFACT = "Windows.Remediation.Quarantine"
+	LINUX_QUARANTINE_ARTIFACT   = "Linux.Remediation.Quarantine"
+
+	// The artifacts test this rule to verify the client can still
+	// reach the server.
+	FRONTEND_RULE_DESCRIPTION = "VelociraptorFrontEnd"
+)
+
+var (
+	quarantineAddressRegex = regexp.MustCompile(`^[a-zA-Z0-9.:_/-]+$`)
+)
+
+// A single permit or block entry in the quarantine policy.
+type quarantineRule struct {
+	Action  string
+	Address string
+	Port    string
+
+	// Only used by the Windows policy which filters in both
+	// directions using the same rule.
+	SourcePort string
+
+	Protocol    string
+	Description string
+}
+
+// Build the policy on the server so it is the same for all the
+// endpoints: Name resolution, address assignment, the frontends and
+// any additional allowed destinations are permitted and everything
+// else is blocked.
+func quarantineRules(
+	config_obj *config_proto.Config,
+	allowed_addresses []string) ([]*quarantineRule, error) {
+
+	result := []*quarantineRule{
+		{Action: "Permit", Address: "any", Port: "53",
+			Protocol: "udp", Description: "DNS"},
+		{Action: "Permit", Address: "any", Port: "53",
+			Protocol: "tcp", Description: "DNS TCP"},
+		{Action: "Permit", Address: "any", Port: "67", SourcePort: "68",
+			Protocol: "udp", Description: "DHCP"},
+	}
+
+	if config_obj.Client != nil {
+		for _, server_url := range config_obj.Client.ServerUrls {
+			parsed, err := url.Parse(server_url)
+			if err != nil || parsed.Hostname() == "" {
+				return nil, fmt.Errorf("Invalid server url %v", server_url)
+			}
+
+			port := parsed.Port()
+			if port == "" {
+				port = "443"
+				if parsed.Scheme == "http" {
+					port = "80"
+				}
+			}
+
+			result = append(result, &quarantineRule{
+				Action:      "Permit",
+				Address:     parsed.Hostname(),
+				Port:        port,
+				Protocol:    "tcp",
+				Description: FRONTEND_RULE_DESCRIPTION,
+			})
+		}
+	}
+
+	for _, allowed := range allowed_addresses {
+		rule, err := parseAllowedAddress(allowed)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, rule)
+	}
+
+	return append(result, &quarantineRule{
+		Action: "Block", Address: "any", Description: "All other traffic",
+	}), nil
+}
+
+// Allowed addresses are an address, hostname or network, optionally
+// followed by a tcp port.
+func parseAllowedAddress(allowed string) (*quarantineRule, error) {
+	result := &quarantineRule{
+		Action:      "Permit",
+		Address:     allowed,
+		Description: "Allowed " + allowed,
+	}
+
+	host, port, err := net.SplitHostPort(allowed)
+	if err == nil {
+		port_number, err := strconv.ParseUint(port, 10, 16)
+		if err != nil || port_number == 0 {
+			return nil, fmt.Errorf("Invalid port in allowed address %q", allowed)
+		}
+		result.Address = host
+		result.Port = port
+		result.Protocol = "tcp"
+	}
+
+	if result.Address == "" || result.Address == "any" ||
+		!quarantineAddressRegex.MatchString(result.Address) {
+		return nil, fmt.Errorf("Invalid allowed address %q", allowed)
+	}
+
+	_, network, err := net.ParseCIDR(result.Address)
+	if err == nil {
+		result.Address = network.String()
+	}
+
+	return result, nil
+}
+
+// The Windows artifact adds the frontends itself using the client's
+// configuration.
+func windowsRuleTable(rules []*quarantineRule) string {
+	rows := [][]string{{"Action", "SrcAddr", "SrcMask", "SrcPort",
+		"DstAddr", "DstMask", "DstPort", "Protocol", "Mirrored", "Description"}}
+
+	for _, rule := range rules {
+		if rule.Description == FRONTEND_RULE_DESCRIPTION {
+			continue
+		}
+
+		if rule.Action == "Block" {
+			rows = append(rows, []string{rule.Action, "any", "", "",
+				rule.Address, "", "", "", "yes", rule.Description})
+			continue
+		}
+
+		src_port := rule.SourcePort
+		protocol := rule.Protocol
+		if protocol == "" {
+			protocol = "any"
+		} else if src_port == "" {
+			src_port = "0"
+		}
+
+		address, mask := rule.Address, ""
+		_, network, err := net.ParseCIDR(rule.Address)
+		if err == nil {
+			address = network.IP.String()
+			mask = net.IP(network.Mask).String()
+		}
+
+		rows = append(rows, []string{rule.Action, "me", "", src_port,
+			address, mask, rule.Port, protocol, "yes", rule.Description})
+	}
+
+	return formatCSV(rows)
+}
+
+func linuxRuleTable(rules []*quarantineRule) string {
+	rows := [][]string{{"Action", "Address", "Port", "Protocol", "Description"}}
+
+	for _, rule := range rules {
+		rows = append(rows, []string{rule.Action, rule.Address, rule.Port,
+			rule.Protocol, rule.Description})
+	}
+
+	return formatCSV(rows)
+}
+
+func formatCSV(rows [][]string) string {
+	buf := &bytes.Buffer{}
+	writer := csv.NewWriter(buf)
+	_ = writer.WriteAll(rows)
+	return buf.String()
+}
+
+// Pick the quarantine artifact for the client's platform.
+func quarantineArtifact(
+	config_obj *config_proto.Config, client_id string) (string, error) {
+
+	client_info_manager, err := services.GetClientInfoManager(config_obj)
+	if err != nil {
+		return "", err
+	}
+
+	client_info, err := client_info_manager.Get(client_id)
+	if err != nil {
+		return "", status.Error(codes.NotFound, "Client not found.")
+	}
+
+	switch client_info.OS() {
+	case services.Windows:
+		return WINDOWS_QUARANTINE_ARTIFACT, nil
+	case services.Linux:
+		return LINUX_QUARANTINE_ARTIFACT, nil
+	}
+
+	return "", status.Errorf(codes.FailedPrecondition,
+		"Quarantine is not supported on %q clients.", client_info.System)
+}
+
+// Isolate the endpoint from the network. The policy still allows the
+// client to reach the server so it can be investigated and released.
+func (self *ApiServer) QuarantineClient(
+	ctx context.Context,
+	in *api_proto.QuarantineClientRequest) (*flows_proto.ArtifactCollectorResponse, error) {
+
+	defer Instrument("QuarantineClient")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.ClientId == "" || in.ClientId == "server" {
+		return nil, status.Error(codes.InvalidArgument,
+			"A client id is required.")
+	}
+
+	user_name := user_record.Name
+	permissions := acls.LABEL_CLIENT
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to quarantine clients.")
+	}
+
+	artifact, err := quarantineArtifact(org_config_obj, in.ClientId)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := quarantineRules(org_config_obj, in.AllowedAddresses)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	env := []*actions_proto.VQLEnv{}
+	switch artifact {
+	case WINDOWS_QUARANTINE_ARTIFACT:
+		env = append(env, &actions_proto.VQLEnv{
+			Key: "RuleLookupTable", Value: windowsRuleTable(rules)})
+		if in.Message != "" {
+			env = append(env, &actions_proto.VQLEnv{
+				Key: "MessageBox", Value: in.Message})
+		}
+
+	default:
+		env = append(env, &actions_proto.VQLEnv{
+			Key: "RuleLookupTable", Value: linuxRuleTable(rules)})
+	}
+
+	// CollectArtifact checks the remaining permissions and any
+	// approvals needed for the artifact.
+	result, err := self.CollectArtifact(ctx, &flows_proto.ArtifactCollectorArgs{
+		ClientId:  in.ClientId,
+		Artifacts: []string{artifact},
+		Specs: []*flows_proto.ArtifactSpec{{
+			Artifact:   artifact,
+			Parameters: &flows_proto.ArtifactParameters{Env: env},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The label also enables the quarantine monitoring artifacts.
+	labeler := services.GetLabeler(org_config_obj)
+	err = labeler.SetClientLabel(org_config_obj, in.ClientId,
+		services.QUARANTINE_LABEL)
+	if err != nil {
+		return nil, err
+	}
+	self.cache.InvalidateClient(org_config_obj, in.ClientId)
+
+	logging.GetLogger(org_config_obj, &logging.Audit).
+		WithFields(logrus.Fields{
+			"user":    user_name,
+			"client":  in.ClientId,
+			"flow_id": result.FlowId,
+			"allowed": in.AllowedAddresses,
+			"reason":  in.Reason,
+		}).Info("QuarantineClient")
+
+	return result, nil
+}
+
+// Remove the quarantine policy from the endpoint.
+func (self *ApiServer) UnquarantineClient(
+	ctx context.Context,
+	in *api_proto.UnquarantineClientRequest) (*flows_proto.ArtifactCollectorResponse, error) {
+
+	defer Instrument("UnquarantineClient")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.ClientId == "" || in.ClientId == "server" {
+		return nil, status.Error(codes.InvalidArgument,
+			"A client id is required.")
+	}
+
+	user_name := user_record.Name
+	permissions := acls.LABEL_CLIENT
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to unquarantine clients.")
+	}
+
+	artifact, err := quarantineArtifact(org_config_obj, in.ClientId)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := self.CollectArtifact(ctx, &flows_proto.ArtifactCollectorArgs{
+		ClientId:  in.ClientId,
+		Artifacts: []string{artifact},
+		Specs: []*flows_proto.ArtifactSpec{{
+			Artifact: artifact,
+			Parameters: &flows_proto.ArtifactParameters{
+				Env: []*actions_proto.VQLEnv{{
+					Key:   "RemovePolicy",
+					Value: "Y",
+				}},
+			},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Otherwise the monitoring artifacts reapply the policy.
+	labeler := services.GetLabeler(org_config_obj)
+	err = labeler.RemoveClientLabel(org_config_obj, in.ClientId,
+		services.QUARANTINE_LABEL)
+	if err != nil {
+		return nil, err
+	}
+	self.cache.InvalidateClient(org_config_obj, in.ClientId)
+
+	logging.GetLogger(org_config_obj, &logging.Audit).
+		WithFields(logrus.Fields{
+			"user":    user_name,
+			"client":  in.ClientId,
+			"flow_id": result.FlowId,
+			"reason":  in.Reason,
+		}).Info("UnquarantineClient")
+
+	return result, nil
+}
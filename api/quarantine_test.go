@@ -0,0 +1,59 @@
+package api
+
+import (
+	"strings"
+	"testing"
+
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/vtesting/assert"
+)
+
+func TestQuarantineRules(t *testing.T) {
+	config_obj := &config_proto.Config{
+		Client: &config_proto.ClientConfig{
+			ServerUrls: []string{
+				"https://velo.example.com:8000/",
+				"https://backup.example.com/",
+			},
+		},
+	}
+
+	rules, err := quarantineRules(config_obj, []string{
+		"10.1.2.3", "192.168.0.0/16", "intranet.example.com:8443"})
+	assert.NoError(t, err)
+
+	linux := linuxRuleTable(rules)
+	assert.Equal(t, `Action,Address,Port,Protocol,Description
+Permit,any,53,udp,DNS
+Permit,any,53,tcp,DNS TCP
+Permit,any,67,udp,DHCP
+Permit,velo.example.com,8000,tcp,VelociraptorFrontEnd
+Permit,backup.example.com,443,tcp,VelociraptorFrontEnd
+Permit,10.1.2.3,,,Allowed 10.1.2.3
+Permit,192.168.0.0/16,,,Allowed 192.168.0.0/16
+Permit,intranet.example.com,8443,tcp,Allowed intranet.example.com:8443
+Block,any,,,All other traffic
+`, linux)
+
+	// The Windows artifact adds the frontends itself.
+	windows := windowsRuleTable(rules)
+	assert.Equal(t, `Action,SrcAddr,SrcMask,SrcPort,DstAddr,DstMask,DstPort,Protocol,Mirrored,Description
+Permit,me,,0,any,,53,udp,yes,DNS
+Permit,me,,0,any,,53,tcp,yes,DNS TCP
+Permit,me,,68,any,,67,udp,yes,DHCP
+Permit,me,,,10.1.2.3,,,any,yes,Allowed 10.1.2.3
+Permit,me,,,192.168.0.0,255.255.0.0,,any,yes,Allowed 192.168.0.0/16
+Permit,me,,0,intranet.example.com,,8443,tcp,yes,Allowed intranet.example.com:8443
+Block,any,,,any,,,,yes,All other traffic
+`, windows)
+	assert.True(t, !strings.Contains(windows, "velo.example.com"))
+
+	// Addresses are passed to the endpoint's firewall tools so only
+	// plain addresses are accepted.
+	for _, allowed := range []string{
+		"", "any", "10.0.0.1:0", "10.0.0.1:http", "host name", "a,b",
+	} {
+		_, err := quarantineRules(config_obj, []string{allowed})
+		assert.Error(t, err, allowed)
+	}
+}
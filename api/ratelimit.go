@@ -0,0 +1,260 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Velocidex/ttlcache/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/protojson"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/constants"
+	"www.velocidex.com/golang/velociraptor/json"
+)
+
+const (
+	DEFAULT_EXPENSIVE_COST = 5
+
+	// Idle buckets are forgotten after this long. A new bucket is
+	// full so this only matters for users hitting the limit.
+	rate_limit_bucket_ttl = 10 * time.Minute
+)
+
+var (
+	default_expensive_endpoints = []string{
+		"GetTable", "GetHuntResults", "SearchClients", "ListClients",
+		"VFSListDirectory",
+	}
+
+	rateLimitCounter = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gui_api_rate_limited",
+			Help: "Number of API calls rejected by the rate limiter.",
+		},
+		[]string{"limit"},
+	)
+)
+
+type rateLimiter struct {
+	mu    sync.Mutex
+	cache *ttlcache.Cache
+
+	user_rate  rate.Limit
+	user_burst int
+	ip_rate    rate.Limit
+	ip_burst   int
+
+	expensive_endpoints []string
+	expensive_cost      int
+}
+
+// Returns nil when rate limiting is not configured.
+func newRateLimiter(
+	ctx context.Context, config_obj *config_proto.Config) *rateLimiter {
+	if config_obj.GUI == nil || config_obj.GUI.RateLimit == nil {
+		return nil
+	}
+
+	config := config_obj.GUI.RateLimit
+	if config.UserRate <= 0 && config.IpRate <= 0 {
+		return nil
+	}
+
+	result := &rateLimiter{
+		cache:               ttlcache.NewCache(),
+		user_rate:           rate.Limit(config.UserRate),
+		user_burst:          int(config.UserBurst),
+		ip_rate:             rate.Limit(config.IpRate),
+		ip_burst:            int(config.IpBurst),
+		expensive_endpoints: config.ExpensiveEndpoints,
+		expensive_cost:      int(config.ExpensiveCost),
+	}
+
+	if len(result.expensive_endpoints) == 0 {
+		result.expensive_endpoints = default_expensive_endpoints
+	}
+
+	if result.expensive_cost == 0 {
+		result.expensive_cost = DEFAULT_EXPENSIVE_COST
+	}
+
+	// The burst must allow at least one expensive call.
+	if result.user_burst < result.expensive_cost {
+		result.user_burst = result.expensive_cost
+	}
+
+	if result.ip_burst < result.expensive_cost {
+		result.ip_burst = result.expensive_cost
+	}
+
+	_ = result.cache.SetTTL(rate_limit_bucket_ttl)
+
+	go func() {
+		<-ctx.Done()
+		result.cache.Close()
+	}()
+
+	return result
+}
+
+func (self *rateLimiter) getLimiter(
+	key string, limit rate.Limit, burst int) *rate.Limiter {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	item, err := self.cache.Get(key)
+	if err == nil {
+		return item.(*rate.Limiter)
+	}
+
+	limiter := rate.NewLimiter(limit, burst)
+	_ = self.cache.Set(key, limiter)
+	return limiter
+}
+
+func (self *rateLimiter) cost(method string) int {
+	for _, endpoint := range self.expensive_endpoints {
+		if endpoint == method {
+			return self.expensive_cost
+		}
+	}
+	return 1
+}
+
+// Take tokens from the user's and the remote address's buckets. If
+// either is empty the call is refused and we return how long the
+// caller should wait.
+func (self *rateLimiter) reserve(
+	user, remote_addr, method string, now time.Time) (time.Duration, string) {
+	cost := self.cost(method)
+
+	reservations := []*rate.Reservation{}
+	cancel := func() {
+		for _, r := range reservations {
+			r.CancelAt(now)
+		}
+	}
+
+	if self.user_rate > 0 && user != "" {
+		limiter := self.getLimiter("user:"+user, self.user_rate, self.user_burst)
+		r := limiter.ReserveN(now, cost)
+		if !r.OK() {
+			return time.Second, "user"
+		}
+		reservations = append(reservations, r)
+
+		delay := r.DelayFrom(now)
+		if delay > 0 {
+			cancel()
+			return delay, "user"
+		}
+	}
+
+	if self.ip_rate > 0 {
+		host, _, err := net.SplitHostPort(remote_addr)
+		if err != nil {
+			host = remote_addr
+		}
+
+		limiter := self.getLimiter("ip:"+host, self.ip_rate, self.ip_burst)
+		r := limiter.ReserveN(now, cost)
+		if !r.OK() {
+			cancel()
+			return time.Second, "ip"
+		}
+		reservations = append(reservations, r)
+
+		delay := r.DelayFrom(now)
+		if delay > 0 {
+			cancel()
+			return delay, "ip"
+		}
+	}
+
+	return 0, ""
+}
+
+// The user context holds the serialized VelociraptorUser which also
+// carries the picture, expiry etc so we key the bucket on the name
+// alone. Otherwise a user could get a fresh bucket by logging in
+// again.
+func rateLimitUser(r *http.Request) string {
+	serialized, ok := r.Context().Value(constants.GRPC_USER_CONTEXT).(string)
+	if !ok {
+		return ""
+	}
+
+	user_info := &api_proto.VelociraptorUser{}
+	err := json.Unmarshal([]byte(serialized), user_info)
+	if err != nil {
+		return serialized
+	}
+	return user_info.Name
+}
+
+// The method name from paths like <base>/api/v1/GetTable/...
+func apiMethod(path string) string {
+	idx := strings.Index(path, "/api/")
+	if idx < 0 {
+		return ""
+	}
+
+	parts := strings.SplitN(path[idx+len("/api/"):], "/", 3)
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[1]
+}
+
+// Wraps the gateway and the GUI's own API handlers so callers which
+// exceed their rate get a 429 with a Retry-After header instead of
+// reaching the API server. The user must already be authenticated.
+func rateLimitHandler(limiter *rateLimiter, parent http.Handler) http.Handler {
+	if limiter == nil {
+		return parent
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delay, limit := limiter.reserve(rateLimitUser(r), r.RemoteAddr,
+			apiMethod(r.URL.Path), time.Now())
+		if delay > 0 {
+			rateLimitCounter.WithLabelValues(limit).Inc()
+			writeRateLimited(w, delay)
+			return
+		}
+
+		parent.ServeHTTP(w, r)
+	})
+}
+
+func writeRateLimited(w http.ResponseWriter, delay time.Duration) {
+	body := &api_proto.ApiErrorResponse{
+		Code: int32(codes.ResourceExhausted),
+		ErrorCode: strings.ToLower(
+			api_proto.ApiErrorDetails_QUOTA_EXCEEDED.String()),
+		Message: "Rate limit exceeded",
+	}
+
+	w.Header().Set("Retry-After",
+		fmt.Sprintf("%d", int64(math.Ceil(delay.Seconds()))))
+
+	serialized, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(body)
+	if err != nil {
+		http.Error(w, body.Message, http.StatusTooManyRequests)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusTooManyRequests)
+	_, _ = w.Write(serialized)
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/constants"
+	"www.velocidex.com/golang/velociraptor/vtesting/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Not configured - no limiter.
+	assert.True(t, newRateLimiter(ctx, &config_proto.Config{}) == nil)
+
+	limiter := newRateLimiter(ctx, &config_proto.Config{
+		GUI: &config_proto.GUIConfig{
+			RateLimit: &config_proto.ApiRateLimitConfig{
+				UserRate:  1,
+				UserBurst: 10,
+				IpRate:    1,
+				IpBurst:   15,
+			},
+		},
+	})
+	assert.True(t, limiter != nil)
+
+	now := time.Unix(1700000000, 0)
+
+	// Two expensive calls use up the user's burst.
+	for i := 0; i < 2; i++ {
+		delay, _ := limiter.reserve("mic", "10.0.0.1:1234", "GetTable", now)
+		assert.Equal(t, time.Duration(0), delay)
+	}
+
+	delay, limit := limiter.reserve("mic", "10.0.0.1:1234", "GetVersion", now)
+	assert.Equal(t, "user", limit)
+	assert.Equal(t, time.Second, delay)
+
+	// Other users have their own bucket but share the address
+	// bucket, which has 5 tokens left.
+	for i := 0; i < 5; i++ {
+		delay, _ = limiter.reserve("bob", "10.0.0.1:5678", "GetVersion", now)
+		assert.Equal(t, time.Duration(0), delay)
+	}
+
+	_, limit = limiter.reserve("bob", "10.0.0.1:5678", "GetVersion", now)
+	assert.Equal(t, "ip", limit)
+
+	// The bucket refills over time.
+	delay, _ = limiter.reserve("mic", "10.0.0.2:1234", "GetVersion",
+		now.Add(time.Second))
+	assert.Equal(t, time.Duration(0), delay)
+
+	// Rejected calls get a 429 with a Retry-After header.
+	limiter = newRateLimiter(ctx, &config_proto.Config{
+		GUI: &config_proto.GUIConfig{
+			RateLimit: &config_proto.ApiRateLimitConfig{
+				UserRate: 0.5,
+			},
+		},
+	})
+	handler := rateLimitHandler(limiter, http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {}))
+
+	request := httptest.NewRequest("GET", "/api/v1/GetTable", nil)
+	request = request.WithContext(context.WithValue(
+		request.Context(), constants.GRPC_USER_CONTEXT, "mic"))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(t, "10", recorder.Header().Get("Retry-After"))
+}
+
+func TestRateLimitKeys(t *testing.T) {
+	for path, method := range map[string]string{
+		"/api/v1/GetTable":                     "GetTable",
+		"/api/v2/ListClients":                  "ListClients",
+		"/base/api/v1/DownloadVFSFile/C.1/a/b": "DownloadVFSFile",
+		"/base/api/v1/UploadFormFile":          "UploadFormFile",
+		"/app/index.html":                      "",
+	} {
+		assert.Equal(t, method, apiMethod(path), path)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	limiter := newRateLimiter(ctx, &config_proto.Config{
+		GUI: &config_proto.GUIConfig{
+			RateLimit: &config_proto.ApiRateLimitConfig{
+				UserRate: 0.5,
+			},
+		},
+	})
+	handler := rateLimitHandler(limiter, http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {}))
+
+	// The same user logging in again gets a different user context
+	// but must not get a new bucket.
+	codes := []int{}
+	for _, serialized := range []string{
+		`{"name":"mic","picture":"a.png"}`,
+		`{"name":"mic","picture":"b.png"}`,
+	} {
+		request := httptest.NewRequest("GET", "/api/v1/ListClients", nil)
+		request = request.WithContext(context.WithValue(
+			request.Context(), constants.GRPC_USER_CONTEXT, serialized))
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		codes = append(codes, recorder.Code)
+	}
+	assert.Equal(t, []int{http.StatusOK, http.StatusTooManyRequests}, codes)
+}
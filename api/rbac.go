@@ -0,0 +1,238 @@
+package api
+
+import (
+	"crypto/x509"
+	"fmt"
+	"strings"
+
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"www.velocidex.com/golang/velociraptor/acls"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/services/users"
+)
+
+const (
+	API_SERVICE_PREFIX = "/proto.API/"
+)
+
+// The permissions needed to call each API method. The caller's roles
+// must grant at least one of them. Handlers may check for further
+// permissions depending on the request (e.g. collecting from the
+// server rather than a client).
+//
+// Methods with no permissions are available to any authenticated
+// user. Every method must be listed here - calls to methods which
+// are not listed are rejected.
+var method_permissions = map[string][]acls.ACL_PERMISSION{
+	// Hunts
+	"CreateHunt":     {acls.COLLECT_CLIENT},
+	"EstimateHunt":   {acls.READ_RESULTS},
+	"ListHunts":      {acls.READ_RESULTS},
+	"GetHunt":        {acls.READ_RESULTS},
+	"ModifyHunt":     {acls.COLLECT_CLIENT},
+	"GetHuntFlows":   {acls.READ_RESULTS},
+	"GetHuntResults": {acls.READ_RESULTS},
+
+	// Clients
+	"NotifyClients":      {acls.COLLECT_CLIENT},
+	"LabelClients":       {acls.LABEL_CLIENT},
+	"ListClients":        {acls.READ_RESULTS},
+	"GetClient":          {acls.READ_RESULTS},
+	"GetClientMetadata":  {acls.READ_RESULTS, acls.SERVER_ADMIN},
+	"SetClientMetadata":  {acls.LABEL_CLIENT},
+	"GetClientFlows":     {acls.READ_RESULTS},
+	"UninstallClient":    {acls.COLLECT_CLIENT},
+	"DeleteClient":       {acls.SERVER_ADMIN},
+	"QuarantineClient":   {acls.COLLECT_CLIENT},
+	"UnquarantineClient": {acls.COLLECT_CLIENT},
+	"GetBandwidthUsage":  {acls.READ_RESULTS},
+
+	// Users
+	"GetUserUITraits":  nil,
+	"SetGUIOptions":    nil,
+	"GetUsers":         {acls.READ_RESULTS},
+	"GetUserFavorites": nil,
+	"GetRoles":         {acls.READ_RESULTS},
+	"SetRole":          {acls.SERVER_ADMIN},
+	"DeleteRole":       {acls.SERVER_ADMIN},
+	"CreateApiKey":     {acls.SERVER_ADMIN},
+	"ListApiKeys":      {acls.SERVER_ADMIN},
+	"RevokeApiKey":     {acls.SERVER_ADMIN},
+
+	// VFS
+	"VFSListDirectory":    {acls.READ_RESULTS},
+	"VFSRefreshDirectory": {acls.COLLECT_CLIENT},
+	"VFSStatDirectory":    {acls.READ_RESULTS},
+	"VFSStatDownload":     {acls.READ_RESULTS},
+	"VFSGetBuffer":        {acls.READ_RESULTS},
+
+	// Results
+	"GetTable":                  {acls.READ_RESULTS},
+	"StreamTable":               {acls.READ_RESULTS},
+	"GetChartData":              {acls.READ_RESULTS},
+	"GetReport":                 {acls.READ_RESULTS},
+	"ListAvailableEventResults": {acls.READ_RESULTS},
+	"SearchFiles":               {acls.READ_RESULTS},
+	"CreateDownloadFile":        {acls.PREPARE_RESULTS},
+
+	// Flows
+	"CollectArtifact":      {acls.COLLECT_CLIENT, acls.COLLECT_SERVER},
+	"CollectArtifactMulti": {acls.COLLECT_CLIENT},
+	"CancelFlow":           {acls.COLLECT_CLIENT, acls.COLLECT_SERVER},
+	"GetFlowDetails":       {acls.READ_RESULTS},
+	"GetFlowRequests":      {acls.READ_RESULTS},
+	"ArchiveFlow":          {acls.COLLECT_CLIENT},
+	"RehydrateFlow":        {acls.READ_RESULTS},
+	"ImportCollection":     {acls.COLLECT_SERVER},
+	"IngestRows":           {acls.COLLECT_CLIENT},
+
+	// Approvals and legal holds
+	"RequestApproval": {acls.COLLECT_CLIENT},
+	"GrantApproval":   {acls.COLLECT_CLIENT},
+	"GetApprovals":    {acls.READ_RESULTS},
+	"SetLegalHold":    {acls.COLLECT_CLIENT, acls.SERVER_ADMIN},
+	"GetLegalHolds":   {acls.READ_RESULTS},
+
+	// Server administration
+	"GetAuditLog":              {acls.SERVER_ADMIN},
+	"GetLDAPMappings":          {acls.SERVER_ADMIN},
+	"SetLDAPMappings":          {acls.SERVER_ADMIN},
+	"GetServerMonitoringState": {acls.READ_RESULTS},
+	"SetServerMonitoringState": {acls.SERVER_ADMIN},
+	"GetClientMonitoringState": {acls.SERVER_ADMIN},
+	"SetClientMonitoringState": {acls.SERVER_ADMIN},
+	"GetComplianceReport":      {acls.READ_RESULTS},
+	"GetAttackCoverage":        {acls.READ_RESULTS},
+	"GetApiVersions":           nil,
+
+	// Jobs
+	"GetJob":    {acls.READ_RESULTS},
+	"ListJobs":  {acls.READ_RESULTS},
+	"CancelJob": {acls.READ_RESULTS, acls.SERVER_ADMIN},
+
+	// Artifacts and tools
+	"GetArtifacts":            {acls.READ_RESULTS},
+	"GetArtifactFile":         {acls.READ_RESULTS},
+	"SetArtifactFile":         {acls.ARTIFACT_WRITER, acls.SERVER_ARTIFACT_WRITER},
+	"TranslateSigma":          {acls.ARTIFACT_WRITER},
+	"LoadArtifactPack":        {acls.SERVER_ARTIFACT_WRITER},
+	"ListExchangeArtifacts":   {acls.ARTIFACT_WRITER},
+	"InstallExchangeArtifact": {acls.SERVER_ADMIN},
+	"CheckExchangeUpdates":    {acls.ARTIFACT_WRITER},
+	"GetToolInfo":             {acls.READ_RESULTS},
+	"SetToolInfo":             {acls.ARTIFACT_WRITER},
+
+	// Notebooks
+	"GetNotebooks":               {acls.READ_RESULTS},
+	"NewNotebook":                {acls.NOTEBOOK_EDITOR},
+	"UpdateNotebook":             {acls.NOTEBOOK_EDITOR},
+	"NewNotebookCell":            {acls.NOTEBOOK_EDITOR},
+	"GetNotebookCell":            {acls.READ_RESULTS},
+	"UpdateNotebookCell":         {acls.NOTEBOOK_EDITOR},
+	"CancelNotebookCell":         {acls.NOTEBOOK_EDITOR},
+	"CreateNotebookDownloadFile": {acls.PREPARE_RESULTS},
+	"UploadNotebookAttachment":   {acls.NOTEBOOK_EDITOR},
+
+	// VQL editing helpers
+	"GetKeywordCompletions": nil,
+	"VQLComplete":           nil,
+	"VQLHover":              nil,
+	"VQLDiagnose":           nil,
+
+	// VQL and server internal calls
+	"Query":         {acls.ANY_QUERY},
+	"WatchEvent":    {acls.ANY_QUERY},
+	"PushEvents":    nil,
+	"WriteEvent":    {acls.MACHINE_STATE},
+	"GetSubject":    {acls.DATASTORE_ACCESS},
+	"SetSubject":    {acls.DATASTORE_ACCESS},
+	"DeleteSubject": {acls.DATASTORE_ACCESS},
+	"ListChildren":  {acls.DATASTORE_ACCESS},
+	"Check":         nil,
+}
+
+// Checks the caller may call the API method before the handler
+// runs. Only calls to the API service are checked.
+type rbacEnforcer struct {
+	config_obj *config_proto.Config
+	ca_pool    *x509.CertPool
+}
+
+func newRBACEnforcer(
+	config_obj *config_proto.Config, ca_pool *x509.CertPool) *rbacEnforcer {
+	return &rbacEnforcer{config_obj: config_obj, ca_pool: ca_pool}
+}
+
+func (self *rbacEnforcer) UnaryInterceptor(ctx context.Context, req interface{},
+	info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	err := self.checkMethod(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (self *rbacEnforcer) StreamInterceptor(srv interface{},
+	stream grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	err := self.checkMethod(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, stream)
+}
+
+func (self *rbacEnforcer) checkMethod(ctx context.Context, full_method string) error {
+	if !strings.HasPrefix(full_method, API_SERVICE_PREFIX) {
+		return nil
+	}
+
+	method := strings.TrimPrefix(full_method, API_SERVICE_PREFIX)
+	permissions, pres := method_permissions[method]
+	if !pres {
+		return status.Error(codes.PermissionDenied, fmt.Sprintf(
+			"No access policy for method %v.", method))
+	}
+
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	// The server's own identity has no user record.
+	// acls.CheckAccess() recognizes its name and grants it every
+	// permission (except those denied in read only mode).
+	user_info := users.GetGRPCUserInfo(self.config_obj, ctx, self.ca_pool)
+
+	org_manager, err := services.GetOrgManager()
+	if err != nil {
+		return err
+	}
+
+	org_config_obj, err := org_manager.GetOrgConfig(user_info.CurrentOrg)
+	if err != nil {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	for _, permission := range permissions {
+		perm, err := acls.CheckAccess(org_config_obj, user_info.Name, permission)
+		if perm && err == nil {
+			return nil
+		}
+	}
+
+	return status.Error(codes.PermissionDenied, fmt.Sprintf(
+		"User is not allowed to call %v (%v).", method,
+		formatPermissions(permissions)))
+}
+
+func formatPermissions(permissions []acls.ACL_PERMISSION) string {
+	names := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		names = append(names, permission.String())
+	}
+	return strings.Join(names, " or ")
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"testing"
+
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/vtesting/assert"
+)
+
+// New API methods must be given an access policy.
+func TestAllMethodsHavePermissions(t *testing.T) {
+	names := []string{}
+	for _, method := range api_proto.API_ServiceDesc.Methods {
+		names = append(names, method.MethodName)
+	}
+	for _, stream := range api_proto.API_ServiceDesc.Streams {
+		names = append(names, stream.StreamName)
+	}
+
+	for _, name := range names {
+		_, pres := method_permissions[name]
+		assert.True(t, pres, "No access policy for %v", name)
+	}
+	assert.Equal(t, len(names), len(method_permissions))
+}
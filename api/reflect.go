@@ -27,6 +27,7 @@ import (
 	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
 	"www.velocidex.com/golang/velociraptor/artifacts/assets"
 	artifacts_proto "www.velocidex.com/golang/velociraptor/artifacts/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
 	"www.velocidex.com/golang/velociraptor/services"
 	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
 	"www.velocidex.com/golang/vfilter"
@@ -66,6 +67,7 @@ func IntrospectDescription() []*api_proto.Completion {
 			Description: item.Doc,
 			Type:        "Function",
 			Args:        getArgDescriptors(item.ArgType, type_map, scope),
+			Version:     int64(item.Version),
 		})
 	}
 
@@ -75,12 +77,40 @@ func IntrospectDescription() []*api_proto.Completion {
 			Description: item.Doc,
 			Type:        "Plugin",
 			Args:        getArgDescriptors(item.ArgType, type_map, scope),
+			Version:     int64(item.Version),
 		})
 	}
 
+	// The registry does not know about categories and examples so
+	// take them from the reference docs.
+	reference, err := LoadApiDescription()
+	if err == nil {
+		enrichDescription(result, reference)
+	}
+
 	return result
 }
 
+// Copy the documentation only metadata from the reference
+// descriptions into the introspected ones.
+func enrichDescription(items, reference []*api_proto.Completion) {
+	lookup := make(map[string]*api_proto.Completion)
+	for _, item := range reference {
+		lookup[item.Type+":"+item.Name] = item
+	}
+
+	for _, item := range items {
+		ref, pres := lookup[item.Type+":"+item.Name]
+		if !pres {
+			continue
+		}
+
+		item.Category = ref.Category
+		item.VersionAdded = ref.VersionAdded
+		item.Examples = ref.Examples
+	}
+}
+
 func (self *ApiServer) GetKeywordCompletions(
 	ctx context.Context,
 	in *emptypb.Empty) (*api_proto.KeywordCompletions, error) {
@@ -109,28 +139,42 @@ func (self *ApiServer) GetKeywordCompletions(
 	}
 	result.Items = append(result.Items, descriptions...)
 
-	manager, err := services.GetRepositoryManager(org_config_obj)
+	artifacts, err := getArtifactCompletions(ctx, org_config_obj)
 	if err != nil {
 		return nil, err
 	}
-	repository, err := manager.GetGlobalRepository(org_config_obj)
+	result.Items = append(result.Items, artifacts...)
+
+	return result, nil
+}
+
+func getArtifactCompletions(
+	ctx context.Context,
+	config_obj *config_proto.Config) ([]*api_proto.Completion, error) {
+	manager, err := services.GetRepositoryManager(config_obj)
 	if err != nil {
 		return nil, err
 	}
-	names, err := repository.List(ctx, org_config_obj)
+	repository, err := manager.GetGlobalRepository(config_obj)
+	if err != nil {
+		return nil, err
+	}
+	names, err := repository.List(ctx, config_obj)
 	if err != nil {
 		return nil, err
 	}
 
+	result := []*api_proto.Completion{}
 	for _, name := range names {
-		artifact, pres := repository.Get(org_config_obj, name)
+		artifact, pres := repository.Get(config_obj, name)
 		if !pres {
 			continue
 		}
-		result.Items = append(result.Items, &api_proto.Completion{
-			Name: "Artifact." + name,
-			Type: "Artifact",
-			Args: getArtifactParamDescriptors(artifact),
+		result = append(result, &api_proto.Completion{
+			Name:        "Artifact." + name,
+			Description: artifact.Description,
+			Type:        "Artifact",
+			Args:        getArtifactParamDescriptors(artifact),
 		})
 	}
 
@@ -157,7 +201,8 @@ func getArgDescriptors(
 			}
 
 			required := ""
-			if strings.Contains(v.Tag, "required") {
+			is_required := strings.Contains(v.Tag, "required")
+			if is_required {
 				required = "(required)"
 			}
 			doc := ""
@@ -169,6 +214,8 @@ func getArgDescriptors(
 				Name:        k,
 				Description: doc + required,
 				Type:        target,
+				Repeated:    v.Repeated,
+				Required:    is_required,
 			})
 		}
 	}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"testing"
+
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/vtesting/assert"
+)
+
+func TestEnrichDescription(t *testing.T) {
+	items := []*api_proto.Completion{
+		{Name: "info", Type: "Plugin", Description: "Live doc", Version: 2},
+		{Name: "info", Type: "Function"},
+		{Name: "new_plugin", Type: "Plugin"},
+	}
+
+	reference := []*api_proto.Completion{
+		{Name: "info", Type: "Plugin", Description: "Old doc",
+			Category: "plugin", VersionAdded: "0.3.0",
+			Examples: []string{"SELECT * FROM info()"}},
+	}
+
+	enrichDescription(items, reference)
+
+	// Metadata is copied but the live description and version are
+	// kept.
+	assert.Equal(t, "Live doc", items[0].Description)
+	assert.Equal(t, int64(2), items[0].Version)
+	assert.Equal(t, "plugin", items[0].Category)
+	assert.Equal(t, "0.3.0", items[0].VersionAdded)
+	assert.Equal(t, []string{"SELECT * FROM info()"}, items[0].Examples)
+
+	// Only items of the same type are matched.
+	assert.Equal(t, "", items[1].Category)
+	assert.Equal(t, "", items[2].Category)
+}
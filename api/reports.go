@@ -19,6 +19,7 @@ func getReport(ctx context.Context,
 	config_obj *config_proto.Config,
 	acl_manager vql_subsystem.ACLManager,
 	repository services.Repository,
+	lang string,
 	in *api_proto.GetReportRequest) (
 	*api_proto.GetReportResponse, error) {
 
@@ -40,6 +41,8 @@ func getReport(ctx context.Context,
 	}
 	defer template_engine.Close()
 
+	template_engine.Lang = lang
+
 	var template_data string
 
 	if in.Type == "" {
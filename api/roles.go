@@ -0,0 +1,110 @@
+package api
+
+import (
+	"github.com/sirupsen/logrus"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"www.velocidex.com/golang/velociraptor/acls"
+	acl_proto "www.velocidex.com/golang/velociraptor/acls/proto"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+func (self *ApiServer) GetRoles(
+	ctx context.Context,
+	in *emptypb.Empty) (*api_proto.Roles, error) {
+
+	defer Instrument("GetRoles")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_record.Name
+	permissions := acls.READ_RESULTS
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to view roles.")
+	}
+
+	roles, err := acls.ListRoles(org_config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api_proto.Roles{Items: roles}, nil
+}
+
+func (self *ApiServer) SetRole(
+	ctx context.Context,
+	in *acl_proto.Role) (*emptypb.Empty, error) {
+
+	defer Instrument("SetRole")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_record.Name
+	permissions := acls.SERVER_ADMIN
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to manage roles.")
+	}
+
+	err = acls.SetCustomRole(org_config_obj, in)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	logging.GetLogger(org_config_obj, &logging.Audit).
+		WithFields(logrus.Fields{
+			"user": user_name,
+			"role": in.Name,
+		}).Info("SetRole")
+
+	return &emptypb.Empty{}, nil
+}
+
+func (self *ApiServer) DeleteRole(
+	ctx context.Context,
+	in *api_proto.DeleteRoleRequest) (*emptypb.Empty, error) {
+
+	defer Instrument("DeleteRole")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user_name := user_record.Name
+	permissions := acls.SERVER_ADMIN
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to manage roles.")
+	}
+
+	err = acls.DeleteCustomRole(org_config_obj, in.Name)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	logging.GetLogger(org_config_obj, &logging.Audit).
+		WithFields(logrus.Fields{
+			"user": user_name,
+			"role": in.Name,
+		}).Info("DeleteRole")
+
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/services/launcher"
+)
+
+// Report the sampling rate the results were collected with so the GUI
+// can show that the table is not complete.
+func (self *ApiServer) addSamplingMetadata(
+	config_obj *config_proto.Config,
+	in *api_proto.GetTableRequest,
+	result *api_proto.GetTableResponse) {
+
+	var request *flows_proto.ArtifactCollectorArgs
+
+	if in.FlowId != "" && in.ClientId != "" {
+		request = self.getFlowRequest(config_obj, in.ClientId, in.FlowId)
+
+	} else if in.HuntId != "" {
+		hunt_dispatcher, err := services.GetHuntDispatcher(config_obj)
+		if err != nil {
+			return
+		}
+
+		hunt, pres := hunt_dispatcher.GetHunt(in.HuntId)
+		if !pres {
+			return
+		}
+		request = hunt.StartRequest
+	}
+
+	if request == nil {
+		return
+	}
+
+	result.SamplePercent = request.SamplePercent
+	result.SampleRows = request.SampleRows
+}
+
+// The GUI pages through a table with many GetTable calls after it
+// already fetched the flow details, so they are usually cached.
+// Otherwise only the flow's context is loaded - there is no need to
+// build the full flow details.
+func (self *ApiServer) getFlowRequest(
+	config_obj *config_proto.Config,
+	client_id, flow_id string) *flows_proto.ArtifactCollectorArgs {
+
+	details, pres := self.cache.GetFlowDetails(config_obj, client_id, flow_id)
+	if pres {
+		return details.Context.GetRequest()
+	}
+
+	collection_context, err := launcher.LoadCollectionContext(
+		config_obj, client_id, flow_id)
+	if err != nil {
+		return nil
+	}
+	return collection_context.Request
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"www.velocidex.com/golang/velociraptor/acls"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/sigma"
+)
+
+// Translate a pack of Sigma rules into artifacts. The generated
+// artifacts are returned for review so the user can install them
+// with SetArtifactFile.
+func (self *ApiServer) TranslateSigma(
+	ctx context.Context,
+	in *api_proto.SigmaTranslationRequest) (
+	*api_proto.SigmaTranslationResponse, error) {
+
+	defer Instrument("TranslateSigma")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := acls.ARTIFACT_WRITER
+	perm, err := acls.CheckAccess(org_config_obj, user_record.Name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to translate Sigma rules.")
+	}
+
+	manager, err := services.GetRepositoryManager(org_config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &api_proto.SigmaTranslationResponse{}
+	for _, translation := range sigma.TranslatePack(in.Pack, in.Prefix) {
+		item := &api_proto.SigmaTranslation{
+			Title:    translation.Title,
+			Id:       translation.Id,
+			Name:     translation.Name,
+			Artifact: translation.Artifact,
+			Warnings: translation.Warnings,
+			Error:    translation.Error,
+		}
+
+		// Make sure the artifact will load when it is installed.
+		if item.Artifact != "" {
+			_, err := manager.NewRepository().LoadYaml(
+				item.Artifact, true /* validate */, false /* built_in */)
+			if err != nil {
+				item.Error = err.Error()
+				item.Name = ""
+				item.Artifact = ""
+			}
+		}
+
+		result.Items = append(result.Items, item)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/Velocidex/ordereddict"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"www.velocidex.com/golang/velociraptor/acls"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/file_store"
+	"www.velocidex.com/golang/velociraptor/json"
+	"www.velocidex.com/golang/velociraptor/result_sets"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+// Rows are sent in chunks of this many JSONL lines.
+const STREAM_TABLE_CHUNK_ROWS = 1000
+
+// StreamTable sends the same rows as GetTable but as a stream of
+// JSONL chunks so callers can read large result sets without paging
+// through them. Over HTTP the stream is sent using chunked transfer
+// encoding.
+func (self *ApiServer) StreamTable(
+	in *api_proto.GetTableRequest,
+	stream api_proto.API_StreamTableServer) error {
+
+	defer Instrument("StreamTable")()
+
+	ctx := stream.Context()
+	users := services.GetUserManager()
+	user_info, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	user_name := user_info.Name
+	permissions := acls.READ_RESULTS
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return status.Error(codes.PermissionDenied,
+			"User is not allowed to view results.")
+	}
+
+	row_chan, closer, err := getStreamRows(ctx, org_config_obj, in)
+	if err != nil {
+		return err
+	}
+	defer closer()
+
+	transform := getTransformer(org_config_obj, in)
+
+	chunk := &api_proto.TableChunk{StartRow: in.StartRow}
+	buf := &bytes.Buffer{}
+	send := func() error {
+		if chunk.Rows == 0 {
+			return nil
+		}
+		chunk.Jsonl = buf.String()
+		err := stream.Send(chunk)
+		chunk = &api_proto.TableChunk{StartRow: chunk.StartRow + chunk.Rows}
+		buf.Reset()
+		return err
+	}
+
+	total := uint64(0)
+	for row := range row_chan {
+		if in.Rows > 0 && total >= in.Rows {
+			break
+		}
+
+		serialized, err := json.Marshal(
+			filterColumns(in.Columns, transform(row)))
+		if err != nil {
+			return err
+		}
+
+		buf.Write(serialized)
+		buf.WriteByte('\n')
+		chunk.Rows++
+		total++
+
+		if chunk.Rows >= STREAM_TABLE_CHUNK_ROWS {
+			err = send()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return send()
+}
+
+// Collected result sets are indexed so we can seek straight to the
+// first row and apply the same sorting and filtering as GetTable.
+// Event tables are read from the start.
+func getStreamRows(
+	ctx context.Context,
+	config_obj *config_proto.Config,
+	in *api_proto.GetTableRequest) (
+	<-chan *ordereddict.Dict, func(), error) {
+
+	if in.Type == "CLIENT_EVENT" || in.Type == "SERVER_EVENT" {
+		row_chan, closer, _, err := getRows(ctx, config_obj, in)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		output_chan := make(chan *ordereddict.Dict)
+		go func() {
+			defer close(output_chan)
+
+			count := uint64(0)
+			for row := range row_chan {
+				count++
+				if count <= in.StartRow {
+					continue
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case output_chan <- row:
+				}
+			}
+		}()
+		return output_chan, closer, nil
+	}
+
+	path_spec, err := getPathSpec(config_obj, in)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	options := result_sets.ResultSetOptions{}
+	if in.SortColumn != "" {
+		options.SortColumn = in.SortColumn
+		options.SortAsc = in.SortDirection
+	}
+
+	if in.FilterColumn != "" && in.FilterRegex != "" {
+		options.FilterColumn = in.FilterColumn
+		options.FilterRegex, err = regexp.Compile("(?i)" + in.FilterRegex)
+		if err != nil {
+			return nil, nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	rs_reader, err := result_sets.NewResultSetReaderWithOptions(
+		ctx, config_obj, file_store.GetFileStore(config_obj),
+		path_spec, options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = rs_reader.SeekToRow(int64(in.StartRow))
+	if err != nil {
+		rs_reader.Close()
+		return nil, nil, err
+	}
+
+	return rs_reader.Rows(ctx), rs_reader.Close, nil
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Velocidex/ordereddict"
+	"google.golang.org/grpc"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/file_store"
+	artifact_paths "www.velocidex.com/golang/velociraptor/paths/artifacts"
+	"www.velocidex.com/golang/velociraptor/result_sets"
+	"www.velocidex.com/golang/velociraptor/utils"
+	"www.velocidex.com/golang/velociraptor/vtesting/assert"
+)
+
+type testTableStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	chunks []*api_proto.TableChunk
+}
+
+func (self *testTableStream) Context() context.Context {
+	return self.ctx
+}
+
+func (self *testTableStream) Send(chunk *api_proto.TableChunk) error {
+	self.chunks = append(self.chunks, chunk)
+	return nil
+}
+
+// Write the rows as the results of the artifact in a flow.
+func (self *ApiTestSuite) writeResults(
+	flow_id, artifact string, rows []*ordereddict.Dict) {
+	path_manager, err := artifact_paths.NewArtifactPathManager(
+		self.ConfigObj, self.client_id, flow_id, artifact)
+	assert.NoError(self.T(), err)
+
+	path, err := path_manager.GetPathForWriting()
+	assert.NoError(self.T(), err)
+
+	rs_writer, err := result_sets.NewResultSetWriter(
+		file_store.GetFileStore(self.ConfigObj), path,
+		nil, utils.SyncCompleter, true /* truncate */)
+	assert.NoError(self.T(), err)
+
+	for _, row := range rows {
+		rs_writer.Write(row)
+	}
+	rs_writer.Close()
+}
+
+func (self *ApiTestSuite) streamTable(
+	in *api_proto.GetTableRequest) []*api_proto.TableChunk {
+	stream := &testTableStream{ctx: self.userContext("alice")}
+	err := self.server.StreamTable(in, stream)
+	assert.NoError(self.T(), err)
+	return stream.chunks
+}
+
+func (self *ApiTestSuite) TestStreamTable() {
+	rows := []*ordereddict.Dict{}
+	for i := 0; i < 2500; i++ {
+		rows = append(rows, ordereddict.NewDict().
+			Set("Id", i).
+			Set("Name", fmt.Sprintf("Row %d", i)))
+	}
+	self.writeResults("F.Stream", "Custom.Safe", rows)
+
+	request := &api_proto.GetTableRequest{
+		ClientId: self.client_id,
+		FlowId:   "F.Stream",
+		Artifact: "Custom.Safe",
+	}
+
+	// The rows are sent in chunks.
+	chunks := self.streamTable(request)
+	assert.Equal(self.T(), 3, len(chunks))
+	for i, count := range []uint64{1000, 1000, 500} {
+		assert.Equal(self.T(), count, chunks[i].Rows)
+		assert.Equal(self.T(), uint64(i*1000), chunks[i].StartRow)
+		assert.Equal(self.T(), int(count),
+			strings.Count(chunks[i].Jsonl, "\n"))
+	}
+	assert.Equal(self.T(), `{"Id":1000,"Name":"Row 1000"}`,
+		strings.SplitN(chunks[1].Jsonl, "\n", 2)[0])
+
+	// The start row and row count are respected.
+	request.StartRow = 10
+	request.Rows = 1005
+	chunks = self.streamTable(request)
+	assert.Equal(self.T(), 2, len(chunks))
+	assert.Equal(self.T(), uint64(10), chunks[0].StartRow)
+	assert.Equal(self.T(), uint64(1010), chunks[1].StartRow)
+	assert.Equal(self.T(), uint64(5), chunks[1].Rows)
+	assert.Equal(self.T(), `{"Id":10,"Name":"Row 10"}`,
+		strings.SplitN(chunks[0].Jsonl, "\n", 2)[0])
+
+	// Filtering and column selection work like GetTable.
+	request = &api_proto.GetTableRequest{
+		ClientId:     self.client_id,
+		FlowId:       "F.Stream",
+		Artifact:     "Custom.Safe",
+		FilterColumn: "Name",
+		FilterRegex:  "^Row 12.$",
+		Columns:      []string{"Id"},
+	}
+	chunks = self.streamTable(request)
+	assert.Equal(self.T(), 1, len(chunks))
+	assert.Equal(self.T(), uint64(10), chunks[0].Rows)
+	assert.Equal(self.T(), `{"Id":120}`,
+		strings.SplitN(chunks[0].Jsonl, "\n", 2)[0])
+
+	// Callers need to be able to read results.
+	stream := &testTableStream{ctx: self.userContext("mallory")}
+	err := self.server.StreamTable(request, stream)
+	assert.Error(self.T(), err)
+}
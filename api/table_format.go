@@ -0,0 +1,183 @@
+package api
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/schema"
+	"github.com/sirupsen/logrus"
+	"www.velocidex.com/golang/velociraptor/acls"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/file_store/csv"
+	"www.velocidex.com/golang/velociraptor/json"
+	"www.velocidex.com/golang/velociraptor/logging"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+)
+
+// GetTable wraps the rows in a GetTableResponse which every consumer
+// needs to unpack. Callers which just want the data may ask for it
+// as CSV or JSONL with the format query parameter or the Accept
+// header and the rows are streamed directly.
+const (
+	TABLE_FORMAT_CSV   = "csv"
+	TABLE_FORMAT_JSONL = "jsonl"
+
+	CSV_CONTENT_TYPE    = "text/csv"
+	NDJSON_CONTENT_TYPE = "application/x-ndjson"
+)
+
+// Returns the requested format or "" if the caller wants the usual
+// protobuf response. The format parameter takes precedence over the
+// Accept header.
+func getTableFormat(r *http.Request) (string, error) {
+	format := r.URL.Query().Get("format")
+	switch format {
+	case TABLE_FORMAT_CSV, TABLE_FORMAT_JSONL:
+		return format, nil
+	case "json":
+		return "", nil
+	case "":
+	default:
+		return "", fmt.Errorf("Unsupported format %q", format)
+	}
+
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		media_type, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+
+		switch media_type {
+		case CSV_CONTENT_TYPE:
+			return TABLE_FORMAT_CSV, nil
+		case NDJSON_CONTENT_TYPE, "application/jsonl":
+			return TABLE_FORMAT_JSONL, nil
+		}
+	}
+
+	return "", nil
+}
+
+// Serve the GetTable route: Requests for CSV or JSONL are streamed
+// here and the rest are passed to the gateway. The gateway applies
+// its own rate limit so only the streamed requests are limited here.
+func tableFormatHandler(
+	config_obj *config_proto.Config,
+	limiter *rateLimiter, gateway http.Handler) http.Handler {
+	stream := rateLimitHandler(limiter, streamTableHandler(config_obj))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		format, err := getTableFormat(r)
+		if err != nil {
+			returnError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if format == "" {
+			gateway.ServeHTTP(w, r)
+			return
+		}
+
+		stream.ServeHTTP(w, r)
+	})
+}
+
+func streamTableHandler(config_obj *config_proto.Config) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		format, err := getTableFormat(r)
+		if err != nil {
+			returnError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		userinfo := GetUserInfo(r.Context(), config_obj)
+		permissions := acls.READ_RESULTS
+		perm, err := acls.CheckAccess(config_obj, userinfo.Name, permissions)
+		if !perm || err != nil {
+			returnError(w, http.StatusForbidden,
+				"User is not allowed to view results.")
+			return
+		}
+
+		query := r.URL.Query()
+		query.Del("format")
+
+		request := &api_proto.GetTableRequest{}
+		decoder := schema.NewDecoder()
+		decoder.SetAliasTag("json")
+		decoder.IgnoreUnknownKeys(true)
+		err = decoder.Decode(request, query)
+		if err != nil {
+			returnError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Applies the same sorting, filtering and start row as
+		// GetTable.
+		row_chan, closer, err := getStreamRows(r.Context(), config_obj, request)
+		if err != nil {
+			returnError(w, http.StatusBadRequest, "Invalid request")
+			return
+		}
+		defer closer()
+
+		transform := getTransformer(config_obj, request)
+
+		logging.GetLogger(config_obj, &logging.Audit).
+			WithFields(logrus.Fields{
+				"user":    userinfo.Name,
+				"request": request,
+				"format":  format,
+				"remote":  r.RemoteAddr,
+			}).Info("GetTable")
+
+		// From here on we already sent the headers and we can not
+		// really report an error to the client.
+		total := uint64(0)
+		switch format {
+		case TABLE_FORMAT_CSV:
+			w.Header().Set("Content-Type", CSV_CONTENT_TYPE+"; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+
+			scope := vql_subsystem.MakeScope()
+			defer scope.Close()
+
+			csv_writer := csv.GetCSVAppender(
+				config_obj, scope, w, true /* write_headers */)
+			defer csv_writer.Close()
+
+			for row := range row_chan {
+				if request.Rows > 0 && total >= request.Rows {
+					break
+				}
+				csv_writer.Write(filterColumns(request.Columns, transform(row)))
+				total++
+			}
+
+		default:
+			w.Header().Set("Content-Type", NDJSON_CONTENT_TYPE)
+			w.WriteHeader(http.StatusOK)
+
+			for row := range row_chan {
+				if request.Rows > 0 && total >= request.Rows {
+					break
+				}
+
+				serialized, err := json.Marshal(
+					filterColumns(request.Columns, transform(row)))
+				if err != nil {
+					return
+				}
+
+				_, err = w.Write(append(serialized, '\n'))
+				if err != nil {
+					return
+				}
+				total++
+			}
+		}
+	})
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"www.velocidex.com/golang/velociraptor/vtesting/assert"
+)
+
+func TestGetTableFormat(t *testing.T) {
+	for _, test := range []struct {
+		url, accept, format string
+	}{
+		{"/api/v1/GetTable", "", ""},
+		{"/api/v1/GetTable", "application/json", ""},
+		{"/api/v1/GetTable?format=csv", "", TABLE_FORMAT_CSV},
+		{"/api/v1/GetTable?format=jsonl", "", TABLE_FORMAT_JSONL},
+		{"/api/v1/GetTable", "text/csv; charset=utf-8", TABLE_FORMAT_CSV},
+		{"/api/v1/GetTable", "application/json, application/x-ndjson",
+			TABLE_FORMAT_JSONL},
+
+		// The format parameter takes precedence.
+		{"/api/v1/GetTable?format=json", "text/csv", ""},
+		{"/api/v1/GetTable?format=jsonl", "text/csv", TABLE_FORMAT_JSONL},
+	} {
+		r := httptest.NewRequest("GET", test.url, nil)
+		if test.accept != "" {
+			r.Header.Set("Accept", test.accept)
+		}
+
+		format, err := getTableFormat(r)
+		assert.NoError(t, err)
+		assert.Equal(t, test.format, format, test.url+" "+test.accept)
+	}
+
+	_, err := getTableFormat(httptest.NewRequest(
+		"GET", "/api/v1/GetTable?format=xml", nil))
+	assert.Error(t, err)
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"github.com/Velocidex/ordereddict"
+	"github.com/sirupsen/logrus"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"www.velocidex.com/golang/velociraptor/acls"
+	actions_proto "www.velocidex.com/golang/velociraptor/actions/proto"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/legal_hold"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+const (
+	DECOMMISSION_ARTIFACT = "Admin.Client.Decommission"
+)
+
+// Schedule the removal of Velociraptor from the endpoint and mark the
+// client as decommissioned. The client is terminated by the removal
+// so the flow will never complete - the client is marked as soon as
+// the flow is scheduled.
+func (self *ApiServer) UninstallClient(
+	ctx context.Context,
+	in *api_proto.UninstallClientRequest) (*flows_proto.ArtifactCollectorResponse, error) {
+
+	defer Instrument("UninstallClient")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.ClientId == "" || in.ClientId == "server" {
+		return nil, status.Error(codes.InvalidArgument,
+			"A client id is required.")
+	}
+
+	user_name := user_record.Name
+	permissions := acls.LABEL_CLIENT
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to decommission clients.")
+	}
+
+	// CollectArtifact checks the remaining permissions and any
+	// approvals needed for the artifact.
+	result, err := self.CollectArtifact(ctx, &flows_proto.ArtifactCollectorArgs{
+		ClientId:  in.ClientId,
+		Artifacts: []string{DECOMMISSION_ARTIFACT},
+		Specs: []*flows_proto.ArtifactSpec{{
+			Artifact: DECOMMISSION_ARTIFACT,
+			Parameters: &flows_proto.ArtifactParameters{
+				Env: []*actions_proto.VQLEnv{{
+					Key:   "ReallyDoIt",
+					Value: "Y",
+				}},
+			},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	labeler := services.GetLabeler(org_config_obj)
+	err = labeler.SetClientLabel(org_config_obj, in.ClientId,
+		services.DECOMMISSIONED_LABEL)
+	if err != nil {
+		return nil, err
+	}
+	self.cache.InvalidateClient(org_config_obj, in.ClientId)
+
+	logging.GetLogger(org_config_obj, &logging.Audit).
+		WithFields(logrus.Fields{
+			"user":    user_name,
+			"client":  in.ClientId,
+			"flow_id": result.FlowId,
+			"reason":  in.Reason,
+		}).Info("UninstallClient")
+
+	return result, nil
+}
+
+// Remove the client's records, collections, VFS cache and uploads
+// from the server. Without really_do_it this only lists the items
+// which would be removed.
+func (self *ApiServer) DeleteClient(
+	ctx context.Context,
+	in *api_proto.DeleteClientRequest) (*api_proto.GetTableResponse, error) {
+
+	defer Instrument("DeleteClient")()
+
+	users := services.GetUserManager()
+	user_record, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.ClientId == "" || in.ClientId == "server" {
+		return nil, status.Error(codes.InvalidArgument,
+			"A client id is required.")
+	}
+
+	user_name := user_record.Name
+	permissions := acls.SERVER_ADMIN
+	perm, err := acls.CheckAccess(org_config_obj, user_name, permissions)
+	if !perm || err != nil {
+		return nil, status.Error(codes.PermissionDenied,
+			"User is not allowed to delete clients.")
+	}
+
+	// The plugin only logs errors so check for held evidence first.
+	if in.ReallyDoIt {
+		err = legal_hold.CheckClientHolds(org_config_obj, in.ClientId)
+		if err != nil {
+			return nil, legalHoldError(err)
+		}
+	}
+
+	env := ordereddict.NewDict().
+		Set("ClientId", in.ClientId).
+		Set("ReallyDoIt", in.ReallyDoIt)
+
+	result, err := RunVQL(ctx, org_config_obj, user_name, env,
+		"SELECT type, vfs_path FROM client_delete("+
+			"client_id=ClientId, really_do_it=ReallyDoIt)")
+	if err != nil {
+		return nil, err
+	}
+
+	if in.ReallyDoIt {
+		self.cache.InvalidateClient(org_config_obj, in.ClientId)
+
+		logging.GetLogger(org_config_obj, &logging.Audit).
+			WithFields(logrus.Fields{
+				"user":   user_name,
+				"client": in.ClientId,
+				"items":  len(result.Rows),
+				"reason": in.Reason,
+			}).Info("DeleteClient")
+	}
+
+	return result, nil
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"path"
+	"regexp"
+
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+)
+
+var (
+	client_id_regex = regexp.MustCompile(`^(server|C\.[0-9a-zA-Z]+)$`)
+	flow_id_regex   = regexp.MustCompile(`^F\.[0-9a-zA-Z]+$`)
+)
+
+type idValidator struct {
+	regex      *regexp.Regexp
+	error_code api_proto.ApiErrorDetails_ErrorCode
+	name       string
+}
+
+// Request fields holding ids which must be well formed.
+var id_validators = map[protoreflect.Name]idValidator{
+	"client_id":  {client_id_regex, api_proto.ApiErrorDetails_INVALID_CLIENT_ID, "client id"},
+	"client_ids": {client_id_regex, api_proto.ApiErrorDetails_INVALID_CLIENT_ID, "client id"},
+	"flow_id":    {flow_id_regex, api_proto.ApiErrorDetails_INVALID_FLOW_ID, "flow id"},
+	"flow_ids":   {flow_id_regex, api_proto.ApiErrorDetails_INVALID_FLOW_ID, "flow id"},
+}
+
+// Reject requests with malformed ids before they reach the handlers
+// so handlers do not need to check them. Empty ids are left for the
+// handlers to deal with since many are optional.
+func validationInterceptor(ctx context.Context, req interface{},
+	info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	message, ok := req.(proto.Message)
+	if ok {
+		err := validateIds(message)
+		if err != nil {
+			return nil, err
+		}
+
+		err = checkDeprecatedFields(ctx, path.Base(info.FullMethod), message)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return handler(ctx, req)
+}
+
+func validateIds(message proto.Message) error {
+	reflected := message.ProtoReflect()
+	fields := reflected.Descriptor().Fields()
+
+	for name, validator := range id_validators {
+		field := fields.ByName(name)
+		if field == nil || field.Kind() != protoreflect.StringKind {
+			continue
+		}
+
+		values := []string{}
+		if field.IsList() {
+			list := reflected.Get(field).List()
+			for i := 0; i < list.Len(); i++ {
+				values = append(values, list.Get(i).String())
+			}
+		} else {
+			values = append(values, reflected.Get(field).String())
+		}
+
+		for _, value := range values {
+			if value != "" && !validator.regex.MatchString(value) {
+				return newApiError(codes.InvalidArgument, validator.error_code,
+					"Invalid %v %q", validator.name, value)
+			}
+		}
+	}
+
+	return nil
+}
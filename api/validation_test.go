@@ -0,0 +1,31 @@
+package api
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/vtesting/assert"
+)
+
+func TestValidateIds(t *testing.T) {
+	for _, request := range []*api_proto.ApiFlowRequest{
+		{ClientId: "C.1234567890abcdef", FlowId: "F.C4G8KTNPMF3TU"},
+		{ClientId: "server", FlowId: "F.Monitoring"},
+		{},
+	} {
+		assert.NoError(t, validateIds(request))
+	}
+
+	err := validateIds(&api_proto.ApiFlowRequest{
+		ClientId: "C.1234/../../etc", FlowId: "F.1234"})
+	st := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Equal(t, api_proto.ApiErrorDetails_INVALID_CLIENT_ID, getErrorCode(st))
+
+	err = validateIds(&api_proto.ApiFlowRequest{
+		ClientId: "C.1234", FlowId: "H.1234"})
+	assert.Equal(t, api_proto.ApiErrorDetails_INVALID_FLOW_ID,
+		getErrorCode(status.Convert(err)))
+}
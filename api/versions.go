@@ -0,0 +1,256 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/emptypb"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+const (
+	// Clients may send a comma separated list of versions they
+	// accept in this header. The server replies with the version
+	// it served.
+	API_VERSION_HEADER = "Velociraptor-Api-Version"
+
+	CURRENT_API_VERSION = "v2"
+
+	// The gateway passes the negotiated version to the API server
+	// in this metadata key.
+	api_version_metadata = "api-version"
+)
+
+// All versions are served by the same handlers. The gateway routes
+// are registered under v1 so other versions are rewritten to it, and
+// the negotiated version is passed to the handlers in the request
+// metadata (see getApiVersion()).
+var api_versions = []*api_proto.ApiVersion{
+	{Version: "v1", Prefix: "/api/v1/"},
+	{Version: "v2", Prefix: "/api/v2/"},
+}
+
+var api_deprecations = []*api_proto.ApiDeprecation{
+	{
+		Method:      "ListHunts",
+		Field:       "offset",
+		Since:       "v2",
+		Replacement: "page_token",
+		Description: "Offset paging is replaced by page tokens.",
+	},
+	{
+		Method:      "SearchClients",
+		Field:       "offset",
+		Since:       "v2",
+		Replacement: "page_token",
+		Description: "Offset paging is replaced by page tokens.",
+	},
+	{
+		Method:      "GetClientFlows",
+		Field:       "offset",
+		Since:       "v2",
+		Replacement: "page_token",
+		Description: "Offset paging is replaced by page tokens.",
+	},
+	{
+		Method:      "GetTable",
+		Field:       "start_row",
+		Since:       "v2",
+		Replacement: "page_token",
+		Description: "Offset paging is replaced by page tokens.",
+	},
+	{
+		Method:      "GetNotebooks",
+		Field:       "offset",
+		Since:       "v2",
+		Replacement: "page_token",
+		Description: "Offset paging is replaced by page tokens.",
+	},
+}
+
+func (self *ApiServer) GetApiVersions(
+	ctx context.Context,
+	in *emptypb.Empty) (*api_proto.ApiVersions, error) {
+
+	defer Instrument("GetApiVersions")()
+
+	// Any authenticated user may see the versions.
+	users := services.GetUserManager()
+	_, _, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api_proto.ApiVersions{
+		Current:      CURRENT_API_VERSION,
+		Negotiated:   getApiVersion(ctx),
+		Versions:     api_versions,
+		Deprecations: api_deprecations,
+	}, nil
+}
+
+// The version negotiated by the gateway. Direct gRPC clients do not
+// negotiate and get v1.
+func getApiVersion(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if ok {
+		values := md.Get(api_version_metadata)
+		if len(values) > 0 && isSupportedVersion(values[0]) {
+			return values[0]
+		}
+	}
+	return "v1"
+}
+
+func isSupportedVersion(version string) bool {
+	for _, v := range api_versions {
+		if v.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// Versions are listed oldest first.
+func versionIndex(version string) int {
+	for idx, v := range api_versions {
+		if v.Version == version {
+			return idx
+		}
+	}
+	return -1
+}
+
+// Fields deprecated in the negotiated version are rejected so clients
+// which moved to the new version do not keep depending on them. Older
+// versions still accept them and are only warned by the gateway.
+func checkDeprecatedFields(ctx context.Context,
+	method string, message proto.Message) error {
+	version := getApiVersion(ctx)
+	reflected := message.ProtoReflect()
+	fields := reflected.Descriptor().Fields()
+
+	for _, deprecation := range api_deprecations {
+		if deprecation.Method != method ||
+			versionIndex(version) < versionIndex(deprecation.Since) {
+			continue
+		}
+
+		field := fields.ByName(protoreflect.Name(deprecation.Field))
+		if field == nil || !reflected.Has(field) {
+			continue
+		}
+
+		return status.Error(codes.InvalidArgument, fmt.Sprintf(
+			"Field %v is not supported in API %v, use %v",
+			deprecation.Field, version, deprecation.Replacement))
+	}
+	return nil
+}
+
+// Pick the first supported version the client accepts. Without a
+// header the version in the path is used.
+func negotiateVersion(header, path_version string) (string, bool) {
+	if header == "" {
+		return path_version, true
+	}
+
+	for _, version := range strings.Split(header, ",") {
+		version = strings.ToLower(strings.TrimSpace(version))
+		if isSupportedVersion(version) {
+			return version, true
+		}
+	}
+	return "", false
+}
+
+// Serve the gateway for requests under /api/<path_version>/. The
+// base path must already be stripped.
+func versionHandler(path_version string, parent http.Handler) http.Handler {
+	path_prefix := "/api/" + path_version + "/"
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version, ok := negotiateVersion(
+			r.Header.Get(API_VERSION_HEADER), path_version)
+		if !ok {
+			writeUnsupportedVersion(w, r.Header.Get(API_VERSION_HEADER))
+			return
+		}
+
+		method := strings.SplitN(
+			strings.TrimPrefix(r.URL.Path, path_prefix), "/", 2)[0]
+		addDeprecationHeaders(w, r, method)
+
+		w.Header().Set(API_VERSION_HEADER, version)
+		r.Header.Set("Grpc-Metadata-"+api_version_metadata, version)
+
+		if path_version != "v1" {
+			r.URL.Path = "/api/v1/" + strings.TrimPrefix(r.URL.Path, path_prefix)
+			r.URL.RawPath = ""
+		}
+
+		parent.ServeHTTP(w, r)
+	})
+}
+
+// Warn clients which still use deprecated fields (RFC 8594).
+func addDeprecationHeaders(w http.ResponseWriter, r *http.Request, method string) {
+	query := r.URL.Query()
+	for _, deprecation := range api_deprecations {
+		if deprecation.Method != method {
+			continue
+		}
+
+		if query.Get(deprecation.Field) == "" &&
+			query.Get(jsonName(deprecation.Field)) == "" {
+			continue
+		}
+
+		w.Header().Set("Deprecation", "true")
+		if deprecation.Sunset != "" {
+			w.Header().Set("Sunset", deprecation.Sunset)
+		}
+		w.Header().Add("Warning", "299 - \"Field "+deprecation.Field+
+			" is deprecated, use "+deprecation.Replacement+"\"")
+	}
+}
+
+// The gateway accepts query parameters in either the proto or the
+// json name (e.g. start_row or startRow).
+func jsonName(field string) string {
+	parts := strings.Split(field, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+func writeUnsupportedVersion(w http.ResponseWriter, requested string) {
+	body := &api_proto.ApiErrorResponse{
+		Code: int32(codes.InvalidArgument),
+		ErrorCode: strings.ToLower(
+			api_proto.ApiErrorDetails_UNSUPPORTED_API_VERSION.String()),
+		Message: "Unsupported API version " + requested,
+	}
+
+	serialized, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(body)
+	if err != nil {
+		http.Error(w, body.Message, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_, _ = w.Write(serialized)
+}
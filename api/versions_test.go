@@ -0,0 +1,84 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/vtesting/assert"
+)
+
+func TestApiVersions(t *testing.T) {
+	var path, metadata_version string
+	parent := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		metadata_version = r.Header.Get("Grpc-Metadata-Api-Version")
+	})
+	handler := versionHandler("v2", parent)
+
+	// v2 requests are served by the v1 routes.
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(
+		"GET", "/api/v2/GetClientFlows/C.1234", nil))
+	assert.Equal(t, "/api/v1/GetClientFlows/C.1234", path)
+	assert.Equal(t, "v2", metadata_version)
+	assert.Equal(t, "v2", recorder.Header().Get(API_VERSION_HEADER))
+	assert.Equal(t, "", recorder.Header().Get("Deprecation"))
+
+	// The header negotiates the version.
+	recorder = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/v2/ListHunts?offset=10", nil)
+	req.Header.Set(API_VERSION_HEADER, "v3, v1")
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(t, "v1", metadata_version)
+	assert.Equal(t, "v1", recorder.Header().Get(API_VERSION_HEADER))
+
+	// Deprecated fields are flagged.
+	assert.Equal(t, "true", recorder.Header().Get("Deprecation"))
+
+	// Json names are also recognized.
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(
+		"GET", "/api/v2/GetTable?startRow=10", nil))
+	assert.Equal(t, "true", recorder.Header().Get("Deprecation"))
+
+	// Unsupported versions are rejected.
+	path = ""
+	recorder = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/api/v2/ListHunts", nil)
+	req.Header.Set(API_VERSION_HEADER, "v3")
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, "", path)
+
+	body := make(map[string]interface{})
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	assert.Equal(t, "unsupported_api_version", body["error_code"])
+}
+
+func TestDeprecatedFields(t *testing.T) {
+	v2 := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(api_version_metadata, "v2"))
+
+	// Direct gRPC callers and v1 clients may still use offsets.
+	request := &api_proto.ListHuntsRequest{Offset: 10}
+	assert.NoError(t, checkDeprecatedFields(
+		context.Background(), "ListHunts", request))
+
+	// They are rejected in v2.
+	err := checkDeprecatedFields(v2, "ListHunts", request)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	assert.NoError(t, checkDeprecatedFields(v2, "ListHunts",
+		&api_proto.ListHuntsRequest{PageToken: "token"}))
+
+	// Other methods are not affected.
+	assert.NoError(t, checkDeprecatedFields(v2, "GetHuntFlows",
+		&api_proto.GetTableRequest{StartRow: 10}))
+}
@@ -0,0 +1,475 @@
+package api
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/participle/lexer"
+	"github.com/pkg/errors"
+	context "golang.org/x/net/context"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/utils"
+	"www.velocidex.com/golang/vfilter"
+)
+
+// A language server style API for VQL editors. Completions, hover
+// documentation and diagnostics are computed from the plugins and
+// functions actually registered in this server, and the artifacts in
+// its repository.
+
+const (
+	VQL_DIAGNOSTIC_ERROR   = "error"
+	VQL_DIAGNOSTIC_WARNING = "warning"
+)
+
+var (
+	vql_keywords = []string{
+		"SELECT", "FROM", "LET", "WHERE", "LIMIT", "GROUP BY",
+		"ORDER BY", "AND", "OR", "NOT", "IN", "AS", "DESC",
+	}
+
+	// Words which may be followed by a parenthesis without being a
+	// call.
+	vql_operators = map[string]bool{
+		"SELECT": true, "FROM": true, "WHERE": true, "AND": true,
+		"OR": true, "NOT": true, "IN": true, "AS": true, "LIMIT": true,
+		"BY": true, "LET": true,
+	}
+
+	vql_word_regex = regexp.MustCompile(`[a-zA-Z0-9_.]*$`)
+	vql_call_regex = regexp.MustCompile(`([a-zA-Z_][a-zA-Z0-9_.]*)\(`)
+	vql_let_regex  = regexp.MustCompile(`(?i)\bLET\s+([a-zA-Z_][a-zA-Z0-9_]*)`)
+	vql_arg_regex  = regexp.MustCompile(`([a-zA-Z_][a-zA-Z0-9_]*)\s*=[^~]`)
+	vql_from_regex = regexp.MustCompile(`(?i)\bFROM\s*$`)
+)
+
+func (self *ApiServer) VQLComplete(
+	ctx context.Context,
+	in *api_proto.VQLLanguageRequest) (*api_proto.KeywordCompletions, error) {
+
+	defer Instrument("VQLComplete")()
+
+	users := services.GetUserManager()
+	_, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	registry, err := getLanguageRegistry(ctx, org_config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api_proto.KeywordCompletions{
+		Items: completeVQL(in.Vql, vqlOffset(in.Vql, in.Position), registry),
+	}, nil
+}
+
+func (self *ApiServer) VQLHover(
+	ctx context.Context,
+	in *api_proto.VQLLanguageRequest) (*api_proto.Completion, error) {
+
+	defer Instrument("VQLHover")()
+
+	users := services.GetUserManager()
+	_, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	registry, err := getLanguageRegistry(ctx, org_config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	result := hoverVQL(in.Vql, vqlOffset(in.Vql, in.Position), registry)
+	if result == nil {
+		result = &api_proto.Completion{}
+	}
+	return result, nil
+}
+
+func (self *ApiServer) VQLDiagnose(
+	ctx context.Context,
+	in *api_proto.VQLLanguageRequest) (*api_proto.VQLDiagnostics, error) {
+
+	defer Instrument("VQLDiagnose")()
+
+	users := services.GetUserManager()
+	_, org_config_obj, err := users.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	registry, err := getLanguageRegistry(ctx, org_config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api_proto.VQLDiagnostics{
+		Items: diagnoseVQL(in.Vql, registry),
+	}, nil
+}
+
+// The plugins and functions registered in this binary and the
+// artifacts in the org's repository.
+func getLanguageRegistry(
+	ctx context.Context, config_obj *config_proto.Config) (
+	map[string]*api_proto.Completion, error) {
+	artifacts, err := getArtifactCompletions(ctx, config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*api_proto.Completion)
+	for _, item := range append(IntrospectDescription(), artifacts...) {
+		result[item.Name] = item
+	}
+	return result, nil
+}
+
+func completeVQL(vql string, offset int,
+	registry map[string]*api_proto.Completion) []*api_proto.Completion {
+	// No completions inside strings or comments.
+	if inVQLLiteral(vql, offset) {
+		return nil
+	}
+
+	masked := maskVQL(vql)
+	before := masked[:offset]
+	prefix := vql_word_regex.FindString(before)
+	before = strings.TrimRight(before[:len(before)-len(prefix)], " \t\r\n")
+
+	result := []*api_proto.Completion{}
+	add := func(item *api_proto.Completion) {
+		if strings.HasPrefix(strings.ToLower(item.Name),
+			strings.ToLower(prefix)) {
+			result = append(result, item)
+		}
+	}
+
+	// Complete the arguments of the call the cursor is in.
+	name, start := enclosingCall(before)
+	if name != "" && (strings.HasSuffix(before, "(") ||
+		strings.HasSuffix(before, ",")) {
+		item, pres := registry[name]
+		if !pres {
+			return result
+		}
+
+		used := make(map[string]bool)
+		for _, match := range vql_arg_regex.FindAllStringSubmatch(
+			before[start:], -1) {
+			used[match[1]] = true
+		}
+
+		for _, arg := range item.Args {
+			if !used[arg.Name] {
+				add(&api_proto.Completion{
+					Name:        arg.Name,
+					Description: arg.Description,
+					Type:        "Argument",
+				})
+			}
+		}
+		return result
+	}
+
+	// After FROM only plugins and queries make sense.
+	after_from := vql_from_regex.MatchString(before)
+	if !after_from {
+		for _, keyword := range vql_keywords {
+			add(&api_proto.Completion{Name: keyword, Type: "Keyword"})
+		}
+	}
+
+	for _, name := range letNames(masked) {
+		add(&api_proto.Completion{Name: name, Type: "Variable"})
+	}
+
+	names := []string{}
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		item := registry[name]
+		if after_from && item.Type == "Function" {
+			continue
+		}
+		add(item)
+	}
+
+	return result
+}
+
+// Describe the plugin, function or artifact under the cursor.
+func hoverVQL(vql string, offset int,
+	registry map[string]*api_proto.Completion) *api_proto.Completion {
+	start := len(vql_word_regex.FindString(vql[:offset]))
+	end := offset
+	for end < len(vql) && isVQLWordChar(vql[end]) {
+		end++
+	}
+
+	item, pres := registry[vql[offset-start:end]]
+	if !pres {
+		return nil
+	}
+	return item
+}
+
+func diagnoseVQL(vql string,
+	registry map[string]*api_proto.Completion) []*api_proto.VQLDiagnostic {
+	result := []*api_proto.VQLDiagnostic{}
+
+	_, err := vfilter.MultiParse(vql)
+	if err != nil {
+		diagnostic := &api_proto.VQLDiagnostic{
+			Severity: VQL_DIAGNOSTIC_ERROR,
+			Message:  err.Error(),
+			Position: &api_proto.VQLPosition{Line: 1, Column: 1},
+		}
+
+		lexer_err, ok := errors.Cause(err).(*lexer.Error)
+		if ok {
+			diagnostic.Message = lexer_err.Msg
+			diagnostic.Position = vqlPosition(vql, lexer_err.Tok.Pos.Offset)
+		}
+
+		return append(result, diagnostic)
+	}
+
+	masked := maskVQL(vql)
+	defined := make(map[string]bool)
+	for _, name := range letNames(masked) {
+		defined[name] = true
+	}
+
+	for _, match := range vql_call_regex.FindAllStringSubmatchIndex(masked, -1) {
+		name := masked[match[2]:match[3]]
+
+		// Skip the tail of longer words and operators.
+		if (match[2] > 0 && isVQLWordChar(masked[match[2]-1])) ||
+			vql_operators[strings.ToUpper(name)] || defined[name] {
+			continue
+		}
+
+		item, pres := registry[name]
+		if !pres {
+			result = append(result, &api_proto.VQLDiagnostic{
+				Severity: VQL_DIAGNOSTIC_WARNING,
+				Message:  "Unknown plugin or function " + name,
+				Position: vqlPosition(vql, match[2]),
+			})
+			continue
+		}
+
+		// Artifacts and some functions (e.g. dict()) take any
+		// arguments.
+		if len(item.Args) == 0 || item.Type == "Artifact" {
+			continue
+		}
+
+		known := make(map[string]bool)
+		for _, arg := range item.Args {
+			known[arg.Name] = true
+		}
+
+		for _, arg := range callArgs(masked, match[1]) {
+			if !known[masked[arg[0]:arg[1]]] {
+				result = append(result, &api_proto.VQLDiagnostic{
+					Severity: VQL_DIAGNOSTIC_WARNING,
+					Message: "Unknown argument " + masked[arg[0]:arg[1]] +
+						" to " + name,
+					Position: vqlPosition(vql, arg[0]),
+				})
+			}
+		}
+	}
+
+	return result
+}
+
+// Find the call whose argument list is open at the end of the
+// text. Returns the name and the offset of the argument list.
+func enclosingCall(text string) (string, int) {
+	depth := 0
+	for i := len(text) - 1; i >= 0; i-- {
+		switch text[i] {
+		case ')', '}', ']':
+			depth++
+		case '{', '[':
+			if depth == 0 {
+				return "", 0
+			}
+			depth--
+		case '(':
+			if depth > 0 {
+				depth--
+				continue
+			}
+			name := vql_word_regex.FindString(text[:i])
+			return name, i + 1
+		}
+	}
+	return "", 0
+}
+
+// Returns the offsets of the argument names of the call whose
+// argument list starts at start.
+func callArgs(masked string, start int) [][]int {
+	result := [][]int{}
+	depth := 0
+	arg_start := start
+	for i := start; i < len(masked); i++ {
+		switch masked[i] {
+		case '(', '{', '[':
+			depth++
+		case ')', '}', ']':
+			if depth == 0 {
+				return append(result, argName(masked, arg_start, i)...)
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				result = append(result, argName(masked, arg_start, i)...)
+				arg_start = i + 1
+			}
+		}
+	}
+	return result
+}
+
+func argName(masked string, start, end int) [][]int {
+	match := vql_arg_regex.FindStringSubmatchIndex(masked[start:end] + " ")
+	if match == nil || strings.TrimSpace(masked[start:start+match[2]]) != "" {
+		return nil
+	}
+	return [][]int{{start + match[2], start + match[3]}}
+}
+
+func letNames(masked string) []string {
+	result := []string{}
+	for _, match := range vql_let_regex.FindAllStringSubmatch(masked, -1) {
+		if !utils.InString(result, match[1]) {
+			result = append(result, match[1])
+		}
+	}
+	return result
+}
+
+// Replace the content of strings and comments with spaces so they
+// are not mistaken for VQL. Offsets and newlines are preserved.
+func maskVQL(vql string) string {
+	return string(maskVQLWith(vql, ' '))
+}
+
+// Is the offset inside a string or comment?
+func inVQLLiteral(vql string, offset int) bool {
+	return maskVQLWith(vql[:offset]+"x", 0)[offset] == 0
+}
+
+func maskVQLWith(vql string, fill byte) []byte {
+	result := []byte(vql)
+	blank := func(start, end int) {
+		for i := start; i < end && i < len(result); i++ {
+			if result[i] != '\n' {
+				result[i] = fill
+			}
+		}
+	}
+
+	for i := 0; i < len(vql); i++ {
+		rest := vql[i:]
+		switch {
+		case strings.HasPrefix(rest, "'''"):
+			end := strings.Index(rest[3:], "'''")
+			if end < 0 {
+				end = len(rest) - 3
+			}
+			blank(i+3, i+3+end)
+			i += end + 5
+
+		case rest[0] == '\'' || rest[0] == '"':
+			end := 1
+			for end < len(rest) && rest[end] != rest[0] {
+				if rest[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			blank(i+1, i+end)
+			i += end
+
+		case strings.HasPrefix(rest, "--") || strings.HasPrefix(rest, "//"):
+			end := strings.Index(rest, "\n")
+			if end < 0 {
+				end = len(rest)
+			}
+			blank(i, i+end)
+			i += end
+
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest, "*/")
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end += 2
+			}
+			blank(i, i+end)
+			i += end - 1
+		}
+	}
+
+	return result
+}
+
+func isVQLWordChar(c byte) bool {
+	return c == '_' || c == '.' || (c >= 'a' && c <= 'z') ||
+		(c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// Positions are 1 based lines and columns like the parser's errors.
+func vqlOffset(vql string, position *api_proto.VQLPosition) int {
+	if position == nil {
+		return len(vql)
+	}
+
+	line, column := uint64(1), uint64(1)
+	for i, c := range vql {
+		if line == position.Line && column == position.Column {
+			return i
+		}
+		if c == '\n' {
+			if line == position.Line {
+				return i
+			}
+			line++
+			column = 1
+			continue
+		}
+		column++
+	}
+	return len(vql)
+}
+
+func vqlPosition(vql string, offset int) *api_proto.VQLPosition {
+	result := &api_proto.VQLPosition{Line: 1, Column: 1}
+	for i, c := range vql {
+		if i >= offset {
+			break
+		}
+		if c == '\n' {
+			result.Line++
+			result.Column = 1
+			continue
+		}
+		result.Column++
+	}
+	return result
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"testing"
+
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/vtesting/assert"
+)
+
+var test_registry = map[string]*api_proto.Completion{
+	"info": {Name: "info", Type: "Plugin"},
+	"glob": {Name: "glob", Type: "Plugin", Args: []*api_proto.ArgDescriptor{
+		{Name: "globs"}, {Name: "root"}, {Name: "accessor"}}},
+	"format": {Name: "format", Type: "Function", Args: []*api_proto.ArgDescriptor{
+		{Name: "format"}, {Name: "args"}}},
+	"Artifact.Generic.Client.Info": {
+		Name: "Artifact.Generic.Client.Info", Type: "Artifact"},
+}
+
+func completionNames(items []*api_proto.Completion) []string {
+	result := []string{}
+	for _, item := range items {
+		result = append(result, item.Name)
+	}
+	return result
+}
+
+func TestVQLComplete(t *testing.T) {
+	// Only plugins are offered after FROM.
+	vql := "SELECT * FROM g"
+	assert.Equal(t, []string{"glob"},
+		completionNames(completeVQL(vql, len(vql), test_registry)))
+
+	// Unused arguments of the enclosing call.
+	vql = "SELECT * FROM glob(globs='/*', "
+	assert.Equal(t, []string{"root", "accessor"},
+		completionNames(completeVQL(vql, len(vql), test_registry)))
+
+	// Nothing inside strings.
+	vql = "SELECT * FROM glob(globs='fo"
+	assert.Equal(t, 0, len(completeVQL(vql, len(vql), test_registry)))
+
+	// LET variables are completed too.
+	vql = "LET hits = SELECT * FROM info() SELECT * FROM hi"
+	assert.Equal(t, []string{"hits"},
+		completionNames(completeVQL(vql, len(vql), test_registry)))
+}
+
+func TestVQLHover(t *testing.T) {
+	vql := "SELECT * FROM Artifact.Generic.Client.Info()"
+	item := hoverVQL(vql, 20, test_registry)
+	assert.NotNil(t, item)
+	assert.Equal(t, "Artifact.Generic.Client.Info", item.Name)
+
+	assert.True(t, hoverVQL(vql, 2, test_registry) == nil)
+}
+
+func TestVQLDiagnose(t *testing.T) {
+	// Unknown plugins and arguments are warnings.
+	vql := "SELECT format(format='%v', foo=1) FROM\n  nosuch()"
+	diagnostics := diagnoseVQL(vql, test_registry)
+	assert.Equal(t, 2, len(diagnostics))
+	assert.Equal(t, "Unknown argument foo to format", diagnostics[0].Message)
+	assert.Equal(t, uint64(28), diagnostics[0].Position.Column)
+	assert.Equal(t, "Unknown plugin or function nosuch",
+		diagnostics[1].Message)
+	assert.Equal(t, uint64(2), diagnostics[1].Position.Line)
+	assert.Equal(t, uint64(3), diagnostics[1].Position.Column)
+
+	// Syntax errors are reported on their own.
+	diagnostics = diagnoseVQL("SELECT * FROM info( WHERE", test_registry)
+	assert.Equal(t, 1, len(diagnostics))
+	assert.Equal(t, VQL_DIAGNOSTIC_ERROR, diagnostics[0].Severity)
+
+	// Names in strings and comments are ignored.
+	diagnostics = diagnoseVQL(
+		"-- nosuch()\nSELECT 'nosuch()' FROM info()", test_registry)
+	assert.Equal(t, 0, len(diagnostics))
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	"www.velocidex.com/golang/velociraptor/acls"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/json"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/utils"
+)
+
+// The Watch endpoint pushes state changes to the GUI and API clients
+// as server sent events so they do not need to poll
+// GetFlowDetails. Callers may restrict the events to a single
+// client with the client_id parameter and to some event types with a
+// comma separated types parameter.
+const (
+	WATCH_FLOW_COMPLETED = "flow_completed"
+	WATCH_CLIENT_CHECKIN = "client_checkin"
+	WATCH_VFS_REFRESHED  = "vfs_refreshed"
+
+	// Proxies close idle connections so send a comment periodically.
+	watch_keepalive = 30 * time.Second
+)
+
+type WatchEvent struct {
+	Type      string `json:"type"`
+	ClientId  string `json:"client_id"`
+	FlowId    string `json:"flow_id,omitempty"`
+	State     string `json:"state,omitempty"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+func watchHandler(config_obj *config_proto.Config) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userinfo := GetUserInfo(r.Context(), config_obj)
+		permissions := acls.READ_RESULTS
+		perm, err := acls.CheckAccess(config_obj, userinfo.Name, permissions)
+		if !perm || err != nil {
+			returnError(w, http.StatusUnauthorized,
+				"User is not allowed to watch events.")
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			returnError(w, http.StatusInternalServerError,
+				"Streaming is not supported")
+			return
+		}
+
+		client_id := r.URL.Query().Get("client_id")
+		types := parseWatchTypes(r.URL.Query().Get("types"))
+
+		journal, err := services.GetJournal(config_obj)
+		if err != nil {
+			returnError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		// Unwatched queues stay nil and block forever below.
+		var flow_chan, checkin_chan <-chan *ordereddict.Dict
+		if types[WATCH_FLOW_COMPLETED] || types[WATCH_VFS_REFRESHED] {
+			var flow_cancel func()
+			flow_chan, flow_cancel = journal.Watch(
+				ctx, "System.Flow.Completion", "WatchAPI")
+			defer flow_cancel()
+		}
+
+		if types[WATCH_CLIENT_CHECKIN] {
+			var checkin_cancel func()
+			checkin_chan, checkin_cancel = journal.Watch(
+				ctx, "Server.Internal.ClientCheckin", "WatchAPI")
+			defer checkin_cancel()
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		for {
+			var event *WatchEvent
+
+			select {
+			case <-ctx.Done():
+				return
+
+			case row, ok := <-flow_chan:
+				if !ok {
+					return
+				}
+				event = flowCompletionEvent(row)
+
+			case row, ok := <-checkin_chan:
+				if !ok {
+					return
+				}
+				event = &WatchEvent{
+					Type:      WATCH_CLIENT_CHECKIN,
+					ClientId:  utils.GetString(row, "ClientId"),
+					Timestamp: time.Now().Unix(),
+				}
+
+			case <-time.After(watch_keepalive):
+				_, err := fmt.Fprint(w, ": keepalive\n\n")
+				if err != nil {
+					return
+				}
+				flusher.Flush()
+				continue
+			}
+
+			if event == nil || !types[event.Type] ||
+				(client_id != "" && event.ClientId != client_id) {
+				continue
+			}
+
+			serialized, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+
+			_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n",
+				event.Type, serialized)
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	})
+}
+
+// All event types are sent by default.
+func parseWatchTypes(types string) map[string]bool {
+	result := make(map[string]bool)
+	for _, t := range strings.Split(types, ",") {
+		t = strings.TrimSpace(t)
+		if t != "" {
+			result[t] = true
+		}
+	}
+
+	if len(result) == 0 {
+		result[WATCH_FLOW_COMPLETED] = true
+		result[WATCH_CLIENT_CHECKIN] = true
+		result[WATCH_VFS_REFRESHED] = true
+	}
+	return result
+}
+
+// VFS refreshes are flows collecting System.VFS.ListDirectory.
+func flowCompletionEvent(row *ordereddict.Dict) *WatchEvent {
+	flow := &flows_proto.ArtifactCollectorContext{}
+	flow_any, _ := row.Get("Flow")
+	err := utils.ParseIntoProtobuf(flow_any, flow)
+	if err != nil {
+		return nil
+	}
+
+	event := &WatchEvent{
+		Type:      WATCH_FLOW_COMPLETED,
+		ClientId:  utils.GetString(row, "ClientId"),
+		FlowId:    utils.GetString(row, "FlowId"),
+		State:     flow.State.String(),
+		Timestamp: time.Now().Unix(),
+	}
+
+	if utils.InString(flow.ArtifactsWithResults, "System.VFS.ListDirectory") {
+		event.Type = WATCH_VFS_REFRESHED
+	}
+
+	return event
+}
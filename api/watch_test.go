@@ -0,0 +1,37 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/Velocidex/ordereddict"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/vtesting/assert"
+)
+
+func TestWatchEvents(t *testing.T) {
+	types := parseWatchTypes("")
+	assert.Equal(t, 3, len(types))
+
+	types = parseWatchTypes("flow_completed, vfs_refreshed")
+	assert.True(t, types[WATCH_FLOW_COMPLETED])
+	assert.True(t, types[WATCH_VFS_REFRESHED])
+	assert.True(t, !types[WATCH_CLIENT_CHECKIN])
+
+	row := ordereddict.NewDict().
+		Set("ClientId", "C.123").
+		Set("FlowId", "F.1").
+		Set("Flow", &flows_proto.ArtifactCollectorContext{
+			State:                flows_proto.ArtifactCollectorContext_FINISHED,
+			ArtifactsWithResults: []string{"Generic.Client.Info"},
+		})
+	event := flowCompletionEvent(row)
+	assert.Equal(t, WATCH_FLOW_COMPLETED, event.Type)
+	assert.Equal(t, "C.123", event.ClientId)
+	assert.Equal(t, "F.1", event.FlowId)
+	assert.Equal(t, "FINISHED", event.State)
+
+	row.Set("Flow", &flows_proto.ArtifactCollectorContext{
+		ArtifactsWithResults: []string{"System.VFS.ListDirectory"},
+	})
+	assert.Equal(t, WATCH_VFS_REFRESHED, flowCompletionEvent(row).Type)
+}
@@ -0,0 +1,375 @@
+// API keys allow automation to call the API as a service account
+// rather than impersonating a human user.
+//
+// A key authenticates as a service account in a single org. The
+// service account's permissions in the org are set when the key is
+// issued so the key is scoped to exactly those permissions. Keys are
+// presented to the GUI's API gateway in the Authorization header as
+// "Bearer <token>" and only a hash of the secret part of the token is
+// stored.
+
+package apikeys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+	"www.velocidex.com/golang/velociraptor/acls"
+	acl_proto "www.velocidex.com/golang/velociraptor/acls/proto"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/datastore"
+	"www.velocidex.com/golang/velociraptor/file_store/api"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/paths"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/services/users"
+	"www.velocidex.com/golang/velociraptor/utils"
+)
+
+const (
+	TOKEN_PREFIX = "vrk_"
+
+	// Only record the last use of a key this often to avoid a
+	// datastore write on every request.
+	LAST_USED_RESOLUTION = time.Minute
+)
+
+var (
+	Clock utils.Clock = &utils.RealClock{}
+
+	// Callers can check for this with errors.Is()
+	InvalidKeyError = errors.New("Invalid API key")
+
+	idRegex = regexp.MustCompile("^[0-9a-f]{16}$")
+)
+
+func keyPath(id string) api.DSPathSpec {
+	return paths.API_KEYS_ROOT.AddChild(id)
+}
+
+// Issue a new key. The token is only returned here. Keys are stored
+// in the root org so the gateway can find them before it knows the
+// org.
+func Create(
+	root_config_obj, org_config_obj *config_proto.Config,
+	in *api_proto.CreateApiKeyRequest,
+	creator string) (*api_proto.CreateApiKeyResponse, error) {
+
+	err := checkPrincipal(root_config_obj, org_config_obj, in.Principal)
+	if err != nil {
+		return nil, err
+	}
+
+	err = setServiceAccount(org_config_obj, in)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+
+	now := Clock.Now()
+	key := &api_proto.ApiKey{
+		Id:          id,
+		Principal:   in.Principal,
+		OrgId:       org_config_obj.OrgId,
+		Description: in.Description,
+		Created:     uint64(now.Unix()),
+		Creator:     creator,
+		Hash:        hashSecret(secret),
+	}
+
+	if in.ExpiresIn > 0 {
+		key.Expires = uint64(now.Add(
+			time.Duration(in.ExpiresIn) * time.Second).Unix())
+	}
+
+	db, err := datastore.GetDB(root_config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.SetSubject(root_config_obj, keyPath(id), key)
+	if err != nil {
+		return nil, err
+	}
+
+	audit(root_config_obj, key, creator, "CreateApiKey")
+
+	return &api_proto.CreateApiKeyResponse{
+		Key:   redact(key),
+		Token: TOKEN_PREFIX + id + "_" + secret,
+	}, nil
+}
+
+// The server's own identity and the gateway are trusted by the gRPC
+// server so keys may never authenticate as them.
+func isReservedPrincipal(config_obj *config_proto.Config, principal string) bool {
+	return principal == config_obj.GetClient().GetPinnedServerName() ||
+		principal == config_obj.GetAPI().GetPinnedGwName()
+}
+
+// API client certificates are given an ACL policy without a user
+// record. Issuing a key with the same name would replace the
+// certificate's policy and let the key act as the certificate.
+func checkPrincipal(
+	root_config_obj, org_config_obj *config_proto.Config,
+	principal string) error {
+	if isReservedPrincipal(root_config_obj, principal) {
+		return fmt.Errorf("%v is reserved: API keys can not be issued for it",
+			principal)
+	}
+
+	_, err := services.GetUserManager().GetUser(principal)
+	if err == nil {
+		return nil
+	}
+
+	for _, config_obj := range []*config_proto.Config{
+		root_config_obj, org_config_obj} {
+		_, err := acls.GetPolicy(config_obj, principal)
+		if err == nil {
+			return fmt.Errorf(
+				"%v is an API client: API keys can not be issued for it",
+				principal)
+		}
+	}
+	return nil
+}
+
+// Create the service account if needed and give it the requested
+// permissions in the org.
+func setServiceAccount(
+	org_config_obj *config_proto.Config,
+	in *api_proto.CreateApiKeyRequest) error {
+
+	user_record, err := users.NewUserRecord(in.Principal)
+	if err != nil {
+		return err
+	}
+
+	users_manager := services.GetUserManager()
+	existing, err := users_manager.GetUser(in.Principal)
+	if err == nil {
+		if !existing.ServiceAccount {
+			return fmt.Errorf(
+				"%v is not a service account: API keys can not be issued for users",
+				in.Principal)
+		}
+		user_record = existing
+	}
+	user_record.ServiceAccount = true
+
+	policy := &acl_proto.ApiClientACL{}
+	if in.Permissions != nil {
+		policy = proto.Clone(in.Permissions).(*acl_proto.ApiClientACL)
+	}
+	policy.Roles = nil
+
+	for _, role := range in.Roles {
+		if !acls.IsValidRole(org_config_obj, role) {
+			return fmt.Errorf("Invalid role %v", role)
+		}
+		policy.Roles = append(policy.Roles, role)
+	}
+
+	err = acls.SetPolicy(org_config_obj, in.Principal, policy)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, org := range user_record.Orgs {
+		if org.Id == org_config_obj.OrgId {
+			found = true
+		}
+	}
+	if !found {
+		user_record.Orgs = append(user_record.Orgs, &api_proto.Org{
+			Name: org_config_obj.OrgName,
+			Id:   org_config_obj.OrgId,
+		})
+	}
+
+	return users_manager.SetUser(user_record)
+}
+
+// List the keys in the org, optionally only those of a single
+// service account.
+func List(
+	root_config_obj *config_proto.Config,
+	org_id, principal string) (*api_proto.ApiKeys, error) {
+	db, err := datastore.GetDB(root_config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	children, err := db.ListChildren(root_config_obj, paths.API_KEYS_ROOT)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &api_proto.ApiKeys{}
+	for _, child := range children {
+		if child.IsDir() {
+			continue
+		}
+
+		key := &api_proto.ApiKey{}
+		err := db.GetSubject(root_config_obj, child, key)
+		if err != nil || key.Id == "" {
+			continue
+		}
+
+		if key.OrgId != org_id ||
+			(principal != "" && key.Principal != principal) {
+			continue
+		}
+
+		result.Items = append(result.Items, redact(key))
+	}
+
+	return result, nil
+}
+
+func Revoke(
+	root_config_obj *config_proto.Config,
+	org_id, id, principal string) error {
+	key, err := get(root_config_obj, id)
+	if err != nil {
+		return err
+	}
+
+	// Keys can only be revoked from the org they were issued in.
+	if key.OrgId != org_id {
+		return InvalidKeyError
+	}
+
+	db, err := datastore.GetDB(root_config_obj)
+	if err != nil {
+		return err
+	}
+
+	err = db.DeleteSubject(root_config_obj, keyPath(id))
+	if err != nil {
+		return err
+	}
+
+	audit(root_config_obj, key, principal, "RevokeApiKey")
+	return nil
+}
+
+// Check the token and return the key it belongs to.
+func Verify(
+	root_config_obj *config_proto.Config, token string) (*api_proto.ApiKey, error) {
+	if !strings.HasPrefix(token, TOKEN_PREFIX) {
+		return nil, InvalidKeyError
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(token, TOKEN_PREFIX), "_", 2)
+	if len(parts) != 2 {
+		return nil, InvalidKeyError
+	}
+
+	key, err := get(root_config_obj, parts[0])
+	if err != nil {
+		return nil, InvalidKeyError
+	}
+
+	if subtle.ConstantTimeCompare(
+		[]byte(hashSecret(parts[1])), []byte(key.Hash)) != 1 {
+		return nil, InvalidKeyError
+	}
+
+	// Also rejects keys issued before reserved names were checked.
+	if isReservedPrincipal(root_config_obj, key.Principal) {
+		return nil, fmt.Errorf("%w: reserved principal", InvalidKeyError)
+	}
+
+	now := Clock.Now()
+	if key.Expires > 0 && uint64(now.Unix()) > key.Expires {
+		return nil, fmt.Errorf("%w: key expired", InvalidKeyError)
+	}
+
+	// Locking the service account disables all its keys.
+	user_record, err := services.GetUserManager().GetUser(key.Principal)
+	if err != nil || !user_record.ServiceAccount || user_record.Locked {
+		return nil, fmt.Errorf("%w: service account disabled", InvalidKeyError)
+	}
+
+	if now.Sub(time.Unix(int64(key.LastUsed), 0)) > LAST_USED_RESOLUTION {
+		key.LastUsed = uint64(now.Unix())
+		db, err := datastore.GetDB(root_config_obj)
+		if err == nil {
+			_ = db.SetSubject(root_config_obj, keyPath(key.Id), key)
+		}
+	}
+
+	return redact(key), nil
+}
+
+func get(root_config_obj *config_proto.Config, id string) (*api_proto.ApiKey, error) {
+	if !idRegex.MatchString(id) {
+		return nil, InvalidKeyError
+	}
+
+	db, err := datastore.GetDB(root_config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &api_proto.ApiKey{}
+	err = db.GetSubject(root_config_obj, keyPath(id), key)
+	if err != nil || key.Id != id {
+		return nil, InvalidKeyError
+	}
+	return key, nil
+}
+
+func redact(key *api_proto.ApiKey) *api_proto.ApiKey {
+	key = proto.Clone(key).(*api_proto.ApiKey)
+	key.Hash = ""
+	return key
+}
+
+func hashSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+func randomHex(length int) (string, error) {
+	buf := make([]byte, length)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func audit(config_obj *config_proto.Config,
+	key *api_proto.ApiKey, principal, action string) {
+	logging.GetLogger(config_obj, &logging.Audit).
+		WithFields(logrus.Fields{
+			"user":            principal,
+			"key_id":          key.Id,
+			"service_account": key.Principal,
+			"org_id":          key.OrgId,
+		}).Info(action)
+}
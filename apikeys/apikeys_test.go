@@ -0,0 +1,128 @@
+package apikeys_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"www.velocidex.com/golang/velociraptor/acls"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/apikeys"
+	"www.velocidex.com/golang/velociraptor/file_store/test_utils"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/utils"
+)
+
+type ApiKeysTestSuite struct {
+	test_utils.TestSuite
+}
+
+func (self *ApiKeysTestSuite) TestKeyLifecycle() {
+	created, err := apikeys.Create(self.ConfigObj, self.ConfigObj,
+		&api_proto.CreateApiKeyRequest{
+			Principal: "automation",
+			Roles:     []string{"reader"},
+		}, "admin")
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), "", created.Key.Hash)
+
+	key, err := apikeys.Verify(self.ConfigObj, created.Token)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), "automation", key.Principal)
+
+	// The service account has exactly the requested permissions.
+	ok, err := acls.CheckAccess(self.ConfigObj, "automation", acls.READ_RESULTS)
+	assert.NoError(self.T(), err)
+	assert.True(self.T(), ok)
+
+	ok, _ = acls.CheckAccess(self.ConfigObj, "automation", acls.COLLECT_CLIENT)
+	assert.False(self.T(), ok)
+
+	// A modified secret is rejected.
+	_, err = apikeys.Verify(self.ConfigObj, created.Token+"0")
+	assert.True(self.T(), errors.Is(err, apikeys.InvalidKeyError))
+
+	keys, err := apikeys.List(self.ConfigObj, "", "automation")
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), 1, len(keys.Items))
+
+	err = apikeys.Revoke(self.ConfigObj, "", created.Key.Id, "admin")
+	assert.NoError(self.T(), err)
+
+	_, err = apikeys.Verify(self.ConfigObj, created.Token)
+	assert.True(self.T(), errors.Is(err, apikeys.InvalidKeyError))
+}
+
+func (self *ApiKeysTestSuite) TestExpiry() {
+	clock := &utils.MockClock{MockNow: time.Unix(1600000000, 0)}
+	apikeys.Clock = clock
+	defer func() { apikeys.Clock = &utils.RealClock{} }()
+
+	created, err := apikeys.Create(self.ConfigObj, self.ConfigObj,
+		&api_proto.CreateApiKeyRequest{
+			Principal: "automation",
+			ExpiresIn: 3600,
+		}, "admin")
+	assert.NoError(self.T(), err)
+
+	_, err = apikeys.Verify(self.ConfigObj, created.Token)
+	assert.NoError(self.T(), err)
+
+	clock.MockNow = clock.MockNow.Add(2 * time.Hour)
+	_, err = apikeys.Verify(self.ConfigObj, created.Token)
+	assert.True(self.T(), errors.Is(err, apikeys.InvalidKeyError))
+}
+
+func (self *ApiKeysTestSuite) TestNoKeysForUsers() {
+	err := services.GetUserManager().SetUser(&api_proto.VelociraptorUser{
+		Name: "fred",
+	})
+	assert.NoError(self.T(), err)
+
+	_, err = apikeys.Create(self.ConfigObj, self.ConfigObj,
+		&api_proto.CreateApiKeyRequest{Principal: "fred"}, "admin")
+	assert.Error(self.T(), err)
+}
+
+func (self *ApiKeysTestSuite) TestReservedPrincipals() {
+	for _, principal := range []string{
+		self.ConfigObj.Client.PinnedServerName, self.ConfigObj.API.PinnedGwName} {
+		_, err := apikeys.Create(self.ConfigObj, self.ConfigObj,
+			&api_proto.CreateApiKeyRequest{Principal: principal}, "admin")
+		assert.Error(self.T(), err, principal)
+	}
+
+	// API client certificates only have an ACL policy.
+	err := acls.GrantRoles(self.ConfigObj, "api_client", []string{"reader"})
+	assert.NoError(self.T(), err)
+
+	_, err = apikeys.Create(self.ConfigObj, self.ConfigObj,
+		&api_proto.CreateApiKeyRequest{
+			Principal: "api_client",
+			Roles:     []string{"administrator"},
+		}, "admin")
+	assert.Error(self.T(), err)
+
+	// The certificate's policy is unchanged.
+	ok, _ := acls.CheckAccess(self.ConfigObj, "api_client", acls.SERVER_ADMIN)
+	assert.False(self.T(), ok)
+
+	// Existing keys are rejected if their principal becomes
+	// reserved.
+	created, err := apikeys.Create(self.ConfigObj, self.ConfigObj,
+		&api_proto.CreateApiKeyRequest{Principal: "automation"}, "admin")
+	assert.NoError(self.T(), err)
+
+	gw_name := self.ConfigObj.API.PinnedGwName
+	self.ConfigObj.API.PinnedGwName = "automation"
+	defer func() { self.ConfigObj.API.PinnedGwName = gw_name }()
+
+	_, err = apikeys.Verify(self.ConfigObj, created.Token)
+	assert.True(self.T(), errors.Is(err, apikeys.InvalidKeyError))
+}
+
+func TestApiKeys(t *testing.T) {
+	suite.Run(t, &ApiKeysTestSuite{})
+}
@@ -0,0 +1,414 @@
+// Two person integrity for dangerous collections.
+//
+// When enabled in the config, collections which use dangerous VQL
+// plugins (e.g. execve or rm) may only be launched with an approval
+// granted by a second user. The requester asks for an approval for a
+// specific client (or a hunt), set of artifacts and their parameters,
+// a different user grants it and the requester then launches the
+// collection with the approval id. Approvals are only valid for a
+// limited time after they are granted and may only be used once.
+//
+// Requesting, granting and using approvals is recorded in the audit
+// log.
+
+package approvals
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/datastore"
+	"www.velocidex.com/golang/velociraptor/file_store/api"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/paths"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/utils"
+)
+
+const (
+	STATE_PENDING  = "PENDING"
+	STATE_APPROVED = "APPROVED"
+	STATE_USED     = "USED"
+
+	DEFAULT_VALIDITY = 60 * 60
+)
+
+var (
+	// Callers can check for this with errors.Is()
+	ApprovalRequiredError = errors.New("Collection requires approval by a second user")
+
+	// Plugins are matched by their whole name so the remote execve
+	// plugins are listed separately.
+	default_dangerous_plugins = []string{
+		"execve", "ssh_execve", "winrm_execve",
+		"rm", "copy", "reg_rm_key", "reg_rm_value", "reg_set_value",
+	}
+
+	// Serialize updates to approvals so they can only be used once.
+	mu sync.Mutex
+
+	// Can be replaced in tests.
+	Clock utils.Clock = utils.RealClock{}
+)
+
+func isEnabled(config_obj *config_proto.Config) bool {
+	return config_obj.TwoPersonIntegrity != nil &&
+		config_obj.TwoPersonIntegrity.Enabled
+}
+
+func getApprovalPath(approval_id string) api.DSPathSpec {
+	return paths.APPROVALS_ROOT.AddUnsafeChild(approval_id)
+}
+
+func newApprovalId() string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return "A." + base32.HexEncoding.EncodeToString(buf)[:13]
+}
+
+// Find the dangerous plugins used by the artifacts or any of their
+// dependencies. Like the artifact dependency calculation this is a
+// simple search of the VQL text.
+func GetDangerousPlugins(
+	config_obj *config_proto.Config,
+	repository services.Repository,
+	artifacts []string) ([]string, error) {
+
+	dangerous := default_dangerous_plugins
+	if config_obj.TwoPersonIntegrity != nil &&
+		len(config_obj.TwoPersonIntegrity.DangerousPlugins) > 0 {
+		dangerous = config_obj.TwoPersonIntegrity.DangerousPlugins
+	}
+
+	quoted := []string{}
+	for _, plugin := range dangerous {
+		quoted = append(quoted, regexp.QuoteMeta(plugin))
+	}
+	plugin_regex, err := regexp.Compile(
+		`\b(` + strings.Join(quoted, "|") + `)\s*\(`)
+	if err != nil {
+		return nil, err
+	}
+
+	launcher, err := services.GetLauncher(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	dependencies, err := launcher.GetDependentArtifacts(
+		config_obj, repository, artifacts)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool)
+	for _, name := range dependencies {
+		artifact, pres := repository.Get(config_obj, name)
+		if !pres {
+			continue
+		}
+
+		queries := []string{artifact.Export, artifact.Precondition}
+		for _, source := range artifact.Sources {
+			queries = append(queries, source.Query, source.Precondition)
+		}
+
+		for _, query := range queries {
+			for _, hit := range plugin_regex.FindAllStringSubmatch(query, -1) {
+				found[hit[1]] = true
+			}
+		}
+	}
+
+	result := make([]string, 0, len(found))
+	for k := range found {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+
+	return result, nil
+}
+
+// A stable hash of the parameters the artifacts are collected
+// with. The order of the specs and of the parameters does not
+// matter. Specs for other artifacts are ignored.
+func HashSpecs(artifacts []string, specs []*flows_proto.ArtifactSpec) string {
+	params := make(map[string]string)
+	for _, spec := range specs {
+		if !utils.InString(artifacts, spec.Artifact) {
+			continue
+		}
+
+		// Later values override earlier ones.
+		for _, env := range spec.Parameters.GetEnv() {
+			params[spec.Artifact+"\x00"+env.Key] = env.Value
+		}
+	}
+
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, k := range keys {
+		fmt.Fprintf(hash, "%q=%q\n", k, params[k])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Request an approval to collect the artifacts on the client (or in a
+// hunt if there is no client id).
+func RequestApproval(
+	config_obj *config_proto.Config,
+	repository services.Repository,
+	request *api_proto.Approval, principal string) (*api_proto.Approval, error) {
+
+	if !isEnabled(config_obj) {
+		return nil, errors.New("Two person integrity is not enabled")
+	}
+
+	if len(request.Artifacts) == 0 {
+		return nil, errors.New("No artifacts specified")
+	}
+
+	plugins, err := GetDangerousPlugins(config_obj, repository, request.Artifacts)
+	if err != nil {
+		return nil, err
+	}
+
+	approval := &api_proto.Approval{
+		ApprovalId:  newApprovalId(),
+		Reason:      request.Reason,
+		Requester:   principal,
+		ClientId:    request.ClientId,
+		Artifacts:   request.Artifacts,
+		Specs:       request.Specs,
+		SpecsHash:   HashSpecs(request.Artifacts, request.Specs),
+		Plugins:     plugins,
+		RequestTime: uint64(Clock.Now().Unix()),
+		State:       STATE_PENDING,
+	}
+
+	err = setApproval(config_obj, approval)
+	if err != nil {
+		return nil, err
+	}
+
+	auditApproval(config_obj, approval, principal, "RequestApproval")
+	return approval, nil
+}
+
+// Grant a pending approval. The approver must be a different user.
+func GrantApproval(
+	config_obj *config_proto.Config,
+	approval_id string, principal string) (*api_proto.Approval, error) {
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	approval, err := GetApproval(config_obj, approval_id)
+	if err != nil {
+		return nil, err
+	}
+
+	if approval.State != STATE_PENDING {
+		return nil, fmt.Errorf("Approval %v is %v", approval_id, approval.State)
+	}
+
+	if approval.Requester == principal {
+		return nil, errors.New("Approvals must be granted by a different user")
+	}
+
+	validity := uint64(DEFAULT_VALIDITY)
+	if config_obj.TwoPersonIntegrity != nil &&
+		config_obj.TwoPersonIntegrity.ApprovalValiditySeconds > 0 {
+		validity = config_obj.TwoPersonIntegrity.ApprovalValiditySeconds
+	}
+
+	now := uint64(Clock.Now().Unix())
+	approval.Approver = principal
+	approval.ApproveTime = now
+	approval.Expires = now + validity
+	approval.State = STATE_APPROVED
+
+	err = setApproval(config_obj, approval)
+	if err != nil {
+		return nil, err
+	}
+
+	auditApproval(config_obj, approval, principal, "GrantApproval")
+	return approval, nil
+}
+
+// Check that a collection by the principal may proceed. If the
+// collection uses dangerous plugins the request must refer to a valid
+// approval which is then used up. Hunts have no client id.
+func CheckCollection(
+	config_obj *config_proto.Config,
+	repository services.Repository,
+	principal string, request *flows_proto.ArtifactCollectorArgs) error {
+	return checkCollection(config_obj, repository, principal, request, true)
+}
+
+// Like CheckCollection() but the approval is not used up (e.g. for a
+// dry run).
+func VerifyCollection(
+	config_obj *config_proto.Config,
+	repository services.Repository,
+	principal string, request *flows_proto.ArtifactCollectorArgs) error {
+	return checkCollection(config_obj, repository, principal, request, false)
+}
+
+func checkCollection(
+	config_obj *config_proto.Config,
+	repository services.Repository,
+	principal string, request *flows_proto.ArtifactCollectorArgs,
+	use bool) error {
+
+	if !isEnabled(config_obj) {
+		return nil
+	}
+
+	plugins, err := GetDangerousPlugins(config_obj, repository, request.Artifacts)
+	if err != nil {
+		return err
+	}
+
+	if len(plugins) == 0 {
+		return nil
+	}
+
+	if request.ApprovalId == "" {
+		return fmt.Errorf("%w: Artifacts use %v",
+			ApprovalRequiredError, strings.Join(plugins, ", "))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	approval, err := GetApproval(config_obj, request.ApprovalId)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ApprovalRequiredError, err)
+	}
+
+	switch {
+	case approval.State != STATE_APPROVED:
+		return fmt.Errorf("%w: Approval %v is %v", ApprovalRequiredError,
+			approval.ApprovalId, approval.State)
+
+	case approval.Requester != principal:
+		return fmt.Errorf("%w: Approval %v was requested by another user",
+			ApprovalRequiredError, approval.ApprovalId)
+
+	case approval.Expires < uint64(Clock.Now().Unix()):
+		return fmt.Errorf("%w: Approval %v has expired",
+			ApprovalRequiredError, approval.ApprovalId)
+
+	case approval.ClientId != request.ClientId:
+		return fmt.Errorf("%w: Approval %v is not for this client",
+			ApprovalRequiredError, approval.ApprovalId)
+	}
+
+	for _, artifact := range request.Artifacts {
+		if !utils.InString(approval.Artifacts, artifact) {
+			return fmt.Errorf("%w: Approval %v does not include %v",
+				ApprovalRequiredError, approval.ApprovalId, artifact)
+		}
+	}
+
+	if HashSpecs(approval.Artifacts, request.Specs) != approval.SpecsHash {
+		return fmt.Errorf("%w: Approval %v was granted for different parameters",
+			ApprovalRequiredError, approval.ApprovalId)
+	}
+
+	if !use {
+		return nil
+	}
+
+	approval.State = STATE_USED
+	err = setApproval(config_obj, approval)
+	if err != nil {
+		return err
+	}
+
+	auditApproval(config_obj, approval, principal, "UseApproval")
+	return nil
+}
+
+func GetApproval(config_obj *config_proto.Config,
+	approval_id string) (*api_proto.Approval, error) {
+	db, err := datastore.GetDB(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	approval := &api_proto.Approval{}
+	err = db.GetSubject(config_obj, getApprovalPath(approval_id), approval)
+	if err != nil || approval.ApprovalId == "" {
+		return nil, fmt.Errorf("Approval %v not found", approval_id)
+	}
+
+	return approval, nil
+}
+
+func GetApprovals(config_obj *config_proto.Config,
+	state string) (*api_proto.ApprovalList, error) {
+	db, err := datastore.GetDB(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &api_proto.ApprovalList{}
+	err = datastore.Walk(config_obj, db, paths.APPROVALS_ROOT,
+		datastore.WalkWithoutDirectories,
+		func(path api.DSPathSpec) error {
+			approval := &api_proto.Approval{}
+			err := db.GetSubject(config_obj, path, approval)
+			if err == nil && approval.ApprovalId != "" &&
+				(state == "" || approval.State == state) {
+				result.Items = append(result.Items, approval)
+			}
+			return nil
+		})
+
+	return result, err
+}
+
+func setApproval(config_obj *config_proto.Config,
+	approval *api_proto.Approval) error {
+	db, err := datastore.GetDB(config_obj)
+	if err != nil {
+		return err
+	}
+
+	return db.SetSubject(config_obj,
+		getApprovalPath(approval.ApprovalId), approval)
+}
+
+func auditApproval(config_obj *config_proto.Config,
+	approval *api_proto.Approval, principal, action string) {
+	logging.GetLogger(config_obj, &logging.Audit).
+		WithFields(logrus.Fields{
+			"user":        principal,
+			"approval_id": approval.ApprovalId,
+			"requester":   approval.Requester,
+			"client":      approval.ClientId,
+			"artifacts":   approval.Artifacts,
+			"plugins":     approval.Plugins,
+			"reason":      approval.Reason,
+		}).Info(action)
+}
@@ -0,0 +1,282 @@
+package approvals_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	actions_proto "www.velocidex.com/golang/velociraptor/actions/proto"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/approvals"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/file_store/test_utils"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/utils"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+)
+
+var definitions = []string{`
+name: Custom.Dangerous
+sources:
+- query: SELECT * FROM Artifact.Custom.Helper()
+`, `
+name: Custom.Helper
+sources:
+- query: SELECT * FROM execve(argv=["ls"])
+`, `
+name: Custom.Safe
+sources:
+- query: SELECT * FROM info()
+`, `
+name: Custom.SSH
+sources:
+- query: SELECT * FROM ssh_execve(hostname="host:22", argv=["ls"])
+`, `
+name: Custom.WinRM
+sources:
+- query: SELECT * FROM winrm_execve (endpoint="https://host:5986/wsman", argv=["dir"])
+`}
+
+type ApprovalsTestSuite struct {
+	test_utils.TestSuite
+	clock *utils.MockClock
+}
+
+func (self *ApprovalsTestSuite) SetupTest() {
+	self.ConfigObj = self.TestSuite.LoadConfig()
+	self.ConfigObj.TwoPersonIntegrity = &config_proto.TwoPersonIntegrityConfig{
+		Enabled:                 true,
+		ApprovalValiditySeconds: 600,
+	}
+	self.ConfigObj.Frontend.ServerServices.HuntDispatcher = true
+	self.LoadArtifacts(definitions)
+	self.TestSuite.SetupTest()
+
+	self.clock = &utils.MockClock{MockNow: time.Unix(1000000, 0)}
+	approvals.Clock = self.clock
+}
+
+func (self *ApprovalsTestSuite) TearDownTest() {
+	approvals.Clock = utils.RealClock{}
+	self.TestSuite.TearDownTest()
+}
+
+func (self *ApprovalsTestSuite) getRepository() services.Repository {
+	manager, err := services.GetRepositoryManager(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	repository, err := manager.GetGlobalRepository(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	return repository
+}
+
+func (self *ApprovalsTestSuite) TestDangerousPlugins() {
+	repository := self.getRepository()
+
+	// Plugins used by dependencies are found too.
+	plugins, err := approvals.GetDangerousPlugins(self.ConfigObj,
+		repository, []string{"Custom.Dangerous"})
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), []string{"execve"}, plugins)
+
+	// Remote execution plugins are dangerous too.
+	plugins, err = approvals.GetDangerousPlugins(self.ConfigObj,
+		repository, []string{"Custom.SSH"})
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), []string{"ssh_execve"}, plugins)
+
+	plugins, err = approvals.GetDangerousPlugins(self.ConfigObj,
+		repository, []string{"Custom.WinRM"})
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), []string{"winrm_execve"}, plugins)
+
+	// Safe collections do not need approval.
+	assert.NoError(self.T(), approvals.CheckCollection(self.ConfigObj,
+		repository, "alice", &flows_proto.ArtifactCollectorArgs{
+			ClientId:  "C.1234",
+			Artifacts: []string{"Custom.Safe"},
+		}))
+}
+
+func (self *ApprovalsTestSuite) TestApproval() {
+	repository := self.getRepository()
+	request := &flows_proto.ArtifactCollectorArgs{
+		ClientId:  "C.1234",
+		Artifacts: []string{"Custom.Dangerous"},
+	}
+
+	err := approvals.CheckCollection(self.ConfigObj, repository, "alice", request)
+	assert.True(self.T(), errors.Is(err, approvals.ApprovalRequiredError))
+
+	approval, err := approvals.RequestApproval(self.ConfigObj, repository,
+		&api_proto.Approval{
+			ClientId:  "C.1234",
+			Artifacts: []string{"Custom.Dangerous"},
+			Reason:    "Containment",
+		}, "alice")
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), approvals.STATE_PENDING, approval.State)
+	assert.Equal(self.T(), []string{"execve"}, approval.Plugins)
+
+	// Pending approvals can not be used yet.
+	request.ApprovalId = approval.ApprovalId
+	err = approvals.CheckCollection(self.ConfigObj, repository, "alice", request)
+	assert.True(self.T(), errors.Is(err, approvals.ApprovalRequiredError))
+
+	// The requester may not approve their own request.
+	_, err = approvals.GrantApproval(self.ConfigObj, approval.ApprovalId, "alice")
+	assert.Error(self.T(), err)
+
+	approval, err = approvals.GrantApproval(
+		self.ConfigObj, approval.ApprovalId, "bob")
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), approvals.STATE_APPROVED, approval.State)
+
+	// Only the requester may use the approval and only on the client.
+	err = approvals.CheckCollection(self.ConfigObj, repository, "bob", request)
+	assert.True(self.T(), errors.Is(err, approvals.ApprovalRequiredError))
+
+	err = approvals.CheckCollection(self.ConfigObj, repository, "alice",
+		&flows_proto.ArtifactCollectorArgs{
+			ClientId:   "C.5678",
+			Artifacts:  []string{"Custom.Dangerous"},
+			ApprovalId: approval.ApprovalId,
+		})
+	assert.True(self.T(), errors.Is(err, approvals.ApprovalRequiredError))
+
+	// Approvals may only be used once.
+	assert.NoError(self.T(), approvals.CheckCollection(
+		self.ConfigObj, repository, "alice", request))
+
+	err = approvals.CheckCollection(self.ConfigObj, repository, "alice", request)
+	assert.True(self.T(), errors.Is(err, approvals.ApprovalRequiredError))
+
+	used, err := approvals.GetApprovals(self.ConfigObj, approvals.STATE_USED)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), 1, len(used.Items))
+	assert.Equal(self.T(), "bob", used.Items[0].Approver)
+}
+
+func (self *ApprovalsTestSuite) TestExpiry() {
+	repository := self.getRepository()
+
+	approval, err := approvals.RequestApproval(self.ConfigObj, repository,
+		&api_proto.Approval{
+			Artifacts: []string{"Custom.Dangerous"},
+		}, "alice")
+	assert.NoError(self.T(), err)
+
+	_, err = approvals.GrantApproval(self.ConfigObj, approval.ApprovalId, "bob")
+	assert.NoError(self.T(), err)
+
+	// Approvals are only valid for a limited time.
+	self.clock.Sleep(601 * time.Second)
+
+	err = approvals.CheckCollection(self.ConfigObj, repository, "alice",
+		&flows_proto.ArtifactCollectorArgs{
+			Artifacts:  []string{"Custom.Dangerous"},
+			ApprovalId: approval.ApprovalId,
+		})
+	assert.True(self.T(), errors.Is(err, approvals.ApprovalRequiredError))
+}
+
+func (self *ApprovalsTestSuite) TestParameters() {
+	repository := self.getRepository()
+	specs := func(value string) []*flows_proto.ArtifactSpec {
+		return []*flows_proto.ArtifactSpec{{
+			Artifact: "Custom.Dangerous",
+			Parameters: &flows_proto.ArtifactParameters{
+				Env: []*actions_proto.VQLEnv{{Key: "Command", Value: value}},
+			},
+		}}
+	}
+
+	approval, err := approvals.RequestApproval(self.ConfigObj, repository,
+		&api_proto.Approval{
+			ClientId:  "C.1234",
+			Artifacts: []string{"Custom.Dangerous"},
+			Specs:     specs("ls"),
+		}, "alice")
+	assert.NoError(self.T(), err)
+
+	_, err = approvals.GrantApproval(self.ConfigObj, approval.ApprovalId, "bob")
+	assert.NoError(self.T(), err)
+
+	// The approval does not cover other parameters.
+	request := &flows_proto.ArtifactCollectorArgs{
+		ClientId:   "C.1234",
+		Artifacts:  []string{"Custom.Dangerous"},
+		Specs:      specs("rm -rf /"),
+		ApprovalId: approval.ApprovalId,
+	}
+	err = approvals.CheckCollection(self.ConfigObj, repository, "alice", request)
+	assert.True(self.T(), errors.Is(err, approvals.ApprovalRequiredError))
+
+	request.Specs = nil
+	err = approvals.CheckCollection(self.ConfigObj, repository, "alice", request)
+	assert.True(self.T(), errors.Is(err, approvals.ApprovalRequiredError))
+
+	request.Specs = specs("ls")
+	assert.NoError(self.T(), approvals.CheckCollection(
+		self.ConfigObj, repository, "alice", request))
+}
+
+func (self *ApprovalsTestSuite) TestHunt() {
+	repository := self.getRepository()
+
+	hunt_dispatcher, err := services.GetHuntDispatcher(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	create_hunt := func(approval_id string, expires uint64) error {
+		_, err := hunt_dispatcher.CreateHunt(self.Ctx, self.ConfigObj,
+			vql_subsystem.NullACLManager{}, &api_proto.Hunt{
+				Creator: "alice",
+				Expires: expires,
+				StartRequest: &flows_proto.ArtifactCollectorArgs{
+					Artifacts:  []string{"Custom.Dangerous"},
+					ApprovalId: approval_id,
+				},
+			})
+		return err
+	}
+
+	err = create_hunt("", 0)
+	assert.True(self.T(), errors.Is(err, approvals.ApprovalRequiredError))
+
+	// Approvals for hunts are not tied to a client.
+	approval, err := approvals.RequestApproval(self.ConfigObj, repository,
+		&api_proto.Approval{
+			Artifacts: []string{"Custom.Dangerous"},
+		}, "alice")
+	assert.NoError(self.T(), err)
+
+	_, err = approvals.GrantApproval(self.ConfigObj, approval.ApprovalId, "bob")
+	assert.NoError(self.T(), err)
+
+	// The approval is not used up when the hunt can not be
+	// scheduled.
+	err = create_hunt(approval.ApprovalId, 1)
+	assert.Error(self.T(), err)
+
+	approval, err = approvals.GetApproval(self.ConfigObj, approval.ApprovalId)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), approvals.STATE_APPROVED, approval.State)
+
+	assert.NoError(self.T(), create_hunt(approval.ApprovalId, 0))
+
+	approval, err = approvals.GetApproval(self.ConfigObj, approval.ApprovalId)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), approvals.STATE_USED, approval.State)
+
+	// Approvals may only be used by one hunt.
+	err = create_hunt(approval.ApprovalId, 0)
+	assert.True(self.T(), errors.Is(err, approvals.ApprovalRequiredError))
+}
+
+func TestApprovals(t *testing.T) {
+	suite.Run(t, &ApprovalsTestSuite{})
+}
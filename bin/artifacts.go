@@ -20,7 +20,9 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
+	"os"
 	"regexp"
 	"strings"
 	"sync"
@@ -28,6 +30,7 @@ import (
 
 	"github.com/Velocidex/ordereddict"
 	"github.com/Velocidex/yaml/v2"
+	"github.com/mattn/go-isatty"
 	"www.velocidex.com/golang/velociraptor/config"
 	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
 	"www.velocidex.com/golang/velociraptor/executor"
@@ -111,6 +114,14 @@ var (
 
 	artifact_command_collect_hardmemory = artifact_command_collect.Flag(
 		"hard_memory_limit", "If we reach this memory limit in bytes we exit.").Uint64()
+
+	artifact_command_collect_profile = artifact_command_collect.Flag(
+		"profile", "The collection profile to run (e.g. quick, standard, deep). "+
+			"Only used by offline collectors built with profiles.").String()
+
+	artifact_command_collect_tui = artifact_command_collect.Flag(
+		"tui", "Show an interactive progress display instead of logs "+
+			"(when running in a terminal).").Bool()
 )
 
 func listArtifactsHint() []string {
@@ -165,6 +176,14 @@ func doArtifactCollect() error {
 		return err
 	}
 
+	// The progress display needs the console to itself so logs
+	// only go to the log file.
+	var progress *ProgressDisplay
+	if *artifact_command_collect_tui && isatty.IsTerminal(os.Stdout.Fd()) {
+		logging.DisableConsole = true
+		progress = NewProgressDisplay()
+	}
+
 	config_obj, err := makeDefaultConfigLoader().
 		WithNullLoader().LoadAndValidate()
 	if err != nil {
@@ -217,6 +236,12 @@ func doArtifactCollect() error {
 			}
 		}
 
+		// The profile is an ordinary artifact parameter which the
+		// offline collector uses to select its artifacts and limits.
+		if *artifact_command_collect_profile != "" {
+			collect_args.Set("Profile", *artifact_command_collect_profile)
+		}
+
 		spec.Set(name, collect_args)
 	}
 
@@ -226,6 +251,10 @@ func doArtifactCollect() error {
 	}
 
 	logger := log.New(&LogWriter{config_obj}, "", 0)
+	if progress != nil {
+		logger = log.New(io.MultiWriter(
+			&LogWriter{config_obj}, progress), "", 0)
+	}
 
 	scope := manager.BuildScope(services.ScopeBuilder{
 		Config:     config_obj,
@@ -292,6 +321,10 @@ func doArtifactCollect() error {
                         timeout=Timeout, progress_timeout=ProgressTimeout,
                         cpu_limit=CpuLimit,
                         password=Password, args=Args, format=Format)`
+	if progress != nil {
+		return progress.Run(ctx, query, scope)
+	}
+
 	return eval_local_query(
 		ctx, config_obj,
 		*artifact_command_collect_format, query, scope)
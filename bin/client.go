@@ -114,6 +114,9 @@ func runClientOnce(
 
 	executor.SetTempfile(config_obj)
 
+	// Apply any config override pushed by the server.
+	server_urls := executor.ApplyStoredConfigOverride(config_obj)
+
 	writeback, err := config.GetWriteback(config_obj.Client)
 	if err != nil {
 		return err
@@ -160,7 +163,7 @@ func runClientOnce(
 		config_obj,
 		manager,
 		exe,
-		config_obj.Client.ServerUrls,
+		server_urls,
 		func() { on_error(ctx, config_obj) },
 		utils.RealClock{},
 	)
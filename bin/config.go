@@ -31,12 +31,14 @@ import (
 	"github.com/Velocidex/survey"
 	"github.com/Velocidex/yaml/v2"
 	errors "github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
 	kingpin "gopkg.in/alecthomas/kingpin.v2"
 	"www.velocidex.com/golang/velociraptor/acls"
 	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
 	"www.velocidex.com/golang/velociraptor/config"
 	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
 	"www.velocidex.com/golang/velociraptor/crypto"
+	crypto_utils "www.velocidex.com/golang/velociraptor/crypto/utils"
 	"www.velocidex.com/golang/velociraptor/json"
 	"www.velocidex.com/golang/velociraptor/logging"
 	"www.velocidex.com/golang/velociraptor/services"
@@ -346,6 +348,20 @@ func getClientConfig(config_obj *config_proto.Config) *config_proto.Config {
 		Client:  config_obj.Client,
 	}
 
+	// Sign the client settings so clients can detect tampering with
+	// their config.
+	if config_obj.Client != nil &&
+		config_obj.CA != nil && config_obj.CA.PrivateKey != "" {
+		client_config.Client = proto.Clone(
+			config_obj.Client).(*config_proto.ClientConfig)
+
+		err := crypto_utils.SignClientConfig(config_obj.CA, client_config.Client)
+		if err != nil {
+			logger := logging.GetLogger(config_obj, &logging.ToolComponent)
+			logger.Error("Unable to sign client config: %v", err)
+		}
+	}
+
 	return client_config
 }
 
@@ -112,7 +112,7 @@ func doGrant() error {
 	if *grant_command_roles != "" {
 		for _, role := range strings.Split(*grant_command_roles, ",") {
 			if !utils.InString(new_policy.Roles, role) {
-				if !acls.ValidateRole(role) {
+				if !acls.IsValidRole(org_config_obj, role) {
 					if err != nil {
 						return fmt.Errorf("Invalid role %v", role)
 					}
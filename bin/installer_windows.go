@@ -512,6 +512,9 @@ func runOnce(ctx context.Context,
 		return
 	}
 
+	// Apply any config override pushed by the server.
+	server_urls := executor.ApplyStoredConfigOverride(config_obj)
+
 	writeback, err := config.GetWriteback(config_obj.Client)
 	if err != nil {
 		return
@@ -539,7 +542,7 @@ func runOnce(ctx context.Context,
 		config_obj,
 		manager,
 		exe,
-		config_obj.Client.ServerUrls,
+		server_urls,
 		func() { on_error(ctx, config_obj) },
 		utils.RealClock{},
 	)
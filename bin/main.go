@@ -23,6 +23,7 @@ import (
 	"os"
 	"runtime/pprof"
 	"runtime/trace"
+	"strings"
 
 	"github.com/Velocidex/survey"
 	errors "github.com/pkg/errors"
@@ -114,6 +115,16 @@ func maybe_unlock_api_config(config_obj *config_proto.Config) error {
 	return nil
 }
 
+// The autoexec args come first so flags given on the command line
+// are applied to the autoexec command.
+func getAutoexecArgs(argv, args []string) []string {
+	result := []string{}
+	for _, arg := range argv {
+		result = append(result, os.ExpandEnv(arg))
+	}
+	return append(result, args...)
+}
+
 var (
 	APIConfigLoader *config.Loader
 	default_config  *config_proto.Config
@@ -125,14 +136,13 @@ func main() {
 	args := os.Args[1:]
 
 	// If no args are given check if there is an embedded config
-	// with autoexec.
-	if len(args) == 0 {
+	// with autoexec. If only flags are given (e.g. --profile for an
+	// offline collector) they are added to the autoexec args.
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
 		config_obj, err := new(config.Loader).WithVerbose(*verbose_flag).
 			WithEmbedded().LoadAndValidate()
 		if err == nil && config_obj.Autoexec != nil && config_obj.Autoexec.Argv != nil {
-			for _, arg := range config_obj.Autoexec.Argv {
-				args = append(args, os.ExpandEnv(arg))
-			}
+			args = getAutoexecArgs(config_obj.Autoexec.Argv, args)
 			logging.Prelog("Autoexec with parameters: %v", args)
 		}
 	}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoexecArgs(t *testing.T) {
+	argv := []string{"artifacts", "collect", "Collector",
+		"--logfile", "Collector.log"}
+
+	// Without args we just run the autoexec command.
+	assert.Equal(t, argv, getAutoexecArgs(argv, nil))
+
+	// Flags on the command line are added to the autoexec command.
+	args := getAutoexecArgs(argv, []string{"--profile", "quick"})
+	assert.Equal(t, []string{"artifacts", "collect", "Collector",
+		"--logfile", "Collector.log", "--profile", "quick"}, args)
+
+	command, err := app.Parse(args)
+	assert.NoError(t, err)
+	assert.Equal(t, artifact_command_collect.FullCommand(), command)
+	assert.Equal(t, "quick", *artifact_command_collect_profile)
+}
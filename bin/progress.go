@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/olekukonko/tablewriter"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/vql/tools"
+	"www.velocidex.com/golang/vfilter"
+)
+
+const (
+	// Keep lines short so they do not wrap and break redrawing.
+	progress_line_width = 78
+
+	// Show at most this many artifacts at once.
+	progress_max_artifacts = 15
+
+	progress_refresh = 500 * time.Millisecond
+)
+
+// An interactive progress display for collections. It takes over the
+// console so log messages are only written to the log file, while
+// errors are shown against the artifact which produced them.
+type ProgressDisplay struct {
+	mu  sync.Mutex
+	out io.Writer
+
+	// Number of lines drawn last time so they can be redrawn.
+	lines int
+
+	last_message string
+}
+
+func NewProgressDisplay() *ProgressDisplay {
+	enableTerminal()
+	return &ProgressDisplay{out: os.Stdout}
+}
+
+// Receives the query log so errors can be attributed to artifacts.
+func (self *ProgressDisplay) Write(b []byte) (int, error) {
+	level, msg := logging.SplitIntoLevelAndLog(b)
+	msg = strings.TrimSpace(msg)
+
+	if level == logging.ERROR ||
+		strings.Contains(strings.ToLower(msg), "error") {
+		tools.CollectorProgress.AddError(msg)
+	}
+
+	self.mu.Lock()
+	self.last_message = msg
+	self.mu.Unlock()
+
+	return len(b), nil
+}
+
+// Run the query while redrawing the progress, then print a summary.
+func (self *ProgressDisplay) Run(
+	ctx context.Context, query string, scope vfilter.Scope) error {
+	vqls, err := vfilter.MultiParse(query)
+	if err != nil {
+		return fmt.Errorf("Unable to parse VQL Query: %w", err)
+	}
+
+	sub_ctx, cancel := InstallSignalHandler(ctx, scope)
+	defer cancel()
+
+	done := make(chan bool)
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for {
+			self.draw(tools.CollectorProgress.Snapshot())
+
+			select {
+			case <-done:
+				return
+			case <-time.After(progress_refresh):
+			}
+		}
+	}()
+
+	// The results are summarized below instead.
+	for _, vql := range vqls {
+		for range vql.Eval(sub_ctx, scope) {
+		}
+	}
+
+	close(done)
+	wg.Wait()
+
+	snapshot := tools.CollectorProgress.Snapshot()
+	self.draw(snapshot)
+	self.summary(snapshot)
+
+	return nil
+}
+
+func (self *ProgressDisplay) draw(snapshot *tools.ProgressSnapshot) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	lines := []string{fmt.Sprintf(
+		"Collected %v/%v artifacts, %v, elapsed %v%v",
+		snapshot.Completed, len(snapshot.Artifacts),
+		humanize.Bytes(uint64(snapshot.Bytes)),
+		snapshot.Elapsed.Round(time.Second), formatETA(snapshot))}
+
+	now := time.Now()
+	shown := 0
+	pending := 0
+	for _, artifact := range snapshot.Artifacts {
+		if shown >= progress_max_artifacts {
+			pending++
+			continue
+		}
+		shown++
+
+		line := fmt.Sprintf("  %-8s %-40s", artifact.State, artifact.Name)
+		if artifact.State != tools.PROGRESS_PENDING {
+			line += fmt.Sprintf(" %6d rows %8s %6v", artifact.Rows,
+				humanize.Bytes(uint64(artifact.Bytes)),
+				artifact.Duration(now).Round(time.Second))
+		}
+		if len(artifact.Errors) > 0 {
+			line += fmt.Sprintf(" %d errors", len(artifact.Errors))
+		}
+		lines = append(lines, line)
+	}
+
+	if pending > 0 {
+		lines = append(lines, fmt.Sprintf("  ... and %d more", pending))
+	}
+	if self.last_message != "" {
+		lines = append(lines, "> "+self.last_message)
+	}
+
+	// Move back to the start of the last frame and redraw it.
+	if self.lines > 0 {
+		fmt.Fprintf(self.out, "\033[%dA", self.lines)
+	}
+	for _, line := range lines {
+		fmt.Fprintf(self.out, "\033[2K%s\n", truncateLine(line))
+	}
+	fmt.Fprint(self.out, "\033[J")
+	self.lines = len(lines)
+}
+
+func (self *ProgressDisplay) summary(snapshot *tools.ProgressSnapshot) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	fmt.Fprintln(self.out)
+	now := time.Now()
+	table := tablewriter.NewWriter(self.out)
+	table.SetHeader([]string{"Artifact", "Status", "Rows", "Size",
+		"Duration", "Errors"})
+	for _, artifact := range snapshot.Artifacts {
+		table.Append([]string{
+			artifact.Name, artifact.State,
+			fmt.Sprintf("%d", artifact.Rows),
+			humanize.Bytes(uint64(artifact.Bytes)),
+			artifact.Duration(now).Round(time.Second).String(),
+			fmt.Sprintf("%d", len(artifact.Errors)),
+		})
+	}
+	table.Render()
+
+	for _, artifact := range snapshot.Artifacts {
+		for _, message := range artifact.Errors {
+			fmt.Fprintf(self.out, "%v: %v\n", artifact.Name, message)
+		}
+	}
+
+	if snapshot.Output != "" {
+		fmt.Fprintf(self.out, "\nCollection written to %v (%v) in %v\n",
+			snapshot.Output, humanize.Bytes(uint64(snapshot.Bytes)),
+			snapshot.Elapsed.Round(time.Second))
+	}
+}
+
+func formatETA(snapshot *tools.ProgressSnapshot) string {
+	if snapshot.ETA == 0 {
+		return ""
+	}
+	return fmt.Sprintf(", ETA %v", snapshot.ETA.Round(time.Second))
+}
+
+func truncateLine(line string) string {
+	runes := []rune(line)
+	if len(runes) > progress_line_width {
+		return string(runes[:progress_line_width-3]) + "..."
+	}
+	return line
+}
@@ -0,0 +1,14 @@
+// +build windows
+
+package main
+
+import (
+	"syscall"
+
+	"www.velocidex.com/golang/velociraptor/logging"
+)
+
+// The console needs to be told to interpret escape codes.
+func enableTerminal() {
+	_ = logging.EnableVirtualTerminalProcessing(syscall.Stdout, true)
+}
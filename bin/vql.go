@@ -228,6 +228,7 @@ func doVQLExport() error {
 			// Override the args
 			new_item.Args = nil
 		}
+		new_item.Version = int64(item.Version)
 
 		arg_desc, pres := type_map.Get(scope, item.ArgType)
 		if pres {
@@ -273,6 +274,7 @@ func doVQLExport() error {
 			// Override the args
 			new_item.Args = nil
 		}
+		new_item.Version = int64(item.Version)
 
 		arg_desc, pres := type_map.Get(scope, item.ArgType)
 		if pres {
@@ -30,6 +30,7 @@ const (
 	FLOW_PREFIX             = "F."
 	FOREMAN_WELL_KNOWN_FLOW = "E.Foreman"
 	HUNT_PREFIX             = "H."
+	JOB_PREFIX              = "J."
 	ORG_PREFIX              = "O"
 
 	// Well known flows - Request ID:
@@ -80,6 +81,18 @@ const (
 	USN_FREQUENCY       = "USN_FREQUENCY"
 	ZIP_FILE_CACHE_SIZE = "ZIP_FILE_CACHE_SIZE"
 
+	// Credentials for the cloud storage accessors. These should be
+	// set to a dict of the accessor's options.
+	S3_CREDENTIALS    = "S3_CREDENTIALS"
+	GCS_CREDENTIALS   = "GCS_CREDENTIALS"
+	AZURE_CREDENTIALS = "AZURE_CREDENTIALS"
+
+	// Connection details for the ssh accessor and ssh_execve().
+	SSH_CONFIG = "SSH_CONFIG"
+
+	// Connection details for the winrm accessor and winrm_execve().
+	WINRM_CONFIG = "WINRM_CONFIG"
+
 	// Certain VQL errors represent a failure in artifact
 	// collection. We use this RegExp to determine if log messages
 	// represent failure.
@@ -59,7 +59,7 @@ func NewClientCryptoManager(config_obj *config_proto.Config, client_private_key_
 	}
 
 	logger := logging.GetLogger(config_obj, &logging.ClientComponent)
-	client_id := crypto_utils.ClientIDFromPublicKey(&private_key.PublicKey)
+	client_id := crypto_utils.GetClientId(config_obj, &private_key.PublicKey)
 	logger.Info("Starting Crypto for client %v", client_id)
 
 	roots := x509.NewCertPool()
@@ -74,13 +74,14 @@ func NewClientCryptoManager(config_obj *config_proto.Config, client_private_key_
 	}
 
 	return &ClientCryptoManager{CryptoManager{
-		config:      config_obj,
-		ClientId:    client_id,
-		private_key: private_key,
-		source:      client_id,
-		Resolver:    NewInMemoryPublicKeyResolver(),
-		cipher_lru:  NewCipherLRU(lru_size),
-		caPool:      roots,
-		logger:      logger,
+		config:       config_obj,
+		ClientId:     client_id,
+		private_key:  private_key,
+		source:       client_id,
+		Resolver:     NewInMemoryPublicKeyResolver(),
+		cipher_lru:   NewCipherLRU(lru_size),
+		replay_cache: NewReplayCache(config_obj),
+		caPool:       roots,
+		logger:       logger,
 	}}, nil
 }
@@ -41,20 +41,40 @@ type CryptoManager struct {
 	// and therefore the same RSA keys.
 	cipher_lru *CipherLRU
 
+	// Rejects messages which were already received.
+	replay_cache *ReplayCache
+
 	caPool *x509.CertPool
 
 	logger *logging.LogContext
+
+	// Advertised to the remote end with each message.
+	compression_policy *crypto_proto.CompressionPolicy
+}
+
+// The server uses this to tell clients how they should compress the
+// data they send.
+func (self *CryptoManager) SetCompressionPolicy(
+	policy *crypto_proto.CompressionPolicy) {
+	self.compression_policy = policy
 }
 
 // Clear all internal caches.
 func (self *CryptoManager) Clear() {
 	self.cipher_lru.Clear()
+	self.replay_cache.Clear()
 	self.Resolver.Clear()
 }
 
+// Remove the cached ciphers for a peer. This is needed when the
+// peer's key changes.
+func (self *CryptoManager) DeleteCipher(name string) {
+	self.cipher_lru.Delete(name)
+}
+
 func (self *CryptoManager) GetCSR() ([]byte, error) {
 	subj := pkix.Name{
-		CommonName: crypto_utils.ClientIDFromPublicKey(&self.private_key.PublicKey),
+		CommonName: self.source,
 	}
 
 	template := x509.CertificateRequest{
@@ -81,12 +101,13 @@ func NewCryptoManager(config_obj *config_proto.Config,
 	}
 
 	return &CryptoManager{
-		config:      config_obj,
-		private_key: private_key,
-		source:      source,
-		Resolver:    public_key_resolver,
-		cipher_lru:  NewCipherLRU(config_obj.Frontend.Resources.ExpectedClients),
-		logger:      logging.GetLogger(config_obj, &logging.ClientComponent),
+		config:       config_obj,
+		private_key:  private_key,
+		source:       source,
+		Resolver:     public_key_resolver,
+		cipher_lru:   NewCipherLRU(config_obj.Frontend.Resources.ExpectedClients),
+		replay_cache: NewReplayCache(config_obj),
+		logger:       logging.GetLogger(config_obj, &logging.ClientComponent),
 	}, nil
 }
 
@@ -227,12 +248,17 @@ func (self *CryptoManager) Decrypt(cipher_text []byte) (*vcrypto.MessageInfo, er
 			return nil, errors.New("HMAC did not verify")
 		}
 
-		msg_info, _, err := self.extractMessageInfo(
+		msg_info, packed_message_list, _, err := self.extractMessageInfo(
 			cipher.cipher_properties, communications)
 		if err != nil {
 			return nil, err
 		}
 
+		err = self.checkReplay(msg_info, packed_message_list, communications)
+		if err != nil {
+			return nil, err
+		}
+
 		// Cipher was cached so we trust it
 		msg_info.Authenticated = true
 
@@ -279,7 +305,7 @@ func (self *CryptoManager) Decrypt(cipher_text []byte) (*vcrypto.MessageInfo, er
 		return nil, errors.WithStack(err)
 	}
 
-	msg_info, org_config_obj, err := self.extractMessageInfo(
+	msg_info, packed_message_list, org_config_obj, err := self.extractMessageInfo(
 		cipher_properties, communications)
 	if err != nil {
 		return nil, err
@@ -292,6 +318,15 @@ func (self *CryptoManager) Decrypt(cipher_text []byte) (*vcrypto.MessageInfo, er
 	msg_info.Authenticated, err = self.getAuthState(
 		org_config_obj, cipher_metadata, serialized_cipher, cipher_properties)
 
+	// Only authenticated messages are tracked so others can not
+	// interfere with the sender's replay state.
+	if err == nil && msg_info.Authenticated {
+		err := self.checkReplay(msg_info, packed_message_list, communications)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// If we could verify the authentication state and it
 	// was authenticated, we are now allowed to cache the
 	// cipher in the input cache. The next packet from
@@ -309,12 +344,34 @@ func (self *CryptoManager) Decrypt(cipher_text []byte) (*vcrypto.MessageInfo, er
 	return msg_info, nil
 }
 
+// Reject messages we already received from the sender. Older
+// senders do not send a message nonce but the packet IV is also
+// random for each packet and covered by the HMAC.
+func (self *CryptoManager) checkReplay(
+	msg_info *vcrypto.MessageInfo,
+	packed_message_list *crypto_proto.PackedMessageList,
+	communications *crypto_proto.ClientCommunication) error {
+	nonce := packed_message_list.MessageNonce
+	if len(nonce) == 0 {
+		nonce = communications.PacketIv
+	}
+
+	err := self.replay_cache.Check(
+		msg_info.Source, nonce, packed_message_list.Timestamp)
+	if err != nil {
+		self.logger.Info("Rejecting replayed message from %v", msg_info.Source)
+		return err
+	}
+	return nil
+}
+
 // Decrypt the message from the communications using the cipher
 // properties.
 func (self *CryptoManager) extractMessageInfo(
 	cipher_properties *crypto_proto.CipherProperties,
 	communications *crypto_proto.ClientCommunication) (
-	*vcrypto.MessageInfo, *config_proto.Config, error) {
+	*vcrypto.MessageInfo, *crypto_proto.PackedMessageList,
+	*config_proto.Config, error) {
 
 	// Decrypt the cipher metadata.
 	plain, err := decryptSymmetric(
@@ -322,31 +379,31 @@ func (self *CryptoManager) extractMessageInfo(
 		communications.Encrypted,
 		communications.PacketIv)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	// Unpack the message list.
 	packed_message_list := &crypto_proto.PackedMessageList{}
 	err = proto.Unmarshal(plain, packed_message_list)
 	if err != nil {
-		return nil, nil, errors.WithStack(err)
+		return nil, nil, nil, errors.WithStack(err)
 	}
 
 	// Get the org id from the nonce
 	org_manager, err := services.GetOrgManager()
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	org_id, err := org_manager.OrgIdByNonce(packed_message_list.Nonce)
 	if err != nil {
-		return nil, nil, errors.New(
+		return nil, nil, nil, errors.New(
 			"Client Nonce is not valid - rejecting message.")
 	}
 
 	org_config_obj, err := org_manager.GetOrgConfig(org_id)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	return &vcrypto.MessageInfo{
@@ -354,9 +411,11 @@ func (self *CryptoManager) extractMessageInfo(
 		RawCompressed: packed_message_list.MessageList,
 		Source: utils.ClientIdFromSourceAndOrg(
 			packed_message_list.Source, org_id),
-		Compression: packed_message_list.Compression,
-		OrgId:       org_id,
-	}, org_config_obj, nil
+		Compression:        packed_message_list.Compression,
+		MessageCompression: packed_message_list.MessageCompression,
+		CompressionPolicy:  packed_message_list.CompressionPolicy,
+		OrgId:              org_id,
+	}, packed_message_list, org_config_obj, nil
 }
 
 // Serialize, compress and encrypt a single message list proto. NOTE:
@@ -396,6 +455,34 @@ func (self *CryptoManager) Encrypt(
 	compression crypto_proto.PackedMessageList_CompressionType,
 	destination string) (
 	[]byte, error) {
+	return self.encrypt(&crypto_proto.PackedMessageList{
+		Compression: compression,
+		MessageList: compressed_message_lists,
+	}, destination)
+}
+
+// Encrypt message lists which are not all compressed the same
+// way. Only servers which advertised a compression policy understand
+// this.
+func (self *CryptoManager) EncryptMessageLists(
+	message_lists [][]byte,
+	compressions []crypto_proto.PackedMessageList_CompressionType,
+	destination string) (
+	[]byte, error) {
+	if len(message_lists) != len(compressions) {
+		return nil, errors.New("EncryptMessageLists: Compression must be specified for each message list")
+	}
+
+	return self.encrypt(&crypto_proto.PackedMessageList{
+		MessageCompression: compressions,
+		MessageList:        message_lists,
+	}, destination)
+}
+
+func (self *CryptoManager) encrypt(
+	packed_message_list *crypto_proto.PackedMessageList,
+	destination string) (
+	[]byte, error) {
 
 	// The cipher is kept the same for all future communications
 	// to enable the remote end to cache it - thereby saving RSA
@@ -430,13 +517,15 @@ func (self *CryptoManager) Encrypt(
 		output_cipher = cipher
 	}
 
-	packed_message_list := &crypto_proto.PackedMessageList{
-		// We always compress the data.
-		Compression: compression,
-		MessageList: compressed_message_lists,
-		Source:      self.source,
-		Nonce:       self.config.Client.Nonce,
-		Timestamp:   uint64(time.Now().UnixNano() / 1000),
+	packed_message_list.Source = self.source
+	packed_message_list.Nonce = self.config.Client.Nonce
+	packed_message_list.Timestamp = uint64(time.Now().UnixNano() / 1000)
+	packed_message_list.MessageNonce = make([]byte, 16)
+	packed_message_list.CompressionPolicy = self.compression_policy
+
+	_, err := rand.Read(packed_message_list.MessageNonce)
+	if err != nil {
+		return nil, errors.WithStack(err)
 	}
 
 	serialized_packed_message_list, err := proto.Marshal(packed_message_list)
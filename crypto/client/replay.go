@@ -0,0 +1,148 @@
+package client
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/utils"
+)
+
+const (
+	// Seconds
+	DEFAULT_REPLAY_WINDOW = 600
+
+	// Forget senders we have not heard from in this long.
+	replay_source_expiry = 24 * time.Hour
+)
+
+var (
+	ReplayError = errors.New("Replayed message rejected")
+
+	metricReplayRejected = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "comms_replay_rejected",
+			Help: "Number of messages rejected as replays.",
+		},
+		[]string{"reason"},
+	)
+)
+
+type replayState struct {
+	// The newest sender timestamp seen (in microseconds).
+	latest    uint64
+	last_seen time.Time
+
+	// Nonces seen within the window and their timestamps.
+	nonces map[string]uint64
+}
+
+// Tracks the nonces recently seen from each sender. Timestamps are
+// only compared to earlier timestamps from the same sender so clock
+// skew between the sender and receiver does not matter.
+type ReplayCache struct {
+	mu sync.Mutex
+
+	// Microseconds, 0 means disabled.
+	window uint64
+
+	by_source   map[string]*replayState
+	last_expiry time.Time
+	clock       utils.Clock
+}
+
+func NewReplayCache(config_obj *config_proto.Config) *ReplayCache {
+	window := config_obj.GetFrontend().GetReplayWindow()
+	switch {
+	case window < 0:
+		window = 0
+	case window == 0:
+		window = DEFAULT_REPLAY_WINDOW
+	}
+
+	return &ReplayCache{
+		window:    uint64(window) * 1000000,
+		by_source: make(map[string]*replayState),
+		clock:     utils.RealClock{},
+	}
+}
+
+// Check that the message was not seen before and remember it.
+// Messages without a nonce or timestamp can not be checked.
+func (self *ReplayCache) Check(
+	source string, nonce []byte, timestamp uint64) error {
+	if self.window == 0 || len(nonce) == 0 || timestamp == 0 {
+		return nil
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	now := self.clock.Now()
+	self.expire(now)
+
+	state, pres := self.by_source[source]
+	if !pres {
+		state = &replayState{nonces: make(map[string]uint64)}
+		self.by_source[source] = state
+	}
+
+	_, pres = state.nonces[string(nonce)]
+	if pres {
+		metricReplayRejected.WithLabelValues("nonce").Inc()
+		return ReplayError
+	}
+
+	if timestamp+self.window < state.latest {
+		// The sender's clock may have been set back. Once nothing
+		// was accepted for a whole window of our own time the
+		// high-water mark expires and the sender starts over from
+		// this message.
+		window := time.Duration(self.window) * time.Microsecond
+		if now.Sub(state.last_seen) <= window {
+			metricReplayRejected.WithLabelValues("expired").Inc()
+			return ReplayError
+		}
+		state.latest = 0
+	}
+
+	// Only accepted messages keep the sender alive, otherwise
+	// rejected messages would prevent the high-water mark from
+	// expiring.
+	state.last_seen = now
+	state.nonces[string(nonce)] = timestamp
+	if timestamp > state.latest {
+		state.latest = timestamp
+
+		for k, v := range state.nonces {
+			if v+self.window < state.latest {
+				delete(state.nonces, k)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (self *ReplayCache) expire(now time.Time) {
+	if now.Sub(self.last_expiry) < time.Minute {
+		return
+	}
+	self.last_expiry = now
+
+	for source, state := range self.by_source {
+		if now.Sub(state.last_seen) > replay_source_expiry {
+			delete(self.by_source, source)
+		}
+	}
+}
+
+func (self *ReplayCache) Clear() {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	self.by_source = make(map[string]*replayState)
+}
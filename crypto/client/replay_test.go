@@ -0,0 +1,77 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/utils"
+)
+
+func TestReplayCache(t *testing.T) {
+	config_obj := &config_proto.Config{
+		Frontend: &config_proto.FrontendConfig{ReplayWindow: 10},
+	}
+
+	now := time.Unix(1600000000, 0)
+	cache := NewReplayCache(config_obj)
+	cache.clock = &utils.MockClock{MockNow: now}
+
+	// Timestamps in microseconds. The sender's clock is far behind
+	// ours which does not matter.
+	ts := uint64(1000000000 * 1000000)
+	assert.NoError(t, cache.Check("C.1", []byte("1"), ts))
+
+	// The same nonce is rejected.
+	assert.ErrorIs(t, cache.Check("C.1", []byte("1"), ts), ReplayError)
+
+	// But not from a different sender.
+	assert.NoError(t, cache.Check("C.2", []byte("1"), ts))
+
+	// Messages may arrive out of order within the window.
+	assert.NoError(t, cache.Check("C.1", []byte("2"), ts+20*1000000))
+	assert.NoError(t, cache.Check("C.1", []byte("3"), ts+15*1000000))
+
+	// Messages older than the window are rejected even though the
+	// nonce was forgotten.
+	assert.ErrorIs(t, cache.Check("C.1", []byte("1"), ts), ReplayError)
+
+	// Replay protection can be disabled.
+	config_obj.Frontend.ReplayWindow = -1
+	cache = NewReplayCache(config_obj)
+	assert.NoError(t, cache.Check("C.1", []byte("1"), ts))
+	assert.NoError(t, cache.Check("C.1", []byte("1"), ts))
+}
+
+func TestReplayCacheClockRollback(t *testing.T) {
+	config_obj := &config_proto.Config{
+		Frontend: &config_proto.FrontendConfig{ReplayWindow: 10},
+	}
+
+	clock := &utils.MockClock{MockNow: time.Unix(1600000000, 0)}
+	cache := NewReplayCache(config_obj)
+	cache.clock = clock
+
+	ts := uint64(1600000000 * 1000000)
+	assert.NoError(t, cache.Check("C.1", []byte("1"), ts))
+
+	// The sender's clock is set back an hour. Its messages are
+	// rejected while we recently accepted messages from it.
+	rolled_back := ts - 3600*1000000
+	assert.ErrorIs(t, cache.Check("C.1", []byte("2"), rolled_back), ReplayError)
+
+	// Rejected messages do not keep the high-water mark alive.
+	clock.MockNow = clock.MockNow.Add(5 * time.Second)
+	assert.ErrorIs(t, cache.Check("C.1", []byte("3"),
+		rolled_back+5*1000000), ReplayError)
+
+	// Once nothing was accepted for a window the sender starts over.
+	clock.MockNow = clock.MockNow.Add(6 * time.Second)
+	assert.NoError(t, cache.Check("C.1", []byte("4"), rolled_back+11*1000000))
+	assert.NoError(t, cache.Check("C.1", []byte("5"), rolled_back+12*1000000))
+
+	// Replays are still rejected.
+	assert.ErrorIs(t, cache.Check("C.1", []byte("4"),
+		rolled_back+11*1000000), ReplayError)
+}
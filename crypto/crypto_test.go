@@ -19,22 +19,31 @@ package crypto_test
 
 import (
 	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"google.golang.org/protobuf/proto"
+	actions_proto "www.velocidex.com/golang/velociraptor/actions/proto"
 	"www.velocidex.com/golang/velociraptor/config"
 	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
 	crypto_client "www.velocidex.com/golang/velociraptor/crypto/client"
 	crypto_proto "www.velocidex.com/golang/velociraptor/crypto/proto"
 	crypto_server "www.velocidex.com/golang/velociraptor/crypto/server"
 	crypto_utils "www.velocidex.com/golang/velociraptor/crypto/utils"
+	"www.velocidex.com/golang/velociraptor/datastore"
 	"www.velocidex.com/golang/velociraptor/file_store/test_utils"
+	"www.velocidex.com/golang/velociraptor/paths"
+	"www.velocidex.com/golang/velociraptor/services"
 	"www.velocidex.com/golang/velociraptor/utils"
 )
 
@@ -48,7 +57,9 @@ type TestSuite struct {
 func (self *TestSuite) SetupTest() {
 	self.ConfigObj = self.LoadConfig()
 	self.ConfigObj.Frontend.ServerServices = &config_proto.ServerServicesConfig{
-		IndexServer: true,
+		IndexServer:    true,
+		ClientInfo:     true,
+		JournalService: true,
 	}
 	self.ConfigObj.Client.WritebackLinux = ""
 	self.ConfigObj.Client.WritebackWindows = ""
@@ -100,16 +111,16 @@ func (self *TestSuite) TestEncDecServerToClient() {
 	serialized, err := proto.Marshal(message_list)
 	assert.NoError(t, err)
 
-	cipher_text, err := self.server_manager.Encrypt(
-		[][]byte{serialized},
-		crypto_proto.PackedMessageList_ZCOMPRESSION,
-		self.client_id)
-	assert.NoError(t, err)
-
 	initial_c := testutil.ToFloat64(crypto_client.RsaDecryptCounter)
 
-	// Decrypt the same message 100 times.
+	// Decrypt 100 messages from the same session.
 	for i := 0; i < 100; i++ {
+		cipher_text, err := self.server_manager.Encrypt(
+			[][]byte{serialized},
+			crypto_proto.PackedMessageList_ZCOMPRESSION,
+			self.client_id)
+		assert.NoError(t, err)
+
 		message_info, err := self.client_manager.Decrypt(cipher_text)
 		if err != nil {
 			t.Fatal(err)
@@ -137,16 +148,16 @@ func (self *TestSuite) TestEncDecClientToServer() {
 	}
 
 	ConfigObj := config.GetDefaultConfig()
-	cipher_text, err := self.client_manager.EncryptMessageList(
-		message_list,
-		crypto_proto.PackedMessageList_ZCOMPRESSION,
-		ConfigObj.Client.PinnedServerName)
-	assert.NoError(t, err)
-
 	initial_c := testutil.ToFloat64(crypto_client.RsaDecryptCounter)
 
-	// Decrypt the same message 100 times.
+	// Decrypt 100 messages from the same session.
 	for i := 0; i < 100; i++ {
+		cipher_text, err := self.client_manager.EncryptMessageList(
+			message_list,
+			crypto_proto.PackedMessageList_ZCOMPRESSION,
+			ConfigObj.Client.PinnedServerName)
+		assert.NoError(t, err)
+
 		message_info, err := self.server_manager.Decrypt(cipher_text)
 		if err != nil {
 			t.Fatal(err)
@@ -166,6 +177,68 @@ func (self *TestSuite) TestEncDecClientToServer() {
 	assert.Equal(t, c-initial_c, float64(1))
 }
 
+func (self *TestSuite) TestCompressionNegotiation() {
+	t := self.T()
+
+	// The server advertises its compression policy to the client.
+	cipher_text, err := self.server_manager.EncryptMessageList(
+		&crypto_proto.MessageList{},
+		crypto_proto.PackedMessageList_UNCOMPRESSED,
+		self.client_id)
+	assert.NoError(t, err)
+
+	message_info, err := self.client_manager.Decrypt(cipher_text)
+	assert.NoError(t, err)
+	require.NotNil(t, message_info.CompressionPolicy)
+	assert.Equal(t, crypto_proto.PackedMessageList_ZCOMPRESSION,
+		message_info.CompressionPolicy.Compression)
+	assert.Equal(t, uint64(1024), message_info.CompressionPolicy.MinSize)
+
+	// The client sends a small message uncompressed and a large one
+	// compressed in the same packet.
+	small, err := proto.Marshal(&crypto_proto.MessageList{
+		Job: []*crypto_proto.VeloMessage{{Name: "small"}},
+	})
+	assert.NoError(t, err)
+
+	large, err := proto.Marshal(&crypto_proto.MessageList{
+		Job: []*crypto_proto.VeloMessage{{
+			Name:     "large",
+			Args: []byte(strings.Repeat("A", 10000)),
+		}},
+	})
+	assert.NoError(t, err)
+
+	compressed, err := utils.Compress(large)
+	assert.NoError(t, err)
+
+	cipher_text, err = self.client_manager.EncryptMessageLists(
+		[][]byte{small, compressed},
+		[]crypto_proto.PackedMessageList_CompressionType{
+			crypto_proto.PackedMessageList_UNCOMPRESSED,
+			crypto_proto.PackedMessageList_ZCOMPRESSION,
+		}, config.GetDefaultConfig().Client.PinnedServerName)
+	assert.NoError(t, err)
+
+	message_info, err = self.server_manager.Decrypt(cipher_text)
+	assert.NoError(t, err)
+
+	jobs := make(map[string]*crypto_proto.VeloMessage)
+	err = message_info.IterateJobs(context.Background(),
+		func(ctx context.Context, item *crypto_proto.VeloMessage) {
+			jobs[item.Name] = item
+		})
+	assert.NoError(t, err)
+	require.Equal(t, 2, len(jobs))
+
+	// Uncompressed messages use the same bandwidth as their size.
+	assert.True(t, jobs["small"].PayloadSize > 0)
+	assert.Equal(t, jobs["small"].PayloadSize, jobs["small"].WireSize)
+
+	assert.True(t, jobs["large"].PayloadSize > 10000)
+	assert.True(t, jobs["large"].WireSize < jobs["large"].PayloadSize/10)
+}
+
 func (self *TestSuite) TestEncryption() {
 	t := self.T()
 	plain_text := []byte("hello world")
@@ -196,6 +269,29 @@ func (self *TestSuite) TestEncryption() {
 	assert.Equal(t, c-initial_c, float64(1))
 }
 
+func (self *TestSuite) TestReplay() {
+	t := self.T()
+
+	ConfigObj := config.GetDefaultConfig()
+	cipher_text, err := self.client_manager.EncryptMessageList(
+		&crypto_proto.MessageList{},
+		crypto_proto.PackedMessageList_ZCOMPRESSION,
+		ConfigObj.Client.PinnedServerName)
+	assert.NoError(t, err)
+
+	_, err = self.server_manager.Decrypt(cipher_text)
+	assert.NoError(t, err)
+
+	// A captured message can not be sent again.
+	_, err = self.server_manager.Decrypt(cipher_text)
+	assert.True(t, errors.Is(err, crypto_client.ReplayError))
+
+	// Even after the cipher is evicted from the cache.
+	self.server_manager.DeleteCipher(self.client_id)
+	_, err = self.server_manager.Decrypt(cipher_text)
+	assert.True(t, errors.Is(err, crypto_client.ReplayError))
+}
+
 func (self *TestSuite) TestClientIDFromPublicKey() {
 	t := self.T()
 
@@ -209,6 +305,113 @@ func (self *TestSuite) TestClientIDFromPublicKey() {
 	assert.True(t, strings.HasPrefix(client_id, "C."))
 }
 
+// The client reads its id from the writeback file.
+func (self *TestSuite) setWritebackClientId(client_id string) {
+	tmpdir, err := ioutil.TempDir("", "crypto_test")
+	assert.NoError(self.T(), err)
+	self.T().Cleanup(func() { os.RemoveAll(tmpdir) })
+
+	writeback_path := filepath.Join(tmpdir, "writeback.yaml")
+	self.ConfigObj.Client.WritebackLinux = writeback_path
+	self.ConfigObj.Client.WritebackDarwin = writeback_path
+	self.ConfigObj.Client.WritebackWindows = writeback_path
+
+	err = config.UpdateWriteback(self.ConfigObj.Client,
+		&config_proto.Writeback{ClientId: client_id})
+	assert.NoError(self.T(), err)
+}
+
+func (self *TestSuite) TestHardwareClientId() {
+	t := self.T()
+
+	client_id := crypto_utils.ClientIDFromHardwareId(
+		"4C4C4544-0042-3510-8052-B4C04F565031")
+	assert.True(t, crypto_utils.IsValidClientId(client_id))
+
+	// A re-imaged machine has a new key but keeps its client id.
+	enroll := func() (string, error) {
+		key, err := crypto_utils.GeneratePrivateKey()
+		assert.NoError(t, err)
+
+		manager, err := crypto_client.NewClientCryptoManager(self.ConfigObj, key)
+		assert.NoError(t, err)
+		assert.Equal(t, client_id, manager.ClientId)
+
+		csr, err := manager.GetCSR()
+		assert.NoError(t, err)
+
+		return self.server_manager.AddCertificateRequest(self.ConfigObj, csr)
+	}
+
+	self.setWritebackClientId(client_id)
+
+	// Hardware ids are not allowed by default.
+	_, err := enroll()
+	assert.Error(t, err)
+
+	self.ConfigObj.Frontend.AllowHardwareClientIds = true
+	enrolled_id, err := enroll()
+	assert.NoError(t, err)
+	assert.Equal(t, client_id, enrolled_id)
+
+	// The client was not seen yet so it may enroll again.
+	_, err = enroll()
+	assert.NoError(t, err)
+
+	// Once the client is active a new key is a collision.
+	db, err := datastore.GetDB(self.ConfigObj)
+	assert.NoError(t, err)
+
+	err = db.SetSubject(self.ConfigObj,
+		paths.NewClientPathManager(client_id).Path(),
+		&actions_proto.ClientInfo{ClientId: client_id})
+	assert.NoError(t, err)
+
+	client_info_manager, err := services.GetClientInfoManager(self.ConfigObj)
+	assert.NoError(t, err)
+
+	client_info_manager.UpdateStats(client_id, &services.Stats{
+		Ping: uint64(time.Now().UnixNano() / 1000),
+	})
+
+	_, err = enroll()
+	assert.True(t, errors.Is(err, crypto_server.HardwareClientIdCollisionError))
+}
+
+func (self *TestSuite) TestHardwareClientIdTakeover() {
+	t := self.T()
+
+	self.ConfigObj.Frontend.AllowHardwareClientIds = true
+
+	// Enroll a new key claiming the id of an existing client whose
+	// id was derived from its key.
+	self.setWritebackClientId(self.client_id)
+	key, err := crypto_utils.GeneratePrivateKey()
+	assert.NoError(t, err)
+
+	manager, err := crypto_client.NewClientCryptoManager(self.ConfigObj, key)
+	assert.NoError(t, err)
+	assert.Equal(t, self.client_id, manager.ClientId)
+
+	csr, err := manager.GetCSR()
+	assert.NoError(t, err)
+
+	// The client was never seen but it must not be taken over.
+	_, err = self.server_manager.AddCertificateRequest(self.ConfigObj, csr)
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, crypto_server.HardwareClientIdCollisionError))
+
+	// The original key is kept.
+	private_key, err := crypto_utils.ParseRsaPrivateKeyFromPemStr(
+		[]byte(self.ConfigObj.Writeback.PrivateKey))
+	assert.NoError(t, err)
+
+	existing_key, pres := self.server_manager.Resolver.GetPublicKey(
+		self.ConfigObj, self.client_id)
+	assert.True(t, pres)
+	assert.True(t, existing_key.Equal(&private_key.PublicKey))
+}
+
 func TestMain(t *testing.T) {
 	suite.Run(t, new(TestSuite))
 }
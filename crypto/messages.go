@@ -19,6 +19,20 @@ type MessageInfo struct {
 	RemoteAddr    string
 	Compression   crypto_proto.PackedMessageList_CompressionType
 	OrgId         string
+
+	// The compression of each element of RawCompressed when they
+	// are not all compressed the same way.
+	MessageCompression []crypto_proto.PackedMessageList_CompressionType
+
+	// The compression the remote end would like us to use.
+	CompressionPolicy *crypto_proto.CompressionPolicy
+}
+
+func (self *MessageInfo) compression(idx int) crypto_proto.PackedMessageList_CompressionType {
+	if idx < len(self.MessageCompression) {
+		return self.MessageCompression[idx]
+	}
+	return self.Compression
 }
 
 // Apply the callback on each job message. This saves memory since we
@@ -26,8 +40,9 @@ type MessageInfo struct {
 func (self *MessageInfo) IterateJobs(
 	ctx context.Context,
 	processor func(ctx context.Context, msg *crypto_proto.VeloMessage)) error {
-	for _, raw := range self.RawCompressed {
-		if self.Compression == crypto_proto.PackedMessageList_ZCOMPRESSION {
+	for idx, raw := range self.RawCompressed {
+		wire_size := len(raw)
+		if self.compression(idx) == crypto_proto.PackedMessageList_ZCOMPRESSION {
 			decompressed, err := utils.Uncompress(ctx, raw)
 			if err != nil {
 				return errors.New("Unable to decompress MessageList")
@@ -42,6 +57,15 @@ func (self *MessageInfo) IterateJobs(
 		}
 
 		for _, job := range message_list.Job {
+			// Attribute the bandwidth to each message in
+			// proportion to its size so it can be accounted for
+			// the flow.
+			job.PayloadSize = uint64(proto.Size(job))
+			if len(raw) > 0 {
+				job.WireSize = job.PayloadSize * uint64(wire_size) /
+					uint64(len(raw))
+			}
+
 			if self.Authenticated {
 				job.AuthState = crypto_proto.VeloMessage_AUTHENTICATED
 			}
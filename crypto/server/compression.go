@@ -0,0 +1,43 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	crypto_proto "www.velocidex.com/golang/velociraptor/crypto/proto"
+)
+
+const (
+	DEFAULT_MIN_COMPRESSION_SIZE = 1024
+)
+
+// The policy the frontend advertises to clients. Clients which do
+// not understand it keep compressing everything.
+func getCompressionPolicy(
+	config_obj *config_proto.Config) (*crypto_proto.CompressionPolicy, error) {
+	result := &crypto_proto.CompressionPolicy{
+		Compression: crypto_proto.PackedMessageList_ZCOMPRESSION,
+		MinSize:     DEFAULT_MIN_COMPRESSION_SIZE,
+	}
+
+	resources := config_obj.Frontend.Resources
+	if resources == nil {
+		return result, nil
+	}
+
+	if resources.MinCompressionSize > 0 {
+		result.MinSize = resources.MinCompressionSize
+	}
+
+	switch strings.ToLower(resources.ClientCompression) {
+	case "", "zlib":
+	case "none":
+		result.Compression = crypto_proto.PackedMessageList_UNCOMPRESSED
+	default:
+		return nil, fmt.Errorf("Unsupported client_compression %v",
+			resources.ClientCompression)
+	}
+
+	return result, nil
+}
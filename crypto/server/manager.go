@@ -5,10 +5,12 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
 	"github.com/Velocidex/ordereddict"
+	"github.com/sirupsen/logrus"
 	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
 	"www.velocidex.com/golang/velociraptor/crypto/client"
 	crypto_proto "www.velocidex.com/golang/velociraptor/crypto/proto"
@@ -16,16 +18,26 @@ import (
 	"www.velocidex.com/golang/velociraptor/datastore"
 	"www.velocidex.com/golang/velociraptor/logging"
 	"www.velocidex.com/golang/velociraptor/paths"
+	"www.velocidex.com/golang/velociraptor/services"
 	"www.velocidex.com/golang/velociraptor/services/journal"
 	"www.velocidex.com/golang/velociraptor/utils"
 )
 
+const (
+	// Seconds
+	DEFAULT_COLLISION_WINDOW = 600
+)
+
+var (
+	HardwareClientIdCollisionError = errors.New("Hardware client id collision")
+)
+
 type ServerCryptoManager struct {
 	*client.CryptoManager
 }
 
 func (self *ServerCryptoManager) Delete(client_id string) {
-
+	self.DeleteCipher(client_id)
 }
 
 func (self *ServerCryptoManager) AddCertificateRequest(
@@ -56,22 +68,90 @@ func (self *ServerCryptoManager) AddCertificateRequest(
 	// corresponds with the public key this client presents. This
 	// avoids the possibility of impersonation since the
 	// public/private key pair is tied into the client id itself.
+	//
+	// Clients may also use an id derived from their hardware if
+	// the server allows it. We can not verify these so we have to
+	// check the id is not in use by another machine.
+	client_id := utils.ClientIdFromConfigObj(common_name, config_obj)
 	if common_name != crypto_utils.ClientIDFromPublicKey(public_key) {
-		return "", errors.New("Invalid CSR")
+		err := self.checkHardwareClientId(
+			config_obj, common_name, client_id, public_key)
+		if err != nil {
+			return "", err
+		}
 	}
-	err = self.Resolver.SetPublicKey(
-		config_obj,
-		utils.ClientIdFromConfigObj(common_name, config_obj),
-		csr.PublicKey.(*rsa.PublicKey))
+
+	err = self.Resolver.SetPublicKey(config_obj, client_id, public_key)
 	if err != nil {
 		return "", err
 	}
 
-	// Derive the client id from the common name and the org id
-	client_id := utils.ClientIdFromConfigObj(csr.Subject.CommonName, config_obj)
 	return client_id, nil
 }
 
+func (self *ServerCryptoManager) checkHardwareClientId(
+	config_obj *config_proto.Config,
+	common_name, client_id string, public_key *rsa.PublicKey) error {
+	if !config_obj.Frontend.AllowHardwareClientIds ||
+		!crypto_utils.IsValidClientId(common_name) {
+		return errors.New("Invalid CSR")
+	}
+
+	existing_key, pres := self.Resolver.GetPublicKey(config_obj, client_id)
+	if !pres || existing_key.Equal(public_key) {
+		return nil
+	}
+
+	logger := logging.GetLogger(config_obj, &logging.Audit)
+
+	// The existing client derived its id from its key so this is
+	// not a hardware id. Otherwise anyone could take over an idle
+	// client by enrolling with its id.
+	if crypto_utils.ClientIDFromPublicKey(existing_key) == common_name {
+		logger.WithFields(logrus.Fields{
+			"client": client_id,
+		}).Error("HardwareClientIdRejected")
+		return fmt.Errorf("Invalid CSR: client %v does not use a hardware id",
+			client_id)
+	}
+
+	// A client re-enrolling with a new key is either the same
+	// machine after re-imaging or a different machine with the same
+	// hardware id (e.g. a cloned VM). If the existing client is
+	// still active it is probably a different machine.
+	collision_window := config_obj.Frontend.HardwareClientIdCollisionWindow
+	if collision_window == 0 {
+		collision_window = DEFAULT_COLLISION_WINDOW
+	}
+
+	client_info_manager, err := services.GetClientInfoManager(config_obj)
+	if err != nil {
+		return err
+	}
+
+	client_info, err := client_info_manager.Get(client_id)
+	if err == nil {
+		last_seen := time.Unix(0, int64(client_info.Ping)*1000)
+		if time.Since(last_seen) < time.Duration(collision_window)*time.Second {
+			logger.WithFields(logrus.Fields{
+				"client":    client_id,
+				"last_seen": last_seen,
+			}).Error("HardwareClientIdCollision")
+			return fmt.Errorf("%w: client %v was seen at %v",
+				HardwareClientIdCollisionError, client_id, last_seen)
+		}
+	}
+
+	logger.WithFields(logrus.Fields{
+		"client": client_id,
+	}).Info("HardwareClientIdReenrolled")
+
+	// Ciphers for the old key are no longer valid.
+	self.DeleteCipher(client_id)
+
+	return nil
+}
+
 func NewServerCryptoManager(
 	ctx context.Context,
 	config_obj *config_proto.Config,
@@ -98,6 +178,12 @@ func NewServerCryptoManager(
 		return nil, err
 	}
 
+	compression_policy, err := getCompressionPolicy(config_obj)
+	if err != nil {
+		return nil, err
+	}
+	base.SetCompressionPolicy(compression_policy)
+
 	server_manager := &ServerCryptoManager{base}
 
 	err = journal.WatchQueueWithCB(ctx, config_obj, wg,
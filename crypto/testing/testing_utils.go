@@ -65,6 +65,24 @@ func (self *NullCryptoManager) Encrypt(
 	return serialized_packed_message_list, nil
 }
 
+func (self *NullCryptoManager) EncryptMessageLists(
+	message_lists [][]byte,
+	compressions []crypto_proto.PackedMessageList_CompressionType,
+	destination string) (
+	[]byte, error) {
+	packed_message_list := &crypto_proto.PackedMessageList{
+		MessageList:        message_lists,
+		MessageCompression: compressions,
+	}
+
+	serialized_packed_message_list, err := proto.Marshal(packed_message_list)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return serialized_packed_message_list, nil
+}
+
 func (self *NullCryptoManager) Decrypt(cipher_text []byte) (
 	*crypto.MessageInfo, error) {
 
@@ -79,5 +97,8 @@ func (self *NullCryptoManager) Decrypt(cipher_text []byte) (
 		Authenticated: true,
 		Source:        "C.123456",
 		Compression:   crypto_proto.PackedMessageList_ZCOMPRESSION,
+
+		MessageCompression: packed_message_list.MessageCompression,
+		CompressionPolicy:  packed_message_list.CompressionPolicy,
 	}, nil
 }
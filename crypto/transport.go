@@ -27,6 +27,9 @@ type ICryptoManager interface {
 	Encrypt(compressed_message_lists [][]byte,
 		compression crypto_proto.PackedMessageList_CompressionType,
 		destination string) ([]byte, error)
+	EncryptMessageLists(message_lists [][]byte,
+		compressions []crypto_proto.PackedMessageList_CompressionType,
+		destination string) ([]byte, error)
 	Decrypt(cipher_text []byte) (*MessageInfo, error)
 }
 
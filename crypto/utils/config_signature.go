@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash"
+
+	errors "github.com/pkg/errors"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+)
+
+var (
+	UnsignedConfigError = errors.New("Client config is not signed")
+)
+
+// Only the settings which control who the client talks to and
+// trusts are signed so signatures survive config upgrades adding
+// new fields.
+func clientConfigDigest(client_config *config_proto.ClientConfig) []byte {
+	h := sha256.New()
+	for _, url := range client_config.ServerUrls {
+		writeField(h, url)
+	}
+	writeField(h, client_config.CaCertificate)
+	writeField(h, client_config.Nonce)
+	writeField(h, client_config.PinnedServerName)
+
+	if client_config.UseSelfSignedSsl {
+		writeField(h, "use_self_signed_ssl")
+	}
+
+	return h.Sum(nil)
+}
+
+// Length prefix each field so fields can not run into each other.
+func writeField(h hash.Hash, value string) {
+	length := make([]byte, 8)
+	binary.LittleEndian.PutUint64(length, uint64(len(value)))
+	_, _ = h.Write(length)
+	_, _ = h.Write([]byte(value))
+}
+
+// Sign the client config with the CA private key.
+func SignClientConfig(
+	ca_config *config_proto.CAConfig,
+	client_config *config_proto.ClientConfig) error {
+	signature, err := signDigest(ca_config, clientConfigDigest(client_config))
+	if err != nil {
+		return err
+	}
+
+	client_config.ConfigSignature = signature
+	return nil
+}
+
+// Verify the client config signature using the CA certificate in the
+// config. Returns UnsignedConfigError if the config has no signature.
+func VerifyClientConfigSignature(client_config *config_proto.ClientConfig) error {
+	if client_config.ConfigSignature == "" {
+		return UnsignedConfigError
+	}
+
+	return verifyDigest(client_config, clientConfigDigest(client_config),
+		client_config.ConfigSignature)
+}
+
+func configOverrideDigest(override *config_proto.ClientConfigOverride) []byte {
+	h := sha256.New()
+	for _, value := range []uint64{
+		override.Version,
+		override.MaxPoll,
+		override.MaxPollStd,
+		override.MaxUploadSize,
+		override.MaxMemoryHardLimit,
+		override.Concurrency,
+	} {
+		writeField(h, fmt.Sprintf("%d", value))
+	}
+
+	for _, url := range override.ExtraServerUrls {
+		writeField(h, url)
+	}
+
+	return h.Sum(nil)
+}
+
+// Sign a config override pushed to the clients with the CA private
+// key.
+func SignClientConfigOverride(
+	ca_config *config_proto.CAConfig,
+	override *config_proto.ClientConfigOverride) error {
+	signature, err := signDigest(ca_config, configOverrideDigest(override))
+	if err != nil {
+		return err
+	}
+
+	override.Signature = signature
+	return nil
+}
+
+// Clients only accept overrides signed by the CA they trust.
+func VerifyClientConfigOverride(
+	client_config *config_proto.ClientConfig,
+	override *config_proto.ClientConfigOverride) error {
+	if override.Signature == "" {
+		return errors.New("Client config override is not signed")
+	}
+
+	return verifyDigest(client_config, configOverrideDigest(override),
+		override.Signature)
+}
+
+func signDigest(ca_config *config_proto.CAConfig, digest []byte) (string, error) {
+	if ca_config == nil || ca_config.PrivateKey == "" {
+		return "", errors.New("No CA private key to sign the client config")
+	}
+
+	private_key, err := ParseRsaPrivateKeyFromPemStr([]byte(ca_config.PrivateKey))
+	if err != nil {
+		return "", err
+	}
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, private_key, crypto.SHA256,
+		digest)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+func verifyDigest(client_config *config_proto.ClientConfig,
+	digest []byte, encoded_signature string) error {
+	signature, err := base64.StdEncoding.DecodeString(encoded_signature)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	cert, err := ParseX509CertFromPemStr([]byte(client_config.CaCertificate))
+	if err != nil {
+		return err
+	}
+
+	public_key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return errors.New("CA certificate does not have an RSA key")
+	}
+
+	err = rsa.VerifyPKCS1v15(public_key, crypto.SHA256, digest, signature)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
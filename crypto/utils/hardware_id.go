@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strings"
+
+	"www.velocidex.com/golang/velociraptor/config"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+)
+
+var (
+	// Manufacturers sometimes leave the hardware UUID unset or set
+	// it to a placeholder shared by many machines.
+	invalid_hardware_ids = map[string]bool{
+		"":                                     true,
+		"00000000-0000-0000-0000-000000000000": true,
+		"ffffffff-ffff-ffff-ffff-ffffffffffff": true,
+		"03000200-0400-0500-0006-000700080009": true,
+	}
+
+	client_id_regex = regexp.MustCompile("^C\\.[0-9a-f]{16}$")
+)
+
+// Client ids may optionally be derived from the machine's hardware
+// identity so a re-imaged machine keeps its client id and its
+// history. Unlike ClientIDFromPublicKey(), the server can not verify
+// that the client owns the id so the server must explicitly allow
+// it.
+func ClientIDFromHardwareId(hardware_id string) string {
+	hashed := sha256.Sum256([]byte(
+		"velociraptor:" + strings.ToLower(strings.TrimSpace(hardware_id))))
+	dst := make([]byte, hex.EncodedLen(8))
+	hex.Encode(dst, hashed[:8])
+	return "C." + string(dst)
+}
+
+func GetHardwareClientId() (string, error) {
+	hardware_id, err := getHardwareId()
+	if err != nil {
+		return "", err
+	}
+
+	hardware_id = strings.ToLower(strings.TrimSpace(hardware_id))
+	if invalid_hardware_ids[hardware_id] {
+		return "", errors.New("No usable hardware id")
+	}
+
+	return ClientIDFromHardwareId(hardware_id), nil
+}
+
+// Client ids which are not derived from the key must still look like
+// client ids.
+func IsValidClientId(client_id string) bool {
+	return client_id_regex.MatchString(client_id)
+}
+
+// The client id is stored in the writeback once derived so it does
+// not change if the hardware id can not be read later. It is
+// otherwise derived from the client's key.
+func GetClientId(config_obj *config_proto.Config,
+	public_key *rsa.PublicKey) string {
+	if config_obj.Client != nil {
+		writeback, err := config.GetWriteback(config_obj.Client)
+		if err == nil && writeback.ClientId != "" {
+			return writeback.ClientId
+		}
+	}
+	return ClientIDFromPublicKey(public_key)
+}
@@ -0,0 +1,28 @@
+// +build darwin
+
+package utils
+
+import (
+	"errors"
+	"os/exec"
+	"regexp"
+)
+
+var (
+	platform_uuid_regex = regexp.MustCompile(`"IOPlatformUUID" = "([^"]+)"`)
+)
+
+func getHardwareId() (string, error) {
+	out, err := exec.Command("ioreg", "-rd1", "-c",
+		"IOPlatformExpertDevice").Output()
+	if err != nil {
+		return "", err
+	}
+
+	match := platform_uuid_regex.FindSubmatch(out)
+	if match == nil {
+		return "", errors.New("IOPlatformUUID not found")
+	}
+
+	return string(match[1]), nil
+}
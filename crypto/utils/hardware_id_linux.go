@@ -0,0 +1,17 @@
+// +build linux
+
+package utils
+
+import (
+	"io/ioutil"
+)
+
+// The SMBIOS system UUID survives re-imaging, unlike
+// /etc/machine-id.
+func getHardwareId() (string, error) {
+	data, err := ioutil.ReadFile("/sys/class/dmi/id/product_uuid")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,9 @@
+// +build !linux,!windows,!darwin
+
+package utils
+
+import "errors"
+
+func getHardwareId() (string, error) {
+	return "", errors.New("Hardware ids are not supported on this platform")
+}
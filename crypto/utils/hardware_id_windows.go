@@ -0,0 +1,27 @@
+// +build windows
+
+package utils
+
+import (
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// Windows records the SMBIOS system UUID of the last hardware
+// configuration it booted with.
+func getHardwareId() (string, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE,
+		`SYSTEM\HardwareConfig`, registry.QUERY_VALUE)
+	if err != nil {
+		return "", err
+	}
+	defer key.Close()
+
+	value, _, err := key.GetStringValue("LastConfig")
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Trim(value, "{}"), nil
+}
@@ -30,6 +30,7 @@ import (
 	errors "github.com/pkg/errors"
 	"www.velocidex.com/golang/velociraptor/config"
 	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/logging"
 )
 
 func ParseRsaPrivateKeyFromPemStr(pem_str []byte) (*rsa.PrivateKey, error) {
@@ -170,6 +171,19 @@ func VerifyConfig(config_obj *config_proto.Config) error {
 		}
 
 		writeback.PrivateKey = string(pem)
+
+		// Only new clients derive their id from the hardware -
+		// existing clients keep their id.
+		if config_obj.Client.UseHardwareClientId && writeback.ClientId == "" {
+			// Fall back to an id derived from the key.
+			client_id, err := GetHardwareClientId()
+			if err != nil {
+				logging.Prelog("Unable to derive client id from hardware: %v", err)
+			} else {
+				writeback.ClientId = client_id
+			}
+		}
+
 		err = config.UpdateWriteback(config_obj.Client, writeback)
 		if err != nil {
 			return fmt.Errorf("During UpdateWriteback: %w", err)
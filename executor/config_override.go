@@ -0,0 +1,192 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"www.velocidex.com/golang/velociraptor/config"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	crypto_utils "www.velocidex.com/golang/velociraptor/crypto/utils"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/responder"
+	"www.velocidex.com/golang/velociraptor/vql/tools"
+)
+
+const (
+	// Bounds for overridden settings so a bad push can not make the
+	// client unreachable or useless.
+	MAX_OVERRIDE_POLL        = 24 * 3600
+	MIN_OVERRIDE_UPLOAD_SIZE = 64 * 1024
+	MIN_OVERRIDE_MEMORY      = 100 * 1024 * 1024
+	MAX_OVERRIDE_CONCURRENCY = 100
+)
+
+func validateConfigOverride(override *config_proto.ClientConfigOverride) error {
+	if override.MaxPoll > MAX_OVERRIDE_POLL {
+		return fmt.Errorf("max_poll must be less than %v", MAX_OVERRIDE_POLL)
+	}
+
+	if override.MaxPollStd > MAX_OVERRIDE_POLL {
+		return fmt.Errorf("max_poll_std must be less than %v", MAX_OVERRIDE_POLL)
+	}
+
+	if override.MaxUploadSize != 0 &&
+		override.MaxUploadSize < MIN_OVERRIDE_UPLOAD_SIZE {
+		return fmt.Errorf("max_upload_size must be at least %v",
+			MIN_OVERRIDE_UPLOAD_SIZE)
+	}
+
+	if override.MaxMemoryHardLimit != 0 &&
+		override.MaxMemoryHardLimit < MIN_OVERRIDE_MEMORY {
+		return fmt.Errorf("max_memory_hard_limit must be at least %v",
+			MIN_OVERRIDE_MEMORY)
+	}
+
+	if override.Concurrency > MAX_OVERRIDE_CONCURRENCY {
+		return fmt.Errorf("concurrency must be less than %v",
+			MAX_OVERRIDE_CONCURRENCY)
+	}
+
+	for _, server_url := range override.ExtraServerUrls {
+		parsed, err := url.Parse(server_url)
+		if err != nil {
+			return fmt.Errorf("Invalid server url %v: %w", server_url, err)
+		}
+
+		if parsed.Scheme != "https" || parsed.Host == "" ||
+			!strings.HasSuffix(server_url, "/") {
+			return fmt.Errorf(
+				"Invalid server url %v: must be https:// and end with /",
+				server_url)
+		}
+	}
+
+	return nil
+}
+
+// Verify the override and apply it to the client config. Nothing is
+// changed unless all the settings are valid.
+func applyConfigOverride(
+	client_config *config_proto.ClientConfig,
+	override *config_proto.ClientConfigOverride) error {
+	err := crypto_utils.VerifyClientConfigOverride(client_config, override)
+	if err != nil {
+		return err
+	}
+
+	err = validateConfigOverride(override)
+	if err != nil {
+		return err
+	}
+
+	if override.MaxPoll > 0 {
+		client_config.MaxPoll = override.MaxPoll
+	}
+
+	if override.MaxPollStd > 0 {
+		client_config.MaxPollStd = override.MaxPollStd
+	}
+
+	if override.MaxUploadSize > 0 {
+		client_config.MaxUploadSize = override.MaxUploadSize
+	}
+
+	if override.MaxMemoryHardLimit > 0 {
+		client_config.MaxMemoryHardLimit = override.MaxMemoryHardLimit
+	}
+
+	if override.Concurrency > 0 {
+		client_config.Concurrency = override.Concurrency
+	}
+
+	return nil
+}
+
+// Apply the override stored in the writeback on startup and return
+// the server urls to connect to. The extra urls are not added to the
+// client config since the server urls are covered by the config
+// signature. An override which is no longer valid is ignored so the
+// client runs with its deployed config.
+func ApplyStoredConfigOverride(config_obj *config_proto.Config) []string {
+	server_urls := append([]string{}, config_obj.Client.ServerUrls...)
+
+	writeback, err := config.GetWriteback(config_obj.Client)
+	if err != nil || writeback.ConfigOverride == nil {
+		return server_urls
+	}
+
+	logger := logging.GetLogger(config_obj, &logging.ClientComponent)
+	override := writeback.ConfigOverride
+	err = applyConfigOverride(config_obj.Client, override)
+	if err != nil {
+		logger.Error("Ignoring client config override version %v: %v",
+			override.Version, err)
+		return server_urls
+	}
+
+	logger.Info("Applied client config override version %v",
+		override.Version)
+
+	return append(server_urls, override.ExtraServerUrls...)
+}
+
+// Store a new override from the server and restart the client to
+// apply it. Invalid overrides are rejected and the client keeps its
+// current settings.
+func updateConfigOverride(
+	ctx context.Context,
+	config_obj *config_proto.Config,
+	responder *responder.Responder,
+	override *config_proto.ClientConfigOverride) {
+
+	writeback, err := config.GetWriteback(config_obj.Client)
+	if err != nil {
+		responder.RaiseError(ctx, fmt.Sprintf("UpdateConfig: %v", err))
+		return
+	}
+
+	current := writeback.ConfigOverride
+	if current != nil && override.Version <= current.Version {
+		responder.Log(ctx, logging.DEFAULT,
+			"UpdateConfig: Ignoring override version %v: "+
+				"version %v already applied",
+			override.Version, current.Version)
+		responder.Return(ctx)
+		return
+	}
+
+	// Check the override against a copy of the deployed config so
+	// the running config is not touched.
+	test_config := proto.Clone(config_obj.Client).(*config_proto.ClientConfig)
+	err = applyConfigOverride(test_config, override)
+	if err != nil {
+		responder.RaiseError(ctx, fmt.Sprintf("UpdateConfig: %v", err))
+		return
+	}
+
+	writeback.ConfigOverride = override
+	err = config.UpdateWriteback(config_obj.Client, writeback)
+	if err != nil {
+		responder.RaiseError(ctx, fmt.Sprintf("UpdateConfig: %v", err))
+		return
+	}
+
+	responder.Log(ctx, logging.DEFAULT,
+		"UpdateConfig: Stored override version %v, restarting client",
+		override.Version)
+	responder.Return(ctx)
+
+	// Allow the responses to be sent before restarting.
+	go func() {
+		time.Sleep(10 * time.Second)
+
+		select {
+		case tools.ClientRestart <- "":
+		default:
+		}
+	}()
+}
@@ -0,0 +1,80 @@
+package executor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"www.velocidex.com/golang/velociraptor/config"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/crypto"
+	crypto_utils "www.velocidex.com/golang/velociraptor/crypto/utils"
+)
+
+func TestConfigOverride(t *testing.T) {
+	dir, err := ioutil.TempDir("", "override")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ca, err := crypto.GenerateCACert(2048)
+	require.NoError(t, err)
+	ca_config := &config_proto.CAConfig{PrivateKey: ca.PrivateKey}
+
+	config_obj := config.GetDefaultConfig()
+	config_obj.Client.CaCertificate = ca.Cert
+	config_obj.Client.ServerUrls = []string{"https://server.example.com/"}
+	config_obj.Client.MaxPoll = 60
+	config_obj.Client.Concurrency = 2
+
+	writeback_path := filepath.Join(dir, "writeback.yaml")
+	config_obj.Client.WritebackLinux = writeback_path
+	config_obj.Client.WritebackWindows = writeback_path
+	config_obj.Client.WritebackDarwin = writeback_path
+
+	override := &config_proto.ClientConfigOverride{
+		Version:         1,
+		MaxPoll:         600,
+		ExtraServerUrls: []string{"https://backup.example.com/"},
+	}
+	require.NoError(t, crypto_utils.SignClientConfigOverride(ca_config, override))
+
+	// Tampering with a signed override is detected.
+	tampered := &config_proto.ClientConfigOverride{
+		Version:   override.Version,
+		MaxPoll:   1,
+		Signature: override.Signature,
+	}
+	test_config := &config_proto.ClientConfig{CaCertificate: ca.Cert}
+	assert.Error(t, applyConfigOverride(test_config, tampered))
+
+	// Validation failures do not change the config.
+	invalid := &config_proto.ClientConfigOverride{
+		Version:         2,
+		MaxPoll:         600,
+		ExtraServerUrls: []string{"http://insecure.example.com/"},
+	}
+	require.NoError(t, crypto_utils.SignClientConfigOverride(ca_config, invalid))
+	assert.Error(t, applyConfigOverride(test_config, invalid))
+	assert.Equal(t, uint64(0), test_config.MaxPoll)
+
+	// Store the override and apply it as the client does on startup.
+	require.NoError(t, config.UpdateWriteback(config_obj.Client,
+		&config_proto.Writeback{ConfigOverride: override}))
+
+	server_urls := ApplyStoredConfigOverride(config_obj)
+	assert.Equal(t, []string{
+		"https://server.example.com/",
+		"https://backup.example.com/",
+	}, server_urls)
+	assert.Equal(t, uint64(600), config_obj.Client.MaxPoll)
+
+	// Unset fields keep the configured value.
+	assert.Equal(t, uint64(2), config_obj.Client.Concurrency)
+
+	// The signed server urls are not changed.
+	assert.Equal(t, []string{"https://server.example.com/"},
+		config_obj.Client.ServerUrls)
+}
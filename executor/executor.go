@@ -275,6 +275,11 @@ func (self *ClientExecutor) processRequestPlugin(
 		return
 	}
 
+	if req.UpdateConfig != nil {
+		updateConfigOverride(ctx, config_obj, responder, req.UpdateConfig)
+		return
+	}
+
 	// This action is deprecated now.
 	if req.UpdateForeman != nil {
 		return
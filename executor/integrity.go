@@ -0,0 +1,300 @@
+package executor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	actions_proto "www.velocidex.com/golang/velociraptor/actions/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/constants"
+	crypto_proto "www.velocidex.com/golang/velociraptor/crypto/proto"
+	crypto_utils "www.velocidex.com/golang/velociraptor/crypto/utils"
+	"www.velocidex.com/golang/velociraptor/json"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/responder"
+	"www.velocidex.com/golang/velociraptor/utils"
+	"www.velocidex.com/golang/velociraptor/vql/parsers/authenticode"
+)
+
+const (
+	// Tamper events are reported in this artifact's event queue.
+	INTEGRITY_ARTIFACT = "Generic.Client.Integrity"
+
+	// Seconds
+	DEFAULT_INTEGRITY_CHECK_PERIOD = 3600
+)
+
+// A failed check. Each check is identified by its name so a failure
+// is only reported once until the check passes again.
+type integrityFailure struct {
+	Check   string
+	Message string
+	Details *ordereddict.Dict
+}
+
+// The integrity checker periodically verifies the client binary,
+// its config and its service installation. The binary is compared
+// against its hash at startup so it is detected if it is replaced
+// while the client is running.
+type IntegrityChecker struct {
+	config_obj *config_proto.Config
+
+	exe_path      string
+	baseline_hash string
+
+	// Checks which are currently failing and the reported message.
+	reported map[string]string
+
+	// The signature is only verified again when the hash changes.
+	signature_hash    string
+	signature_failure *integrityFailure
+}
+
+func NewIntegrityChecker(config_obj *config_proto.Config) (*IntegrityChecker, error) {
+	exe_path, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := hashFile(exe_path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &IntegrityChecker{
+		config_obj:    config_obj,
+		exe_path:      exe_path,
+		baseline_hash: hash,
+		reported:      make(map[string]string),
+	}, nil
+}
+
+// Run all checks and return the failures which were not reported
+// before.
+func (self *IntegrityChecker) Check(ctx context.Context) []*integrityFailure {
+	var failures []*integrityFailure
+	failures = append(failures, self.checkBinary(ctx)...)
+	failures = append(failures, self.checkConfig()...)
+	failures = append(failures, self.checkService()...)
+
+	failing := make(map[string]string)
+	var result []*integrityFailure
+	for _, failure := range failures {
+		failing[failure.Check] = failure.Message
+		if self.reported[failure.Check] != failure.Message {
+			result = append(result, failure)
+		}
+	}
+	self.reported = failing
+
+	return result
+}
+
+func (self *IntegrityChecker) checkBinary(ctx context.Context) []*integrityFailure {
+	hash, err := hashFile(self.exe_path)
+	if err != nil {
+		return []*integrityFailure{{
+			Check:   "Binary",
+			Message: fmt.Sprintf("Unable to read client binary: %v", err),
+			Details: ordereddict.NewDict().Set("Path", self.exe_path),
+		}}
+	}
+
+	details := ordereddict.NewDict().
+		Set("Path", self.exe_path).
+		Set("SHA256", hash).
+		Set("StartupSHA256", self.baseline_hash)
+
+	var result []*integrityFailure
+	if hash != self.baseline_hash {
+		result = append(result, &integrityFailure{
+			Check:   "Binary",
+			Message: "Client binary was modified since the client started",
+			Details: details,
+		})
+	}
+
+	allowed := self.config_obj.Client.GetIntegrity().GetBinaryHashes()
+	if len(allowed) > 0 && !utils.InString(allowed, hash) {
+		result = append(result, &integrityFailure{
+			Check:   "BinaryHash",
+			Message: "Client binary is not an allowed release",
+			Details: details,
+		})
+	}
+
+	// Only the signature of signed binaries can be broken. The
+	// signature can only change with the hash so it is not checked
+	// again unless the hash changes.
+	if hash != self.signature_hash {
+		self.signature_hash = hash
+		self.signature_failure = nil
+
+		signature, err := authenticode.VerifyFile(ctx, self.exe_path)
+		if err == nil && signature.Signed &&
+			signature.Trusted != authenticode.TRUSTED {
+			self.signature_failure = &integrityFailure{
+				Check:   "Signature",
+				Message: "Client binary signature is not valid",
+				Details: ordereddict.NewDict().
+					Set("Path", self.exe_path).
+					Set("SHA256", hash).
+					Set("Trusted", signature.Trusted).
+					Set("Subject", signature.Subject).
+					Set("Error", signature.Error),
+			}
+		}
+	}
+
+	if self.signature_failure != nil {
+		result = append(result, self.signature_failure)
+	}
+
+	return result
+}
+
+// Unsigned configs are not reported since older servers do not sign
+// them.
+func (self *IntegrityChecker) checkConfig() []*integrityFailure {
+	if self.config_obj.Client == nil {
+		return nil
+	}
+
+	err := crypto_utils.VerifyClientConfigSignature(self.config_obj.Client)
+	if err == nil || errors.Is(err, crypto_utils.UnsignedConfigError) {
+		return nil
+	}
+
+	return []*integrityFailure{{
+		Check:   "Config",
+		Message: "Client config signature does not verify",
+		Details: ordereddict.NewDict().
+			Set("Error", err.Error()).
+			Set("ServerUrls", self.config_obj.Client.ServerUrls),
+	}}
+}
+
+func (self *IntegrityChecker) checkService() []*integrityFailure {
+	message, details := checkService(self.config_obj, self.exe_path)
+	if message == "" {
+		return nil
+	}
+
+	return []*integrityFailure{{
+		Check:   "Service",
+		Message: message,
+		Details: details,
+	}}
+}
+
+func hashFile(path string) (string, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer fd.Close()
+
+	hasher := sha256.New()
+	_, err = io.Copy(hasher, fd)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// Send tamper events to the server as urgent monitoring rows so
+// they are not queued behind other collections.
+func sendIntegrityFailures(ctx context.Context,
+	responder *responder.Responder, failures []*integrityFailure) {
+	if len(failures) == 0 {
+		return
+	}
+
+	now := time.Now().UTC()
+	jsonl := []byte{}
+	for _, failure := range failures {
+		serialized, err := json.Marshal(ordereddict.NewDict().
+			Set("Timestamp", now.Unix()).
+			Set("Severity", "HIGH").
+			Set("Check", failure.Check).
+			Set("Message", failure.Message).
+			Set("Details", failure.Details))
+		if err != nil {
+			continue
+		}
+		jsonl = append(jsonl, serialized...)
+		jsonl = append(jsonl, '\n')
+	}
+
+	responder.AddResponse(ctx, &crypto_proto.VeloMessage{
+		VQLResponse: &actions_proto.VQLResponse{
+			Query: &actions_proto.VQLRequest{
+				Name: INTEGRITY_ARTIFACT,
+			},
+			JSONLResponse: string(jsonl),
+			TotalRows:     uint64(len(failures)),
+			Timestamp:     uint64(now.UnixNano() / 1000),
+		}})
+}
+
+func StartIntegrityService(
+	ctx context.Context,
+	wg *sync.WaitGroup,
+	config_obj *config_proto.Config,
+	exe *ClientExecutor) error {
+
+	integrity_config := config_obj.Client.GetIntegrity()
+	if integrity_config.GetDisabled() {
+		return nil
+	}
+
+	logger := logging.GetLogger(config_obj, &logging.ClientComponent)
+	checker, err := NewIntegrityChecker(config_obj)
+	if err != nil {
+		return err
+	}
+
+	period := integrity_config.GetCheckPeriod()
+	if period == 0 {
+		period = DEFAULT_INTEGRITY_CHECK_PERIOD
+	}
+
+	responder := responder.NewResponder(
+		config_obj, &crypto_proto.VeloMessage{
+			SessionId: constants.MONITORING_WELL_KNOWN_FLOW,
+			Urgent:    true,
+		}, exe.Outbound)
+
+	logger.Info("<green>Starting</> client integrity checks every %v seconds.",
+		period)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for {
+			failures := checker.Check(ctx)
+			for _, failure := range failures {
+				logger.Error("Integrity check %v failed: %v",
+					failure.Check, failure.Message)
+			}
+			sendIntegrityFailures(ctx, responder, failures)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Duration(period) * time.Second):
+			}
+		}
+	}()
+
+	return nil
+}
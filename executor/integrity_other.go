@@ -0,0 +1,14 @@
+// +build !windows
+
+package executor
+
+import (
+	"github.com/Velocidex/ordereddict"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+)
+
+// Only the Windows service is currently checked.
+func checkService(
+	config_obj *config_proto.Config, exe_path string) (string, *ordereddict.Dict) {
+	return "", nil
+}
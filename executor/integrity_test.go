@@ -0,0 +1,81 @@
+package executor
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"www.velocidex.com/golang/velociraptor/config"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/crypto"
+	crypto_utils "www.velocidex.com/golang/velociraptor/crypto/utils"
+)
+
+func TestIntegrityChecks(t *testing.T) {
+	ctx := context.Background()
+
+	dir, err := ioutil.TempDir("", "integrity")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	exe_path := filepath.Join(dir, "velociraptor.exe")
+	require.NoError(t, ioutil.WriteFile(exe_path, []byte("original"), 0700))
+
+	ca, err := crypto.GenerateCACert(2048)
+	require.NoError(t, err)
+
+	config_obj := config.GetDefaultConfig()
+	config_obj.Client.CaCertificate = ca.Cert
+	require.NoError(t, crypto_utils.SignClientConfig(
+		&config_proto.CAConfig{PrivateKey: ca.PrivateKey}, config_obj.Client))
+
+	baseline_hash, err := hashFile(exe_path)
+	require.NoError(t, err)
+
+	checker := &IntegrityChecker{
+		config_obj:    config_obj,
+		exe_path:      exe_path,
+		baseline_hash: baseline_hash,
+		reported:      make(map[string]string),
+	}
+
+	// Nothing is wrong yet.
+	assert.Empty(t, checker.Check(ctx))
+
+	// Replace the binary.
+	require.NoError(t, ioutil.WriteFile(exe_path, []byte("modified"), 0700))
+	failures := checker.Check(ctx)
+	require.Equal(t, 1, len(failures))
+	assert.Equal(t, "Binary", failures[0].Check)
+
+	// The failure is only reported once.
+	assert.Empty(t, checker.Check(ctx))
+
+	// Point the client at a different server.
+	config_obj.Client.ServerUrls = []string{"https://evil.com/"}
+	failures = checker.Check(ctx)
+	require.Equal(t, 1, len(failures))
+	assert.Equal(t, "Config", failures[0].Check)
+
+	// Once the binary is restored a new modification is reported
+	// again.
+	require.NoError(t, ioutil.WriteFile(exe_path, []byte("original"), 0700))
+	assert.Empty(t, checker.Check(ctx))
+
+	require.NoError(t, ioutil.WriteFile(exe_path, []byte("modified"), 0700))
+	failures = checker.Check(ctx)
+	require.Equal(t, 1, len(failures))
+	assert.Equal(t, "Binary", failures[0].Check)
+
+	// Only allowed release binaries are accepted.
+	config_obj.Client.Integrity = &config_proto.ClientIntegrityConfig{
+		BinaryHashes: []string{baseline_hash},
+	}
+	failures = checker.Check(ctx)
+	require.Equal(t, 1, len(failures))
+	assert.Equal(t, "BinaryHash", failures[0].Check)
+}
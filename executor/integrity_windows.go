@@ -0,0 +1,65 @@
+// +build windows
+
+package executor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Velocidex/ordereddict"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+)
+
+// Check the service which runs the client was not removed, disabled
+// or pointed at a different binary. Returns a message describing
+// the problem or "" if the service is fine.
+func checkService(
+	config_obj *config_proto.Config, exe_path string) (string, *ordereddict.Dict) {
+	// Nothing to check if we are not running as a service.
+	interactive, err := svc.IsAnInteractiveSession()
+	if err != nil || interactive {
+		return "", nil
+	}
+
+	name := config_obj.Client.GetWindowsInstaller().GetServiceName()
+	if name == "" {
+		return "", nil
+	}
+
+	details := ordereddict.NewDict().Set("ServiceName", name)
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return "", nil
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(name)
+	if err != nil {
+		return fmt.Sprintf("Service %v can not be opened: %v", name, err), details
+	}
+	defer s.Close()
+
+	config, err := s.Config()
+	if err != nil {
+		return fmt.Sprintf("Service %v can not be queried: %v", name, err), details
+	}
+
+	details.Set("BinaryPathName", config.BinaryPathName).
+		Set("StartType", config.StartType)
+
+	if config.StartType != mgr.StartAutomatic {
+		return fmt.Sprintf("Service %v is no longer started automatically", name),
+			details
+	}
+
+	if !strings.Contains(strings.ToLower(config.BinaryPathName),
+		strings.ToLower(exe_path)) {
+		return fmt.Sprintf("Service %v does not run the client binary %v",
+			name, exe_path), details
+	}
+
+	return "", nil
+}
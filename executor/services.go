@@ -27,11 +27,20 @@ func StartServices(
 	}
 
 	// Now start client specific services.
-	return sm.Start(func(ctx context.Context,
+	err = sm.Start(func(ctx context.Context,
 		wg *sync.WaitGroup,
 		config_obj *config_proto.Config) error {
 		return StartEventTableService(ctx, wg, config_obj, exe)
 	})
+	if err != nil {
+		return err
+	}
+
+	return sm.Start(func(ctx context.Context,
+		wg *sync.WaitGroup,
+		config_obj *config_proto.Config) error {
+		return StartIntegrityService(ctx, wg, config_obj, exe)
+	})
 }
 
 func StartEventTableService(
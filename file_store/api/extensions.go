@@ -47,6 +47,9 @@ func GetExtensionForFilestore(path_spec FSPathSpec) string {
 	case PATH_TYPE_FILESTORE_DOWNLOAD_REPORT:
 		return ".html"
 
+	case PATH_TYPE_FILESTORE_DOWNLOAD_PDF:
+		return ".pdf"
+
 	case PATH_TYPE_FILESTORE_TMP:
 		return ".tmp"
 
@@ -113,6 +116,10 @@ func GetFileStorePathTypeFromExtension(name string) (PathType, string) {
 		return PATH_TYPE_FILESTORE_DOWNLOAD_REPORT, name[:len(name)-5]
 	}
 
+	if strings.HasSuffix(name, ".pdf") {
+		return PATH_TYPE_FILESTORE_DOWNLOAD_PDF, name[:len(name)-4]
+	}
+
 	if strings.HasSuffix(name, ".tmp") {
 		return PATH_TYPE_FILESTORE_TMP, name[:len(name)-4]
 	}
@@ -107,6 +107,9 @@ const (
 
 	// Arbitrary extensions.
 	PATH_TYPE_FILESTORE_ANY
+
+	// Reports rendered as PDF in the download folder.
+	PATH_TYPE_FILESTORE_DOWNLOAD_PDF
 )
 
 type _PathSpec interface {
@@ -231,6 +231,12 @@ Frontend:
     label: true
     launcher: true
     notebook_service: false
+    compliance_service: false
+    file_index_service: false
+    job_service: false
+    exchange_service: false
+    canary_service: false
+    flow_reaper_service: false
 
   resources:
     connections_per_second: 100
@@ -36,6 +36,9 @@ type: INTERNAL
 name: Server.Internal.Label
 type: INTERNAL
 `, `
+name: Server.Internal.ClientMetadata
+type: INTERNAL
+`, `
 name: Server.Internal.Notifications
 type: INTERNAL
 `, `
@@ -110,6 +110,7 @@ func (self *FileStoreTestSuite) TestListChildrenWithTypes() {
 		// Used to write zip files in the download folder.
 		api.PATH_TYPE_FILESTORE_DOWNLOAD_ZIP,
 		api.PATH_TYPE_FILESTORE_DOWNLOAD_REPORT,
+		api.PATH_TYPE_FILESTORE_DOWNLOAD_PDF,
 
 		// TMP files
 		api.PATH_TYPE_FILESTORE_TMP,
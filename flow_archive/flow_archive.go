@@ -0,0 +1,359 @@
+/*
+  Flow archival keeps the file store small by moving the bulk data of
+  old flows (result sets, logs, uploads and the text index) into a
+  zip file in the flow archive - usually a mount of cheaper, slower
+  storage.
+
+  The flow's context stays in the datastore so archived flows are
+  still listed and their metadata remains queryable. The context
+  records where the flow was archived to. Before the results can be
+  read again the flow must be rehydrated, which copies the data back
+  into the file store and removes the archive. The GUI rehydrates a
+  flow when an analyst opens it.
+*/
+
+package flow_archive
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/datastore"
+	"www.velocidex.com/golang/velociraptor/file_store"
+	"www.velocidex.com/golang/velociraptor/file_store/api"
+	"www.velocidex.com/golang/velociraptor/file_store/path_specs"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/paths"
+	artifact_paths "www.velocidex.com/golang/velociraptor/paths/artifacts"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/services/launcher"
+	"www.velocidex.com/golang/velociraptor/utils"
+)
+
+var (
+	NotConfiguredError = errors.New("Flow archive is not configured")
+)
+
+// Move the flow's bulk data into the archive.
+func ArchiveFlow(
+	config_obj *config_proto.Config,
+	client_id, flow_id, principal string) (
+	*flows_proto.ArtifactCollectorContext, error) {
+
+	collection_context, err := getCollectionContext(
+		config_obj, client_id, flow_id)
+	if err != nil {
+		return nil, err
+	}
+
+	if collection_context.ArchiveLocation != "" {
+		return nil, fmt.Errorf("%w: Flow %v is already archived",
+			services.FlowInvalidStateError, flow_id)
+	}
+
+	if collection_context.State == flows_proto.ArtifactCollectorContext_RUNNING {
+		return nil, fmt.Errorf("%w: Flow %v is still running",
+			services.FlowInvalidStateError, flow_id)
+	}
+
+	location, err := getArchiveLocation(config_obj, client_id, flow_id)
+	if err != nil {
+		return nil, err
+	}
+
+	files := getFlowFiles(config_obj, collection_context)
+	err = writeArchive(config_obj, location, files)
+	if err != nil {
+		return nil, err
+	}
+
+	collection_context.ArchiveLocation = location
+	collection_context.ArchiveTime = uint64(time.Now().UnixNano() / 1000)
+	err = setCollectionContext(config_obj, collection_context)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only remove the data once the flow is marked as archived. If
+	// we fail part way through, rehydrating the flow will restore
+	// it.
+	file_store_factory := file_store.GetFileStore(config_obj)
+	for _, path := range files {
+		err = file_store_factory.Delete(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	auditArchive(config_obj, collection_context, principal, "ArchiveFlow")
+	launcher.NotifyFlowModification(config_obj, client_id, flow_id, "Archive")
+
+	return collection_context, nil
+}
+
+// Restore an archived flow into the file store. Flows which are not
+// archived are left alone.
+func RehydrateFlow(
+	config_obj *config_proto.Config,
+	client_id, flow_id, principal string) (
+	*flows_proto.ArtifactCollectorContext, error) {
+
+	collection_context, err := getCollectionContext(
+		config_obj, client_id, flow_id)
+	if err != nil {
+		return nil, err
+	}
+
+	if collection_context.ArchiveLocation == "" {
+		return collection_context, nil
+	}
+
+	err = readArchive(config_obj, collection_context.ArchiveLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	location := collection_context.ArchiveLocation
+	collection_context.ArchiveLocation = ""
+	collection_context.ArchiveTime = 0
+	err = setCollectionContext(config_obj, collection_context)
+	if err != nil {
+		return nil, err
+	}
+
+	auditArchive(config_obj, collection_context, principal, "RehydrateFlow")
+	launcher.NotifyFlowModification(config_obj, client_id, flow_id, "Rehydrate")
+
+	// The data is back in the file store so the archive is not
+	// needed any more.
+	err = os.Remove(location)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return collection_context, nil
+}
+
+// Archives are stored by org, client and flow.
+func getArchiveLocation(config_obj *config_proto.Config,
+	client_id, flow_id string) (string, error) {
+	if config_obj.FlowArchive == nil || config_obj.FlowArchive.Location == "" {
+		return "", NotConfiguredError
+	}
+
+	org_id := config_obj.OrgId
+	if org_id == "" {
+		org_id = "root"
+	}
+
+	return filepath.Join(config_obj.FlowArchive.Location,
+		utils.SanitizeString(org_id),
+		utils.SanitizeString(client_id),
+		utils.SanitizeString(flow_id)+".zip"), nil
+}
+
+// The flow's data in the file store. The flow's notebook is not
+// archived.
+func getFlowFiles(config_obj *config_proto.Config,
+	collection_context *flows_proto.ArtifactCollectorContext) []api.FSPathSpec {
+	client_id := collection_context.ClientId
+	flow_id := collection_context.SessionId
+
+	result := []api.FSPathSpec{}
+	file_store_factory := file_store.GetFileStore(config_obj)
+
+	// Result sets are stored outside the flow's directory.
+	for _, artifact_name := range collection_context.ArtifactsWithResults {
+		path_manager, err := artifact_paths.NewArtifactPathManager(
+			config_obj, client_id, flow_id, artifact_name)
+		if err != nil {
+			continue
+		}
+
+		result_path, err := path_manager.GetPathForWriting()
+		if err != nil {
+			continue
+		}
+
+		for _, path := range []api.FSPathSpec{result_path,
+			result_path.SetType(api.PATH_TYPE_FILESTORE_JSON_INDEX)} {
+			_, err = file_store_factory.StatFile(path)
+			if err == nil {
+				result = append(result, path)
+			}
+		}
+	}
+
+	// Logs, uploads and the text index.
+	flow_path_manager := paths.NewFlowPathManager(client_id, flow_id)
+	_ = api.Walk(file_store_factory, flow_path_manager.Path().AsFilestorePath(),
+		func(path api.FSPathSpec, info os.FileInfo) error {
+			result = append(result, path)
+			return nil
+		})
+
+	return result
+}
+
+// Each file is stored in the zip under its file store path.
+func writeArchive(config_obj *config_proto.Config,
+	location string, files []api.FSPathSpec) error {
+	err := os.MkdirAll(filepath.Dir(location), 0700)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file so a partial archive never
+	// replaces a good one.
+	tmp_location := location + ".tmp"
+	out_fd, err := os.OpenFile(tmp_location,
+		os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp_location)
+
+	zip_writer := zip.NewWriter(out_fd)
+	file_store_factory := file_store.GetFileStore(config_obj)
+	for _, path := range files {
+		err = copyToArchive(file_store_factory, zip_writer, path)
+		if err != nil {
+			out_fd.Close()
+			return err
+		}
+	}
+
+	err = zip_writer.Close()
+	if err != nil {
+		out_fd.Close()
+		return err
+	}
+
+	err = out_fd.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp_location, location)
+}
+
+func copyToArchive(file_store_factory api.FileStore,
+	zip_writer *zip.Writer, path api.FSPathSpec) error {
+	reader, err := file_store_factory.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	writer, err := zip_writer.Create(
+		strings.TrimPrefix(path.AsClientPath(), "/"))
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(writer, reader)
+	return err
+}
+
+func readArchive(config_obj *config_proto.Config, location string) error {
+	zip_reader, err := zip.OpenReader(location)
+	if err != nil {
+		return err
+	}
+	defer zip_reader.Close()
+
+	file_store_factory := file_store.GetFileStore(config_obj)
+	for _, member := range zip_reader.File {
+		path := path_specs.NewUnsafeFilestorePath(
+			utils.SplitComponents(member.Name)...).
+			SetType(api.PATH_TYPE_FILESTORE_ANY)
+
+		err = copyFromArchive(file_store_factory, member, path)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func copyFromArchive(file_store_factory api.FileStore,
+	member *zip.File, path api.FSPathSpec) error {
+	reader, err := member.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	writer, err := file_store_factory.WriteFileWithCompletion(
+		path, utils.SyncCompleter)
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+
+	// File store writers append so clear any partial data left
+	// over from an interrupted archive.
+	err = writer.Truncate()
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(writer, reader)
+	return err
+}
+
+func getCollectionContext(config_obj *config_proto.Config,
+	client_id, flow_id string) (*flows_proto.ArtifactCollectorContext, error) {
+	db, err := datastore.GetDB(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	collection_context := &flows_proto.ArtifactCollectorContext{}
+	err = db.GetSubject(config_obj,
+		paths.NewFlowPathManager(client_id, flow_id).Path(),
+		collection_context)
+	if err != nil {
+		return nil, err
+	}
+
+	if collection_context.SessionId == "" {
+		return nil, fmt.Errorf("Unknown flow %v %v", client_id, flow_id)
+	}
+
+	return collection_context, nil
+}
+
+func setCollectionContext(config_obj *config_proto.Config,
+	collection_context *flows_proto.ArtifactCollectorContext) error {
+	db, err := datastore.GetDB(config_obj)
+	if err != nil {
+		return err
+	}
+
+	return db.SetSubject(config_obj,
+		paths.NewFlowPathManager(collection_context.ClientId,
+			collection_context.SessionId).Path(),
+		collection_context)
+}
+
+func auditArchive(config_obj *config_proto.Config,
+	collection_context *flows_proto.ArtifactCollectorContext,
+	principal, action string) {
+	logging.GetLogger(config_obj, &logging.Audit).
+		WithFields(logrus.Fields{
+			"user":    principal,
+			"client":  collection_context.ClientId,
+			"flow_id": collection_context.SessionId,
+		}).Info(action)
+}
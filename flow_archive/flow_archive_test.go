@@ -0,0 +1,162 @@
+package flow_archive_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/datastore"
+	"www.velocidex.com/golang/velociraptor/file_store"
+	"www.velocidex.com/golang/velociraptor/file_store/api"
+	"www.velocidex.com/golang/velociraptor/file_store/test_utils"
+	"www.velocidex.com/golang/velociraptor/flow_archive"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/paths"
+	artifact_paths "www.velocidex.com/golang/velociraptor/paths/artifacts"
+	"www.velocidex.com/golang/velociraptor/utils"
+)
+
+var (
+	CLIENT_ID = "C.1234"
+	FLOW_ID   = "F.1234"
+)
+
+type FlowArchiveTestSuite struct {
+	test_utils.TestSuite
+	archive_dir string
+}
+
+func (self *FlowArchiveTestSuite) SetupTest() {
+	self.ConfigObj = self.LoadConfig()
+	self.LoadArtifacts([]string{`
+name: Custom.Test
+type: CLIENT
+`})
+
+	self.TestSuite.SetupTest()
+
+	var err error
+	self.archive_dir, err = ioutil.TempDir("", "flow_archive_test")
+	assert.NoError(self.T(), err)
+
+	self.ConfigObj.FlowArchive = &config_proto.FlowArchiveConfig{
+		Location: self.archive_dir,
+	}
+
+	db, err := datastore.GetDB(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	err = db.SetSubject(self.ConfigObj,
+		paths.NewFlowPathManager(CLIENT_ID, FLOW_ID).Path(),
+		&flows_proto.ArtifactCollectorContext{
+			ClientId:             CLIENT_ID,
+			SessionId:            FLOW_ID,
+			State:                flows_proto.ArtifactCollectorContext_FINISHED,
+			ArtifactsWithResults: []string{"Custom.Test"},
+		})
+	assert.NoError(self.T(), err)
+}
+
+func (self *FlowArchiveTestSuite) TearDownTest() {
+	os.RemoveAll(self.archive_dir)
+	self.TestSuite.TearDownTest()
+}
+
+func (self *FlowArchiveTestSuite) writeFile(path api.FSPathSpec, data string) {
+	file_store_factory := file_store.GetFileStore(self.ConfigObj)
+	writer, err := file_store_factory.WriteFileWithCompletion(
+		path, utils.SyncCompleter)
+	assert.NoError(self.T(), err)
+	defer writer.Close()
+
+	_, err = writer.Write([]byte(data))
+	assert.NoError(self.T(), err)
+}
+
+func (self *FlowArchiveTestSuite) readFile(path api.FSPathSpec) (string, error) {
+	file_store_factory := file_store.GetFileStore(self.ConfigObj)
+	reader, err := file_store_factory.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	data, err := ioutil.ReadAll(reader)
+	return string(data), err
+}
+
+func (self *FlowArchiveTestSuite) TestArchiveAndRehydrate() {
+	flow_path_manager := paths.NewFlowPathManager(CLIENT_ID, FLOW_ID)
+	path_manager, err := artifact_paths.NewArtifactPathManager(
+		self.ConfigObj, CLIENT_ID, FLOW_ID, "Custom.Test")
+	assert.NoError(self.T(), err)
+
+	result_path, err := path_manager.GetPathForWriting()
+	assert.NoError(self.T(), err)
+
+	upload_path := flow_path_manager.GetUploadsFile(
+		"file", "/tmp/evidence.txt").Path()
+
+	self.writeFile(result_path, "{\"Row\":1}\n")
+	self.writeFile(flow_path_manager.Log(), "{\"message\":\"Hello\"}\n")
+	self.writeFile(upload_path, "Uploaded data")
+
+	collection_context, err := flow_archive.ArchiveFlow(
+		self.ConfigObj, CLIENT_ID, FLOW_ID, "admin")
+	assert.NoError(self.T(), err)
+	assert.NotEmpty(self.T(), collection_context.ArchiveLocation)
+
+	// The bulk data is moved out of the file store.
+	_, err = os.Stat(collection_context.ArchiveLocation)
+	assert.NoError(self.T(), err)
+
+	for _, path := range []api.FSPathSpec{
+		result_path, flow_path_manager.Log(), upload_path} {
+		_, err = self.readFile(path)
+		assert.Error(self.T(), err, path.AsClientPath())
+	}
+
+	// Archiving again is an error.
+	_, err = flow_archive.ArchiveFlow(
+		self.ConfigObj, CLIENT_ID, FLOW_ID, "admin")
+	assert.Error(self.T(), err)
+
+	// Rehydrating restores the data and removes the archive.
+	collection_context, err = flow_archive.RehydrateFlow(
+		self.ConfigObj, CLIENT_ID, FLOW_ID, "admin")
+	assert.NoError(self.T(), err)
+	assert.Empty(self.T(), collection_context.ArchiveLocation)
+
+	data, err := self.readFile(result_path)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), "{\"Row\":1}\n", data)
+
+	data, err = self.readFile(flow_path_manager.Log())
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), "{\"message\":\"Hello\"}\n", data)
+
+	data, err = self.readFile(upload_path)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), "Uploaded data", data)
+
+	// Rehydrating a flow which is not archived does nothing.
+	_, err = flow_archive.RehydrateFlow(
+		self.ConfigObj, CLIENT_ID, FLOW_ID, "admin")
+	assert.NoError(self.T(), err)
+}
+
+func (self *FlowArchiveTestSuite) TestNotConfigured() {
+	self.ConfigObj.FlowArchive = nil
+
+	_, err := flow_archive.ArchiveFlow(
+		self.ConfigObj, CLIENT_ID, FLOW_ID, "admin")
+	assert.True(self.T(), errors.Is(err, flow_archive.NotConfiguredError))
+}
+
+func TestFlowArchive(t *testing.T) {
+	suite.Run(t, &FlowArchiveTestSuite{})
+}
@@ -155,6 +155,28 @@ func updateContext(
 		ping_record, nil)
 }
 
+// Record when the client received the requests for its flows. The
+// client's timeout starts from here so a flow which is not heard from
+// long after this is stuck.
+func MarkFlowsDelivered(
+	config_obj *config_proto.Config,
+	client_id string, tasks []*crypto_proto.VeloMessage) {
+
+	seen := make(map[string]bool)
+	for _, task := range tasks {
+		if task.VQLClientAction == nil || seen[task.SessionId] {
+			continue
+		}
+		seen[task.SessionId] = true
+
+		err := updateContext(config_obj, client_id, task.SessionId)
+		if err != nil {
+			logger := logging.GetLogger(config_obj, &logging.FrontendComponent)
+			logger.Error("MarkFlowsDelivered: %v", err)
+		}
+	}
+}
+
 // closeContext is called after all messages from the clients are
 // processed in this group. Client messages are sent in groups inside
 // the same POST request. Most of the time they belong to the same
@@ -292,7 +314,8 @@ func flushContextUploadedFiles(
 			Set("started", time.Now().UTC().String()).
 			Set("vfs_path", row.Name).
 			Set("file_size", row.Size).
-			Set("uploaded_size", row.StoredSize))
+			Set("uploaded_size", row.StoredSize).
+			Set("content_type", row.ContentType))
 	}
 
 	// Clear the logs from the flow object.
@@ -473,6 +496,11 @@ func CheckForStatus(
 	collection_context.ActiveTime = uint64(time.Now().UnixNano() / 1000)
 	collection_context.ExecutionDuration += message.Status.Duration
 
+	// Older clients do not send their environment.
+	if message.Status.Environment != nil {
+		collection_context.ClientEnvironment = message.Status.Environment
+	}
+
 	// Each status message decreases outstanding_requests by one -
 	// when we hit 0 we can mark the flow as finished.
 	collection_context.OutstandingRequests--
@@ -541,10 +569,11 @@ func appendUploadDataToFile(
 		collection_context.UploadedFiles = append(
 			collection_context.UploadedFiles,
 			&flows_proto.ArtifactUploadedFileInfo{
-				Name:       file_path_manager.Path().AsClientPath(),
-				Components: file_path_manager.Path().Components(),
-				Size:       file_buffer.Size,
-				StoredSize: size,
+				Name:        file_path_manager.Path().AsClientPath(),
+				Components:  file_path_manager.Path().Components(),
+				Size:        file_buffer.Size,
+				StoredSize:  size,
+				ContentType: utils.DetectContentType(file_buffer.Data),
 			})
 		collection_context.Dirty = true
 	}
@@ -589,8 +618,9 @@ func appendUploadDataToFile(
 					AsClientPath(),
 				Components: file_path_manager.IndexPath().
 					Components(),
-				Size:       uint64(len(data)),
-				StoredSize: uint64(len(data)),
+				Size:        uint64(len(data)),
+				StoredSize:  uint64(len(data)),
+				ContentType: "application/json",
 			})
 		collection_context.Dirty = true
 	}
@@ -750,6 +780,10 @@ func (self *FlowRunner) ProcessSingleMessage(
 		return
 	}
 
+	// Account for the bandwidth used by the collection.
+	collection_context.TotalPayloadBytes += job.PayloadSize
+	collection_context.TotalWireBytes += job.WireSize
+
 	if job.LogMessage != nil {
 		LogMessage(self.config_obj, collection_context, job.LogMessage)
 		return
@@ -416,6 +416,9 @@ func (self *TestSuite) TestClientUploaderStoreFile() {
 	uploaded_size, _ := upload_metadata_rows[0].GetInt64("uploaded_size")
 	assert.Equal(self.T(), uploaded_size, int64(12))
 
+	content_type, _ := upload_metadata_rows[0].GetString("content_type")
+	assert.Equal(self.T(), content_type, "text/plain; charset=utf-8")
+
 	// Check the System.Upload.Completion event.
 	artifact_path_manager, err := artifacts.NewArtifactPathManager(
 		self.ConfigObj, self.client_id, self.flow_id,
@@ -517,6 +520,36 @@ func (self *TestSuite) TestCollectionCompletionOkStatus() {
 		flow.ArtifactsWithResults)
 }
 
+// The client's environment is recorded in the collection context.
+func (self *TestSuite) TestCollectionCompletionClientEnvironment() {
+	flow := self.testCollectionCompletion(1, []*crypto_proto.VeloMessage{
+		{
+			SessionId: self.flow_id,
+			RequestId: 1,
+			Status: &crypto_proto.VeloStatus{
+				Status:   crypto_proto.VeloStatus_OK,
+				Duration: 100,
+				Environment: &crypto_proto.ClientEnvironment{
+					ClientVersion:   "0.6.5",
+					Os:              "windows",
+					PlatformVersion: "10.0.19044 Build 19044",
+					Uptime:          3600,
+					User:            "NT AUTHORITY\\SYSTEM",
+				},
+			},
+		},
+	})
+
+	assert.Equal(self.T(), flows_proto.ArtifactCollectorContext_FINISHED,
+		flow.State)
+	assert.NotNil(self.T(), flow.ClientEnvironment)
+	assert.Equal(self.T(), "0.6.5", flow.ClientEnvironment.ClientVersion)
+	assert.Equal(self.T(), "10.0.19044 Build 19044",
+		flow.ClientEnvironment.PlatformVersion)
+	assert.Equal(self.T(), uint64(3600), flow.ClientEnvironment.Uptime)
+	assert.Equal(self.T(), "NT AUTHORITY\\SYSTEM", flow.ClientEnvironment.User)
+}
+
 // Emulate an artifact with 2 sources - both responses come one after
 // the other but second query failed - this means the entire
 // collection is failed but we get all the results.
@@ -0,0 +1,503 @@
+package flows
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	actions_proto "www.velocidex.com/golang/velociraptor/actions/proto"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/datastore"
+	"www.velocidex.com/golang/velociraptor/file_store"
+	"www.velocidex.com/golang/velociraptor/file_store/api"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/json"
+	"www.velocidex.com/golang/velociraptor/paths"
+	artifact_paths "www.velocidex.com/golang/velociraptor/paths/artifacts"
+	"www.velocidex.com/golang/velociraptor/reporting"
+	"www.velocidex.com/golang/velociraptor/result_sets"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/services/launcher"
+	"www.velocidex.com/golang/velociraptor/utils"
+)
+
+type ImportCollectionOptions struct {
+	// The client id to import into. If empty (or "auto") we use
+	// the container metadata or the hostname to find a matching
+	// client, or create a new one.
+	ClientId string
+
+	// When creating a new client, use this as the hostname. If
+	// not set we use the hostname recorded in the container.
+	Hostname string
+
+	// The user doing the import.
+	Creator string
+
+	// The tool which produced the archive (e.g. kape or cylr). By
+	// default we expect a Velociraptor offline collection.
+	Format string
+
+	// Messages are logged here as well as into the flow log.
+	Log func(format string, args ...interface{})
+}
+
+// Import an offline collector container (or a third party triage
+// archive) into the server as a new flow so it can be treated like
+// any other collection.
+func ImportCollection(
+	ctx context.Context,
+	config_obj *config_proto.Config,
+	zipfile *zip.Reader,
+	filename string,
+	options ImportCollectionOptions) (*flows_proto.ArtifactCollectorContext, error) {
+
+	if options.Log == nil {
+		options.Log = func(format string, args ...interface{}) {}
+	}
+
+	layout, err := getTriageLayout(options.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	// Older collectors do not write the metadata so it is optional.
+	metadata, err := readContainerMetadata(zipfile)
+	if err != nil {
+		options.Log("Unable to read container metadata: %v", err)
+		metadata = &reporting.ContainerMetadata{}
+	}
+
+	if options.Hostname == "" {
+		options.Hostname = metadata.Hostname
+	}
+
+	client_id := options.ClientId
+	if client_id == "" || client_id == "auto" {
+		client_id, err = getExistingClientOrNewClient(ctx, config_obj,
+			metadata.ClientId, options.Hostname, options.Log)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	indexer, err := services.GetIndexer(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	api_client, err := indexer.FastGetApiClient(ctx, config_obj, client_id)
+	if err != nil || api_client.AgentInformation == nil ||
+		api_client.AgentInformation.Name == "" {
+		return nil, errors.New("client_id not known")
+	}
+
+	file_store_factory := file_store.GetFileStore(config_obj)
+	manager, err := services.GetRepositoryManager(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	repository, err := manager.GetGlobalRepository(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep track of all the artifacts in the zip file.
+	artifacts := make(map[string]bool)
+
+	// Create a new flow and path manager for it.
+	flow_id := launcher.NewFlowId(client_id)
+	path_manager := paths.NewFlowPathManager(client_id, flow_id)
+	new_flow := &flows_proto.ArtifactCollectorContext{
+		SessionId: flow_id,
+		ClientId:  client_id,
+		Request: &flows_proto.ArtifactCollectorArgs{
+			Creator:  options.Creator,
+			ClientId: client_id,
+		},
+		CreateTime: uint64(time.Now().UnixNano() / 1000),
+		State:      flows_proto.ArtifactCollectorContext_FINISHED,
+	}
+
+	// Preserve the time the collection was actually made.
+	if metadata.Timestamp > 0 {
+		new_flow.StartTime = uint64(metadata.Timestamp * 1000000)
+	}
+
+	uploaded_files_result_set, err := result_sets.NewResultSetWriter(
+		file_store_factory, path_manager.UploadMetadata(),
+		nil, utils.SyncCompleter, true /* truncate */)
+	if err != nil {
+		return nil, err
+	}
+	defer uploaded_files_result_set.Close()
+
+	log_result_set, err := result_sets.NewResultSetWriter(
+		file_store_factory, path_manager.Log(),
+		nil, utils.SyncCompleter, true /* truncate */)
+	if err != nil {
+		return nil, err
+	}
+	defer log_result_set.Close()
+
+	// A log function that stores messages in the flow log as well
+	// as passing them to the caller.
+	log := func(format string, args ...interface{}) {
+		now := time.Now().UTC()
+		log_result_set.Write(ordereddict.NewDict().
+			Set("Timestamp", fmt.Sprintf("%v", now)).
+			Set("time", time.Unix(int64(now.UnixNano())/1000000, 0).String()).
+			Set("message", fmt.Sprintf(format, args...)))
+
+		options.Log(format, args...)
+	}
+
+	log("Importing zip file %v into client id %v", filename, client_id)
+
+	// Files from triage archives are listed in this result set.
+	var triage_result_set result_sets.ResultSetWriter
+	if layout != nil {
+		artifact_path_manager, err := artifact_paths.NewArtifactPathManager(
+			config_obj, client_id, flow_id, triageSource)
+		if err != nil {
+			return nil, err
+		}
+
+		triage_result_set, err = result_sets.NewResultSetWriter(
+			file_store_factory, artifact_path_manager.Path(),
+			nil, utils.SyncCompleter, true /* truncate */)
+		if err != nil {
+			return nil, err
+		}
+		defer triage_result_set.Close()
+
+		artifacts[triageArtifact] = true
+		new_flow.ArtifactsWithResults = append(
+			new_flow.ArtifactsWithResults, triageSource)
+	}
+
+	for _, file := range zipfile.File {
+		if file.Mode().IsDir() || file.Name == reporting.CONTAINER_METADATA {
+			continue
+		}
+
+		log("Filename %v", file.Name)
+
+		if layout != nil {
+			// Members which are not collected files (e.g. the
+			// tool's logs) are still kept under their own name.
+			source := layout(file.Name)
+			if source == "" {
+				source = file.Name
+			}
+
+			out_path := path_manager.GetUploadsFile("auto", source).Path()
+			content_type, err := copyZipMember(
+				ctx, file_store_factory, file, out_path)
+			if err != nil {
+				log("Error copying %v: %v", file.Name, err)
+				continue
+			}
+
+			new_flow.TotalUploadedFiles++
+			new_flow.TotalUploadedBytes += file.UncompressedSize64
+			new_flow.TotalCollectedRows++
+
+			uploaded_files_result_set.Write(ordereddict.NewDict().
+				Set("Timestamp", time.Now().UTC().Unix()).
+				Set("started", time.Now().UTC().String()).
+				Set("vfs_path", out_path).
+				Set("file_size", file.UncompressedSize64).
+				Set("uploaded_size", file.UncompressedSize64).
+				Set("content_type", content_type))
+
+			triage_result_set.Write(ordereddict.NewDict().
+				Set("CopiedOnTimestamp", time.Now().UTC()).
+				Set("SourceFile", source).
+				Set("DestinationFile", out_path.AsClientPath()).
+				Set("FileSize", file.UncompressedSize64).
+				Set("Modified", file.Modified.UTC()))
+			continue
+		}
+
+		// Files can be either an artifact or an upload
+		artifact_name := strings.TrimSuffix(file.Name, ".json")
+		artifact, pres := repository.Get(config_obj, artifact_name)
+		if pres {
+			// File is an artifact result set - import it
+			// into the filestore. artifact_name is the
+			// full name include source of the artifact so
+			// we need to dedup here.
+			artifacts[artifact.Name] = true
+
+			new_flow.ArtifactsWithResults = append(new_flow.ArtifactsWithResults,
+				artifact_name)
+
+			func() {
+				// Now copy the artifact results over.
+				fd, err := file.Open()
+				if err != nil {
+					log("Error copying %v", err)
+					return
+				}
+				defer fd.Close()
+
+				artifact_path_manager, err := artifact_paths.
+					NewArtifactPathManager(
+						config_obj, client_id, flow_id, artifact_name)
+				if err != nil {
+					log("Error copying %v", err)
+					return
+				}
+
+				rs_writer, err := result_sets.NewResultSetWriter(
+					file_store_factory,
+					artifact_path_manager.Path(),
+					nil, utils.SyncCompleter, true /* truncate */)
+				if err != nil {
+					log("Error copying %v", err)
+					return
+				}
+				defer rs_writer.Close()
+
+				// Now copy the rows from the zip to the filestore.
+				count := 0
+				for row := range utils.ReadJsonFromFile(ctx, fd) {
+					new_flow.TotalCollectedRows++
+					rs_writer.Write(row)
+					count++
+				}
+
+				log("Imported %v rows", count)
+			}()
+		} else {
+			new_flow.TotalUploadedFiles++
+			new_flow.TotalUploadedBytes += file.UncompressedSize64
+
+			now := time.Now()
+			out_path := path_manager.GetUploadsFile("file", file.Name).Path()
+			log("Copying file %v -> %v", file.Name, out_path.AsClientPath())
+
+			content_type, err := copyZipMember(
+				ctx, file_store_factory, file, out_path)
+			if err != nil {
+				log("Error copying %v", err)
+				continue
+			}
+
+			uploaded_files_result_set.Write(ordereddict.NewDict().
+				Set("Timestamp", now.UTC().Unix()).
+				Set("started", now.UTC().String()).
+				Set("vfs_path", out_path).
+				Set("file_size", file.UncompressedSize64).
+				Set("uploaded_size", file.UncompressedSize64).
+				Set("content_type", content_type))
+		}
+	}
+
+	// Copy all unique artifacts to the request struct - this will
+	// go into the flow context.
+	for k := range artifacts {
+		new_flow.Request.Artifacts = append(new_flow.Request.Artifacts, k)
+	}
+
+	return new_flow, storeNewFlow(config_obj, new_flow)
+}
+
+// Store a flow which was not collected from the client (e.g. an
+// import) and announce it like any other completed flow.
+func storeNewFlow(
+	config_obj *config_proto.Config,
+	new_flow *flows_proto.ArtifactCollectorContext) error {
+	db, err := datastore.GetDB(config_obj)
+	if err != nil {
+		return err
+	}
+
+	path_manager := paths.NewFlowPathManager(
+		new_flow.ClientId, new_flow.SessionId)
+	err = db.SetSubject(config_obj, path_manager.Path(), new_flow)
+	if err != nil {
+		return err
+	}
+
+	// Write an empty request so we can show something in the GUI
+	err = db.SetSubject(config_obj, path_manager.Task(),
+		&api_proto.ApiFlowRequestDetails{})
+	if err != nil {
+		return err
+	}
+
+	// Generate a fake System.Flow.Completion event for the
+	// uploaded flow in case there are any listeners who are
+	// interested.
+	journal, err := services.GetJournal(config_obj)
+	if err != nil {
+		return err
+	}
+
+	row := ordereddict.NewDict().
+		Set("Timestamp", time.Now().UTC().Unix()).
+		Set("Flow", new_flow).
+		Set("FlowId", new_flow.SessionId).
+		Set("ClientId", new_flow.ClientId)
+
+	return journal.PushRowsToArtifact(config_obj,
+		[]*ordereddict.Dict{row},
+		"System.Flow.Completion", new_flow.ClientId,
+		new_flow.SessionId,
+	)
+}
+
+// Copy the member into the file store and return its detected
+// content type.
+func copyZipMember(
+	ctx context.Context,
+	file_store_factory api.FileStore,
+	file *zip.File, out_path api.FSPathSpec) (string, error) {
+	fd, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer fd.Close()
+
+	out_fd, err := file_store_factory.WriteFile(out_path)
+	if err != nil {
+		return "", err
+	}
+	defer out_fd.Close()
+
+	reader := bufio.NewReaderSize(fd, 4096)
+	header, _ := reader.Peek(4096)
+	content_type := utils.DetectContentType(header)
+
+	_, err = utils.Copy(ctx, out_fd, reader)
+	return content_type, err
+}
+
+func readContainerMetadata(
+	zipfile *zip.Reader) (*reporting.ContainerMetadata, error) {
+	fd, err := zipfile.Open(reporting.CONTAINER_METADATA)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	serialized, err := ioutil.ReadAll(fd)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &reporting.ContainerMetadata{}
+	err = json.Unmarshal(serialized, result)
+	return result, err
+}
+
+// Generate a new client id
+func NewClientId() string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	dst := make([]byte, hex.EncodedLen(8))
+	hex.Encode(dst, buf)
+	return "C." + string(dst)
+}
+
+// Prefer the client id recorded in the container if the server
+// knows about it, then a client with the same hostname.
+func getExistingClientOrNewClient(
+	ctx context.Context,
+	config_obj *config_proto.Config,
+	client_id, hostname string,
+	log func(format string, args ...interface{})) (string, error) {
+
+	indexer, err := services.GetIndexer(config_obj)
+	if err != nil {
+		return "", err
+	}
+
+	if client_id != "" {
+		api_client, err := indexer.FastGetApiClient(ctx, config_obj, client_id)
+		if err == nil && api_client.AgentInformation != nil &&
+			api_client.AgentInformation.Name != "" {
+			log("client id found '%v'", client_id)
+			return client_id, nil
+		}
+	}
+
+	log("Searching for a client id with name '%v'", hostname)
+
+	// Search for an existing client with the same hostname
+	search_resp, err := indexer.SearchClients(ctx, config_obj,
+		&api_proto.SearchClientsRequest{Query: "host:" + hostname}, "")
+	if err == nil && len(search_resp.Items) > 0 {
+		client_id := search_resp.Items[0].ClientId
+		log("client id found '%v'", client_id)
+		return client_id, nil
+	}
+
+	return makeNewClient(config_obj, hostname, log)
+}
+
+// Create a new client record
+func makeNewClient(
+	config_obj *config_proto.Config,
+	hostname string,
+	log func(format string, args ...interface{})) (string, error) {
+
+	if hostname == "" {
+		return "", errors.New("New clients must have a hostname")
+	}
+
+	client_id := NewClientId()
+	client_info := &actions_proto.ClientInfo{
+		ClientId:     client_id,
+		Hostname:     hostname,
+		Fqdn:         hostname,
+		Architecture: "Offline",
+		ClientName:   "OfflineVelociraptor",
+	}
+
+	log("Creating new client '%v'", client_id)
+
+	db, err := datastore.GetDB(config_obj)
+	if err != nil {
+		return "", err
+	}
+
+	indexer, err := services.GetIndexer(config_obj)
+	if err != nil {
+		return "", err
+	}
+
+	client_path_manager := paths.NewClientPathManager(client_id)
+	err = db.SetSubject(config_obj,
+		client_path_manager.Path(), client_info)
+	if err != nil {
+		return "", err
+	}
+
+	// Add the new client to the index.
+	for _, term := range []string{
+		"all", // This is used for "." search
+		client_id,
+		"host:" + client_info.Fqdn,
+		"host:" + client_info.Hostname,
+	} {
+		err = indexer.SetIndex(client_id, term)
+		if err != nil {
+			return client_id, err
+		}
+	}
+
+	return client_id, nil
+}
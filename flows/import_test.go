@@ -0,0 +1,97 @@
+package flows
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+
+	"github.com/stretchr/testify/assert"
+	"www.velocidex.com/golang/velociraptor/json"
+	"www.velocidex.com/golang/velociraptor/reporting"
+)
+
+func (self *TestSuite) makeContainer(
+	metadata *reporting.ContainerMetadata) *zip.Reader {
+	buffer := &bytes.Buffer{}
+	zip_writer := zip.NewWriter(buffer)
+
+	members := map[string]string{
+		"Generic.Client.Profile.json": "{\"A\":1}\n{\"A\":2}\n",
+		"uploads/auto/C%3A/foo.txt":   "hello",
+	}
+	if metadata != nil {
+		members[reporting.CONTAINER_METADATA] = json.MustMarshalString(metadata)
+	}
+
+	for name, data := range members {
+		fd, err := zip_writer.Create(name)
+		assert.NoError(self.T(), err)
+		_, err = fd.Write([]byte(data))
+		assert.NoError(self.T(), err)
+	}
+	assert.NoError(self.T(), zip_writer.Close())
+
+	reader, err := zip.NewReader(
+		bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	assert.NoError(self.T(), err)
+
+	return reader
+}
+
+func (self *TestSuite) TestImportCollection() {
+	ctx := context.Background()
+
+	// Without metadata or a hostname we can not create a client.
+	_, err := ImportCollection(ctx, self.ConfigObj,
+		self.makeContainer(nil), "test.zip", ImportCollectionOptions{
+			ClientId: "auto",
+		})
+	assert.Error(self.T(), err)
+
+	// The hostname is taken from the container metadata.
+	container := self.makeContainer(&reporting.ContainerMetadata{
+		Hostname:  "OfflineHost",
+		Timestamp: 1620000000,
+	})
+
+	new_flow, err := ImportCollection(ctx, self.ConfigObj,
+		container, "test.zip", ImportCollectionOptions{})
+	assert.NoError(self.T(), err)
+
+	assert.Equal(self.T(), uint64(2), new_flow.TotalCollectedRows)
+	assert.Equal(self.T(), uint64(1), new_flow.TotalUploadedFiles)
+	assert.Equal(self.T(), uint64(1620000000000000), new_flow.StartTime)
+	assert.Equal(self.T(), []string{"Generic.Client.Profile"},
+		new_flow.Request.Artifacts)
+
+	// Importing again goes to the same client.
+	new_flow2, err := ImportCollection(ctx, self.ConfigObj,
+		container, "test.zip", ImportCollectionOptions{})
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), new_flow.ClientId, new_flow2.ClientId)
+	assert.NotEqual(self.T(), new_flow.SessionId, new_flow2.SessionId)
+}
+
+func (self *TestSuite) TestTriageLayouts() {
+	for _, tc := range []struct {
+		layout   triageLayout
+		name     string
+		expected string
+	}{
+		{kapeLayout, "C/Windows/System32/config/SAM",
+			"C:\\Windows\\System32\\config\\SAM"},
+		{kapeLayout, "2021-01-01_Host/C/Users/a/NTUSER.DAT",
+			"C:\\Users\\a\\NTUSER.DAT"},
+		{kapeLayout, "vss3/C/Windows/System32/config/SAM",
+			"\\\\?\\GLOBALROOT\\Device\\HarddiskVolumeShadowCopy3\\Windows\\System32\\config\\SAM"},
+		{kapeLayout, "2021-01-01_CopyLog.csv", ""},
+		{cylrLayout, "C/$MFT", "C:\\$MFT"},
+		{cylrLayout, "etc/passwd", "/etc/passwd"},
+		{grrLayout, "archive/C.1234/fs/os/C:/Windows/notepad.exe",
+			"C:\\Windows\\notepad.exe"},
+		{grrLayout, "archive/C.1234/fs/os/etc/passwd", "/etc/passwd"},
+		{grrLayout, "archive/MANIFEST", ""},
+	} {
+		assert.Equal(self.T(), tc.expected, tc.layout(tc.name), tc.name)
+	}
+}
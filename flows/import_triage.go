@@ -0,0 +1,130 @@
+package flows
+
+// Third party triage tools produce archives of raw files copied from
+// the endpoint. We import these as if they were collected by the
+// Generic.Collectors.File artifact so they can be processed with
+// notebooks and server VQL like any other collection.
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	IMPORT_FORMAT_VELOCIRAPTOR = "velociraptor"
+	IMPORT_FORMAT_KAPE         = "kape"
+	IMPORT_FORMAT_CYLR         = "cylr"
+	IMPORT_FORMAT_GRR          = "grr"
+
+	// Imported files are reported in this artifact's result set.
+	triageArtifact = "Generic.Collectors.File"
+	triageSource   = triageArtifact + "/Uploads"
+)
+
+var (
+	driveRegex = regexp.MustCompile("^[a-zA-Z]:?$")
+	vssRegex   = regexp.MustCompile("(?i)^vss([0-9]+)$")
+)
+
+// A triage layout maps the name of an archive member to the path
+// of the file on the original system. It returns "" for members
+// which are not collected files (e.g. the tool's own logs).
+type triageLayout func(name string) string
+
+func getTriageLayout(format string) (triageLayout, error) {
+	switch format {
+	case "", IMPORT_FORMAT_VELOCIRAPTOR:
+		return nil, nil
+
+	case IMPORT_FORMAT_KAPE:
+		return kapeLayout, nil
+
+	case IMPORT_FORMAT_CYLR:
+		return cylrLayout, nil
+
+	case IMPORT_FORMAT_GRR:
+		return grrLayout, nil
+	}
+
+	return nil, fmt.Errorf("Unsupported import format %v", format)
+}
+
+func splitMemberName(name string) []string {
+	result := []string{}
+	for _, c := range strings.Split(strings.ReplaceAll(name, "\\", "/"), "/") {
+		if c != "" {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+func windowsPath(drive string, components []string) string {
+	return strings.ToUpper(drive[:1]) + ":\\" + strings.Join(components, "\\")
+}
+
+// KAPE target output stores files under a directory named after the
+// drive letter (e.g. C/Windows/...) and volume shadow copies under
+// vssN/C/... The whole tree may be nested in a single directory
+// named after the collection.
+func kapeLayout(name string) string {
+	components := splitMemberName(name)
+
+	for i := 0; i < 2 && i < len(components)-1; i++ {
+		c := components[i]
+
+		if driveRegex.MatchString(c) {
+			return windowsPath(c, components[i+1:])
+		}
+
+		m := vssRegex.FindStringSubmatch(c)
+		if m != nil && i+2 < len(components) &&
+			driveRegex.MatchString(components[i+1]) {
+			return "\\\\?\\GLOBALROOT\\Device\\HarddiskVolumeShadowCopy" +
+				m[1] + "\\" + strings.Join(components[i+2:], "\\")
+		}
+	}
+
+	return ""
+}
+
+// CyLR stores Windows files under the drive letter and Unix files
+// relative to the root.
+func cylrLayout(name string) string {
+	components := splitMemberName(name)
+	if len(components) == 0 {
+		return ""
+	}
+
+	if len(components) > 1 && driveRegex.MatchString(components[0]) {
+		return windowsPath(components[0], components[1:])
+	}
+
+	return "/" + strings.Join(components, "/")
+}
+
+// GRR archives store files under <client id>/fs/<pathtype>/<path>
+func grrLayout(name string) string {
+	components := splitMemberName(name)
+
+	for i := 0; i+2 < len(components); i++ {
+		if components[i] != "fs" {
+			continue
+		}
+
+		switch components[i+1] {
+		case "os", "tsk", "ntfs", "registry":
+		default:
+			continue
+		}
+
+		path := components[i+2:]
+		if len(path) > 1 && driveRegex.MatchString(path[0]) {
+			return windowsPath(path[0], path[1:])
+		}
+		return "/" + strings.Join(path, "/")
+	}
+
+	return ""
+}
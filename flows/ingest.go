@@ -0,0 +1,182 @@
+package flows
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/file_store"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/paths"
+	artifact_paths "www.velocidex.com/golang/velociraptor/paths/artifacts"
+	"www.velocidex.com/golang/velociraptor/result_sets"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/services/launcher"
+	"www.velocidex.com/golang/velociraptor/utils"
+)
+
+type IngestRowsOptions struct {
+	// The client the rows are about. It must already exist.
+	ClientId string
+
+	// The artifact (with source if needed) the rows belong to.
+	Artifact string
+
+	// The user pushing the rows.
+	Creator string
+
+	// Messages are logged here as well as into the flow log.
+	Log func(format string, args ...interface{})
+}
+
+// Store line delimited JSON rows produced by an external tool (e.g. a
+// third party scanner) as a new flow so they can be analysed like
+// rows collected by Velociraptor itself.
+func IngestRows(
+	ctx context.Context,
+	config_obj *config_proto.Config,
+	reader io.Reader,
+	options IngestRowsOptions) (*flows_proto.ArtifactCollectorContext, error) {
+
+	if options.Log == nil {
+		options.Log = func(format string, args ...interface{}) {}
+	}
+
+	indexer, err := services.GetIndexer(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	api_client, err := indexer.FastGetApiClient(ctx, config_obj, options.ClientId)
+	if err != nil || api_client.AgentInformation == nil ||
+		api_client.AgentInformation.Name == "" {
+		return nil, errors.New("client_id not known")
+	}
+
+	manager, err := services.GetRepositoryManager(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	repository, err := manager.GetGlobalRepository(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	artifact, pres := repository.Get(config_obj, options.Artifact)
+	if !pres {
+		return nil, fmt.Errorf("Artifact %v not known", options.Artifact)
+	}
+
+	client_id := options.ClientId
+	flow_id := launcher.NewFlowId(client_id)
+	path_manager := paths.NewFlowPathManager(client_id, flow_id)
+	now := uint64(time.Now().UnixNano() / 1000)
+	new_flow := &flows_proto.ArtifactCollectorContext{
+		SessionId: flow_id,
+		ClientId:  client_id,
+		Request: &flows_proto.ArtifactCollectorArgs{
+			Creator:   options.Creator,
+			ClientId:  client_id,
+			Artifacts: []string{artifact.Name},
+		},
+		CreateTime:           now,
+		StartTime:            now,
+		State:                flows_proto.ArtifactCollectorContext_FINISHED,
+		ArtifactsWithResults: []string{options.Artifact},
+	}
+
+	file_store_factory := file_store.GetFileStore(config_obj)
+	log_result_set, err := result_sets.NewResultSetWriter(
+		file_store_factory, path_manager.Log(),
+		nil, utils.SyncCompleter, true /* truncate */)
+	if err != nil {
+		return nil, err
+	}
+	defer log_result_set.Close()
+
+	log := func(format string, args ...interface{}) {
+		now := time.Now().UTC()
+		log_result_set.Write(ordereddict.NewDict().
+			Set("Timestamp", fmt.Sprintf("%v", now)).
+			Set("time", time.Unix(int64(now.UnixNano())/1000000, 0).String()).
+			Set("message", fmt.Sprintf(format, args...)))
+
+		options.Log(format, args...)
+	}
+
+	artifact_path_manager, err := artifact_paths.NewArtifactPathManager(
+		config_obj, client_id, flow_id, options.Artifact)
+	if err != nil {
+		return nil, err
+	}
+
+	rs_writer, err := result_sets.NewResultSetWriter(
+		file_store_factory, artifact_path_manager.Path(),
+		nil, utils.SyncCompleter, true /* truncate */)
+	if err != nil {
+		return nil, err
+	}
+
+	log("Ingesting rows for %v from %v", options.Artifact, options.Creator)
+
+	malformed, err := ingestJsonl(ctx, reader, func(row *ordereddict.Dict) {
+		rs_writer.Write(row)
+		new_flow.TotalCollectedRows++
+	})
+	rs_writer.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if malformed > 0 {
+		log("Skipped %v malformed rows", malformed)
+	}
+	log("Ingested %v rows", new_flow.TotalCollectedRows)
+
+	new_flow.ActiveTime = uint64(time.Now().UnixNano() / 1000)
+
+	return new_flow, storeNewFlow(config_obj, new_flow)
+}
+
+// Parse each line of the reader as a row. Unlike
+// utils.ReadJsonFromFile, the last line does not need to end with a
+// newline and read errors are reported. Returns the number of lines
+// which could not be parsed.
+func ingestJsonl(ctx context.Context, reader io.Reader,
+	cb func(row *ordereddict.Dict)) (int, error) {
+	malformed := 0
+	buffered := bufio.NewReader(reader)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return malformed, ctx.Err()
+		default:
+		}
+
+		line, err := buffered.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return malformed, err
+		}
+
+		if len(bytes.TrimSpace(line)) > 0 {
+			item := ordereddict.NewDict()
+			if item.UnmarshalJSON(line) != nil {
+				malformed++
+			} else {
+				cb(item)
+			}
+		}
+
+		if err != nil {
+			return malformed, nil
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package flows
+
+import (
+	"context"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+	"www.velocidex.com/golang/velociraptor/file_store"
+	artifact_paths "www.velocidex.com/golang/velociraptor/paths/artifacts"
+	"www.velocidex.com/golang/velociraptor/result_sets"
+)
+
+func (self *TestSuite) TestIngestRows() {
+	ctx := context.Background()
+
+	client_id, err := getExistingClientOrNewClient(ctx, self.ConfigObj,
+		"", "ScannedHost", func(format string, args ...interface{}) {})
+	assert.NoError(self.T(), err)
+
+	// Malformed lines are skipped and the last line does not need a
+	// newline.
+	rows := "{\"A\":1}\nnot json\n\n{\"A\":2}\n{\"A\":3}"
+
+	new_flow, err := IngestRows(ctx, self.ConfigObj,
+		strings.NewReader(rows), IngestRowsOptions{
+			ClientId: client_id,
+			Artifact: "Generic.Client.Profile",
+			Creator:  "scanner",
+		})
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), uint64(3), new_flow.TotalCollectedRows)
+	assert.Equal(self.T(), []string{"Generic.Client.Profile"},
+		new_flow.ArtifactsWithResults)
+
+	path_manager, err := artifact_paths.NewArtifactPathManager(
+		self.ConfigObj, client_id, new_flow.SessionId,
+		"Generic.Client.Profile")
+	assert.NoError(self.T(), err)
+
+	reader, err := result_sets.NewResultSetReader(
+		file_store.GetFileStore(self.ConfigObj), path_manager.Path())
+	assert.NoError(self.T(), err)
+	defer reader.Close()
+	assert.Equal(self.T(), int64(3), reader.TotalRows())
+
+	// Rows can only be ingested for known clients and artifacts.
+	_, err = IngestRows(ctx, self.ConfigObj,
+		strings.NewReader(rows), IngestRowsOptions{
+			ClientId: client_id,
+			Artifact: "Unknown.Artifact",
+		})
+	assert.Error(self.T(), err)
+
+	_, err = IngestRows(ctx, self.ConfigObj,
+		strings.NewReader(rows), IngestRowsOptions{
+			ClientId: "C.1234",
+			Artifact: "Generic.Client.Profile",
+		})
+	assert.Error(self.T(), err)
+}
@@ -23,7 +23,10 @@ var (
 // protect the server from being overloaded.  This function checks
 // that the collection is still within the allowed quota, otherwise
 // the collection is terminated and the client is notified that it is
-// cancelled.
+// cancelled. A continuation is recorded so a collection truncated by
+// its row limit can be resumed by a new flow. Only the limits
+// enforced here record a continuation - the client's own limits do
+// not.
 func checkContextResourceLimits(config_obj *config_proto.Config,
 	collection_context *CollectionContext) (err error) {
 
@@ -41,6 +44,9 @@ func checkContextResourceLimits(config_obj *config_proto.Config,
 		collection_context.TotalCollectedRows > collection_context.Request.MaxRows {
 		collection_context.State = flows_proto.ArtifactCollectorContext_ERROR
 		collection_context.Status = "Row count exceeded limit"
+		collection_context.Continuation = &flows_proto.FlowContinuation{
+			FlowId: collection_context.SessionId,
+		}
 		err = cancelCollection(config_obj, collection_context.ClientId,
 			collection_context.SessionId)
 	}
@@ -50,6 +56,10 @@ func checkContextResourceLimits(config_obj *config_proto.Config,
 		collection_context.TotalUploadedBytes > collection_context.Request.MaxUploadBytes {
 		collection_context.State = flows_proto.ArtifactCollectorContext_ERROR
 		collection_context.Status = "Collection exceeded upload limits"
+		collection_context.Continuation = &flows_proto.FlowContinuation{
+			FlowId:              collection_context.SessionId,
+			UploadLimitExceeded: true,
+		}
 		err = cancelCollection(config_obj, collection_context.ClientId,
 			collection_context.SessionId)
 	}
@@ -82,6 +82,8 @@ func flushContextLogsMonitoring(
 
 	// Append logs to messages from previous packets.
 	file_store_factory := file_store.GetFileStore(config_obj)
+	client_id := services.GetMonitoringClientId(
+		config_obj, collection_context.ClientId)
 	for _, row := range collection_context.Logs {
 		artifact_name := row.Artifact
 		if artifact_name == "" {
@@ -92,7 +94,7 @@ func flushContextLogsMonitoring(
 		rs_writer, pres := writers[artifact_name]
 		if !pres {
 			log_path_manager, err := artifact_paths.NewArtifactLogPathManager(
-				config_obj, collection_context.ClientId,
+				config_obj, client_id,
 				collection_context.SessionId, artifact_name)
 			if err != nil {
 				return err
@@ -141,12 +143,17 @@ func flushMonitoringLogs(
 		return err
 	}
 
+	// Events from ephemeral clients are aggregated with their pool.
+	// The rows still carry the original ClientId.
+	client_id := services.GetMonitoringClientId(
+		config_obj, collection_context.ClientId)
+
 	for query_name, jsonl_buff := range collection_context.monitoring_batch {
 		err := journal.PushJsonlToArtifact(
 			config_obj,
 			jsonl_buff.Bytes(),
 			query_name,
-			collection_context.ClientId,
+			client_id,
 			collection_context.SessionId)
 		if err != nil {
 			return err
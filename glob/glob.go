@@ -21,9 +21,10 @@ import (
 	"context"
 	"fmt"
 	"regexp"
-	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"www.velocidex.com/golang/velociraptor/accessors"
 	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
@@ -68,19 +69,26 @@ func (self _RecursiveComponent) Match(f accessors.FileInfo) bool {
 }
 
 type _RegexComponent struct {
-	regexp   string
+	regexp string
+
+	// Compiled on first use - the tree may be walked by many workers.
+	once     sync.Once
 	compiled *regexp.Regexp
 }
 
 func (self *_RegexComponent) Match(f accessors.FileInfo) bool {
-	if self.compiled == nil {
+	return self.MatchName(f.Name())
+}
+
+func (self *_RegexComponent) MatchName(name string) bool {
+	self.once.Do(func() {
 		self.compiled = regexp.MustCompile("^(?msi)" + self.regexp)
-	}
+	})
 
-	return self.compiled.MatchString(f.Name())
+	return self.compiled.MatchString(name)
 }
 
-func (self _RegexComponent) String() string {
+func (self *_RegexComponent) String() string {
 	return "re:" + self.regexp
 }
 
@@ -105,6 +113,17 @@ type GlobOptions struct {
 
 	// Allow the user to control which directory we descend into.
 	RecursionCallback func(file_info accessors.FileInfo) bool
+
+	// Number of directories to list in parallel. The default of 1
+	// walks the filesystem in breadth first order.
+	Workers int
+
+	// Files and directories matching these globs are not reported
+	// and not descended into.
+	Exclusions []*accessors.OSPath
+
+	// If set, log progress at this interval.
+	ProgressInterval time.Duration
 }
 
 // A tree of filters - each filter branches to a subfilter.
@@ -181,58 +200,6 @@ func (self *Globber) _add_filter(components []_PathFilterer) error {
 	return nil
 }
 
-func (self *Globber) is_dir_or_link(
-	f accessors.FileInfo, accessor accessors.FileSystemAccessor, depth int) bool {
-	// Do not follow symlinks to symlinks deeply.
-	if depth > 10 {
-		return false
-	}
-
-	// Allow the callers to control our symlink behavior.
-	if self.options.RecursionCallback != nil &&
-		!self.options.RecursionCallback(f) {
-		return false
-	}
-
-	// If it is a link we need to determine if the target is a
-	// directory.
-	if f.IsLink() {
-		if self.options.DoNotFollowSymlinks {
-			return false
-		}
-
-		target, err := f.GetLink()
-		if err == nil {
-			target_info, err := accessor.Lstat(target.String())
-			if err == nil {
-				// Check if the target is on a different filesystem
-				// than the current file
-				if self.options.OneFilesystem {
-					current_dev, ok := DevOf(f)
-					if ok {
-						target_dev, ok := DevOf(target_info)
-						if ok && current_dev != target_dev {
-							return false
-						}
-					}
-				}
-
-				return self.is_dir_or_link(target_info, accessor, depth+1)
-			}
-
-			// Hmm we failed to lstat the target - assume
-			// it is a directory anyway.
-			return true
-		}
-	}
-
-	if f.IsDir() {
-		return true
-	}
-
-	return false
-}
-
 // Expands the component tree by traversing the filesystem. This
 // version uses a context to allow cancellation. We write the FileInfo
 // into the output channel.
@@ -247,87 +214,13 @@ func (self *Globber) ExpandWithContext(
 	go func() {
 		defer close(output_chan)
 
-		// We want to do a breadth first recursion - not a
-		// depth first recursion. This ensures that readers of
-		// the results can detect all the hits in a particular
-		// directory before processing its children.
-		children := make(map[string][]*Globber)
-
-		// Walk the filter tree. List the directory and for each file
-		// that matches a filter at this level, recurse into the next
-		// level.
-		files, err := accessor.ReadDirWithOSPath(root)
+		walker, err := newWalker(ctx, scope, self.options, accessor, output_chan)
 		if err != nil {
-			scope.Log("Globber: %v while processing %v",
-				err, root.String())
+			scope.Log("Globber: %v", err)
 			return
 		}
 
-		result := []accessors.FileInfo{}
-
-		// For each file that matched, we check which component
-		// would match it.
-		for _, f := range files {
-			for filterer, next := range self.filters {
-				if !filterer.Match(f) {
-					continue
-				}
-				if next == nil {
-					continue
-				}
-				_, next_has_sentinal := next.filters[sentinal_filter]
-				if next_has_sentinal {
-					result = append(result, f)
-				}
-
-				// Only recurse into directories.
-				if self.is_dir_or_link(f, accessor, 0) {
-					name := f.Name()
-					item := []*Globber{next}
-					prev_item, pres := children[name]
-					if pres {
-						item = append(prev_item, next)
-					}
-					children[name] = item
-				}
-			}
-		}
-
-		// Sort the results alphabetically.
-		sort.Slice(result, func(i, j int) bool {
-			return -1 == strings.Compare(
-				result[i].OSPath().Basename(),
-				result[j].OSPath().Basename())
-		})
-		for _, f := range result {
-			select {
-			case <-ctx.Done():
-				return
-
-			case output_chan <- f:
-			}
-		}
-
-		for name, nexts := range children {
-			next_path := root.Append(name)
-			for _, next := range nexts {
-				// There is no point expanding this
-				// node if it is just a sentinal -
-				// special case it for efficiency.
-				if is_sentinal(next) {
-					continue
-				}
-				for f := range next.ExpandWithContext(
-					ctx, scope, config_obj, next_path, accessor) {
-					select {
-					case <-ctx.Done():
-						return
-
-					case output_chan <- f:
-					}
-				}
-			}
-		}
+		walker.run(root, self)
 	}()
 
 	return output_chan
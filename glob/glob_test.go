@@ -21,11 +21,13 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"testing"
 
 	"www.velocidex.com/golang/velociraptor/accessors"
 	"www.velocidex.com/golang/velociraptor/json"
+	"www.velocidex.com/golang/velociraptor/utils"
 	"www.velocidex.com/golang/velociraptor/vtesting/assert"
 
 	"github.com/Velocidex/ordereddict"
@@ -200,3 +202,152 @@ func TestBraceExpansion(t *testing.T) {
 		assert.Equal(t, e, expected[idx])
 	}
 }
+
+func runGlob(t *testing.T, options GlobOptions,
+	accessor accessors.FileSystemAccessor, patterns ...string) []string {
+	globber := NewGlobber().WithOptions(options)
+	for _, pattern := range ExpandBraces(patterns) {
+		err := globber.Add(accessors.MustNewLinuxOSPath(pattern))
+		assert.NoError(t, err)
+	}
+
+	var returned []string
+	for row := range globber.ExpandWithContext(
+		context.Background(), vql_subsystem.MakeScope(),
+		config.GetDefaultConfig(),
+		accessors.MustNewLinuxOSPath("/"), accessor) {
+		returned = append(returned, row.FullPath())
+	}
+	return returned
+}
+
+// Parallel walking finds the same files, although in a different
+// order.
+func TestGlobWorkers(t *testing.T) {
+	fs_accessor := GetMockFileSystemAccessor()
+
+	for _, fixture := range _GlobFixture {
+		expected := runGlob(t, GlobOptions{}, fs_accessor, fixture.patterns...)
+		returned := runGlob(t, GlobOptions{Workers: 4}, fs_accessor,
+			fixture.patterns...)
+
+		sort.Strings(expected)
+		sort.Strings(returned)
+		assert.Equal(t, expected, returned, fixture.name)
+	}
+}
+
+func TestGlobExclusions(t *testing.T) {
+	fs_accessor := GetMockFileSystemAccessor()
+
+	returned := runGlob(t, GlobOptions{
+		Exclusions: []*accessors.OSPath{
+			accessors.MustNewLinuxOSPath("/usr/bin/x11"),
+			accessors.MustNewLinuxOSPath("/tmp/**/2*"),
+		},
+	}, fs_accessor, "/usr/**", "/tmp/**")
+
+	assert.Equal(t, []string{
+		"/tmp/1",
+		"/usr/bin",
+		"/usr/sbin",
+		"/tmp/1/1.txt",
+		"/tmp/1/3",
+		"/tmp/1/4",
+		"/tmp/1/5",
+		"/usr/bin/diff",
+		"/usr/sbin/X",
+	}, returned)
+}
+
+// A FileInfo for a symlink.
+type linkFileInfo struct {
+	*accessors.VirtualFileInfo
+	target *accessors.OSPath
+}
+
+func (self linkFileInfo) IsLink() bool {
+	return true
+}
+
+func (self linkFileInfo) GetLink() (*accessors.OSPath, error) {
+	return self.target, nil
+}
+
+// Add symlinks to an accessor.
+type linkAccessor struct {
+	accessors.FileSystemAccessor
+	links map[string]*accessors.OSPath
+}
+
+// Replace any links in the path with their targets.
+func (self linkAccessor) resolve(path *accessors.OSPath) *accessors.OSPath {
+	for i := len(path.Components); i > 0; i-- {
+		prefix := accessors.MustNewLinuxOSPath("")
+		prefix.Components = path.Components[:i]
+		target, pres := self.links[prefix.String()]
+		if pres {
+			result := target.Copy()
+			result.Components = append(result.Components,
+				path.Components[i:]...)
+			return self.resolve(result)
+		}
+	}
+	return path
+}
+
+func (self linkAccessor) ReadDirWithOSPath(
+	path *accessors.OSPath) ([]accessors.FileInfo, error) {
+	files, err := self.FileSystemAccessor.ReadDirWithOSPath(self.resolve(path))
+	if err != nil {
+		return nil, err
+	}
+
+	var result []accessors.FileInfo
+	for _, f := range files {
+		info := &accessors.VirtualFileInfo{
+			Path:   path.Append(f.Name()),
+			IsDir_: f.IsDir(),
+		}
+		target, pres := self.links[info.Path.String()]
+		if pres {
+			result = append(result, linkFileInfo{info, target})
+		} else {
+			result = append(result, info)
+		}
+	}
+	return result, nil
+}
+
+func (self linkAccessor) Lstat(path string) (accessors.FileInfo, error) {
+	return self.FileSystemAccessor.LstatWithOSPath(
+		self.resolve(accessors.MustNewLinuxOSPath(path)))
+}
+
+func TestGlobLinkLoop(t *testing.T) {
+	fs_accessor := GetMockFileSystemAccessor()
+	fs_accessor.(*accessors.VirtualFilesystemAccessor).SetVirtualFileInfo(
+		&accessors.VirtualFileInfo{
+			Path: accessors.MustNewLinuxOSPath("/tmp/1/2/21/loop"),
+		})
+
+	// A link back to a parent directory (like Windows junctions
+	// "Application Data" -> ".") and a link elsewhere which is
+	// followed.
+	link_accessor := linkAccessor{
+		FileSystemAccessor: fs_accessor,
+		links: map[string]*accessors.OSPath{
+			"/tmp/1/2/21/loop": accessors.MustNewLinuxOSPath("/tmp/1"),
+			"/tmp/1/4":         accessors.MustNewLinuxOSPath("/bin"),
+		},
+	}
+
+	returned := runGlob(t, GlobOptions{}, link_accessor, "/tmp/**/*")
+
+	// The loop is reported but not followed.
+	assert.True(t, utils.InString(returned, "/tmp/1/2/21/loop"))
+	assert.True(t, utils.InString(returned, "/tmp/1/4/bash"))
+	for _, path := range returned {
+		assert.True(t, !strings.Contains(path, "loop/"), path)
+	}
+}
@@ -0,0 +1,442 @@
+/*
+   Velociraptor - Hunting Evil
+   Copyright (C) 2019 Velocidex Innovations.
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+package glob
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"www.velocidex.com/golang/velociraptor/accessors"
+	"www.velocidex.com/golang/vfilter"
+)
+
+// A directory to list and the filters to apply to its contents.
+type walkItem struct {
+	path *accessors.OSPath
+
+	// The path of the directory after following any links. This is
+	// used to detect link loops.
+	real *accessors.OSPath
+
+	globbers []*Globber
+}
+
+// Walks the filesystem using a pool of workers. Directories are
+// processed in the order they are discovered, and a directory's
+// matches are always emitted before any of its children are
+// listed. With a single worker the output is in breadth first order.
+type walker struct {
+	ctx         context.Context
+	scope       vfilter.Scope
+	options     GlobOptions
+	accessor    accessors.FileSystemAccessor
+	output_chan chan accessors.FileInfo
+
+	exclusions [][]_PathFilterer
+
+	mu    sync.Mutex
+	cond  *sync.Cond
+	queue []*walkItem
+
+	// Number of items queued or being processed. When this drops to
+	// 0 the walk is complete.
+	pending int
+
+	// The recursion callback runs VQL so we do not call it
+	// concurrently.
+	callback_mu sync.Mutex
+
+	// Progress stats
+	dirs    int64
+	files   int64
+	matches int64
+}
+
+func newWalker(ctx context.Context, scope vfilter.Scope,
+	options GlobOptions, accessor accessors.FileSystemAccessor,
+	output_chan chan accessors.FileInfo) (*walker, error) {
+	result := &walker{
+		ctx:         ctx,
+		scope:       scope,
+		options:     options,
+		accessor:    accessor,
+		output_chan: output_chan,
+	}
+	result.cond = sync.NewCond(&result.mu)
+
+	for _, exclusion := range options.Exclusions {
+		filter, err := convert_glob_into_path_components(exclusion)
+		if err != nil {
+			return nil, err
+		}
+		result.exclusions = append(result.exclusions, filter)
+	}
+
+	return result, nil
+}
+
+func (self *walker) run(root *accessors.OSPath, globber *Globber) {
+	self.push([]*walkItem{{
+		path:     root,
+		real:     root,
+		globbers: []*Globber{globber},
+	}})
+
+	sub_ctx, cancel := context.WithCancel(self.ctx)
+	defer cancel()
+
+	// Wake up idle workers when we are cancelled.
+	go func() {
+		<-sub_ctx.Done()
+		self.mu.Lock()
+		self.cond.Broadcast()
+		self.mu.Unlock()
+	}()
+
+	if self.options.ProgressInterval > 0 {
+		go self.reportProgress(sub_ctx)
+	}
+
+	workers := self.options.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				item := self.pop()
+				if item == nil {
+					return
+				}
+				self.process(item)
+				self.done()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (self *walker) push(items []*walkItem) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	self.queue = append(self.queue, items...)
+	self.pending += len(items)
+	self.cond.Broadcast()
+}
+
+// Wait for the next directory to process. Returns nil when there is
+// no more work.
+func (self *walker) pop() *walkItem {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	for {
+		if self.ctx.Err() != nil || self.pending == 0 {
+			return nil
+		}
+
+		if len(self.queue) > 0 {
+			item := self.queue[0]
+			self.queue[0] = nil
+			self.queue = self.queue[1:]
+			return item
+		}
+
+		self.cond.Wait()
+	}
+}
+
+func (self *walker) done() {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	self.pending--
+	if self.pending == 0 {
+		self.cond.Broadcast()
+	}
+}
+
+func (self *walker) reportProgress(ctx context.Context) {
+	start := time.Now()
+	ticker := time.NewTicker(self.options.ProgressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			self.mu.Lock()
+			queued := len(self.queue)
+			self.mu.Unlock()
+
+			self.scope.Log("glob: Progress after %v: Examined %v files in %v directories, %v matches, %v directories queued",
+				time.Since(start).Round(time.Second),
+				atomic.LoadInt64(&self.files),
+				atomic.LoadInt64(&self.dirs),
+				atomic.LoadInt64(&self.matches), queued)
+		}
+	}
+}
+
+// List the directory and for each file that matches a filter at this
+// level, queue the directory for the next level.
+func (self *walker) process(item *walkItem) {
+	files, err := self.accessor.ReadDirWithOSPath(item.path)
+	if err != nil {
+		self.scope.Log("Globber: %v while processing %v",
+			err, item.path.String())
+		return
+	}
+
+	atomic.AddInt64(&self.dirs, 1)
+	atomic.AddInt64(&self.files, int64(len(files)))
+
+	result := []accessors.FileInfo{}
+	children := make(map[string]*walkItem)
+
+	for _, f := range files {
+		if self.isExcluded(item.path, f.Name()) {
+			continue
+		}
+
+		// Each file is reported at most once even if several
+		// patterns match it.
+		matched := false
+		var nexts []*Globber
+
+		for _, globber := range item.globbers {
+			for filterer, next := range globber.filters {
+				if next == nil || !filterer.Match(f) {
+					continue
+				}
+
+				_, next_has_sentinal := next.filters[sentinal_filter]
+				if next_has_sentinal {
+					matched = true
+				}
+
+				// There is no point expanding this node if it is
+				// just a sentinal.
+				if !is_sentinal(next) {
+					nexts = append(nexts, next)
+				}
+			}
+		}
+
+		if matched {
+			result = append(result, f)
+		}
+
+		if len(nexts) == 0 {
+			continue
+		}
+
+		// Only recurse into directories.
+		target, is_dir := self.is_dir_or_link(f, 0)
+		if !is_dir {
+			continue
+		}
+
+		real := item.real.Append(f.Name())
+		if target != nil {
+			// A link to one of our parents (e.g. a junction like
+			// "Application Data") would recurse forever.
+			if isParentOf(target, item.real) {
+				self.scope.Log("glob: Skipping link loop %v -> %v",
+					item.path.Append(f.Name()).String(), target.String())
+				continue
+			}
+			real = target
+		}
+
+		name := f.Name()
+		child, pres := children[name]
+		if !pres {
+			child = &walkItem{
+				path: item.path.Append(name),
+				real: real,
+			}
+			children[name] = child
+		}
+		child.globbers = append(child.globbers, nexts...)
+	}
+
+	// Sort the results alphabetically.
+	sort.Slice(result, func(i, j int) bool {
+		return -1 == strings.Compare(
+			result[i].OSPath().Basename(),
+			result[j].OSPath().Basename())
+	})
+	for _, f := range result {
+		select {
+		case <-self.ctx.Done():
+			return
+
+		case self.output_chan <- f:
+			atomic.AddInt64(&self.matches, 1)
+		}
+	}
+
+	// Only queue the children after the matches in this directory
+	// are sent.
+	names := make([]string, 0, len(children))
+	for name := range children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	items := make([]*walkItem, 0, len(names))
+	for _, name := range names {
+		items = append(items, children[name])
+	}
+	self.push(items)
+}
+
+// Determine if the file is a directory we should recurse into. If
+// the file is a link we also return the link target.
+func (self *walker) is_dir_or_link(
+	f accessors.FileInfo, depth int) (*accessors.OSPath, bool) {
+	// Do not follow symlinks to symlinks deeply.
+	if depth > 10 {
+		return nil, false
+	}
+
+	// Allow the callers to control our symlink behavior.
+	if self.options.RecursionCallback != nil {
+		self.callback_mu.Lock()
+		recurse := self.options.RecursionCallback(f)
+		self.callback_mu.Unlock()
+
+		if !recurse {
+			return nil, false
+		}
+	}
+
+	// If it is a link we need to determine if the target is a
+	// directory.
+	if f.IsLink() {
+		if self.options.DoNotFollowSymlinks {
+			return nil, false
+		}
+
+		target, err := f.GetLink()
+		if err == nil {
+			target_info, err := self.accessor.Lstat(target.String())
+			if err == nil {
+				// Check if the target is on a different filesystem
+				// than the current file
+				if self.options.OneFilesystem {
+					current_dev, ok := DevOf(f)
+					if ok {
+						target_dev, ok := DevOf(target_info)
+						if ok && current_dev != target_dev {
+							return nil, false
+						}
+					}
+				}
+
+				next_target, is_dir := self.is_dir_or_link(target_info, depth+1)
+				if next_target != nil {
+					target = next_target
+				}
+				return target, is_dir
+			}
+
+			// Hmm we failed to lstat the target - assume
+			// it is a directory anyway.
+			return target, true
+		}
+	}
+
+	return nil, f.IsDir()
+}
+
+func (self *walker) isExcluded(dir *accessors.OSPath, name string) bool {
+	if len(self.exclusions) == 0 {
+		return false
+	}
+
+	components := append(append([]string{}, dir.Components...), name)
+	for _, exclusion := range self.exclusions {
+		if matchComponents(exclusion, components) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match a path against the filters from a glob.
+func matchComponents(filters []_PathFilterer, components []string) bool {
+	if len(filters) == 0 {
+		return len(components) == 0
+	}
+
+	switch t := filters[0].(type) {
+	case _RecursiveComponent:
+		// Matches between 0 and depth components.
+		re := &_RegexComponent{regexp: t.path}
+		for i := 0; i <= t.depth && i <= len(components); i++ {
+			if matchComponents(filters[1:], components[i:]) {
+				return true
+			}
+
+			if i < len(components) && !re.MatchName(components[i]) {
+				return false
+			}
+		}
+		return false
+
+	case _LiteralComponent:
+		return len(components) > 0 &&
+			strings.EqualFold(t.path, components[0]) &&
+			matchComponents(filters[1:], components[1:])
+
+	case *_RegexComponent:
+		return len(components) > 0 &&
+			t.MatchName(components[0]) &&
+			matchComponents(filters[1:], components[1:])
+	}
+
+	return false
+}
+
+// Is parent the same as, or a parent of, path?
+func isParentOf(parent, path *accessors.OSPath) bool {
+	if len(parent.Components) > len(path.Components) {
+		return false
+	}
+
+	for i, component := range parent.Components {
+		if !strings.EqualFold(component, path.Components[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -79,6 +79,27 @@ class FlowInspector extends React.Component {
                 return;
             };
 
+            // Archived flows need to be restored before we can see
+            // their results.
+            let context = response.data && response.data.context;
+            if (context && context.archive_location) {
+                this.rehydrateFlow();
+                return;
+            }
+
+            this.setState({detailed_flow: response.data, loading: false});
+        });
+    }
+
+    rehydrateFlow = () => {
+        api.post("v1/RehydrateFlow", {
+            flow_id: this.props.flow.session_id,
+            client_id: this.props.flow.client_id,
+        }, this.source.token).then((response) => {
+            if (response.cancel) {
+                return;
+            };
+
             this.setState({detailed_flow: response.data, loading: false});
         });
     }
@@ -566,6 +566,10 @@ type NotificationReader struct {
 	// in the ring buffer if there is room.
 	IsPaused int32
 
+	// The compression the server would like us to use. The sender
+	// and the receiver share it.
+	compression *compressionPolicy
+
 	// A callback that will be notified when the reader
 	// completes. In the real client this is a fatal error since
 	// without notification comms the client is unreachable. In
@@ -605,6 +609,7 @@ func NewNotificationReader(
 		maxPoll:               time.Duration(config_obj.Client.MaxPoll) * time.Second,
 		maxPollDev:            maxPollDev,
 		current_poll_duration: time.Second,
+		compression:           &compressionPolicy{},
 		on_exit:               on_exit,
 		clock:                 clock,
 	}
@@ -614,11 +619,12 @@ func NewNotificationReader(
 // the server.
 func (self *NotificationReader) sendMessageList(
 	ctx context.Context, message_list [][]byte,
+	compressions []crypto_proto.PackedMessageList_CompressionType,
 	urgent bool) {
 
 	for {
 		if atomic.LoadInt32(&self.IsPaused) == 0 {
-			err := self.sendToURL(ctx, message_list, urgent)
+			err := self.sendToURL(ctx, message_list, compressions, urgent)
 			// Success!
 			if err == nil {
 				return
@@ -667,6 +673,7 @@ func (self *NotificationReader) sendMessageList(
 func (self *NotificationReader) sendToURL(
 	ctx context.Context,
 	message_list [][]byte,
+	compressions []crypto_proto.PackedMessageList_CompressionType,
 	urgent bool) (err error) {
 
 	if self.connector.ServerName() == "" {
@@ -675,11 +682,17 @@ func (self *NotificationReader) sendToURL(
 
 	self.logger.Info("%s: Connected to %s", self.name,
 		self.connector.GetCurrentUrl(self.handler))
-	// Clients always compress messages to the server.
-	cipher_text, err := self.manager.Encrypt(
-		message_list,
-		crypto_proto.PackedMessageList_ZCOMPRESSION,
-		self.connector.ServerName())
+	// Without a policy from the server all messages are compressed.
+	var cipher_text []byte
+	if compressions == nil {
+		cipher_text, err = self.manager.Encrypt(
+			message_list,
+			crypto_proto.PackedMessageList_ZCOMPRESSION,
+			self.connector.ServerName())
+	} else {
+		cipher_text, err = self.manager.EncryptMessageLists(
+			message_list, compressions, self.connector.ServerName())
+	}
 	if err != nil {
 		return err
 	}
@@ -705,6 +718,10 @@ func (self *NotificationReader) sendToURL(
 		return err
 	}
 
+	if message_info.CompressionPolicy != nil {
+		self.compression.Set(message_info.CompressionPolicy)
+	}
+
 	return message_info.IterateJobs(ctx,
 		func(ctx context.Context, msg *crypto_proto.VeloMessage) {
 
@@ -757,10 +774,12 @@ func (self *NotificationReader) Start(
 			message_list := self.GetMessageList()
 			serialized_message_list, err := proto.Marshal(message_list)
 			if err == nil {
-				compressed, err := utils.Compress(serialized_message_list)
+				compressed, compression, err := compressMessageList(
+					self.compression.Get(), serialized_message_list)
 				if err == nil {
-					self.sendMessageList(
-						ctx, [][]byte{compressed}, false)
+					self.sendMessageList(ctx, [][]byte{compressed},
+						[]crypto_proto.PackedMessageList_CompressionType{
+							compression}, false)
 				}
 			}
 
@@ -876,6 +895,11 @@ func NewHTTPCommunicator(
 		return nil, err
 	}
 
+	receiver := NewNotificationReader(
+		config_obj, connector, manager, executor, enroller,
+		logger, "Receiver", "reader", child_on_exit, clock)
+	receiver.compression = sender.compression
+
 	result := &HTTPCommunicator{
 		config_obj: config_obj,
 		logger:     logger,
@@ -886,11 +910,9 @@ func NewHTTPCommunicator(
 			logger:     logger,
 			clock:      clock,
 		},
-		on_exit: on_exit,
-		sender:  sender,
-		receiver: NewNotificationReader(
-			config_obj, connector, manager, executor, enroller,
-			logger, "Receiver", "reader", child_on_exit, clock),
+		on_exit:  on_exit,
+		sender:   sender,
+		receiver: receiver,
 	}
 
 	return result, nil
@@ -244,7 +244,7 @@ func (self *CommsTestSuite) TestEnrollment() {
 		{data: "", status: 406},
 	}
 
-	communicator.receiver.sendMessageList(context.Background(), nil, false)
+	communicator.receiver.sendMessageList(context.Background(), nil, nil, false)
 
 	checkResponses(self.T(), self.frontend1.events, []string{
 		// First request looks for server.pem but fails on frontend1
@@ -279,7 +279,7 @@ func (self *CommsTestSuite) TestServerError() {
 		{data: string(self.empty_response), status: 200},
 	}
 
-	communicator.receiver.sendMessageList(context.Background(), nil, false)
+	communicator.receiver.sendMessageList(context.Background(), nil, nil, false)
 
 	checkResponses(self.T(), self.frontend1.events, []string{
 		// First request looks for server.pem
@@ -332,7 +332,7 @@ func (self *CommsTestSuite) TestMultiFrontends() {
 		{data: string(self.empty_response), status: 200},
 	}
 
-	communicator.receiver.sendMessageList(context.Background(), nil, false)
+	communicator.receiver.sendMessageList(context.Background(), nil, nil, false)
 
 	// Message ordering is important
 	checkResponses(self.T(), self.frontend1.events, []string{
@@ -393,7 +393,7 @@ func (self *CommsTestSuite) TestMultiFrontendsAllIsBorked() {
 		{data: string(self.empty_response), status: 200},
 	}
 
-	communicator.receiver.sendMessageList(context.Background(), nil, false)
+	communicator.receiver.sendMessageList(context.Background(), nil, nil, false)
 
 	//utils.Debug(self.frontend1.events)
 	//utils.Debug(self.frontend2.events)
@@ -467,7 +467,7 @@ func (self *CommsTestSuite) TestMultiFrontendsIntermittantFailure() {
 		{data: string(self.empty_response), status: 200},
 	}
 
-	communicator.receiver.sendMessageList(context.Background(), nil, false)
+	communicator.receiver.sendMessageList(context.Background(), nil, nil, false)
 
 	//utils.Debug(self.frontend1.events)
 	//utils.Debug(self.frontend2.events)
@@ -527,7 +527,7 @@ func (self *CommsTestSuite) TestMultiFrontendsHeavyFailure() {
 		{data: "", status: 500},
 	}
 
-	communicator.receiver.sendMessageList(context.Background(), nil, false)
+	communicator.receiver.sendMessageList(context.Background(), nil, nil, false)
 
 	//utils.Debug(self.frontend1.events)
 	//utils.Debug(self.frontend2.events)
@@ -600,8 +600,8 @@ func (self *CommsTestSuite) TestMultiFrontendRedirect() {
 	}
 
 	// Request 2 packets.
-	communicator.receiver.sendMessageList(context.Background(), nil, false)
-	communicator.receiver.sendMessageList(context.Background(), nil, false)
+	communicator.receiver.sendMessageList(context.Background(), nil, nil, false)
+	communicator.receiver.sendMessageList(context.Background(), nil, nil, false)
 
 	//utils.Debug(self.frontend1.events)
 	//utils.Debug(self.frontend2.events)
@@ -670,8 +670,8 @@ func (self *CommsTestSuite) TestMultiFrontendRedirectWithErrors() {
 		{data: string(self.empty_response), status: 200},
 	}
 
-	communicator.receiver.sendMessageList(context.Background(), nil, false)
-	communicator.receiver.sendMessageList(context.Background(), nil, false)
+	communicator.receiver.sendMessageList(context.Background(), nil, nil, false)
+	communicator.receiver.sendMessageList(context.Background(), nil, nil, false)
 
 	//utils.Debug(self.frontend1.events)
 	//utils.Debug(self.frontend2.events)
@@ -757,7 +757,7 @@ func (self *CommsTestSuite) TestMultiRedirects() {
 		{data: string(self.empty_response), status: 200},
 	}
 
-	communicator.receiver.sendMessageList(context.Background(), nil, false)
+	communicator.receiver.sendMessageList(context.Background(), nil, nil, false)
 
 	//utils.Debug(self.frontend1.events)
 	//utils.Debug(self.frontend2.events)
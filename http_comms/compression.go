@@ -0,0 +1,49 @@
+package http_comms
+
+import (
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	crypto_proto "www.velocidex.com/golang/velociraptor/crypto/proto"
+	"www.velocidex.com/golang/velociraptor/utils"
+)
+
+// Holds the compression policy last advertised by the server. It is
+// shared between the sender and the receiver.
+type compressionPolicy struct {
+	mu     sync.Mutex
+	policy *crypto_proto.CompressionPolicy
+}
+
+func (self *compressionPolicy) Get() *crypto_proto.CompressionPolicy {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	return self.policy
+}
+
+func (self *compressionPolicy) Set(policy *crypto_proto.CompressionPolicy) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	if !proto.Equal(self.policy, policy) {
+		self.policy = proto.Clone(policy).(*crypto_proto.CompressionPolicy)
+	}
+}
+
+// Compress a serialized message list according to the policy. Until
+// the server advertises a policy we compress everything because
+// older servers expect all message lists to be compressed.
+func compressMessageList(
+	policy *crypto_proto.CompressionPolicy, message_list []byte) (
+	[]byte, crypto_proto.PackedMessageList_CompressionType, error) {
+
+	if policy != nil &&
+		(policy.Compression == crypto_proto.PackedMessageList_UNCOMPRESSED ||
+			uint64(len(message_list)) < policy.MinSize) {
+		return message_list, crypto_proto.PackedMessageList_UNCOMPRESSED, nil
+	}
+
+	compressed, err := utils.Compress(message_list)
+	return compressed, crypto_proto.PackedMessageList_ZCOMPRESSION, err
+}
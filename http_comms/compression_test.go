@@ -0,0 +1,44 @@
+package http_comms
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	crypto_proto "www.velocidex.com/golang/velociraptor/crypto/proto"
+)
+
+func TestCompressMessageList(t *testing.T) {
+	small := []byte("hello")
+	large := bytes.Repeat([]byte("A"), 2000)
+
+	// Old servers expect everything to be compressed.
+	_, compression, err := compressMessageList(nil, small)
+	assert.NoError(t, err)
+	assert.Equal(t, crypto_proto.PackedMessageList_ZCOMPRESSION, compression)
+
+	policy := &compressionPolicy{}
+	policy.Set(&crypto_proto.CompressionPolicy{
+		Compression: crypto_proto.PackedMessageList_ZCOMPRESSION,
+		MinSize:     1024,
+	})
+
+	result, compression, err := compressMessageList(policy.Get(), small)
+	assert.NoError(t, err)
+	assert.Equal(t, crypto_proto.PackedMessageList_UNCOMPRESSED, compression)
+	assert.Equal(t, small, result)
+
+	result, compression, err = compressMessageList(policy.Get(), large)
+	assert.NoError(t, err)
+	assert.Equal(t, crypto_proto.PackedMessageList_ZCOMPRESSION, compression)
+	assert.True(t, len(result) < len(large))
+
+	// The server may disable compression altogether.
+	policy.Set(&crypto_proto.CompressionPolicy{
+		Compression: crypto_proto.PackedMessageList_UNCOMPRESSED,
+	})
+	result, compression, err = compressMessageList(policy.Get(), large)
+	assert.NoError(t, err)
+	assert.Equal(t, crypto_proto.PackedMessageList_UNCOMPRESSED, compression)
+	assert.Equal(t, large, result)
+}
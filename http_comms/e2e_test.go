@@ -153,7 +153,7 @@ func (self *TestSuite) TestServerRotateKeyE2E() {
 
 	// Make sure the client is properly enrolled
 	vtesting.WaitUntil(2*time.Second, self.T(), func() bool {
-		err := comm.sender.sendToURL(client_ctx, [][]byte{}, false)
+		err := comm.sender.sendToURL(client_ctx, [][]byte{}, nil, false)
 		assert.NoError(self.T(), err)
 
 		return vtesting.ContainsString("response with status: 200",
@@ -188,7 +188,7 @@ func (self *TestSuite) TestServerRotateKeyE2E() {
 
 	// Make sure the client properly rekeys and continues to talk to the server
 	vtesting.WaitUntil(5*time.Second, self.T(), func() bool {
-		err := comm.sender.sendToURL(client_ctx, [][]byte{}, false)
+		err := comm.sender.sendToURL(client_ctx, [][]byte{}, nil, false)
 		if err != nil {
 			return false
 		}
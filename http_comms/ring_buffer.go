@@ -17,7 +17,6 @@ import (
 	"www.velocidex.com/golang/velociraptor/executor"
 	"www.velocidex.com/golang/velociraptor/json"
 	"www.velocidex.com/golang/velociraptor/logging"
-	"www.velocidex.com/golang/velociraptor/utils"
 )
 
 const (
@@ -174,9 +173,14 @@ func (self *FileBasedRingBuffer) AvailableBytes() uint64 {
 }
 
 // Call Lease() repeatadly and compress each result until we get
-// closer to the required size.
-func LeaseAndCompress(self IRingBuffer, size uint64) [][]byte {
+// closer to the required size. Each message list is compressed
+// according to the server's policy so we also return how each one
+// was compressed.
+func LeaseAndCompress(self IRingBuffer, size uint64,
+	policy *crypto_proto.CompressionPolicy) (
+	[][]byte, []crypto_proto.PackedMessageList_CompressionType) {
 	result := [][]byte{}
+	compressions := []crypto_proto.PackedMessageList_CompressionType{}
 	total_len := uint64(0)
 	step := size / 4
 
@@ -188,7 +192,8 @@ func LeaseAndCompress(self IRingBuffer, size uint64) [][]byte {
 			break
 		}
 
-		compressed_message_list, err := utils.Compress(next_message_list)
+		compressed_message_list, compression, err := compressMessageList(
+			policy, next_message_list)
 		if err != nil || len(compressed_message_list) == 0 {
 			// Something terrible happened! The file is
 			// corrupted and it is better to start again.
@@ -196,10 +201,11 @@ func LeaseAndCompress(self IRingBuffer, size uint64) [][]byte {
 			break
 		}
 		result = append(result, compressed_message_list)
+		compressions = append(compressions, compression)
 		total_len += uint64(len(compressed_message_list))
 	}
 
-	return result
+	return result, compressions
 }
 
 // Determine if the item is blacklisted. Items are blacklisted when
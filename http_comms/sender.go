@@ -184,17 +184,18 @@ func (self *Sender) PumpRingBufferToSendMessage(
 
 		if atomic.LoadInt32(&self.IsPaused) == 0 {
 			// Grab some messages from the urgent ring buffer.
-			compressed_messages := LeaseAndCompress(self.urgent_buffer,
-				self.config_obj.Client.MaxUploadSize)
+			policy := self.compression.Get()
+			compressed_messages, compressions := LeaseAndCompress(
+				self.urgent_buffer, self.config_obj.Client.MaxUploadSize, policy)
 			if len(compressed_messages) > 0 {
-				self.sendMessageList(ctx, compressed_messages,
+				self.sendMessageList(ctx, compressed_messages, compressions,
 					true /* urgent */)
 				self.urgent_buffer.Commit()
 			}
 
 			// Grab some messages from the ring buffer.
-			compressed_messages = LeaseAndCompress(self.ring_buffer,
-				self.config_obj.Client.MaxUploadSize)
+			compressed_messages, compressions = LeaseAndCompress(
+				self.ring_buffer, self.config_obj.Client.MaxUploadSize, policy)
 			if len(compressed_messages) > 0 {
 				// sendMessageList will block until
 				// the messages are successfully sent
@@ -202,7 +203,8 @@ func (self *Sender) PumpRingBufferToSendMessage(
 				// know the messages are sent so we
 				// can commit them from the ring
 				// buffer.
-				self.sendMessageList(ctx, compressed_messages, false /* urgent */)
+				self.sendMessageList(ctx, compressed_messages, compressions,
+					false /* urgent */)
 				self.ring_buffer.Commit()
 
 				// We need to make sure our memory
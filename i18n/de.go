@@ -0,0 +1,18 @@
+package i18n
+
+func init() {
+	Register("de", Catalog{
+		"Client %v not found":                  "Client %v nicht gefunden",
+		"Invalid %v %q":                        "Ungültige %v %q",
+		"User is not allowed to launch flows.": "Der Benutzer darf keine Flows starten.",
+		"User is not allowed to cancel flows.": "Der Benutzer darf keine Flows abbrechen.",
+		"User is not allowed to view clients.": "Der Benutzer darf keine Clients anzeigen.",
+		"User is not allowed to view results.": "Der Benutzer darf keine Ergebnisse anzeigen.",
+		"User is not allowed to view reports.": "Der Benutzer darf keine Berichte anzeigen.",
+		"User is not allowed to view the VFS.": "Der Benutzer darf das VFS nicht anzeigen.",
+		"User is not allowed to view hunts.":   "Der Benutzer darf keine Hunts anzeigen.",
+		"User is not allowed to launch hunts.": "Der Benutzer darf keine Hunts starten.",
+		"Query produced no rows.":              "Die Abfrage lieferte keine Zeilen.",
+		"Please specify a query to run":        "Bitte geben Sie eine Abfrage an",
+	})
+}
@@ -0,0 +1,18 @@
+package i18n
+
+func init() {
+	Register("es", Catalog{
+		"Client %v not found":                  "Cliente %v no encontrado",
+		"Invalid %v %q":                        "%v %q no válido",
+		"User is not allowed to launch flows.": "El usuario no tiene permiso para iniciar flujos.",
+		"User is not allowed to cancel flows.": "El usuario no tiene permiso para cancelar flujos.",
+		"User is not allowed to view clients.": "El usuario no tiene permiso para ver clientes.",
+		"User is not allowed to view results.": "El usuario no tiene permiso para ver resultados.",
+		"User is not allowed to view reports.": "El usuario no tiene permiso para ver informes.",
+		"User is not allowed to view the VFS.": "El usuario no tiene permiso para ver el VFS.",
+		"User is not allowed to view hunts.":   "El usuario no tiene permiso para ver cacerías.",
+		"User is not allowed to launch hunts.": "El usuario no tiene permiso para iniciar cacerías.",
+		"Query produced no rows.":              "La consulta no produjo filas.",
+		"Please specify a query to run":        "Especifique una consulta para ejecutar",
+	})
+}
@@ -0,0 +1,18 @@
+package i18n
+
+func init() {
+	Register("fr", Catalog{
+		"Client %v not found":                  "Client %v introuvable",
+		"Invalid %v %q":                        "%v %q non valide",
+		"User is not allowed to launch flows.": "L'utilisateur n'est pas autorisé à lancer des flux.",
+		"User is not allowed to cancel flows.": "L'utilisateur n'est pas autorisé à annuler des flux.",
+		"User is not allowed to view clients.": "L'utilisateur n'est pas autorisé à voir les clients.",
+		"User is not allowed to view results.": "L'utilisateur n'est pas autorisé à voir les résultats.",
+		"User is not allowed to view reports.": "L'utilisateur n'est pas autorisé à voir les rapports.",
+		"User is not allowed to view the VFS.": "L'utilisateur n'est pas autorisé à voir le VFS.",
+		"User is not allowed to view hunts.":   "L'utilisateur n'est pas autorisé à voir les chasses.",
+		"User is not allowed to launch hunts.": "L'utilisateur n'est pas autorisé à lancer des chasses.",
+		"Query produced no rows.":              "La requête n'a produit aucune ligne.",
+		"Please specify a query to run":        "Veuillez indiquer une requête à exécuter",
+	})
+}
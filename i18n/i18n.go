@@ -0,0 +1,104 @@
+// Translate user facing server messages.
+//
+// Messages are looked up by their English text (or format string),
+// the same way the GUI's i8n module works, so untranslated messages
+// simply fall back to English. Language names follow the GUI's
+// language setting (e.g. "de", "por", "jp").
+
+package i18n
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	DEFAULT_LANG = "en"
+)
+
+type Catalog map[string]string
+
+var (
+	mu       sync.Mutex
+	catalogs = make(map[string]Catalog)
+
+	// Standard language tags for the GUI's language names.
+	aliases = map[string]string{
+		"pt": "por",
+		"ja": "jp",
+	}
+)
+
+// Add translations for a language. Later registrations override
+// earlier ones for the same message.
+func Register(lang string, catalog Catalog) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	existing, pres := catalogs[lang]
+	if !pres {
+		existing = make(Catalog)
+		catalogs[lang] = existing
+	}
+
+	for k, v := range catalog {
+		existing[k] = v
+	}
+}
+
+// Normalize a language name or tag (e.g. "pt-BR") to a supported
+// language, or the default language if it is not supported.
+func Normalize(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, candidate := range []string{lang, strings.SplitN(lang, "-", 2)[0]} {
+		alias, pres := aliases[candidate]
+		if pres {
+			candidate = alias
+		}
+
+		_, pres = catalogs[candidate]
+		if pres {
+			return candidate
+		}
+	}
+	return DEFAULT_LANG
+}
+
+// Pick the first supported language from an Accept-Language header.
+func FromAcceptLanguage(header string) string {
+	for _, part := range strings.Split(header, ",") {
+		tag := strings.SplitN(part, ";", 2)[0]
+		lang := Normalize(tag)
+		if lang != DEFAULT_LANG ||
+			strings.HasPrefix(strings.TrimSpace(tag), DEFAULT_LANG) {
+			return lang
+		}
+	}
+	return DEFAULT_LANG
+}
+
+func T(lang, message string) string {
+	mu.Lock()
+	defer mu.Unlock()
+
+	catalog, pres := catalogs[lang]
+	if !pres {
+		return message
+	}
+
+	translated, pres := catalog[message]
+	if !pres {
+		return message
+	}
+	return translated
+}
+
+// Translate the format string and then apply the args.
+func Sprintf(lang, format string, args ...interface{}) string {
+	return fmt.Sprintf(T(lang, format), args...)
+}
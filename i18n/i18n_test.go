@@ -0,0 +1,30 @@
+package i18n
+
+import (
+	"testing"
+
+	"www.velocidex.com/golang/velociraptor/vtesting/assert"
+)
+
+func TestTranslate(t *testing.T) {
+	assert.Equal(t, "Client C.1234 nicht gefunden",
+		Sprintf("de", "Client %v not found", "C.1234"))
+
+	// Unknown messages and languages fall back to English.
+	assert.Equal(t, "Something else", T("de", "Something else"))
+	assert.Equal(t, "Client C.1 not found",
+		Sprintf("xx", "Client %v not found", "C.1"))
+}
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "de", Normalize("de"))
+	assert.Equal(t, "de", Normalize("DE-at"))
+	assert.Equal(t, "por", Normalize("pt-BR"))
+	assert.Equal(t, "jp", Normalize("ja"))
+	assert.Equal(t, DEFAULT_LANG, Normalize("xx"))
+	assert.Equal(t, DEFAULT_LANG, Normalize(""))
+
+	assert.Equal(t, "fr", FromAcceptLanguage("xx-YY, fr-CH;q=0.9, en;q=0.8"))
+	assert.Equal(t, "en", FromAcceptLanguage("en-US,de;q=0.5"))
+	assert.Equal(t, "en", FromAcceptLanguage(""))
+}
@@ -0,0 +1,18 @@
+package i18n
+
+func init() {
+	Register("jp", Catalog{
+		"Client %v not found":                  "クライアント %v が見つかりません",
+		"Invalid %v %q":                        "無効な%v %q",
+		"User is not allowed to launch flows.": "ユーザーにはフローを起動する権限がありません。",
+		"User is not allowed to cancel flows.": "ユーザーにはフローをキャンセルする権限がありません。",
+		"User is not allowed to view clients.": "ユーザーにはクライアントを表示する権限がありません。",
+		"User is not allowed to view results.": "ユーザーには結果を表示する権限がありません。",
+		"User is not allowed to view reports.": "ユーザーにはレポートを表示する権限がありません。",
+		"User is not allowed to view the VFS.": "ユーザーには VFS を表示する権限がありません。",
+		"User is not allowed to view hunts.":   "ユーザーにはハントを表示する権限がありません。",
+		"User is not allowed to launch hunts.": "ユーザーにはハントを起動する権限がありません。",
+		"Query produced no rows.":              "クエリは行を返しませんでした。",
+		"Please specify a query to run":        "実行するクエリを指定してください",
+	})
+}
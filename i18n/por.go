@@ -0,0 +1,18 @@
+package i18n
+
+func init() {
+	Register("por", Catalog{
+		"Client %v not found":                  "Cliente %v não encontrado",
+		"Invalid %v %q":                        "%v %q inválido",
+		"User is not allowed to launch flows.": "O usuário não tem permissão para iniciar fluxos.",
+		"User is not allowed to cancel flows.": "O usuário não tem permissão para cancelar fluxos.",
+		"User is not allowed to view clients.": "O usuário não tem permissão para ver clientes.",
+		"User is not allowed to view results.": "O usuário não tem permissão para ver resultados.",
+		"User is not allowed to view reports.": "O usuário não tem permissão para ver relatórios.",
+		"User is not allowed to view the VFS.": "O usuário não tem permissão para ver o VFS.",
+		"User is not allowed to view hunts.":   "O usuário não tem permissão para ver caçadas.",
+		"User is not allowed to launch hunts.": "O usuário não tem permissão para iniciar caçadas.",
+		"Query produced no rows.":              "A consulta não produziu linhas.",
+		"Please specify a query to run":        "Especifique uma consulta para executar",
+	})
+}
@@ -0,0 +1,202 @@
+package ldap
+
+// A minimal BER encoder and decoder covering the parts of the LDAP
+// protocol we use. We can not use encoding/asn1 because Active
+// Directory sends lengths in the long form even when they would fit
+// in a single byte, which DER (and therefore encoding/asn1) rejects.
+
+import (
+	"errors"
+	"io"
+)
+
+const (
+	TAG_BOOLEAN      = 0x01
+	TAG_INTEGER      = 0x02
+	TAG_OCTET_STRING = 0x04
+	TAG_ENUMERATED   = 0x0a
+	TAG_SEQUENCE     = 0x30
+	TAG_SET          = 0x31
+
+	// Protocol operations are APPLICATION tagged.
+	TAG_BIND_REQUEST           = 0x60
+	TAG_BIND_RESPONSE          = 0x61
+	TAG_UNBIND_REQUEST         = 0x42
+	TAG_SEARCH_REQUEST         = 0x63
+	TAG_SEARCH_RESULT_ENTRY    = 0x64
+	TAG_SEARCH_RESULT_DONE     = 0x65
+	TAG_SEARCH_RESULT_REFERRAL = 0x73
+
+	// Context specific tags.
+	TAG_SIMPLE_AUTH    = 0x80
+	TAG_EQUALITY_MATCH = 0xa3
+
+	// Refuse to allocate unreasonably large messages.
+	MAX_ELEMENT_SIZE = 10 * 1024 * 1024
+)
+
+var (
+	truncatedError = errors.New("BER: truncated element")
+)
+
+type berElement struct {
+	tag  byte
+	data []byte
+}
+
+func (self berElement) String() string {
+	return string(self.data)
+}
+
+func (self berElement) Int() int64 {
+	var result int64
+	for i, b := range self.data {
+		if i == 0 && b&0x80 != 0 {
+			result = -1
+		}
+		result = result<<8 | int64(b)
+	}
+	return result
+}
+
+// The children of a constructed element.
+func (self berElement) Children() ([]berElement, error) {
+	return parseElements(self.data)
+}
+
+func encodeElement(tag byte, data []byte) []byte {
+	result := []byte{tag}
+	length := len(data)
+	if length < 0x80 {
+		result = append(result, byte(length))
+	} else {
+		length_bytes := []byte{}
+		for ; length > 0; length >>= 8 {
+			length_bytes = append([]byte{byte(length)}, length_bytes...)
+		}
+		result = append(result, 0x80|byte(len(length_bytes)))
+		result = append(result, length_bytes...)
+	}
+	return append(result, data...)
+}
+
+func encodeString(tag byte, value string) []byte {
+	return encodeElement(tag, []byte(value))
+}
+
+// LDAP only needs non-negative integers.
+func encodeInt(tag byte, value uint32) []byte {
+	data := []byte{byte(value)}
+	for value >>= 8; value != 0; value >>= 8 {
+		data = append([]byte{byte(value)}, data...)
+	}
+
+	// A leading 1 bit would make the value negative.
+	if data[0]&0x80 != 0 {
+		data = append([]byte{0}, data...)
+	}
+	return encodeElement(tag, data)
+}
+
+func encodeBool(value bool) []byte {
+	if value {
+		return encodeElement(TAG_BOOLEAN, []byte{0xff})
+	}
+	return encodeElement(TAG_BOOLEAN, []byte{0})
+}
+
+func encodeConstructed(tag byte, children ...[]byte) []byte {
+	data := []byte{}
+	for _, child := range children {
+		data = append(data, child...)
+	}
+	return encodeElement(tag, data)
+}
+
+// Split the buffer into consecutive elements.
+func parseElements(data []byte) ([]berElement, error) {
+	result := []berElement{}
+	for len(data) > 0 {
+		if len(data) < 2 {
+			return nil, truncatedError
+		}
+
+		tag := data[0]
+		if tag&0x1f == 0x1f {
+			return nil, errors.New("BER: multi-byte tags are not supported")
+		}
+
+		length, header, err := parseLength(data[1:])
+		if err != nil {
+			return nil, err
+		}
+
+		data = data[1+header:]
+		if length > len(data) {
+			return nil, truncatedError
+		}
+
+		result = append(result, berElement{tag: tag, data: data[:length]})
+		data = data[length:]
+	}
+	return result, nil
+}
+
+// Returns the length and the number of bytes it took.
+func parseLength(data []byte) (int, int, error) {
+	if data[0]&0x80 == 0 {
+		return int(data[0]), 1, nil
+	}
+
+	count := int(data[0] & 0x7f)
+	if count == 0 || count > 4 {
+		return 0, 0, errors.New("BER: unsupported length encoding")
+	}
+
+	if len(data) < 1+count {
+		return 0, 0, truncatedError
+	}
+
+	length := 0
+	for _, b := range data[1 : 1+count] {
+		length = length<<8 | int(b)
+	}
+
+	if length > MAX_ELEMENT_SIZE {
+		return 0, 0, errors.New("BER: element too large")
+	}
+
+	return length, 1 + count, nil
+}
+
+// Read a single element from the network.
+func readElement(reader io.Reader) (berElement, error) {
+	header := make([]byte, 2)
+	_, err := io.ReadFull(reader, header)
+	if err != nil {
+		return berElement{}, err
+	}
+
+	length_bytes := header[1:]
+	if header[1]&0x80 != 0 {
+		extra := make([]byte, header[1]&0x7f)
+		_, err = io.ReadFull(reader, extra)
+		if err != nil {
+			return berElement{}, err
+		}
+		length_bytes = append(length_bytes, extra...)
+	}
+
+	length, _, err := parseLength(length_bytes)
+	if err != nil {
+		return berElement{}, err
+	}
+
+	data := make([]byte, length)
+	_, err = io.ReadFull(reader, data)
+	if err != nil {
+		return berElement{}, err
+	}
+
+	return berElement{tag: header[0], data: data}, nil
+}
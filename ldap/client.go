@@ -0,0 +1,258 @@
+package ldap
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+)
+
+const (
+	SCOPE_WHOLE_SUBTREE = 2
+	DEREF_NEVER         = 0
+
+	RESULT_SUCCESS = 0
+
+	TIMEOUT = 30 * time.Second
+)
+
+// An entry returned by a search. Attribute names are lower cased
+// because LDAP attribute names are case insensitive.
+type Entry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+type Client struct {
+	conn       net.Conn
+	message_id uint32
+}
+
+// Connect to the server in the url - ldap:// or ldaps://
+func Dial(config_obj *config_proto.LDAPConfig) (*Client, error) {
+	parsed, err := url.Parse(config_obj.Url)
+	if err != nil {
+		return nil, err
+	}
+
+	host := parsed.Host
+	var conn net.Conn
+	dialer := &net.Dialer{Timeout: TIMEOUT}
+
+	switch parsed.Scheme {
+	case "ldap":
+		if parsed.Port() == "" {
+			host = net.JoinHostPort(parsed.Hostname(), "389")
+		}
+		conn, err = dialer.Dial("tcp", host)
+
+	case "ldaps":
+		if parsed.Port() == "" {
+			host = net.JoinHostPort(parsed.Hostname(), "636")
+		}
+		conn, err = tls.DialWithDialer(dialer, "tcp", host, &tls.Config{
+			ServerName:         parsed.Hostname(),
+			InsecureSkipVerify: config_obj.InsecureSkipVerify,
+		})
+
+	default:
+		return nil, fmt.Errorf("Unsupported LDAP url %v", config_obj.Url)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Do not let a slow directory server hold up logins.
+	err = conn.SetDeadline(time.Now().Add(TIMEOUT))
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &Client{conn: conn}, nil
+}
+
+func (self *Client) Close() error {
+	_, _ = self.conn.Write(self.message(encodeElement(TAG_UNBIND_REQUEST, nil)))
+	return self.conn.Close()
+}
+
+// Wrap the operation in an LDAPMessage.
+func (self *Client) message(op []byte) []byte {
+	self.message_id++
+	return encodeConstructed(TAG_SEQUENCE,
+		encodeInt(TAG_INTEGER, self.message_id), op)
+}
+
+// Read the next response and return its protocol operation.
+func (self *Client) readResponse() (berElement, error) {
+	message, err := readElement(self.conn)
+	if err != nil {
+		return berElement{}, err
+	}
+
+	if message.tag != TAG_SEQUENCE {
+		return berElement{}, errors.New("LDAP: invalid message")
+	}
+
+	children, err := message.Children()
+	if err != nil {
+		return berElement{}, err
+	}
+
+	if len(children) < 2 || children[0].tag != TAG_INTEGER {
+		return berElement{}, errors.New("LDAP: invalid message")
+	}
+
+	if uint32(children[0].Int()) != self.message_id {
+		return berElement{}, errors.New("LDAP: unexpected message id")
+	}
+
+	return children[1], nil
+}
+
+// Simple bind with a DN and password.
+func (self *Client) Bind(dn, password string) error {
+	_, err := self.conn.Write(self.message(encodeConstructed(
+		TAG_BIND_REQUEST,
+		encodeInt(TAG_INTEGER, 3),
+		encodeString(TAG_OCTET_STRING, dn),
+		encodeString(TAG_SIMPLE_AUTH, password))))
+	if err != nil {
+		return err
+	}
+
+	response, err := self.readResponse()
+	if err != nil {
+		return err
+	}
+
+	if response.tag != TAG_BIND_RESPONSE {
+		return errors.New("LDAP: invalid bind response")
+	}
+
+	return checkResult(response, "bind")
+}
+
+// Search the subtree for entries where attribute equals value.
+func (self *Client) Search(base_dn, attribute, value string,
+	attributes []string) ([]*Entry, error) {
+
+	requested := [][]byte{}
+	for _, name := range attributes {
+		requested = append(requested, encodeString(TAG_OCTET_STRING, name))
+	}
+
+	_, err := self.conn.Write(self.message(encodeConstructed(
+		TAG_SEARCH_REQUEST,
+		encodeString(TAG_OCTET_STRING, base_dn),
+		encodeInt(TAG_ENUMERATED, SCOPE_WHOLE_SUBTREE),
+		encodeInt(TAG_ENUMERATED, DEREF_NEVER),
+		encodeInt(TAG_INTEGER, 0),
+		encodeInt(TAG_INTEGER, 0),
+		encodeBool(false),
+		encodeConstructed(TAG_EQUALITY_MATCH,
+			encodeString(TAG_OCTET_STRING, attribute),
+			encodeString(TAG_OCTET_STRING, value)),
+		encodeConstructed(TAG_SEQUENCE, requested...))))
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*Entry{}
+	for {
+		response, err := self.readResponse()
+		if err != nil {
+			return nil, err
+		}
+
+		switch response.tag {
+		case TAG_SEARCH_RESULT_ENTRY:
+			entry, err := parseEntry(response)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, entry)
+
+		case TAG_SEARCH_RESULT_DONE:
+			return result, checkResult(response, "search")
+
+		// We do not follow referrals to other servers.
+		case TAG_SEARCH_RESULT_REFERRAL:
+
+		default:
+			return nil, errors.New("LDAP: invalid search response")
+		}
+	}
+}
+
+func parseEntry(response berElement) (*Entry, error) {
+	children, err := response.Children()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(children) < 2 {
+		return nil, errors.New("LDAP: invalid search entry")
+	}
+
+	entry := &Entry{
+		DN:         children[0].String(),
+		Attributes: make(map[string][]string),
+	}
+
+	attributes, err := children[1].Children()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, attribute := range attributes {
+		parts, err := attribute.Children()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(parts) < 2 {
+			return nil, errors.New("LDAP: invalid attribute")
+		}
+
+		values, err := parts[1].Children()
+		if err != nil {
+			return nil, err
+		}
+
+		name := strings.ToLower(parts[0].String())
+		for _, value := range values {
+			entry.Attributes[name] = append(entry.Attributes[name], value.String())
+		}
+	}
+
+	return entry, nil
+}
+
+// The LDAPResult starts with the result code, matched DN and a
+// diagnostic message.
+func checkResult(response berElement, operation string) error {
+	children, err := response.Children()
+	if err != nil {
+		return err
+	}
+
+	if len(children) < 3 {
+		return fmt.Errorf("LDAP: invalid %v response", operation)
+	}
+
+	code := children[0].Int()
+	if code != RESULT_SUCCESS {
+		return fmt.Errorf("LDAP %v failed (%v): %v",
+			operation, code, children[2].String())
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package ldap
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"www.velocidex.com/golang/velociraptor/acls"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/file_store/test_utils"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+// Active Directory always uses 4 byte lengths.
+func encodeLong(tag byte, children ...[]byte) []byte {
+	data := []byte{}
+	for _, child := range children {
+		data = append(data, child...)
+	}
+	length := len(data)
+	return append([]byte{tag, 0x84,
+		byte(length >> 24), byte(length >> 16), byte(length >> 8), byte(length)},
+		data...)
+}
+
+func encodeResult(tag byte) []byte {
+	return encodeLong(tag,
+		encodeInt(TAG_ENUMERATED, RESULT_SUCCESS),
+		encodeString(TAG_OCTET_STRING, ""),
+		encodeString(TAG_OCTET_STRING, ""))
+}
+
+// A directory with a single user.
+type fakeServer struct {
+	mu       sync.Mutex
+	listener net.Listener
+	username string
+	groups   []string
+}
+
+func (self *fakeServer) setGroups(groups []string) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	self.groups = groups
+}
+
+func (self *fakeServer) serve() {
+	for {
+		conn, err := self.listener.Accept()
+		if err != nil {
+			return
+		}
+		go self.handle(conn)
+	}
+}
+
+func (self *fakeServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	for {
+		message, err := readElement(conn)
+		if err != nil {
+			return
+		}
+
+		children, err := message.Children()
+		if err != nil || len(children) < 2 {
+			return
+		}
+
+		message_id := uint32(children[0].Int())
+		reply := func(op []byte) {
+			_, _ = conn.Write(encodeLong(TAG_SEQUENCE,
+				encodeInt(TAG_INTEGER, message_id), op))
+		}
+
+		switch children[1].tag {
+		case TAG_BIND_REQUEST:
+			reply(encodeResult(TAG_BIND_RESPONSE))
+
+		case TAG_SEARCH_REQUEST:
+			request, _ := children[1].Children()
+			filter, _ := request[6].Children()
+			if filter[1].String() == self.username {
+				reply(self.entry())
+			}
+			reply(encodeResult(TAG_SEARCH_RESULT_DONE))
+
+		default:
+			return
+		}
+	}
+}
+
+func (self *fakeServer) entry() []byte {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	values := [][]byte{}
+	for _, group := range self.groups {
+		values = append(values, encodeString(TAG_OCTET_STRING, group))
+	}
+
+	return encodeLong(TAG_SEARCH_RESULT_ENTRY,
+		encodeString(TAG_OCTET_STRING, "CN="+self.username+",DC=example,DC=com"),
+		encodeLong(TAG_SEQUENCE,
+			encodeLong(TAG_SEQUENCE,
+				encodeString(TAG_OCTET_STRING, "memberOf"),
+				encodeLong(TAG_SET, values...))))
+}
+
+type LDAPTestSuite struct {
+	test_utils.TestSuite
+	server *fakeServer
+}
+
+func (self *LDAPTestSuite) SetupTest() {
+	self.TestSuite.SetupTest()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(self.T(), err)
+
+	self.server = &fakeServer{listener: listener, username: "alice"}
+	go self.server.serve()
+
+	self.ConfigObj.Ldap = &config_proto.LDAPConfig{
+		Url:    "ldap://" + listener.Addr().String(),
+		BaseDn: "DC=example,DC=com",
+	}
+}
+
+func (self *LDAPTestSuite) TearDownTest() {
+	self.server.listener.Close()
+	self.TestSuite.TearDownTest()
+}
+
+func (self *LDAPTestSuite) TestSearch() {
+	self.server.setGroups([]string{"CN=Responders,OU=Groups,DC=example,DC=com"})
+
+	groups, err := getUserGroups(self.ConfigObj.Ldap, "alice")
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(),
+		[]string{"CN=Responders,OU=Groups,DC=example,DC=com"}, groups)
+
+	// Unknown users have no groups.
+	groups, err = getUserGroups(self.ConfigObj.Ldap, "bob")
+	assert.NoError(self.T(), err)
+	assert.Empty(self.T(), groups)
+}
+
+func (self *LDAPTestSuite) TestSyncUser() {
+	err := SetMappings(self.ConfigObj, &api_proto.LDAPGroupMappings{
+		Items: []*api_proto.LDAPGroupMapping{{
+			Group: "responders",
+			OrgId: "root",
+			Roles: []string{"investigator"},
+		}, {
+			Group: "CN=Admins,OU=Groups,DC=example,DC=com",
+			Roles: []string{"administrator"},
+		}}}, "admin")
+	assert.NoError(self.T(), err)
+
+	// Invalid roles are rejected.
+	err = SetMappings(self.ConfigObj, &api_proto.LDAPGroupMappings{
+		Items: []*api_proto.LDAPGroupMapping{{
+			Group: "responders",
+			Roles: []string{"superuser"},
+		}}}, "admin")
+	assert.Error(self.T(), err)
+
+	// Membership of the Responders group grants the investigator
+	// role in the root org and creates the user.
+	self.server.setGroups([]string{"CN=Responders,OU=Groups,DC=example,DC=com"})
+	err = SyncUser(self.ConfigObj, "alice")
+	assert.NoError(self.T(), err)
+
+	policy, err := acls.GetPolicy(self.ConfigObj, "alice")
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), []string{"investigator"}, policy.Roles)
+
+	user_record, err := services.GetUserManager().GetUser("alice")
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), 1, len(user_record.Orgs))
+
+	// Removing the user from the group has no effect until the next
+	// refresh.
+	self.server.setGroups(nil)
+	err = SyncUser(self.ConfigObj, "alice")
+	assert.NoError(self.T(), err)
+
+	policy, err = acls.GetPolicy(self.ConfigObj, "alice")
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), []string{"investigator"}, policy.Roles)
+
+	// Then the role and org access are revoked.
+	mu.Lock()
+	last_sync = make(map[string]time.Time)
+	mu.Unlock()
+
+	err = SyncUser(self.ConfigObj, "alice")
+	assert.NoError(self.T(), err)
+
+	policy, err = acls.GetPolicy(self.ConfigObj, "alice")
+	assert.NoError(self.T(), err)
+	assert.Empty(self.T(), policy.Roles)
+
+	user_record, err = services.GetUserManager().GetUser("alice")
+	assert.NoError(self.T(), err)
+	assert.Empty(self.T(), user_record.Orgs)
+}
+
+func TestLDAP(t *testing.T) {
+	suite.Run(t, &LDAPTestSuite{})
+}
@@ -0,0 +1,343 @@
+// Users' roles and org access may be derived from their Active
+// Directory groups. When a user logs in we look up their groups and
+// apply the group to role mappings:
+//
+// - For every org named in a mapping, the user is given exactly the
+//   roles of the mappings they match. If they match none of the
+//   mappings for an org, their roles are removed and they lose access
+//   to the org.
+//
+// - Orgs not named in any mapping are managed by hand as before.
+//
+// Groups are refreshed periodically while the user is logged in, so
+// removing a user from a group in AD eventually revokes their access.
+// Only direct group membership (the memberOf attribute) is considered.
+
+package ldap
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"www.velocidex.com/golang/velociraptor/acls"
+	acl_proto "www.velocidex.com/golang/velociraptor/acls/proto"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/datastore"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/paths"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/services/users"
+	"www.velocidex.com/golang/velociraptor/utils"
+)
+
+const (
+	DEFAULT_USER_ATTRIBUTE  = "sAMAccountName"
+	DEFAULT_GROUP_ATTRIBUTE = "memberOf"
+	DEFAULT_REFRESH         = 15 * time.Minute
+)
+
+var (
+	mu sync.Mutex
+
+	// When each user's groups were last looked up.
+	last_sync = make(map[string]time.Time)
+)
+
+// The mappings are global and stored in the root org.
+func GetMappings(
+	config_obj *config_proto.Config) (*api_proto.LDAPGroupMappings, error) {
+	db, err := datastore.GetDB(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &api_proto.LDAPGroupMappings{}
+	err = db.GetSubject(config_obj, paths.LDAP_MAPPINGS, result)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func SetMappings(
+	config_obj *config_proto.Config,
+	mappings *api_proto.LDAPGroupMappings, principal string) error {
+
+	for _, mapping := range mappings.Items {
+		if mapping.Group == "" {
+			return errors.New("Mappings must specify a group")
+		}
+
+		for _, role := range mapping.Roles {
+			if !acls.IsValidRole(config_obj, role) {
+				return fmt.Errorf("Invalid role %v", role)
+			}
+		}
+	}
+
+	db, err := datastore.GetDB(config_obj)
+	if err != nil {
+		return err
+	}
+
+	err = db.SetSubject(config_obj, paths.LDAP_MAPPINGS, mappings)
+	if err != nil {
+		return err
+	}
+
+	// Apply the new mappings at the next request.
+	mu.Lock()
+	last_sync = make(map[string]time.Time)
+	mu.Unlock()
+
+	logging.GetLogger(config_obj, &logging.Audit).
+		WithFields(logrus.Fields{
+			"user":     principal,
+			"mappings": mappings.Items,
+		}).Info("SetLDAPMappings")
+
+	return nil
+}
+
+// Refresh the user's roles from their groups. This is called on each
+// authenticated request so it only queries the directory once per
+// refresh period.
+func SyncUser(config_obj *config_proto.Config, username string) error {
+	if config_obj.Ldap == nil || config_obj.Ldap.Url == "" {
+		return nil
+	}
+
+	refresh := DEFAULT_REFRESH
+	if config_obj.Ldap.RefreshSeconds > 0 {
+		refresh = time.Duration(config_obj.Ldap.RefreshSeconds) * time.Second
+	}
+
+	// Record the attempt even if it fails so an unavailable
+	// directory server is not queried on every request.
+	now := time.Now()
+	mu.Lock()
+	last, pres := last_sync[username]
+	if pres && now.Sub(last) < refresh {
+		mu.Unlock()
+		return nil
+	}
+	last_sync[username] = now
+	mu.Unlock()
+
+	mappings, err := GetMappings(config_obj)
+	if err != nil || len(mappings.Items) == 0 {
+		return nil
+	}
+
+	groups, err := getUserGroups(config_obj.Ldap, username)
+	if err != nil {
+		return err
+	}
+
+	return applyMappings(config_obj, username, groups, mappings.Items)
+}
+
+func getUserGroups(
+	config_obj *config_proto.LDAPConfig, username string) ([]string, error) {
+	user_attribute := config_obj.UserAttribute
+	if user_attribute == "" {
+		user_attribute = DEFAULT_USER_ATTRIBUTE
+	}
+
+	group_attribute := config_obj.GroupAttribute
+	if group_attribute == "" {
+		group_attribute = DEFAULT_GROUP_ATTRIBUTE
+	}
+
+	client, err := Dial(config_obj)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	err = client.Bind(config_obj.BindDn, config_obj.BindPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := client.Search(config_obj.BaseDn,
+		user_attribute, username, []string{group_attribute})
+	if err != nil {
+		return nil, err
+	}
+
+	// An ambiguous match must not grant anything.
+	if len(entries) > 1 {
+		return nil, fmt.Errorf("LDAP: %v matches %v entries",
+			username, len(entries))
+	}
+
+	// A user missing from the directory has no groups.
+	result := []string{}
+	for _, entry := range entries {
+		result = append(result,
+			entry.Attributes[strings.ToLower(group_attribute)]...)
+	}
+
+	return result, nil
+}
+
+func applyMappings(
+	config_obj *config_proto.Config, username string,
+	groups []string, mappings []*api_proto.LDAPGroupMapping) error {
+
+	// Every org named in a mapping is managed by the mappings.
+	wanted := make(map[string][]string)
+	for _, mapping := range mappings {
+		org_id := normalizeOrgId(mapping.OrgId)
+		roles := wanted[org_id]
+		if isMember(groups, mapping.Group) {
+			for _, role := range mapping.Roles {
+				if !utils.InString(roles, role) {
+					roles = append(roles, role)
+				}
+			}
+		}
+		wanted[org_id] = roles
+	}
+
+	users_manager := services.GetUserManager()
+	user_record, err := users_manager.GetUserWithHashes(username)
+	if err != nil {
+		// Users with no roles are not created.
+		if !hasRoles(wanted) {
+			return nil
+		}
+
+		user_record, err = users.NewUserRecord(username)
+		if err != nil {
+			return err
+		}
+	}
+
+	org_manager, err := services.GetOrgManager()
+	if err != nil {
+		return err
+	}
+
+	logger := logging.GetLogger(config_obj, &logging.Audit)
+	changed := false
+	for org_id, roles := range wanted {
+		org_config_obj, err := org_manager.GetOrgConfig(org_id)
+		if err != nil {
+			logging.GetLogger(config_obj, &logging.FrontendComponent).
+				Error("LDAP mapping for unknown org %v", org_id)
+			continue
+		}
+
+		policy, err := acls.GetPolicy(org_config_obj, username)
+		if err != nil {
+			policy = &acl_proto.ApiClientACL{}
+		}
+
+		if !sameRoles(policy.Roles, roles) {
+			err = acls.GrantRoles(org_config_obj, username, roles)
+			if err != nil {
+				return err
+			}
+
+			logger.WithFields(logrus.Fields{
+				"user":  username,
+				"org":   org_id,
+				"roles": roles,
+			}).Info("LDAP roles updated")
+		}
+
+		if setOrgMembership(user_record, org_config_obj, len(roles) > 0) {
+			changed = true
+		}
+	}
+
+	if changed {
+		return users_manager.SetUser(user_record)
+	}
+	return nil
+}
+
+// Add or remove the org from the user record. Returns true if the
+// record changed.
+func setOrgMembership(user_record *api_proto.VelociraptorUser,
+	org_config_obj *config_proto.Config, member bool) bool {
+	for idx, org := range user_record.Orgs {
+		if normalizeOrgId(org.Id) == org_config_obj.OrgId {
+			if member {
+				return false
+			}
+			user_record.Orgs = append(user_record.Orgs[:idx],
+				user_record.Orgs[idx+1:]...)
+			return true
+		}
+	}
+
+	if !member {
+		return false
+	}
+
+	user_record.Orgs = append(user_record.Orgs, &api_proto.Org{
+		Name: org_config_obj.OrgName,
+		Id:   org_config_obj.OrgId,
+	})
+	return true
+}
+
+// Groups are matched by their DN or their CN.
+func isMember(groups []string, group string) bool {
+	for _, dn := range groups {
+		if strings.EqualFold(dn, group) ||
+			strings.EqualFold(commonName(dn), group) {
+			return true
+		}
+	}
+	return false
+}
+
+// The CN of a DN like CN=Responders,OU=Groups,DC=example,DC=com
+func commonName(dn string) string {
+	rdn := strings.SplitN(dn, ",", 2)[0]
+	parts := strings.SplitN(rdn, "=", 2)
+	if len(parts) == 2 && strings.EqualFold(strings.TrimSpace(parts[0]), "CN") {
+		return strings.TrimSpace(parts[1])
+	}
+	return ""
+}
+
+// The GUI refers to the root org as "root".
+func normalizeOrgId(org_id string) string {
+	if org_id == "root" {
+		return ""
+	}
+	return org_id
+}
+
+func hasRoles(wanted map[string][]string) bool {
+	for _, roles := range wanted {
+		if len(roles) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func sameRoles(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, role := range a {
+		if !utils.InString(b, role) {
+			return false
+		}
+	}
+	return true
+}
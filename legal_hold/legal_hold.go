@@ -0,0 +1,224 @@
+// Evidence may be placed under legal hold to ensure it is preserved.
+// Held flows (including their uploads) and hunts can not be deleted
+// until the hold is released by an administrator. All deletion paths
+// check for holds before removing any data.
+//
+// Placing and releasing holds is recorded in the audit log so there
+// is a history of the hold.
+
+package legal_hold
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/datastore"
+	"www.velocidex.com/golang/velociraptor/file_store/api"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/paths"
+)
+
+const (
+	HOLD_FLOW = "flow"
+	HOLD_HUNT = "hunt"
+)
+
+var (
+	// Callers can check for this with errors.Is()
+	LegalHoldError = errors.New("Evidence is under legal hold")
+)
+
+func getHoldPath(hold *api_proto.LegalHold) (api.DSPathSpec, error) {
+	switch hold.Type {
+	case HOLD_FLOW:
+		if hold.ClientId == "" || hold.FlowId == "" {
+			return nil, errors.New("Flow holds require a client id and flow id")
+		}
+		return paths.LEGAL_HOLD_ROOT.AddUnsafeChild(
+			"flows", hold.ClientId, hold.FlowId), nil
+
+	case HOLD_HUNT:
+		if hold.HuntId == "" {
+			return nil, errors.New("Hunt holds require a hunt id")
+		}
+		return paths.LEGAL_HOLD_ROOT.AddUnsafeChild("hunts", hold.HuntId), nil
+	}
+
+	return nil, fmt.Errorf("Unknown hold type %v", hold.Type)
+}
+
+// Place a hold on a flow or hunt. Placing the hold again updates
+// the reason.
+func SetHold(
+	config_obj *config_proto.Config,
+	hold *api_proto.LegalHold, principal string) error {
+
+	hold_path, err := getHoldPath(hold)
+	if err != nil {
+		return err
+	}
+
+	db, err := datastore.GetDB(config_obj)
+	if err != nil {
+		return err
+	}
+
+	hold.Principal = principal
+	hold.Timestamp = uint64(time.Now().Unix())
+
+	err = db.SetSubject(config_obj, hold_path, hold)
+	if err != nil {
+		return err
+	}
+
+	auditHold(config_obj, hold, principal, "PlaceLegalHold")
+	return nil
+}
+
+func ReleaseHold(
+	config_obj *config_proto.Config,
+	hold *api_proto.LegalHold, principal string) error {
+
+	hold_path, err := getHoldPath(hold)
+	if err != nil {
+		return err
+	}
+
+	db, err := datastore.GetDB(config_obj)
+	if err != nil {
+		return err
+	}
+
+	existing := &api_proto.LegalHold{}
+	err = db.GetSubject(config_obj, hold_path, existing)
+	if err != nil || existing.Type == "" {
+		return fmt.Errorf("No legal hold found: %w", os.ErrNotExist)
+	}
+
+	err = db.DeleteSubject(config_obj, hold_path)
+	if err != nil {
+		return err
+	}
+
+	// Record the original reason with the release.
+	if hold.Reason == "" {
+		hold.Reason = existing.Reason
+	}
+	auditHold(config_obj, hold, principal, "ReleaseLegalHold")
+	return nil
+}
+
+func auditHold(config_obj *config_proto.Config,
+	hold *api_proto.LegalHold, principal, action string) {
+	logging.GetLogger(config_obj, &logging.Audit).
+		WithFields(logrus.Fields{
+			"user":    principal,
+			"type":    hold.Type,
+			"client":  hold.ClientId,
+			"flow_id": hold.FlowId,
+			"hunt_id": hold.HuntId,
+			"reason":  hold.Reason,
+		}).Info(action)
+}
+
+func GetHolds(config_obj *config_proto.Config) (*api_proto.LegalHolds, error) {
+	db, err := datastore.GetDB(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &api_proto.LegalHolds{}
+	err = datastore.Walk(config_obj, db, paths.LEGAL_HOLD_ROOT,
+		datastore.WalkWithoutDirectories,
+		func(path api.DSPathSpec) error {
+			hold := &api_proto.LegalHold{}
+			err := db.GetSubject(config_obj, path, hold)
+			if err == nil && hold.Type != "" {
+				result.Items = append(result.Items, hold)
+			}
+			return nil
+		})
+
+	return result, err
+}
+
+func isHeld(config_obj *config_proto.Config, hold *api_proto.LegalHold) bool {
+	hold_path, err := getHoldPath(hold)
+	if err != nil {
+		return false
+	}
+
+	db, err := datastore.GetDB(config_obj)
+	if err != nil {
+		return false
+	}
+
+	existing := &api_proto.LegalHold{}
+	err = db.GetSubject(config_obj, hold_path, existing)
+	return err == nil && existing.Type != ""
+}
+
+// Returns an error if the flow may not be deleted. Flows launched by
+// a hunt are also held by a hold on the hunt.
+func CheckFlowHold(config_obj *config_proto.Config,
+	client_id, flow_id, creator string) error {
+	if isHeld(config_obj, &api_proto.LegalHold{
+		Type: HOLD_FLOW, ClientId: client_id, FlowId: flow_id}) {
+		return fmt.Errorf("%w: flow %v", LegalHoldError, flow_id)
+	}
+
+	if strings.HasPrefix(creator, "H.") {
+		return CheckHuntHold(config_obj, creator)
+	}
+
+	return nil
+}
+
+func CheckHuntHold(config_obj *config_proto.Config, hunt_id string) error {
+	if isHeld(config_obj, &api_proto.LegalHold{
+		Type: HOLD_HUNT, HuntId: hunt_id}) {
+		return fmt.Errorf("%w: hunt %v", LegalHoldError, hunt_id)
+	}
+	return nil
+}
+
+// Returns an error if any of the client's flows are held.
+func CheckClientHolds(config_obj *config_proto.Config, client_id string) error {
+	db, err := datastore.GetDB(config_obj)
+	if err != nil {
+		return err
+	}
+
+	children, _ := db.ListChildren(config_obj,
+		paths.LEGAL_HOLD_ROOT.AddUnsafeChild("flows", client_id))
+	if len(children) > 0 {
+		return fmt.Errorf("%w: client %v has held flows",
+			LegalHoldError, client_id)
+	}
+	return nil
+}
+
+// Check an arbitrary datastore or filestore path to see if it
+// belongs to held evidence.
+func CheckPathHold(config_obj *config_proto.Config, components []string) error {
+	switch {
+	case len(components) >= 4 && components[0] == "clients" &&
+		components[2] == "collections":
+		return CheckFlowHold(config_obj, components[1], components[3], "")
+
+	case len(components) >= 2 && components[0] == "clients":
+		return CheckClientHolds(config_obj, components[1])
+
+	case len(components) >= 2 && components[0] == "hunts":
+		return CheckHuntHold(config_obj, strings.TrimSuffix(
+			components[1], ".json"))
+	}
+
+	return nil
+}
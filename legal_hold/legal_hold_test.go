@@ -0,0 +1,87 @@
+package legal_hold_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/file_store/test_utils"
+	"www.velocidex.com/golang/velociraptor/legal_hold"
+)
+
+type LegalHoldTestSuite struct {
+	test_utils.TestSuite
+}
+
+func (self *LegalHoldTestSuite) TestFlowHold() {
+	hold := &api_proto.LegalHold{
+		Type:     legal_hold.HOLD_FLOW,
+		ClientId: "C.1234",
+		FlowId:   "F.1234",
+		Reason:   "Litigation",
+	}
+
+	err := legal_hold.SetHold(self.ConfigObj, hold, "admin")
+	assert.NoError(self.T(), err)
+
+	err = legal_hold.CheckFlowHold(self.ConfigObj, "C.1234", "F.1234", "")
+	assert.True(self.T(), errors.Is(err, legal_hold.LegalHoldError))
+
+	// Other flows on the client are not held but the client can
+	// not be deleted.
+	assert.NoError(self.T(), legal_hold.CheckFlowHold(
+		self.ConfigObj, "C.1234", "F.5678", ""))
+	assert.Error(self.T(), legal_hold.CheckClientHolds(self.ConfigObj, "C.1234"))
+
+	// Uploads in the flow are held too.
+	err = legal_hold.CheckPathHold(self.ConfigObj, []string{
+		"clients", "C.1234", "collections", "F.1234", "uploads", "file"})
+	assert.True(self.T(), errors.Is(err, legal_hold.LegalHoldError))
+
+	holds, err := legal_hold.GetHolds(self.ConfigObj)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), 1, len(holds.Items))
+	assert.Equal(self.T(), "admin", holds.Items[0].Principal)
+
+	// Once released the flow can be deleted.
+	err = legal_hold.ReleaseHold(self.ConfigObj, &api_proto.LegalHold{
+		Type:     legal_hold.HOLD_FLOW,
+		ClientId: "C.1234",
+		FlowId:   "F.1234",
+	}, "admin")
+	assert.NoError(self.T(), err)
+
+	assert.NoError(self.T(), legal_hold.CheckFlowHold(
+		self.ConfigObj, "C.1234", "F.1234", ""))
+	assert.NoError(self.T(), legal_hold.CheckClientHolds(self.ConfigObj, "C.1234"))
+
+	// Releasing again is an error.
+	err = legal_hold.ReleaseHold(self.ConfigObj, hold, "admin")
+	assert.Error(self.T(), err)
+}
+
+func (self *LegalHoldTestSuite) TestHuntHold() {
+	err := legal_hold.SetHold(self.ConfigObj, &api_proto.LegalHold{
+		Type:   legal_hold.HOLD_HUNT,
+		HuntId: "H.1234",
+	}, "admin")
+	assert.NoError(self.T(), err)
+
+	assert.Error(self.T(), legal_hold.CheckHuntHold(self.ConfigObj, "H.1234"))
+
+	// Flows created by the hunt are also held.
+	err = legal_hold.CheckFlowHold(self.ConfigObj, "C.1234", "F.1234", "H.1234")
+	assert.True(self.T(), errors.Is(err, legal_hold.LegalHoldError))
+
+	// Holds must refer to a flow or hunt.
+	err = legal_hold.SetHold(self.ConfigObj, &api_proto.LegalHold{
+		Type: legal_hold.HOLD_FLOW,
+	}, "admin")
+	assert.Error(self.T(), err)
+}
+
+func TestLegalHold(t *testing.T) {
+	suite.Run(t, &LegalHoldTestSuite{})
+}
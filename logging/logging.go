@@ -41,6 +41,10 @@ var (
 	SuppressLogging = false
 	NoColor         = false
 
+	// Set when something else (e.g. a progress display) owns the
+	// console so log messages are only written to log files.
+	DisableConsole = false
+
 	GenericComponent  = "Velociraptor"
 	FrontendComponent = "VelociraptorFrontend"
 	ClientComponent   = "VelociraptorClient"
@@ -327,18 +331,20 @@ func (self *LogManager) makeNewComponent(
 	}
 
 	// Add stderr logging if required.
-	stderr_map := lfshook.WriterMap{
-		logrus.ErrorLevel: os.Stderr,
-	}
+	if !DisableConsole {
+		stderr_map := lfshook.WriterMap{
+			logrus.ErrorLevel: os.Stderr,
+		}
 
-	if !SuppressLogging {
-		stderr_map[logrus.DebugLevel] = os.Stderr
-		stderr_map[logrus.InfoLevel] = os.Stderr
-		stderr_map[logrus.WarnLevel] = os.Stderr
-		stderr_map[logrus.ErrorLevel] = os.Stderr
-	}
+		if !SuppressLogging {
+			stderr_map[logrus.DebugLevel] = os.Stderr
+			stderr_map[logrus.InfoLevel] = os.Stderr
+			stderr_map[logrus.WarnLevel] = os.Stderr
+			stderr_map[logrus.ErrorLevel] = os.Stderr
+		}
 
-	Log.Hooks.Add(lfshook.NewHook(stderr_map, &Formatter{stderr_map}))
+		Log.Hooks.Add(lfshook.NewHook(stderr_map, &Formatter{stderr_map}))
+	}
 	if !NoColor && !isatty.IsTerminal(os.Stdout.Fd()) {
 		NoColor = true
 	}
@@ -24,6 +24,31 @@ var (
 	NOTEBOOK_INDEX = path_specs.NewSafeDatastorePath("notebook_index").
 			SetType(api.PATH_TYPE_DATASTORE_PROTO)
 
+	// Notebooks which contain scheduled cells.
+	NOTEBOOK_SCHEDULE_INDEX = path_specs.NewSafeDatastorePath(
+		"notebook_schedule_index").
+		SetType(api.PATH_TYPE_DATASTORE_PROTO)
+
+	// Background jobs.
+	JOBS_ROOT = path_specs.NewSafeDatastorePath("jobs").
+			SetType(api.PATH_TYPE_DATASTORE_PROTO)
+
+	// Two person integrity approvals.
+	APPROVALS_ROOT = path_specs.NewUnsafeDatastorePath("approvals").
+			SetType(api.PATH_TYPE_DATASTORE_PROTO)
+
+	// Flows and hunts under legal hold.
+	LEGAL_HOLD_ROOT = path_specs.NewUnsafeDatastorePath("legal_holds").
+			SetType(api.PATH_TYPE_DATASTORE_PROTO)
+
+	// Active Directory group to role mappings.
+	LDAP_MAPPINGS = path_specs.NewSafeDatastorePath("config", "ldap_mappings").
+			SetType(api.PATH_TYPE_DATASTORE_PROTO)
+
+	// Artifacts installed from the artifact exchange.
+	EXCHANGE_INSTALLED = path_specs.NewSafeDatastorePath("config", "exchange").
+				SetType(api.PATH_TYPE_DATASTORE_PROTO)
+
 	NOTEBOOK_ROOT = path_specs.NewSafeDatastorePath("notebooks").
 			SetType(api.PATH_TYPE_DATASTORE_JSON)
 
@@ -45,6 +70,14 @@ var (
 	ACL_ROOT = path_specs.NewUnsafeDatastorePath("acl").
 			SetType(api.PATH_TYPE_DATASTORE_JSON)
 
+	// API keys for service accounts, stored in the root org.
+	API_KEYS_ROOT = path_specs.NewSafeDatastorePath("api_keys").
+			SetType(api.PATH_TYPE_DATASTORE_PROTO)
+
+	// Custom role definitions.
+	ROLES_ROOT = path_specs.NewUnsafeDatastorePath("roles").
+			SetType(api.PATH_TYPE_DATASTORE_JSON)
+
 	ORGS_ROOT = path_specs.NewSafeDatastorePath("orgs").
 			SetType(api.PATH_TYPE_DATASTORE_JSON)
 
@@ -86,6 +86,11 @@ func (self FlowPathManager) UploadContainer() api.FSPathSpec {
 		SetType(api.PATH_TYPE_FILESTORE_ANY)
 }
 
+// Text extracted from the flow's uploads by the file indexer.
+func (self FlowPathManager) TextIndex() api.FSPathSpec {
+	return self.Path().AddChild("text_index").AsFilestorePath()
+}
+
 func (self FlowPathManager) GetDownloadsDirectory() api.FSPathSpec {
 	return DOWNLOADS_ROOT.AddUnsafeChild(self.client_id, self.flow_id)
 }
@@ -114,6 +119,17 @@ func (self FlowPathManager) GetReportsFile(hostname string) api.FSPathSpec {
 		SetType(api.PATH_TYPE_FILESTORE_DOWNLOAD_REPORT)
 }
 
+func (self FlowPathManager) GetSTIXFile(hostname string) api.FSPathSpec {
+	// If there is no hostname we drop the leading -
+	if hostname != "" {
+		hostname += "-"
+	}
+	return DOWNLOADS_ROOT.AddUnsafeChild(self.client_id, self.flow_id,
+		fmt.Sprintf("STIX %v%v-%v", hostname,
+			self.client_id, self.flow_id)).
+		SetType(api.PATH_TYPE_FILESTORE_JSON)
+}
+
 // Where to store the uploaded file in the filestore.
 func (self FlowPathManager) GetUploadsFile(
 	accessor, client_path string) *UploadFile {
@@ -30,6 +30,12 @@ func (self *NotebookPathManager) Path() api.DSPathSpec {
 	return self.root.AddChild(self.notebook_id).SetTag("Notebook")
 }
 
+// A log of who edited the notebook.
+func (self *NotebookPathManager) ActivityLog() api.FSPathSpec {
+	return self.root.AddChild(self.notebook_id, "activity").
+		AsFilestorePath().SetTag("NotebookActivity")
+}
+
 func (self *NotebookPathManager) UploadsDir() api.FSPathSpec {
 	return self.root.AsFilestorePath().
 		AddUnsafeChild(self.notebook_id, "uploads").
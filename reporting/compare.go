@@ -0,0 +1,247 @@
+package reporting
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	"github.com/alexmullins/zip"
+	"github.com/pkg/errors"
+	"www.velocidex.com/golang/velociraptor/utils"
+)
+
+// Compare two collection containers (e.g. collected from the same
+// host before and after remediation) and report which members and
+// results changed between them.
+
+const (
+	CHANGE_ADDED     = "added"
+	CHANGE_REMOVED   = "removed"
+	CHANGE_MODIFIED  = "modified"
+	CHANGE_UNCHANGED = "unchanged"
+
+	MEMBER_METADATA = "metadata"
+	MEMBER_RESULT   = "result"
+	MEMBER_UPLOAD   = "upload"
+	MEMBER_OTHER    = "other"
+)
+
+type ContainerSummary struct {
+	Path      string `json:"path"`
+	Hostname  string `json:"hostname"`
+	ClientId  string `json:"client_id,omitempty"`
+	Timestamp int64  `json:"timestamp"`
+	Members   int    `json:"members"`
+	Rows      int64  `json:"rows"`
+}
+
+type MemberDiff struct {
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	Change       string `json:"change"`
+	FirstSize    uint64 `json:"first_size"`
+	SecondSize   uint64 `json:"second_size"`
+	FirstRows    int64  `json:"first_rows"`
+	SecondRows   int64  `json:"second_rows"`
+	FirstSha256  string `json:"first_sha256,omitempty"`
+	SecondSha256 string `json:"second_sha256,omitempty"`
+}
+
+type ContainerComparison struct {
+	First  *ContainerSummary `json:"first"`
+	Second *ContainerSummary `json:"second"`
+
+	// Comparing collections from different hosts is usually a
+	// mistake.
+	SameHost bool `json:"same_host"`
+
+	Added     int `json:"added"`
+	Removed   int `json:"removed"`
+	Modified  int `json:"modified"`
+	Unchanged int `json:"unchanged"`
+
+	// All members of both containers sorted by name.
+	Members []*MemberDiff `json:"members"`
+}
+
+type containerMember struct {
+	size   uint64
+	rows   int64
+	sha256 string
+}
+
+func CompareContainers(
+	ctx context.Context, first_path, second_path string) (
+	*ContainerComparison, error) {
+	first, first_members, err := readContainerMembers(ctx, first_path)
+	if err != nil {
+		return nil, err
+	}
+
+	second, second_members, err := readContainerMembers(ctx, second_path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ContainerComparison{
+		First:  first,
+		Second: second,
+		SameHost: first.Hostname == second.Hostname &&
+			first.ClientId == second.ClientId,
+	}
+
+	names := []string{}
+	for name := range first_members {
+		names = append(names, name)
+	}
+	for name := range second_members {
+		_, pres := first_members[name]
+		if !pres {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		diff := &MemberDiff{
+			Name: name,
+			Type: memberType(name),
+		}
+
+		first_member, first_pres := first_members[name]
+		if first_pres {
+			diff.FirstSize = first_member.size
+			diff.FirstRows = first_member.rows
+			diff.FirstSha256 = first_member.sha256
+		}
+
+		second_member, second_pres := second_members[name]
+		if second_pres {
+			diff.SecondSize = second_member.size
+			diff.SecondRows = second_member.rows
+			diff.SecondSha256 = second_member.sha256
+		}
+
+		switch {
+		case !first_pres:
+			diff.Change = CHANGE_ADDED
+			result.Added++
+		case !second_pres:
+			diff.Change = CHANGE_REMOVED
+			result.Removed++
+		case diff.FirstSha256 != diff.SecondSha256:
+			diff.Change = CHANGE_MODIFIED
+			result.Modified++
+		default:
+			diff.Change = CHANGE_UNCHANGED
+			result.Unchanged++
+		}
+
+		result.Members = append(result.Members, diff)
+	}
+
+	return result, nil
+}
+
+// Hash every member of the container and count the rows in each
+// result set.
+func readContainerMembers(ctx context.Context, path string) (
+	*ContainerSummary, map[string]*containerMember, error) {
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, path)
+	}
+	defer reader.Close()
+
+	summary := &ContainerSummary{Path: path}
+	members := make(map[string]*containerMember)
+
+	for _, f := range reader.File {
+		if f.IsEncrypted() {
+			return nil, nil, errors.Errorf(
+				"Container %v is encrypted. Decrypt it before comparing.",
+				path)
+		}
+
+		if strings.HasSuffix(f.Name, "/") {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		default:
+		}
+
+		member, err := hashMember(ctx, f)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, f.Name)
+		}
+
+		if memberType(f.Name) != MEMBER_RESULT {
+			member.rows = 0
+		}
+		summary.Rows += member.rows
+		members[f.Name] = member
+	}
+	summary.Members = len(members)
+
+	// Older containers may not have metadata.
+	metadata := &ContainerMetadata{}
+	err = readJsonMember(&reader.Reader, CONTAINER_METADATA, metadata)
+	if err == nil {
+		summary.Hostname = metadata.Hostname
+		summary.ClientId = metadata.ClientId
+		summary.Timestamp = metadata.Timestamp
+	}
+
+	return summary, members, nil
+}
+
+func hashMember(ctx context.Context, f *zip.File) (*containerMember, error) {
+	fd, err := f.Open()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer fd.Close()
+
+	sha_sum := sha256.New()
+	counter := &lineCounter{}
+	n, err := utils.Copy(ctx, utils.NewTee(sha_sum, counter), fd)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &containerMember{
+		size:   uint64(n),
+		rows:   counter.lines,
+		sha256: hex.EncodeToString(sha_sum.Sum(nil)),
+	}, nil
+}
+
+func memberType(name string) string {
+	switch {
+	case name == CONTAINER_METADATA:
+		return MEMBER_METADATA
+	case strings.HasPrefix(name, "uploads/"):
+		return MEMBER_UPLOAD
+	case strings.HasSuffix(name, ".json"):
+		return MEMBER_RESULT
+	default:
+		return MEMBER_OTHER
+	}
+}
+
+// Result sets are stored as line delimited JSON so each line is a
+// row.
+type lineCounter struct {
+	lines int64
+}
+
+func (self *lineCounter) Write(b []byte) (int, error) {
+	self.lines += int64(bytes.Count(b, []byte{'\n'}))
+	return len(b), nil
+}
@@ -0,0 +1,82 @@
+package reporting
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"www.velocidex.com/golang/velociraptor/config"
+)
+
+func writeTestContainer(t *testing.T, path string, members map[string]string) {
+	container, err := NewContainer(config.GetDefaultConfig(), path, "", 5)
+	require.NoError(t, err)
+
+	for name, data := range members {
+		fd, err := container.Create(name, time.Now())
+		require.NoError(t, err)
+		_, err = fd.Write([]byte(data))
+		require.NoError(t, err)
+		fd.Close()
+	}
+
+	require.NoError(t, container.WriteMetadata(&ContainerMetadata{
+		Hostname: "TestHost",
+	}))
+	require.NoError(t, container.Close())
+}
+
+func TestCompareContainers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "compare")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	before := filepath.Join(dir, "before.zip")
+	writeTestContainer(t, before, map[string]string{
+		"Windows.System.Services.json": "{\"Name\":\"A\"}\n{\"Name\":\"Evil\"}\n",
+		"Generic.Client.Info.json":     "{\"Hostname\":\"TestHost\"}\n",
+		"uploads/auto/C%3A/evil.exe":   "MZ",
+	})
+
+	after := filepath.Join(dir, "after.zip")
+	writeTestContainer(t, after, map[string]string{
+		"Windows.System.Services.json": "{\"Name\":\"A\"}\n",
+		"Generic.Client.Info.json":     "{\"Hostname\":\"TestHost\"}\n",
+		"uploads/auto/C%3A/log.txt":    "cleaned",
+	})
+
+	comparison, err := CompareContainers(context.Background(), before, after)
+	require.NoError(t, err)
+
+	assert.True(t, comparison.SameHost)
+	assert.Equal(t, 1, comparison.Added)
+	assert.Equal(t, 1, comparison.Removed)
+	assert.Equal(t, 1, comparison.Modified)
+
+	// The metadata and Generic.Client.Info are the same.
+	assert.Equal(t, 2, comparison.Unchanged)
+	assert.Equal(t, int64(3), comparison.First.Rows)
+	assert.Equal(t, int64(2), comparison.Second.Rows)
+
+	changes := make(map[string]*MemberDiff)
+	for _, member := range comparison.Members {
+		changes[member.Name] = member
+	}
+
+	services := changes["Windows.System.Services.json"]
+	require.NotNil(t, services)
+	assert.Equal(t, CHANGE_MODIFIED, services.Change)
+	assert.Equal(t, MEMBER_RESULT, services.Type)
+	assert.Equal(t, int64(2), services.FirstRows)
+	assert.Equal(t, int64(1), services.SecondRows)
+
+	assert.Equal(t, CHANGE_REMOVED, changes["uploads/auto/C%3A/evil.exe"].Change)
+	assert.Equal(t, CHANGE_ADDED, changes["uploads/auto/C%3A/log.txt"].Change)
+	assert.Equal(t, MEMBER_UPLOAD, changes["uploads/auto/C%3A/log.txt"].Type)
+	assert.Equal(t, int64(0), changes["uploads/auto/C%3A/log.txt"].SecondRows)
+}
@@ -12,6 +12,7 @@ import (
 	"path"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/alexmullins/zip"
@@ -61,7 +62,8 @@ type Container struct {
 
 	// If a password is set, we create a new zip file here, and a
 	// member within it then redirect the zip above to write on
-	// it.
+	// it. This way member names and the metadata are encrypted
+	// together with the data.
 	delegate_zip *zip.Writer
 	delegate_fd  io.Writer
 
@@ -71,6 +73,24 @@ type Container struct {
 	// Keep track of all writers so we can safely close the container.
 	writer_wg sync.WaitGroup
 	closed    bool
+
+	// Total rows stored (accessed atomically).
+	rows int64
+}
+
+// Encrypted containers do not reveal the names of their members.
+func (self *Container) IsEncrypted() bool {
+	return self.delegate_zip != nil
+}
+
+// The number of result rows stored in the container so far.
+func (self *Container) RowsWritten() int64 {
+	return atomic.LoadInt64(&self.rows)
+}
+
+// The number of bytes written to the container file so far.
+func (self *Container) BytesWritten() int64 {
+	return int64(self.writer.Count())
 }
 
 func (self *Container) Create(name string, mtime time.Time) (io.WriteCloser, error) {
@@ -96,6 +116,34 @@ func (self *Container) Create(name string, mtime time.Time) (io.WriteCloser, err
 	}, nil
 }
 
+// The name of the container member describing the collection.
+const CONTAINER_METADATA = "metadata.json"
+
+// Describes where the collection came from so it can be imported
+// into the server later.
+type ContainerMetadata struct {
+	Hostname  string   `json:"hostname"`
+	ClientId  string   `json:"client_id,omitempty"`
+	Timestamp int64    `json:"timestamp"`
+	Artifacts []string `json:"artifacts"`
+}
+
+func (self *Container) WriteMetadata(metadata *ContainerMetadata) error {
+	serialized, err := json.MarshalIndent(metadata)
+	if err != nil {
+		return err
+	}
+
+	fd, err := self.Create(CONTAINER_METADATA, time.Time{})
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	_, err = fd.Write(serialized)
+	return err
+}
+
 func (self *Container) StoreArtifact(
 	config_obj *config_proto.Config,
 	ctx context.Context,
@@ -174,6 +222,7 @@ func (self *Container) StoreArtifact(
 			if err != nil {
 				return errors.WithStack(err)
 			}
+			atomic.AddInt64(&self.rows, 1)
 
 			if csv_writer != nil {
 				csv_writer.Write(row)
@@ -224,8 +273,15 @@ func (self *Container) Upload(
 
 	sanitized_name := sanitize_upload_name(store_as_name)
 
-	scope.Log("Collecting file %s into %s (%v bytes)",
-		filename.String(), store_as_name, expected_size)
+	// Do not leak the names of collected files into the (plain text)
+	// logs when the container is encrypted.
+	if self.IsEncrypted() {
+		scope.Log("Collecting file into encrypted container (%v bytes)",
+			expected_size)
+	} else {
+		scope.Log("Collecting file %s into %s (%v bytes)",
+			filename.String(), store_as_name, expected_size)
+	}
 
 	// Try to collect sparse files if possible
 	result, err := self.maybeCollectSparseFile(
@@ -312,7 +368,8 @@ func (self *Container) maybeCollectSparseFile(
 			}, err
 		}
 
-		run_writer := utils.NewTee(writer, sha_sum, md5_sum)
+		range_sum := sha256.New()
+		run_writer := utils.NewTee(writer, sha_sum, md5_sum, range_sum)
 		n, err := utils.CopyN(ctx, run_writer, range_reader, rng.Length)
 		if err != nil {
 			return &uploads.UploadResponse{
@@ -331,6 +388,10 @@ func (self *Container) maybeCollectSparseFile(
 				ctx, run_writer, utils.ZeroReader{}, rng.Length-int64(n))
 		}
 
+		// Each run is hashed so it can be verified on its own.
+		index.Ranges[len(index.Ranges)-1].Sha256 = hex.EncodeToString(
+			range_sum.Sum(nil))
+
 		count += n
 	}
 
@@ -405,6 +466,27 @@ func (self *Container) Close() error {
 func NewContainer(
 	config_obj *config_proto.Config,
 	path string, password string, level int64) (*Container, error) {
+	return newContainer(config_obj, path, password, level, nil)
+}
+
+// Create a container in split credential mode. The container is
+// encrypted with a random password which is only stored encrypted
+// with the public key. Use DecryptContainer() with the private key
+// to read it.
+func NewEscrowedContainer(
+	config_obj *config_proto.Config,
+	path string, public_key_pem string, level int64) (*Container, error) {
+	password, key, err := newEscrowedPassword(public_key_pem)
+	if err != nil {
+		return nil, err
+	}
+	return newContainer(config_obj, path, password, level, key)
+}
+
+func newContainer(
+	config_obj *config_proto.Config,
+	path string, password string, level int64,
+	key *ContainerKey) (*Container, error) {
 	fd, err := os.OpenFile(
 		path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
 	if err != nil {
@@ -429,10 +511,18 @@ func NewContainer(
 	if password != "" {
 		result.delegate_zip = zip.NewWriter(result.writer)
 
+		// The key must be written before the data member.
+		if key != nil {
+			err = writeContainerKey(result.delegate_zip, key)
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		// We are writing a zip file into here - no need to
 		// compress.
 		fh := &zip.FileHeader{
-			Name:   "data.zip",
+			Name:   CONTAINER_DATA,
 			Method: zip.Store,
 		}
 		fh.SetPassword(password)
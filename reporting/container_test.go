@@ -0,0 +1,62 @@
+package reporting
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"www.velocidex.com/golang/velociraptor/config"
+)
+
+// Member names and the metadata must not be visible without the
+// password.
+func TestEncryptedContainerHidesNames(t *testing.T) {
+	dir, err := ioutil.TempDir("", "container")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	container_path := filepath.Join(dir, "collection.zip")
+	container, err := NewContainer(config.GetDefaultConfig(),
+		container_path, "secret", 5)
+	require.NoError(t, err)
+	assert.True(t, container.IsEncrypted())
+
+	member_name := "uploads/auto/C%3A/Users/Secret_Project/passwords.txt"
+	fd, err := container.Create(member_name, time.Now())
+	require.NoError(t, err)
+	_, err = fd.Write([]byte("hunter2"))
+	require.NoError(t, err)
+	fd.Close()
+
+	require.NoError(t, container.WriteMetadata(&ContainerMetadata{
+		Hostname:  "SecretHostName",
+		Artifacts: []string{"Windows.KapeFiles.Targets"},
+	}))
+	require.NoError(t, container.Close())
+
+	data, err := ioutil.ReadFile(container_path)
+	require.NoError(t, err)
+
+	for _, secret := range []string{
+		"Secret_Project", "hunter2", CONTAINER_METADATA,
+		"SecretHostName", "Windows.KapeFiles.Targets"} {
+		assert.False(t, strings.Contains(string(data), secret), secret)
+	}
+
+	// Only the encrypted data member is visible.
+	reader, err := zip.OpenReader(container_path)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	names := []string{}
+	for _, f := range reader.File {
+		names = append(names, f.Name)
+	}
+	assert.Equal(t, []string{CONTAINER_DATA}, names)
+}
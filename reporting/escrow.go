@@ -0,0 +1,265 @@
+package reporting
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/alexmullins/zip"
+	"github.com/pkg/errors"
+	crypto_utils "www.velocidex.com/golang/velociraptor/crypto/utils"
+	"www.velocidex.com/golang/velociraptor/json"
+)
+
+// In split credential mode the container password is random and
+// only stored encrypted with the public key in this member of the
+// outer (unencrypted) zip. The collector never holds the private
+// key so a captured collector or container can not be decrypted.
+const (
+	CONTAINER_KEY    = "key.json"
+	CONTAINER_DATA   = "data.zip"
+	KEY_SCHEME_OAEP  = "rsa-oaep-sha256"
+	escrow_pass_size = 32
+)
+
+type ContainerKey struct {
+	Scheme            string `json:"scheme"`
+	EncryptedPassword string `json:"encrypted_password"`
+
+	// SHA256 of the DER encoded public key so the right private key
+	// can be found.
+	KeyFingerprint string `json:"key_fingerprint"`
+}
+
+// Accepts a PEM encoded RSA public key (PKIX or PKCS1) or a
+// certificate.
+func ParsePublicKey(pem_str string) (*rsa.PublicKey, error) {
+	data := []byte(pem_str)
+	for {
+		block, rest := pem.Decode(data)
+		if block == nil {
+			return nil, errors.New("No RSA public key found in PEM")
+		}
+		data = rest
+
+		var key interface{}
+		var err error
+
+		switch block.Type {
+		case "PUBLIC KEY":
+			key, err = x509.ParsePKIXPublicKey(block.Bytes)
+		case "RSA PUBLIC KEY":
+			key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+		case "CERTIFICATE":
+			var cert *x509.Certificate
+			cert, err = x509.ParseCertificate(block.Bytes)
+			if err == nil {
+				key = cert.PublicKey
+			}
+		default:
+			continue
+		}
+
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		rsa_key, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return nil, errors.New("Public key is not an RSA key")
+		}
+		return rsa_key, nil
+	}
+}
+
+func parsePrivateKey(pem_str string) (*rsa.PrivateKey, error) {
+	key, err := crypto_utils.ParseRsaPrivateKeyFromPemStr([]byte(pem_str))
+	if err == nil {
+		return key, nil
+	}
+
+	// Also accept PKCS8 keys as produced by openssl genpkey.
+	block, _ := pem.Decode([]byte(pem_str))
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, err
+	}
+
+	pkcs8_key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	rsa_key, ok := pkcs8_key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("Private key is not an RSA key")
+	}
+	return rsa_key, nil
+}
+
+func keyFingerprint(public_key *rsa.PublicKey) string {
+	der, _ := x509.MarshalPKIXPublicKey(public_key)
+	hash := sha256.Sum256(der)
+	return hex.EncodeToString(hash[:])
+}
+
+// Generate a random container password and encrypt it with the
+// public key.
+func newEscrowedPassword(public_key_pem string) (string, *ContainerKey, error) {
+	public_key, err := ParsePublicKey(public_key_pem)
+	if err != nil {
+		return "", nil, err
+	}
+
+	buf := make([]byte, escrow_pass_size)
+	_, err = rand.Read(buf)
+	if err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+	password := hex.EncodeToString(buf)
+
+	encrypted, err := rsa.EncryptOAEP(sha256.New(), rand.Reader,
+		public_key, []byte(password), nil)
+	if err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+
+	return password, &ContainerKey{
+		Scheme:            KEY_SCHEME_OAEP,
+		EncryptedPassword: base64.StdEncoding.EncodeToString(encrypted),
+		KeyFingerprint:    keyFingerprint(public_key),
+	}, nil
+}
+
+// Recover the password of a container made in split credential mode
+// using the private key.
+func GetContainerPassword(container_path, private_key_pem string) (string, error) {
+	reader, err := zip.OpenReader(container_path)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer reader.Close()
+
+	return getContainerPassword(&reader.Reader, private_key_pem)
+}
+
+func getContainerPassword(
+	reader *zip.Reader, private_key_pem string) (string, error) {
+	private_key, err := parsePrivateKey(private_key_pem)
+	if err != nil {
+		return "", err
+	}
+
+	key := &ContainerKey{}
+	err = readJsonMember(reader, CONTAINER_KEY, key)
+	if err != nil {
+		return "", err
+	}
+
+	if key.Scheme != KEY_SCHEME_OAEP {
+		return "", errors.Errorf("Unsupported key scheme %v", key.Scheme)
+	}
+
+	if key.KeyFingerprint != keyFingerprint(&private_key.PublicKey) {
+		return "", errors.New("Container was encrypted with a different key")
+	}
+
+	encrypted, err := base64.StdEncoding.DecodeString(key.EncryptedPassword)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	password, err := rsa.DecryptOAEP(sha256.New(), rand.Reader,
+		private_key, encrypted, nil)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return string(password), nil
+}
+
+// Decrypt a container made in split credential mode into a plain
+// collection zip at output_path (e.g. for importing into the
+// server).
+func DecryptContainer(
+	container_path, private_key_pem, output_path string) error {
+	reader, err := zip.OpenReader(container_path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer reader.Close()
+
+	password, err := getContainerPassword(&reader.Reader, private_key_pem)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range reader.File {
+		if f.Name != CONTAINER_DATA {
+			continue
+		}
+
+		f.SetPassword(password)
+		in, err := f.Open()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer in.Close()
+
+		out, err := os.OpenFile(output_path,
+			os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer out.Close()
+
+		_, err = io.Copy(out, in)
+		return err
+	}
+
+	return errors.New("Container has no encrypted data")
+}
+
+func writeContainerKey(writer *zip.Writer, key *ContainerKey) error {
+	serialized, err := json.MarshalIndent(key)
+	if err != nil {
+		return err
+	}
+
+	fd, err := writer.Create(CONTAINER_KEY)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = fd.Write(serialized)
+	return err
+}
+
+func readJsonMember(reader *zip.Reader, name string, target interface{}) error {
+	for _, f := range reader.File {
+		if f.Name != name {
+			continue
+		}
+
+		fd, err := f.Open()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer fd.Close()
+
+		data, err := ioutil.ReadAll(io.LimitReader(fd, 1024*1024))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		return json.Unmarshal(data, target)
+	}
+
+	return errors.Errorf("Container has no %v member", name)
+}
@@ -0,0 +1,82 @@
+package reporting
+
+import (
+	"archive/zip"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"www.velocidex.com/golang/velociraptor/config"
+)
+
+func makeTestKey(t *testing.T) (public_pem, private_pem string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	public_pem = string(pem.EncodeToMemory(&pem.Block{
+		Type: "PUBLIC KEY", Bytes: der}))
+	private_pem = string(pem.EncodeToMemory(&pem.Block{
+		Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	return public_pem, private_pem
+}
+
+func TestEscrowedContainer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "escrow")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	public_pem, private_pem := makeTestKey(t)
+	container_path := filepath.Join(dir, "collection.zip")
+
+	config_obj := config.GetDefaultConfig()
+	container, err := NewEscrowedContainer(
+		config_obj, container_path, public_pem, 5)
+	require.NoError(t, err)
+
+	fd, err := container.Create("hello.txt", time.Now())
+	require.NoError(t, err)
+	_, err = fd.Write([]byte("hello world"))
+	require.NoError(t, err)
+	fd.Close()
+	require.NoError(t, container.Close())
+
+	// Decrypt with the private key.
+	output_path := filepath.Join(dir, "decrypted.zip")
+	require.NoError(t, DecryptContainer(container_path, private_pem, output_path))
+
+	reader, err := zip.OpenReader(output_path)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	var found bool
+	for _, f := range reader.File {
+		if f.Name != "hello.txt" {
+			continue
+		}
+		member, err := f.Open()
+		require.NoError(t, err)
+		data, err := ioutil.ReadAll(member)
+		member.Close()
+		require.NoError(t, err)
+
+		assert.Equal(t, "hello world", string(data))
+		found = true
+	}
+	assert.True(t, found)
+
+	// A different private key can not decrypt the container.
+	_, other_private_pem := makeTestKey(t)
+	_, err = GetContainerPassword(container_path, other_private_pem)
+	assert.Error(t, err)
+}
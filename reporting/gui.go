@@ -26,6 +26,7 @@ import (
 	artifacts_proto "www.velocidex.com/golang/velociraptor/artifacts/proto"
 	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
 	"www.velocidex.com/golang/velociraptor/file_store"
+	"www.velocidex.com/golang/velociraptor/i18n"
 	"www.velocidex.com/golang/velociraptor/json"
 	"www.velocidex.com/golang/velociraptor/paths"
 	"www.velocidex.com/golang/velociraptor/result_sets"
@@ -60,6 +61,9 @@ type GuiTemplateEngine struct {
 	Data         map[string]*actions_proto.VQLResponse
 	Progress     utils.ProgressReporter
 	Start        time.Time
+
+	// The language used for messages and the T template function.
+	Lang string
 }
 
 // Go templates can call functions which take args. The pipeline is
@@ -120,6 +124,12 @@ func (self *GuiTemplateEngine) getTableOptions() (*ordereddict.Dict, error) {
 	return options[0], nil
 }
 
+// Translate a message into the engine's language. Templates use
+// this as {{ T "Found %v hits" $count }}.
+func (self *GuiTemplateEngine) Translate(format string, args ...interface{}) string {
+	return i18n.Sprintf(self.Lang, format, args...)
+}
+
 func (self *GuiTemplateEngine) Expand(values ...interface{}) interface{} {
 	_, argv := parseOptions(values)
 	// Not enough args.
@@ -135,7 +145,7 @@ func (self *GuiTemplateEngine) Expand(values ...interface{}) interface{} {
 
 	case []*paths.NotebookCellQuery:
 		if len(t) == 0 { // No rows returned.
-			self.Scope.Log("Query produced no rows.")
+			self.Scope.Log("%s", self.Translate("Query produced no rows."))
 			return results
 		}
 
@@ -154,7 +164,7 @@ func (self *GuiTemplateEngine) Expand(values ...interface{}) interface{} {
 
 	case []*ordereddict.Dict:
 		if len(t) == 0 { // No rows returned.
-			self.Scope.Log("Query produced no rows.")
+			self.Scope.Log("%s", self.Translate("Query produced no rows."))
 			return results
 		}
 		for _, item := range t {
@@ -200,7 +210,7 @@ func (self *GuiTemplateEngine) Table(values ...interface{}) interface{} {
 
 	case []*paths.NotebookCellQuery:
 		if len(t) == 0 { // No rows returned.
-			self.Scope.Log("Query produced no rows.")
+			self.Scope.Log("%s", self.Translate("Query produced no rows."))
 			return ""
 		}
 
@@ -223,7 +233,7 @@ func (self *GuiTemplateEngine) Table(values ...interface{}) interface{} {
 
 	case []*ordereddict.Dict:
 		if len(t) == 0 { // No rows returned.
-			self.Scope.Log("Query produced no rows.")
+			self.Scope.Log("%s", self.Translate("Query produced no rows."))
 			return ""
 		}
 
@@ -467,7 +477,7 @@ func (self *GuiTemplateEngine) Query(queries ...string) interface{} {
 
 		// Specifically trap the empty string.
 		if whitespace_regexp.MatchString(query) {
-			self.Error("Please specify a query to run")
+			self.Error("%s", self.Translate("Please specify a query to run"))
 			return nil
 		}
 
@@ -653,6 +663,7 @@ func NewGuiTemplateEngine(
 		path_manager:       notebook_cell_path_manager,
 		Data:               make(map[string]*actions_proto.VQLResponse),
 		Start:              time.Now(),
+		Lang:               i18n.DEFAULT_LANG,
 	}
 	template_engine.tmpl = template.New("").Funcs(sprig.TxtFuncMap()).Funcs(
 		template.FuncMap{
@@ -668,6 +679,7 @@ func NewGuiTemplateEngine(
 			"Expand":       template_engine.Expand,
 			"import":       template_engine.Import,
 			"str":          strval,
+			"T":            template_engine.Translate,
 		})
 	return template_engine, nil
 }
@@ -359,6 +359,7 @@ func NewHTMLTemplateEngine(
 			"Expand":    template_engine.Expand,
 			"import":    template_engine.Import,
 			"str":       strval,
+			"Translate": template_engine.Translate,
 		})
 	return template_engine, nil
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/Velocidex/ordereddict"
 	artifacts_proto "www.velocidex.com/golang/velociraptor/artifacts/proto"
 	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/json"
 	"www.velocidex.com/golang/velociraptor/logging"
 	"www.velocidex.com/golang/velociraptor/paths"
 	"www.velocidex.com/golang/velociraptor/services"
@@ -47,6 +48,67 @@ func (self *BaseTemplateEngine) Close() {
 	self.Scope.Close()
 }
 
+// Template artifacts (e.g. Reporting.Default) may declare parameters
+// to control branding and localization. The defaults may be
+// overridden when the report is created so each org or export can
+// use its own settings.
+func (self *BaseTemplateEngine) SetTemplateParameters(
+	parameters *ordereddict.Dict) {
+	for _, param := range self.Artifact.Parameters {
+		var value interface{} = param.Default
+		if parameters != nil {
+			override, pres := parameters.Get(param.Name)
+			if pres {
+				value = override
+			}
+		}
+
+		// Allow json parameters to be given as a string.
+		str_value, ok := value.(string)
+		if ok && param.Type == "json" {
+			parsed := ordereddict.NewDict()
+			err := json.Unmarshal([]byte(str_value), parsed)
+			if err == nil {
+				value = parsed
+			}
+		}
+
+		self.SetEnv(param.Name, value)
+	}
+}
+
+// Translate a string using the Translations parameter of the
+// template. Translations are keyed by locale then by the original
+// string. Untranslated strings are returned as is.
+func (self *BaseTemplateEngine) Translate(text string) string {
+	locale, _ := self.Env.GetString("Locale")
+	if locale == "" {
+		return text
+	}
+
+	translations, pres := self.Env.Get("Translations")
+	if !pres {
+		return text
+	}
+
+	for _, key := range []string{locale, strings.Split(locale, "-")[0]} {
+		strings_for_locale, pres := self.Scope.Associative(translations, key)
+		if !pres {
+			continue
+		}
+
+		translated, pres := self.Scope.Associative(strings_for_locale, text)
+		if pres {
+			str, ok := translated.(string)
+			if ok && str != "" {
+				return str
+			}
+		}
+	}
+
+	return text
+}
+
 func (self *BaseTemplateEngine) getFunction(a interface{}, b string,
 	opts ...interface{}) interface{} {
 
@@ -0,0 +1,63 @@
+package responder
+
+import (
+	"os/user"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/host"
+	crypto_proto "www.velocidex.com/golang/velociraptor/crypto/proto"
+)
+
+var (
+	env_mu    sync.Mutex
+	host_info *host.InfoStat
+	username  string
+)
+
+// Getting the host info can be expensive on some platforms so we
+// only do it once. The uptime is derived from the boot time each
+// time so it is always current.
+func getHostInfo() (*host.InfoStat, string) {
+	env_mu.Lock()
+	defer env_mu.Unlock()
+
+	if host_info == nil {
+		info, err := host.Info()
+		if err != nil {
+			info = &host.InfoStat{}
+		}
+		host_info = info
+
+		current, err := user.Current()
+		if err == nil {
+			username = current.Username
+		}
+	}
+
+	return host_info, username
+}
+
+func getClientEnvironment(client_version string) *crypto_proto.ClientEnvironment {
+	info, username := getHostInfo()
+
+	now := time.Now()
+	result := &crypto_proto.ClientEnvironment{
+		ClientVersion:   client_version,
+		Os:              info.OS,
+		Platform:        info.Platform,
+		PlatformVersion: info.PlatformVersion,
+		KernelVersion:   info.KernelVersion,
+		Architecture:    runtime.GOARCH,
+		Hostname:        info.Hostname,
+		User:            username,
+		Timestamp:       uint64(now.UnixNano() / 1000),
+	}
+
+	if info.BootTime > 0 && uint64(now.Unix()) > info.BootTime {
+		result.Uptime = uint64(now.Unix()) - info.BootTime
+	}
+
+	return result
+}
@@ -59,9 +59,10 @@ func (self *PoolEventResponder) NewResponder(
 
 	// Prepare a new responder that will feed us.
 	result := &Responder{
-		request: req,
-		output:  in,
-		logger:  logging.GetLogger(config_obj, &logging.ClientComponent),
+		request:        req,
+		output:         in,
+		logger:         logging.GetLogger(config_obj, &logging.ClientComponent),
+		client_version: getClientVersion(config_obj),
 	}
 
 	go func() {
@@ -64,6 +64,9 @@ type Responder struct {
 	start_time int64
 	log_id     int64
 
+	// Reported with the status message.
+	client_version string
+
 	// The name of the query we are currently running.
 	Artifact string
 
@@ -79,21 +82,30 @@ func NewResponder(
 	request *crypto_proto.VeloMessage,
 	output chan *crypto_proto.VeloMessage) *Responder {
 	result := &Responder{
-		request:    request,
-		output:     output,
-		logger:     logging.GetLogger(config_obj, &logging.ClientComponent),
-		start_time: time.Now().UnixNano(),
+		request:        request,
+		output:         output,
+		logger:         logging.GetLogger(config_obj, &logging.ClientComponent),
+		start_time:     time.Now().UnixNano(),
+		client_version: getClientVersion(config_obj),
 	}
 	return result
 }
 
 func (self *Responder) Copy() *Responder {
 	return &Responder{
-		request:    self.request,
-		output:     self.output,
-		logger:     self.logger,
-		start_time: time.Now().UnixNano(),
+		request:        self.request,
+		output:         self.output,
+		logger:         self.logger,
+		start_time:     time.Now().UnixNano(),
+		client_version: self.client_version,
+	}
+}
+
+func getClientVersion(config_obj *config_proto.Config) string {
+	if config_obj == nil || config_obj.Version == nil {
+		return ""
 	}
+	return config_obj.Version.Version
 }
 
 func (self *Responder) updateStats(message *crypto_proto.VeloMessage) {
@@ -123,6 +135,7 @@ func (self *Responder) getStatus() *crypto_proto.VeloStatus {
 		LogRows:           self.log_rows,
 		ResultRows:        self.result_rows,
 		Duration:          time.Now().UnixNano() - self.start_time,
+		Environment:       getClientEnvironment(self.client_version),
 	}
 
 	if self.request.VQLClientAction != nil {
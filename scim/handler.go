@@ -0,0 +1,206 @@
+package scim
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/logging"
+)
+
+const (
+	DEFAULT_COUNT = 100
+	MAX_COUNT     = 1000
+)
+
+type scimError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+}
+
+// The handler serves the SCIM endpoint under prefix. Identity
+// providers authenticate with the configured bearer token rather
+// than the GUI's authenticator.
+func NewHandler(config_obj *config_proto.Config, prefix string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !checkToken(config_obj, r) {
+			logging.GetLogger(config_obj, &logging.Audit).
+				WithFields(logrus.Fields{
+					"remote": r.RemoteAddr,
+					"method": r.Method,
+				}).Error("SCIM request rejected")
+
+			writeError(w, http.StatusUnauthorized, "", "Invalid bearer token")
+			return
+		}
+
+		path := strings.Trim(strings.TrimPrefix(r.URL.Path, prefix), "/")
+		components := strings.Split(path, "/")
+
+		switch {
+		case path == "ServiceProviderConfig" && r.Method == http.MethodGet:
+			writeJSON(w, http.StatusOK, serviceProviderConfig())
+
+		case path == "Users" && r.Method == http.MethodGet:
+			listUsers(config_obj, w, r)
+
+		case path == "Users" && r.Method == http.MethodPost:
+			user := &User{}
+			if !readJSON(w, r, user) {
+				return
+			}
+			result, err := CreateUser(config_obj, user)
+			writeResult(w, http.StatusCreated, result, err)
+
+		case len(components) == 2 && components[0] == "Users":
+			handleUser(config_obj, components[1], w, r)
+
+		default:
+			writeError(w, http.StatusNotFound, "", "Unsupported endpoint")
+		}
+	})
+}
+
+func handleUser(config_obj *config_proto.Config, username string,
+	w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		result, err := GetUser(config_obj, username)
+		writeResult(w, http.StatusOK, result, err)
+
+	case http.MethodPut:
+		user := &User{}
+		if !readJSON(w, r, user) {
+			return
+		}
+		result, err := ReplaceUser(config_obj, username, user)
+		writeResult(w, http.StatusOK, result, err)
+
+	case http.MethodPatch:
+		patch := &PatchRequest{}
+		if !readJSON(w, r, patch) {
+			return
+		}
+		result, err := PatchUser(config_obj, username, patch)
+		writeResult(w, http.StatusOK, result, err)
+
+	case http.MethodDelete:
+		err := DeleteUser(config_obj, username)
+		if err != nil {
+			writeResult(w, http.StatusNoContent, nil, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "", "Unsupported method")
+	}
+}
+
+func listUsers(config_obj *config_proto.Config,
+	w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	start_index, _ := strconv.Atoi(query.Get("startIndex"))
+	count := DEFAULT_COUNT
+	if query.Get("count") != "" {
+		count, _ = strconv.Atoi(query.Get("count"))
+	}
+	if count > MAX_COUNT {
+		count = MAX_COUNT
+	}
+
+	result, err := ListUsers(config_obj, query.Get("filter"), start_index, count)
+	writeResult(w, http.StatusOK, result, err)
+}
+
+func checkToken(config_obj *config_proto.Config, r *http.Request) bool {
+	if config_obj.Scim == nil || config_obj.Scim.BearerToken == "" {
+		return false
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare(
+		[]byte(token), []byte(config_obj.Scim.BearerToken)) == 1
+}
+
+func serviceProviderConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"schemas": []string{
+			"urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"},
+		"patch":          map[string]bool{"supported": true},
+		"bulk":           map[string]interface{}{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]interface{}{"supported": true, "maxResults": MAX_COUNT},
+		"changePassword": map[string]bool{"supported": true},
+		"sort":           map[string]bool{"supported": false},
+		"etag":           map[string]bool{"supported": false},
+		"authenticationSchemes": []map[string]string{{
+			"type":        "oauthbearertoken",
+			"name":        "OAuth Bearer Token",
+			"description": "Authentication with the configured bearer token",
+		}},
+	}
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, target interface{}) bool {
+	data, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, 1024*1024))
+	if err == nil {
+		err = json.Unmarshal(data, target)
+	}
+
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalidSyntax", err.Error())
+		return false
+	}
+	return true
+}
+
+func writeResult(w http.ResponseWriter, status int,
+	result interface{}, err error) {
+	switch {
+	case err == nil:
+		writeJSON(w, status, result)
+
+	case errors.Is(err, NotFoundError):
+		writeError(w, http.StatusNotFound, "", err.Error())
+
+	case errors.Is(err, ConflictError):
+		writeError(w, http.StatusConflict, "uniqueness", err.Error())
+
+	case errors.Is(err, InvalidError):
+		writeError(w, http.StatusBadRequest, "invalidValue", err.Error())
+
+	default:
+		writeError(w, http.StatusInternalServerError, "", err.Error())
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, scim_type, detail string) {
+	writeJSON(w, status, &scimError{
+		Schemas:  []string{ERROR_SCHEMA},
+		Status:   fmt.Sprintf("%d", status),
+		ScimType: scim_type,
+		Detail:   detail,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	serialized, err := json.Marshal(value)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/scim+json")
+	w.WriteHeader(status)
+	_, _ = w.Write(serialized)
+}
@@ -0,0 +1,561 @@
+// A SCIM 2.0 (RFC 7643/7644) server so identity providers can
+// provision and deprovision users automatically.
+//
+// The SCIM id of a user is their username. Roles are given as SCIM
+// role values - either a plain role (e.g. "investigator") which
+// applies to the root org, or "<org id>:<role>" for other orgs. When a
+// user's roles are replaced they lose access to any org they no
+// longer have a role in. Deactivating a user locks their account and
+// deleting a user removes them and their ACLs from all orgs.
+
+package scim
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"www.velocidex.com/golang/velociraptor/acls"
+	acl_proto "www.velocidex.com/golang/velociraptor/acls/proto"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/datastore"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/paths"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/services/users"
+)
+
+const (
+	USER_SCHEMA  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	LIST_SCHEMA  = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	PATCH_SCHEMA = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	ERROR_SCHEMA = "urn:ietf:params:scim:api:messages:2.0:Error"
+
+	// Changes made through SCIM are audited as this principal.
+	PRINCIPAL = "scim"
+)
+
+var (
+	// Callers can check for these with errors.Is()
+	NotFoundError = errors.New("User not found")
+	ConflictError = errors.New("User already exists")
+	InvalidError  = errors.New("Invalid request")
+)
+
+type MultiValue struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+type Meta struct {
+	ResourceType string `json:"resourceType"`
+}
+
+// Attributes we do not support are ignored.
+type User struct {
+	Schemas  []string     `json:"schemas"`
+	Id       string       `json:"id,omitempty"`
+	UserName string       `json:"userName"`
+	Active   *bool        `json:"active,omitempty"`
+	Password string       `json:"password,omitempty"`
+	Emails   []MultiValue `json:"emails,omitempty"`
+
+	// Nil when the request does not mention roles.
+	Roles []MultiValue `json:"roles"`
+
+	Meta *Meta `json:"meta,omitempty"`
+}
+
+type ListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []*User  `json:"Resources"`
+}
+
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+func GetUser(config_obj *config_proto.Config, username string) (*User, error) {
+	user_record, err := services.GetUserManager().GetUser(username)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", NotFoundError, username)
+	}
+
+	return toSCIM(user_record), nil
+}
+
+// Only the userName eq "name" filter is supported since this is what
+// identity providers use to find existing users.
+func ListUsers(config_obj *config_proto.Config,
+	filter string, start_index, count int) (*ListResponse, error) {
+	filter_username, err := parseFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	user_records, err := services.GetUserManager().ListUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(user_records, func(i, j int) bool {
+		return user_records[i].Name < user_records[j].Name
+	})
+
+	matching := []*api_proto.VelociraptorUser{}
+	for _, user_record := range user_records {
+		if filter != "" && !strings.EqualFold(user_record.Name, filter_username) {
+			continue
+		}
+		matching = append(matching, user_record)
+	}
+
+	// startIndex is 1 based.
+	if start_index < 1 {
+		start_index = 1
+	}
+
+	result := &ListResponse{
+		Schemas:      []string{LIST_SCHEMA},
+		TotalResults: len(matching),
+		StartIndex:   start_index,
+		Resources:    []*User{},
+	}
+
+	for i := start_index - 1; i < len(matching) && len(result.Resources) < count; i++ {
+		result.Resources = append(result.Resources, toSCIM(matching[i]))
+	}
+	result.ItemsPerPage = len(result.Resources)
+
+	return result, nil
+}
+
+func parseFilter(filter string) (string, error) {
+	if filter == "" {
+		return "", nil
+	}
+
+	parts := strings.SplitN(strings.TrimSpace(filter), " ", 3)
+	if len(parts) != 3 ||
+		!strings.EqualFold(parts[0], "userName") ||
+		!strings.EqualFold(parts[1], "eq") {
+		return "", fmt.Errorf("%w: unsupported filter %v", InvalidError, filter)
+	}
+
+	return strings.Trim(parts[2], "\""), nil
+}
+
+func CreateUser(config_obj *config_proto.Config, user *User) (*User, error) {
+	users_manager := services.GetUserManager()
+	_, err := users_manager.GetUser(user.UserName)
+	if err == nil {
+		return nil, fmt.Errorf("%w: %v", ConflictError, user.UserName)
+	}
+
+	user_record, err := users.NewUserRecord(user.UserName)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", InvalidError, err)
+	}
+
+	// Users who authenticate with SSO do not need a password but set
+	// a random one to prevent login if the authenticator is changed
+	// to a password based one.
+	password := user.Password
+	if password == "" {
+		buf := make([]byte, 32)
+		_, err = rand.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		password = string(buf)
+	}
+	users.SetPassword(user_record, password)
+
+	err = updateUser(config_obj, user_record, user)
+	if err != nil {
+		return nil, err
+	}
+
+	auditSCIM(config_obj, user_record.Name, "SCIMCreateUser")
+
+	return toSCIM(user_record), nil
+}
+
+// Replace the user's attributes. Roles are only changed if the
+// request includes them.
+func ReplaceUser(config_obj *config_proto.Config,
+	username string, user *User) (*User, error) {
+	user_record, err := services.GetUserManager().GetUserWithHashes(username)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", NotFoundError, username)
+	}
+
+	if user.UserName != "" && user.UserName != user_record.Name {
+		return nil, fmt.Errorf("%w: userName can not be changed", InvalidError)
+	}
+
+	if user.Password != "" {
+		users.SetPassword(user_record, user.Password)
+	}
+
+	err = updateUser(config_obj, user_record, user)
+	if err != nil {
+		return nil, err
+	}
+
+	auditSCIM(config_obj, user_record.Name, "SCIMUpdateUser")
+
+	return toSCIM(user_record), nil
+}
+
+// Apply a PatchOp to the user. Operations may either name the
+// attribute in the path or give a value object of attributes.
+func PatchUser(config_obj *config_proto.Config,
+	username string, patch *PatchRequest) (*User, error) {
+	current, err := GetUser(config_obj, username)
+	if err != nil {
+		return nil, err
+	}
+
+	// Roles are only rewritten if an operation touches them.
+	update := &User{Active: current.Active}
+
+	for _, op := range patch.Operations {
+		values := make(map[string]interface{})
+		if op.Path != "" {
+			values[op.Path] = op.Value
+		} else if value, ok := op.Value.(map[string]interface{}); ok {
+			values = value
+		}
+
+		for path, value := range values {
+			switch strings.ToLower(path) {
+			case "active":
+				active, err := parseBool(value)
+				if err != nil {
+					return nil, err
+				}
+				update.Active = &active
+
+			case "roles":
+				if update.Roles == nil {
+					update.Roles = append([]MultiValue{}, current.Roles...)
+				}
+				update.Roles, err = patchRoles(
+					update.Roles, strings.ToLower(op.Op), value)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return ReplaceUser(config_obj, username, update)
+}
+
+func parseBool(value interface{}) (bool, error) {
+	switch t := value.(type) {
+	case bool:
+		return t, nil
+
+	// Some identity providers send booleans as strings.
+	case string:
+		switch strings.ToLower(t) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("%w: invalid boolean %v", InvalidError, value)
+}
+
+func patchRoles(roles []MultiValue, op string,
+	value interface{}) ([]MultiValue, error) {
+	values := []MultiValue{}
+	items, ok := value.([]interface{})
+	if !ok && value != nil {
+		items = []interface{}{value}
+	}
+
+	for _, item := range items {
+		switch t := item.(type) {
+		case string:
+			values = append(values, MultiValue{Value: t})
+		case map[string]interface{}:
+			role, _ := t["value"].(string)
+			values = append(values, MultiValue{Value: role})
+		default:
+			return nil, fmt.Errorf("%w: invalid role %v", InvalidError, item)
+		}
+	}
+
+	switch op {
+	case "add":
+		return append(roles, values...), nil
+
+	case "replace":
+		return values, nil
+
+	case "remove":
+		// Removing without a value removes all roles.
+		if value == nil {
+			return []MultiValue{}, nil
+		}
+
+		result := []MultiValue{}
+		for _, role := range roles {
+			if !containsRole(values, role.Value) {
+				result = append(result, role)
+			}
+		}
+		return result, nil
+	}
+
+	return nil, fmt.Errorf("%w: unsupported operation %v", InvalidError, op)
+}
+
+func containsRole(roles []MultiValue, value string) bool {
+	for _, role := range roles {
+		if role.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Deprovisioned users are removed from all their orgs.
+func DeleteUser(config_obj *config_proto.Config, username string) error {
+	user_record, err := services.GetUserManager().GetUser(username)
+	if err != nil {
+		return fmt.Errorf("%w: %v", NotFoundError, username)
+	}
+
+	org_manager, err := services.GetOrgManager()
+	if err != nil {
+		return err
+	}
+
+	user_path_manager := paths.NewUserPathManager(username)
+	for _, org_id := range userOrgIds(user_record) {
+		org_config_obj, err := org_manager.GetOrgConfig(org_id)
+		if err != nil {
+			continue
+		}
+
+		db, err := datastore.GetDB(org_config_obj)
+		if err != nil {
+			return err
+		}
+
+		err = db.DeleteSubject(org_config_obj, user_path_manager.ACL())
+		if err != nil {
+			return err
+		}
+	}
+
+	db, err := datastore.GetDB(config_obj)
+	if err != nil {
+		return err
+	}
+
+	err = db.DeleteSubject(config_obj, user_path_manager.Path())
+	if err != nil {
+		return err
+	}
+
+	auditSCIM(config_obj, username, "SCIMDeleteUser")
+
+	return nil
+}
+
+// Apply the SCIM attributes to the user record and store it.
+func updateUser(config_obj *config_proto.Config,
+	user_record *api_proto.VelociraptorUser, user *User) error {
+	if user.Active != nil {
+		user_record.Locked = !*user.Active
+	}
+
+	for _, email := range user.Emails {
+		if email.Primary || user_record.Email == "" {
+			user_record.Email = email.Value
+		}
+	}
+
+	if user.Roles != nil {
+		err := setRoles(user_record, user.Roles)
+		if err != nil {
+			return err
+		}
+	}
+
+	return services.GetUserManager().SetUser(user_record)
+}
+
+// Give the user exactly these roles, adding and removing orgs from
+// the user record as needed.
+func setRoles(user_record *api_proto.VelociraptorUser, roles []MultiValue) error {
+	org_roles := make(map[string][]string)
+	for _, role := range roles {
+		org_id, name := parseRole(role.Value)
+		org_roles[org_id] = append(org_roles[org_id], name)
+	}
+
+	org_manager, err := services.GetOrgManager()
+	if err != nil {
+		return err
+	}
+
+	// Check all the orgs and roles exist before changing
+	// anything. Custom roles are defined per org.
+	org_configs := make(map[string]*config_proto.Config)
+	for org_id, names := range org_roles {
+		org_config_obj, err := org_manager.GetOrgConfig(org_id)
+		if err != nil {
+			return fmt.Errorf("%w: unknown org %v", InvalidError, org_id)
+		}
+		for _, name := range names {
+			if !acls.IsValidRole(org_config_obj, name) {
+				return fmt.Errorf("%w: invalid role %v", InvalidError, name)
+			}
+		}
+		org_configs[org_id] = org_config_obj
+	}
+
+	// Revoke access to orgs which are no longer mentioned.
+	for _, org_id := range userOrgIds(user_record) {
+		_, pres := org_roles[org_id]
+		if pres {
+			continue
+		}
+
+		org_config_obj, err := org_manager.GetOrgConfig(org_id)
+		if err != nil {
+			continue
+		}
+
+		err = acls.GrantRoles(org_config_obj, user_record.Name, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	user_record.Orgs = nil
+	for org_id, names := range org_roles {
+		org_config_obj := org_configs[org_id]
+		err = acls.GrantRoles(org_config_obj, user_record.Name, names)
+		if err != nil {
+			return err
+		}
+
+		user_record.Orgs = append(user_record.Orgs, &api_proto.Org{
+			Name: org_config_obj.OrgName,
+			Id:   org_config_obj.OrgId,
+		})
+	}
+
+	return nil
+}
+
+// Roles in other orgs are prefixed with the org id.
+func parseRole(value string) (string, string) {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) == 2 {
+		org_id := parts[0]
+		if org_id == "root" {
+			org_id = ""
+		}
+		return org_id, parts[1]
+	}
+	return "", value
+}
+
+func formatRole(org_id, role string) string {
+	if org_id == "" {
+		return role
+	}
+	return org_id + ":" + role
+}
+
+// The orgs the user may access, always including the root org since
+// users may be given roles there without being added to it.
+func userOrgIds(user_record *api_proto.VelociraptorUser) []string {
+	result := []string{""}
+	for _, org := range user_record.Orgs {
+		org_id := org.Id
+		if org_id == "root" {
+			org_id = ""
+		}
+		if org_id != "" {
+			result = append(result, org_id)
+		}
+	}
+	return result
+}
+
+func toSCIM(
+	user_record *api_proto.VelociraptorUser) *User {
+	active := !user_record.Locked
+	result := &User{
+		Schemas:  []string{USER_SCHEMA},
+		Id:       user_record.Name,
+		UserName: user_record.Name,
+		Active:   &active,
+		Roles:    []MultiValue{},
+		Meta:     &Meta{ResourceType: "User"},
+	}
+
+	if user_record.Email != "" {
+		result.Emails = []MultiValue{{
+			Value: user_record.Email, Primary: true}}
+	}
+
+	org_manager, err := services.GetOrgManager()
+	if err != nil {
+		return result
+	}
+
+	for _, org_id := range userOrgIds(user_record) {
+		org_config_obj, err := org_manager.GetOrgConfig(org_id)
+		if err != nil {
+			continue
+		}
+
+		policy, err := acls.GetPolicy(org_config_obj, user_record.Name)
+		if err != nil {
+			policy = &acl_proto.ApiClientACL{}
+		}
+
+		for _, role := range policy.Roles {
+			result.Roles = append(result.Roles, MultiValue{
+				Value: formatRole(org_id, role),
+			})
+		}
+	}
+
+	return result
+}
+
+func auditSCIM(config_obj *config_proto.Config, username, action string) {
+	logging.GetLogger(config_obj, &logging.Audit).
+		WithFields(logrus.Fields{
+			"user":     PRINCIPAL,
+			"username": username,
+		}).Info(action)
+}
@@ -0,0 +1,149 @@
+package scim_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"www.velocidex.com/golang/velociraptor/acls"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/file_store/test_utils"
+	"www.velocidex.com/golang/velociraptor/scim"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+type SCIMTestSuite struct {
+	test_utils.TestSuite
+	server *httptest.Server
+}
+
+func (self *SCIMTestSuite) SetupTest() {
+	self.TestSuite.SetupTest()
+
+	self.ConfigObj.Scim = &config_proto.SCIMConfig{
+		BearerToken: "secret",
+	}
+
+	self.server = httptest.NewServer(
+		scim.NewHandler(self.ConfigObj, "/scim/v2/"))
+}
+
+func (self *SCIMTestSuite) TearDownTest() {
+	self.server.Close()
+	self.TestSuite.TearDownTest()
+}
+
+func (self *SCIMTestSuite) request(method, path, token string,
+	body interface{}, result interface{}) int {
+	serialized, err := json.Marshal(body)
+	assert.NoError(self.T(), err)
+
+	req, err := http.NewRequest(method, self.server.URL+"/scim/v2/"+path,
+		bytes.NewReader(serialized))
+	assert.NoError(self.T(), err)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(self.T(), err)
+	defer resp.Body.Close()
+
+	if result != nil {
+		_ = json.NewDecoder(resp.Body).Decode(result)
+	}
+	return resp.StatusCode
+}
+
+func (self *SCIMTestSuite) TestProvisioning() {
+	// A bad token is rejected.
+	status := self.request("GET", "Users", "wrong", nil, nil)
+	assert.Equal(self.T(), http.StatusUnauthorized, status)
+
+	// Create a user with a role in the root org.
+	user := &scim.User{}
+	status = self.request("POST", "Users", "secret", map[string]interface{}{
+		"schemas":  []string{scim.USER_SCHEMA},
+		"userName": "alice",
+		"emails":   []map[string]interface{}{{"value": "alice@example.com", "primary": true}},
+		"roles":    []map[string]interface{}{{"value": "investigator"}},
+	}, user)
+	assert.Equal(self.T(), http.StatusCreated, status)
+	assert.Equal(self.T(), "alice", user.Id)
+	assert.True(self.T(), *user.Active)
+	assert.Equal(self.T(), []scim.MultiValue{{Value: "investigator"}}, user.Roles)
+
+	policy, err := acls.GetPolicy(self.ConfigObj, "alice")
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), []string{"investigator"}, policy.Roles)
+
+	// Creating the user again is a conflict.
+	status = self.request("POST", "Users", "secret", map[string]interface{}{
+		"userName": "alice",
+	}, nil)
+	assert.Equal(self.T(), http.StatusConflict, status)
+
+	// Invalid roles and unknown orgs are rejected.
+	for _, role := range []string{"superuser", "O1234:reader"} {
+		status = self.request("POST", "Users", "secret", map[string]interface{}{
+			"userName": "bob",
+			"roles":    []map[string]interface{}{{"value": role}},
+		}, nil)
+		assert.Equal(self.T(), http.StatusBadRequest, status, role)
+	}
+
+	// Find the user by name.
+	list := &scim.ListResponse{}
+	status = self.request("GET", `Users?filter=userName%20eq%20%22alice%22`,
+		"secret", nil, list)
+	assert.Equal(self.T(), http.StatusOK, status)
+	assert.Equal(self.T(), 1, list.TotalResults)
+	assert.Equal(self.T(), "alice@example.com", list.Resources[0].Emails[0].Value)
+
+	// Deactivating the user locks the account.
+	status = self.request("PATCH", "Users/alice", "secret", map[string]interface{}{
+		"schemas": []string{scim.PATCH_SCHEMA},
+		"Operations": []map[string]interface{}{{
+			"op": "Replace", "value": map[string]interface{}{"active": "False"}}},
+	}, user)
+	assert.Equal(self.T(), http.StatusOK, status)
+	assert.False(self.T(), *user.Active)
+
+	user_record, err := services.GetUserManager().GetUser("alice")
+	assert.NoError(self.T(), err)
+	assert.True(self.T(), user_record.Locked)
+
+	// Change the roles.
+	status = self.request("PATCH", "Users/alice", "secret", map[string]interface{}{
+		"schemas": []string{scim.PATCH_SCHEMA},
+		"Operations": []map[string]interface{}{{
+			"op": "add", "path": "roles",
+			"value": []map[string]interface{}{{"value": "reader"}}}, {
+			"op": "remove", "path": "roles",
+			"value": []map[string]interface{}{{"value": "investigator"}}}},
+	}, user)
+	assert.Equal(self.T(), http.StatusOK, status)
+
+	policy, err = acls.GetPolicy(self.ConfigObj, "alice")
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), []string{"reader"}, policy.Roles)
+
+	// The user stays locked.
+	assert.False(self.T(), *user.Active)
+
+	// Deprovision the user.
+	status = self.request("DELETE", "Users/alice", "secret", nil, nil)
+	assert.Equal(self.T(), http.StatusNoContent, status)
+
+	status = self.request("GET", "Users/alice", "secret", nil, nil)
+	assert.Equal(self.T(), http.StatusNotFound, status)
+
+	_, err = acls.GetPolicy(self.ConfigObj, "alice")
+	assert.Error(self.T(), err)
+}
+
+func TestSCIM(t *testing.T) {
+	suite.Run(t, &SCIMTestSuite{})
+}
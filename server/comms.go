@@ -128,6 +128,7 @@ func PrepareFrontendMux(
 
 	base := config_obj.Frontend.BasePath
 	router.Handle(base+"/healthz", healthz(server_obj))
+	router.Handle(base+"/readyz", readyz(config_obj, server_obj))
 	router.Handle(base+"/server.pem", server_pem(config_obj))
 	router.Handle(base+"/control", RecordHTTPStats(control(config_obj, server_obj)))
 	router.Handle(base+"/reader", RecordHTTPStats(reader(server_obj)))
@@ -144,6 +145,9 @@ func PrepareFrontendMux(
 	return nil
 }
 
+// Liveness probe: the process is up and serving. Backend checks are
+// only done by the readiness probe so a slow datastore does not get
+// a working frontend restarted.
 func healthz(server_obj *Server) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if atomic.LoadInt32(&server_obj.Healthy) == 1 {
@@ -525,6 +529,16 @@ func reader(server_obj *Server) http.Handler {
 		notification, cancel := notifier.ListenForNotification(source)
 		defer cancel()
 
+		// Let API watchers know the client checked in.
+		journal, err := services.GetJournal(org_config_obj)
+		if err == nil {
+			journal.PushRowsToArtifactAsync(org_config_obj,
+				ordereddict.NewDict().
+					Set("ClientId", source).
+					Set("RemoteAddr", req.RemoteAddr),
+				"Server.Internal.ClientCheckin")
+		}
+
 		// Deadlines are designed to ensure that connections
 		// are not blocked for too long (maybe several
 		// minutes). This helps to expire connections when the
@@ -0,0 +1,180 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/datastore"
+	"www.velocidex.com/golang/velociraptor/file_store"
+	"www.velocidex.com/golang/velociraptor/file_store/api"
+	"www.velocidex.com/golang/velociraptor/file_store/path_specs"
+	"www.velocidex.com/golang/velociraptor/grpc_client"
+	"www.velocidex.com/golang/velociraptor/json"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/utils"
+)
+
+const (
+	// Probes should answer well within the orchestrator's timeout.
+	HEALTH_CHECK_TIMEOUT = 5 * time.Second
+)
+
+type healthCheck struct {
+	name  string
+	check func(ctx context.Context, config_obj *config_proto.Config) error
+}
+
+var health_checks = []healthCheck{
+	{"grpc", checkGRPCBackend},
+	{"datastore", checkDatastore},
+	{"filestore", checkFilestore},
+}
+
+// Run all the checks and return their status. The result is nil if
+// all the checks passed.
+func runHealthChecks(
+	ctx context.Context,
+	config_obj *config_proto.Config,
+	server_obj *Server) (*ordereddict.Dict, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, HEALTH_CHECK_TIMEOUT)
+	defer cancel()
+
+	var result_err error
+	result := ordereddict.NewDict()
+
+	if atomic.LoadInt32(&server_obj.Healthy) != 1 {
+		result_err = errors.New("server is not serving")
+		result.Set("server", result_err.Error())
+	} else {
+		result.Set("server", "ok")
+	}
+
+	for _, item := range health_checks {
+		err := item.check(ctx, config_obj)
+		if err != nil {
+			result.Set(item.name, err.Error())
+			result_err = err
+			continue
+		}
+		result.Set(item.name, "ok")
+	}
+
+	return result, result_err
+}
+
+// The master checks its own API server while minions check their
+// connection to the master.
+func checkGRPCBackend(ctx context.Context, config_obj *config_proto.Config) error {
+	frontend_manager, err := services.GetFrontendManager(config_obj)
+	if err != nil {
+		return err
+	}
+
+	api_client, closer, err := frontend_manager.GetMasterAPIClient(ctx)
+	if errors.Is(err, services.FrontendIsMaster) {
+		if config_obj.API == nil {
+			return nil
+		}
+		api_client, closer, err = grpc_client.Factory.GetAPIClient(
+			ctx, config_obj)
+	}
+	if err != nil {
+		return err
+	}
+	defer closer()
+
+	response, err := api_client.Check(ctx, &api_proto.HealthCheckRequest{})
+	if err != nil {
+		return err
+	}
+
+	if response.Status != api_proto.HealthCheckResponse_SERVING {
+		return fmt.Errorf("API server is %v", response.Status)
+	}
+	return nil
+}
+
+// Write and read back a small record.
+func checkDatastore(ctx context.Context, config_obj *config_proto.Config) error {
+	db, err := datastore.GetDB(config_obj)
+	if err != nil {
+		return err
+	}
+
+	path := path_specs.NewSafeDatastorePath("health", healthNodeName(config_obj)).
+		SetType(api.PATH_TYPE_DATASTORE_PROTO)
+
+	record := &api_proto.HealthCheckResponse{
+		Status: api_proto.HealthCheckResponse_SERVING,
+	}
+	err = db.SetSubject(config_obj, path, record)
+	if err != nil {
+		return err
+	}
+
+	return db.GetSubject(config_obj, path, &api_proto.HealthCheckResponse{})
+}
+
+// Make sure we can still write results and uploads.
+func checkFilestore(ctx context.Context, config_obj *config_proto.Config) error {
+	file_store_factory := file_store.GetFileStore(config_obj)
+	if file_store_factory == nil {
+		return errors.New("filestore not configured")
+	}
+
+	path := path_specs.NewSafeFilestorePath("health", healthNodeName(config_obj)).
+		SetType(api.PATH_TYPE_FILESTORE_TMP)
+
+	writer, err := file_store_factory.WriteFileWithCompletion(
+		path, utils.SyncCompleter)
+	if err != nil {
+		return err
+	}
+
+	err = writer.Truncate()
+	if err == nil {
+		_, err = writer.Write([]byte(time.Now().UTC().String()))
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+
+	close_err := writer.Close()
+	if err != nil {
+		return err
+	}
+	return close_err
+}
+
+// Each frontend writes its own probe records.
+func healthNodeName(config_obj *config_proto.Config) string {
+	if config_obj.Frontend == nil {
+		return "server"
+	}
+	return services.GetNodeName(config_obj.Frontend)
+}
+
+// Readiness probe: checks the backends this frontend depends on and
+// reports the status of each check so operators can see why a
+// frontend is not receiving traffic.
+func readyz(config_obj *config_proto.Config, server_obj *Server) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result, err := runHealthChecks(r.Context(), config_obj, server_obj)
+
+		w.Header().Set("Content-Type", "application/json")
+		if err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+		_, _ = w.Write(json.MustMarshalIndent(result))
+	})
+}
@@ -0,0 +1,76 @@
+package server_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mock_proto "www.velocidex.com/golang/velociraptor/api/mock"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/grpc_client"
+	"www.velocidex.com/golang/velociraptor/server"
+	"www.velocidex.com/golang/velociraptor/vtesting/assert"
+)
+
+func (self *ServerTestSuite) TestHealthProbes() {
+	ctrl := gomock.NewController(self.T())
+	defer ctrl.Finish()
+
+	mock := mock_proto.NewMockAPIClient(ctrl)
+	old_factory := grpc_client.Factory
+	defer func() { grpc_client.Factory = old_factory }()
+	grpc_client.Factory = MockAPIClientFactory{mock: mock}
+
+	mux := http.NewServeMux()
+	err := server.PrepareFrontendMux(self.ConfigObj, self.server, mux)
+	require.NoError(self.T(), err)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	// The server is not serving yet.
+	atomic.StoreInt32(&self.server.Healthy, 0)
+	mock.EXPECT().Check(gomock.Any(), gomock.Any()).
+		Return(&api_proto.HealthCheckResponse{
+			Status: api_proto.HealthCheckResponse_SERVING,
+		}, nil).Times(1)
+
+	assert.Equal(self.T(), http.StatusServiceUnavailable, get("/healthz").Code)
+
+	w := get("/readyz")
+	assert.Equal(self.T(), http.StatusServiceUnavailable, w.Code)
+	assert.Regexp(self.T(), `"server": "server is not serving"`, w.Body.String())
+
+	// Everything is up.
+	atomic.StoreInt32(&self.server.Healthy, 1)
+	mock.EXPECT().Check(gomock.Any(), gomock.Any()).
+		Return(&api_proto.HealthCheckResponse{
+			Status: api_proto.HealthCheckResponse_SERVING,
+		}, nil).Times(1)
+
+	assert.Equal(self.T(), http.StatusNoContent, get("/healthz").Code)
+
+	w = get("/readyz")
+	assert.Equal(self.T(), http.StatusOK, w.Code)
+	assert.Regexp(self.T(), `"grpc": "ok"`, w.Body.String())
+	assert.Regexp(self.T(), `"datastore": "ok"`, w.Body.String())
+	assert.Regexp(self.T(), `"filestore": "ok"`, w.Body.String())
+
+	// The API server is down: the frontend is not ready but the
+	// liveness probe does not touch the backends so it stays
+	// healthy.
+	mock.EXPECT().Check(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("connection refused")).Times(1)
+
+	assert.Equal(self.T(), http.StatusNoContent, get("/healthz").Code)
+
+	w = get("/readyz")
+	assert.Equal(self.T(), http.StatusServiceUnavailable, w.Code)
+	assert.Regexp(self.T(), `"grpc": "connection refused"`, w.Body.String())
+}
@@ -36,6 +36,7 @@ import (
 	"www.velocidex.com/golang/velociraptor/flows"
 	"www.velocidex.com/golang/velociraptor/logging"
 	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/services/canary"
 	"www.velocidex.com/golang/velociraptor/utils"
 )
 
@@ -51,6 +52,15 @@ var (
 	})
 )
 
+// The canary feeds its responses through the same flow runner as
+// real clients.
+func init() {
+	canary.RegisterFlowProcessor(
+		func(config_obj *config_proto.Config) canary.MessageProcessor {
+			return flows.NewFlowRunner(config_obj)
+		})
+}
+
 type Server struct {
 	manager *crypto_server.ServerCryptoManager
 	logger  *logging.LogContext
@@ -256,9 +266,35 @@ func (self *Server) Decrypt(ctx context.Context, request []byte) (
 		return nil, err
 	}
 
+	// Account for the bandwidth here because the same message may be
+	// processed several times.
+	updateBytesReceived(message_info, uint64(len(request)))
+
 	return message_info, nil
 }
 
+// Record the bytes received from the client. Clients which are not
+// enrolled yet have no record so this is best effort.
+func updateBytesReceived(message_info *crypto.MessageInfo, received uint64) {
+	org_manager, err := services.GetOrgManager()
+	if err != nil {
+		return
+	}
+
+	config_obj, err := org_manager.GetOrgConfig(message_info.OrgId)
+	if err != nil {
+		return
+	}
+
+	client_info_manager, err := services.GetClientInfoManager(config_obj)
+	if err != nil {
+		return
+	}
+
+	_ = client_info_manager.UpdateStats(message_info.Source,
+		&services.Stats{BytesReceived: received})
+}
+
 func (self *Server) Process(
 	ctx context.Context,
 	message_info *crypto.MessageInfo,
@@ -303,6 +339,7 @@ func (self *Server) Process(
 		tasks, err := client_info_manager.GetClientTasks(message_info.Source)
 		if err == nil {
 			message_list.Job = append(message_list.Job, tasks...)
+			flows.MarkFlowsDelivered(config_obj, message_info.Source, tasks)
 		}
 	}
 
@@ -322,6 +359,18 @@ func (self *Server) Process(
 		return nil, 0, err
 	}
 
+	// When draining requests, callers only send the response if
+	// there are any jobs.
+	if len(message_list.Job) > 0 || !drain_requests_for_client {
+		err = client_info_manager.UpdateStats(message_info.Source,
+			&services.Stats{
+				BytesSent: uint64(len(response)),
+			})
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
 	return response, len(message_list.Job), nil
 }
 
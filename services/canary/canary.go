@@ -0,0 +1,394 @@
+/*
+  The canary service checks the collection pipeline end to end.
+
+  A synthetic client is registered on the server. Periodically the
+  service schedules a trivial collection for it, picks up the tasks
+  from the client's queue as a real client would, runs the VQL and
+  feeds the responses back through the flow runner. The canary passes
+  when the collection completes with results within the timeout.
+
+  The result and latency of each probe are exported as metrics so a
+  frontend which silently stopped processing collections is noticed
+  before analysts are left waiting for their results.
+*/
+
+package canary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"www.velocidex.com/golang/velociraptor/actions"
+	actions_proto "www.velocidex.com/golang/velociraptor/actions/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	crypto_proto "www.velocidex.com/golang/velociraptor/crypto/proto"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/responder"
+	"www.velocidex.com/golang/velociraptor/services"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+)
+
+const (
+	CANARY_CLIENT_ID = "C.canary"
+	CANARY_ARTIFACT  = "Server.Internal.Canary"
+
+	DEFAULT_FREQUENCY = 5 * time.Minute
+	DEFAULT_TIMEOUT   = time.Minute
+
+	// How often to check the queue and the flow while waiting.
+	POLL_INTERVAL = 500 * time.Millisecond
+)
+
+var (
+	canaryLatency = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "canary_latency_seconds",
+			Help:    "Time for the canary collection to complete.",
+			Buckets: prometheus.ExponentialBuckets(0.1, 2, 10),
+		},
+	)
+
+	canaryPass = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "canary_pass_total",
+		Help: "Number of canary collections which completed.",
+	})
+
+	canaryFail = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "canary_fail_total",
+		Help: "Number of canary collections which failed.",
+	})
+
+	canaryHealthy = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "canary_healthy",
+		Help: "1 if the last canary collection completed, 0 otherwise.",
+	})
+)
+
+// Processes the responses of the canary's collection. This is the
+// flow runner, which lives in the flows package. The flows package
+// depends on the services so the runner is registered by the server
+// rather than imported here.
+type MessageProcessor interface {
+	ProcessSingleMessage(ctx context.Context, message *crypto_proto.VeloMessage)
+	Close()
+}
+
+var (
+	mu                 sync.Mutex
+	new_flow_processor func(config_obj *config_proto.Config) MessageProcessor
+)
+
+func RegisterFlowProcessor(
+	factory func(config_obj *config_proto.Config) MessageProcessor) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	new_flow_processor = factory
+}
+
+func newFlowProcessor(
+	config_obj *config_proto.Config) (MessageProcessor, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if new_flow_processor == nil {
+		return nil, errors.New("flow processor not registered")
+	}
+	return new_flow_processor(config_obj), nil
+}
+
+type CanaryService struct {
+	timeout time.Duration
+}
+
+// Run a single canary collection and return how long it took.
+func (self *CanaryService) Probe(
+	ctx context.Context,
+	config_obj *config_proto.Config) (time.Duration, error) {
+
+	timeout := self.timeout
+	if timeout == 0 {
+		timeout = DEFAULT_TIMEOUT
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	flow_id, err := self.schedule(ctx, config_obj)
+	if err != nil {
+		return 0, fmt.Errorf("scheduling collection: %w", err)
+	}
+
+	err = self.runClient(ctx, config_obj, flow_id)
+	if err != nil {
+		return 0, fmt.Errorf("flow %v: %w", flow_id, err)
+	}
+
+	err = self.waitForCompletion(ctx, config_obj, flow_id)
+	if err != nil {
+		return 0, fmt.Errorf("flow %v: %w", flow_id, err)
+	}
+
+	latency := time.Now().Sub(start)
+
+	// Only failed collections are kept for inspection.
+	launcher, err := services.GetLauncher(config_obj)
+	if err != nil {
+		return 0, err
+	}
+	_, err = launcher.DeleteFlow(ctx, config_obj,
+		CANARY_CLIENT_ID, flow_id, true /* really_do_it */)
+	if err != nil {
+		return 0, err
+	}
+
+	return latency, nil
+}
+
+func (self *CanaryService) schedule(
+	ctx context.Context,
+	config_obj *config_proto.Config) (string, error) {
+
+	manager, err := services.GetRepositoryManager(config_obj)
+	if err != nil {
+		return "", err
+	}
+
+	repository, err := manager.GetGlobalRepository(config_obj)
+	if err != nil {
+		return "", err
+	}
+
+	launcher, err := services.GetLauncher(config_obj)
+	if err != nil {
+		return "", err
+	}
+
+	return launcher.ScheduleArtifactCollection(ctx, config_obj,
+		vql_subsystem.NullACLManager{}, repository,
+		&flows_proto.ArtifactCollectorArgs{
+			Creator:   "CanaryService",
+			ClientId:  CANARY_CLIENT_ID,
+			Artifacts: []string{CANARY_ARTIFACT},
+		}, nil)
+}
+
+// Act as the client: Wait for the tasks to be queued, run them and
+// send the responses to the flow runner. Only the canary's own tasks
+// are run - anything else queued for the synthetic client is
+// dropped.
+func (self *CanaryService) runClient(
+	ctx context.Context,
+	config_obj *config_proto.Config, flow_id string) error {
+
+	client_info_manager, err := services.GetClientInfoManager(config_obj)
+	if err != nil {
+		return err
+	}
+
+	var tasks []*crypto_proto.VeloMessage
+	for {
+		queued, err := client_info_manager.GetClientTasks(CANARY_CLIENT_ID)
+		if err != nil {
+			return err
+		}
+
+		for _, task := range queued {
+			if task.SessionId == flow_id && task.VQLClientAction != nil {
+				tasks = append(tasks, task)
+			}
+		}
+
+		if len(tasks) > 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.New("timed out waiting for tasks")
+		case <-time.After(POLL_INTERVAL):
+		}
+	}
+
+	err = client_info_manager.UpdateStats(CANARY_CLIENT_ID, &services.Stats{
+		Ping: uint64(time.Now().UnixNano() / 1000),
+	})
+	if err != nil {
+		return err
+	}
+
+	output := make(chan *crypto_proto.VeloMessage)
+	responses := []*crypto_proto.VeloMessage{}
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for message := range output {
+			responses = append(responses, message)
+		}
+	}()
+
+	for _, task := range tasks {
+		task.AuthState = crypto_proto.VeloMessage_AUTHENTICATED
+		actions.VQLClientAction{}.StartQuery(config_obj, ctx,
+			responder.NewResponder(config_obj, task, output),
+			task.VQLClientAction)
+	}
+	close(output)
+	wg.Wait()
+
+	runner, err := newFlowProcessor(config_obj)
+	if err != nil {
+		return err
+	}
+	defer runner.Close()
+
+	for _, message := range responses {
+		message.Source = CANARY_CLIENT_ID
+		message.OrgId = config_obj.OrgId
+		message.AuthState = crypto_proto.VeloMessage_AUTHENTICATED
+		runner.ProcessSingleMessage(ctx, message)
+	}
+
+	return nil
+}
+
+func (self *CanaryService) waitForCompletion(
+	ctx context.Context,
+	config_obj *config_proto.Config, flow_id string) error {
+
+	launcher, err := services.GetLauncher(config_obj)
+	if err != nil {
+		return err
+	}
+
+	for {
+		details, err := launcher.GetFlowDetails(
+			config_obj, CANARY_CLIENT_ID, flow_id)
+		if err != nil {
+			return err
+		}
+
+		collection_context := details.Context
+		if collection_context == nil {
+			return errors.New("no collection context")
+		}
+
+		switch collection_context.State {
+		case flows_proto.ArtifactCollectorContext_FINISHED:
+			if collection_context.TotalCollectedRows == 0 {
+				return errors.New("collection returned no rows")
+			}
+			return nil
+
+		case flows_proto.ArtifactCollectorContext_RUNNING:
+
+		default:
+			return fmt.Errorf("collection is %v: %v",
+				collection_context.State, collection_context.Status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.New("timed out waiting for the collection to complete")
+		case <-time.After(POLL_INTERVAL):
+		}
+	}
+}
+
+func (self *CanaryService) runProbe(
+	ctx context.Context, config_obj *config_proto.Config) {
+
+	logger := logging.GetLogger(config_obj, &logging.FrontendComponent)
+
+	latency, err := self.Probe(ctx, config_obj)
+	if err != nil {
+		canaryFail.Inc()
+		canaryHealthy.Set(0)
+		logger.Error("CanaryService: %v", err)
+		return
+	}
+
+	canaryPass.Inc()
+	canaryHealthy.Set(1)
+	canaryLatency.Observe(latency.Seconds())
+}
+
+// The synthetic client needs a client record so tasks can be queued
+// for it.
+func RegisterCanaryClient(config_obj *config_proto.Config) error {
+	client_info_manager, err := services.GetClientInfoManager(config_obj)
+	if err != nil {
+		return err
+	}
+
+	_, err = client_info_manager.Get(CANARY_CLIENT_ID)
+	if err == nil {
+		return nil
+	}
+
+	return client_info_manager.Set(&services.ClientInfo{actions_proto.ClientInfo{
+		ClientId: CANARY_CLIENT_ID,
+		Hostname: "velociraptor-canary",
+		Fqdn:     "velociraptor-canary",
+	}})
+}
+
+func NewCanaryService(
+	ctx context.Context,
+	wg *sync.WaitGroup,
+	config_obj *config_proto.Config) error {
+
+	// The canary is opt in and only runs on the master.
+	if config_obj.Canary == nil || !config_obj.Canary.Enabled ||
+		!services.IsMaster(config_obj) {
+		return nil
+	}
+
+	service := &CanaryService{timeout: DEFAULT_TIMEOUT}
+	if config_obj.Canary.TimeoutSeconds > 0 {
+		service.timeout = time.Duration(
+			config_obj.Canary.TimeoutSeconds) * time.Second
+	}
+
+	frequency := DEFAULT_FREQUENCY
+	if config_obj.Canary.FrequencySeconds > 0 {
+		frequency = time.Duration(
+			config_obj.Canary.FrequencySeconds) * time.Second
+	}
+
+	err := RegisterCanaryClient(config_obj)
+	if err != nil {
+		return err
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		logger := logging.GetLogger(config_obj, &logging.FrontendComponent)
+		logger.Info("<green>Starting</> Canary Service for %v.",
+			services.GetOrgName(config_obj))
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-time.After(frequency):
+				service.runProbe(ctx, config_obj)
+			}
+		}
+	}()
+
+	return nil
+}
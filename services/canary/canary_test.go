@@ -0,0 +1,84 @@
+package canary_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"www.velocidex.com/golang/velociraptor/file_store/test_utils"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/services/canary"
+
+	_ "www.velocidex.com/golang/velociraptor/result_sets/timed"
+	_ "www.velocidex.com/golang/velociraptor/server"
+)
+
+type CanaryTestSuite struct {
+	test_utils.TestSuite
+}
+
+func (self *CanaryTestSuite) SetupTest() {
+	self.TestSuite.SetupTest()
+
+	self.LoadArtifactFiles(
+		"../../artifacts/definitions/Server/Internal/Canary.yaml")
+
+	err := canary.RegisterCanaryClient(self.ConfigObj)
+	assert.NoError(self.T(), err)
+}
+
+func (self *CanaryTestSuite) TestProbe() {
+	service := &canary.CanaryService{}
+
+	latency, err := service.Probe(context.Background(), self.ConfigObj)
+	assert.NoError(self.T(), err)
+	assert.True(self.T(), latency > 0)
+
+	// Successful collections are removed.
+	launcher, err := services.GetLauncher(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	flows, err := launcher.GetFlows(self.ConfigObj, canary.CANARY_CLIENT_ID,
+		true /* include_archived */, nil, 0, 10)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), 0, len(flows.Items))
+}
+
+func (self *CanaryTestSuite) TestProbeFailure() {
+	manager, err := services.GetRepositoryManager(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	repository, err := manager.GetGlobalRepository(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	// A broken pipeline returns no results.
+	_, err = repository.LoadYaml(`
+name: Server.Internal.Canary
+type: CLIENT
+sources:
+- query: SELECT * FROM scope() WHERE FALSE
+`, true, true)
+	assert.NoError(self.T(), err)
+
+	service := &canary.CanaryService{}
+	_, err = service.Probe(context.Background(), self.ConfigObj)
+	assert.Error(self.T(), err)
+	assert.Contains(self.T(), err.Error(), "collection returned no rows")
+
+	// The failed collection is kept for inspection.
+	launcher, err := services.GetLauncher(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	flows, err := launcher.GetFlows(self.ConfigObj, canary.CANARY_CLIENT_ID,
+		true /* include_archived */, nil, 0, 10)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), 1, len(flows.Items))
+	assert.Equal(self.T(), flows_proto.ArtifactCollectorContext_FINISHED,
+		flows.Items[0].State)
+}
+
+func TestCanaryService(t *testing.T) {
+	suite.Run(t, &CanaryTestSuite{})
+}
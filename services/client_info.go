@@ -16,6 +16,11 @@ const (
 
 type ClientOS int
 
+const (
+	// Index prefix for the pools of ephemeral clients.
+	EPHEMERAL_POOL_PREFIX = "pool:"
+)
+
 // Keep some stats about the client in the cache. These will be synced
 // to disk periodically.
 type Stats struct {
@@ -23,6 +28,11 @@ type Stats struct {
 	LastHuntTimestamp     uint64 `json:"LastHuntTimestamp,omitempty"`
 	LastEventTableVersion uint64 `json:"LastEventTableVersion,omitempty"`
 	IpAddress             string `json:"IpAddress,omitempty"`
+
+	// Bytes transferred since the last update. Unlike the other
+	// stats these are added to the client's totals.
+	BytesReceived uint64 `json:"BytesReceived,omitempty"`
+	BytesSent     uint64 `json:"BytesSent,omitempty"`
 }
 
 func GetClientInfoManager(config_obj *config_proto.Config) (ClientInfoManager, error) {
@@ -108,3 +118,55 @@ func GetHostname(
 
 	return info.Hostname
 }
+
+// Monitoring events from ephemeral clients are stored with their
+// pool under this client id.
+func EphemeralPoolClientId(pool string) string {
+	return "P." + pool
+}
+
+// The client id that monitoring events from the client are stored
+// under.
+func GetMonitoringClientId(
+	config_obj *config_proto.Config,
+	client_id string) string {
+	client_info_manager, err := GetClientInfoManager(config_obj)
+	if err != nil {
+		return client_id
+	}
+	info, err := client_info_manager.Get(client_id)
+	if err != nil || info.EphemeralPool == "" {
+		return client_id
+	}
+
+	return EphemeralPoolClientId(info.EphemeralPool)
+}
+
+// Search terms for the client's location. Clients may be searched
+// with e.g. "country:au" or "city:sydney".
+func GeoLocationSearchTerms(geo *actions_proto.GeoLocation) []string {
+	result := []string{}
+	if geo.GetCountryCode() != "" {
+		result = append(result, "country:"+geo.CountryCode)
+	}
+	if geo.GetCity() != "" {
+		result = append(result, "city:"+geo.City)
+	}
+	return result
+}
+
+// Search terms for the client's local network e.g. "ssid:CorpWifi"
+// or "gateway:10.0.0.1".
+func NetworkContextSearchTerms(network *actions_proto.NetworkContext) []string {
+	result := []string{}
+	for _, ssid := range network.GetSsids() {
+		result = append(result, "ssid:"+ssid)
+	}
+	for _, gateway := range network.GetGateways() {
+		result = append(result, "gateway:"+gateway)
+	}
+	for _, dns := range network.GetDnsServers() {
+		result = append(result, "dns:"+dns)
+	}
+	return result
+}
@@ -15,6 +15,10 @@
    - LastEventTableVersion - the version of the client event table the
      client currently has.
 
+   - BytesReceived and BytesSent - the bandwidth used by the
+     client. Updates to these carry the bytes transferred since the
+     last update and are added to the totals.
+
    While client stats are needed on both the master and minion nodes
    our goal is to minimize IO to the filestore.
 
@@ -113,6 +117,8 @@ func (self *CachedInfo) GetStats() *services.Stats {
 		IpAddress:             self.record.IpAddress,
 		LastHuntTimestamp:     self.record.LastHuntTimestamp,
 		LastEventTableVersion: self.record.LastEventTableVersion,
+		BytesReceived:         self.record.BytesReceived,
+		BytesSent:             self.record.BytesSent,
 	}
 }
 
@@ -163,6 +169,16 @@ func (self *CachedInfo) _UpdateStats(
 		}
 		self.record.LastEventTableVersion = stats.LastEventTableVersion
 	}
+
+	if stats.BytesReceived > 0 || stats.BytesSent > 0 {
+		self.dirty = true
+		if mutation_manager != nil {
+			mutation_manager.AddBandwidth(client_id,
+				stats.BytesReceived, stats.BytesSent)
+		}
+		self.record.BytesReceived += stats.BytesReceived
+		self.record.BytesSent += stats.BytesSent
+	}
 }
 
 // Write ping record to data store if it is dirty.
@@ -182,18 +198,27 @@ func (self *CachedInfo) Flush() error {
 		return nil
 	}
 
+	old_geo, new_geo, geo_changed := self._updateGeoLocation()
+
 	ping_client_info := &actions_proto.ClientInfo{
 		Ping:                  self.record.Ping,
 		PingTime:              time.Unix(0, int64(self.record.Ping)*1000).String(),
 		IpAddress:             self.record.IpAddress,
 		LastHuntTimestamp:     self.record.LastHuntTimestamp,
 		LastEventTableVersion: self.record.LastEventTableVersion,
+		GeoLocation:           self.record.GeoLocation,
+		BytesReceived:         self.record.BytesReceived,
+		BytesSent:             self.record.BytesSent,
 	}
 	client_id := self.record.ClientId
 	self.dirty = false
 	self.last_flush = uint64(self.owner.Clock.Now().UnixNano() / 1000)
 	self.mu.Unlock()
 
+	if geo_changed {
+		self.owner.updateGeoIndex(client_id, old_geo, new_geo)
+	}
+
 	// Record some stats about the client.
 	db, err := datastore.GetDB(self.owner.config_obj)
 	if err != nil {
@@ -209,6 +234,29 @@ func (self *CachedInfo) Flush() error {
 	return nil
 }
 
+// Geolocate the client when its address changes. Must be called
+// with the lock held.
+func (self *CachedInfo) _updateGeoLocation() (
+	old_geo, new_geo *actions_proto.GeoLocation, changed bool) {
+	if self.record.IpAddress == "" ||
+		getHost(self.record.IpAddress) == self.record.GeoLocation.GetIpAddress() ||
+		getGeoIPDatabase(self.owner.config_obj) == nil {
+		return nil, nil, false
+	}
+
+	old_geo = self.record.GeoLocation
+	new_geo = lookupGeoLocation(self.owner.config_obj, self.record.IpAddress)
+	if new_geo == nil {
+		// Remember we looked up this address already.
+		new_geo = &actions_proto.GeoLocation{
+			IpAddress: getHost(self.record.IpAddress),
+		}
+	}
+	self.record.GeoLocation = new_geo
+
+	return old_geo, new_geo, true
+}
+
 type ClientInfoManager struct {
 	config_obj *config_proto.Config
 
@@ -476,6 +524,32 @@ func (self *ClientInfoManager) ProcessPing(
 		}
 	}
 
+	for _, field := range []string{"BytesReceived", "BytesSent"} {
+		bandwidth, pres := getDict(mutation, field)
+		if !pres {
+			continue
+		}
+
+		for _, client_id := range bandwidth.Keys() {
+			value, pres := bandwidth.GetInt64(client_id)
+			if !pres {
+				continue
+			}
+
+			stats := &services.Stats{}
+			if field == "BytesReceived" {
+				stats.BytesReceived = uint64(value)
+			} else {
+				stats.BytesSent = uint64(value)
+			}
+
+			cached_info, err := self.GetCacheInfo(client_id)
+			if err == nil {
+				cached_info._UpdateStats(client_id, stats, nil)
+			}
+		}
+	}
+
 	last_event_table_version, pres := getDict(mutation, "LastEventTableVersion")
 	if pres {
 		for _, client_id := range last_event_table_version.Keys() {
@@ -498,6 +572,23 @@ func (self *ClientInfoManager) ProcessPing(
 	return nil
 }
 
+// Keep the location search terms in sync with the location.
+func (self *ClientInfoManager) updateGeoIndex(client_id string,
+	old_geo, new_geo *actions_proto.GeoLocation) {
+	indexer, err := services.GetIndexer(self.config_obj)
+	if err != nil {
+		return
+	}
+
+	for _, term := range services.GeoLocationSearchTerms(old_geo) {
+		indexer.UnsetIndex(client_id, term)
+	}
+
+	for _, term := range services.GeoLocationSearchTerms(new_geo) {
+		indexer.SetIndex(client_id, term)
+	}
+}
+
 func (self *ClientInfoManager) Flush(client_id string) {
 	cache_info, err := self.GetCacheInfo(client_id)
 	if err == nil {
@@ -630,6 +721,9 @@ func (self *ClientInfoManager) GetCacheInfoFromStorage(
 		client_info.IpAddress = ping_info.IpAddress
 		client_info.LastHuntTimestamp = ping_info.LastHuntTimestamp
 		client_info.LastEventTableVersion = ping_info.LastEventTableVersion
+		client_info.GeoLocation = ping_info.GeoLocation
+		client_info.BytesReceived = ping_info.BytesReceived
+		client_info.BytesSent = ping_info.BytesSent
 		cache_info.last_flush = ping_info.Ping
 	}
 
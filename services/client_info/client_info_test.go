@@ -100,6 +100,34 @@ func (self *ClientInfoTestSuite) TestClientInfo() {
 	assert.Equal(self.T(), stored_client_info.IpAddress, "127.0.0.1")
 }
 
+func (self *ClientInfoTestSuite) TestBandwidth() {
+	client_info_manager, err := services.GetClientInfoManager(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	// Bandwidth updates are added to the totals.
+	for i := 0; i < 3; i++ {
+		err = client_info_manager.UpdateStats(self.client_id, &services.Stats{
+			BytesReceived: 100,
+			BytesSent:     10,
+		})
+		assert.NoError(self.T(), err)
+	}
+
+	info, err := client_info_manager.Get(self.client_id)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), uint64(300), info.BytesReceived)
+	assert.Equal(self.T(), uint64(30), info.BytesSent)
+
+	// The totals are kept in the ping record so they survive the
+	// client falling out of the cache.
+	client_info_manager.Flush(self.client_id)
+
+	info, err = client_info_manager.Get(self.client_id)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), uint64(300), info.BytesReceived)
+	assert.Equal(self.T(), uint64(30), info.BytesSent)
+}
+
 // Check that master and minion update each other.
 func (self *ClientInfoTestSuite) TestMasterMinion() {
 	// Fetch the master client info manager
@@ -123,13 +151,16 @@ func (self *ClientInfoTestSuite) TestMasterMinion() {
 
 	// Update the minion timestamp
 	minion_client_info_manager.UpdateStats(self.client_id, &services.Stats{
-		IpAddress: "127.0.0.1",
+		IpAddress:     "127.0.0.1",
+		BytesReceived: 100,
 	})
 
 	vtesting.WaitUntil(2*time.Second, self.T(), func() bool {
 		client_info, err := master_client_info_manager.Get(self.client_id)
 		assert.NoError(self.T(), err)
-		return client_info.IpAddress == "127.0.0.1"
+		// The bandwidth seen by the minion is added on the master.
+		return client_info.IpAddress == "127.0.0.1" &&
+			client_info.BytesReceived == 100
 	})
 
 	// Make sure we actually write the result in the datastore.
@@ -0,0 +1,100 @@
+package client_info
+
+import (
+	"net"
+	"sync"
+
+	"github.com/oschwald/maxminddb-golang"
+	actions_proto "www.velocidex.com/golang/velociraptor/actions/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/logging"
+)
+
+var (
+	geoip_mu sync.Mutex
+
+	// Databases are opened once and kept open. Failures are cached
+	// as a nil reader so we do not retry on every lookup.
+	geoip_dbs = make(map[string]*maxminddb.Reader)
+)
+
+// The subset of the MaxMind City database we care about.
+type geoIPRecord struct {
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Country struct {
+		IsoCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	Location struct {
+		Latitude  float64 `maxminddb:"latitude"`
+		Longitude float64 `maxminddb:"longitude"`
+	} `maxminddb:"location"`
+}
+
+func getGeoIPDatabase(config_obj *config_proto.Config) *maxminddb.Reader {
+	if config_obj.Frontend == nil || config_obj.Frontend.GeoipDatabase == "" {
+		return nil
+	}
+
+	geoip_mu.Lock()
+	defer geoip_mu.Unlock()
+
+	path := config_obj.Frontend.GeoipDatabase
+	db, pres := geoip_dbs[path]
+	if pres {
+		return db
+	}
+
+	db, err := maxminddb.Open(path)
+	if err != nil {
+		logger := logging.GetLogger(config_obj, &logging.FrontendComponent)
+		logger.Error("ClientInfoManager: Unable to open GeoIP database %v: %v",
+			path, err)
+		db = nil
+	}
+	geoip_dbs[path] = db
+
+	return db
+}
+
+// Strip the port from the remote address.
+func getHost(ip_address string) string {
+	host, _, err := net.SplitHostPort(ip_address)
+	if err != nil {
+		return ip_address
+	}
+	return host
+}
+
+// Locate the client's egress address. Returns nil if there is no
+// database configured or the address is not found.
+func lookupGeoLocation(config_obj *config_proto.Config,
+	ip_address string) *actions_proto.GeoLocation {
+	db := getGeoIPDatabase(config_obj)
+	if db == nil {
+		return nil
+	}
+
+	host := getHost(ip_address)
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil
+	}
+
+	record := &geoIPRecord{}
+	err := db.Lookup(ip, record)
+	if err != nil || record.Country.IsoCode == "" {
+		return nil
+	}
+
+	return &actions_proto.GeoLocation{
+		IpAddress:   host,
+		Country:     record.Country.Names["en"],
+		CountryCode: record.Country.IsoCode,
+		City:        record.City.Names["en"],
+		Latitude:    record.Location.Latitude,
+		Longitude:   record.Location.Longitude,
+	}
+}
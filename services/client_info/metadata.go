@@ -0,0 +1,98 @@
+package client_info
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/Velocidex/ordereddict"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/datastore"
+	"www.velocidex.com/golang/velociraptor/paths"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+// Client metadata is free form key/value data attached to the client
+// (e.g. the owner or business unit from a CMDB). Missing metadata is
+// returned as an empty set.
+func GetMetadata(
+	config_obj *config_proto.Config,
+	client_id string) (*api_proto.ClientMetadata, error) {
+
+	db, err := datastore.GetDB(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &api_proto.ClientMetadata{}
+	err = db.GetSubject(config_obj,
+		paths.NewClientPathManager(client_id).Metadata(), result)
+	if errors.Is(err, os.ErrNotExist) {
+		err = nil
+	}
+	result.ClientId = client_id
+	return result, err
+}
+
+// Replace the client's metadata and let listeners know so hunts
+// targeting the metadata can be rechecked.
+func SetMetadata(
+	config_obj *config_proto.Config,
+	metadata *api_proto.ClientMetadata) error {
+
+	if metadata.ClientId == "" {
+		return errors.New("client_id must be specified")
+	}
+
+	db, err := datastore.GetDB(config_obj)
+	if err != nil {
+		return err
+	}
+
+	err = db.SetSubject(config_obj,
+		paths.NewClientPathManager(metadata.ClientId).Metadata(), metadata)
+	if err != nil {
+		return err
+	}
+
+	journal, err := services.GetJournal(config_obj)
+	if err != nil {
+		return err
+	}
+
+	journal.PushRowsToArtifactAsync(config_obj,
+		ordereddict.NewDict().
+			Set("client_id", metadata.ClientId),
+		"Server.Internal.ClientMetadata")
+	return nil
+}
+
+// Check that the metadata has all the conditions. Keys and values
+// are compared case insensitively and a condition without a value
+// only requires the key.
+func MatchMetadata(
+	metadata *api_proto.ClientMetadata,
+	conditions []*api_proto.ClientMetadataItem) bool {
+
+	for _, condition := range conditions {
+		if !hasMetadataItem(metadata, condition) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasMetadataItem(
+	metadata *api_proto.ClientMetadata,
+	condition *api_proto.ClientMetadataItem) bool {
+
+	for _, item := range metadata.GetItems() {
+		if strings.EqualFold(item.Key, condition.Key) &&
+			(condition.Value == "" ||
+				strings.EqualFold(item.Value, condition.Value)) {
+			return true
+		}
+	}
+	return false
+}
@@ -14,6 +14,10 @@ type MutationManager struct {
 	ip_address               *ordereddict.Dict
 	last_hunt_timestamp      *ordereddict.Dict
 	last_event_table_version *ordereddict.Dict
+
+	// Bandwidth is accumulated until the mutation is sent.
+	bytes_received map[string]uint64
+	bytes_sent     map[string]uint64
 }
 
 func NewMutationManager() *MutationManager {
@@ -22,6 +26,8 @@ func NewMutationManager() *MutationManager {
 		ip_address:               ordereddict.NewDict(),
 		last_hunt_timestamp:      ordereddict.NewDict(),
 		last_event_table_version: ordereddict.NewDict(),
+		bytes_received:           make(map[string]uint64),
+		bytes_sent:               make(map[string]uint64),
 	}
 }
 
@@ -53,12 +59,27 @@ func (self *MutationManager) AddLastEventTableVersion(client_id string, ts uint6
 	self.last_event_table_version.Set(client_id, ts)
 }
 
+func (self *MutationManager) AddBandwidth(
+	client_id string, received, sent uint64) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	if received > 0 {
+		self.bytes_received[client_id] += received
+	}
+
+	if sent > 0 {
+		self.bytes_sent[client_id] += sent
+	}
+}
+
 func (self *MutationManager) Size() int {
 	self.mu.Lock()
 	defer self.mu.Unlock()
 
 	return self.pings.Len() + self.ip_address.Len() +
-		self.last_hunt_timestamp.Len() + self.last_event_table_version.Len()
+		self.last_hunt_timestamp.Len() + self.last_event_table_version.Len() +
+		len(self.bytes_received) + len(self.bytes_sent)
 
 }
 
@@ -70,12 +91,24 @@ func (self *MutationManager) GetMutation() *ordereddict.Dict {
 		Set("Ping", self.pings).
 		Set("IpAddress", self.ip_address).
 		Set("LastHuntTimestamp", self.last_hunt_timestamp).
-		Set("LastEventTableVersion", self.last_event_table_version)
+		Set("LastEventTableVersion", self.last_event_table_version).
+		Set("BytesReceived", bandwidthDict(self.bytes_received)).
+		Set("BytesSent", bandwidthDict(self.bytes_sent))
 
 	self.pings = ordereddict.NewDict()
 	self.ip_address = ordereddict.NewDict()
 	self.last_hunt_timestamp = ordereddict.NewDict()
 	self.last_event_table_version = ordereddict.NewDict()
+	self.bytes_received = make(map[string]uint64)
+	self.bytes_sent = make(map[string]uint64)
+
+	return result
+}
 
+func bandwidthDict(bandwidth map[string]uint64) *ordereddict.Dict {
+	result := ordereddict.NewDict()
+	for client_id, value := range bandwidth {
+		result.Set(client_id, value)
+	}
 	return result
 }
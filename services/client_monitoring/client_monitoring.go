@@ -425,7 +425,7 @@ func (self *ClientEventTable) load_from_file(
 		}
 		self.state.LabelEvents = append(self.state.LabelEvents,
 			&flows_proto.LabelEvents{
-				Label: "Quarantine",
+				Label: services.QUARANTINE_LABEL,
 				Artifacts: &flows_proto.ArtifactCollectorArgs{
 					Artifacts: []string{
 						"Windows.Remediation.QuarantineMonitor",
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"context"
+
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+)
+
+// The compliance manager keeps labeled clients compliant with the
+// configured compliance policies by scheduling the required
+// collections when they are overdue.
+func GetComplianceManager(config_obj *config_proto.Config) (ComplianceManager, error) {
+	org_manager, err := GetOrgManager()
+	if err != nil {
+		return nil, err
+	}
+
+	return org_manager.Services(config_obj.OrgId).ComplianceManager()
+}
+
+type ComplianceManager interface {
+	// Check all clients against the policies and schedule
+	// collections for those which are overdue.
+	Enforce(ctx context.Context, config_obj *config_proto.Config) error
+
+	// Report the coverage of each policy.
+	GetReport(ctx context.Context, config_obj *config_proto.Config) (
+		*api_proto.ComplianceReport, error)
+}
@@ -0,0 +1,372 @@
+/*
+  The compliance service ensures that clients carrying a label
+  collect a set of required artifacts regularly.
+
+  Policies are defined in the config file. Each policy names a label,
+  a set of artifacts and an interval. Periodically the service checks
+  every client with the label and, if any of the artifacts were not
+  successfully collected within the interval, it schedules a new
+  collection for the missing artifacts.
+
+  The same check is used to produce a coverage report which shows
+  the percentage of clients that collected everything on time and
+  lists the stragglers.
+*/
+
+package compliance
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"www.velocidex.com/golang/velociraptor/allowlist"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/approvals"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/services"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+)
+
+const (
+	DEFAULT_CHECK_FREQUENCY = 600 * time.Second
+	DEFAULT_INTERVAL        = 24 * 60 * 60
+
+	// Maximum number of recent flows to examine on each client.
+	MAX_FLOWS = 1000
+)
+
+type ComplianceService struct {
+	mu sync.Mutex
+}
+
+// The compliance state of a single client with respect to a policy.
+type clientState struct {
+	// Artifacts not successfully collected within the interval.
+	missing []string
+
+	// Missing artifacts which are currently being collected so we do
+	// not need to schedule them again.
+	in_flight map[string]bool
+}
+
+func (self *ComplianceService) checkClient(
+	config_obj *config_proto.Config,
+	launcher services.Launcher,
+	client_id string, policy *config_proto.CompliancePolicy,
+	now time.Time) (*clientState, error) {
+
+	interval := policy.IntervalSeconds
+	if interval == 0 {
+		interval = DEFAULT_INTERVAL
+	}
+	earliest := uint64(now.Add(
+		-time.Duration(interval)*time.Second).UnixNano() / 1000)
+
+	flows, err := launcher.GetFlows(config_obj, client_id, false,
+		func(flow *flows_proto.ArtifactCollectorContext) bool {
+			return flow.CreateTime >= earliest && flow.Request != nil
+		}, 0, MAX_FLOWS)
+	if err != nil {
+		return nil, err
+	}
+
+	collected := make(map[string]bool)
+	result := &clientState{in_flight: make(map[string]bool)}
+
+	for _, flow := range flows.Items {
+		for _, name := range flow.Request.Artifacts {
+			name = strings.ToLower(name)
+			switch flow.State {
+			case flows_proto.ArtifactCollectorContext_FINISHED:
+				collected[name] = true
+			case flows_proto.ArtifactCollectorContext_RUNNING:
+				result.in_flight[name] = true
+			}
+		}
+	}
+
+	for _, artifact := range policy.Artifacts {
+		if !collected[strings.ToLower(artifact)] {
+			result.missing = append(result.missing, artifact)
+		}
+	}
+
+	return result, nil
+}
+
+// Enumerate all the clients with the label.
+func (self *ComplianceService) getClients(
+	ctx context.Context, config_obj *config_proto.Config,
+	label string) (chan *api_proto.ApiClient, error) {
+
+	indexer, err := services.GetIndexer(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	scope := vql_subsystem.MakeScope()
+	search_chan, err := indexer.SearchClientsChan(
+		ctx, scope, config_obj, "label:"+label, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// The index search matches label prefixes so filter for the
+	// exact label here.
+	output_chan := make(chan *api_proto.ApiClient)
+	go func() {
+		defer close(output_chan)
+
+		for api_client := range search_chan {
+			if !hasLabel(api_client, label) {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case output_chan <- api_client:
+			}
+		}
+	}()
+
+	return output_chan, nil
+}
+
+func (self *ComplianceService) GetReport(
+	ctx context.Context, config_obj *config_proto.Config) (
+	*api_proto.ComplianceReport, error) {
+
+	launcher, err := services.GetLauncher(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := &api_proto.ComplianceReport{
+		Timestamp: uint64(now.Unix()),
+	}
+
+	for _, policy := range getPolicies(config_obj) {
+		coverage := &api_proto.ComplianceCoverage{
+			Label:           policy.Label,
+			Artifacts:       policy.Artifacts,
+			IntervalSeconds: policy.IntervalSeconds,
+		}
+		if coverage.IntervalSeconds == 0 {
+			coverage.IntervalSeconds = DEFAULT_INTERVAL
+		}
+
+		clients, err := self.getClients(ctx, config_obj, policy.Label)
+		if err != nil {
+			return nil, err
+		}
+
+		for api_client := range clients {
+			state, err := self.checkClient(
+				config_obj, launcher, api_client.ClientId, policy, now)
+			if err != nil {
+				continue
+			}
+
+			coverage.TotalClients++
+			if len(state.missing) == 0 {
+				coverage.CompliantClients++
+				continue
+			}
+
+			straggler := &api_proto.ComplianceStraggler{
+				ClientId:         api_client.ClientId,
+				MissingArtifacts: state.missing,
+				LastSeenAt:       api_client.LastSeenAt,
+			}
+			if api_client.OsInfo != nil {
+				straggler.Hostname = api_client.OsInfo.Hostname
+			}
+			coverage.Stragglers = append(coverage.Stragglers, straggler)
+		}
+
+		if coverage.TotalClients > 0 {
+			coverage.Coverage = 100 * float64(coverage.CompliantClients) /
+				float64(coverage.TotalClients)
+		}
+
+		sort.Slice(coverage.Stragglers, func(i, j int) bool {
+			return coverage.Stragglers[i].ClientId <
+				coverage.Stragglers[j].ClientId
+		})
+
+		result.Policies = append(result.Policies, coverage)
+	}
+
+	return result, nil
+}
+
+func (self *ComplianceService) Enforce(
+	ctx context.Context, config_obj *config_proto.Config) error {
+
+	// Only one enforcer may run at the same time.
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	launcher, err := services.GetLauncher(config_obj)
+	if err != nil {
+		return err
+	}
+
+	manager, err := services.GetRepositoryManager(config_obj)
+	if err != nil {
+		return err
+	}
+
+	repository, err := manager.GetGlobalRepository(config_obj)
+	if err != nil {
+		return err
+	}
+
+	logger := logging.GetLogger(config_obj, &logging.FrontendComponent)
+	now := time.Now()
+
+	for _, policy := range getPolicies(config_obj) {
+		clients, err := self.getClients(ctx, config_obj, policy.Label)
+		if err != nil {
+			return err
+		}
+
+		for api_client := range clients {
+			state, err := self.checkClient(
+				config_obj, launcher, api_client.ClientId, policy, now)
+			if err != nil {
+				continue
+			}
+
+			to_schedule := []string{}
+			for _, artifact := range state.missing {
+				if !state.in_flight[strings.ToLower(artifact)] {
+					to_schedule = append(to_schedule, artifact)
+				}
+			}
+
+			if len(to_schedule) == 0 {
+				continue
+			}
+
+			client_id := api_client.ClientId
+			request := &flows_proto.ArtifactCollectorArgs{
+				Creator:   "ComplianceService",
+				ClientId:  client_id,
+				Artifacts: to_schedule,
+			}
+
+			err = checkCollection(config_obj, repository, request)
+			if err != nil {
+				logger.Error("ComplianceService: Unable to schedule %v on %v: %v",
+					to_schedule, client_id, err)
+				continue
+			}
+
+			flow_id, err := launcher.ScheduleArtifactCollection(
+				ctx, config_obj, vql_subsystem.NullACLManager{},
+				repository, request, func() {
+					notifier, err := services.GetNotifier(config_obj)
+					if err == nil {
+						notifier.NotifyListener(
+							config_obj, client_id, "Compliance")
+					}
+				})
+			if err != nil {
+				logger.Error("ComplianceService: Unable to schedule %v on %v: %v",
+					to_schedule, client_id, err)
+				continue
+			}
+
+			logger.Info("ComplianceService: Scheduled %v on %v (%v) for label %v",
+				to_schedule, client_id, flow_id, policy.Label)
+		}
+	}
+
+	return nil
+}
+
+// Collections scheduled by the service are subject to the same
+// checks as collections launched by users. The service can not
+// request approvals so policies may not use dangerous plugins.
+func checkCollection(config_obj *config_proto.Config,
+	repository services.Repository,
+	request *flows_proto.ArtifactCollectorArgs) error {
+	err := allowlist.CheckClient(
+		config_obj, request.ClientId, request.Artifacts)
+	if err != nil {
+		return err
+	}
+
+	return approvals.CheckCollection(
+		config_obj, repository, request.Creator, request)
+}
+
+func getPolicies(
+	config_obj *config_proto.Config) []*config_proto.CompliancePolicy {
+	if config_obj.Compliance == nil {
+		return nil
+	}
+	return config_obj.Compliance.Policies
+}
+
+func hasLabel(api_client *api_proto.ApiClient, label string) bool {
+	for _, l := range api_client.Labels {
+		if strings.EqualFold(l, label) {
+			return true
+		}
+	}
+	return false
+}
+
+func NewComplianceService(
+	ctx context.Context,
+	wg *sync.WaitGroup,
+	config_obj *config_proto.Config) (services.ComplianceManager, error) {
+
+	service := &ComplianceService{}
+
+	// Only the master schedules collections and there is nothing to
+	// do if there are no policies.
+	if !services.IsMaster(config_obj) ||
+		len(getPolicies(config_obj)) == 0 {
+		return service, nil
+	}
+
+	frequency := DEFAULT_CHECK_FREQUENCY
+	if config_obj.Compliance.CheckFrequencySeconds > 0 {
+		frequency = time.Duration(
+			config_obj.Compliance.CheckFrequencySeconds) * time.Second
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		logger := logging.GetLogger(config_obj, &logging.FrontendComponent)
+		logger.Info("<green>Starting</> Compliance Service for %v.",
+			services.GetOrgName(config_obj))
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-time.After(frequency):
+				err := service.Enforce(ctx, config_obj)
+				if err != nil {
+					logger.Error("ComplianceService: %v", err)
+				}
+			}
+		}
+	}()
+
+	return service, nil
+}
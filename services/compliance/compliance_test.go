@@ -0,0 +1,152 @@
+package compliance_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	actions_proto "www.velocidex.com/golang/velociraptor/actions/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/datastore"
+	"www.velocidex.com/golang/velociraptor/file_store/test_utils"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/paths"
+	"www.velocidex.com/golang/velociraptor/services"
+
+	_ "www.velocidex.com/golang/velociraptor/result_sets/timed"
+)
+
+type ComplianceTestSuite struct {
+	test_utils.TestSuite
+}
+
+func (self *ComplianceTestSuite) SetupTest() {
+	self.ConfigObj = self.LoadConfig()
+	self.ConfigObj.Frontend.ServerServices.ComplianceService = true
+	self.ConfigObj.Compliance = &config_proto.ComplianceConfig{
+		Policies: []*config_proto.CompliancePolicy{{
+			Label:           "Servers",
+			Artifacts:       []string{"Generic.Client.Info"},
+			IntervalSeconds: 3600,
+		}},
+		// Do not run the enforcer automatically.
+		CheckFrequencySeconds: 100000,
+	}
+
+	self.TestSuite.SetupTest()
+
+	db, err := datastore.GetDB(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	labeler := services.GetLabeler(self.ConfigObj)
+
+	// C.1 and C.2 are servers, C.3 is not.
+	for _, client_id := range []string{"C.1", "C.2", "C.3"} {
+		path_manager := paths.NewClientPathManager(client_id)
+		err = db.SetSubject(self.ConfigObj, path_manager.Path(),
+			&actions_proto.ClientInfo{
+				ClientId: client_id,
+				Hostname: "Host" + client_id,
+			})
+		assert.NoError(self.T(), err)
+
+		if client_id != "C.3" {
+			err = labeler.SetClientLabel(self.ConfigObj, client_id, "Servers")
+			assert.NoError(self.T(), err)
+		}
+	}
+
+	// Only C.1 collected the artifact recently.
+	path_manager := paths.NewFlowPathManager("C.1", "F.1")
+	err = db.SetSubject(self.ConfigObj, path_manager.Path(),
+		&flows_proto.ArtifactCollectorContext{
+			SessionId:  "F.1",
+			ClientId:   "C.1",
+			State:      flows_proto.ArtifactCollectorContext_FINISHED,
+			CreateTime: uint64(time.Now().UnixNano() / 1000),
+			Request: &flows_proto.ArtifactCollectorArgs{
+				Artifacts: []string{"Generic.Client.Info"},
+			},
+		})
+	assert.NoError(self.T(), err)
+}
+
+func (self *ComplianceTestSuite) TestComplianceReport() {
+	compliance_manager, err := services.GetComplianceManager(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	report, err := compliance_manager.GetReport(self.Ctx, self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	assert.Equal(self.T(), 1, len(report.Policies))
+	coverage := report.Policies[0]
+	assert.Equal(self.T(), uint64(2), coverage.TotalClients)
+	assert.Equal(self.T(), uint64(1), coverage.CompliantClients)
+	assert.Equal(self.T(), float64(50), coverage.Coverage)
+	assert.Equal(self.T(), 1, len(coverage.Stragglers))
+	assert.Equal(self.T(), "C.2", coverage.Stragglers[0].ClientId)
+	assert.Equal(self.T(), "HostC.2", coverage.Stragglers[0].Hostname)
+	assert.Equal(self.T(), []string{"Generic.Client.Info"},
+		coverage.Stragglers[0].MissingArtifacts)
+}
+
+func (self *ComplianceTestSuite) TestEnforce() {
+	compliance_manager, err := services.GetComplianceManager(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	launcher, err := services.GetLauncher(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	// Enforcing twice should only schedule one collection since the
+	// first one is still in flight.
+	for i := 0; i < 2; i++ {
+		err = compliance_manager.Enforce(self.Ctx, self.ConfigObj)
+		assert.NoError(self.T(), err)
+	}
+
+	flows, err := launcher.GetFlows(self.ConfigObj, "C.2", true, nil, 0, 10)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), 1, len(flows.Items))
+	assert.Equal(self.T(), "ComplianceService", flows.Items[0].Request.Creator)
+
+	// C.1 is already compliant and C.3 is not covered by the policy.
+	for _, client_id := range []string{"C.1", "C.3"} {
+		flows, err := launcher.GetFlows(self.ConfigObj, client_id, true, nil, 0, 10)
+		assert.NoError(self.T(), err)
+		for _, flow := range flows.Items {
+			assert.NotEqual(self.T(), "ComplianceService", flow.Request.Creator)
+		}
+	}
+}
+
+func (self *ComplianceTestSuite) TestEnforceAllowlist() {
+	// Servers may only collect the stats artifact.
+	self.ConfigObj.ArtifactAllowlist = []*config_proto.ArtifactAllowlistRule{{
+		Label:     "Servers",
+		Artifacts: []string{"Generic.Client.Stats"},
+	}}
+	defer func() {
+		self.ConfigObj.ArtifactAllowlist = nil
+	}()
+
+	compliance_manager, err := services.GetComplianceManager(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	launcher, err := services.GetLauncher(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	// The policy is not enforced on clients where it is not allowed.
+	err = compliance_manager.Enforce(self.Ctx, self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	flows, err := launcher.GetFlows(self.ConfigObj, "C.2", true, nil, 0, 10)
+	assert.NoError(self.T(), err)
+	for _, flow := range flows.Items {
+		assert.NotEqual(self.T(), "ComplianceService", flow.Request.Creator)
+	}
+}
+
+func TestComplianceService(t *testing.T) {
+	suite.Run(t, &ComplianceTestSuite{})
+}
@@ -0,0 +1,180 @@
+/*
+  The ephemeral client service removes clients running in ephemeral
+  mode once they stop checking in.
+
+  Non-persistent machines (VDI instances, containers) enroll as a new
+  client each time they start. Clients configured with an ephemeral
+  pool are indexed under the pool and periodically the service
+  deletes those which were not seen within the expiry time so the
+  client list does not fill up with dead instances. Their monitoring
+  events are stored with the pool so they survive the client.
+*/
+
+package ephemeral
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	"github.com/sirupsen/logrus"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/utils"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/vfilter"
+)
+
+const (
+	DEFAULT_EXPIRY          = 24 * time.Hour
+	DEFAULT_CHECK_FREQUENCY = time.Hour
+)
+
+var (
+	Clock utils.Clock = &utils.RealClock{}
+)
+
+type EphemeralClientService struct{}
+
+// Find the ephemeral clients which were not seen within the expiry
+// time.
+func (self *EphemeralClientService) ExpiredClients(
+	ctx context.Context,
+	config_obj *config_proto.Config) ([]string, error) {
+
+	indexer, err := services.GetIndexer(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	expiry := DEFAULT_EXPIRY
+	if config_obj.EphemeralClients != nil &&
+		config_obj.EphemeralClients.ExpirySeconds > 0 {
+		expiry = time.Duration(
+			config_obj.EphemeralClients.ExpirySeconds) * time.Second
+	}
+	cutoff := uint64(Clock.Now().Add(-expiry).UnixNano() / 1000)
+
+	seen := make(map[string]bool)
+	result := []string{}
+
+	for hit := range indexer.SearchIndexWithPrefix(
+		ctx, config_obj, services.EPHEMERAL_POOL_PREFIX) {
+		if hit == nil || seen[hit.Entity] {
+			continue
+		}
+		seen[hit.Entity] = true
+
+		api_client, err := indexer.FastGetApiClient(ctx, config_obj, hit.Entity)
+		if err != nil {
+			continue
+		}
+
+		// Clients which never checked in are measured from
+		// enrollment.
+		last_seen := api_client.LastSeenAt
+		if last_seen == 0 {
+			last_seen = api_client.FirstSeenAt
+		}
+
+		if last_seen < cutoff {
+			result = append(result, hit.Entity)
+		}
+	}
+
+	return result, nil
+}
+
+// Delete the expired clients. Clients under a legal hold are kept.
+func (self *EphemeralClientService) Expire(
+	ctx context.Context,
+	config_obj *config_proto.Config) error {
+
+	expired, err := self.ExpiredClients(ctx, config_obj)
+	if err != nil || len(expired) == 0 {
+		return err
+	}
+
+	manager, err := services.GetRepositoryManager(config_obj)
+	if err != nil {
+		return err
+	}
+
+	logger := logging.GetLogger(config_obj, &logging.FrontendComponent)
+	vql, err := vfilter.Parse(
+		"SELECT * FROM client_delete(client_id=ClientId, really_do_it=TRUE)")
+	if err != nil {
+		return err
+	}
+
+	for _, client_id := range expired {
+		scope := manager.BuildScope(services.ScopeBuilder{
+			Config:     config_obj,
+			ACLManager: vql_subsystem.NullACLManager{},
+			Logger: logging.NewPlainLogger(
+				config_obj, &logging.FrontendComponent),
+			Env: ordereddict.NewDict().Set("ClientId", client_id),
+		})
+
+		for range vql.Eval(ctx, scope) {
+		}
+		scope.Close()
+
+		logger.Info("EphemeralClientService: Removed expired client %v",
+			client_id)
+
+		logging.GetLogger(config_obj, &logging.Audit).
+			WithFields(logrus.Fields{
+				"user":   "EphemeralClientService",
+				"client": client_id,
+			}).Info("ExpireEphemeralClient")
+	}
+
+	return nil
+}
+
+func NewEphemeralClientService(
+	ctx context.Context,
+	wg *sync.WaitGroup,
+	config_obj *config_proto.Config) error {
+
+	// Only the master deletes clients.
+	if !services.IsMaster(config_obj) {
+		return nil
+	}
+
+	service := &EphemeralClientService{}
+
+	frequency := DEFAULT_CHECK_FREQUENCY
+	if config_obj.EphemeralClients != nil &&
+		config_obj.EphemeralClients.CheckFrequencySeconds > 0 {
+		frequency = time.Duration(
+			config_obj.EphemeralClients.CheckFrequencySeconds) * time.Second
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		logger := logging.GetLogger(config_obj, &logging.FrontendComponent)
+		logger.Info("<green>Starting</> Ephemeral Client Service for %v.",
+			services.GetOrgName(config_obj))
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-time.After(frequency):
+				err := service.Expire(ctx, config_obj)
+				if err != nil {
+					logger.Error("EphemeralClientService: %v", err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package ephemeral_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	actions_proto "www.velocidex.com/golang/velociraptor/actions/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/datastore"
+	"www.velocidex.com/golang/velociraptor/file_store/test_utils"
+	"www.velocidex.com/golang/velociraptor/paths"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/services/ephemeral"
+	"www.velocidex.com/golang/velociraptor/utils"
+	"www.velocidex.com/golang/velociraptor/vtesting"
+
+	_ "www.velocidex.com/golang/velociraptor/result_sets/timed"
+	_ "www.velocidex.com/golang/velociraptor/vql/server/clients"
+)
+
+type EphemeralTestSuite struct {
+	test_utils.TestSuite
+	now time.Time
+}
+
+func (self *EphemeralTestSuite) SetupTest() {
+	self.ConfigObj = self.LoadConfig()
+	self.ConfigObj.Frontend.ServerServices.EphemeralClientService = true
+	self.ConfigObj.EphemeralClients = &config_proto.EphemeralClientsConfig{
+		ExpirySeconds: 3600,
+
+		// Do not expire clients automatically.
+		CheckFrequencySeconds: 100000,
+	}
+
+	self.TestSuite.SetupTest()
+
+	self.now = time.Unix(1700000000, 0)
+	ephemeral.Clock = &utils.MockClock{MockNow: self.now}
+
+	db, err := datastore.GetDB(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	indexer, err := services.GetIndexer(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	old := uint64(self.now.Add(-2*time.Hour).UnixNano() / 1000)
+	recent := uint64(self.now.Add(-time.Minute).UnixNano() / 1000)
+
+	// C.1 is an expired VDI instance, C.2 is still active and C.3
+	// is a regular client which is never expired.
+	for client_id, record := range map[string]*actions_proto.ClientInfo{
+		"C.1": {Ping: old, EphemeralPool: "VDI"},
+		"C.2": {Ping: recent, EphemeralPool: "VDI"},
+		"C.3": {Ping: old},
+	} {
+		record.ClientId = client_id
+		err = db.SetSubject(self.ConfigObj,
+			paths.NewClientPathManager(client_id).Path(), record)
+		assert.NoError(self.T(), err)
+
+		err = indexer.SetIndex(client_id, "all")
+		assert.NoError(self.T(), err)
+
+		if record.EphemeralPool != "" {
+			err = indexer.SetIndex(client_id,
+				services.EPHEMERAL_POOL_PREFIX+record.EphemeralPool)
+			assert.NoError(self.T(), err)
+		}
+	}
+}
+
+func (self *EphemeralTestSuite) TearDownTest() {
+	ephemeral.Clock = &utils.RealClock{}
+	self.TestSuite.TearDownTest()
+}
+
+func (self *EphemeralTestSuite) TestExpire() {
+	service := &ephemeral.EphemeralClientService{}
+	ctx := context.Background()
+
+	expired, err := service.ExpiredClients(ctx, self.ConfigObj)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), []string{"C.1"}, expired)
+
+	err = service.Expire(ctx, self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	client_info_manager, err := services.GetClientInfoManager(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	// The client info cache is cleared by the delete event.
+	vtesting.WaitUntil(5*time.Second, self.T(), func() bool {
+		_, err := client_info_manager.Get("C.1")
+		return err != nil
+	})
+
+	for _, client_id := range []string{"C.2", "C.3"} {
+		_, err = client_info_manager.Get(client_id)
+		assert.NoError(self.T(), err)
+	}
+
+	expired, err = service.ExpiredClients(ctx, self.ConfigObj)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), 0, len(expired))
+}
+
+func (self *EphemeralTestSuite) TestMonitoringClientId() {
+	assert.Equal(self.T(), services.EphemeralPoolClientId("VDI"),
+		services.GetMonitoringClientId(self.ConfigObj, "C.1"))
+	assert.Equal(self.T(), "C.3",
+		services.GetMonitoringClientId(self.ConfigObj, "C.3"))
+}
+
+func TestEphemeralClientService(t *testing.T) {
+	suite.Run(t, &EphemeralTestSuite{})
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"context"
+
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+)
+
+// The artifact exchange service browses a remote exchange of
+// community artifacts and installs them into the repository. The
+// exchange index is signed so artifacts are only installed from a
+// trusted exchange.
+func GetArtifactExchange(config_obj *config_proto.Config) (ArtifactExchange, error) {
+	org_manager, err := GetOrgManager()
+	if err != nil {
+		return nil, err
+	}
+
+	return org_manager.Services(config_obj.OrgId).ArtifactExchange()
+}
+
+type ArtifactExchange interface {
+	// List the artifacts on the exchange and whether they are
+	// installed.
+	ListArtifacts(ctx context.Context, config_obj *config_proto.Config,
+		filter string) (*api_proto.ExchangeArtifacts, error)
+
+	// Install the artifact from the exchange. If version is empty we
+	// install the pinned version or the latest version.
+	InstallArtifact(ctx context.Context, config_obj *config_proto.Config,
+		principal, name, version string) (*api_proto.ExchangeArtifact, error)
+
+	// Report the installed artifacts and whether newer versions are
+	// available.
+	CheckForUpdates(ctx context.Context, config_obj *config_proto.Config,
+		filter string) (*api_proto.ExchangeArtifacts, error)
+}
@@ -0,0 +1,466 @@
+/*
+  The artifact exchange service installs community artifacts from a
+  remote exchange.
+
+  The exchange publishes an index of the artifacts it carries at
+  <url>/index.json:
+
+  {"artifacts": [{"name": "Windows.Detection.Foo", "version": "1.2.0",
+                  "author": "...", "description": "...",
+                  "url": "Windows.Detection.Foo-1.2.0.yaml",
+                  "sha256": "..."}]}
+
+  The index is signed with the exchange's ed25519 key and the base64
+  encoded signature is published at <url>/index.json.sig. Since the
+  index records the hash of each definition, verifying the index is
+  enough to trust the definitions it points to.
+
+  Installed artifacts are renamed with the exchange prefix so they can
+  not replace built in artifacts. We record the installed version of
+  each artifact in the datastore so we can tell when updates are
+  published. Artifacts may be pinned to a version in the config file,
+  in which case we do not offer newer versions.
+*/
+
+package exchange
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/datastore"
+	"www.velocidex.com/golang/velociraptor/json"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/paths"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/utils"
+	"www.velocidex.com/golang/velociraptor/vql/networking"
+)
+
+const (
+	DEFAULT_PREFIX = "Exchange."
+
+	// Indexes and artifact definitions are small - refuse to read
+	// anything larger than this.
+	MAX_DOWNLOAD_SIZE = 10 * 1024 * 1024
+)
+
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+type indexEntry struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Author      string `json:"author"`
+	Description string `json:"description"`
+	Url         string `json:"url"`
+	Sha256      string `json:"sha256"`
+}
+
+type exchangeIndex struct {
+	Artifacts []*indexEntry `json:"artifacts"`
+}
+
+// Returns the latest version of the artifact, or the requested
+// version.
+func (self *exchangeIndex) get(name, version string) (*indexEntry, bool) {
+	var result *indexEntry
+	for _, entry := range self.Artifacts {
+		if entry.Name != name {
+			continue
+		}
+
+		if version != "" {
+			if utils.CompareVersions(entry.Version, version) == 0 {
+				return entry, true
+			}
+			continue
+		}
+
+		if result == nil ||
+			utils.CompareVersions(entry.Version, result.Version) > 0 {
+			result = entry
+		}
+	}
+
+	return result, result != nil
+}
+
+type ExchangeService struct {
+	// Only one install may update the installed records at a time.
+	mu sync.Mutex
+
+	Client HTTPClient
+	Clock  utils.Clock
+}
+
+func (self *ExchangeService) ListArtifacts(
+	ctx context.Context, config_obj *config_proto.Config,
+	filter string) (*api_proto.ExchangeArtifacts, error) {
+
+	index, err := self.fetchIndex(ctx, config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	installed, err := getInstalled(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &api_proto.ExchangeArtifacts{}
+	seen := make(map[string]bool)
+	for _, entry := range index.Artifacts {
+		if seen[entry.Name] || !strings.Contains(entry.Name, filter) {
+			continue
+		}
+		seen[entry.Name] = true
+
+		result.Items = append(result.Items,
+			getStatus(config_obj, index, entry.Name, installed[entry.Name]))
+	}
+
+	sort.Slice(result.Items, func(i, j int) bool {
+		return result.Items[i].Name < result.Items[j].Name
+	})
+
+	return result, nil
+}
+
+func (self *ExchangeService) CheckForUpdates(
+	ctx context.Context, config_obj *config_proto.Config,
+	filter string) (*api_proto.ExchangeArtifacts, error) {
+
+	index, err := self.fetchIndex(ctx, config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	installed, err := getInstalled(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &api_proto.ExchangeArtifacts{}
+	for name, item := range installed {
+		if !strings.Contains(name, filter) {
+			continue
+		}
+		result.Items = append(result.Items,
+			getStatus(config_obj, index, name, item))
+	}
+
+	sort.Slice(result.Items, func(i, j int) bool {
+		return result.Items[i].Name < result.Items[j].Name
+	})
+
+	return result, nil
+}
+
+func (self *ExchangeService) InstallArtifact(
+	ctx context.Context, config_obj *config_proto.Config,
+	principal, name, version string) (*api_proto.ExchangeArtifact, error) {
+
+	pinned := getPinnedVersion(config_obj, name)
+	if version == "" {
+		version = pinned
+	}
+
+	if pinned != "" && utils.CompareVersions(version, pinned) != 0 {
+		return nil, fmt.Errorf("Artifact %v is pinned to version %v",
+			name, pinned)
+	}
+
+	index, err := self.fetchIndex(ctx, config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, pres := index.get(name, version)
+	if !pres {
+		if version != "" {
+			return nil, fmt.Errorf("Artifact %v version %v not found on exchange",
+				name, version)
+		}
+		return nil, fmt.Errorf("Artifact %v not found on exchange", name)
+	}
+
+	artifact_url, err := resolveUrl(config_obj, entry.Url)
+	if err != nil {
+		return nil, err
+	}
+
+	definition, err := self.fetch(ctx, artifact_url)
+	if err != nil {
+		return nil, err
+	}
+
+	// The index is signed so the hash proves the definition is the
+	// one the exchange published.
+	sha_sum := sha256.Sum256(definition)
+	hash := hex.EncodeToString(sha_sum[:])
+	if !strings.EqualFold(hash, entry.Sha256) {
+		return nil, fmt.Errorf("Artifact %v: hash mismatch (expected %v got %v)",
+			name, entry.Sha256, hash)
+	}
+
+	manager, err := services.GetRepositoryManager(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	artifact, err := manager.SetArtifactFile(config_obj, principal,
+		string(definition), getPrefix(config_obj))
+	if err != nil {
+		return nil, err
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	installed, err := getInstalled(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &api_proto.ExchangeArtifact{
+		Name:             entry.Name,
+		InstalledName:    artifact.Name,
+		InstalledVersion: entry.Version,
+		InstalledBy:      principal,
+		InstalledTime:    uint64(self.Clock.Now().Unix()),
+		Sha256:           hash,
+	}
+	installed[entry.Name] = item
+
+	err = setInstalled(config_obj, installed)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := logging.GetLogger(config_obj, &logging.FrontendComponent)
+	logger.Info("ArtifactExchange: Installed <green>%v</> version %v as %v",
+		entry.Name, entry.Version, artifact.Name)
+
+	return getStatus(config_obj, index, entry.Name, item), nil
+}
+
+// Fetch the index and verify it was signed by the exchange.
+func (self *ExchangeService) fetchIndex(
+	ctx context.Context, config_obj *config_proto.Config) (*exchangeIndex, error) {
+
+	exchange_config := config_obj.ArtifactExchange
+	if exchange_config == nil || exchange_config.Url == "" {
+		return nil, errors.New("Artifact exchange is not configured")
+	}
+
+	if exchange_config.PublicKey == "" {
+		return nil, errors.New("Artifact exchange public key is not configured")
+	}
+
+	public_key, err := base64.StdEncoding.DecodeString(
+		strings.TrimSpace(exchange_config.PublicKey))
+	if err != nil || len(public_key) != ed25519.PublicKeySize {
+		return nil, errors.New("Artifact exchange public key is invalid")
+	}
+
+	index_url, err := resolveUrl(config_obj, "index.json")
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := self.fetch(ctx, index_url)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded_signature, err := self.fetch(ctx, index_url+".sig")
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(
+		strings.TrimSpace(string(encoded_signature)))
+	if err != nil {
+		return nil, fmt.Errorf("Artifact exchange index signature: %w", err)
+	}
+
+	if !ed25519.Verify(public_key, data, signature) {
+		return nil, errors.New(
+			"Artifact exchange index signature verification failed")
+	}
+
+	result := &exchangeIndex{}
+	err = json.Unmarshal(data, result)
+	if err != nil {
+		return nil, fmt.Errorf("Artifact exchange index: %w", err)
+	}
+
+	return result, nil
+}
+
+func (self *ExchangeService) fetch(
+	ctx context.Context, url string) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := self.Client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("Unable to download %v: %v", url, res.Status)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(res.Body, MAX_DOWNLOAD_SIZE+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > MAX_DOWNLOAD_SIZE {
+		return nil, fmt.Errorf("Download of %v is too large", url)
+	}
+
+	return data, nil
+}
+
+// Compare the installed version with the version we would install.
+func getStatus(config_obj *config_proto.Config, index *exchangeIndex,
+	name string, installed *api_proto.ExchangeArtifact) *api_proto.ExchangeArtifact {
+
+	result := &api_proto.ExchangeArtifact{
+		Name:          name,
+		PinnedVersion: getPinnedVersion(config_obj, name),
+	}
+
+	if installed != nil {
+		result.InstalledName = installed.InstalledName
+		result.InstalledVersion = installed.InstalledVersion
+		result.InstalledBy = installed.InstalledBy
+		result.InstalledTime = installed.InstalledTime
+		result.Sha256 = installed.Sha256
+	}
+
+	entry, pres := index.get(name, result.PinnedVersion)
+	if !pres {
+		return result
+	}
+
+	result.Version = entry.Version
+	result.Author = entry.Author
+	result.Description = entry.Description
+	result.UpdateAvailable = installed != nil &&
+		utils.CompareVersions(entry.Version, installed.InstalledVersion) > 0
+
+	return result
+}
+
+func getInstalled(config_obj *config_proto.Config) (
+	map[string]*api_proto.ExchangeArtifact, error) {
+	db, err := datastore.GetDB(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	// The record does not exist until the first install.
+	installed := &api_proto.ExchangeArtifacts{}
+	_ = db.GetSubject(config_obj, paths.EXCHANGE_INSTALLED, installed)
+
+	result := make(map[string]*api_proto.ExchangeArtifact)
+	for _, item := range installed.Items {
+		result[item.Name] = item
+	}
+	return result, nil
+}
+
+func setInstalled(config_obj *config_proto.Config,
+	installed map[string]*api_proto.ExchangeArtifact) error {
+	db, err := datastore.GetDB(config_obj)
+	if err != nil {
+		return err
+	}
+
+	record := &api_proto.ExchangeArtifacts{}
+	for _, item := range installed {
+		record.Items = append(record.Items, item)
+	}
+
+	sort.Slice(record.Items, func(i, j int) bool {
+		return record.Items[i].Name < record.Items[j].Name
+	})
+
+	return db.SetSubject(config_obj, paths.EXCHANGE_INSTALLED, record)
+}
+
+func getPinnedVersion(config_obj *config_proto.Config, name string) string {
+	if config_obj.ArtifactExchange == nil {
+		return ""
+	}
+
+	for _, pin := range config_obj.ArtifactExchange.Pinned {
+		if pin.Name == name {
+			return pin.Version
+		}
+	}
+	return ""
+}
+
+func getPrefix(config_obj *config_proto.Config) string {
+	if config_obj.ArtifactExchange == nil ||
+		config_obj.ArtifactExchange.Prefix == "" {
+		return DEFAULT_PREFIX
+	}
+	return config_obj.ArtifactExchange.Prefix
+}
+
+// Artifact urls in the index may be relative to the exchange url.
+func resolveUrl(config_obj *config_proto.Config, ref string) (string, error) {
+	base, err := url.Parse(
+		strings.TrimSuffix(config_obj.ArtifactExchange.Url, "/") + "/")
+	if err != nil {
+		return "", err
+	}
+
+	parsed, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+
+	return base.ResolveReference(parsed).String(), nil
+}
+
+func NewExchangeService(
+	ctx context.Context,
+	wg *sync.WaitGroup,
+	config_obj *config_proto.Config) (services.ArtifactExchange, error) {
+
+	// Use the VQL http client so it can accept the same certs.
+	default_client, err := networking.GetDefaultHTTPClient(config_obj.Client, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExchangeService{
+		Client: default_client,
+		Clock:  utils.RealClock{},
+	}, nil
+}
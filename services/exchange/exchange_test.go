@@ -0,0 +1,185 @@
+package exchange_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/file_store/test_utils"
+	"www.velocidex.com/golang/velociraptor/json"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+var definitions = map[string]string{
+	"1.0.0": `
+name: Test.Artifact
+description: Version 1.0.0
+sources:
+- query: SELECT * FROM info()
+`,
+	"1.1.0": `
+name: Test.Artifact
+description: Version 1.1.0
+sources:
+- query: SELECT * FROM info()
+`,
+}
+
+type ExchangeTestSuite struct {
+	test_utils.TestSuite
+
+	mu          sync.Mutex
+	files       map[string]string
+	private_key ed25519.PrivateKey
+	server      *httptest.Server
+}
+
+func (self *ExchangeTestSuite) SetupTest() {
+	public_key, private_key, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(self.T(), err)
+	self.private_key = private_key
+
+	self.files = make(map[string]string)
+	self.server = httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			self.mu.Lock()
+			defer self.mu.Unlock()
+
+			data, pres := self.files[strings.TrimPrefix(r.URL.Path, "/")]
+			if !pres {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte(data))
+		}))
+
+	self.publish(self.private_key)
+
+	self.ConfigObj = self.LoadConfig()
+	self.ConfigObj.Frontend.ServerServices.ExchangeService = true
+	self.ConfigObj.ArtifactExchange = &config_proto.ArtifactExchangeConfig{
+		Url:       self.server.URL + "/exchange",
+		PublicKey: base64.StdEncoding.EncodeToString(public_key),
+	}
+
+	self.TestSuite.SetupTest()
+}
+
+func (self *ExchangeTestSuite) TearDownTest() {
+	self.server.Close()
+	self.TestSuite.TearDownTest()
+}
+
+// Publish both versions of the artifact and sign the index with the
+// key.
+func (self *ExchangeTestSuite) publish(key ed25519.PrivateKey) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	entries := []map[string]string{}
+	for version, definition := range definitions {
+		hash := sha256.Sum256([]byte(definition))
+		filename := "Test.Artifact-" + version + ".yaml"
+		self.files["exchange/"+filename] = definition
+		entries = append(entries, map[string]string{
+			"name":    "Test.Artifact",
+			"version": version,
+			"url":     filename,
+			"sha256":  hex.EncodeToString(hash[:]),
+		})
+	}
+
+	index := json.MustMarshalString(
+		map[string]interface{}{"artifacts": entries})
+	self.files["exchange/index.json"] = index
+	self.files["exchange/index.json.sig"] = base64.StdEncoding.EncodeToString(
+		ed25519.Sign(key, []byte(index)))
+}
+
+func (self *ExchangeTestSuite) TestInstallAndUpdate() {
+	exchange, err := services.GetArtifactExchange(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	artifacts, err := exchange.ListArtifacts(self.Ctx, self.ConfigObj, "")
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), 1, len(artifacts.Items))
+	assert.Equal(self.T(), "1.1.0", artifacts.Items[0].Version)
+	assert.Equal(self.T(), "", artifacts.Items[0].InstalledVersion)
+
+	// Install the older version.
+	installed, err := exchange.InstallArtifact(self.Ctx, self.ConfigObj,
+		"admin", "Test.Artifact", "1.0.0")
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), "Exchange.Test.Artifact", installed.InstalledName)
+	assert.Equal(self.T(), "1.0.0", installed.InstalledVersion)
+	assert.True(self.T(), installed.UpdateAvailable)
+
+	manager, err := services.GetRepositoryManager(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	repository, err := manager.GetGlobalRepository(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	artifact, pres := repository.Get(self.ConfigObj, "Exchange.Test.Artifact")
+	assert.True(self.T(), pres)
+	assert.Equal(self.T(), "Version 1.0.0", artifact.Description)
+
+	updates, err := exchange.CheckForUpdates(self.Ctx, self.ConfigObj, "")
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), 1, len(updates.Items))
+	assert.Equal(self.T(), "1.1.0", updates.Items[0].Version)
+	assert.True(self.T(), updates.Items[0].UpdateAvailable)
+
+	// Pinned artifacts are not updated.
+	self.ConfigObj.ArtifactExchange.Pinned = []*config_proto.ExchangePin{{
+		Name: "Test.Artifact", Version: "1.0.0",
+	}}
+
+	updates, err = exchange.CheckForUpdates(self.Ctx, self.ConfigObj, "")
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), "1.0.0", updates.Items[0].Version)
+	assert.False(self.T(), updates.Items[0].UpdateAvailable)
+
+	_, err = exchange.InstallArtifact(self.Ctx, self.ConfigObj,
+		"admin", "Test.Artifact", "1.1.0")
+	assert.Error(self.T(), err)
+}
+
+func (self *ExchangeTestSuite) TestVerification() {
+	exchange, err := services.GetArtifactExchange(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	// A definition which does not match the signed index is
+	// rejected.
+	self.mu.Lock()
+	self.files["exchange/Test.Artifact-1.1.0.yaml"] += "author: Mallory\n"
+	self.mu.Unlock()
+
+	_, err = exchange.InstallArtifact(self.Ctx, self.ConfigObj,
+		"admin", "Test.Artifact", "")
+	assert.Error(self.T(), err)
+	assert.Contains(self.T(), err.Error(), "hash mismatch")
+
+	// An index signed by another key is rejected.
+	_, other_key, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(self.T(), err)
+	self.publish(other_key)
+
+	_, err = exchange.ListArtifacts(self.Ctx, self.ConfigObj, "")
+	assert.Error(self.T(), err)
+	assert.Contains(self.T(), err.Error(), "signature verification failed")
+}
+
+func TestExchangeService(t *testing.T) {
+	suite.Run(t, &ExchangeTestSuite{})
+}
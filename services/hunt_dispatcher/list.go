@@ -14,6 +14,7 @@ import (
 	"www.velocidex.com/golang/velociraptor/file_store/api"
 	"www.velocidex.com/golang/velociraptor/paths"
 	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/utils"
 )
 
 // Backwards compatibility: Figure out the list of collected hunts
@@ -53,15 +54,10 @@ func (self HuntDispatcher) ListHunts(
 		return nil, err
 	}
 
-	end := in.Count + in.Offset
-	if end > 1000 {
-		end = 1000
-	}
-
 	// We need to get all the active hunts so we can sort them by
 	// creation time. This should be very fast because all hunts
 	// are kept in memory inside the hunt dispatcher.
-	items := make([]*api_proto.Hunt, 0, end)
+	items := []*api_proto.Hunt{}
 	err = dispatcher.ApplyFuncOnHunts(
 		func(hunt *api_proto.Hunt) error {
 			// Only show non-archived hunts.
@@ -84,12 +80,9 @@ func (self HuntDispatcher) ListHunts(
 		return items[i].CreateTime > items[j].CreateTime
 	})
 
-	if end > uint64(len(items)) {
-		end = uint64(len(items))
-	}
-
+	start, end := utils.PageBounds(in.Offset, in.Count, len(items))
 	return &api_proto.ListHuntsResponse{
-		Items: items[in.Offset:end],
+		Items: items[start:end],
 	}, nil
 }
 
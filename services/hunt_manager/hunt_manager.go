@@ -57,6 +57,7 @@ import (
 	"github.com/Velocidex/ordereddict"
 	"golang.org/x/time/rate"
 	"google.golang.org/protobuf/proto"
+	"www.velocidex.com/golang/velociraptor/allowlist"
 	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
 	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
 	"www.velocidex.com/golang/velociraptor/constants"
@@ -64,6 +65,7 @@ import (
 	"www.velocidex.com/golang/velociraptor/logging"
 	"www.velocidex.com/golang/velociraptor/paths"
 	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/services/client_info"
 	"www.velocidex.com/golang/velociraptor/services/journal"
 	"www.velocidex.com/golang/velociraptor/utils"
 	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
@@ -129,6 +131,13 @@ func (self *HuntManager) Start(
 		return err
 	}
 
+	err = journal.WatchQueueWithCB(ctx, config_obj, wg,
+		"Server.Internal.ClientMetadata", "HuntManager",
+		self.ProcessMetadataChange)
+	if err != nil {
+		return err
+	}
+
 	err = journal.WatchQueueWithCB(ctx, config_obj, wg,
 		"Server.Internal.Interrogation", "HuntManager",
 		self.ProcessInterrogation)
@@ -212,6 +221,16 @@ func (self *HuntManager) processMutation(
 				modification = services.HuntFlushToDatastoreAsync
 			}
 
+			if mutation.Stats.TotalSentBytes > 0 ||
+				mutation.Stats.TotalWireBytes > 0 {
+				hunt_obj.Stats.TotalSentBytes += mutation.Stats.TotalSentBytes
+				hunt_obj.Stats.TotalWireBytes += mutation.Stats.TotalWireBytes
+				hunt_obj.Stats.TotalPayloadBytes +=
+					mutation.Stats.TotalPayloadBytes
+
+				modification = services.HuntFlushToDatastoreAsync
+			}
+
 			// These modifications affect the state of the hunt and so
 			// need to propagate to all minions
 			// immediately. Eventually they will also hit the
@@ -370,6 +389,10 @@ func (self *HuntManager) ProcessFlowCompletion(
 	// All completions increment this counter.
 	mutation.Stats.TotalClientsWithResults = 1
 
+	mutation.Stats.TotalSentBytes = flow.TotalSentBytes
+	mutation.Stats.TotalWireBytes = flow.TotalWireBytes
+	mutation.Stats.TotalPayloadBytes = flow.TotalPayloadBytes
+
 	// Only errored completions increment this one.
 	if flow.State == flows_proto.ArtifactCollectorContext_ERROR {
 		mutation.Stats.TotalClientsWithErrors = 1
@@ -427,6 +450,24 @@ func (self *HuntManager) ProcessLabelChange(
 		})
 }
 
+// When the client's metadata changes it may now match hunts with
+// metadata conditions.
+func (self *HuntManager) ProcessMetadataChange(
+	ctx context.Context,
+	config_obj *config_proto.Config,
+	row *ordereddict.Dict) error {
+
+	client_id, pres := row.GetString("client_id")
+	if !pres || client_id == "server" {
+		return nil
+	}
+
+	return self.participateInAllHunts(ctx, config_obj, client_id,
+		func(hunt *api_proto.Hunt) bool {
+			return len(hunt.GetCondition().GetMetadata()) > 0
+		})
+}
+
 func (self *HuntManager) participateInAllHunts(ctx context.Context,
 	config_obj *config_proto.Config, client_id string,
 	should_participate_cb func(hunt *api_proto.Hunt) bool) error {
@@ -532,6 +573,18 @@ func (self *HuntManager) ProcessParticipation(
 		participation_row.ClientId) {
 		return fmt.Errorf("Hunt %v: hunt label does not match with %v",
 			participation_row.HuntId, participation_row.ClientId)
+
+	} else if !huntMatchesMetadata(config_obj, hunt_obj,
+		participation_row.ClientId) {
+		return fmt.Errorf("Hunt %v: %v does not match metadata condition",
+			participation_row.HuntId, participation_row.ClientId)
+
+		// Decommissioned clients are only hunted when the hunt
+		// targets them explicitly.
+	} else if services.IsDecommissioned(config_obj, participation_row.ClientId) &&
+		!huntTargetsLabel(hunt_obj, services.DECOMMISSIONED_LABEL) {
+		return fmt.Errorf("Hunt %v: %v is decommissioned",
+			participation_row.HuntId, participation_row.ClientId)
 	}
 
 	// Hunt limit exceeded or it expired - we stop it.
@@ -604,6 +657,16 @@ func huntHasLabel(
 	return false
 }
 
+// Check if the hunt's label condition names the label.
+func huntTargetsLabel(hunt_obj *api_proto.Hunt, label string) bool {
+	for _, l := range hunt_obj.GetCondition().GetLabels().GetLabel() {
+		if strings.EqualFold(l, label) {
+			return true
+		}
+	}
+	return false
+}
+
 // Check if the client should be scheduled based on excluded labels.
 func huntHasExcludeLabel(
 	config_obj *config_proto.Config,
@@ -649,6 +712,24 @@ func huntMatchesOS(hunt_obj *api_proto.Hunt, client_info *services.ClientInfo) b
 	return true
 }
 
+// Check the client's metadata (e.g. owner or criticality from a
+// CMDB).
+func huntMatchesMetadata(
+	config_obj *config_proto.Config,
+	hunt_obj *api_proto.Hunt, client_id string) bool {
+	conditions := hunt_obj.GetCondition().GetMetadata()
+	if len(conditions) == 0 {
+		return true
+	}
+
+	metadata, err := client_info.GetMetadata(config_obj, client_id)
+	if err != nil {
+		return false
+	}
+
+	return client_info.MatchMetadata(metadata, conditions)
+}
+
 // Check if we already launched it on this client. We maintain
 // a data store index of all the clients and hunts to be able
 // to quickly check if a certain hunt ran on a particular
@@ -699,6 +780,15 @@ func scheduleHuntOnClient(
 
 	hunt_id := hunt_obj.HuntId
 
+	// Restricted clients may not be able to participate in the hunt.
+	if hunt_obj.StartRequest != nil {
+		err := allowlist.CheckClient(config_obj, client_id,
+			hunt_obj.StartRequest.Artifacts)
+		if err != nil {
+			return err
+		}
+	}
+
 	manager, err := services.GetRepositoryManager(config_obj)
 	if err != nil {
 		return err
@@ -18,6 +18,7 @@ import (
 	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
 	"www.velocidex.com/golang/velociraptor/paths"
 	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/services/client_info"
 	"www.velocidex.com/golang/velociraptor/services/hunt_manager"
 	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
 	"www.velocidex.com/golang/velociraptor/vtesting"
@@ -633,6 +634,77 @@ func (self *HuntTestSuite) TestHuntClientOSConditionInterrogation() {
 	})
 }
 
+func (self *HuntTestSuite) TestHuntClientMetadataCondition() {
+	t := self.T()
+
+	launcher, err := services.GetLauncher(self.ConfigObj)
+	assert.NoError(t, err)
+
+	launcher.SetFlowIdForTests("F.1234")
+
+	hunt_obj := &api_proto.Hunt{
+		HuntId:       self.hunt_id,
+		StartRequest: self.expected,
+		State:        api_proto.Hunt_RUNNING,
+		Stats:        &api_proto.HuntStats{},
+		Expires:      uint64(time.Now().Add(7*24*time.Hour).UTC().UnixNano() / 1000),
+		Condition: &api_proto.HuntCondition{
+			Metadata: []*api_proto.ClientMetadataItem{
+				{Key: "Owner", Value: "alice"},
+				{Key: "Criticality"},
+			},
+		},
+	}
+
+	db, err := datastore.GetDB(self.ConfigObj)
+	assert.NoError(t, err)
+
+	hunt_path_manager := paths.NewHuntPathManager(hunt_obj.HuntId)
+	err = db.SetSubject(self.ConfigObj, hunt_path_manager.Path(), hunt_obj)
+	assert.NoError(t, err)
+
+	hunt_dispatcher, err := services.GetHuntDispatcher(self.ConfigObj)
+	assert.NoError(t, err)
+	hunt_dispatcher.Refresh(self.ConfigObj)
+
+	// Only some of the metadata matches.
+	err = client_info.SetMetadata(self.ConfigObj, &api_proto.ClientMetadata{
+		ClientId: self.client_id,
+		Items: []*api_proto.ClientMetadataItem{
+			{Key: "owner", Value: "Alice"},
+		},
+	})
+	assert.NoError(t, err)
+
+	err = hunt_manager.HuntManagerForTests.ProcessParticipation(
+		self.Ctx, self.ConfigObj,
+		ordereddict.NewDict().
+			Set("HuntId", self.hunt_id).
+			Set("ClientId", self.client_id))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "does not match metadata condition")
+
+	// Updating the metadata reconsiders the client for the hunt.
+	err = client_info.SetMetadata(self.ConfigObj, &api_proto.ClientMetadata{
+		ClientId: self.client_id,
+		Items: []*api_proto.ClientMetadataItem{
+			{Key: "owner", Value: "Alice"},
+			{Key: "criticality", Value: "High"},
+		},
+	})
+	assert.NoError(t, err)
+
+	vtesting.WaitUntil(5*time.Second, self.T(), func() bool {
+		_, err := LoadCollectionContext(self.ConfigObj, self.client_id, "F.1234")
+		return err == nil
+	})
+
+	collection_context, err := LoadCollectionContext(self.ConfigObj,
+		self.client_id, "F.1234")
+	assert.NoError(t, err)
+	assert.Equal(t, collection_context.Request.Artifacts, self.expected.Artifacts)
+}
+
 // Hunt stats are only updated by the hunt manager by sending the
 // manager mutations.
 func (self *HuntTestSuite) TestHuntManagerMutations() {
@@ -685,6 +757,9 @@ func (self *HuntTestSuite) TestHuntManagerMutations() {
 		// No actual results but the collection is done. See #1743.
 		ArtifactsWithResults: nil,
 		State:                flows_proto.ArtifactCollectorContext_FINISHED,
+		TotalSentBytes:       100,
+		TotalWireBytes:       200,
+		TotalPayloadBytes:    1000,
 	}
 
 	assert.NoError(self.T(), journal.PushRowsToArtifact(self.ConfigObj,
@@ -699,6 +774,12 @@ func (self *HuntTestSuite) TestHuntManagerMutations() {
 		return h.Stats.TotalClientsWithResults == 1
 	})
 
+	// The bandwidth used by the collection is added to the hunt.
+	h, _ = dispatcher.GetHunt(hunt_obj.HuntId)
+	assert.Equal(self.T(), uint64(100), h.Stats.TotalSentBytes)
+	assert.Equal(self.T(), uint64(200), h.Stats.TotalWireBytes)
+	assert.Equal(self.T(), uint64(1000), h.Stats.TotalPayloadBytes)
+
 	// To stop the hunt, we send a hunt mutation that sets the
 	// state of the hunt to stopped.
 	assert.NoError(self.T(), journal.PushRowsToArtifact(self.ConfigObj,
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 
 	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
 	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
@@ -9,6 +10,10 @@ import (
 	"www.velocidex.com/golang/vfilter"
 )
 
+var (
+	InvalidSearchFilterError = errors.New("invalid search filter")
+)
+
 func GetIndexer(config_obj *config_proto.Config) (Indexer, error) {
 	org_manager, err := GetOrgManager()
 	if err != nil {
@@ -85,6 +85,8 @@ func GetApiClient(
 	result.FirstSeenAt = public_key_info.EnrollTime
 	result.LastSeenAt = client_info.Ping
 	result.LastIp = client_info.IpAddress
+	result.NetworkContext = client_info.NetworkContext
+	result.GeoLocation = client_info.GeoLocation
 
 	return result, nil
 }
@@ -138,5 +140,9 @@ func (self *Indexer) FastGetApiClient(
 		LastIp:                      client_info.IpAddress,
 		LastInterrogateFlowId:       client_info.LastInterrogateFlowId,
 		LastInterrogateArtifactName: client_info.LastInterrogateArtifactName,
+		NetworkContext:              client_info.NetworkContext,
+		GeoLocation:                 client_info.GeoLocation,
+		BytesReceived:               client_info.BytesReceived,
+		BytesSent:                   client_info.BytesSent,
 	}, nil
 }
@@ -0,0 +1,167 @@
+package indexing
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/utils"
+)
+
+const (
+	// Default look back period for the collected: search verb.
+	DEFAULT_COLLECTED_DAYS = 7
+
+	// Maximum number of recent flows to examine on each client.
+	MAX_COLLECTED_FLOWS = 1000
+)
+
+// Parse a search term of the form ArtifactName[:days] into the
+// artifact name and the earliest creation time (in microseconds) of
+// interesting flows.
+func parseCollectedTerm(term string, now time.Time) (string, uint64) {
+	days := int64(DEFAULT_COLLECTED_DAYS)
+
+	parts := strings.SplitN(term, ":", 2)
+	if len(parts) == 2 {
+		number, err := strconv.ParseInt(
+			strings.TrimSuffix(parts[1], "d"), 10, 64)
+		if err == nil && number > 0 {
+			days = number
+		}
+	}
+
+	earliest := now.Add(-time.Duration(days) * 24 * time.Hour)
+	return parts[0], uint64(earliest.UnixNano() / 1000)
+}
+
+// The index term for clients that collected the artifact.
+func collectedTerm(artifact string) string {
+	return "collected:" + strings.ToLower(artifact)
+}
+
+// Index the client by the artifacts it collected so searches only
+// need to look at the clients which collected the artifact at some
+// point. Flows completed before the index was introduced are not
+// indexed.
+func (self *Indexer) ProcessFlowCompletion(
+	ctx context.Context,
+	config_obj *config_proto.Config,
+	row *ordereddict.Dict) error {
+
+	flow := &flows_proto.ArtifactCollectorContext{}
+	flow_any, pres := row.Get("Flow")
+	if !pres {
+		return errors.New("Flow not found")
+	}
+
+	err := utils.ParseIntoProtobuf(flow_any, flow)
+	if err != nil {
+		return err
+	}
+
+	// Server artifacts are not collected from clients.
+	if flow.Request == nil || flow.ClientId == "" ||
+		flow.ClientId == "server" {
+		return nil
+	}
+
+	for _, artifact := range flow.Request.Artifacts {
+		err := self.SetIndex(flow.ClientId, collectedTerm(artifact))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Find all the clients which collected the artifact recently. The
+// index narrows the search to clients which collected the artifact
+// and we then check their flows which are stored in time order so
+// we only need to look at the most recent ones.
+func (self *Indexer) searchCollected(
+	ctx context.Context,
+	config_obj *config_proto.Config,
+	in *api_proto.SearchClientsRequest, client_filter *clientFilter,
+	term string, limit uint64) (*api_proto.SearchClientsResponse, error) {
+
+	// It does not make sense to complete on names.
+	if in.NameOnly {
+		return &api_proto.SearchClientsResponse{}, nil
+	}
+
+	launcher, err := services.GetLauncher(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &api_proto.SearchClientsResponse{}
+	total_count := 0
+
+	now := time.Now()
+	artifact, earliest := parseCollectedTerm(term, now)
+	if artifact == "" {
+		return result, nil
+	}
+
+	flow_filter := func(flow *flows_proto.ArtifactCollectorContext) bool {
+		if flow.CreateTime < earliest || flow.Request == nil {
+			return false
+		}
+
+		for _, name := range flow.Request.Artifacts {
+			if strings.EqualFold(name, artifact) {
+				return true
+			}
+		}
+		return false
+	}
+
+	now_us := uint64(now.UnixNano() / 1000)
+
+	// Match the exact artifact name and not other artifacts with
+	// the same prefix.
+	prefix := collectedTerm(artifact) + "/"
+	for hit := range self.SearchIndexWithPrefix(ctx, config_obj, prefix) {
+		api_client, err := self.FastGetApiClient(ctx, config_obj, hit.Entity)
+		if err != nil {
+			continue
+		}
+
+		// Skip clients that are offline
+		if in.Filter == api_proto.SearchClientsRequest_ONLINE &&
+			now_us > api_client.LastSeenAt &&
+			now_us-api_client.LastSeenAt > 1000000*60*15 {
+			continue
+		}
+
+		if !client_filter.Match(api_client) {
+			continue
+		}
+
+		flows, err := launcher.GetFlows(config_obj, api_client.ClientId,
+			true, flow_filter, 0, MAX_COLLECTED_FLOWS)
+		if err != nil || len(flows.Items) == 0 {
+			continue
+		}
+
+		total_count++
+		if uint64(total_count) <= in.Offset {
+			continue
+		}
+
+		result.Items = append(result.Items, api_client)
+		if uint64(len(result.Items)) >= limit {
+			return result, nil
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,70 @@
+package indexing
+
+import (
+	"strconv"
+	"strings"
+
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+)
+
+// Search cursors are opaque to callers. Searches of the client index
+// continue after the index key of the last client returned, so
+// clients enrolling between pages do not shift the pages. Other
+// searches can only record the offset of the next page.
+const (
+	cursor_key_prefix    = "k:"
+	cursor_offset_prefix = "o:"
+)
+
+// Returns the index key to continue after, if the cursor has one.
+func parseKeyCursor(cursor string) string {
+	if strings.HasPrefix(cursor, cursor_key_prefix) {
+		return strings.TrimPrefix(cursor, cursor_key_prefix)
+	}
+	return ""
+}
+
+// Offset cursors replace the request offset.
+func applyOffsetCursor(in *api_proto.SearchClientsRequest) {
+	if !strings.HasPrefix(in.Cursor, cursor_offset_prefix) {
+		return
+	}
+
+	offset, err := strconv.ParseUint(
+		strings.TrimPrefix(in.Cursor, cursor_offset_prefix), 10, 64)
+	if err == nil {
+		in.Offset = offset
+	}
+}
+
+// Searches which do not support key cursors fetch one more result
+// than the limit to tell if there is another page.
+func searchWithOffsetCursor(
+	in *api_proto.SearchClientsRequest, limit uint64,
+	search func(limit uint64) (*api_proto.SearchClientsResponse, error)) (
+	*api_proto.SearchClientsResponse, error) {
+	applyOffsetCursor(in)
+
+	result, err := search(limit + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	more := false
+	if uint64(len(result.Items)) > limit {
+		result.Items = result.Items[:limit]
+		more = true
+	}
+
+	if uint64(len(result.Names)) > limit {
+		result.Names = result.Names[:limit]
+		more = true
+	}
+
+	if more {
+		result.NextCursor = cursor_offset_prefix +
+			strconv.FormatUint(in.Offset+limit, 10)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,338 @@
+package indexing
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/services/client_info"
+	"www.velocidex.com/golang/velociraptor/utils"
+)
+
+// Structured filters are applied to the full client record after the
+// search term selected the candidates.
+type clientFilter struct {
+	os               string
+	min_version      string
+	max_version      string
+	last_seen_before uint64
+	last_seen_after  uint64
+	labels           labelExpression
+	network          *net.IPNet
+
+	// Metadata is not part of the client record so it is loaded
+	// from the datastore for each candidate.
+	config_obj *config_proto.Config
+	metadata   []*api_proto.ClientMetadataItem
+}
+
+// Returns nil if the request has no filters.
+func newClientFilter(
+	config_obj *config_proto.Config,
+	in *api_proto.SearchClientsRequest) (*clientFilter, error) {
+	filters := in.Filters
+	if filters == nil {
+		return nil, nil
+	}
+
+	result := &clientFilter{
+		os:               strings.ToLower(filters.Os),
+		min_version:      filters.MinVersion,
+		max_version:      filters.MaxVersion,
+		last_seen_before: filters.LastSeenBefore,
+		last_seen_after:  filters.LastSeenAfter,
+		config_obj:       config_obj,
+	}
+
+	if filters.Labels != "" {
+		expression, err := parseLabelExpression(filters.Labels)
+		if err != nil {
+			return nil, fmt.Errorf("%w: labels: %v",
+				services.InvalidSearchFilterError, err)
+		}
+		result.labels = expression
+	}
+
+	if filters.IpCidr != "" {
+		_, network, err := net.ParseCIDR(filters.IpCidr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: ip_cidr: %v",
+				services.InvalidSearchFilterError, err)
+		}
+		result.network = network
+	}
+
+	for _, item := range filters.Metadata {
+		if item.Key == "" {
+			return nil, fmt.Errorf("%w: metadata: key is required",
+				services.InvalidSearchFilterError)
+		}
+		result.metadata = append(result.metadata, item)
+	}
+
+	return result, nil
+}
+
+func (self *clientFilter) Match(api_client *api_proto.ApiClient) bool {
+	if self == nil {
+		return true
+	}
+
+	if self.os != "" &&
+		!strings.EqualFold(api_client.OsInfo.GetSystem(), self.os) {
+		return false
+	}
+
+	version := api_client.AgentInformation.GetVersion()
+	if self.min_version != "" &&
+		(version == "" || utils.CompareVersions(version, self.min_version) < 0) {
+		return false
+	}
+
+	if self.max_version != "" &&
+		(version == "" || utils.CompareVersions(version, self.max_version) > 0) {
+		return false
+	}
+
+	if self.last_seen_before > 0 &&
+		api_client.LastSeenAt >= self.last_seen_before {
+		return false
+	}
+
+	if self.last_seen_after > 0 &&
+		api_client.LastSeenAt <= self.last_seen_after {
+		return false
+	}
+
+	if self.labels != nil && !self.labels.Match(api_client.Labels) {
+		return false
+	}
+
+	if self.network != nil {
+		ip := net.ParseIP(lastIPAddress(api_client.LastIp))
+		if ip == nil || !self.network.Contains(ip) {
+			return false
+		}
+	}
+
+	if len(self.metadata) > 0 {
+		metadata, err := client_info.GetMetadata(
+			self.config_obj, api_client.ClientId)
+		if err != nil || !client_info.MatchMetadata(metadata, self.metadata) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// The last IP is recorded with the remote port.
+func lastIPAddress(last_ip string) string {
+	host, _, err := net.SplitHostPort(last_ip)
+	if err != nil {
+		return last_ip
+	}
+	return host
+}
+
+// A label expression is a boolean combination of labels using AND,
+// OR, NOT and parentheses. NOT binds tighter than AND which binds
+// tighter than OR. Labels containing spaces may be quoted.
+type labelExpression interface {
+	Match(labels []string) bool
+}
+
+type labelTerm string
+
+func (self labelTerm) Match(labels []string) bool {
+	for _, label := range labels {
+		if strings.EqualFold(label, string(self)) {
+			return true
+		}
+	}
+	return false
+}
+
+type labelAnd []labelExpression
+
+func (self labelAnd) Match(labels []string) bool {
+	for _, expression := range self {
+		if !expression.Match(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+type labelOr []labelExpression
+
+func (self labelOr) Match(labels []string) bool {
+	for _, expression := range self {
+		if expression.Match(labels) {
+			return true
+		}
+	}
+	return false
+}
+
+type labelNot struct {
+	expression labelExpression
+}
+
+func (self labelNot) Match(labels []string) bool {
+	return !self.expression.Match(labels)
+}
+
+type labelParser struct {
+	tokens []string
+	pos    int
+}
+
+func parseLabelExpression(expression string) (labelExpression, error) {
+	tokens, err := tokenizeLabelExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := &labelParser{tokens: tokens}
+	result, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if parser.pos < len(tokens) {
+		return nil, fmt.Errorf("unexpected %q", tokens[parser.pos])
+	}
+	return result, nil
+}
+
+func tokenizeLabelExpression(expression string) ([]string, error) {
+	result := []string{}
+	current := ""
+
+	flush := func() {
+		if current != "" {
+			result = append(result, current)
+			current = ""
+		}
+	}
+
+	for i := 0; i < len(expression); i++ {
+		c := expression[i]
+		switch c {
+		case ' ', '\t', '\n':
+			flush()
+
+		case '(', ')':
+			flush()
+			result = append(result, string(c))
+
+		case '"':
+			flush()
+			end := strings.IndexByte(expression[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quote")
+			}
+			// Quoted labels are never operators.
+			result = append(result, "\""+expression[i+1:i+1+end])
+			i += end + 1
+
+		default:
+			current += string(c)
+		}
+	}
+	flush()
+
+	return result, nil
+}
+
+func (self *labelParser) peek() string {
+	if self.pos < len(self.tokens) {
+		return self.tokens[self.pos]
+	}
+	return ""
+}
+
+func (self *labelParser) isOperator(operator string) bool {
+	return strings.EqualFold(self.peek(), operator)
+}
+
+func (self *labelParser) parseOr() (labelExpression, error) {
+	result := labelOr{}
+	for {
+		expression, err := self.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, expression)
+
+		if !self.isOperator("OR") {
+			break
+		}
+		self.pos++
+	}
+
+	if len(result) == 1 {
+		return result[0], nil
+	}
+	return result, nil
+}
+
+func (self *labelParser) parseAnd() (labelExpression, error) {
+	result := labelAnd{}
+	for {
+		expression, err := self.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, expression)
+
+		if !self.isOperator("AND") {
+			break
+		}
+		self.pos++
+	}
+
+	if len(result) == 1 {
+		return result[0], nil
+	}
+	return result, nil
+}
+
+func (self *labelParser) parseNot() (labelExpression, error) {
+	if self.isOperator("NOT") {
+		self.pos++
+		expression, err := self.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return labelNot{expression}, nil
+	}
+
+	token := self.peek()
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("unexpected end of expression")
+
+	case token == "(":
+		self.pos++
+		expression, err := self.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if self.peek() != ")" {
+			return nil, fmt.Errorf("missing )")
+		}
+		self.pos++
+		return expression, nil
+
+	case token == ")", self.isOperator("AND"), self.isOperator("OR"):
+		return nil, fmt.Errorf("unexpected %q", token)
+	}
+
+	self.pos++
+	return labelTerm(strings.TrimPrefix(token, "\"")), nil
+}
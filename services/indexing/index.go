@@ -56,6 +56,7 @@ import (
 	"www.velocidex.com/golang/velociraptor/paths"
 	"www.velocidex.com/golang/velociraptor/result_sets"
 	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/services/journal"
 	"www.velocidex.com/golang/velociraptor/utils"
 )
 
@@ -462,5 +463,12 @@ func NewIndexingService(ctx context.Context, wg *sync.WaitGroup,
 	indexer := NewIndexer(config_obj)
 	indexer.Start(ctx, wg, config_obj)
 
+	err := journal.WatchQueueWithCB(ctx, config_obj, wg,
+		"System.Flow.Completion", "Indexer",
+		indexer.ProcessFlowCompletion)
+	if err != nil {
+		return nil, err
+	}
+
 	return indexer, nil
 }
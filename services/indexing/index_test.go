@@ -10,6 +10,8 @@ import (
 	"github.com/stretchr/testify/suite"
 	actions_proto "www.velocidex.com/golang/velociraptor/actions/proto"
 	"www.velocidex.com/golang/velociraptor/datastore"
+	"www.velocidex.com/golang/velociraptor/file_store"
+	"www.velocidex.com/golang/velociraptor/file_store/memory"
 	"www.velocidex.com/golang/velociraptor/file_store/test_utils"
 	"www.velocidex.com/golang/velociraptor/paths"
 	"www.velocidex.com/golang/velociraptor/services"
@@ -35,6 +37,19 @@ func (self *TestSuite) SetupTest() {
 	self.populatedClients()
 }
 
+func (self *TestSuite) TearDownTest() {
+	self.TestSuite.TearDownTest()
+
+	// The indexer writes a snapshot when it exits which must not be
+	// loaded by the next test.
+	self.Wg.Wait()
+	file_store_factory, ok := file_store.GetFileStore(
+		self.ConfigObj).(*memory.MemoryFileStore)
+	if ok {
+		file_store_factory.Clear()
+	}
+}
+
 // Make some clients in the index.
 func (self *TestSuite) populatedClients() {
 	self.clients = nil
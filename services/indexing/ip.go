@@ -13,7 +13,7 @@ import (
 func (self *Indexer) searchLastIP(
 	ctx context.Context,
 	config_obj *config_proto.Config,
-	in *api_proto.SearchClientsRequest,
+	in *api_proto.SearchClientsRequest, client_filter *clientFilter,
 	term string, limit uint64) (*api_proto.SearchClientsResponse, error) {
 
 	// It does not make sense to complete on names.
@@ -51,13 +51,17 @@ func (self *Indexer) searchLastIP(
 			continue
 		}
 
+		if !client_filter.Match(api_client) {
+			continue
+		}
+
 		total_count++
-		if uint64(total_count) < in.Offset {
+		if uint64(total_count) <= in.Offset {
 			continue
 		}
 
 		result.Items = append(result.Items, api_client)
-		if uint64(len(result.Items)) > limit {
+		if uint64(len(result.Items)) >= limit {
 			return result, nil
 		}
 	}
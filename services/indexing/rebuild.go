@@ -9,6 +9,7 @@ import (
 	"www.velocidex.com/golang/velociraptor/datastore"
 	"www.velocidex.com/golang/velociraptor/logging"
 	"www.velocidex.com/golang/velociraptor/paths"
+	"www.velocidex.com/golang/velociraptor/services"
 )
 
 // Load all the client records slowly and rebuild the index. This
@@ -67,6 +68,17 @@ func (self *Indexer) LoadIndexFromDatastore(
 				self.SetIndex(client_id, "mac:"+mac)
 			}
 		}
+
+		// Add the network and location facets.
+		for _, term := range services.NetworkContextSearchTerms(
+			client_info.NetworkContext) {
+			self.SetIndex(client_id, term)
+		}
+
+		for _, term := range services.GeoLocationSearchTerms(
+			client_info.GeoLocation) {
+			self.SetIndex(client_id, term)
+		}
 	}
 
 	logger.Info("<green>Indexing service</> search index loaded %v items in %v",
@@ -13,6 +13,7 @@ import (
 	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
 	"www.velocidex.com/golang/velociraptor/datastore"
 	"www.velocidex.com/golang/velociraptor/paths"
+	"www.velocidex.com/golang/velociraptor/services"
 	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
 )
 
@@ -24,6 +25,13 @@ var (
 		"client:",
 		"recent:",
 		"ip:",
+		"collected:",
+		"ssid:",
+		"gateway:",
+		"dns:",
+		"country:",
+		"city:",
+		"pool:",
 	}
 )
 
@@ -50,7 +58,7 @@ func splitIntoOperatorAndTerms(term string) (string, string) {
 func (self *Indexer) searchRecents(
 	ctx context.Context,
 	config_obj *config_proto.Config,
-	in *api_proto.SearchClientsRequest,
+	in *api_proto.SearchClientsRequest, client_filter *clientFilter,
 	principal string, term string, limit uint64) (
 	*api_proto.SearchClientsResponse, error) {
 	path_manager := &paths.UserPathManager{principal}
@@ -81,15 +89,15 @@ func (self *Indexer) searchRecents(
 		return metadata[i].FirstSeenAt > metadata[j].FirstSeenAt
 	})
 
+	skip_decommissioned := skipDecommissioned(in)
 	for _, md := range metadata {
 		client_id := md.ClientId
-		api_client, err := self.FastGetApiClient(ctx, config_obj, client_id)
-		if err != nil {
+		if skip_decommissioned &&
+			services.IsDecommissioned(config_obj, client_id) {
 			continue
 		}
-
-		total_count++
-		if uint64(total_count) < in.Offset {
+		api_client, err := self.FastGetApiClient(ctx, config_obj, client_id)
+		if err != nil {
 			continue
 		}
 
@@ -100,9 +108,18 @@ func (self *Indexer) searchRecents(
 			continue
 		}
 
+		if !client_filter.Match(api_client) {
+			continue
+		}
+
+		total_count++
+		if uint64(total_count) <= in.Offset {
+			continue
+		}
+
 		result.Items = append(result.Items, api_client)
 
-		if uint64(len(result.Items)) > limit {
+		if uint64(len(result.Items)) >= limit {
 			break
 		}
 	}
@@ -110,6 +127,13 @@ func (self *Indexer) searchRecents(
 	return result, nil
 }
 
+// Decommissioned clients are hidden unless the caller asks for them
+// or searches for their label.
+func skipDecommissioned(in *api_proto.SearchClientsRequest) bool {
+	return !in.IncludeDecommissioned && !strings.EqualFold(in.Query,
+		"label:"+services.DECOMMISSIONED_LABEL)
+}
+
 func (self *Indexer) SearchClients(
 	ctx context.Context,
 	config_obj *config_proto.Config,
@@ -121,30 +145,52 @@ func (self *Indexer) SearchClients(
 		limit = in.Limit
 	}
 
+	client_filter, err := newClientFilter(config_obj, in)
+	if err != nil {
+		return nil, err
+	}
+
+	// Filters alone apply to all clients.
+	if client_filter != nil && in.Query == "" {
+		in.Query = "all"
+	}
+
 	operator, term := splitIntoOperatorAndTerms(in.Query)
 	switch operator {
-	case "label", "host", "all", "mac":
-		return self.searchClientIndex(ctx, config_obj, in, limit)
+	case "label", "host", "all", "mac",
+		"ssid", "gateway", "dns", "country", "city", "pool":
+		return self.searchClientIndex(ctx, config_obj, in, client_filter, limit)
 
 	case "client":
 		in.Query = term
-		return self.searchClientIndex(ctx, config_obj, in, limit)
+		return self.searchClientIndex(ctx, config_obj, in, client_filter, limit)
+	}
 
-	case "recent":
-		return self.searchRecents(ctx, config_obj, in, principal, term, limit)
+	return searchWithOffsetCursor(in, limit,
+		func(limit uint64) (*api_proto.SearchClientsResponse, error) {
+			switch operator {
+			case "recent":
+				return self.searchRecents(ctx, config_obj, in, client_filter,
+					principal, term, limit)
 
-	case "ip":
-		return self.searchLastIP(ctx, config_obj, in, term, limit)
+			case "ip":
+				return self.searchLastIP(ctx, config_obj, in, client_filter,
+					term, limit)
 
-	default:
-		return self.searchVerbs(ctx, config_obj, in, limit)
-	}
+			case "collected":
+				return self.searchCollected(ctx, config_obj, in, client_filter,
+					term, limit)
+
+			default:
+				return self.searchVerbs(ctx, config_obj, in, client_filter, limit)
+			}
+		})
 }
 
 func (self *Indexer) searchClientIndex(
 	ctx context.Context,
 	config_obj *config_proto.Config,
-	in *api_proto.SearchClientsRequest,
+	in *api_proto.SearchClientsRequest, client_filter *clientFilter,
 	limit uint64) (*api_proto.SearchClientsResponse, error) {
 
 	if !self.Ready() {
@@ -156,14 +202,23 @@ func (self *Indexer) searchClientIndex(
 
 	seen := make(map[string]bool)
 	result := &api_proto.SearchClientsResponse{}
-	total_count := 0
 	options := OPTION_CLIENT_RECORDS
 	if in.NameOnly {
 		options = OPTION_NAME_ONLY
 	}
 
+	// Continue after the last client of the previous page or skip
+	// the offset.
+	after := parseKeyCursor(in.Cursor)
+	applyOffsetCursor(in)
+
+	// The index key of the last hit returned.
+	last_key := ""
+	more := false
+
 	scope := vql_subsystem.MakeScope()
 	prefix, filter := splitSearchTermIntoPrefixAndFilter(scope, in.Query)
+	skip_decommissioned := skipDecommissioned(in)
 	for hit := range self.SearchIndexWithPrefix(ctx, config_obj, prefix) {
 		if hit == nil {
 			continue
@@ -184,39 +239,73 @@ func (self *Indexer) searchClientIndex(
 		if pres {
 			continue
 		}
-		seen[key] = true
 
-		total_count++
-		if uint64(total_count) < in.Offset {
+		if skip_decommissioned &&
+			services.IsDecommissioned(config_obj, hit.Entity) {
 			continue
 		}
 
-		switch options {
-		case OPTION_CLIENT_RECORDS:
-			api_client, err := self.FastGetApiClient(ctx, config_obj, hit.Entity)
+		// Checking if the client is online or matches the filters
+		// needs the full record.
+		var api_client *api_proto.ApiClient
+		check_online := options == OPTION_CLIENT_RECORDS &&
+			in.Filter == api_proto.SearchClientsRequest_ONLINE
+		if check_online || client_filter != nil {
+			var err error
+			api_client, err = self.FastGetApiClient(ctx, config_obj, hit.Entity)
 			if err != nil {
 				continue
 			}
 
 			// Skip clients that are offline
-			if in.Filter == api_proto.SearchClientsRequest_ONLINE &&
-				now > api_client.LastSeenAt &&
+			if check_online && now > api_client.LastSeenAt &&
 				now-api_client.LastSeenAt > 1000000*60*15 {
 				continue
 			}
 
-			result.Items = append(result.Items, api_client)
-			if uint64(len(result.Items)) > limit {
-				return result, nil
+			// A name is suggested if any of its clients match.
+			if !client_filter.Match(api_client) {
+				continue
+			}
+		}
+		seen[key] = true
+
+		// Keep counting past the page for the total.
+		result.TotalHits++
+		index_key := NewRecord(hit).IndexTerm
+		if after != "" {
+			if index_key <= after {
+				continue
+			}
+		} else if result.TotalHits <= in.Offset {
+			continue
+		}
+
+		if uint64(len(result.Items)+len(result.Names)) >= limit {
+			more = true
+			continue
+		}
+
+		switch options {
+		case OPTION_CLIENT_RECORDS:
+			if api_client == nil {
+				var err error
+				api_client, err = self.FastGetApiClient(ctx, config_obj, hit.Entity)
+				if err != nil {
+					result.TotalHits--
+					continue
+				}
 			}
+			result.Items = append(result.Items, api_client)
 
 		case OPTION_NAME_ONLY:
 			result.Names = append(result.Names, hit.Term)
-			if uint64(len(result.Names)) > limit {
-				return result, nil
-			}
 		}
+		last_key = index_key
+	}
 
+	if more {
+		result.NextCursor = cursor_key_prefix + last_key
 	}
 
 	return result, nil
@@ -226,7 +315,7 @@ func (self *Indexer) searchClientIndex(
 // possible.
 func (self *Indexer) searchVerbs(ctx context.Context,
 	config_obj *config_proto.Config,
-	in *api_proto.SearchClientsRequest,
+	in *api_proto.SearchClientsRequest, client_filter *clientFilter,
 	limit uint64) (*api_proto.SearchClientsResponse, error) {
 
 	terms := []string{}
@@ -248,7 +337,9 @@ func (self *Indexer) searchVerbs(ctx context.Context,
 				Query:    "host:" + in.Query,
 				Limit:    in.Limit,
 				Filter:   in.Filter,
-			}, limit)
+
+				IncludeDecommissioned: in.IncludeDecommissioned,
+			}, client_filter, limit)
 		if err == nil {
 			terms = append(terms, res.Names...)
 			items = append(items, res.Items...)
@@ -263,7 +354,9 @@ func (self *Indexer) searchVerbs(ctx context.Context,
 				Query:    "label:" + in.Query,
 				Filter:   in.Filter,
 				Limit:    in.Limit,
-			}, limit)
+
+				IncludeDecommissioned: in.IncludeDecommissioned,
+			}, client_filter, limit)
 		if err == nil {
 			terms = append(terms, res.Names...)
 			items = append(items, res.Items...)
@@ -2,14 +2,23 @@ package indexing_test
 
 import (
 	"context"
+	"errors"
 	"strings"
+	"time"
 
 	"github.com/Velocidex/ordereddict"
 	"github.com/alecthomas/assert"
 	"github.com/sebdah/goldie"
+	actions_proto "www.velocidex.com/golang/velociraptor/actions/proto"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/datastore"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
 	"www.velocidex.com/golang/velociraptor/json"
+	"www.velocidex.com/golang/velociraptor/paths"
 	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/services/client_info"
 	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/velociraptor/vtesting"
 )
 
 func (self *TestSuite) TestWildCardSearch() {
@@ -66,3 +75,291 @@ func (self *TestSuite) TestPrefixSearch() {
 	}
 	assert.Equal(self.T(), prefixed_clients, searched_clients)
 }
+
+func (self *TestSuite) TestCollectedSearch() {
+	db, err := datastore.GetDB(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	now := uint64(time.Now().UnixNano() / 1000)
+	day := uint64(24 * time.Hour / time.Microsecond)
+
+	indexer, err := services.GetIndexer(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	journal, err := services.GetJournal(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	// One recent collection, one too old and one of another artifact.
+	for _, flow := range []struct {
+		client_id, flow_id, artifact string
+		create_time                  uint64
+	}{
+		{self.clients[0], "F.1", "Generic.Client.Info", now - day},
+		{self.clients[1], "F.2", "Generic.Client.Info", now - 10*day},
+		{self.clients[2], "F.3", "Windows.Sys.Users", now - day},
+	} {
+		flow_context := &flows_proto.ArtifactCollectorContext{
+			SessionId:  flow.flow_id,
+			ClientId:   flow.client_id,
+			CreateTime: flow.create_time,
+			Request: &flows_proto.ArtifactCollectorArgs{
+				Artifacts: []string{flow.artifact},
+			},
+		}
+
+		path_manager := paths.NewFlowPathManager(flow.client_id, flow.flow_id)
+		err = db.SetSubject(self.ConfigObj, path_manager.Path(), flow_context)
+		assert.NoError(self.T(), err)
+
+		// The completion event adds the client to the index.
+		err = journal.PushRowsToArtifact(self.ConfigObj,
+			[]*ordereddict.Dict{ordereddict.NewDict().
+				Set("Flow", flow_context).
+				Set("FlowId", flow.flow_id).
+				Set("ClientId", flow.client_id)},
+			"System.Flow.Completion", flow.client_id, flow.flow_id)
+		assert.NoError(self.T(), err)
+	}
+
+	vtesting.WaitUntil(5*time.Second, self.T(), func() bool {
+		count := 0
+		for range indexer.SearchIndexWithPrefix(
+			context.Background(), self.ConfigObj, "collected:") {
+			count++
+		}
+		return count == 3
+	})
+
+	results := ordereddict.NewDict()
+	for _, search_term := range []string{
+		"collected:Generic.Client.Info",
+		"collected:generic.client.info:30",
+		"collected:Windows.Sys.Users",
+	} {
+		res, err := indexer.SearchClients(context.Background(),
+			self.ConfigObj, &api_proto.SearchClientsRequest{
+				Query: search_term,
+			}, "")
+		assert.NoError(self.T(), err)
+
+		searched_clients := []string{}
+		for _, item := range res.Items {
+			searched_clients = append(searched_clients, item.ClientId)
+		}
+		results.Set(search_term, searched_clients)
+	}
+
+	goldie.Assert(self.T(), "TestCollectedSearch",
+		json.MustMarshalIndent(results))
+}
+
+func (self *TestSuite) TestSearchCursor() {
+	indexer, err := services.GetIndexer(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	for _, client_id := range self.clients {
+		err = indexer.SetIndex(client_id, "all")
+		assert.NoError(self.T(), err)
+	}
+
+	seen := make(map[string]bool)
+	cursor := ""
+	for page := 0; ; page++ {
+		res, err := indexer.SearchClients(context.Background(),
+			self.ConfigObj, &api_proto.SearchClientsRequest{
+				Query:  "all",
+				Limit:  10,
+				Cursor: cursor,
+			}, "")
+		assert.NoError(self.T(), err)
+
+		for _, item := range res.Items {
+			assert.False(self.T(), seen[item.ClientId], item.ClientId)
+			seen[item.ClientId] = true
+		}
+
+		// A client enrolling while paging sorts before the cursor
+		// and must not shift the following pages.
+		if page == 0 {
+			assert.Equal(self.T(), uint64(len(self.clients)), res.TotalHits)
+
+			new_client := "C.0000000000000000"
+			db, err := datastore.GetDB(self.ConfigObj)
+			assert.NoError(self.T(), err)
+
+			err = db.SetSubject(self.ConfigObj,
+				paths.NewClientPathManager(new_client).Path(),
+				&actions_proto.ClientInfo{ClientId: new_client})
+			assert.NoError(self.T(), err)
+
+			err = indexer.SetIndex(new_client, "all")
+			assert.NoError(self.T(), err)
+		}
+
+		cursor = res.NextCursor
+		if cursor == "" {
+			break
+		}
+	}
+
+	for _, client_id := range self.clients {
+		assert.True(self.T(), seen[client_id], client_id)
+	}
+}
+
+func (self *TestSuite) TestSearchDecommissioned() {
+	indexer, err := services.GetIndexer(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	for _, client_id := range self.clients {
+		err = indexer.SetIndex(client_id, "all")
+		assert.NoError(self.T(), err)
+	}
+
+	decommissioned := self.clients[0]
+	err = services.GetLabeler(self.ConfigObj).SetClientLabel(
+		self.ConfigObj, decommissioned, services.DECOMMISSIONED_LABEL)
+	assert.NoError(self.T(), err)
+
+	search := func(in *api_proto.SearchClientsRequest) []string {
+		in.Limit = 1000
+		res, err := indexer.SearchClients(context.Background(),
+			self.ConfigObj, in, "")
+		assert.NoError(self.T(), err)
+
+		result := []string{}
+		for _, item := range res.Items {
+			result = append(result, item.ClientId)
+		}
+		return result
+	}
+
+	// Hidden by default.
+	hits := search(&api_proto.SearchClientsRequest{Query: "all"})
+	assert.Equal(self.T(), len(self.clients)-1, len(hits))
+	assert.NotContains(self.T(), hits, decommissioned)
+
+	hits = search(&api_proto.SearchClientsRequest{
+		Query: "all", IncludeDecommissioned: true})
+	assert.Equal(self.T(), len(self.clients), len(hits))
+
+	// Searching for the label finds them.
+	hits = search(&api_proto.SearchClientsRequest{
+		Query: "label:decommissioned"})
+	assert.Equal(self.T(), []string{decommissioned}, hits)
+}
+
+func (self *TestSuite) TestSearchFilters() {
+	db, err := datastore.GetDB(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	indexer, err := services.GetIndexer(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	now := uint64(time.Now().UnixNano() / 1000)
+	day := uint64(24 * time.Hour / time.Microsecond)
+
+	labeler := services.GetLabeler(self.ConfigObj)
+	for i, record := range []*actions_proto.ClientInfo{
+		{System: "windows", ClientVersion: "0.6.4", Ping: now - 40*day,
+			IpAddress: "10.1.2.3:4567", Labels: []string{"Prod", "Web"}},
+		{System: "windows", ClientVersion: "0.6.5", Ping: now,
+			IpAddress: "10.2.2.3:4567", Labels: []string{"Prod", "DB"}},
+		{System: "linux", ClientVersion: "0.6.5-rc1", Ping: now - 40*day,
+			IpAddress: "10.1.9.9:4567", Labels: []string{"Web", "Quarantine"}},
+		{System: "darwin", ClientVersion: "0.6.6", Ping: now,
+			IpAddress: "192.168.1.1:4567"},
+	} {
+		client_id := self.clients[i]
+		record.ClientId = client_id
+		err = db.SetSubject(self.ConfigObj,
+			paths.NewClientPathManager(client_id).Path(), record)
+		assert.NoError(self.T(), err)
+
+		for _, label := range record.Labels {
+			err = labeler.SetClientLabel(self.ConfigObj, client_id, label)
+			assert.NoError(self.T(), err)
+		}
+
+		err = indexer.SetIndex(client_id, "all")
+		assert.NoError(self.T(), err)
+	}
+
+	search := func(filters *api_proto.ClientSearchFilters) []string {
+		res, err := indexer.SearchClients(context.Background(),
+			self.ConfigObj, &api_proto.SearchClientsRequest{
+				Limit:   1000,
+				Filters: filters,
+			}, "")
+		assert.NoError(self.T(), err)
+
+		result := []string{}
+		for _, item := range res.Items {
+			result = append(result, item.ClientId)
+		}
+		return result
+	}
+
+	// All Windows clients not seen in 30 days.
+	assert.Equal(self.T(), []string{self.clients[0]},
+		search(&api_proto.ClientSearchFilters{
+			Os: "Windows", LastSeenBefore: now - 30*day}))
+
+	assert.Equal(self.T(), []string{self.clients[1], self.clients[3]},
+		search(&api_proto.ClientSearchFilters{LastSeenAfter: now - day}))
+
+	// Pre-releases sort before the release.
+	assert.Equal(self.T(), []string{self.clients[0], self.clients[2]},
+		search(&api_proto.ClientSearchFilters{MaxVersion: "0.6.5-rc2"}))
+
+	assert.Equal(self.T(), []string{self.clients[1], self.clients[3]},
+		search(&api_proto.ClientSearchFilters{MinVersion: "0.6.5"}))
+
+	assert.Equal(self.T(), []string{self.clients[0], self.clients[2]},
+		search(&api_proto.ClientSearchFilters{IpCidr: "10.1.0.0/16"}))
+
+	assert.Equal(self.T(), []string{self.clients[0], self.clients[1]},
+		search(&api_proto.ClientSearchFilters{
+			Labels: "prod AND (web OR db)"}))
+
+	assert.Equal(self.T(), []string{self.clients[0]},
+		search(&api_proto.ClientSearchFilters{
+			Labels: "Web AND NOT Quarantine"}))
+
+	assert.Equal(self.T(), []string{self.clients[1], self.clients[2]},
+		search(&api_proto.ClientSearchFilters{Labels: "DB OR Quarantine"}))
+
+	// Metadata keys and values are compared case insensitively.
+	for i, owner := range []string{"alice", "bob", "Alice"} {
+		err = client_info.SetMetadata(self.ConfigObj, &api_proto.ClientMetadata{
+			ClientId: self.clients[i],
+			Items: []*api_proto.ClientMetadataItem{
+				{Key: "Owner", Value: owner},
+			},
+		})
+		assert.NoError(self.T(), err)
+	}
+
+	assert.Equal(self.T(), []string{self.clients[0], self.clients[2]},
+		search(&api_proto.ClientSearchFilters{
+			Metadata: []*api_proto.ClientMetadataItem{
+				{Key: "owner", Value: "ALICE"}}}))
+
+	assert.Equal(self.T(), []string{self.clients[0], self.clients[1], self.clients[2]},
+		search(&api_proto.ClientSearchFilters{
+			Metadata: []*api_proto.ClientMetadataItem{{Key: "Owner"}}}))
+
+	// Invalid filters are rejected.
+	for _, filters := range []*api_proto.ClientSearchFilters{
+		{Labels: "Prod AND"},
+		{Labels: "(Prod OR Web"},
+		{IpCidr: "10.1.0.0"},
+		{Metadata: []*api_proto.ClientMetadataItem{{Value: "alice"}}},
+	} {
+		_, err := indexer.SearchClients(context.Background(),
+			self.ConfigObj, &api_proto.SearchClientsRequest{
+				Filters: filters,
+			}, "")
+		assert.True(self.T(), errors.Is(err, services.InvalidSearchFilterError))
+	}
+}
@@ -14,7 +14,7 @@
   it is likely to become outdated.
 
   Once the Generic.Client.Info collection is complete we process the
-  results, update indexes etc. When this is done we emit a
+  results, update indexes, apply the automatic labeling rules etc. When this is done we emit a
   Server.Internal.Interrogation event. Queries that are interested in
   new interrogation results need to watch for
   Server.Internal.Interrogation to ensure they do not race with the
@@ -238,6 +238,7 @@ func (self *EnrollmentService) ProcessInterrogateResults(
 			ClientVersion:               getter("BuildTime"),
 			LastInterrogateFlowId:       flow_id,
 			LastInterrogateArtifactName: artifact,
+			EphemeralPool:               getter("EphemeralPool"),
 		}
 
 		label_array, ok := row.GetStrings("Labels")
@@ -245,6 +246,20 @@ func (self *EnrollmentService) ProcessInterrogateResults(
 			client_info.Labels = append(client_info.Labels, label_array...)
 		}
 
+		// Ephemeral clients are labeled with their pool so they
+		// can be targeted as a group.
+		if client_info.EphemeralPool != "" {
+			client_info.Labels = append(client_info.Labels,
+				client_info.EphemeralPool)
+		}
+
+		// Older clients do not report capabilities at all.
+		capabilities, ok := row.GetStrings("Capabilities")
+		if ok {
+			client_info.Capabilities = capabilities
+			client_info.CapabilitiesAdvertised = true
+		}
+
 		mac_addresses, ok := row.GetStrings("MACAddresses")
 		if ok {
 			client_info.MacAddresses = append(
@@ -257,6 +272,9 @@ func (self *EnrollmentService) ProcessInterrogateResults(
 		return errors.New("No Generic.Client.Info results")
 	}
 
+	client_info.NetworkContext = readNetworkContext(
+		ctx, config_obj, client_id, flow_id, artifact)
+
 	client_path_manager := paths.NewClientPathManager(client_id)
 	db, err := datastore.GetDB(config_obj)
 	if err != nil {
@@ -278,6 +296,13 @@ func (self *EnrollmentService) ProcessInterrogateResults(
 		return err
 	}
 
+	// Remember the previous network so we can update the index.
+	var old_network *actions_proto.NetworkContext
+	old_client_info, err := client_info_manager.Get(client_id)
+	if err == nil {
+		old_network = old_client_info.NetworkContext
+	}
+
 	journal, err := services.GetJournal(config_obj)
 	if err != nil {
 		return err
@@ -310,11 +335,31 @@ func (self *EnrollmentService) ProcessInterrogateResults(
 		}
 	}
 
+	// Rule based labels are re-evaluated on every interrogation.
+	err = applyAutoLabelRules(ctx, config_obj, client_info, flow_id, artifact)
+	if err != nil {
+		return err
+	}
+
 	indexer, err := services.GetIndexer(config_obj)
 	if err != nil {
 		return err
 	}
 
+	// The network facets reflect the latest interrogation only.
+	for _, term := range services.NetworkContextSearchTerms(old_network) {
+		indexer.UnsetIndex(client_id, term)
+	}
+
+	for _, term := range services.NetworkContextSearchTerms(
+		client_info.NetworkContext) {
+		err := indexer.SetIndex(client_id, term)
+		if err != nil {
+			logger := logging.GetLogger(config_obj, &logging.FrontendComponent)
+			logger.Error("Unable to set index: %v", err)
+		}
+	}
+
 	// Add MAC addresses to the index.
 	for _, mac := range client_info.MacAddresses {
 		err := indexer.SetIndex(client_id, "mac:"+mac)
@@ -326,9 +371,15 @@ func (self *EnrollmentService) ProcessInterrogateResults(
 
 	// Update the client indexes for the GUI. Add any keywords we
 	// wish to be searchable in the UI here.
-	for _, term := range []string{
+	terms := []string{
 		"host:" + client_info.Fqdn,
-		"host:" + client_info.Hostname} {
+		"host:" + client_info.Hostname}
+	if client_info.EphemeralPool != "" {
+		terms = append(terms, services.EPHEMERAL_POOL_PREFIX+
+			client_info.EphemeralPool)
+	}
+
+	for _, term := range terms {
 		err := indexer.SetIndex(client_id, term)
 		if err != nil {
 			logger := logging.GetLogger(config_obj, &logging.FrontendComponent)
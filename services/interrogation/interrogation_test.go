@@ -1,6 +1,7 @@
 package interrogation_test
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -8,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	actions_proto "www.velocidex.com/golang/velociraptor/actions/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
 	"www.velocidex.com/golang/velociraptor/datastore"
 	"www.velocidex.com/golang/velociraptor/file_store/api"
 	"www.velocidex.com/golang/velociraptor/file_store/test_utils"
@@ -103,6 +105,122 @@ func (self *ServicesTestSuite) TestInterrogationService() {
 	assert.NoError(self.T(), err)
 }
 
+func (self *ServicesTestSuite) TestNetworkContext() {
+	journal, err := services.GetJournal(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	// The NetworkContext source is written before the flow completes.
+	journal.PushRowsToArtifact(self.ConfigObj,
+		[]*ordereddict.Dict{ordereddict.NewDict().
+			Set("Interfaces", []*ordereddict.Dict{ordereddict.NewDict().
+				Set("Name", "eth0").
+				Set("MAC", "00:11:22:33:44:55").
+				Set("Addresses", []string{"10.0.0.5"})}).
+			Set("Gateways", []string{"10.0.0.1"}).
+			Set("DNSServers", []string{"8.8.8.8", "8.8.8.8", ""}).
+			Set("SSIDs", []string{"CorpWifi"})},
+		"Generic.Client.Info/NetworkContext", self.client_id, self.flow_id)
+
+	self.EmulateCollection(
+		"Generic.Client.Info/BasicInformation", []*ordereddict.Dict{
+			ordereddict.NewDict().
+				Set("ClientId", self.client_id).
+				Set("Hostname", "MyHost"),
+		})
+
+	db, err := datastore.GetDB(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	client_path_manager := paths.NewClientPathManager(self.client_id)
+	client_info := &actions_proto.ClientInfo{}
+
+	vtesting.WaitUntil(2*time.Second, self.T(), func() bool {
+		db.GetSubject(self.ConfigObj, client_path_manager.Path(), client_info)
+		return client_info.NetworkContext != nil
+	})
+
+	assert.Equal(self.T(), []string{"CorpWifi"}, client_info.NetworkContext.Ssids)
+	assert.Equal(self.T(), []string{"8.8.8.8"}, client_info.NetworkContext.DnsServers)
+	assert.Equal(self.T(), 1, len(client_info.NetworkContext.Interfaces))
+
+	// The client is searchable by its network.
+	indexer, err := services.GetIndexer(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	hits := []string{}
+	for hit := range indexer.SearchIndexWithPrefix(
+		context.Background(), self.ConfigObj, "ssid:corpwifi") {
+		hits = append(hits, hit.Entity)
+	}
+	assert.Equal(self.T(), []string{self.client_id}, hits)
+}
+
+func (self *ServicesTestSuite) TestAutoLabelRules() {
+	self.ConfigObj.AutoLabelRules = []*config_proto.AutoLabelRule{{
+		Label:    "Workstations",
+		Os:       "^windows$",
+		IpRanges: []string{"10.0.0.0/8"},
+	}, {
+		Label:             "Chrome",
+		InstalledSoftware: "google chrome",
+	}, {
+		Label:              "DomainControllers",
+		OrganizationalUnit: "OU=Domain Controllers",
+	}}
+
+	journal, err := services.GetJournal(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	journal.PushRowsToArtifact(self.ConfigObj,
+		[]*ordereddict.Dict{ordereddict.NewDict().
+			Set("OU", "CN=MyHost,OU=Workstations,DC=corp,DC=com").
+			Set("Software", []string{"Google Chrome", "7-Zip"})},
+		"Generic.Client.Info/Inventory", self.client_id, self.flow_id)
+
+	journal.PushRowsToArtifact(self.ConfigObj,
+		[]*ordereddict.Dict{ordereddict.NewDict().
+			Set("Interfaces", []*ordereddict.Dict{ordereddict.NewDict().
+				Set("Name", "eth0").
+				Set("Addresses", []string{"10.1.2.3/16"})})},
+		"Generic.Client.Info/NetworkContext", self.client_id, self.flow_id)
+
+	self.EmulateCollection(
+		"Generic.Client.Info/BasicInformation", []*ordereddict.Dict{
+			ordereddict.NewDict().
+				Set("ClientId", self.client_id).
+				Set("Hostname", "MyHost").
+				Set("OS", "windows"),
+		})
+
+	labeler := services.GetLabeler(self.ConfigObj)
+	vtesting.WaitUntil(2*time.Second, self.T(), func() bool {
+		return labeler.IsLabelSet(self.ConfigObj, self.client_id, "Workstations") &&
+			labeler.IsLabelSet(self.ConfigObj, self.client_id, "Chrome")
+	})
+	assert.False(self.T(), labeler.IsLabelSet(
+		self.ConfigObj, self.client_id, "DomainControllers"))
+
+	// The rules are re-evaluated on the next interrogation and
+	// labels which no longer match are removed.
+	err = labeler.SetClientLabel(self.ConfigObj, self.client_id, "DomainControllers")
+	assert.NoError(self.T(), err)
+
+	self.flow_id = "F.1233"
+	self.EmulateCollection(
+		"Generic.Client.Info/BasicInformation", []*ordereddict.Dict{
+			ordereddict.NewDict().
+				Set("ClientId", self.client_id).
+				Set("Hostname", "MyHost").
+				Set("OS", "linux"),
+		})
+
+	vtesting.WaitUntil(2*time.Second, self.T(), func() bool {
+		return !labeler.IsLabelSet(self.ConfigObj, self.client_id, "Workstations") &&
+			!labeler.IsLabelSet(self.ConfigObj, self.client_id, "Chrome") &&
+			!labeler.IsLabelSet(self.ConfigObj, self.client_id, "DomainControllers")
+	})
+}
+
 func (self *ServicesTestSuite) TestEnrollService() {
 	enroll_message := ordereddict.NewDict().Set("ClientId", self.client_id)
 
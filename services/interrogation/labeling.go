@@ -0,0 +1,237 @@
+package interrogation
+
+import (
+	"context"
+	"net"
+	"regexp"
+	"strings"
+
+	actions_proto "www.velocidex.com/golang/velociraptor/actions/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/file_store"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/paths/artifacts"
+	"www.velocidex.com/golang/velociraptor/result_sets"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+// Everything we know about the client which the auto labeling rules
+// may match on.
+type labelingFacts struct {
+	os, release, hostname, fqdn, ou string
+	software                        []string
+	addresses                       []net.IP
+}
+
+// Rules which apply to the org the config object belongs to.
+func getAutoLabelRules(
+	config_obj *config_proto.Config) []*config_proto.AutoLabelRule {
+	result := []*config_proto.AutoLabelRule{}
+	for _, rule := range config_obj.AutoLabelRules {
+		if rule.Label == "" {
+			continue
+		}
+		if rule.OrgId == "" || rule.OrgId == config_obj.OrgId {
+			result = append(result, rule)
+		}
+	}
+	return result
+}
+
+// Add or remove the rule labels on the client. A label is set if any
+// of its rules match and removed if none match.
+func applyAutoLabelRules(
+	ctx context.Context,
+	config_obj *config_proto.Config,
+	client_info *actions_proto.ClientInfo,
+	flow_id, artifact string) error {
+
+	rules := getAutoLabelRules(config_obj)
+	if len(rules) == 0 {
+		return nil
+	}
+
+	facts := getLabelingFacts(ctx, config_obj, client_info, flow_id, artifact)
+	logger := logging.GetLogger(config_obj, &logging.FrontendComponent)
+
+	// Labels are compared case insensitively.
+	wanted := make(map[string]bool)
+	labels := make(map[string]string)
+	for _, rule := range rules {
+		key := strings.ToLower(rule.Label)
+		labels[key] = rule.Label
+
+		matched, err := matchRule(rule, facts)
+		if err != nil {
+			logger.Error("Auto label rule for %v: %v", rule.Label, err)
+			continue
+		}
+		if matched {
+			wanted[key] = true
+		}
+	}
+
+	client_id := client_info.ClientId
+	labeler := services.GetLabeler(config_obj)
+	for key, label := range labels {
+		is_set := labeler.IsLabelSet(config_obj, client_id, label)
+		switch {
+		case wanted[key] && !is_set:
+			err := labeler.SetClientLabel(config_obj, client_id, label)
+			if err != nil {
+				return err
+			}
+
+		case !wanted[key] && is_set:
+			err := labeler.RemoveClientLabel(config_obj, client_id, label)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func matchRule(rule *config_proto.AutoLabelRule, facts *labelingFacts) (bool, error) {
+	for _, check := range []struct {
+		pattern string
+		values  []string
+	}{
+		{rule.Os, []string{facts.os}},
+		{rule.Release, []string{facts.release}},
+		{rule.Hostname, []string{facts.hostname, facts.fqdn}},
+		{rule.OrganizationalUnit, []string{facts.ou}},
+		{rule.InstalledSoftware, facts.software},
+	} {
+		if check.pattern == "" {
+			continue
+		}
+
+		re, err := regexp.Compile("(?i)" + check.pattern)
+		if err != nil {
+			return false, err
+		}
+
+		if !matchAny(re, check.values) {
+			return false, nil
+		}
+	}
+
+	if len(rule.IpRanges) > 0 {
+		matched, err := matchIpRanges(rule.IpRanges, facts.addresses)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func matchAny(re *regexp.Regexp, values []string) bool {
+	for _, v := range values {
+		if v != "" && re.MatchString(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchIpRanges(ranges []string, addresses []net.IP) (bool, error) {
+	for _, r := range ranges {
+		_, network, err := net.ParseCIDR(r)
+		if err != nil {
+			return false, err
+		}
+
+		for _, ip := range addresses {
+			if network.Contains(ip) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func getLabelingFacts(
+	ctx context.Context,
+	config_obj *config_proto.Config,
+	client_info *actions_proto.ClientInfo,
+	flow_id, artifact string) *labelingFacts {
+
+	facts := &labelingFacts{
+		os:       client_info.System,
+		release:  client_info.Release,
+		hostname: client_info.Hostname,
+		fqdn:     client_info.Fqdn,
+	}
+
+	// The addresses of the client's interfaces and the address it
+	// connects from.
+	if client_info.NetworkContext != nil {
+		for _, iface := range client_info.NetworkContext.Interfaces {
+			for _, address := range iface.Addresses {
+				facts.addresses = appendIP(facts.addresses, address)
+			}
+		}
+	}
+
+	client_info_manager, err := services.GetClientInfoManager(config_obj)
+	if err == nil {
+		stats, err := client_info_manager.GetStats(client_info.ClientId)
+		if err == nil {
+			facts.addresses = appendIP(facts.addresses, stats.IpAddress)
+		}
+	}
+
+	readInventory(ctx, config_obj, client_info.ClientId, flow_id, artifact, facts)
+
+	return facts
+}
+
+// Addresses may include a port or a network mask.
+func appendIP(addresses []net.IP, address string) []net.IP {
+	host, _, err := net.SplitHostPort(address)
+	if err == nil {
+		address = host
+	}
+
+	ip, _, err := net.ParseCIDR(address)
+	if err != nil {
+		ip = net.ParseIP(address)
+	}
+
+	if ip != nil {
+		addresses = append(addresses, ip)
+	}
+	return addresses
+}
+
+// The Inventory source is optional because customized interrogation
+// artifacts may not have it.
+func readInventory(
+	ctx context.Context,
+	config_obj *config_proto.Config,
+	client_id, flow_id, artifact string, facts *labelingFacts) {
+
+	source := strings.TrimSuffix(artifact, "/BasicInformation") + "/Inventory"
+	path_manager, err := artifacts.NewArtifactPathManager(config_obj,
+		client_id, flow_id, source)
+	if err != nil {
+		return
+	}
+
+	file_store_factory := file_store.GetFileStore(config_obj)
+	rs_reader, err := result_sets.NewResultSetReader(
+		file_store_factory, path_manager.Path())
+	if err != nil {
+		return
+	}
+	defer rs_reader.Close()
+
+	for row := range rs_reader.Rows(ctx) {
+		facts.ou, _ = row.GetString("OU")
+		facts.software, _ = row.GetStrings("Software")
+		break
+	}
+}
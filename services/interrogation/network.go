@@ -0,0 +1,95 @@
+package interrogation
+
+import (
+	"context"
+	"strings"
+
+	actions_proto "www.velocidex.com/golang/velociraptor/actions/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/file_store"
+	"www.velocidex.com/golang/velociraptor/json"
+	"www.velocidex.com/golang/velociraptor/paths/artifacts"
+	"www.velocidex.com/golang/velociraptor/result_sets"
+)
+
+// The row produced by the NetworkContext source of Generic.Client.Info
+type networkContextRow struct {
+	Interfaces []struct {
+		Name      string   `json:"Name"`
+		MAC       string   `json:"MAC"`
+		Addresses []string `json:"Addresses"`
+	} `json:"Interfaces"`
+	Gateways   []string `json:"Gateways"`
+	DNSServers []string `json:"DNSServers"`
+	SSIDs      []string `json:"SSIDs"`
+}
+
+// Read the client's network context from the interrogation flow. The
+// source is optional because customized interrogation artifacts may
+// not have it.
+func readNetworkContext(
+	ctx context.Context,
+	config_obj *config_proto.Config,
+	client_id, flow_id, artifact string) *actions_proto.NetworkContext {
+
+	source := strings.TrimSuffix(artifact, "/BasicInformation") +
+		"/NetworkContext"
+	path_manager, err := artifacts.NewArtifactPathManager(config_obj,
+		client_id, flow_id, source)
+	if err != nil {
+		return nil
+	}
+
+	file_store_factory := file_store.GetFileStore(config_obj)
+	rs_reader, err := result_sets.NewResultSetReader(
+		file_store_factory, path_manager.Path())
+	if err != nil {
+		return nil
+	}
+	defer rs_reader.Close()
+
+	for row := range rs_reader.Rows(ctx) {
+		serialized, err := json.Marshal(row)
+		if err != nil {
+			return nil
+		}
+
+		network := &networkContextRow{}
+		err = json.Unmarshal(serialized, network)
+		if err != nil {
+			return nil
+		}
+
+		result := &actions_proto.NetworkContext{
+			Gateways:   filterEmpty(network.Gateways),
+			DnsServers: filterEmpty(network.DNSServers),
+			Ssids:      filterEmpty(network.SSIDs),
+		}
+		for _, iface := range network.Interfaces {
+			result.Interfaces = append(result.Interfaces,
+				&actions_proto.NetworkInterface{
+					Name:      iface.Name,
+					Mac:       iface.MAC,
+					Addresses: filterEmpty(iface.Addresses),
+				})
+		}
+		return result
+	}
+
+	return nil
+}
+
+// Remove empty and repeated values (e.g. scutil lists the same DNS
+// server for each resolver).
+func filterEmpty(in []string) []string {
+	result := []string{}
+	seen := make(map[string]bool)
+	for _, i := range in {
+		i = strings.TrimSpace(i)
+		if i != "" && !seen[i] {
+			seen[i] = true
+			result = append(result, i)
+		}
+	}
+	return result
+}
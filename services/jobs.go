@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+)
+
+// The job manager runs long running server operations (exports,
+// imports etc) in the background and tracks their progress so
+// callers can poll for the result or cancel them.
+func GetJobManager(config_obj *config_proto.Config) (JobManager, error) {
+	org_manager, err := GetOrgManager()
+	if err != nil {
+		return nil, err
+	}
+
+	return org_manager.Services(config_obj.OrgId).JobManager()
+}
+
+// Jobs report their progress as they go.
+type JobProgress interface {
+	SetProgress(completed, total uint64)
+}
+
+// A job returns the location of its result (e.g. a vfs path). The
+// context is cancelled when the job is cancelled.
+type JobFunc func(ctx context.Context, progress JobProgress) (string, error)
+
+type JobManager interface {
+	// Start the job in the background. The job's type, description
+	// and principal should be filled in.
+	StartJob(config_obj *config_proto.Config,
+		job *api_proto.Job, cb JobFunc) (*api_proto.Job, error)
+
+	GetJob(ctx context.Context, job_id string) (*api_proto.Job, error)
+
+	// List all jobs, most recent first.
+	ListJobs(ctx context.Context) ([]*api_proto.Job, error)
+
+	CancelJob(ctx context.Context, job_id, principal string) (*api_proto.Job, error)
+
+	// Wait for the job to finish and return its final state.
+	WaitForJob(ctx context.Context, job_id string) (*api_proto.Job, error)
+}
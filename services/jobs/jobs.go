@@ -0,0 +1,333 @@
+/*
+  The job service runs long running server operations in the
+  background.
+
+  Operations like exports and imports may take a long time so the
+  API starts them as a job and returns the job id immediately. The
+  caller then polls the job for its progress and the location of its
+  result, or cancels it.
+
+  Jobs are recorded in the datastore so their status survives the
+  request which started them. Jobs which were running when the server
+  stopped can not be resumed so they are marked as failed when the
+  service starts.
+*/
+
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/constants"
+	"www.velocidex.com/golang/velociraptor/datastore"
+	"www.velocidex.com/golang/velociraptor/file_store/api"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/paths"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+const (
+	// Progress updates are only written to the datastore this often.
+	PROGRESS_WRITE_FREQUENCY = time.Second
+)
+
+type runningJob struct {
+	mu         sync.Mutex
+	job        *api_proto.Job
+	last_write time.Time
+
+	cancel func()
+	done   chan bool
+}
+
+func (self *runningJob) Get() *api_proto.Job {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	return proto.Clone(self.job).(*api_proto.Job)
+}
+
+type jobProgress struct {
+	service *JobService
+	running *runningJob
+}
+
+func (self *jobProgress) SetProgress(completed, total uint64) {
+	self.running.mu.Lock()
+	self.running.job.Completed = completed
+	self.running.job.Total = total
+
+	now := time.Now()
+	if now.Sub(self.running.last_write) < PROGRESS_WRITE_FREQUENCY {
+		self.running.mu.Unlock()
+		return
+	}
+	self.running.last_write = now
+	job := proto.Clone(self.running.job).(*api_proto.Job)
+	self.running.mu.Unlock()
+
+	_ = self.service.setJob(job)
+}
+
+type JobService struct {
+	mu sync.Mutex
+
+	ctx        context.Context
+	wg         *sync.WaitGroup
+	config_obj *config_proto.Config
+
+	running map[string]*runningJob
+}
+
+func (self *JobService) StartJob(
+	config_obj *config_proto.Config,
+	job *api_proto.Job, cb services.JobFunc) (*api_proto.Job, error) {
+
+	job = proto.Clone(job).(*api_proto.Job)
+	job.JobId = NewJobId()
+	job.State = api_proto.Job_RUNNING
+	job.CreateTime = uint64(time.Now().Unix())
+
+	err := self.setJob(job)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(self.ctx)
+	running := &runningJob{
+		job:    proto.Clone(job).(*api_proto.Job),
+		cancel: cancel,
+		done:   make(chan bool),
+	}
+
+	self.mu.Lock()
+	self.running[job.JobId] = running
+	self.mu.Unlock()
+
+	logger := logging.GetLogger(config_obj, &logging.FrontendComponent)
+	logger.WithFields(logrus.Fields{
+		"job_id":    job.JobId,
+		"type":      job.Type,
+		"principal": job.Principal,
+	}).Info("JobService: Starting job")
+
+	self.wg.Add(1)
+	go func() {
+		defer self.wg.Done()
+		defer close(running.done)
+		defer cancel()
+
+		result, err := cb(ctx, &jobProgress{service: self, running: running})
+
+		running.mu.Lock()
+		// Cancelled jobs are already finished.
+		if running.job.State == api_proto.Job_RUNNING {
+			running.job.EndTime = uint64(time.Now().Unix())
+			if err != nil {
+				running.job.State = api_proto.Job_ERROR
+				running.job.Error = err.Error()
+			} else {
+				running.job.State = api_proto.Job_FINISHED
+				running.job.Result = result
+			}
+		}
+		final := proto.Clone(running.job).(*api_proto.Job)
+		running.mu.Unlock()
+
+		err = self.setJob(final)
+		if err != nil {
+			logger.Error("JobService: Unable to write job %v: %v",
+				final.JobId, err)
+		}
+
+		self.mu.Lock()
+		delete(self.running, final.JobId)
+		self.mu.Unlock()
+	}()
+
+	return job, nil
+}
+
+func (self *JobService) getRunningJob(job_id string) (*runningJob, bool) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	running, pres := self.running[job_id]
+	return running, pres
+}
+
+func (self *JobService) GetJob(
+	ctx context.Context, job_id string) (*api_proto.Job, error) {
+	running, pres := self.getRunningJob(job_id)
+	if pres {
+		return running.Get(), nil
+	}
+
+	db, err := datastore.GetDB(self.config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &api_proto.Job{}
+	err = db.GetSubject(self.config_obj, paths.JOBS_ROOT.AddChild(job_id), job)
+	if err != nil || job.JobId == "" {
+		return nil, fmt.Errorf("Job %v not found", job_id)
+	}
+
+	return job, nil
+}
+
+func (self *JobService) ListJobs(ctx context.Context) ([]*api_proto.Job, error) {
+	db, err := datastore.GetDB(self.config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	children, err := db.ListChildren(self.config_obj, paths.JOBS_ROOT)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*api_proto.Job, 0, len(children))
+	for _, child := range children {
+		if child.IsDir() {
+			continue
+		}
+
+		job, err := self.GetJob(ctx, child.Base())
+		if err == nil {
+			result = append(result, job)
+		}
+	}
+
+	// Job ids start with the creation time but sort on the actual
+	// time in case several jobs start at once.
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CreateTime != result[j].CreateTime {
+			return result[i].CreateTime > result[j].CreateTime
+		}
+		return result[i].JobId > result[j].JobId
+	})
+
+	return result, nil
+}
+
+func (self *JobService) CancelJob(
+	ctx context.Context, job_id, principal string) (*api_proto.Job, error) {
+	running, pres := self.getRunningJob(job_id)
+	if !pres {
+		return nil, fmt.Errorf("Job %v is not running", job_id)
+	}
+
+	running.mu.Lock()
+	running.job.State = api_proto.Job_CANCELLED
+	running.job.Error = "Cancelled by " + principal
+	running.job.EndTime = uint64(time.Now().Unix())
+	job := proto.Clone(running.job).(*api_proto.Job)
+	running.mu.Unlock()
+
+	running.cancel()
+
+	logging.GetLogger(self.config_obj, &logging.Audit).
+		WithFields(logrus.Fields{
+			"user":   principal,
+			"job_id": job_id,
+			"type":   job.Type,
+		}).Info("CancelJob")
+
+	return job, self.setJob(job)
+}
+
+func (self *JobService) WaitForJob(
+	ctx context.Context, job_id string) (*api_proto.Job, error) {
+	running, pres := self.getRunningJob(job_id)
+	if pres {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-running.done:
+		}
+	}
+
+	return self.GetJob(ctx, job_id)
+}
+
+func (self *JobService) setJob(job *api_proto.Job) error {
+	db, err := datastore.GetDB(self.config_obj)
+	if err != nil {
+		return err
+	}
+
+	return db.SetSubject(self.config_obj,
+		paths.JOBS_ROOT.AddChild(job.JobId), job)
+}
+
+// Jobs which were running when the server stopped will never finish.
+func (self *JobService) failInterruptedJobs() error {
+	db, err := datastore.GetDB(self.config_obj)
+	if err != nil {
+		return err
+	}
+
+	return datastore.Walk(self.config_obj, db, paths.JOBS_ROOT,
+		datastore.WalkWithoutDirectories,
+		func(path api.DSPathSpec) error {
+			job := &api_proto.Job{}
+			err := db.GetSubject(self.config_obj, path, job)
+			if err != nil || job.State != api_proto.Job_RUNNING {
+				return nil
+			}
+
+			job.State = api_proto.Job_ERROR
+			job.Error = "Server restarted"
+			return self.setJob(job)
+		})
+}
+
+// Like hunt ids, job ids start with the creation time.
+func NewJobId() string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+
+	binary.BigEndian.PutUint32(buf, uint32(time.Now().Unix()))
+	result := base32.HexEncoding.EncodeToString(buf)[:13]
+
+	return constants.JOB_PREFIX + result
+}
+
+func NewJobService(
+	ctx context.Context,
+	wg *sync.WaitGroup,
+	config_obj *config_proto.Config) (services.JobManager, error) {
+
+	service := &JobService{
+		ctx:        ctx,
+		wg:         wg,
+		config_obj: config_obj,
+		running:    make(map[string]*runningJob),
+	}
+
+	if services.IsMaster(config_obj) {
+		err := service.failInterruptedJobs()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	logger := logging.GetLogger(config_obj, &logging.FrontendComponent)
+	logger.Info("<green>Starting</> Job Service for %v.",
+		services.GetOrgName(config_obj))
+
+	return service, nil
+}
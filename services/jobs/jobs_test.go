@@ -0,0 +1,129 @@
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/datastore"
+	"www.velocidex.com/golang/velociraptor/file_store/test_utils"
+	"www.velocidex.com/golang/velociraptor/paths"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/services/jobs"
+)
+
+type JobsTestSuite struct {
+	test_utils.TestSuite
+}
+
+func (self *JobsTestSuite) SetupTest() {
+	self.ConfigObj = self.LoadConfig()
+	self.ConfigObj.Frontend.ServerServices.JobService = true
+
+	self.TestSuite.SetupTest()
+}
+
+func (self *JobsTestSuite) TestJobLifecycle() {
+	job_manager, err := services.GetJobManager(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	started := make(chan bool)
+	finish := make(chan bool)
+	job, err := job_manager.StartJob(self.ConfigObj, &api_proto.Job{
+		Type:      "export",
+		Principal: "admin",
+	}, func(ctx context.Context, progress services.JobProgress) (string, error) {
+		progress.SetProgress(1, 2)
+		close(started)
+		<-finish
+		return "/downloads/export.zip", nil
+	})
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), api_proto.Job_RUNNING, job.State)
+
+	// Progress is visible while the job runs.
+	<-started
+	job, err = job_manager.GetJob(self.Ctx, job.JobId)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), api_proto.Job_RUNNING, job.State)
+	assert.Equal(self.T(), uint64(1), job.Completed)
+	assert.Equal(self.T(), uint64(2), job.Total)
+
+	close(finish)
+	job, err = job_manager.WaitForJob(self.Ctx, job.JobId)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), api_proto.Job_FINISHED, job.State)
+	assert.Equal(self.T(), "/downloads/export.zip", job.Result)
+
+	// Finished jobs can not be cancelled.
+	_, err = job_manager.CancelJob(self.Ctx, job.JobId, "admin")
+	assert.Error(self.T(), err)
+
+	// Errors are recorded in the job.
+	job, err = job_manager.StartJob(self.ConfigObj, &api_proto.Job{
+		Type: "import",
+	}, func(ctx context.Context, progress services.JobProgress) (string, error) {
+		return "", errors.New("Corrupted container")
+	})
+	assert.NoError(self.T(), err)
+
+	job, err = job_manager.WaitForJob(self.Ctx, job.JobId)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), api_proto.Job_ERROR, job.State)
+	assert.Equal(self.T(), "Corrupted container", job.Error)
+
+	all_jobs, err := job_manager.ListJobs(self.Ctx)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), 2, len(all_jobs))
+}
+
+func (self *JobsTestSuite) TestCancelJob() {
+	job_manager, err := services.GetJobManager(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	job, err := job_manager.StartJob(self.ConfigObj, &api_proto.Job{
+		Type: "export",
+	}, func(ctx context.Context, progress services.JobProgress) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	assert.NoError(self.T(), err)
+
+	_, err = job_manager.CancelJob(self.Ctx, job.JobId, "admin")
+	assert.NoError(self.T(), err)
+
+	// The job's context is cancelled and it stays cancelled.
+	job, err = job_manager.WaitForJob(self.Ctx, job.JobId)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), api_proto.Job_CANCELLED, job.State)
+	assert.Equal(self.T(), "Cancelled by admin", job.Error)
+}
+
+func (self *JobsTestSuite) TestInterruptedJobs() {
+	db, err := datastore.GetDB(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	err = db.SetSubject(self.ConfigObj, paths.JOBS_ROOT.AddChild("J.1234"),
+		&api_proto.Job{
+			JobId: "J.1234",
+			State: api_proto.Job_RUNNING,
+		})
+	assert.NoError(self.T(), err)
+
+	job_manager, err := jobs.NewJobService(self.Ctx, &sync.WaitGroup{},
+		self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	job, err := job_manager.GetJob(self.Ctx, "J.1234")
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), api_proto.Job_ERROR, job.State)
+	assert.Equal(self.T(), "Server restarted", job.Error)
+}
+
+func TestJobs(t *testing.T) {
+	suite.Run(t, &JobsTestSuite{})
+}
@@ -7,6 +7,24 @@ import (
 // The Label service is responsible for manipulating client's labels
 // in a fast and efficient manner.
 
+const (
+	// Set on clients which were uninstalled. These clients are
+	// excluded from hunts and client searches unless explicitly
+	// requested.
+	DECOMMISSIONED_LABEL = "Decommissioned"
+
+	// Set on clients which are isolated from the network.
+	QUARANTINE_LABEL = "Quarantine"
+)
+
+func IsDecommissioned(config_obj *config_proto.Config, client_id string) bool {
+	labeler := GetLabeler(config_obj)
+	if labeler == nil {
+		return false
+	}
+	return labeler.IsLabelSet(config_obj, client_id, DECOMMISSIONED_LABEL)
+}
+
 func GetLabeler(config_obj *config_proto.Config) Labeler {
 	org_manager, err := GetOrgManager()
 	if err != nil {
@@ -43,6 +43,7 @@ package services
 
 import (
 	"context"
+	"errors"
 
 	"github.com/Velocidex/ordereddict"
 	actions_proto "www.velocidex.com/golang/velociraptor/actions/proto"
@@ -53,6 +54,13 @@ import (
 	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
 )
 
+var (
+	// Callers can check for these with errors.Is()
+	FlowInvalidStateError         = errors.New("Invalid flow state")
+	ArtifactPermissionDeniedError = errors.New("permission denied")
+	ClientCapabilityMissingError  = errors.New("missing client capability")
+)
+
 type DeleteFlowResponse struct {
 	Type  string            `json:"type"`
 	Data  *ordereddict.Dict `json:"data"`
@@ -79,6 +87,24 @@ type CompilerOptions struct {
 	IgnoreMissingArtifacts bool
 }
 
+// Narrows down the collections returned by SearchFlows(). The time
+// range is checked using the creation time encoded in the flow id so
+// collections outside the range are skipped without loading them.
+type FlowSearchOptions struct {
+	IncludeArchived bool
+
+	// Only return collections created within this range (in
+	// seconds). Zero means unbounded.
+	StartTime uint64
+	EndTime   uint64
+
+	// By default the most recent collections are returned first.
+	Ascending bool
+
+	// Applied to the remaining collections after they are loaded.
+	Filter func(flow *flows_proto.ArtifactCollectorContext) bool
+}
+
 type Launcher interface {
 	// Only used for tests to force a predictable flow id.
 	SetFlowIdForTests(flow_id string)
@@ -145,6 +171,13 @@ type Launcher interface {
 		flow_filter func(flow *flows_proto.ArtifactCollectorContext) bool,
 		offset uint64, length uint64) (*api_proto.ApiFlowResponse, error)
 
+	// Like GetFlows() but with more control over the collections
+	// returned and their order.
+	SearchFlows(
+		config_obj *config_proto.Config,
+		client_id string, options FlowSearchOptions,
+		offset uint64, length uint64) (*api_proto.ApiFlowResponse, error)
+
 	// Get the details of a flow - this has a lot more information
 	// than the previous method.
 	GetFlowDetails(
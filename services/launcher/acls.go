@@ -3,10 +3,10 @@ package launcher
 import (
 	"fmt"
 
-	errors "github.com/pkg/errors"
 	"www.velocidex.com/golang/velociraptor/acls"
 	artifacts_proto "www.velocidex.com/golang/velociraptor/artifacts/proto"
 	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/services"
 	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
 )
 
@@ -22,11 +22,23 @@ func CheckAccess(
 	// Principal must have ALL permissions to succeed.
 	for _, perm := range artifact.RequiredPermissions {
 		permission := acls.GetPermission(perm)
+
+		// Internal launches (e.g. hunts) may use a permissive ACL
+		// manager so check read only mode explicitly.
+		if acls.DeniedByReadOnlyMode(config_obj, permission) {
+			return fmt.Errorf(
+				"While collecting artifact (%s) %w %v: "+
+					"Server is in read only mode",
+				artifact.Name, services.ArtifactPermissionDeniedError,
+				permission)
+		}
+
 		perm, err := acl_manager.CheckAccess(permission)
 		if !perm || err != nil {
-			return errors.New(fmt.Sprintf(
-				"While collecting artifact (%s) permission denied %v",
-				artifact.Name, permission))
+			return fmt.Errorf(
+				"While collecting artifact (%s) %w %v",
+				artifact.Name, services.ArtifactPermissionDeniedError,
+				permission)
 		}
 	}
 
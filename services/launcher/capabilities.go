@@ -0,0 +1,90 @@
+package launcher
+
+import (
+	"fmt"
+	"strings"
+
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/utils"
+)
+
+const (
+	CAPABILITY_CHECK_DENY = "deny"
+	CAPABILITY_CHECK_WARN = "warn"
+)
+
+// Check the requested artifacts against the capabilities the client
+// advertised during interrogation. Clients which never advertised
+// capabilities (older clients or not yet interrogated) are not
+// restricted.
+func CheckClientCapabilities(
+	config_obj *config_proto.Config,
+	collector_request *flows_proto.ArtifactCollectorArgs) error {
+
+	client_id := collector_request.ClientId
+	if client_id == "server" {
+		return nil
+	}
+
+	client_info_manager, err := services.GetClientInfoManager(config_obj)
+	if err != nil {
+		return err
+	}
+
+	client_info, err := client_info_manager.Get(client_id)
+	if err != nil || !client_info.CapabilitiesAdvertised {
+		return nil
+	}
+
+	manager, err := services.GetRepositoryManager(config_obj)
+	if err != nil {
+		return err
+	}
+
+	repository, err := manager.GetGlobalRepository(config_obj)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range collector_request.Artifacts {
+		artifact, pres := repository.Get(config_obj, name)
+		if !pres {
+			continue
+		}
+
+		missing := []string{}
+		for _, capability := range artifact.RequiredCapabilities {
+			if !utils.InString(client_info.Capabilities, capability) {
+				missing = append(missing, capability)
+			}
+		}
+
+		if len(missing) == 0 {
+			continue
+		}
+
+		err := fmt.Errorf("While collecting artifact (%s) on %v %w: %v",
+			name, client_id, services.ClientCapabilityMissingError,
+			strings.Join(missing, ", "))
+
+		if getCapabilityCheck(config_obj) != CAPABILITY_CHECK_WARN {
+			return err
+		}
+
+		logger := logging.GetLogger(config_obj, &logging.FrontendComponent)
+		logger.Warn("Launcher: %v", err)
+	}
+
+	return nil
+}
+
+func getCapabilityCheck(config_obj *config_proto.Config) string {
+	if config_obj.Defaults == nil ||
+		config_obj.Defaults.ClientCapabilityCheck == "" {
+		return CAPABILITY_CHECK_DENY
+	}
+	return strings.ToLower(config_obj.Defaults.ClientCapabilityCheck)
+}
@@ -0,0 +1,78 @@
+package launcher_test
+
+import (
+	"errors"
+
+	"github.com/stretchr/testify/assert"
+	actions_proto "www.velocidex.com/golang/velociraptor/actions/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/datastore"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/paths"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/services/launcher"
+)
+
+func (self *LauncherTestSuite) TestClientCapabilities() {
+	manager, err := services.GetRepositoryManager(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	repository, err := manager.GetGlobalRepository(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	_, err = repository.LoadYaml(`
+name: Test.Artifact.RawDisk
+required_capabilities:
+- raw_disk
+
+sources:
+- query:  |
+    SELECT * FROM info()
+`, true, true)
+	assert.NoError(self.T(), err)
+
+	db, err := datastore.GetDB(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	// C.1 is a low privilege client, C.2 is privileged and C.3 is
+	// an old client which does not advertise capabilities.
+	for _, record := range []*actions_proto.ClientInfo{
+		{ClientId: "C.1", CapabilitiesAdvertised: true},
+		{ClientId: "C.2", CapabilitiesAdvertised: true,
+			Capabilities: []string{"raw_disk", "memory"}},
+		{ClientId: "C.3"},
+	} {
+		err = db.SetSubject(self.ConfigObj,
+			paths.NewClientPathManager(record.ClientId).Path(), record)
+		assert.NoError(self.T(), err)
+	}
+
+	request := func(client_id string) *flows_proto.ArtifactCollectorArgs {
+		return &flows_proto.ArtifactCollectorArgs{
+			ClientId:  client_id,
+			Artifacts: []string{"Test.Artifact.RawDisk"},
+		}
+	}
+
+	err = launcher.CheckClientCapabilities(self.ConfigObj, request("C.1"))
+	assert.True(self.T(), errors.Is(err, services.ClientCapabilityMissingError))
+	assert.Contains(self.T(), err.Error(), "raw_disk")
+
+	for _, client_id := range []string{"C.2", "C.3", "server"} {
+		err = launcher.CheckClientCapabilities(
+			self.ConfigObj, request(client_id))
+		assert.NoError(self.T(), err)
+	}
+
+	// In warn mode the collection is allowed.
+	defaults := self.ConfigObj.Defaults
+	self.ConfigObj.Defaults = &config_proto.Defaults{
+		ClientCapabilityCheck: "warn",
+	}
+	defer func() {
+		self.ConfigObj.Defaults = defaults
+	}()
+
+	err = launcher.CheckClientCapabilities(self.ConfigObj, request("C.1"))
+	assert.NoError(self.T(), err)
+}
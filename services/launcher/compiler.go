@@ -135,6 +135,7 @@ LET %v <= if(
 		result.OpsPerSecond = artifact.Resources.OpsPerSecond
 		result.CpuLimit = artifact.Resources.CpuLimit
 		result.IopsLimit = artifact.Resources.IopsLimit
+		result.MaxMemory = artifact.Resources.MaxMemory
 	}
 
 	err := resolveImports(config_obj, artifact, result)
@@ -0,0 +1,159 @@
+package launcher
+
+import (
+	"fmt"
+	"strings"
+
+	actions_proto "www.velocidex.com/golang/velociraptor/actions/proto"
+	"www.velocidex.com/golang/velociraptor/artifacts"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/file_store"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/logging"
+	artifact_paths "www.velocidex.com/golang/velociraptor/paths/artifacts"
+	"www.velocidex.com/golang/velociraptor/result_sets"
+)
+
+// Resolve the continuation into the number of rows to skip for each
+// source. The truncated flow must be complete so all its rows are
+// counted. Continuing a continuation adds the rows skipped by the
+// truncated flow itself.
+func prepareContinuation(
+	config_obj *config_proto.Config,
+	collector_request *flows_proto.ArtifactCollectorArgs) error {
+
+	continuation := collector_request.ContinueFrom
+	if continuation == nil || len(continuation.Offsets) > 0 {
+		return nil
+	}
+
+	if continuation.FlowId == "" {
+		return fmt.Errorf("continue_from: flow id not provided")
+	}
+
+	// Sampling drops rows so the offsets would not line up.
+	if collector_request.SamplePercent > 0 || collector_request.SampleRows > 0 {
+		return fmt.Errorf("continue_from: can not be combined with sampling")
+	}
+
+	truncated, err := LoadCollectionContext(config_obj,
+		collector_request.ClientId, continuation.FlowId)
+	if err != nil {
+		return fmt.Errorf("continue_from: %w", err)
+	}
+
+	if truncated.Continuation == nil {
+		return fmt.Errorf("continue_from: flow %v was not truncated",
+			continuation.FlowId)
+	}
+
+	if truncated.State == flows_proto.ArtifactCollectorContext_RUNNING {
+		return fmt.Errorf("continue_from: flow %v is still running",
+			continuation.FlowId)
+	}
+
+	if truncated.Continuation.UploadLimitExceeded {
+		return fmt.Errorf("continue_from: flow %v exceeded its upload limit "+
+			"and can not be resumed. Collect it again with a larger "+
+			"max_upload_bytes", continuation.FlowId)
+	}
+
+	if truncated.Request.GetSamplePercent() > 0 ||
+		truncated.Request.GetSampleRows() > 0 {
+		return fmt.Errorf("continue_from: flow %v was sampled",
+			continuation.FlowId)
+	}
+
+	offsets := make(map[string]uint64)
+	if truncated.Request != nil && truncated.Request.ContinueFrom != nil {
+		for _, offset := range truncated.Request.ContinueFrom.Offsets {
+			offsets[offset.Source] = offset.Rows
+		}
+	}
+
+	file_store_factory := file_store.GetFileStore(config_obj)
+	for _, source := range truncated.ArtifactsWithResults {
+		path_manager, err := artifact_paths.NewArtifactPathManager(
+			config_obj, collector_request.ClientId,
+			continuation.FlowId, source)
+		if err != nil {
+			return err
+		}
+
+		rs_reader, err := result_sets.NewResultSetReader(
+			file_store_factory, path_manager.Path())
+		if err != nil {
+			return err
+		}
+		total_rows := rs_reader.TotalRows()
+		rs_reader.Close()
+
+		if total_rows > 0 {
+			offsets[source] += uint64(total_rows)
+		}
+	}
+
+	// Keep the order stable in the stored request.
+	sources := []string{}
+	for _, offset := range truncated.Request.GetContinueFrom().GetOffsets() {
+		sources = append(sources, offset.Source)
+	}
+	for _, source := range truncated.ArtifactsWithResults {
+		sources = append(sources, source)
+	}
+
+	for _, source := range sources {
+		rows, pres := offsets[source]
+		if !pres {
+			continue
+		}
+		delete(offsets, source)
+
+		continuation.Offsets = append(continuation.Offsets,
+			&flows_proto.ContinuationOffset{Source: source, Rows: rows})
+	}
+
+	// We can not tell if a source returns its rows in a stable order
+	// so leave a hint in case the results look wrong.
+	logger := logging.GetLogger(config_obj, &logging.FrontendComponent)
+	logger.Warn("Launcher: Resuming flow %v on %v skips the rows it "+
+		"already collected. This assumes the sources return rows in "+
+		"the same order each time.", continuation.FlowId,
+		collector_request.ClientId)
+
+	return nil
+}
+
+// Skip the rows already collected by the truncated flow. This relies
+// on the source returning rows in the same order each time it runs.
+func applyContinuation(
+	config_obj *config_proto.Config,
+	collector_request *flows_proto.ArtifactCollectorArgs,
+	vql_collector_args *actions_proto.VQLCollectorArgs) {
+
+	continuation := collector_request.ContinueFrom
+	if continuation == nil || len(continuation.Offsets) == 0 {
+		return
+	}
+
+	for _, query := range vql_collector_args.Query {
+		if query.Name == "" {
+			continue
+		}
+
+		name := query.Name
+		if strings.HasPrefix(name, "$") && config_obj.Frontend != nil {
+			name = artifacts.DeobfuscateString(config_obj, name)
+		}
+
+		for _, offset := range continuation.Offsets {
+			if offset.Source != name || offset.Rows == 0 {
+				continue
+			}
+
+			query.VQL = fmt.Sprintf(
+				"SELECT * FROM foreach(row={\n%s\n}) WHERE count() > %d",
+				query.VQL, offset.Rows)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package launcher_test
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Velocidex/ordereddict"
+	"github.com/stretchr/testify/assert"
+	"www.velocidex.com/golang/velociraptor/datastore"
+	"www.velocidex.com/golang/velociraptor/file_store"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/paths"
+	artifact_paths "www.velocidex.com/golang/velociraptor/paths/artifacts"
+	"www.velocidex.com/golang/velociraptor/result_sets"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/utils"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+)
+
+func (self *LauncherTestSuite) TestCompilingContinuation() {
+	// The path manager finds the artifact type in the global
+	// repository.
+	manager, err := services.GetRepositoryManager(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	repository, err := manager.GetGlobalRepository(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	_, err = repository.LoadYaml(`
+name: Test.Artifact.Truncated
+
+sources:
+- name: First
+  query:  |
+    SELECT * FROM info()
+- name: Second
+  query:  |
+    SELECT * FROM info()
+`, true, true)
+	assert.NoError(self.T(), err)
+
+	client_id := "C.1234"
+	flow_id := "F.Truncated"
+
+	db, err := datastore.GetDB(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	// A previous flow was truncated after 3 rows from the first
+	// source.
+	truncated := &flows_proto.ArtifactCollectorContext{
+		ClientId:  client_id,
+		SessionId: flow_id,
+		State:     flows_proto.ArtifactCollectorContext_ERROR,
+		Request: &flows_proto.ArtifactCollectorArgs{
+			ClientId:  client_id,
+			Artifacts: []string{"Test.Artifact.Truncated"},
+		},
+		ArtifactsWithResults: []string{"Test.Artifact.Truncated/First"},
+		Continuation:         &flows_proto.FlowContinuation{FlowId: flow_id},
+	}
+	err = db.SetSubject(self.ConfigObj,
+		paths.NewFlowPathManager(client_id, flow_id).Path(), truncated)
+	assert.NoError(self.T(), err)
+
+	path_manager, err := artifact_paths.NewArtifactPathManager(
+		self.ConfigObj, client_id, flow_id, "Test.Artifact.Truncated/First")
+	assert.NoError(self.T(), err)
+
+	rs_writer, err := result_sets.NewResultSetWriter(
+		file_store.GetFileStore(self.ConfigObj), path_manager.Path(),
+		nil, utils.SyncCompleter, true /* truncate */)
+	assert.NoError(self.T(), err)
+
+	for i := 0; i < 3; i++ {
+		rs_writer.Write(ordereddict.NewDict().Set("Foo", i))
+	}
+	rs_writer.Close()
+
+	launcher, err := services.GetLauncher(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	request := &flows_proto.ArtifactCollectorArgs{
+		Creator:      "UserX",
+		ClientId:     client_id,
+		Artifacts:    []string{"Test.Artifact.Truncated"},
+		ContinueFrom: &flows_proto.FlowContinuation{FlowId: flow_id},
+	}
+
+	ctx := context.Background()
+	compiled, err := launcher.CompileCollectorArgs(
+		ctx, self.ConfigObj, vql_subsystem.NullACLManager{}, repository,
+		services.CompilerOptions{}, request)
+	assert.NoError(self.T(), err)
+
+	// The offsets are recorded in the request.
+	assert.Equal(self.T(), 1, len(request.ContinueFrom.Offsets))
+	assert.Equal(self.T(), "Test.Artifact.Truncated/First",
+		request.ContinueFrom.Offsets[0].Source)
+	assert.Equal(self.T(), uint64(3), request.ContinueFrom.Offsets[0].Rows)
+
+	// Only the first source skips rows.
+	skipped := []string{}
+	for _, query := range compiled[0].Query {
+		if strings.Contains(query.VQL, "WHERE count() > 3") {
+			skipped = append(skipped, query.Name)
+		}
+	}
+	assert.Equal(self.T(), []string{"Test.Artifact.Truncated/First"}, skipped)
+
+	// Flows which were not truncated can not be continued.
+	truncated.Continuation = nil
+	err = db.SetSubject(self.ConfigObj,
+		paths.NewFlowPathManager(client_id, flow_id).Path(), truncated)
+	assert.NoError(self.T(), err)
+
+	_, err = launcher.CompileCollectorArgs(
+		ctx, self.ConfigObj, vql_subsystem.NullACLManager{}, repository,
+		services.CompilerOptions{}, &flows_proto.ArtifactCollectorArgs{
+			Creator:      "UserX",
+			ClientId:     client_id,
+			Artifacts:    []string{"Test.Artifact.Truncated"},
+			ContinueFrom: &flows_proto.FlowContinuation{FlowId: flow_id},
+		})
+	assert.Error(self.T(), err)
+	assert.Contains(self.T(), err.Error(), "was not truncated")
+
+	// Flows which exceeded their upload limit may have rows
+	// referring to uploads which were never stored.
+	truncated.Continuation = &flows_proto.FlowContinuation{
+		FlowId:              flow_id,
+		UploadLimitExceeded: true,
+	}
+	err = db.SetSubject(self.ConfigObj,
+		paths.NewFlowPathManager(client_id, flow_id).Path(), truncated)
+	assert.NoError(self.T(), err)
+
+	_, err = launcher.CompileCollectorArgs(
+		ctx, self.ConfigObj, vql_subsystem.NullACLManager{}, repository,
+		services.CompilerOptions{}, &flows_proto.ArtifactCollectorArgs{
+			Creator:      "UserX",
+			ClientId:     client_id,
+			Artifacts:    []string{"Test.Artifact.Truncated"},
+			ContinueFrom: &flows_proto.FlowContinuation{FlowId: flow_id},
+		})
+	assert.Error(self.T(), err)
+	assert.Contains(self.T(), err.Error(), "exceeded its upload limit")
+
+	// Sampling drops rows so the offsets would be wrong.
+	_, err = launcher.CompileCollectorArgs(
+		ctx, self.ConfigObj, vql_subsystem.NullACLManager{}, repository,
+		services.CompilerOptions{}, &flows_proto.ArtifactCollectorArgs{
+			Creator:      "UserX",
+			ClientId:     client_id,
+			Artifacts:    []string{"Test.Artifact.Truncated"},
+			ContinueFrom: &flows_proto.FlowContinuation{FlowId: flow_id},
+			SampleRows:   10,
+		})
+	assert.Error(self.T(), err)
+	assert.Contains(self.T(), err.Error(), "sampling")
+}
@@ -11,6 +11,7 @@ import (
 	"www.velocidex.com/golang/velociraptor/file_store"
 	"www.velocidex.com/golang/velociraptor/file_store/api"
 	"www.velocidex.com/golang/velociraptor/file_store/path_specs"
+	"www.velocidex.com/golang/velociraptor/legal_hold"
 	"www.velocidex.com/golang/velociraptor/paths"
 	artifact_paths "www.velocidex.com/golang/velociraptor/paths/artifacts"
 	"www.velocidex.com/golang/velociraptor/result_sets"
@@ -34,6 +35,14 @@ func (self *Launcher) DeleteFlow(
 		return nil, nil
 	}
 
+	if really_do_it {
+		err = legal_hold.CheckFlowHold(config_obj, client_id, flow_id,
+			collection_context.Request.GetCreator())
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	flow_path_manager := paths.NewFlowPathManager(client_id, flow_id)
 
 	upload_metadata_path := flow_path_manager.UploadMetadata()
@@ -84,9 +93,22 @@ func (self *Launcher) DeleteFlow(
 
 	}
 
+	// The text index only exists if the file indexer is enabled.
+	_, err = file_store_factory.StatFile(flow_path_manager.TextIndex())
+	if err == nil {
+		r.emit_fs("TextIndex", flow_path_manager.TextIndex())
+		r.emit_fs("TextIndexIndex", flow_path_manager.TextIndex().
+			SetType(api.PATH_TYPE_FILESTORE_JSON_INDEX))
+	}
+
 	r.emit_fs("Log", flow_path_manager.Log())
 	r.emit_fs("LogIndex", flow_path_manager.Log().
 		SetType(api.PATH_TYPE_FILESTORE_JSON_INDEX))
+	// Archived flows keep their bulk data in the flow archive.
+	if collection_context.ArchiveLocation != "" {
+		r.emit_archive(collection_context.ArchiveLocation)
+	}
+
 	r.emit_ds("CollectionContext", flow_path_manager.Path())
 	r.emit_ds("Task", flow_path_manager.Task())
 
@@ -119,6 +141,10 @@ func (self *Launcher) DeleteFlow(
 			return nil
 		})
 
+	if really_do_it {
+		NotifyFlowModification(config_obj, client_id, flow_id, "Delete")
+	}
+
 	return r.responses, nil
 }
 
@@ -183,3 +209,21 @@ func (self *reporter) emit_fs(
 		Error: error_message,
 	})
 }
+
+func (self *reporter) emit_archive(location string) {
+	var error_message string
+
+	if self.really_do_it {
+		err := os.Remove(location)
+		if err != nil && !os.IsNotExist(err) {
+			error_message = fmt.Sprintf(
+				"Error deleting %v: %v", location, err)
+		}
+	}
+
+	self.responses = append(self.responses, &services.DeleteFlowResponse{
+		Type:  "Archive",
+		Data:  ordereddict.NewDict().Set("Location", location),
+		Error: error_message,
+	})
+}
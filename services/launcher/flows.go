@@ -19,10 +19,11 @@ package launcher
 
 import (
 	"context"
+	"fmt"
+	"os"
 	"sort"
 	"time"
 
-	errors "github.com/pkg/errors"
 	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
 	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
 	constants "www.velocidex.com/golang/velociraptor/constants"
@@ -43,6 +44,17 @@ func (self *Launcher) GetFlows(
 	flow_filter func(flow *flows_proto.ArtifactCollectorContext) bool,
 	offset uint64, length uint64) (*api_proto.ApiFlowResponse, error) {
 
+	return self.SearchFlows(config_obj, client_id, services.FlowSearchOptions{
+		IncludeArchived: include_archived,
+		Filter:          flow_filter,
+	}, offset, length)
+}
+
+func (self *Launcher) SearchFlows(
+	config_obj *config_proto.Config,
+	client_id string, options services.FlowSearchOptions,
+	offset uint64, length uint64) (*api_proto.ApiFlowResponse, error) {
+
 	result := &api_proto.ApiFlowResponse{}
 	db, err := datastore.GetDB(config_obj)
 	if err != nil {
@@ -72,26 +84,46 @@ func (self *Launcher) GetFlows(
 	// Flow IDs represent timestamp so they are sortable. The UI
 	// relies on more recent flows being at the top.
 	sort.Slice(flow_urns, func(i, j int) bool {
+		if options.Ascending {
+			return flow_urns[i].Base() < flow_urns[j].Base()
+		}
 		return flow_urns[i].Base() >= flow_urns[j].Base()
 	})
 
-	// Page the flow urns
-	end := offset + length
-	if end > uint64(len(flow_urns)) {
-		end = uint64(len(flow_urns))
-	}
-	flow_urns = flow_urns[offset:end]
-
-	// Collect the items that match from this backend read
-	// into an array
+	// Page over the flows which match so pages are always full
+	// until the last page.
 	items := []*flows_proto.ArtifactCollectorContext{}
 
 	for _, urn := range flow_urns {
+		if length > 0 && uint64(len(items)) >= length {
+			break
+		}
+
 		// Hide the monitoring flow since it is not a real flow.
 		if urn.Base() == constants.MONITORING_WELL_KNOWN_FLOW {
 			continue
 		}
 
+		// Check the time range before loading the collection.
+		create_time, ok := FlowIdTime(urn.Base())
+		if ok {
+			if options.StartTime > 0 && create_time < options.StartTime {
+				// All the remaining flows are older.
+				if !options.Ascending {
+					break
+				}
+				continue
+			}
+
+			if options.EndTime > 0 && create_time > options.EndTime {
+				// All the remaining flows are newer.
+				if options.Ascending {
+					break
+				}
+				continue
+			}
+		}
+
 		collection_context := &flows_proto.ArtifactCollectorContext{}
 		err := db.GetSubject(config_obj, urn, collection_context)
 		if err != nil || collection_context.SessionId == "" {
@@ -101,13 +133,24 @@ func (self *Launcher) GetFlows(
 			continue
 		}
 
-		if !include_archived &&
+		if !options.IncludeArchived &&
 			collection_context.State ==
 				flows_proto.ArtifactCollectorContext_ARCHIVED {
 			continue
 		}
 
-		if flow_filter != nil && !flow_filter(collection_context) {
+		// Flow ids which do not encode the time (e.g. imported
+		// collections) are checked using the recorded create time.
+		if !ok && !inTimeRange(collection_context, options) {
+			continue
+		}
+
+		if options.Filter != nil && !options.Filter(collection_context) {
+			continue
+		}
+
+		if offset > 0 {
+			offset--
 			continue
 		}
 
@@ -118,6 +161,22 @@ func (self *Launcher) GetFlows(
 	return result, nil
 }
 
+func inTimeRange(
+	collection_context *flows_proto.ArtifactCollectorContext,
+	options services.FlowSearchOptions) bool {
+
+	// Create time is in microseconds.
+	create_time := collection_context.CreateTime / 1000000
+	if options.StartTime > 0 && create_time < options.StartTime {
+		return false
+	}
+
+	if options.EndTime > 0 && create_time > options.EndTime {
+		return false
+	}
+	return true
+}
+
 func (self *Launcher) GetFlowDetails(
 	config_obj *config_proto.Config,
 	client_id string, flow_id string) (*api_proto.FlowDetails, error) {
@@ -224,7 +283,8 @@ func LoadCollectionContext(
 	}
 
 	if collection_context.SessionId == "" {
-		return nil, errors.New("Unknown flow " + client_id + " " + flow_id)
+		return nil, fmt.Errorf("Unknown flow %v %v: %w",
+			client_id, flow_id, os.ErrNotExist)
 	}
 	return collection_context, nil
 }
@@ -242,8 +302,8 @@ func (self *Launcher) CancelFlow(
 		config_obj, client_id, flow_id)
 	if err == nil {
 		if collection_context.State != flows_proto.ArtifactCollectorContext_RUNNING {
-			return nil, errors.New("Flow is not in the running state. " +
-				"Can only cancel running flows.")
+			return nil, fmt.Errorf("%w: Flow is not in the running state. "+
+				"Can only cancel running flows.", services.FlowInvalidStateError)
 		}
 
 		collection_context.State = flows_proto.ArtifactCollectorContext_ERROR
@@ -295,6 +355,8 @@ func (self *Launcher) CancelFlow(
 		return nil, err
 	}
 
+	NotifyFlowModification(config_obj, client_id, flow_id, "Cancel")
+
 	return &api_proto.StartFlowResponse{
 		FlowId: flow_id,
 	}, nil
@@ -0,0 +1,107 @@
+package launcher_test
+
+import (
+	"encoding/base32"
+	"encoding/binary"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"www.velocidex.com/golang/velociraptor/datastore"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/paths"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/services/launcher"
+)
+
+// Make a flow id the same way as NewFlowId() for a known time.
+func flowIdForTime(timestamp uint32) string {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint32(buf, timestamp)
+	return "F." + base32.HexEncoding.EncodeToString(buf)[:13]
+}
+
+func (self *LauncherTestSuite) TestFlowIdTime() {
+	now := uint64(time.Now().Unix())
+	create_time, ok := launcher.FlowIdTime(launcher.NewFlowId("C.1234"))
+	assert.True(self.T(), ok)
+	assert.True(self.T(), create_time >= now && create_time <= now+1)
+
+	create_time, ok = launcher.FlowIdTime(flowIdForTime(1000))
+	assert.True(self.T(), ok)
+	assert.Equal(self.T(), uint64(1000), create_time)
+
+	for _, flow_id := range []string{"F.1234", "F.Imported", "F.!!!!!!!!!!!!!"} {
+		_, ok = launcher.FlowIdTime(flow_id)
+		assert.False(self.T(), ok, flow_id)
+	}
+}
+
+func (self *LauncherTestSuite) TestSearchFlows() {
+	client_id := "C.1234"
+
+	db, err := datastore.GetDB(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	for _, flow := range []*flows_proto.ArtifactCollectorContext{{
+		SessionId: flowIdForTime(1000),
+		State:     flows_proto.ArtifactCollectorContext_FINISHED,
+	}, {
+		SessionId: flowIdForTime(2000),
+		State:     flows_proto.ArtifactCollectorContext_ERROR,
+	}, {
+		SessionId: flowIdForTime(3000),
+		State:     flows_proto.ArtifactCollectorContext_RUNNING,
+	}, {
+		// An imported collection does not encode the time in its
+		// flow id.
+		SessionId:  "F.Imported",
+		CreateTime: 2500 * 1000000,
+		State:      flows_proto.ArtifactCollectorContext_FINISHED,
+	}} {
+		flow.ClientId = client_id
+		err = db.SetSubject(self.ConfigObj,
+			paths.NewFlowPathManager(client_id, flow.SessionId).Path(), flow)
+		assert.NoError(self.T(), err)
+	}
+
+	launcher, err := services.GetLauncher(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	search := func(options services.FlowSearchOptions) []string {
+		result, err := launcher.SearchFlows(
+			self.ConfigObj, client_id, options, 0, 100)
+		assert.NoError(self.T(), err)
+
+		flow_ids := []string{}
+		for _, item := range result.Items {
+			flow_ids = append(flow_ids, item.SessionId)
+		}
+		return flow_ids
+	}
+
+	// Most recent flows first by default.
+	assert.Equal(self.T(), []string{
+		"F.Imported", flowIdForTime(3000), flowIdForTime(2000),
+		flowIdForTime(1000)}, search(services.FlowSearchOptions{}))
+
+	assert.Equal(self.T(), []string{
+		flowIdForTime(1000), flowIdForTime(2000), flowIdForTime(3000),
+		"F.Imported"}, search(services.FlowSearchOptions{Ascending: true}))
+
+	// The imported flow is checked using its create time.
+	assert.Equal(self.T(), []string{"F.Imported", flowIdForTime(2000)},
+		search(services.FlowSearchOptions{StartTime: 1500, EndTime: 2600}))
+
+	assert.Equal(self.T(), []string{flowIdForTime(1000)},
+		search(services.FlowSearchOptions{EndTime: 1500, Ascending: true}))
+
+	assert.Equal(self.T(), []string{flowIdForTime(3000)},
+		search(services.FlowSearchOptions{StartTime: 2600}))
+
+	assert.Equal(self.T(), []string{"F.Imported", flowIdForTime(1000)},
+		search(services.FlowSearchOptions{
+			Filter: func(flow *flows_proto.ArtifactCollectorContext) bool {
+				return flow.State == flows_proto.ArtifactCollectorContext_FINISHED
+			},
+		}))
+}
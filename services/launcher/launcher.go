@@ -121,11 +121,13 @@ import (
 	"encoding/base32"
 	"encoding/binary"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	errors "github.com/pkg/errors"
 	"google.golang.org/protobuf/proto"
+	"www.velocidex.com/golang/velociraptor/acls"
 	actions_proto "www.velocidex.com/golang/velociraptor/actions/proto"
 	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
 	"www.velocidex.com/golang/velociraptor/artifacts"
@@ -188,12 +190,22 @@ func (self *Launcher) CompileCollectorArgs(
 
 	result := []*actions_proto.VQLCollectorArgs{}
 
+	err := validateSampling(collector_request)
+	if err != nil {
+		return nil, err
+	}
+
+	err = prepareContinuation(config_obj, collector_request)
+	if err != nil {
+		return nil, err
+	}
+
 	// We extract the default resource limits from each artifact
 	// definition and calculate a collection wide default. For
 	// example if a collection specifies artifact A (with max_rows
 	// = 10) and artifact B (with max_rows = 20), then the
 	// collection will have max_rows = 20.
-	var max_rows, max_upload_bytes, timeout uint64
+	var max_rows, max_upload_bytes, timeout, max_memory uint64
 	var ops_per_sec, cpu_limit, iops_limit float32
 
 	for _, spec := range getCollectorSpecs(collector_request) {
@@ -261,12 +273,21 @@ func (self *Launcher) CompileCollectorArgs(
 				vql_collector_args.Timeout = collector_request.Timeout
 			}
 
+			if collector_request.MaxMemory > 0 {
+				vql_collector_args.MaxMemory = collector_request.MaxMemory
+			}
+
+			applyContinuation(config_obj, collector_request, vql_collector_args)
+			applySampling(collector_request, vql_collector_args)
+
 			vql_collector_args.MaxRow = 1000
+			vql_collector_args.ReadOnly = acls.IsReadOnlyMode(config_obj)
 
 			timeout = vql_collector_args.Timeout
 			ops_per_sec = vql_collector_args.OpsPerSecond
 			cpu_limit = vql_collector_args.CpuLimit
 			iops_limit = vql_collector_args.IopsLimit
+			max_memory = vql_collector_args.MaxMemory
 
 			result = append(result, vql_collector_args)
 		}
@@ -298,6 +319,10 @@ func (self *Launcher) CompileCollectorArgs(
 		collector_request.IopsLimit = iops_limit
 	}
 
+	if collector_request.MaxMemory == 0 {
+		collector_request.MaxMemory = max_memory
+	}
+
 	// Update the total count of requests
 	for idx, item := range result {
 		item.QueryId = int64(idx + 1)
@@ -534,6 +559,11 @@ func (self *Launcher) ScheduleArtifactCollectionFromCollectorArgs(
 		return "", errors.New("Client id not provided.")
 	}
 
+	err := CheckClientCapabilities(config_obj, collector_request)
+	if err != nil {
+		return "", err
+	}
+
 	db, err := datastore.GetDB(config_obj)
 	if err != nil {
 		return "", err
@@ -549,6 +579,7 @@ func (self *Launcher) ScheduleArtifactCollectionFromCollectorArgs(
 	// Compile all the requests into specific tasks to be sent to the
 	// client.
 	tasks := []*crypto_proto.VeloMessage{}
+	sent_bytes := uint64(0)
 	for id, arg := range vql_collector_args {
 		// If sending to the server record who actually launched this.
 		if client_id == "server" {
@@ -569,6 +600,7 @@ func (self *Launcher) ScheduleArtifactCollectionFromCollectorArgs(
 		}
 
 		tasks = append(tasks, task)
+		sent_bytes += uint64(proto.Size(task))
 	}
 
 	// Save the collection context first.
@@ -582,6 +614,7 @@ func (self *Launcher) ScheduleArtifactCollectionFromCollectorArgs(
 		Request:             collector_request,
 		ClientId:            client_id,
 		OutstandingRequests: int64(len(tasks)),
+		TotalSentBytes:      sent_bytes,
 	}
 
 	// Store the collection_context first, then queue all the tasks.
@@ -670,6 +703,23 @@ func NewFlowId(client_id string) string {
 	return constants.FLOW_PREFIX + result
 }
 
+// Recovers the creation time (in seconds) from a flow id made by
+// NewFlowId(). Returns false if the flow id was made some other way.
+func FlowIdTime(flow_id string) (uint64, bool) {
+	if !strings.HasPrefix(flow_id, constants.FLOW_PREFIX) ||
+		len(flow_id) != len(constants.FLOW_PREFIX)+13 {
+		return 0, false
+	}
+
+	buf, err := base32.HexEncoding.DecodeString(
+		strings.TrimPrefix(flow_id, constants.FLOW_PREFIX) + "===")
+	if err != nil || len(buf) < 4 {
+		return 0, false
+	}
+
+	return uint64(binary.BigEndian.Uint32(buf)), true
+}
+
 func NewLauncherService(
 	ctx context.Context,
 	wg *sync.WaitGroup,
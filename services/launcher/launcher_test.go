@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -612,6 +613,113 @@ sources:
 	assert.Equal(self.T(), len(compiled[0].Query), 2)
 }
 
+func (self *LauncherTestSuite) TestCompilingReadOnlyMode() {
+	repository := self.LoadArtifacts([]string{`
+name: Test.Artifact.Permissions
+required_permissions:
+- EXECVE
+
+sources:
+- query:  |
+    SELECT * FROM info()
+`, `
+name: Test.Artifact.NoPermissions
+
+sources:
+- query:  |
+    SELECT * FROM info()
+`})
+
+	self.ConfigObj.ReadOnlyMode = true
+	defer func() {
+		self.ConfigObj.ReadOnlyMode = false
+	}()
+
+	err := acls.SetPolicy(self.ConfigObj, "UserX",
+		&acl_proto.ApiClientACL{Execve: true, CollectClient: true})
+	assert.NoError(self.T(), err)
+
+	// Even though the user has EXECVE it is removed in read only
+	// mode.
+	policy, err := acls.GetEffectivePolicy(self.ConfigObj, "UserX")
+	assert.NoError(self.T(), err)
+	assert.False(self.T(), policy.Execve)
+	assert.True(self.T(), policy.CollectClient)
+
+	launcher, err := services.GetLauncher(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	// Internal launches with a permissive ACL manager are also
+	// denied.
+	ctx := context.Background()
+	_, err = launcher.CompileCollectorArgs(
+		ctx, self.ConfigObj, vql_subsystem.NullACLManager{}, repository,
+		services.CompilerOptions{}, &flows_proto.ArtifactCollectorArgs{
+			Creator:   "UserX",
+			ClientId:  "C.1234",
+			Artifacts: []string{"Test.Artifact.Permissions"},
+		})
+	assert.Error(self.T(), err)
+	assert.Contains(self.T(), err.Error(), "read only mode")
+
+	// Other artifacts can be collected but the client is told not
+	// to run programs.
+	compiled, err := launcher.CompileCollectorArgs(
+		ctx, self.ConfigObj, vql_subsystem.NullACLManager{}, repository,
+		services.CompilerOptions{}, &flows_proto.ArtifactCollectorArgs{
+			Creator:   "UserX",
+			ClientId:  "C.1234",
+			Artifacts: []string{"Test.Artifact.NoPermissions"},
+		})
+	assert.NoError(self.T(), err)
+	assert.True(self.T(), compiled[0].ReadOnly)
+}
+
+func (self *LauncherTestSuite) TestCompilingSampling() {
+	repository := self.LoadArtifacts([]string{`
+name: Test.Artifact.Sampling
+
+sources:
+- query:  |
+    SELECT * FROM info()
+`})
+
+	launcher, err := services.GetLauncher(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	ctx := context.Background()
+	request := &flows_proto.ArtifactCollectorArgs{
+		Creator:       "UserX",
+		ClientId:      "C.1234",
+		Artifacts:     []string{"Test.Artifact.Sampling"},
+		SamplePercent: 10,
+		SampleRows:    50,
+	}
+
+	compiled, err := launcher.CompileCollectorArgs(
+		ctx, self.ConfigObj, vql_subsystem.NullACLManager{}, repository,
+		services.CompilerOptions{}, request)
+	assert.NoError(self.T(), err)
+
+	// Only the named source query is wrapped.
+	for _, query := range compiled[0].Query {
+		if query.Name == "" {
+			assert.NotContains(self.T(), query.VQL, "rand(")
+			continue
+		}
+		assert.Contains(self.T(), query.VQL, "SELECT * FROM Test_Artifact_Sampling_0_0")
+		assert.Contains(self.T(), query.VQL, "WHERE rand(range=1000000) < 100000")
+		assert.True(self.T(), strings.HasSuffix(query.VQL, "LIMIT 50"))
+	}
+
+	// Invalid percentages are rejected.
+	request.SamplePercent = 150
+	_, err = launcher.CompileCollectorArgs(
+		ctx, self.ConfigObj, vql_subsystem.NullACLManager{}, repository,
+		services.CompilerOptions{}, request)
+	assert.Error(self.T(), err)
+}
+
 func (self *LauncherTestSuite) TestParameterTypes() {
 	repository := self.LoadArtifacts(testArtifactWithTypes)
 
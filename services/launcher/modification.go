@@ -0,0 +1,32 @@
+package launcher
+
+import (
+	"github.com/Velocidex/ordereddict"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+// Flows are normally only updated by the flow runner which announces
+// them on System.Flow.Completion. Other changes are announced here so
+// caches can drop their copy of the flow.
+func NotifyFlowModification(
+	config_obj *config_proto.Config,
+	client_id, flow_id, action string) {
+
+	journal, err := services.GetJournal(config_obj)
+	if err != nil {
+		return
+	}
+
+	err = journal.PushRowsToArtifact(config_obj,
+		[]*ordereddict.Dict{ordereddict.NewDict().
+			Set("ClientId", client_id).
+			Set("FlowId", flow_id).
+			Set("Action", action)},
+		"Server.Internal.FlowModification", "server", "")
+	if err != nil {
+		logger := logging.GetLogger(config_obj, &logging.FrontendComponent)
+		logger.Error("NotifyFlowModification: %v", err)
+	}
+}
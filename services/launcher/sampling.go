@@ -0,0 +1,57 @@
+package launcher
+
+import (
+	"errors"
+	"fmt"
+
+	actions_proto "www.velocidex.com/golang/velociraptor/actions/proto"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+)
+
+// Percentages are converted to a fraction of this range for rand().
+const sample_range = 1000000
+
+func validateSampling(collector_request *flows_proto.ArtifactCollectorArgs) error {
+	if collector_request.SamplePercent < 0 ||
+		collector_request.SamplePercent > 100 {
+		return errors.New("sample_percent must be between 0 and 100")
+	}
+	return nil
+}
+
+// Wrap each source query so only a sample of its rows is
+// returned. The queries are rewritten on the server so sampling
+// works with all clients.
+func applySampling(
+	collector_request *flows_proto.ArtifactCollectorArgs,
+	vql_collector_args *actions_proto.VQLCollectorArgs) {
+
+	percent := collector_request.SamplePercent
+	if percent == 100 {
+		percent = 0
+	}
+
+	if percent == 0 && collector_request.SampleRows == 0 {
+		return
+	}
+
+	for _, query := range vql_collector_args.Query {
+		// Only named queries produce results.
+		if query.Name == "" {
+			continue
+		}
+
+		// The query is on its own line so trailing comments do not
+		// swallow the closing brace.
+		vql := fmt.Sprintf("SELECT * FROM foreach(row={\n%s\n})", query.VQL)
+		if percent > 0 {
+			vql += fmt.Sprintf(" WHERE rand(range=%d) < %d",
+				sample_range, int64(float64(percent)*sample_range/100))
+		}
+
+		if collector_request.SampleRows > 0 {
+			vql += fmt.Sprintf(" LIMIT %d", collector_request.SampleRows)
+		}
+		query.VQL = vql
+	}
+}
@@ -47,9 +47,20 @@ type NotebookManager interface {
 	// Cancel a current operation
 	CancelNotebookCell(ctx context.Context, notebook_id, cell_id string) error
 
+	// Can the user edit the notebook?
 	CheckNotebookAccess(
 		notebook *api_proto.NotebookMetadata, user string) bool
 
+	// Can the user view the notebook?
+	CheckNotebookReadAccess(
+		notebook *api_proto.NotebookMetadata, user string) bool
+
+	// Record who changed the notebook in the notebook's activity
+	// log. The cell id may be empty for changes to the notebook
+	// itself.
+	LogNotebookActivity(ctx context.Context,
+		notebook_id, user_name, cell_id, action string) error
+
 	UploadNotebookAttachment(ctx context.Context,
 		in *api_proto.NotebookFileUploadRequest) (
 		*api_proto.NotebookFileUploadResponse, error)
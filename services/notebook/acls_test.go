@@ -81,6 +81,37 @@ func (self *ACLTestSuite) TestNotebookPublicACL() {
 	// test_utils.GetMemoryDataStore(self.T(), self.ConfigObj).Debug()
 }
 
+func (self *ACLTestSuite) TestNotebookPublicReadACL() {
+	new_notebook := &api_proto.NotebookMetadata{
+		NotebookId:    "N.12346",
+		Creator:       "Creator",
+		Collaborators: []string{"Editor"},
+		PublicRead:    true,
+	}
+
+	notebook_manager_any, err := services.GetNotebookManager(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	notebook_manager := notebook_manager_any.(*notebook.NotebookManager)
+
+	// Everyone may read the notebook.
+	assert.True(self.T(), notebook_manager.CheckNotebookReadAccess(
+		new_notebook, "User1"))
+
+	// But only the creator and collaborators may edit it.
+	assert.False(self.T(), notebook_manager.CheckNotebookAccess(
+		new_notebook, "User1"))
+	assert.True(self.T(), notebook_manager.CheckNotebookAccess(
+		new_notebook, "Editor"))
+
+	// Without public read User1 loses read access.
+	new_notebook.PublicRead = false
+	assert.False(self.T(), notebook_manager.CheckNotebookReadAccess(
+		new_notebook, "User1"))
+	assert.True(self.T(), notebook_manager.CheckNotebookReadAccess(
+		new_notebook, "Editor"))
+}
+
 func TestACLs(t *testing.T) {
 	suite.Run(t, &ACLTestSuite{})
 }
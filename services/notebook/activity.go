@@ -0,0 +1,40 @@
+package notebook
+
+import (
+	"context"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	"www.velocidex.com/golang/velociraptor/file_store"
+	"www.velocidex.com/golang/velociraptor/json"
+	"www.velocidex.com/golang/velociraptor/paths"
+	"www.velocidex.com/golang/velociraptor/result_sets"
+	"www.velocidex.com/golang/velociraptor/utils"
+)
+
+// The activity log is a result set stored with the notebook so it
+// can be viewed with the GetTable API (Type "activity").
+func (self *NotebookManager) LogNotebookActivity(ctx context.Context,
+	notebook_id, user_name, cell_id, action string) error {
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	path_manager := paths.NewNotebookPathManager(notebook_id)
+	file_store_factory := file_store.GetFileStore(self.config_obj)
+	rs_writer, err := result_sets.NewResultSetWriter(file_store_factory,
+		path_manager.ActivityLog(), json.NoEncOpts,
+		utils.SyncCompleter, result_sets.AppendMode)
+	if err != nil {
+		return err
+	}
+	defer rs_writer.Close()
+
+	rs_writer.Write(ordereddict.NewDict().
+		Set("Timestamp", time.Now().UTC()).
+		Set("User", user_name).
+		Set("CellId", cell_id).
+		Set("Action", action))
+
+	return nil
+}
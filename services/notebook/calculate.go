@@ -10,6 +10,7 @@ import (
 
 	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
 	artifacts_proto "www.velocidex.com/golang/velociraptor/artifacts/proto"
+	"www.velocidex.com/golang/velociraptor/i18n"
 	"www.velocidex.com/golang/velociraptor/logging"
 	"www.velocidex.com/golang/velociraptor/paths"
 	"www.velocidex.com/golang/velociraptor/reporting"
@@ -23,6 +24,13 @@ func (self *NotebookManager) UpdateNotebookCell(
 	user_name string,
 	in *api_proto.NotebookCellRequest) (*api_proto.NotebookCell, error) {
 
+	if in.Schedule != "" {
+		_, err := getScheduleInterval(in.Schedule)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	notebook_cell := &api_proto.NotebookCell{
 		Input:            in.Input,
 		Output:           `<div class="padded"><i class="fa fa-spinner fa-spin fa-fw"></i> Calculating...</div>`,
@@ -33,6 +41,7 @@ func (self *NotebookManager) UpdateNotebookCell(
 		Calculating:      true,
 		Env:              in.Env,
 	}
+	setCellSchedule(notebook_cell, in.Schedule, user_name, time.Now())
 
 	notebook_path_manager := paths.NewNotebookPathManager(
 		notebook_metadata.NotebookId)
@@ -42,6 +51,13 @@ func (self *NotebookManager) UpdateNotebookCell(
 		return nil, err
 	}
 
+	if in.Schedule != "" {
+		err = self.indexScheduledNotebook(in.NotebookId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Run the actual query independently.
 	query_ctx, query_cancel := context.WithCancel(context.Background())
 
@@ -66,6 +82,16 @@ func (self *NotebookManager) UpdateNotebookCell(
 
 	tmpl.SetEnv("NotebookId", in.NotebookId)
 
+	// Messages are rendered in the language of the user updating
+	// the cell.
+	users := services.GetUserManager()
+	if users != nil {
+		options, _ := users.GetUserOptions(user_name)
+		if options != nil && options.Lang != "" {
+			tmpl.Lang = i18n.Normalize(options.Lang)
+		}
+	}
+
 	// Register a progress reporter so we can monitor how the
 	// template rendering is going.
 	tmpl.Progress = &progressReporter{
@@ -148,7 +174,8 @@ func (self *NotebookManager) UpdateNotebookCell(
 
 		resp, err := self.updateCellContents(query_ctx, tmpl,
 			in.CurrentlyEditing, in.NotebookId,
-			in.CellId, cell_type, in.Env, input, in.Input)
+			in.CellId, cell_type, in.Env, in.Schedule, user_name,
+			input, in.Input)
 		if err != nil {
 			main_err = err
 			logger := logging.GetLogger(self.config_obj, &logging.GUIComponent)
@@ -176,6 +203,7 @@ func (self *NotebookManager) updateCellContents(
 	currently_editing bool,
 	notebook_id, cell_id, cell_type string,
 	env []*api_proto.Env,
+	schedule, user_name string,
 	input, original_input string) (res *api_proto.NotebookCell, err error) {
 
 	output := ""
@@ -192,7 +220,8 @@ func (self *NotebookManager) updateCellContents(
 				fmt.Sprintf("Error: %v", err))
 		}
 
-		return &api_proto.NotebookCell{
+		now := time.Now()
+		notebook_cell := &api_proto.NotebookCell{
 			Input:            original_input,
 			Output:           output,
 			Data:             string(encoded_data),
@@ -200,10 +229,12 @@ func (self *NotebookManager) updateCellContents(
 			CellId:           cell_id,
 			Type:             cell_type,
 			Env:              env,
-			Timestamp:        time.Now().Unix(),
+			Timestamp:        now.Unix(),
 			CurrentlyEditing: currently_editing,
 			Duration:         int64(time.Since(tmpl.Start).Seconds()),
 		}
+		setCellSchedule(notebook_cell, schedule, user_name, now)
+		return notebook_cell
 	}
 
 	// If an error occurs it is important to ensure the cell is
@@ -71,6 +71,7 @@ func (self *NotebookManager) NewNotebookCell(
 		CellId:     notebook.LatestCellId,
 		Type:       in.Type,
 		Env:        in.Env,
+		Schedule:   in.Schedule,
 
 		// New cells are opened for editing.
 		CurrentlyEditing: true,
@@ -17,6 +17,9 @@ import (
 type NotebookManager struct {
 	config_obj *config_proto.Config
 	Store      NotebookStore
+
+	// Serializes writes to the activity log.
+	mu sync.Mutex
 }
 
 func (self *NotebookManager) GetNotebook(
@@ -101,6 +104,8 @@ func (self *NotebookManager) GetNotebookCell(ctx context.Context,
 		return nil, err
 	}
 
+	notebook_cell.Stale = isCellStale(notebook_cell, time.Now())
+
 	return notebook_cell, nil
 }
 
@@ -169,8 +174,15 @@ func NewNotebookManagerService(
 	wg *sync.WaitGroup,
 	config_obj *config_proto.Config) (services.NotebookManager, error) {
 
-	return NewNotebookManager(config_obj,
+	notebook_manager := NewNotebookManager(config_obj,
 		&NotebookStoreImpl{
 			config_obj: config_obj,
-		}), nil
+		})
+
+	// Only the master recalculates scheduled cells.
+	if services.IsMaster(config_obj) {
+		notebook_manager.startScheduler(ctx, wg)
+	}
+
+	return notebook_manager, nil
 }
@@ -0,0 +1,210 @@
+package notebook
+
+// Notebook cells may be scheduled to be recalculated periodically
+// so notebooks can be used as simple dashboards. The output of the
+// last calculation is stored in the cell as usual and served until
+// the next update is due.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"www.velocidex.com/golang/velociraptor/acls"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/datastore"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/paths"
+	"www.velocidex.com/golang/velociraptor/services"
+)
+
+const (
+	SCHEDULE_CHECK_FREQUENCY = time.Minute
+
+	// All scheduled notebooks are kept under a single keyword in
+	// the schedule index.
+	scheduleIndexKeyword = "all"
+)
+
+var (
+	invalidScheduleError = errors.New(
+		"Invalid schedule: must be one of hourly or daily")
+)
+
+func getScheduleInterval(schedule string) (time.Duration, error) {
+	switch schedule {
+	case "hourly":
+		return time.Hour, nil
+	case "daily":
+		return 24 * time.Hour, nil
+	}
+	return 0, invalidScheduleError
+}
+
+// Fill in the schedule fields of a freshly calculated cell.
+func setCellSchedule(cell *api_proto.NotebookCell,
+	schedule, user_name string, now time.Time) {
+	interval, err := getScheduleInterval(schedule)
+	if err != nil {
+		return
+	}
+
+	cell.Schedule = schedule
+	cell.ScheduledBy = user_name
+	cell.NextUpdate = now.Add(interval).Unix()
+}
+
+func isCellStale(cell *api_proto.NotebookCell, now time.Time) bool {
+	return cell.Schedule != "" && !cell.Calculating &&
+		cell.NextUpdate > 0 && now.Unix() > cell.NextUpdate
+}
+
+// Add the notebook to the schedule index so the scheduler can find
+// it. Notebooks are removed from the index lazily by the scheduler
+// once they have no more scheduled cells.
+func (self *NotebookManager) indexScheduledNotebook(notebook_id string) error {
+	indexer, err := services.GetIndexer(self.config_obj)
+	if err != nil {
+		return err
+	}
+
+	return indexer.SetSimpleIndex(self.config_obj,
+		paths.NOTEBOOK_SCHEDULE_INDEX, notebook_id,
+		[]string{scheduleIndexKeyword})
+}
+
+// Recalculate all scheduled cells which are due.
+func (self *NotebookManager) updateScheduledCells(
+	ctx context.Context, now time.Time) error {
+
+	db, err := datastore.GetDB(self.config_obj)
+	if err != nil {
+		return err
+	}
+
+	index_urn := paths.NOTEBOOK_SCHEDULE_INDEX.AddChild(scheduleIndexKeyword)
+	children, err := db.ListChildren(self.config_obj, index_urn)
+	if err != nil {
+		return err
+	}
+
+	logger := logging.GetLogger(self.config_obj, &logging.GUIComponent)
+	for _, child := range children {
+		notebook_id := child.Base()
+		scheduled, err := self.updateScheduledNotebook(ctx, notebook_id, now)
+		if err != nil {
+			logger.Error("NotebookManager: updating scheduled cells in %v: %v",
+				notebook_id, err)
+			continue
+		}
+
+		if !scheduled {
+			indexer, err := services.GetIndexer(self.config_obj)
+			if err != nil {
+				return err
+			}
+
+			err = indexer.UnsetSimpleIndex(self.config_obj,
+				paths.NOTEBOOK_SCHEDULE_INDEX, notebook_id,
+				[]string{scheduleIndexKeyword})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Returns true if the notebook still has scheduled cells.
+func (self *NotebookManager) updateScheduledNotebook(
+	ctx context.Context, notebook_id string, now time.Time) (bool, error) {
+
+	notebook, err := self.Store.GetNotebook(notebook_id)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	scheduled := false
+	for _, cell_md := range notebook.CellMetadata {
+		cell, err := self.Store.GetNotebookCell(notebook_id, cell_md.CellId)
+		if err != nil || cell.Schedule == "" {
+			continue
+		}
+
+		scheduled = true
+		if cell.Calculating || now.Unix() < cell.NextUpdate {
+			continue
+		}
+
+		// The cell is recalculated with the permissions of the
+		// user who scheduled it so it must still be allowed to.
+		user_name := cell.ScheduledBy
+		perm, err := acls.CheckAccess(
+			self.config_obj, user_name, acls.NOTEBOOK_EDITOR)
+		if !perm || err != nil ||
+			!self.CheckNotebookAccess(notebook, user_name) {
+			return scheduled, fmt.Errorf(
+				"User %v may no longer update notebook", user_name)
+		}
+
+		// Push the next update out before starting so a slow
+		// query is not started again on the next check.
+		interval, err := getScheduleInterval(cell.Schedule)
+		if err != nil {
+			return scheduled, err
+		}
+		cell.NextUpdate = now.Add(interval).Unix()
+		err = self.Store.SetNotebookCell(notebook_id, cell)
+		if err != nil {
+			return scheduled, err
+		}
+
+		_, err = self.UpdateNotebookCell(ctx, notebook, user_name,
+			&api_proto.NotebookCellRequest{
+				NotebookId: notebook_id,
+				CellId:     cell.CellId,
+				Input:      cell.Input,
+				Type:       cell.Type,
+				Env:        cell.Env,
+				Schedule:   cell.Schedule,
+			})
+		if err != nil {
+			return scheduled, err
+		}
+	}
+
+	return scheduled, nil
+}
+
+func (self *NotebookManager) startScheduler(
+	ctx context.Context, wg *sync.WaitGroup) {
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		logger := logging.GetLogger(self.config_obj, &logging.GUIComponent)
+		logger.Info("<green>Starting</> Notebook Scheduler for %v.",
+			services.GetOrgName(self.config_obj))
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-time.After(SCHEDULE_CHECK_FREQUENCY):
+				err := self.updateScheduledCells(ctx, time.Now())
+				if err != nil {
+					logger.Error("NotebookManager: %v", err)
+				}
+			}
+		}
+	}()
+}
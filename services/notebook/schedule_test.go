@@ -0,0 +1,128 @@
+package notebook_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert"
+	"github.com/stretchr/testify/suite"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/file_store/test_utils"
+	"www.velocidex.com/golang/velociraptor/paths"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/services/notebook"
+	"www.velocidex.com/golang/velociraptor/vtesting"
+)
+
+type ScheduleTestSuite struct {
+	test_utils.TestSuite
+	notebook_manager *notebook.NotebookManager
+}
+
+func (self *ScheduleTestSuite) SetupTest() {
+	self.ConfigObj = self.LoadConfig()
+	self.ConfigObj.Frontend.ServerServices.NotebookService = true
+
+	self.TestSuite.SetupTest()
+
+	self.LoadArtifactFiles(
+		"../../artifacts/definitions/Server/Internal/ArtifactDescription.yaml")
+
+	notebook_manager, err := services.GetNotebookManager(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	self.notebook_manager = notebook_manager.(*notebook.NotebookManager)
+}
+
+func (self *ScheduleTestSuite) TestScheduleCell() {
+	notebook_metadata := &api_proto.NotebookMetadata{
+		NotebookId: "N.1234",
+		Creator:    "User1",
+	}
+
+	// Only hourly and daily schedules are supported.
+	_, err := self.notebook_manager.UpdateNotebookCell(self.Ctx,
+		notebook_metadata, "User1", &api_proto.NotebookCellRequest{
+			NotebookId: "N.1234",
+			CellId:     "NC.1",
+			Input:      "SELECT * FROM info()",
+			Type:       "VQL",
+			Schedule:   "weekly",
+		})
+	assert.Error(self.T(), err)
+
+	now := time.Now().Unix()
+	cell, err := self.notebook_manager.UpdateNotebookCell(self.Ctx,
+		notebook_metadata, "User1", &api_proto.NotebookCellRequest{
+			NotebookId: "N.1234",
+			CellId:     "NC.1",
+			Input:      "SELECT * FROM info()",
+			Type:       "VQL",
+			Schedule:   "hourly",
+		})
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), "hourly", cell.Schedule)
+	assert.Equal(self.T(), "User1", cell.ScheduledBy)
+	assert.True(self.T(), cell.NextUpdate >= now+3600)
+
+	// The notebook is added to the schedule index.
+	indexer, err := services.GetIndexer(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	err = indexer.CheckSimpleIndex(self.ConfigObj,
+		paths.NOTEBOOK_SCHEDULE_INDEX, "N.1234", []string{"all"})
+	assert.NoError(self.T(), err)
+
+	// The calculated cell keeps its schedule.
+	vtesting.WaitUntil(5*time.Second, self.T(), func() bool {
+		cell, err = self.notebook_manager.GetNotebookCell(
+			self.Ctx, "N.1234", "NC.1")
+		return err == nil && !cell.Calculating
+	})
+	assert.Equal(self.T(), "hourly", cell.Schedule)
+	assert.Equal(self.T(), "User1", cell.ScheduledBy)
+	assert.True(self.T(), cell.NextUpdate >= now+3600)
+	assert.False(self.T(), cell.Stale)
+}
+
+func (self *ScheduleTestSuite) TestStaleCell() {
+	err := self.notebook_manager.Store.SetNotebook(&api_proto.NotebookMetadata{
+		NotebookId: "N.1234",
+		Creator:    "User1",
+	})
+	assert.NoError(self.T(), err)
+
+	now := time.Now().Unix()
+	for _, test := range []struct {
+		cell  *api_proto.NotebookCell
+		stale bool
+	}{
+		// Missed its update.
+		{&api_proto.NotebookCell{
+			Schedule: "hourly", NextUpdate: now - 60}, true},
+
+		// Not due yet.
+		{&api_proto.NotebookCell{
+			Schedule: "hourly", NextUpdate: now + 60}, false},
+
+		// Currently being updated.
+		{&api_proto.NotebookCell{
+			Schedule: "hourly", NextUpdate: now - 60, Calculating: true}, false},
+
+		// Not scheduled.
+		{&api_proto.NotebookCell{NextUpdate: now - 60}, false},
+	} {
+		test.cell.CellId = "NC.1"
+		err = self.notebook_manager.Store.SetNotebookCell("N.1234", test.cell)
+		assert.NoError(self.T(), err)
+
+		cell, err := self.notebook_manager.GetNotebookCell(
+			self.Ctx, "N.1234", "NC.1")
+		assert.NoError(self.T(), err)
+		assert.Equal(self.T(), test.stale, cell.Stale)
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	suite.Run(t, &ScheduleTestSuite{})
+}
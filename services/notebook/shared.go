@@ -32,6 +32,18 @@ func (self *NotebookManager) CheckNotebookAccess(
 	return notebook.Creator == user || utils.InString(notebook.Collaborators, user)
 }
 
+// Notebooks shared for reading may be viewed by all users in the
+// org but only edited by the creator and collaborators.
+func (self *NotebookManager) CheckNotebookReadAccess(
+	notebook *api_proto.NotebookMetadata,
+	user string) bool {
+	if notebook.PublicRead {
+		return true
+	}
+
+	return self.CheckNotebookAccess(notebook, user)
+}
+
 // Returns all the notebooks which are either owned or shared with the
 // user
 func (self *NotebookManager) GetSharedNotebooks(
@@ -53,16 +65,8 @@ func (self *NotebookManager) GetSharedNotebooks(
 		return nil, err
 	}
 
-	for idx, notebook_id_urn := range notebook_id_urns {
+	for _, notebook_id_urn := range notebook_id_urns {
 		notebook_id := notebook_id_urn.Base()
-		if uint64(idx) < offset {
-			continue
-		}
-
-		if uint64(idx) > offset+count {
-			break
-		}
-
 		notebook_path_manager := paths.NewNotebookPathManager(notebook_id)
 		notebook := &api_proto.NotebookMetadata{}
 		err := db.GetSubject(self.config_obj, notebook_path_manager.Path(), notebook)
@@ -78,7 +82,7 @@ func (self *NotebookManager) GetSharedNotebooks(
 			continue
 		}
 
-		if !self.CheckNotebookAccess(notebook, user) {
+		if !self.CheckNotebookReadAccess(notebook, user) {
 			continue
 		}
 
@@ -87,11 +91,13 @@ func (self *NotebookManager) GetSharedNotebooks(
 		}
 	}
 
+	// Page over the visible notebooks so pages are stable.
 	sort.Slice(result, func(i, j int) bool {
 		return result[i].NotebookId < result[j].NotebookId
 	})
 
-	return result, nil
+	start, end := utils.PageBounds(offset, count, len(result))
+	return result[start:end], nil
 }
 
 func GetAllNotebooks(
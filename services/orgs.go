@@ -50,6 +50,10 @@ type ServiceContainer interface {
 	ClientEventManager() (ClientEventTable, error)
 	ServerEventManager() (ServerEventManager, error)
 	Notifier() (Notifier, error)
+	ComplianceManager() (ComplianceManager, error)
+	FileIndexer() (FileIndexer, error)
+	JobManager() (JobManager, error)
+	ArtifactExchange() (ArtifactExchange, error)
 }
 
 // The org manager manages multi-tenancies.
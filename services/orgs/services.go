@@ -8,19 +8,26 @@ import (
 	"www.velocidex.com/golang/velociraptor/logging"
 	"www.velocidex.com/golang/velociraptor/services"
 	"www.velocidex.com/golang/velociraptor/services/broadcast"
+	"www.velocidex.com/golang/velociraptor/services/canary"
 	"www.velocidex.com/golang/velociraptor/services/client_info"
 	"www.velocidex.com/golang/velociraptor/services/client_monitoring"
+	"www.velocidex.com/golang/velociraptor/services/compliance"
+	"www.velocidex.com/golang/velociraptor/services/ephemeral"
+	"www.velocidex.com/golang/velociraptor/services/exchange"
+	"www.velocidex.com/golang/velociraptor/services/file_index"
 	"www.velocidex.com/golang/velociraptor/services/frontend"
 	"www.velocidex.com/golang/velociraptor/services/hunt_dispatcher"
 	"www.velocidex.com/golang/velociraptor/services/hunt_manager"
 	"www.velocidex.com/golang/velociraptor/services/indexing"
 	"www.velocidex.com/golang/velociraptor/services/interrogation"
 	"www.velocidex.com/golang/velociraptor/services/inventory"
+	"www.velocidex.com/golang/velociraptor/services/jobs"
 	"www.velocidex.com/golang/velociraptor/services/journal"
 	"www.velocidex.com/golang/velociraptor/services/labels"
 	"www.velocidex.com/golang/velociraptor/services/launcher"
 	"www.velocidex.com/golang/velociraptor/services/notebook"
 	"www.velocidex.com/golang/velociraptor/services/notifications"
+	"www.velocidex.com/golang/velociraptor/services/reaper"
 	"www.velocidex.com/golang/velociraptor/services/repository"
 	"www.velocidex.com/golang/velociraptor/services/sanity"
 	"www.velocidex.com/golang/velociraptor/services/server_artifacts"
@@ -47,6 +54,10 @@ type ServiceContainer struct {
 	client_event_manager services.ClientEventTable
 	server_event_manager services.ServerEventManager
 	notifier             services.Notifier
+	compliance_manager   services.ComplianceManager
+	file_indexer         services.FileIndexer
+	job_manager          services.JobManager
+	exchange             services.ArtifactExchange
 }
 
 func (self *ServiceContainer) MockFrontendManager(svc services.FrontendManager) {
@@ -121,6 +132,50 @@ func (self *ServiceContainer) Launcher() (services.Launcher, error) {
 	return self.launcher, nil
 }
 
+func (self *ServiceContainer) ComplianceManager() (services.ComplianceManager, error) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	if self.compliance_manager == nil {
+		return nil, errors.New("Compliance service not initialized")
+	}
+
+	return self.compliance_manager, nil
+}
+
+func (self *ServiceContainer) FileIndexer() (services.FileIndexer, error) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	if self.file_indexer == nil {
+		return nil, errors.New("File index service not initialized")
+	}
+
+	return self.file_indexer, nil
+}
+
+func (self *ServiceContainer) JobManager() (services.JobManager, error) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	if self.job_manager == nil {
+		return nil, errors.New("Job service not initialized")
+	}
+
+	return self.job_manager, nil
+}
+
+func (self *ServiceContainer) ArtifactExchange() (services.ArtifactExchange, error) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	if self.exchange == nil {
+		return nil, errors.New("Artifact exchange service not initialized")
+	}
+
+	return self.exchange, nil
+}
+
 func (self *ServiceContainer) HuntDispatcher() (services.IHuntDispatcher, error) {
 
 	self.mu.Lock()
@@ -520,6 +575,71 @@ func (self *OrgManager) startOrgFromContext(org_ctx *OrgContext) (err error) {
 		service_container.server_event_manager = server_event_manager
 		service_container.mu.Unlock()
 	}
+
+	if spec.ComplianceService {
+		cm, err := compliance.NewComplianceService(self.ctx, self.wg, org_config)
+		if err != nil {
+			return err
+		}
+
+		service_container.mu.Lock()
+		service_container.compliance_manager = cm
+		service_container.mu.Unlock()
+	}
+
+	if spec.FileIndexService {
+		fi, err := file_index.NewFileIndexService(self.ctx, self.wg, org_config)
+		if err != nil {
+			return err
+		}
+
+		service_container.mu.Lock()
+		service_container.file_indexer = fi
+		service_container.mu.Unlock()
+	}
+
+	if spec.JobService {
+		jm, err := jobs.NewJobService(self.ctx, self.wg, org_config)
+		if err != nil {
+			return err
+		}
+
+		service_container.mu.Lock()
+		service_container.job_manager = jm
+		service_container.mu.Unlock()
+	}
+
+	if spec.ExchangeService {
+		ex, err := exchange.NewExchangeService(self.ctx, self.wg, org_config)
+		if err != nil {
+			return err
+		}
+
+		service_container.mu.Lock()
+		service_container.exchange = ex
+		service_container.mu.Unlock()
+	}
+
+	if spec.EphemeralClientService {
+		err = ephemeral.NewEphemeralClientService(self.ctx, self.wg, org_config)
+		if err != nil {
+			return err
+		}
+	}
+
+	if spec.CanaryService {
+		err = canary.NewCanaryService(self.ctx, self.wg, org_config)
+		if err != nil {
+			return err
+		}
+	}
+
+	if spec.FlowReaperService {
+		err = reaper.NewFlowReaperService(self.ctx, self.wg, org_config)
+		if err != nil {
+			return err
+		}
+	}
 	return err
 }
 
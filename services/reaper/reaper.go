@@ -0,0 +1,282 @@
+/*
+  The flow reaper marks stuck flows as failed.
+
+  A flow stays in the RUNNING state until the client reports its
+  completion. If the client crashes, is reinstalled or loses the
+  collection, the flow would run forever. The client enforces the
+  flow's timeout from the time it receives the requests, so if nothing
+  was heard about the flow long after that, it will never complete.
+
+  Flows whose requests are still queued are waiting for an offline
+  client and are left alone. Reaped flows are reported on the
+  System.Flow.Timeout artifact so they can be alerted on.
+*/
+
+package reaper
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/datastore"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/paths"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/utils"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+)
+
+const (
+	DEFAULT_GRACE           = 10 * time.Minute
+	DEFAULT_CHECK_FREQUENCY = time.Hour
+
+	// The client's default query timeout.
+	DEFAULT_FLOW_TIMEOUT = 600
+)
+
+var (
+	Clock utils.Clock = &utils.RealClock{}
+)
+
+type FlowReaperService struct {
+	grace time.Duration
+}
+
+// Check if the flow is stuck: the client should have reported back
+// within the timeout after it received the requests.
+func (self *FlowReaperService) isStuck(
+	collection_context *flows_proto.ArtifactCollectorContext,
+	queued map[string]bool) bool {
+
+	if collection_context.State != flows_proto.ArtifactCollectorContext_RUNNING {
+		return false
+	}
+
+	// The client has not picked up the requests yet.
+	if queued[collection_context.SessionId] {
+		return false
+	}
+
+	timeout := collection_context.Request.GetTimeout()
+	if timeout == 0 {
+		timeout = DEFAULT_FLOW_TIMEOUT
+	}
+
+	deadline := lastActive(collection_context).
+		Add(time.Duration(timeout) * time.Second).
+		Add(self.grace)
+
+	return Clock.Now().After(deadline)
+}
+
+// Flows which never responded were last active when they were
+// created or delivered.
+func lastActive(
+	collection_context *flows_proto.ArtifactCollectorContext) time.Time {
+	last_active := collection_context.ActiveTime
+	if last_active < collection_context.CreateTime {
+		last_active = collection_context.CreateTime
+	}
+	return time.Unix(0, int64(last_active)*1000)
+}
+
+// Find the client's stuck flows.
+func (self *FlowReaperService) StuckFlows(
+	config_obj *config_proto.Config,
+	client_id string) ([]*flows_proto.ArtifactCollectorContext, error) {
+
+	launcher, err := services.GetLauncher(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	running, err := launcher.GetFlows(config_obj, client_id, false,
+		func(flow *flows_proto.ArtifactCollectorContext) bool {
+			return flow.State == flows_proto.ArtifactCollectorContext_RUNNING
+		}, 0, 0)
+	if err != nil || len(running.Items) == 0 {
+		return nil, err
+	}
+
+	client_info_manager, err := services.GetClientInfoManager(config_obj)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := client_info_manager.PeekClientTasks(client_id)
+	if err != nil {
+		return nil, err
+	}
+
+	queued := make(map[string]bool)
+	for _, task := range tasks {
+		queued[task.SessionId] = true
+	}
+
+	result := []*flows_proto.ArtifactCollectorContext{}
+	for _, flow := range running.Items {
+		// The flow list does not include the latest activity.
+		details, err := launcher.GetFlowDetails(
+			config_obj, client_id, flow.SessionId)
+		if err != nil || details.Context == nil {
+			continue
+		}
+
+		if self.isStuck(details.Context, queued) {
+			result = append(result, details.Context)
+		}
+	}
+
+	return result, nil
+}
+
+// Mark the flow as failed and let listeners know.
+func (self *FlowReaperService) Reap(
+	config_obj *config_proto.Config,
+	collection_context *flows_proto.ArtifactCollectorContext) error {
+
+	db, err := datastore.GetDB(config_obj)
+	if err != nil {
+		return err
+	}
+
+	last_active := lastActive(collection_context).UTC()
+
+	collection_context.State = flows_proto.ArtifactCollectorContext_ERROR
+	collection_context.Status = fmt.Sprintf(
+		"Timeout: Nothing was heard from the client since %v",
+		last_active.Format(time.RFC3339))
+	collection_context.Backtrace = ""
+
+	flow_path_manager := paths.NewFlowPathManager(
+		collection_context.ClientId, collection_context.SessionId)
+	err = db.SetSubject(config_obj, flow_path_manager.Path(), collection_context)
+	if err != nil {
+		return err
+	}
+
+	journal, err := services.GetJournal(config_obj)
+	if err != nil {
+		return err
+	}
+
+	// Hunts count the failed flow as for any other completion.
+	err = journal.PushRowsToArtifact(config_obj,
+		[]*ordereddict.Dict{ordereddict.NewDict().
+			Set("Timestamp", Clock.Now().UTC().Unix()).
+			Set("Flow", collection_context).
+			Set("FlowId", collection_context.SessionId).
+			Set("ClientId", collection_context.ClientId)},
+		"System.Flow.Completion", "server", "")
+	if err != nil {
+		return err
+	}
+
+	return journal.PushRowsToArtifact(config_obj,
+		[]*ordereddict.Dict{ordereddict.NewDict().
+			Set("Timestamp", Clock.Now().UTC().Unix()).
+			Set("ClientId", collection_context.ClientId).
+			Set("FlowId", collection_context.SessionId).
+			Set("Artifacts", collection_context.Request.GetArtifacts()).
+			Set("Creator", collection_context.Request.GetCreator()).
+			Set("LastActive", last_active)},
+		"System.Flow.Timeout", "server", "")
+}
+
+// Check all the clients for stuck flows.
+func (self *FlowReaperService) ReapAll(
+	ctx context.Context,
+	config_obj *config_proto.Config) error {
+
+	indexer, err := services.GetIndexer(config_obj)
+	if err != nil {
+		return err
+	}
+
+	scope := vql_subsystem.MakeScope()
+	defer scope.Close()
+
+	search_chan, err := indexer.SearchClientsChan(
+		ctx, scope, config_obj, "all", "")
+	if err != nil {
+		return err
+	}
+
+	logger := logging.GetLogger(config_obj, &logging.FrontendComponent)
+	for api_client := range search_chan {
+		stuck, err := self.StuckFlows(config_obj, api_client.ClientId)
+		if err != nil {
+			logger.Error("FlowReaperService: %v: %v", api_client.ClientId, err)
+			continue
+		}
+
+		for _, collection_context := range stuck {
+			err = self.Reap(config_obj, collection_context)
+			if err != nil {
+				logger.Error("FlowReaperService: %v: %v",
+					collection_context.SessionId, err)
+				continue
+			}
+
+			logger.Info("FlowReaperService: Flow %v on %v timed out",
+				collection_context.SessionId, collection_context.ClientId)
+		}
+	}
+
+	return nil
+}
+
+func NewFlowReaperService(
+	ctx context.Context,
+	wg *sync.WaitGroup,
+	config_obj *config_proto.Config) error {
+
+	// Only the master looks for stuck flows.
+	if !services.IsMaster(config_obj) {
+		return nil
+	}
+
+	service := &FlowReaperService{grace: DEFAULT_GRACE}
+	frequency := DEFAULT_CHECK_FREQUENCY
+
+	if config_obj.FlowReaper != nil {
+		if config_obj.FlowReaper.GraceSeconds > 0 {
+			service.grace = time.Duration(
+				config_obj.FlowReaper.GraceSeconds) * time.Second
+		}
+
+		if config_obj.FlowReaper.CheckFrequencySeconds > 0 {
+			frequency = time.Duration(
+				config_obj.FlowReaper.CheckFrequencySeconds) * time.Second
+		}
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		logger := logging.GetLogger(config_obj, &logging.FrontendComponent)
+		logger.Info("<green>Starting</> Flow Reaper Service for %v.",
+			services.GetOrgName(config_obj))
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-time.After(frequency):
+				err := service.ReapAll(ctx, config_obj)
+				if err != nil {
+					logger.Error("FlowReaperService: %v", err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
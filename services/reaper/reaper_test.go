@@ -0,0 +1,133 @@
+package reaper_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	actions_proto "www.velocidex.com/golang/velociraptor/actions/proto"
+	crypto_proto "www.velocidex.com/golang/velociraptor/crypto/proto"
+	"www.velocidex.com/golang/velociraptor/datastore"
+	"www.velocidex.com/golang/velociraptor/file_store/test_utils"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/paths"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/services/reaper"
+	"www.velocidex.com/golang/velociraptor/utils"
+
+	_ "www.velocidex.com/golang/velociraptor/result_sets/timed"
+)
+
+type ReaperTestSuite struct {
+	test_utils.TestSuite
+	client_id string
+	now       time.Time
+}
+
+func (self *ReaperTestSuite) SetupTest() {
+	self.TestSuite.SetupTest()
+
+	self.LoadArtifactFiles(
+		"../../artifacts/definitions/System/Flow/Timeout.yaml")
+
+	self.client_id = "C.1"
+	self.now = time.Unix(1700000000, 0)
+	reaper.Clock = &utils.MockClock{MockNow: self.now}
+
+	client_info_manager, err := services.GetClientInfoManager(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	err = client_info_manager.Set(&services.ClientInfo{actions_proto.ClientInfo{
+		ClientId: self.client_id,
+	}})
+	assert.NoError(self.T(), err)
+
+	indexer, err := services.GetIndexer(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	err = indexer.SetIndex(self.client_id, "all")
+	assert.NoError(self.T(), err)
+
+	db, err := datastore.GetDB(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	old := uint64(self.now.Add(-2*time.Hour).UnixNano() / 1000)
+	recent := uint64(self.now.Add(-time.Minute).UnixNano() / 1000)
+
+	// F.1 is stuck, F.2 is waiting for the client to come online, F.3
+	// is still sending results and F.4 is already complete.
+	for _, flow := range []*flows_proto.ArtifactCollectorContext{
+		{SessionId: "F.1", CreateTime: old,
+			State: flows_proto.ArtifactCollectorContext_RUNNING},
+		{SessionId: "F.2", CreateTime: old,
+			State: flows_proto.ArtifactCollectorContext_RUNNING},
+		{SessionId: "F.3", CreateTime: old, ActiveTime: recent,
+			State: flows_proto.ArtifactCollectorContext_RUNNING},
+		{SessionId: "F.4", CreateTime: old,
+			State: flows_proto.ArtifactCollectorContext_FINISHED},
+	} {
+		flow.ClientId = self.client_id
+		flow.Request = &flows_proto.ArtifactCollectorArgs{
+			Artifacts: []string{"Generic.Client.Info"},
+			Timeout:   600,
+		}
+		err = db.SetSubject(self.ConfigObj,
+			paths.NewFlowPathManager(self.client_id, flow.SessionId).Path(),
+			flow)
+		assert.NoError(self.T(), err)
+	}
+
+	err = client_info_manager.QueueMessageForClient(self.client_id,
+		&crypto_proto.VeloMessage{
+			SessionId:       "F.2",
+			VQLClientAction: &actions_proto.VQLCollectorArgs{},
+		}, false /* notify */, nil)
+	assert.NoError(self.T(), err)
+}
+
+func (self *ReaperTestSuite) TearDownTest() {
+	reaper.Clock = &utils.RealClock{}
+	self.TestSuite.TearDownTest()
+}
+
+func (self *ReaperTestSuite) TestReapStuckFlows() {
+	service := &reaper.FlowReaperService{}
+
+	stuck, err := service.StuckFlows(self.ConfigObj, self.client_id)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), 1, len(stuck))
+	assert.Equal(self.T(), "F.1", stuck[0].SessionId)
+
+	err = service.ReapAll(context.Background(), self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	launcher, err := services.GetLauncher(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	for flow_id, state := range map[string]flows_proto.ArtifactCollectorContext_State{
+		"F.1": flows_proto.ArtifactCollectorContext_ERROR,
+		"F.2": flows_proto.ArtifactCollectorContext_RUNNING,
+		"F.3": flows_proto.ArtifactCollectorContext_RUNNING,
+		"F.4": flows_proto.ArtifactCollectorContext_FINISHED,
+	} {
+		details, err := launcher.GetFlowDetails(
+			self.ConfigObj, self.client_id, flow_id)
+		assert.NoError(self.T(), err)
+		assert.Equal(self.T(), state, details.Context.State, flow_id)
+
+		if flow_id == "F.1" {
+			assert.Contains(self.T(), details.Context.Status, "Timeout")
+		}
+	}
+
+	// Nothing is left to reap.
+	stuck, err = service.StuckFlows(self.ConfigObj, self.client_id)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), 0, len(stuck))
+}
+
+func TestFlowReaperService(t *testing.T) {
+	suite.Run(t, &ReaperTestSuite{})
+}
@@ -45,6 +45,9 @@ import (
 
 var (
 	artifactNameRegex = regexp.MustCompile("^[a-zA-Z0-9_.]+$")
+
+	// ATT&CK technique ids, optionally with a sub-technique.
+	attackTechniqueRegex = regexp.MustCompile(`^T[0-9]{4}(\.[0-9]{3})?$`)
 )
 
 // Holds multiple artifact definitions.
@@ -209,6 +212,15 @@ func (self *Repository) LoadProto(artifact *artifacts_proto.Artifact, validate b
 		return nil, errors.New("Artifact type invalid.")
 	}
 
+	// Normalize technique ids so they can be matched exactly.
+	for i, technique := range artifact.MitreAttack {
+		technique = strings.ToUpper(strings.TrimSpace(technique))
+		if !attackTechniqueRegex.MatchString(technique) {
+			return nil, fmt.Errorf("Invalid ATT&CK technique id %q", technique)
+		}
+		artifact.MitreAttack[i] = technique
+	}
+
 	// Normalize the artifact by converting the deprecated Queries
 	// field to the Query field.
 	for _, source := range artifact.Sources {
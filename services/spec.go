@@ -53,5 +53,13 @@ func AllServicesSpec() *config_proto.ServerServicesConfig {
 		Label:               true,
 		Launcher:            true,
 		NotebookService:     true,
+		ComplianceService:   true,
+		FileIndexService:    true,
+		JobService:          true,
+		ExchangeService:     true,
+
+		EphemeralClientService: true,
+		CanaryService:          true,
+		FlowReaperService:      true,
 	}
 }
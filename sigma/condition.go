@@ -0,0 +1,158 @@
+package sigma
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var conditionTokenRegex = regexp.MustCompile(`\(|\)|\||[^\s()|]+`)
+
+// The translated search identifiers in the order they appear in the
+// rule.
+type searchSet struct {
+	names []string
+	vql   map[string]string
+}
+
+// Search identifiers matching a pattern like "selection*". The
+// pattern "them" matches all of them.
+func (self *searchSet) match(pattern string) []string {
+	result := []string{}
+	for _, name := range self.names {
+		matched, _ := path.Match(pattern, name)
+		if pattern == "them" || matched {
+			result = append(result, self.vql[name])
+		}
+	}
+	return result
+}
+
+// A recursive descent parser for Sigma conditions:
+//
+// expr   := term ("or" term)*
+// term   := factor ("and" factor)*
+// factor := "not" factor | "(" expr ")" | quantifier "of" pattern | name
+type conditionParser struct {
+	tokens   []string
+	pos      int
+	searches *searchSet
+}
+
+func parseCondition(condition string, searches *searchSet) (string, error) {
+	parser := &conditionParser{
+		tokens:   conditionTokenRegex.FindAllString(condition, -1),
+		searches: searches,
+	}
+
+	result, err := parser.expr()
+	if err != nil {
+		return "", fmt.Errorf("Condition %q: %w", condition, err)
+	}
+
+	if parser.pos < len(parser.tokens) {
+		if parser.peek() == "|" {
+			return "", fmt.Errorf(
+				"Condition %q: aggregations are not supported", condition)
+		}
+		return "", fmt.Errorf("Condition %q: unexpected %q",
+			condition, parser.peek())
+	}
+
+	return result, nil
+}
+
+func (self *conditionParser) peek() string {
+	if self.pos >= len(self.tokens) {
+		return ""
+	}
+	return self.tokens[self.pos]
+}
+
+func (self *conditionParser) next() string {
+	token := self.peek()
+	self.pos++
+	return token
+}
+
+func (self *conditionParser) expr() (string, error) {
+	terms := []string{}
+	for {
+		term, err := self.term()
+		if err != nil {
+			return "", err
+		}
+		terms = append(terms, term)
+
+		if strings.ToLower(self.peek()) != "or" {
+			return joinTerms(terms, " OR "), nil
+		}
+		self.next()
+	}
+}
+
+func (self *conditionParser) term() (string, error) {
+	factors := []string{}
+	for {
+		factor, err := self.factor()
+		if err != nil {
+			return "", err
+		}
+		factors = append(factors, factor)
+
+		if strings.ToLower(self.peek()) != "and" {
+			return joinTerms(factors, " AND "), nil
+		}
+		self.next()
+	}
+}
+
+func (self *conditionParser) factor() (string, error) {
+	token := self.next()
+	switch strings.ToLower(token) {
+	case "":
+		return "", fmt.Errorf("unexpected end of condition")
+
+	case "not":
+		factor, err := self.factor()
+		if err != nil {
+			return "", err
+		}
+		return "NOT (" + factor + ")", nil
+
+	case "(":
+		expr, err := self.expr()
+		if err != nil {
+			return "", err
+		}
+		if self.next() != ")" {
+			return "", fmt.Errorf("missing )")
+		}
+		// joinTerms() already parenthesizes compound expressions.
+		return expr, nil
+
+	case "1", "any", "all":
+		if strings.ToLower(self.next()) != "of" {
+			return "", fmt.Errorf("expected 'of' after %q", token)
+		}
+
+		pattern := self.next()
+		terms := self.searches.match(pattern)
+		if len(terms) == 0 {
+			return "", fmt.Errorf("no search identifiers match %q", pattern)
+		}
+
+		if strings.ToLower(token) == "all" {
+			return joinTerms(terms, " AND "), nil
+		}
+		return joinTerms(terms, " OR "), nil
+
+	default:
+		vql, pres := self.searches.vql[token]
+		if !pres {
+			return "", fmt.Errorf("unknown search identifier %q", token)
+		}
+		return vql, nil
+	}
+}
@@ -0,0 +1,261 @@
+package sigma
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Velocidex/yaml/v2"
+)
+
+var (
+	identifierRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+	// Fields in the System part of the event. Everything else is
+	// looked up in EventData.
+	systemFields = map[string]string{
+		"EventID":       "System.EventID.Value",
+		"Channel":       "System.Channel",
+		"Computer":      "System.Computer",
+		"Provider_Name": "System.Provider.Name",
+		"Level":         "System.Level",
+	}
+)
+
+// Translate the detection section into a VQL expression.
+func (self *translator) translateDetection(detection yaml.MapSlice) (string, error) {
+	searches := &searchSet{vql: make(map[string]string)}
+	var conditions []string
+
+	for _, item := range detection {
+		name := fmt.Sprintf("%v", item.Key)
+		switch name {
+		case "condition":
+			switch value := item.Value.(type) {
+			case string:
+				conditions = append(conditions, value)
+			case []interface{}:
+				for _, c := range value {
+					conditions = append(conditions, fmt.Sprintf("%v", c))
+				}
+			default:
+				return "", errors.New("Invalid condition")
+			}
+
+		case "timeframe":
+			return "", errors.New("Rules with a timeframe are not supported")
+
+		default:
+			vql, err := self.translateSearch(item.Value)
+			if err != nil {
+				return "", fmt.Errorf("%v: %w", name, err)
+			}
+			searches.names = append(searches.names, name)
+			searches.vql[name] = vql
+		}
+	}
+
+	if len(conditions) == 0 {
+		return "", errors.New("Rule has no condition")
+	}
+
+	// Multiple conditions are alternatives.
+	result := []string{}
+	for _, condition := range conditions {
+		vql, err := parseCondition(condition, searches)
+		if err != nil {
+			return "", err
+		}
+		result = append(result, vql)
+	}
+
+	return joinTerms(result, " OR "), nil
+}
+
+// A search identifier is either a map of fields which must all
+// match, a list of such maps of which any must match or a list of
+// keywords.
+func (self *translator) translateSearch(search interface{}) (string, error) {
+	switch search := search.(type) {
+	case yaml.MapSlice:
+		return self.translateFieldMap(search)
+
+	case []interface{}:
+		terms := []string{}
+		for _, item := range search {
+			field_map, ok := item.(yaml.MapSlice)
+			if ok {
+				vql, err := self.translateFieldMap(field_map)
+				if err != nil {
+					return "", err
+				}
+				terms = append(terms, vql)
+				continue
+			}
+
+			vql, err := self.translateKeyword(item)
+			if err != nil {
+				return "", err
+			}
+			terms = append(terms, vql)
+		}
+		return joinTerms(terms, " OR "), nil
+
+	default:
+		return self.translateKeyword(search)
+	}
+}
+
+func (self *translator) translateFieldMap(field_map yaml.MapSlice) (string, error) {
+	terms := []string{}
+	for _, item := range field_map {
+		vql, err := self.translateField(fmt.Sprintf("%v", item.Key), item.Value)
+		if err != nil {
+			return "", err
+		}
+		terms = append(terms, vql)
+	}
+	return joinTerms(terms, " AND "), nil
+}
+
+// Keywords may appear anywhere in the event.
+func (self *translator) translateKeyword(keyword interface{}) (string, error) {
+	str, ok := keyword.(string)
+	if !ok {
+		str = fmt.Sprintf("%v", keyword)
+	}
+
+	self.warn("Keyword %q is matched against the serialized event data", str)
+	return fmt.Sprintf("serialize(item=EventData) =~ %s",
+		quoteRegex("(?i)"+wildcardToRegex(str))), nil
+}
+
+func (self *translator) translateField(key string, value interface{}) (string, error) {
+	parts := strings.Split(key, "|")
+	field := fieldExpression(parts[0])
+
+	match := ""
+	match_all := false
+	for _, modifier := range parts[1:] {
+		switch modifier {
+		case "contains", "startswith", "endswith", "re":
+			match = modifier
+		case "all":
+			match_all = true
+		default:
+			return "", fmt.Errorf("Unsupported modifier %q", modifier)
+		}
+	}
+
+	values, ok := value.([]interface{})
+	if !ok {
+		values = []interface{}{value}
+	}
+
+	terms := []string{}
+	for _, value := range values {
+		vql, err := valueExpression(field, match, value)
+		if err != nil {
+			return "", err
+		}
+		terms = append(terms, vql)
+	}
+
+	if match_all {
+		return joinTerms(terms, " AND "), nil
+	}
+	return joinTerms(terms, " OR "), nil
+}
+
+func fieldExpression(field string) string {
+	expression, pres := systemFields[field]
+	if pres {
+		return expression
+	}
+
+	if identifierRegex.MatchString(field) {
+		return "EventData." + field
+	}
+	return "EventData.`" + field + "`"
+}
+
+// Sigma string matching is case insensitive and supports * and ?
+// wildcards.
+func valueExpression(field, match string, value interface{}) (string, error) {
+	switch value := value.(type) {
+	case nil:
+		return "NOT " + field, nil
+
+	case int, int64, uint64, float64, bool:
+		if match == "" {
+			return fmt.Sprintf("%s = %v", field, value), nil
+		}
+		return valueExpression(field, match, fmt.Sprintf("%v", value))
+
+	case string:
+		regex := wildcardToRegex(value)
+		switch match {
+		case "":
+			regex = "(?i)^" + regex + "$"
+		case "contains":
+			regex = "(?i)" + regex
+		case "startswith":
+			regex = "(?i)^" + regex
+		case "endswith":
+			regex = "(?i)" + regex + "$"
+		case "re":
+			regex = value
+		}
+		return field + " =~ " + quoteRegex(regex), nil
+
+	default:
+		return "", fmt.Errorf("Unsupported value %v", value)
+	}
+}
+
+// A backslash only escapes wildcards and itself. Other backslashes
+// (e.g. in paths) are literal.
+func wildcardToRegex(value string) string {
+	result := &strings.Builder{}
+	runes := []rune(value)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case c == '\\' && i+1 < len(runes) &&
+			(runes[i+1] == '*' || runes[i+1] == '?' || runes[i+1] == '\\'):
+			i++
+			result.WriteString(regexp.QuoteMeta(string(runes[i])))
+
+		case c == '*':
+			result.WriteString(".*")
+
+		case c == '?':
+			result.WriteString(".")
+
+		default:
+			result.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return result.String()
+}
+
+// VQL triple quoted strings are not unescaped so the regex is passed
+// unchanged. Quotes are escaped in the regex itself.
+func quoteRegex(regex string) string {
+	return "'''" + strings.Replace(regex, "'", `\x27`, -1) + "'''"
+}
+
+func joinTerms(terms []string, operator string) string {
+	switch len(terms) {
+	case 0:
+		// Nothing matches an empty list of alternatives.
+		if operator == " OR " {
+			return "FALSE"
+		}
+		return "TRUE"
+	case 1:
+		return terms[0]
+	}
+	return "(" + strings.Join(terms, operator) + ")"
+}
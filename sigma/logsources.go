@@ -0,0 +1,85 @@
+package sigma
+
+import (
+	"fmt"
+)
+
+const (
+	EVTX_ROOT = "C:/Windows/System32/winevt/Logs/"
+
+	SYSMON_LOG     = "Microsoft-Windows-Sysmon%4Operational.evtx"
+	POWERSHELL_LOG = "Microsoft-Windows-PowerShell%4Operational.evtx"
+)
+
+type logSource struct {
+	path string
+
+	// Only these events are considered.
+	event_ids []int
+}
+
+// Event logs by Sigma service.
+var serviceLogs = map[string]string{
+	"security":           "Security.evtx",
+	"system":             "System.evtx",
+	"application":        "Application.evtx",
+	"sysmon":             SYSMON_LOG,
+	"powershell":         POWERSHELL_LOG,
+	"powershell-classic": "Windows PowerShell.evtx",
+	"taskscheduler":      "Microsoft-Windows-TaskScheduler%4Operational.evtx",
+	"windefend":          "Microsoft-Windows-Windows Defender%4Operational.evtx",
+	"bits-client":        "Microsoft-Windows-Bits-Client%4Operational.evtx",
+}
+
+// Sigma categories are generic but on Windows they are usually
+// collected with Sysmon.
+var categorySources = map[string]logSource{
+	"process_creation":     {SYSMON_LOG, []int{1}},
+	"network_connection":   {SYSMON_LOG, []int{3}},
+	"driver_load":          {SYSMON_LOG, []int{6}},
+	"image_load":           {SYSMON_LOG, []int{7}},
+	"create_remote_thread": {SYSMON_LOG, []int{8}},
+	"process_access":       {SYSMON_LOG, []int{10}},
+	"file_event":           {SYSMON_LOG, []int{11}},
+	"registry_event":       {SYSMON_LOG, []int{12, 13, 14}},
+	"registry_add":         {SYSMON_LOG, []int{12}},
+	"registry_set":         {SYSMON_LOG, []int{13}},
+	"dns_query":            {SYSMON_LOG, []int{22}},
+	"file_delete":          {SYSMON_LOG, []int{23}},
+	"ps_script":            {POWERSHELL_LOG, []int{4104}},
+	"ps_module":            {POWERSHELL_LOG, []int{4103}},
+}
+
+func (self *translator) getLogSource(source LogSource) (*logSource, error) {
+	if source.Product != "windows" {
+		return nil, fmt.Errorf("Unsupported product %q: only windows rules are supported",
+			source.Product)
+	}
+
+	if source.Service != "" {
+		path, pres := serviceLogs[source.Service]
+		if !pres {
+			return nil, fmt.Errorf("Unsupported service %q", source.Service)
+		}
+		if source.Category != "" {
+			self.warn("Category %q is ignored for service %q",
+				source.Category, source.Service)
+		}
+		return &logSource{path: EVTX_ROOT + path}, nil
+	}
+
+	category, pres := categorySources[source.Category]
+	if !pres {
+		return nil, fmt.Errorf("Unsupported category %q", source.Category)
+	}
+
+	if category.path == SYSMON_LOG {
+		self.warn("Category %q is translated to Sysmon events which requires Sysmon on the endpoint",
+			source.Category)
+	}
+
+	return &logSource{
+		path:      EVTX_ROOT + category.path,
+		event_ids: category.event_ids,
+	}, nil
+}
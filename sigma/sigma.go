@@ -0,0 +1,223 @@
+// Translate Sigma rules (https://github.com/SigmaHQ/sigma) into
+// Velociraptor client monitoring artifacts.
+//
+// Only rules for Windows event logs are supported. Each rule becomes
+// a CLIENT_EVENT artifact which watches the relevant event log and
+// emits the events matching the rule's detection. Anything in a rule
+// which can not be translated exactly is either reported as a
+// warning (when the artifact still detects what the rule describes)
+// or makes the translation fail.
+
+package sigma
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Velocidex/yaml/v2"
+)
+
+const (
+	DEFAULT_PREFIX = "Custom.Sigma."
+)
+
+var (
+	documentSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+	nonNameRegex      = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+	// Sigma tags techniques as e.g. attack.t1059.001
+	attackTagRegex = regexp.MustCompile(`^attack\.(t[0-9]{4}(\.[0-9]{3})?)$`)
+)
+
+type LogSource struct {
+	Category string `yaml:"category"`
+	Product  string `yaml:"product"`
+	Service  string `yaml:"service"`
+}
+
+type Rule struct {
+	Title          string        `yaml:"title"`
+	Id             string        `yaml:"id"`
+	Status         string        `yaml:"status"`
+	Description    string        `yaml:"description"`
+	Author         string        `yaml:"author"`
+	References     []string      `yaml:"references"`
+	Tags           []string      `yaml:"tags"`
+	Level          string        `yaml:"level"`
+	LogSource      LogSource     `yaml:"logsource"`
+	Detection      yaml.MapSlice `yaml:"detection"`
+	FalsePositives []string      `yaml:"falsepositives"`
+}
+
+// The result of translating a single rule.
+type Translation struct {
+	Title string
+	Id    string
+
+	// The generated artifact's name and definition. Empty if the
+	// rule could not be translated.
+	Name     string
+	Artifact string
+
+	// Parts of the rule which were approximated.
+	Warnings []string
+	Error    string
+}
+
+// Translate every rule in a pack. A pack is a single YAML file with
+// one rule per document. Rules which fail do not stop the others.
+func TranslatePack(pack, prefix string) []*Translation {
+	result := []*Translation{}
+	for _, document := range documentSeparator.Split(pack, -1) {
+		if strings.TrimSpace(document) == "" {
+			continue
+		}
+		result = append(result, Translate(document, prefix))
+	}
+	return result
+}
+
+func Translate(document, prefix string) *Translation {
+	if prefix == "" {
+		prefix = DEFAULT_PREFIX
+	}
+
+	result := &Translation{}
+
+	rule := &Rule{}
+	err := yaml.Unmarshal([]byte(document), rule)
+	if err != nil {
+		result.Error = fmt.Sprintf("Invalid rule: %v", err)
+		return result
+	}
+
+	result.Title = rule.Title
+	result.Id = rule.Id
+
+	translator := &translator{}
+	artifact, err := translator.translate(rule, prefix)
+	result.Warnings = translator.warnings
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Name = artifactName(rule, prefix)
+	result.Artifact = artifact
+	return result
+}
+
+type translator struct {
+	warnings []string
+}
+
+func (self *translator) warn(format string, args ...interface{}) {
+	self.warnings = append(self.warnings, fmt.Sprintf(format, args...))
+}
+
+func (self *translator) translate(rule *Rule, prefix string) (string, error) {
+	if rule.Title == "" {
+		return "", errors.New("Rule has no title")
+	}
+
+	source, err := self.getLogSource(rule.LogSource)
+	if err != nil {
+		return "", err
+	}
+
+	where, err := self.translateDetection(rule.Detection)
+	if err != nil {
+		return "", err
+	}
+
+	switch len(source.event_ids) {
+	case 0:
+	case 1:
+		where = fmt.Sprintf("System.EventID.Value = %v\n        AND %s",
+			source.event_ids[0], where)
+	default:
+		ids := []string{}
+		for _, id := range source.event_ids {
+			ids = append(ids, fmt.Sprintf("%v", id))
+		}
+		where = fmt.Sprintf("System.EventID.Value IN (%s)\n        AND %s",
+			strings.Join(ids, ", "), where)
+	}
+
+	return formatArtifact(rule, artifactName(rule, prefix),
+		source.path, where), nil
+}
+
+// Artifact names are made from the rule's title.
+func artifactName(rule *Rule, prefix string) string {
+	name := ""
+	for _, word := range nonNameRegex.Split(rule.Title, -1) {
+		if word == "" {
+			continue
+		}
+		name += strings.ToUpper(word[:1]) + word[1:]
+	}
+	return prefix + name
+}
+
+func formatArtifact(rule *Rule, name, path, where string) string {
+	description := strings.TrimSpace(rule.Title + "\n\n" + rule.Description)
+	if rule.Id != "" {
+		description += "\n\nTranslated from Sigma rule " + rule.Id
+	}
+	if rule.Level != "" {
+		description += "\n\nLevel: " + rule.Level
+	}
+	for _, reference := range rule.References {
+		description += "\n\n" + reference
+	}
+
+	result := &strings.Builder{}
+	fmt.Fprintf(result, "name: %s\n", name)
+	fmt.Fprintf(result, "description: |\n%s\n", indent(description, "  "))
+
+	techniques := attackTechniques(rule.Tags)
+	if len(techniques) > 0 {
+		fmt.Fprintf(result, "mitre_attack:\n")
+		for _, technique := range techniques {
+			fmt.Fprintf(result, "  - %s\n", technique)
+		}
+		fmt.Fprintf(result, "\n")
+	}
+
+	fmt.Fprintf(result, "type: CLIENT_EVENT\n\n")
+	fmt.Fprintf(result, "parameters:\n")
+	fmt.Fprintf(result, "  - name: EvtxPath\n")
+	fmt.Fprintf(result, "    default: %s\n\n", path)
+	fmt.Fprintf(result, "sources:\n")
+	fmt.Fprintf(result, "  - precondition:\n")
+	fmt.Fprintf(result, "      SELECT OS FROM info() WHERE OS = 'windows'\n")
+	fmt.Fprintf(result, "    query: |\n")
+	fmt.Fprintf(result, "      SELECT * FROM watch_evtx(filename=EvtxPath)\n")
+	fmt.Fprintf(result, "      WHERE %s\n", where)
+
+	return result.String()
+}
+
+func attackTechniques(tags []string) []string {
+	result := []string{}
+	for _, tag := range tags {
+		match := attackTagRegex.FindStringSubmatch(strings.ToLower(tag))
+		if match != nil {
+			result = append(result, strings.ToUpper(match[1]))
+		}
+	}
+	return result
+}
+
+func indent(text, prefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
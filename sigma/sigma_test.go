@@ -0,0 +1,88 @@
+package sigma
+
+import (
+	"strings"
+	"testing"
+
+	"www.velocidex.com/golang/velociraptor/vtesting/assert"
+)
+
+const testRule = `
+title: Suspicious Encoded PowerShell
+id: 8a2ef4d4-2a6b-4c43-8f0c-0f7c3f8e1d2a
+level: high
+tags:
+  - attack.execution
+  - attack.t1059.001
+logsource:
+  category: process_creation
+  product: windows
+detection:
+  selection:
+    Image|endswith: '\powershell.exe'
+    CommandLine|contains:
+      - ' -enc '
+      - ' -EncodedCommand '
+  filter:
+    ParentImage: 'C:\Windows\System32\\*'
+  condition: selection and not filter
+`
+
+func TestTranslate(t *testing.T) {
+	result := Translate(testRule, "")
+	assert.Equal(t, "", result.Error)
+	assert.Equal(t, "Custom.Sigma.SuspiciousEncodedPowerShell", result.Name)
+	assert.Equal(t, 1, len(result.Warnings))
+	assert.Regexp(t, "mitre_attack:\n  - T1059.001\n", result.Artifact)
+	assert.Regexp(t, `watch_evtx\(filename=EvtxPath\)`, result.Artifact)
+	assert.Regexp(t, "Microsoft-Windows-Sysmon%4Operational.evtx",
+		result.Artifact)
+
+	expected := "System.EventID.Value = 1\n        AND (" +
+		`(EventData.Image =~ '''(?i)\\powershell\.exe$''' AND ` +
+		`(EventData.CommandLine =~ '''(?i) -enc ''' OR ` +
+		`EventData.CommandLine =~ '''(?i) -EncodedCommand ''')) AND ` +
+		`NOT (EventData.ParentImage =~ '''(?i)^C:\\Windows\\System32\\.*$'''))`
+	assert.True(t, strings.Contains(result.Artifact, expected), result.Artifact)
+}
+
+func TestTranslateConditions(t *testing.T) {
+	searches := &searchSet{
+		names: []string{"sel1", "sel2", "other"},
+		vql:   map[string]string{"sel1": "A", "sel2": "B", "other": "C"},
+	}
+
+	for _, test := range []struct{ condition, vql string }{
+		{"1 of sel*", "(A OR B)"},
+		{"all of them", "(A AND B AND C)"},
+		{"sel1 or sel2 and not other", "(A OR (B AND NOT (C)))"},
+		{"(sel1 or sel2) and other", "((A OR B) AND C)"},
+	} {
+		vql, err := parseCondition(test.condition, searches)
+		assert.NoError(t, err)
+		assert.Equal(t, test.vql, vql)
+	}
+
+	_, err := parseCondition("sel1 | count() > 5", searches)
+	assert.Error(t, err)
+
+	_, err = parseCondition("sel3", searches)
+	assert.Error(t, err)
+}
+
+func TestTranslatePack(t *testing.T) {
+	pack := testRule + "\n---\n" + `
+title: Linux rule
+logsource:
+  product: linux
+detection:
+  sel:
+    foo: bar
+  condition: sel
+`
+	results := TranslatePack(pack, "Custom.Test.")
+	assert.Equal(t, 2, len(results))
+	assert.Equal(t, "Custom.Test.SuspiciousEncodedPowerShell", results[0].Name)
+	assert.Regexp(t, "Unsupported product", results[1].Error)
+	assert.Equal(t, "", results[1].Artifact)
+}
@@ -20,6 +20,10 @@ type UploadResponse struct {
 	Md5        string `json:"md5,omitempty"`
 	StoredName string `json:"StoredName,omitempty"`
 	Reference  string `json:"Reference,omitempty"`
+
+	// Set when the file was read with a different accessor than
+	// requested (e.g. "ntfs" when the file was locked).
+	Accessor string `json:"Accessor,omitempty"`
 }
 
 // Provide an uploader capable of uploading any reader object.
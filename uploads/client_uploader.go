@@ -230,12 +230,16 @@ func (self *VelociraptorUploader) maybeUploadSparse(
 	}
 
 	// Send each range separately
-	for _, rng := range ranges {
+	for idx, rng := range ranges {
 		// Ignore sparse ranges
 		if rng.IsSparse {
 			continue
 		}
 
+		// Hash each range separately so it can be verified on its
+		// own.
+		range_sum := sha256.New()
+
 		// Range is not sparse - send it one buffer at the time.
 		to_read := rng.Length
 		read_offset = rng.Offset
@@ -277,6 +281,11 @@ func (self *VelociraptorUploader) maybeUploadSparse(
 				return nil, err
 			}
 
+			_, err = range_sum.Write(data)
+			if err != nil {
+				return nil, err
+			}
+
 			packet := &actions_proto.FileBuffer{
 				Pathspec: &actions_proto.PathSpec{
 					Path:     store_as_name,
@@ -305,6 +314,8 @@ func (self *VelociraptorUploader) maybeUploadSparse(
 			write_offset += int64(read_bytes)
 			read_offset += int64(read_bytes)
 		}
+
+		index.Ranges[idx].Sha256 = hex.EncodeToString(range_sum.Sum(nil))
 	}
 
 	// We did a sparse file, upload the index as well.
@@ -189,11 +189,12 @@ func (self *FileBasedUploader) maybeCollectSparseFile(
 			file_length = 0
 		}
 
-		index = append(index, ordereddict.NewDict().
+		index_entry := ordereddict.NewDict().
 			Set("file_offset", count).
 			Set("original_offset", rng.Offset).
 			Set("file_length", file_length).
-			Set("length", rng.Length))
+			Set("length", rng.Length)
+		index = append(index, index_entry)
 
 		if rng.IsSparse {
 			is_sparse = true
@@ -205,13 +206,18 @@ func (self *FileBasedUploader) maybeCollectSparseFile(
 			return nil, err
 		}
 
-		n, err := utils.CopyN(ctx, utils.NewTee(writer, sha_sum, md5_sum),
+		range_sum := sha256.New()
+		n, err := utils.CopyN(ctx,
+			utils.NewTee(writer, sha_sum, md5_sum, range_sum),
 			range_reader, rng.Length)
 		if err != nil {
 			return &UploadResponse{
 				Error: err.Error(),
 			}, err
 		}
+
+		// Each run is hashed so it can be verified on its own.
+		index_entry.Set("sha256", hex.EncodeToString(range_sum.Sum(nil)))
 		count += n
 	}
 
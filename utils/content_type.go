@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+)
+
+// Content type detection for uploaded files. We only ever see the
+// first chunk of the file so detection is based on magic bytes at
+// the start of the file. The standard library detects most document
+// and media types but does not know about executables, which are
+// the most interesting for DFIR so we check for those first.
+
+const (
+	CONTENT_TYPE_PE    = "application/vnd.microsoft.portable-executable"
+	CONTENT_TYPE_ELF   = "application/x-elf"
+	CONTENT_TYPE_MACHO = "application/x-mach-binary"
+)
+
+type magicSignature struct {
+	offset       int
+	magic        []byte
+	content_type string
+}
+
+var (
+	signatures = []magicSignature{
+		{0, []byte("\x7fELF"), CONTENT_TYPE_ELF},
+		{0, []byte("\xfe\xed\xfa\xce"), CONTENT_TYPE_MACHO},
+		{0, []byte("\xfe\xed\xfa\xcf"), CONTENT_TYPE_MACHO},
+		{0, []byte("\xce\xfa\xed\xfe"), CONTENT_TYPE_MACHO},
+		{0, []byte("\xcf\xfa\xed\xfe"), CONTENT_TYPE_MACHO},
+		{0, []byte("regf"), "application/x-ms-registry"},
+		{0, []byte("ElfFile\x00"), "application/x-ms-evtx"},
+		{0, []byte("SQLite format 3\x00"), "application/vnd.sqlite3"},
+		{0, []byte("7z\xbc\xaf\x27\x1c"), "application/x-7z-compressed"},
+		{0, []byte("MSCF"), "application/vnd.ms-cab-compressed"},
+		{0, []byte("\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1"), "application/x-ole-storage"},
+		{4, []byte("SCCA"), "application/x-ms-prefetch"},
+		{0, []byte("MAM\x04"), "application/x-ms-prefetch"},
+		{0, []byte("L\x00\x00\x00\x01\x14\x02\x00"), "application/x-ms-shortcut"},
+	}
+
+	// Short names users can search for instead of the full MIME
+	// type.
+	content_type_aliases = map[string]string{
+		"pe":       CONTENT_TYPE_PE,
+		"exe":      CONTENT_TYPE_PE,
+		"elf":      CONTENT_TYPE_ELF,
+		"macho":    CONTENT_TYPE_MACHO,
+		"pdf":      "application/pdf",
+		"zip":      "application/zip",
+		"registry": "application/x-ms-registry",
+		"evtx":     "application/x-ms-evtx",
+		"sqlite":   "application/vnd.sqlite3",
+		"prefetch": "application/x-ms-prefetch",
+		"lnk":      "application/x-ms-shortcut",
+		"ole":      "application/x-ole-storage",
+		"text":     "text/plain",
+	}
+)
+
+// Detect the content type of a file from its first few bytes.
+func DetectContentType(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+
+	if isPE(data) {
+		return CONTENT_TYPE_PE
+	}
+
+	for _, sig := range signatures {
+		if len(data) >= sig.offset+len(sig.magic) &&
+			bytes.Equal(data[sig.offset:sig.offset+len(sig.magic)], sig.magic) {
+			return sig.content_type
+		}
+	}
+
+	return http.DetectContentType(data)
+}
+
+// A PE file starts with an MZ header which points at the PE header.
+// Plain DOS executables do not have the PE header.
+func isPE(data []byte) bool {
+	if len(data) < 0x40 || data[0] != 'M' || data[1] != 'Z' {
+		return false
+	}
+
+	pe_offset := int(data[0x3c]) | int(data[0x3d])<<8 |
+		int(data[0x3e])<<16 | int(data[0x3f])<<24
+
+	// The PE header may lie beyond the first chunk in which case
+	// we trust the MZ header.
+	if pe_offset < 0 || pe_offset+4 > len(data) {
+		return true
+	}
+
+	return bytes.Equal(data[pe_offset:pe_offset+4], []byte("PE\x00\x00"))
+}
+
+// Check if the content type matches the type the user asked for. The
+// type may be an alias (e.g. "pe"), a full MIME type or a MIME type
+// prefix (e.g. "image/").
+func MatchContentType(content_type, file_type string) bool {
+	if file_type == "" {
+		return true
+	}
+
+	file_type = strings.ToLower(file_type)
+	alias, pres := content_type_aliases[file_type]
+	if pres {
+		file_type = alias
+	}
+
+	// Strip parameters like "; charset=utf-8"
+	content_type = strings.ToLower(
+		strings.TrimSpace(strings.Split(content_type, ";")[0]))
+
+	if strings.HasSuffix(file_type, "/") {
+		return strings.HasPrefix(content_type, file_type)
+	}
+	return content_type == file_type
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectContentType(t *testing.T) {
+	pe := make([]byte, 0x100)
+	copy(pe, "MZ")
+	pe[0x3c] = 0x80
+	copy(pe[0x80:], "PE\x00\x00")
+
+	for _, tc := range []struct {
+		data     []byte
+		expected string
+	}{
+		{pe, CONTENT_TYPE_PE},
+		{[]byte("\x7fELF\x02\x01\x01"), CONTENT_TYPE_ELF},
+		{[]byte("\xcf\xfa\xed\xfe\x07\x00"), CONTENT_TYPE_MACHO},
+		{[]byte("regf\x00\x00\x00\x00"), "application/x-ms-registry"},
+		{[]byte("%PDF-1.4\n"), "application/pdf"},
+		{[]byte("Hello world"), "text/plain; charset=utf-8"},
+		{nil, ""},
+	} {
+		assert.Equal(t, tc.expected, DetectContentType(tc.data))
+	}
+
+	// A DOS stub without a PE header is not a PE file.
+	copy(pe[0x80:], "XX")
+	assert.NotEqual(t, CONTENT_TYPE_PE, DetectContentType(pe))
+}
+
+func TestMatchContentType(t *testing.T) {
+	assert.True(t, MatchContentType(CONTENT_TYPE_PE, "pe"))
+	assert.True(t, MatchContentType(CONTENT_TYPE_PE, "PE"))
+	assert.True(t, MatchContentType("text/plain; charset=utf-8", "text"))
+	assert.True(t, MatchContentType("image/png", "image/"))
+	assert.True(t, MatchContentType("image/png", ""))
+	assert.False(t, MatchContentType("image/png", "pe"))
+	assert.False(t, MatchContentType("", "pe"))
+}
@@ -85,3 +85,8 @@ func CheckDirWritable(dirname string) error {
 func ExpandEnv(path string) string {
 	return os.ExpandEnv(path)
 }
+
+// Files are never locked against reading on Unix.
+func IsFileLocked(err error) bool {
+	return false
+}
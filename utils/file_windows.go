@@ -3,9 +3,11 @@
 package utils
 
 import (
+	"errors"
 	"io/ioutil"
 	"os"
 
+	"golang.org/x/sys/windows"
 	"golang.org/x/sys/windows/registry"
 )
 
@@ -26,3 +28,10 @@ func ExpandEnv(path string) string {
 
 	return expanded_path
 }
+
+// Is the error because another process has the file open without
+// sharing (e.g. registry hives and event logs in use)?
+func IsFileLocked(err error) bool {
+	return errors.Is(err, windows.ERROR_SHARING_VIOLATION) ||
+		errors.Is(err, windows.ERROR_LOCK_VIOLATION)
+}
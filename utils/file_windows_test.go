@@ -0,0 +1,41 @@
+// +build windows
+
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/windows"
+)
+
+func TestIsFileLocked(t *testing.T) {
+	fd, err := ioutil.TempFile("", "locked")
+	assert.NoError(t, err)
+	fd.Close()
+	defer os.Remove(fd.Name())
+
+	// Open the file without sharing it like the system does with
+	// registry hives.
+	name, err := windows.UTF16PtrFromString(fd.Name())
+	assert.NoError(t, err)
+
+	handle, err := windows.CreateFile(name, windows.GENERIC_READ,
+		0, nil, windows.OPEN_EXISTING, windows.FILE_ATTRIBUTE_NORMAL, 0)
+	assert.NoError(t, err)
+
+	_, err = os.Open(fd.Name())
+	assert.Error(t, err)
+	assert.True(t, IsFileLocked(err))
+
+	windows.CloseHandle(handle)
+
+	locked, err := os.Open(fd.Name())
+	assert.NoError(t, err)
+	locked.Close()
+
+	_, err = os.Open(fd.Name() + ".missing")
+	assert.False(t, IsFileLocked(err))
+}
@@ -0,0 +1,17 @@
+package utils
+
+// Calculate the slice bounds for a page of a list with length
+// items. Offsets past the end of the list produce an empty page and
+// a zero count means the rest of the list.
+func PageBounds(offset, count uint64, length int) (start, end int) {
+	if offset > uint64(length) {
+		offset = uint64(length)
+	}
+
+	end = length
+	if count > 0 && offset+count < uint64(length) {
+		end = int(offset + count)
+	}
+
+	return int(offset), end
+}
@@ -1,22 +1,26 @@
 package utils
 
 import (
+	"bytes"
 	"io"
+	"sync/atomic"
 )
 
 type TeeWriter struct {
 	writers []io.Writer
-	count   int
+
+	// Accessed atomically so progress can be read while writing.
+	count int64
 }
 
 func (self *TeeWriter) Count() int {
-	return self.count
+	return int(atomic.LoadInt64(&self.count))
 }
 
 func (self *TeeWriter) Write(p []byte) (n int, err error) {
 	for _, writer := range self.writers {
 		n, err = writer.Write(p)
-		self.count += n
+		atomic.AddInt64(&self.count, int64(n))
 		if err != nil && err != io.EOF {
 			return n, err
 		}
@@ -32,3 +36,30 @@ func NewTee(writers ...io.Writer) *TeeWriter {
 		writers: writers,
 	}
 }
+
+// A buffer which silently drops data after it is full so a noisy
+// writer (e.g. an external command) can not exhaust memory.
+type LimitedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (self *LimitedBuffer) Write(data []byte) (int, error) {
+	remaining := self.limit - self.buf.Len()
+	if remaining > 0 {
+		if len(data) > remaining {
+			self.buf.Write(data[:remaining])
+		} else {
+			self.buf.Write(data)
+		}
+	}
+	return len(data), nil
+}
+
+func (self *LimitedBuffer) String() string {
+	return self.buf.String()
+}
+
+func NewLimitedBuffer(limit int) *LimitedBuffer {
+	return &LimitedBuffer{limit: limit}
+}
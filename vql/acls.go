@@ -167,3 +167,17 @@ func GetPrincipal(scope vfilter.Scope) string {
 
 	return manager.principal
 }
+
+// Record a privileged operation (e.g. running a command on a remote
+// host) in the audit log. The audit log is only available on the
+// server.
+func AuditLog(scope vfilter.Scope, operation string, fields logrus.Fields) {
+	config_obj, ok := GetServerConfig(scope)
+	if !ok {
+		return
+	}
+
+	fields["user"] = GetPrincipal(scope)
+	logging.GetLogger(config_obj, &logging.Audit).
+		WithFields(fields).Info(operation)
+}
@@ -0,0 +1,35 @@
+package vql
+
+import (
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/constants"
+	"www.velocidex.com/golang/vfilter"
+)
+
+// Capabilities a client advertises to the server. Artifacts declare
+// the capabilities they need in required_capabilities.
+const (
+	CAPABILITY_RAW_DISK = "raw_disk"
+	CAPABILITY_MEMORY   = "memory"
+)
+
+// The capabilities of the running client. Raw disk and process
+// memory access need administrator privileges, and are never
+// advertised when the client is configured in low privilege mode.
+func GetCapabilities(scope vfilter.Scope) []string {
+	result := []string{}
+
+	if !IsAdmin() {
+		return result
+	}
+
+	scope_config, pres := scope.Resolve(constants.SCOPE_CONFIG)
+	if pres {
+		config, ok := scope_config.(*config_proto.ClientConfig)
+		if ok && config != nil && config.LowPrivilege {
+			return result
+		}
+	}
+
+	return append(result, CAPABILITY_RAW_DISK, CAPABILITY_MEMORY)
+}
@@ -0,0 +1,147 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	"www.velocidex.com/golang/velociraptor/accessors"
+	"www.velocidex.com/golang/velociraptor/artifacts"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	vfilter "www.velocidex.com/golang/vfilter"
+	"www.velocidex.com/golang/vfilter/arg_parser"
+)
+
+const (
+	// Skip regions larger than this unless the user asks for them.
+	DEFAULT_MAX_REGION_SIZE = 100 * 1024 * 1024
+)
+
+type ProcDumpRegionsPluginArgs struct {
+	Pid     uint64              `vfilter:"required,field=pid,doc=The pid to dump."`
+	Regions vfilter.StoredQuery `vfilter:"required,field=regions,doc=A query producing the regions to dump (rows with Address and Size columns, e.g. from vad())."`
+	MaxSize uint64              `vfilter:"optional,field=max_size,doc=Skip regions larger than this (default 100mb)."`
+}
+
+type ProcDumpRegionsPlugin struct{}
+
+func (self ProcDumpRegionsPlugin) Call(
+	ctx context.Context,
+	scope vfilter.Scope,
+	args *ordereddict.Dict) <-chan vfilter.Row {
+	output_chan := make(chan vfilter.Row)
+
+	go func() {
+		defer close(output_chan)
+
+		arg := &ProcDumpRegionsPluginArgs{}
+		err := arg_parser.ExtractArgsWithContext(ctx, scope, args, arg)
+		if err != nil {
+			scope.Log("proc_dump_regions: %v", err)
+			return
+		}
+
+		err = vql_subsystem.CheckFilesystemAccess(scope, "process")
+		if err != nil {
+			scope.Log("proc_dump_regions: %v", err)
+			return
+		}
+
+		uploader, ok := artifacts.GetUploader(scope)
+		if !ok {
+			scope.Log("proc_dump_regions: Uploader not configured.")
+			return
+		}
+
+		accessor, err := accessors.GetAccessor("process", scope)
+		if err != nil {
+			scope.Log("proc_dump_regions: %v", err)
+			return
+		}
+
+		filename, err := accessor.ParsePath(fmt.Sprintf("/%d", arg.Pid))
+		if err != nil {
+			scope.Log("proc_dump_regions: %v", err)
+			return
+		}
+
+		fd, err := accessor.OpenWithOSPath(filename)
+		if err != nil {
+			scope.Log("proc_dump_regions: pid %v: %v", arg.Pid, err)
+			return
+		}
+		defer fd.Close()
+
+		max_size := arg.MaxSize
+		if max_size == 0 {
+			max_size = DEFAULT_MAX_REGION_SIZE
+		}
+
+		// Do not dump the same region twice (e.g. when there are
+		// multiple hits in the same region).
+		seen := make(map[uint64]bool)
+
+		for region := range arg.Regions.Eval(ctx, scope) {
+			address := vql_subsystem.GetIntFromRow(scope, region, "Address")
+			size := vql_subsystem.GetIntFromRow(scope, region, "Size")
+			if size == 0 || seen[address] {
+				continue
+			}
+			seen[address] = true
+
+			if size > max_size {
+				scope.Log("proc_dump_regions: pid %v: Skipping region %#x "+
+					"of size %v (larger than max_size)", arg.Pid, address, size)
+				continue
+			}
+
+			_, err = fd.Seek(int64(address), os.SEEK_SET)
+			if err != nil {
+				scope.Log("proc_dump_regions: pid %v: %v", arg.Pid, err)
+				continue
+			}
+
+			name := fmt.Sprintf("%d/%#x-%#x.dmp", arg.Pid, address, address+size)
+			upload_response, err := uploader.Upload(
+				ctx, scope, filename, "process", name, int64(size),
+				time.Time{}, time.Time{}, time.Time{}, time.Time{},
+				io.LimitReader(fd, int64(size)))
+			if err != nil {
+				scope.Log("proc_dump_regions: pid %v: %v", arg.Pid, err)
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+
+			case output_chan <- ordereddict.NewDict().
+				Set("Pid", arg.Pid).
+				Set("Address", address).
+				Set("Size", size).
+				Set("Region", region).
+				Set("Upload", upload_response):
+			}
+		}
+	}()
+
+	return output_chan
+}
+
+func (self ProcDumpRegionsPlugin) Info(
+	scope vfilter.Scope,
+	type_map *vfilter.TypeMap) *vfilter.PluginInfo {
+	return &vfilter.PluginInfo{
+		Name: "proc_dump_regions",
+		Doc: "Upload selected memory regions of a process. Each " +
+			"region is uploaded as a separate file.",
+		ArgType: type_map.AddType(scope, &ProcDumpRegionsPluginArgs{}),
+	}
+}
+
+func init() {
+	vql_subsystem.RegisterPlugin(&ProcDumpRegionsPlugin{})
+}
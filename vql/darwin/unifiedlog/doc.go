@@ -0,0 +1,4 @@
+package unifiedlog
+
+// This package contains plugins to read the macOS unified log using
+// the OSLog framework. It is only available on macOS with cgo enabled.
@@ -0,0 +1,274 @@
+// +build darwin,cgo
+
+/*
+   Velociraptor - Hunting Evil
+   Copyright (C) 2019 Velocidex Innovations.
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package unifiedlog
+
+// #cgo LDFLAGS: -framework Foundation -framework OSLog
+//
+// #include <stdlib.h>
+// int read_unified_log(char *archive, char *predicate,
+//                      double start_time, double end_time,
+//                      void *context, char **error);
+import "C"
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+	"unsafe"
+
+	"github.com/Velocidex/ordereddict"
+	"github.com/mattn/go-pointer"
+	"www.velocidex.com/golang/velociraptor/acls"
+	"www.velocidex.com/golang/velociraptor/utils"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/vfilter"
+	"www.velocidex.com/golang/vfilter/arg_parser"
+)
+
+var (
+	// Maps OSLogEntryLogLevel
+	levelNames = map[int]string{
+		0: "Undefined",
+		1: "Debug",
+		2: "Info",
+		3: "Notice",
+		4: "Error",
+		5: "Fault",
+	}
+)
+
+type logContext struct {
+	ctx         context.Context
+	output_chan chan vfilter.Row
+
+	// The timestamp of the last entry emitted and how many entries
+	// were emitted with that timestamp. Since the store is
+	// positioned by date, when we poll again we see those entries
+	// again and need to skip them.
+	last float64
+	seen int
+	skip int
+}
+
+//export unified_log_entry
+func unified_log_entry(ptr unsafe.Pointer, timestamp C.double,
+	entry_type *C.char, level C.int,
+	process *C.char, sender *C.char, pid C.int,
+	thread_id C.ulonglong, activity_id C.ulonglong,
+	subsystem *C.char, category *C.char,
+	format *C.char, message *C.char) C.int {
+
+	defer utils.CheckForPanic("unified_log_entry")
+
+	self := pointer.Restore(ptr).(*logContext)
+
+	ts := float64(timestamp)
+	switch {
+	case ts < self.last:
+		return 0
+
+	case ts == self.last:
+		if self.skip > 0 {
+			self.skip--
+			return 0
+		}
+		self.seen++
+
+	default:
+		self.last = ts
+		self.seen = 1
+	}
+
+	sec, frac := math.Modf(ts)
+	row := ordereddict.NewDict().
+		Set("Time", time.Unix(int64(sec), int64(frac*1e9)).UTC()).
+		Set("Type", C.GoString(entry_type)).
+		Set("Level", levelNames[int(level)]).
+		Set("Process", C.GoString(process)).
+		Set("PID", int64(pid)).
+		Set("ThreadID", uint64(thread_id)).
+		Set("ActivityID", uint64(activity_id)).
+		Set("Sender", C.GoString(sender)).
+		Set("Subsystem", C.GoString(subsystem)).
+		Set("Category", C.GoString(category)).
+		Set("Format", C.GoString(format)).
+		Set("Message", C.GoString(message))
+
+	select {
+	case <-self.ctx.Done():
+		return 1
+	case self.output_chan <- row:
+		return 0
+	}
+}
+
+// Read the log entries after start_time. Entries already emitted at
+// the last timestamp are skipped.
+func readLog(self *logContext, archive, predicate string,
+	start_time, end_time float64) error {
+
+	c_archive := C.CString(archive)
+	defer C.free(unsafe.Pointer(c_archive))
+
+	c_predicate := C.CString(predicate)
+	defer C.free(unsafe.Pointer(c_predicate))
+
+	ptr := pointer.Save(self)
+	defer pointer.Unref(ptr)
+
+	self.skip = self.seen
+
+	var c_error *C.char
+	res := C.read_unified_log(c_archive, c_predicate,
+		C.double(start_time), C.double(end_time), ptr, &c_error)
+	if res != 0 {
+		defer C.free(unsafe.Pointer(c_error))
+		return errors.New(C.GoString(c_error))
+	}
+
+	return nil
+}
+
+func toEpoch(t time.Time) float64 {
+	if t.IsZero() {
+		return 0
+	}
+	return float64(t.UnixNano()) / 1e9
+}
+
+type _UnifiedLogPluginArgs struct {
+	Archive   string    `vfilter:"optional,field=archive,doc=Path to a .logarchive bundle to parse (default is the local system log)."`
+	Predicate string    `vfilter:"optional,field=predicate,doc=A predicate to filter entries (the same as log show --predicate)."`
+	StartTime time.Time `vfilter:"optional,field=start_time,doc=Only show entries after this time."`
+	EndTime   time.Time `vfilter:"optional,field=end_time,doc=Only show entries before this time."`
+}
+
+type _UnifiedLogPlugin struct{}
+
+func (self _UnifiedLogPlugin) Call(
+	ctx context.Context,
+	scope vfilter.Scope,
+	args *ordereddict.Dict) <-chan vfilter.Row {
+	output_chan := make(chan vfilter.Row)
+
+	go func() {
+		defer close(output_chan)
+
+		arg := &_UnifiedLogPluginArgs{}
+		err := arg_parser.ExtractArgsWithContext(ctx, scope, args, arg)
+		if err != nil {
+			scope.Log("parse_unified_log: %v", err)
+			return
+		}
+
+		err = vql_subsystem.CheckAccess(scope, acls.FILESYSTEM_READ)
+		if err != nil {
+			scope.Log("parse_unified_log: %v", err)
+			return
+		}
+
+		log_ctx := &logContext{ctx: ctx, output_chan: output_chan}
+		err = readLog(log_ctx, arg.Archive, arg.Predicate,
+			toEpoch(arg.StartTime), toEpoch(arg.EndTime))
+		if err != nil {
+			scope.Log("parse_unified_log: %v", err)
+		}
+	}()
+
+	return output_chan
+}
+
+func (self _UnifiedLogPlugin) Info(scope vfilter.Scope, type_map *vfilter.TypeMap) *vfilter.PluginInfo {
+	return &vfilter.PluginInfo{
+		Name: "parse_unified_log",
+		Doc: "Parse the macOS unified log from the local system or " +
+			"from a .logarchive bundle.",
+		ArgType: type_map.AddType(scope, &_UnifiedLogPluginArgs{}),
+	}
+}
+
+type _WatchUnifiedLogPluginArgs struct {
+	Predicate string `vfilter:"optional,field=predicate,doc=A predicate to filter entries (the same as log stream --predicate)."`
+}
+
+type _WatchUnifiedLogPlugin struct{}
+
+func (self _WatchUnifiedLogPlugin) Call(
+	ctx context.Context,
+	scope vfilter.Scope,
+	args *ordereddict.Dict) <-chan vfilter.Row {
+	output_chan := make(chan vfilter.Row)
+
+	go func() {
+		defer close(output_chan)
+
+		arg := &_WatchUnifiedLogPluginArgs{}
+		err := arg_parser.ExtractArgsWithContext(ctx, scope, args, arg)
+		if err != nil {
+			scope.Log("watch_unified_log: %v", err)
+			return
+		}
+
+		err = vql_subsystem.CheckAccess(scope, acls.FILESYSTEM_READ)
+		if err != nil {
+			scope.Log("watch_unified_log: %v", err)
+			return
+		}
+
+		// Start streaming from now.
+		log_ctx := &logContext{
+			ctx:         ctx,
+			output_chan: output_chan,
+			last:        toEpoch(time.Now()),
+		}
+
+		for {
+			err = readLog(log_ctx, "", arg.Predicate, log_ctx.last, 0)
+			if err != nil {
+				scope.Log("watch_unified_log: %v", err)
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-time.After(3 * time.Second):
+			}
+		}
+	}()
+
+	return output_chan
+}
+
+func (self _WatchUnifiedLogPlugin) Info(scope vfilter.Scope, type_map *vfilter.TypeMap) *vfilter.PluginInfo {
+	return &vfilter.PluginInfo{
+		Name:    "watch_unified_log",
+		Doc:     "Watch the macOS unified log and stream new entries.",
+		ArgType: type_map.AddType(scope, &_WatchUnifiedLogPluginArgs{}),
+	}
+}
+
+func init() {
+	vql_subsystem.RegisterPlugin(&_UnifiedLogPlugin{})
+	vql_subsystem.RegisterPlugin(&_WatchUnifiedLogPlugin{})
+}
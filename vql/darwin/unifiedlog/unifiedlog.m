@@ -0,0 +1,147 @@
+// +build darwin,cgo
+
+// Read the macOS unified log using the OSLog framework.
+// https://developer.apple.com/documentation/oslog/oslogstore
+
+#import <Foundation/Foundation.h>
+#import <OSLog/OSLog.h>
+
+#include <stdlib.h>
+#include <string.h>
+
+// The GO callback which will receive each entry. Returning non zero
+// stops the enumeration.
+int unified_log_entry(void *context, double timestamp,
+                      char *entry_type, int level,
+                      char *process, char *sender, int pid,
+                      unsigned long long thread_id,
+                      unsigned long long activity_id,
+                      char *subsystem, char *category,
+                      char *format, char *message);
+
+static char *to_c(NSString *str) {
+    if (str == nil) {
+        return "";
+    }
+    return (char *)[str UTF8String];
+}
+
+static char *copy_error(NSError *err, const char *fallback) {
+    if (err == nil) {
+        return strdup(fallback);
+    }
+    return strdup([[err localizedDescription] UTF8String]);
+}
+
+// Enumerate the log entries between start_time and end_time (seconds
+// since the epoch, 0 means unbounded) matching the predicate. If
+// archive is empty we read the local system store. On failure an error
+// message is allocated in *error which the caller must free.
+int read_unified_log(char *archive, char *predicate,
+                     double start_time, double end_time,
+                     void *context, char **error) {
+    if (@available(macOS 10.15, *)) {
+        @autoreleasepool {
+            NSError *err = nil;
+            OSLogStore *store = nil;
+
+            if (archive != NULL && *archive != 0) {
+                NSURL *url = [NSURL fileURLWithPath:
+                                  [NSString stringWithUTF8String:archive]];
+                store = [OSLogStore storeWithURL:url error:&err];
+            } else if (@available(macOS 12.0, *)) {
+                store = [OSLogStore localStoreAndReturnError:&err];
+            } else {
+                *error = strdup("Reading the local log store requires macOS 12");
+                return -1;
+            }
+
+            if (store == nil) {
+                *error = copy_error(err, "Unable to open log store");
+                return -1;
+            }
+
+            OSLogPosition *position = nil;
+            if (start_time > 0) {
+                position = [store positionWithDate:
+                                      [NSDate dateWithTimeIntervalSince1970:start_time]];
+            }
+
+            NSPredicate *filter = nil;
+            if (predicate != NULL && *predicate != 0) {
+                @try {
+                    filter = [NSPredicate predicateWithFormat:
+                                              [NSString stringWithUTF8String:predicate]];
+                } @catch (NSException *e) {
+                    *error = strdup([[e reason] UTF8String]);
+                    return -1;
+                }
+            }
+
+            OSLogEnumerator *entries = [store entriesEnumeratorWithOptions:0
+                                                                  position:position
+                                                                 predicate:filter
+                                                                     error:&err];
+            if (entries == nil) {
+                *error = copy_error(err, "Unable to enumerate log store");
+                return -1;
+            }
+
+            for (OSLogEntry *entry in entries) {
+                @autoreleasepool {
+                    double timestamp = [[entry date] timeIntervalSince1970];
+                    if (end_time > 0 && timestamp > end_time) {
+                        break;
+                    }
+
+                    char *entry_type = "";
+                    int level = 0;
+                    if ([entry isKindOfClass:[OSLogEntryLog class]]) {
+                        entry_type = "log";
+                        level = (int)[(OSLogEntryLog *)entry level];
+                    } else if ([entry isKindOfClass:[OSLogEntrySignpost class]]) {
+                        entry_type = "signpost";
+                    } else if ([entry isKindOfClass:[OSLogEntryActivity class]]) {
+                        entry_type = "activity";
+                    } else if ([entry isKindOfClass:[OSLogEntryBoundary class]]) {
+                        entry_type = "boundary";
+                    }
+
+                    char *process = "", *sender = "";
+                    int pid = 0;
+                    unsigned long long thread_id = 0, activity_id = 0;
+                    if ([entry conformsToProtocol:@protocol(OSLogEntryFromProcess)]) {
+                        OSLogEntry<OSLogEntryFromProcess> *from =
+                            (OSLogEntry<OSLogEntryFromProcess> *)entry;
+                        process = to_c([from process]);
+                        sender = to_c([from sender]);
+                        pid = (int)[from processIdentifier];
+                        thread_id = [from threadIdentifier];
+                        activity_id = [from activityIdentifier];
+                    }
+
+                    char *subsystem = "", *category = "", *format = "";
+                    if ([entry conformsToProtocol:@protocol(OSLogEntryWithPayload)]) {
+                        OSLogEntry<OSLogEntryWithPayload> *payload =
+                            (OSLogEntry<OSLogEntryWithPayload> *)entry;
+                        subsystem = to_c([payload subsystem]);
+                        category = to_c([payload category]);
+                        format = to_c([payload formatString]);
+                    }
+
+                    if (unified_log_entry(context, timestamp, entry_type, level,
+                                          process, sender, pid,
+                                          thread_id, activity_id,
+                                          subsystem, category, format,
+                                          to_c([entry composedMessage])) != 0) {
+                        break;
+                    }
+                }
+            }
+        }
+        return 0;
+    }
+
+    *error = strdup("The unified log requires macOS 10.15");
+    return -1;
+}
@@ -22,6 +22,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/Velocidex/ordereddict"
 	"github.com/pkg/errors"
@@ -41,6 +42,9 @@ type GlobPluginArgs struct {
 	DoNotFollowSymlinks bool              `vfilter:"optional,field=nosymlink,doc=If set we do not follow symlinks."`
 	RecursionCallback   string            `vfilter:"optional,field=recursion_callback,doc=A VQL function that determines if a directory should be recursed (e.g. \"x=>NOT x.Name =~ 'proc'\")."`
 	OneFilesystem       bool              `vfilter:"optional,field=one_filesystem,doc=If set we do not follow links to other filesystems."`
+	Exclude             []string          `vfilter:"optional,field=exclude,doc=One or more glob patterns of files and directories to skip. Matching directories are not descended into."`
+	Workers             int64             `vfilter:"optional,field=workers,doc=Number of directories to list in parallel (default 1). Results from parallel workers are not in breadth first order."`
+	ProgressInterval    int64             `vfilter:"optional,field=progress_interval,doc=If set, log progress every this many seconds."`
 }
 
 type GlobPlugin struct{}
@@ -103,6 +107,8 @@ func (self GlobPlugin) Call(
 		options := glob.GlobOptions{
 			DoNotFollowSymlinks: arg.DoNotFollowSymlinks,
 			OneFilesystem:       arg.OneFilesystem,
+			Workers:             int(arg.Workers),
+			ProgressInterval:    time.Duration(arg.ProgressInterval) * time.Second,
 		}
 
 		if arg.RecursionCallback != "" {
@@ -119,7 +125,7 @@ func (self GlobPlugin) Call(
 			}
 		}
 
-		globber := glob.NewGlobber().WithOptions(options)
+		globber := glob.NewGlobber()
 
 		// If root is not specified we try to find a common
 		// root from the globs.
@@ -168,6 +174,17 @@ func (self GlobPlugin) Call(
 			}
 		}
 
+		// Exclusions are relative to the same root as the globs.
+		for _, item := range glob.ExpandBraces(arg.Exclude) {
+			item_path, err := root.Parse(item)
+			if err != nil {
+				scope.Log("glob: %v", err)
+				return
+			}
+			options.Exclusions = append(options.Exclusions, item_path)
+		}
+		globber.WithOptions(options)
+
 		file_chan := globber.ExpandWithContext(
 			ctx, scope, config_obj, root, accessor)
 		for f := range file_chan {
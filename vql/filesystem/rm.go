@@ -6,6 +6,7 @@ import (
 
 	"github.com/Velocidex/ordereddict"
 	"www.velocidex.com/golang/velociraptor/acls"
+	"www.velocidex.com/golang/velociraptor/artifacts"
 	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
 	"www.velocidex.com/golang/vfilter"
 	"www.velocidex.com/golang/vfilter/arg_parser"
@@ -27,6 +28,13 @@ func (self *_RmFunction) Call(ctx context.Context,
 		return false
 	}
 
+	// Check the config if we are allowed to modify the endpoint.
+	config_obj, ok := artifacts.GetConfig(scope)
+	if ok && config_obj.PreventExecve {
+		scope.Log("rm: Not allowed to write by configuration.")
+		return false
+	}
+
 	arg := &_RmRequest{}
 	err = arg_parser.ExtractArgsWithContext(ctx, scope, args, arg)
 	if err != nil {
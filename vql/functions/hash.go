@@ -50,19 +50,136 @@ type HashResult struct {
 	sha1   hash.Hash
 	SHA256 string
 	sha256 hash.Hash
+	SSDEEP string
+	ssdeep *SSDeep
+
+	// Total number of bytes hashed.
+	size int64
+}
+
+// Prepare the hashers selected by hashselect. By default we
+// calculate MD5, SHA1 and SHA256.
+func newHashResult(hashselect []string) (*HashResult, error) {
+	if hashselect == nil {
+		return &HashResult{
+			md5:    md5.New(),
+			sha1:   sha1.New(),
+			sha256: sha256.New(),
+		}, nil
+	}
+
+	result := &HashResult{}
+	for _, hash_opt := range hashselect {
+		switch hash_opt {
+		case "sha256", "SHA256":
+			result.sha256 = sha256.New()
+		case "sha1", "SHA1":
+			result.sha1 = sha1.New()
+		case "md5", "MD5":
+			result.md5 = md5.New()
+		case "ssdeep", "SSDEEP":
+			result.ssdeep = NewSSDeep()
+		default:
+			return nil, fmt.Errorf(
+				"hashselect option %s not recognized (should be md5, sha1, sha256, ssdeep)",
+				hash_opt)
+		}
+	}
+	return result, nil
+}
+
+func (self *HashResult) Write(buf []byte) {
+	if self.md5 != nil {
+		_, _ = self.md5.Write(buf)
+	}
+
+	if self.sha1 != nil {
+		_, _ = self.sha1.Write(buf)
+	}
+
+	if self.sha256 != nil {
+		_, _ = self.sha256.Write(buf)
+	}
+
+	if self.ssdeep != nil {
+		_, _ = self.ssdeep.Write(buf)
+	}
+
+	self.size += int64(len(buf))
+}
+
+func (self *HashResult) finalize() {
+	if self.md5 != nil {
+		self.MD5 = fmt.Sprintf("%x", self.md5.Sum(nil))
+	}
+
+	if self.sha1 != nil {
+		self.SHA1 = fmt.Sprintf("%x", self.sha1.Sum(nil))
+	}
+
+	if self.sha256 != nil {
+		self.SHA256 = fmt.Sprintf("%x", self.sha256.Sum(nil))
+	}
+
+	if self.ssdeep != nil {
+		self.SSDEEP = self.ssdeep.Digest()
+	}
 }
 
 func (self *HashResult) ToDict() *ordereddict.Dict {
-	return ordereddict.NewDict().
+	result := ordereddict.NewDict().
 		Set("MD5", self.MD5).
 		Set("SHA1", self.SHA1).
 		Set("SHA256", self.SHA256)
+
+	// Fuzzy hashes are only reported when asked for.
+	if self.ssdeep != nil {
+		result.Set("SSDEEP", self.SSDEEP)
+	}
+	return result
+}
+
+// Read the file once, feeding each buffer to all the selected
+// hashers.
+func hashFile(ctx context.Context, scope vfilter.Scope,
+	fd io.Reader, result *HashResult) error {
+	cached_buffer := pool.Get().(*[]byte)
+	defer pool.Put(cached_buffer)
+
+	buf := *cached_buffer
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		default:
+			n, err := fd.Read(buf)
+			if n > 0 {
+				result.Write(buf[:n])
+			}
+
+			// We are done!
+			if n == 0 || err == io.EOF {
+				if n == 0 {
+					result.finalize()
+					return nil
+				}
+
+			} else if err != nil {
+				return err
+			}
+
+			// Charge an op for each buffer we read
+			scope.ChargeOp()
+		}
+	}
 }
 
 type HashFunctionArgs struct {
 	Path       *accessors.OSPath `vfilter:"required,field=path,doc=Path to open and hash."`
 	Accessor   string            `vfilter:"optional,field=accessor,doc=The accessor to use"`
-	HashSelect []string          `vfilter:"optional,field=hashselect,doc=The hash function to use (MD5,SHA1,SHA256)"`
+	HashSelect []string          `vfilter:"optional,field=hashselect,doc=The hash function to use (MD5,SHA1,SHA256,SSDEEP)"`
 }
 
 // HashFunction calculates a hash of a file. It may be expensive
@@ -79,11 +196,6 @@ func (self *HashFunction) Call(ctx context.Context,
 		return vfilter.Null{}
 	}
 
-	cached_buffer := pool.Get().(*[]byte)
-	defer pool.Put(cached_buffer)
-
-	buf := *cached_buffer
-
 	err = vql_subsystem.CheckFilesystemAccess(scope, arg.Accessor)
 	if err != nil {
 		scope.Log("hash: %s", err)
@@ -103,80 +215,21 @@ func (self *HashFunction) Call(ctx context.Context,
 	}
 	defer file.Close()
 
-	result := &HashResult{}
-
-	if arg.HashSelect == nil {
-		result = &HashResult{
-			md5:    md5.New(),
-			sha1:   sha1.New(),
-			sha256: sha256.New(),
-		}
-	} else {
-		for _, hash_opt := range arg.HashSelect {
-			switch hash_opt {
-			case "sha256", "SHA256":
-				result.sha256 = sha256.New()
-			case "sha1", "SHA1":
-				result.sha1 = sha1.New()
-			case "md5", "MD5":
-				result.md5 = md5.New()
-			default:
-				scope.Log("hashselect option %s not recognized (should be md5, sha1, sha256)",
-					hash_opt)
-				return vfilter.Null{}
-			}
-		}
+	result, err := newHashResult(arg.HashSelect)
+	if err != nil {
+		scope.Log("hash: %v", err)
+		return vfilter.Null{}
 	}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return vfilter.Null{}
-
-		default:
-			n, err := file.Read(buf)
-
-			// We are done!
-			if n == 0 || err == io.EOF {
-				if n == 0 {
-					if result.md5 != nil {
-						result.MD5 = fmt.Sprintf(
-							"%x", result.md5.Sum(nil))
-					}
-
-					if result.sha1 != nil {
-						result.SHA1 = fmt.Sprintf(
-							"%x", result.sha1.Sum(nil))
-					}
-
-					if result.sha256 != nil {
-						result.SHA256 = fmt.Sprintf(
-							"%x", result.sha256.Sum(nil))
-					}
-					return result.ToDict()
-				}
-
-			} else if err != nil {
-				scope.Log("hash: %v", err)
-				return vfilter.Null{}
-			}
-
-			if result.md5 != nil {
-				_, _ = result.md5.Write(buf[:n])
-			}
-
-			if result.sha1 != nil {
-				_, _ = result.sha1.Write(buf[:n])
-			}
-
-			if result.sha256 != nil {
-				_, _ = result.sha256.Write(buf[:n])
-			}
-
-			// Charge an op for each buffer we read
-			scope.ChargeOp()
+	err = hashFile(ctx, scope, file, result)
+	if err != nil {
+		if ctx.Err() == nil {
+			scope.Log("hash: %v", err)
 		}
+		return vfilter.Null{}
 	}
+
+	return result.ToDict()
 }
 
 func (self HashFunction) Info(scope vfilter.Scope, type_map *vfilter.TypeMap) *vfilter.FunctionInfo {
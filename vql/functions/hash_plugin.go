@@ -0,0 +1,139 @@
+/*
+   Velociraptor - Hunting Evil
+   Copyright (C) 2019 Velocidex Innovations.
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+package functions
+
+import (
+	"context"
+
+	"github.com/Velocidex/ordereddict"
+	"www.velocidex.com/golang/velociraptor/accessors"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/vfilter"
+	"www.velocidex.com/golang/vfilter/arg_parser"
+)
+
+type HashPluginArgs struct {
+	Paths      []string `vfilter:"required,field=path,doc=One or more paths to open and hash."`
+	Accessor   string   `vfilter:"optional,field=accessor,doc=The accessor to use"`
+	HashSelect []string `vfilter:"optional,field=hashselect,doc=The hash functions to use (MD5,SHA1,SHA256,SSDEEP)"`
+}
+
+// HashPlugin hashes many files, emitting a row per file. Each file is
+// read only once no matter how many hashes are selected.
+type HashPlugin struct{}
+
+func (self HashPlugin) Call(
+	ctx context.Context,
+	scope vfilter.Scope,
+	args *ordereddict.Dict) <-chan vfilter.Row {
+	output_chan := make(chan vfilter.Row)
+
+	go func() {
+		defer close(output_chan)
+
+		arg := &HashPluginArgs{}
+		err := arg_parser.ExtractArgsWithContext(ctx, scope, args, arg)
+		if err != nil {
+			scope.Log("hash: %v", err)
+			return
+		}
+
+		// Validate the selection before we open any files.
+		_, err = newHashResult(arg.HashSelect)
+		if err != nil {
+			scope.Log("hash: %v", err)
+			return
+		}
+
+		err = vql_subsystem.CheckFilesystemAccess(scope, arg.Accessor)
+		if err != nil {
+			scope.Log("hash: %v", err)
+			return
+		}
+
+		accessor, err := accessors.GetAccessor(arg.Accessor, scope)
+		if err != nil {
+			scope.Log("hash: %v", err)
+			return
+		}
+
+		for _, path := range arg.Paths {
+			row, err := self.hashPath(ctx, scope, accessor, path, arg.HashSelect)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				scope.Log("hash: %v: %v", path, err)
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case output_chan <- row:
+			}
+		}
+	}()
+
+	return output_chan
+}
+
+func (self HashPlugin) hashPath(
+	ctx context.Context, scope vfilter.Scope,
+	accessor accessors.FileSystemAccessor,
+	path string, hashselect []string) (*ordereddict.Dict, error) {
+	result, err := newHashResult(hashselect)
+	if err != nil {
+		return nil, err
+	}
+
+	os_path, err := accessor.ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fd, err := accessor.OpenWithOSPath(os_path)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	err = hashFile(ctx, scope, fd, result)
+	if err != nil {
+		return nil, err
+	}
+
+	row := ordereddict.NewDict().
+		Set("OSPath", os_path).
+		Set("Size", result.size)
+	row.MergeFrom(result.ToDict())
+
+	return row, nil
+}
+
+func (self HashPlugin) Info(scope vfilter.Scope, type_map *vfilter.TypeMap) *vfilter.PluginInfo {
+	return &vfilter.PluginInfo{
+		Name:    "hash",
+		Doc:     "Calculate the hashes of many files in a single pass.",
+		ArgType: type_map.AddType(scope, &HashPluginArgs{}),
+	}
+}
+
+func init() {
+	vql_subsystem.RegisterPlugin(&HashPlugin{})
+}
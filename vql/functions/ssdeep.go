@@ -0,0 +1,206 @@
+package functions
+
+import (
+	"fmt"
+	"strings"
+)
+
+// An implementation of the ssdeep context triggered piecewise hash
+// (https://ssdeep-project.github.io/ssdeep/). This is a port of the
+// streaming engine in ssdeep's fuzzy.c so digests are identical to
+// those produced by the ssdeep tool.
+
+const (
+	ssdeepRollingWindow  = 7
+	ssdeepMinBlocksize   = 3
+	ssdeepHashInit       = 0x27
+	ssdeepHashPrime      = 0x01000193
+	ssdeepSpamsumLength  = 64
+	ssdeepNumBlockhashes = 31
+
+	ssdeepB64 = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+)
+
+func ssdeepBlocksize(index int) uint32 {
+	return uint32(ssdeepMinBlocksize) << uint(index)
+}
+
+// The FNV hash - we only ever use the bottom 6 bits.
+func ssdeepSumHash(c byte, h byte) byte {
+	return byte((uint32(h)*ssdeepHashPrime ^ uint32(c)) & 0x3f)
+}
+
+type ssdeepRollState struct {
+	window     [ssdeepRollingWindow]byte
+	h1, h2, h3 uint32
+	n          int
+}
+
+func (self *ssdeepRollState) hash(c byte) {
+	self.h2 -= self.h1
+	self.h2 += ssdeepRollingWindow * uint32(c)
+
+	self.h1 += uint32(c)
+	self.h1 -= uint32(self.window[self.n])
+
+	self.window[self.n] = c
+	self.n++
+	if self.n == ssdeepRollingWindow {
+		self.n = 0
+	}
+
+	self.h3 <<= 5
+	self.h3 ^= uint32(c)
+}
+
+func (self *ssdeepRollState) sum() uint32 {
+	return self.h1 + self.h2 + self.h3
+}
+
+type ssdeepBlockhash struct {
+	digest     [ssdeepSpamsumLength]byte
+	dindex     int
+	halfdigest byte
+	h, halfh   byte
+}
+
+// Calculates the ssdeep digest of the data written to it.
+type SSDeep struct {
+	total_size uint64
+	bhend      int
+	bh         [ssdeepNumBlockhashes]ssdeepBlockhash
+	roll       ssdeepRollState
+
+	need_lasthash bool
+	lasth         byte
+}
+
+func NewSSDeep() *SSDeep {
+	result := &SSDeep{bhend: 1}
+	result.bh[0].h = ssdeepHashInit
+	result.bh[0].halfh = ssdeepHashInit
+	return result
+}
+
+func (self *SSDeep) tryForkBlockhash() {
+	obh := &self.bh[self.bhend-1]
+	if self.bhend < ssdeepNumBlockhashes {
+		nbh := &self.bh[self.bhend]
+		nbh.h = obh.h
+		nbh.halfh = obh.halfh
+		nbh.dindex = 0
+		nbh.halfdigest = 0
+		self.bhend++
+
+	} else if !self.need_lasthash {
+		self.need_lasthash = true
+		self.lasth = obh.h
+	}
+}
+
+func (self *SSDeep) Write(data []byte) (int, error) {
+	for _, c := range data {
+		self.step(c)
+	}
+	self.total_size += uint64(len(data))
+	return len(data), nil
+}
+
+func (self *SSDeep) step(c byte) {
+	self.roll.hash(c)
+	h := self.roll.sum()
+
+	for i := 0; i < self.bhend; i++ {
+		self.bh[i].h = ssdeepSumHash(c, self.bh[i].h)
+		self.bh[i].halfh = ssdeepSumHash(c, self.bh[i].halfh)
+	}
+	if self.need_lasthash {
+		self.lasth = ssdeepSumHash(c, self.lasth)
+	}
+
+	// Each block size triggers on a subset of the triggers of the
+	// next smaller one. Note that bhend may grow as we go.
+	for i := 0; i < self.bhend; i++ {
+		bs := ssdeepBlocksize(i)
+		if h%bs != bs-1 {
+			break
+		}
+
+		bh := &self.bh[i]
+		if bh.dindex == 0 {
+			self.tryForkBlockhash()
+		}
+
+		bh.digest[bh.dindex] = ssdeepB64[bh.h]
+		bh.halfdigest = ssdeepB64[bh.halfh]
+		if bh.dindex < ssdeepSpamsumLength-1 {
+			bh.dindex++
+			bh.h = ssdeepHashInit
+			if bh.dindex < ssdeepSpamsumLength/2 {
+				bh.halfh = ssdeepHashInit
+				bh.halfdigest = 0
+			}
+		}
+	}
+}
+
+// The digest in the usual blocksize:hash:hash form.
+func (self *SSDeep) Digest() string {
+	h := self.roll.sum()
+
+	// Initial blocksize guess.
+	bi := 0
+	for uint64(ssdeepBlocksize(bi))*ssdeepSpamsumLength < self.total_size {
+		bi++
+		if bi >= ssdeepNumBlockhashes {
+			return ""
+		}
+	}
+
+	// Adapt blocksize guess to actual digest length.
+	for bi >= self.bhend {
+		bi--
+	}
+	for bi > 0 && self.bh[bi].dindex < ssdeepSpamsumLength/2 {
+		bi--
+	}
+
+	result := &strings.Builder{}
+	fmt.Fprintf(result, "%d:", ssdeepBlocksize(bi))
+
+	bh := &self.bh[bi]
+	result.Write(bh.digest[:bh.dindex])
+	if h != 0 {
+		result.WriteByte(ssdeepB64[bh.h])
+	} else if bh.digest[bh.dindex] != 0 {
+		// The digest is full and the last character was
+		// overwritten since.
+		result.WriteByte(bh.digest[bh.dindex])
+	}
+	result.WriteByte(':')
+
+	if bi < self.bhend-1 {
+		// The second part is truncated to half the length.
+		bh := &self.bh[bi+1]
+		i := bh.dindex
+		if i > ssdeepSpamsumLength/2-1 {
+			i = ssdeepSpamsumLength/2 - 1
+		}
+		result.Write(bh.digest[:i])
+
+		if h != 0 {
+			result.WriteByte(ssdeepB64[bh.halfh])
+		} else if bh.halfdigest != 0 {
+			result.WriteByte(bh.halfdigest)
+		}
+
+	} else if h != 0 {
+		if bi == 0 {
+			result.WriteByte(ssdeepB64[self.bh[bi].h])
+		} else {
+			result.WriteByte(ssdeepB64[self.lasth])
+		}
+	}
+
+	return result.String()
+}
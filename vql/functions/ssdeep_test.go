@@ -0,0 +1,109 @@
+package functions
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"www.velocidex.com/golang/velociraptor/vtesting/assert"
+)
+
+// A simple (but slow) implementation which hashes the data once per
+// candidate blocksize. The streaming engine must produce the same
+// digests.
+func referenceSSDeep(data []byte) string {
+	bs := uint32(ssdeepMinBlocksize)
+	for uint64(bs)*ssdeepSpamsumLength < uint64(len(data)) {
+		bs *= 2
+	}
+
+	for {
+		first, ok := referenceSSDeepPart(data, bs, ssdeepSpamsumLength-1)
+		if !ok && bs > ssdeepMinBlocksize {
+			bs /= 2
+			continue
+		}
+		second, _ := referenceSSDeepPart(data, bs*2, ssdeepSpamsumLength/2-1)
+		return fmt.Sprintf("%d:%s:%s", bs, first, second)
+	}
+}
+
+// Returns the digest for the blocksize and whether enough chunks
+// were found for the blocksize to be used.
+func referenceSSDeepPart(data []byte, bs uint32, max_len int) (string, bool) {
+	roll := ssdeepRollState{}
+	h := byte(ssdeepHashInit)
+	result := []byte{}
+	var last byte
+
+	for _, c := range data {
+		h = ssdeepSumHash(c, h)
+		roll.hash(c)
+		if roll.sum()%bs == bs-1 {
+			if len(result) < max_len {
+				result = append(result, ssdeepB64[h])
+				h = ssdeepHashInit
+			} else {
+				last = ssdeepB64[h]
+			}
+		}
+	}
+
+	ok := len(result) >= ssdeepSpamsumLength/2
+	if roll.sum() != 0 {
+		result = append(result, ssdeepB64[h])
+	} else if last != 0 {
+		result = append(result, last)
+	}
+	return string(result), ok
+}
+
+func TestSSDeepEmpty(t *testing.T) {
+	assert.Equal(t, "3::", NewSSDeep().Digest())
+}
+
+func TestSSDeepReference(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	inputs := [][]byte{
+		[]byte("hello"),
+		[]byte(strings.Repeat("Velociraptor ", 1000)),
+
+		// The rolling hash is 0 after a run of zeros.
+		append([]byte("hello world"), make([]byte, 100)...),
+	}
+
+	for _, size := range []int{1, 7, 100, 1000, 5000, 20000, 100000, 300000} {
+		data := make([]byte, size)
+		rng.Read(data)
+		inputs = append(inputs, data)
+
+		// Low entropy data produces few chunks.
+		text := make([]byte, size)
+		for i := range text {
+			text[i] = "abcd \n"[rng.Intn(6)]
+		}
+		inputs = append(inputs, text)
+	}
+
+	for _, data := range inputs {
+		expected := referenceSSDeep(data)
+
+		hasher := NewSSDeep()
+		hasher.Write(data)
+		assert.Equal(t, expected, hasher.Digest(), "Size %v", len(data))
+
+		// Writing in arbitrary chunks gives the same result.
+		hasher = NewSSDeep()
+		for remaining := data; len(remaining) > 0; {
+			n := rng.Intn(5000) + 1
+			if n > len(remaining) {
+				n = len(remaining)
+			}
+			hasher.Write(remaining[:n])
+			remaining = remaining[n:]
+		}
+		assert.Equal(t, expected, hasher.Digest(), "Size %v", len(data))
+	}
+}
@@ -89,7 +89,8 @@ func init() {
 
 				item := getInfo(info).
 					Set("Fqdn", fqdn.Get()).
-					Set("Architecture", runtime.GOARCH)
+					Set("Architecture", runtime.GOARCH).
+					Set("Capabilities", GetCapabilities(scope))
 				result = append(result, item)
 
 				return result
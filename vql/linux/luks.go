@@ -0,0 +1,332 @@
+package linux
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Velocidex/ordereddict"
+	"www.velocidex.com/golang/velociraptor/acls"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/vfilter"
+	"www.velocidex.com/golang/vfilter/arg_parser"
+)
+
+// LUKS on disk formats are described in
+// https://gitlab.com/cryptsetup/cryptsetup/-/wikis/LUKS-standard/on-disk-format.pdf
+// https://gitlab.com/cryptsetup/LUKS2-docs
+
+const (
+	LUKS_KEY_ENABLED = 0x00AC71F3
+
+	LUKS2_BINARY_HEADER_SIZE = 4096
+
+	// Do not trust JSON areas larger than this.
+	LUKS2_MAX_JSON_SIZE = 4 * 1024 * 1024
+)
+
+var (
+	luksMagic = []byte("LUKS\xba\xbe")
+)
+
+type luks1KeySlot struct {
+	Active            uint32
+	Iterations        uint32
+	Salt              [32]byte
+	KeyMaterialOffset uint32
+	Stripes           uint32
+}
+
+type luks1Header struct {
+	Magic         [6]byte
+	Version       uint16
+	CipherName    [32]byte
+	CipherMode    [32]byte
+	HashSpec      [32]byte
+	PayloadOffset uint32
+	KeyBytes      uint32
+	MKDigest      [20]byte
+	MKDigestSalt  [32]byte
+	MKDigestIter  uint32
+	UUID          [40]byte
+	KeySlots      [8]luks1KeySlot
+}
+
+type luks2Header struct {
+	Magic       [6]byte
+	Version     uint16
+	HeaderSize  uint64
+	SequenceId  uint64
+	Label       [48]byte
+	ChecksumAlg [32]byte
+	Salt        [64]byte
+	UUID        [40]byte
+	Subsystem   [48]byte
+	HeaderOff   uint64
+}
+
+type luks2Metadata struct {
+	Keyslots map[string]struct {
+		Type    string `json:"type"`
+		KeySize int    `json:"key_size"`
+	} `json:"keyslots"`
+	Segments map[string]struct {
+		Type       string `json:"type"`
+		Encryption string `json:"encryption"`
+	} `json:"segments"`
+	Tokens map[string]struct {
+		Type string `json:"type"`
+	} `json:"tokens"`
+}
+
+func cString(b []byte) string {
+	return string(bytes.TrimRight(b, "\x00"))
+}
+
+// Parse the LUKS header at the start of the device. Returns an error
+// if the device is not a LUKS volume.
+func ParseLUKSHeader(reader io.ReaderAt) (*ordereddict.Dict, error) {
+	magic := make([]byte, 8)
+	_, err := reader.ReadAt(magic, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(magic[:6], luksMagic) {
+		return nil, errors.New("Not a LUKS volume")
+	}
+
+	switch binary.BigEndian.Uint16(magic[6:]) {
+	case 1:
+		return parseLUKS1(reader)
+	case 2:
+		return parseLUKS2(reader)
+	}
+
+	return nil, errors.New("Unsupported LUKS version")
+}
+
+func parseLUKS1(reader io.ReaderAt) (*ordereddict.Dict, error) {
+	header := &luks1Header{}
+	err := binary.Read(io.NewSectionReader(reader, 0, 1024),
+		binary.BigEndian, header)
+	if err != nil {
+		return nil, err
+	}
+
+	active := 0
+	for _, slot := range header.KeySlots {
+		if slot.Active == LUKS_KEY_ENABLED {
+			active++
+		}
+	}
+
+	return ordereddict.NewDict().
+		Set("Version", 1).
+		Set("UUID", cString(header.UUID[:])).
+		Set("Label", "").
+		Set("Cipher", cString(header.CipherName[:])+"-"+
+			cString(header.CipherMode[:])).
+		Set("KeySize", header.KeyBytes*8).
+		Set("Hash", cString(header.HashSpec[:])).
+		Set("ActiveKeySlots", active).
+		Set("Tokens", []string{}), nil
+}
+
+func parseLUKS2(reader io.ReaderAt) (*ordereddict.Dict, error) {
+	header := &luks2Header{}
+	err := binary.Read(io.NewSectionReader(reader, 0, 1024),
+		binary.BigEndian, header)
+	if err != nil {
+		return nil, err
+	}
+
+	if header.HeaderSize <= LUKS2_BINARY_HEADER_SIZE ||
+		header.HeaderSize-LUKS2_BINARY_HEADER_SIZE > LUKS2_MAX_JSON_SIZE {
+		return nil, errors.New("Invalid LUKS2 header size")
+	}
+
+	json_area := make([]byte, header.HeaderSize-LUKS2_BINARY_HEADER_SIZE)
+	n, err := reader.ReadAt(json_area, LUKS2_BINARY_HEADER_SIZE)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	json_area = bytes.TrimRight(json_area[:n], "\x00")
+
+	metadata := &luks2Metadata{}
+	err = json.Unmarshal(json_area, metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	segments := make([]string, 0, len(metadata.Segments))
+	for k := range metadata.Segments {
+		segments = append(segments, k)
+	}
+	sort.Strings(segments)
+
+	cipher := ""
+	for _, segment := range segments {
+		if metadata.Segments[segment].Type == "crypt" {
+			cipher = metadata.Segments[segment].Encryption
+			break
+		}
+	}
+
+	key_size := 0
+	for _, slot := range metadata.Keyslots {
+		key_size = slot.KeySize * 8
+		break
+	}
+
+	// Tokens show how the volume may be unlocked without a
+	// passphrase (e.g. systemd-tpm2 or systemd-fido2).
+	tokens := []string{}
+	for _, token := range metadata.Tokens {
+		tokens = append(tokens, token.Type)
+	}
+	sort.Strings(tokens)
+
+	return ordereddict.NewDict().
+		Set("Version", 2).
+		Set("UUID", cString(header.UUID[:])).
+		Set("Label", cString(header.Label[:])).
+		Set("Cipher", cipher).
+		Set("KeySize", key_size).
+		Set("Hash", cString(header.ChecksumAlg[:])).
+		Set("ActiveKeySlots", len(metadata.Keyslots)).
+		Set("Tokens", tokens), nil
+}
+
+// Find the device mapper devices which are open LUKS volumes. Returns
+// a map of the LUKS UUID (without dashes) to the mapped device.
+func getOpenLUKSMappings(sys_block string) map[string]string {
+	result := make(map[string]string)
+
+	uuids, _ := filepath.Glob(filepath.Join(sys_block, "dm-*", "dm", "uuid"))
+	for _, uuid_path := range uuids {
+		// The uuid looks like CRYPT-LUKS2-<uuid>-<name>
+		data, err := ioutil.ReadFile(uuid_path)
+		if err != nil {
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimSpace(string(data)), "-", 4)
+		if len(parts) < 3 || parts[0] != "CRYPT" ||
+			!strings.HasPrefix(parts[1], "LUKS") {
+			continue
+		}
+
+		name, err := ioutil.ReadFile(
+			filepath.Join(filepath.Dir(uuid_path), "name"))
+		if err != nil {
+			continue
+		}
+
+		result[strings.ToLower(parts[2])] = "/dev/mapper/" +
+			strings.TrimSpace(string(name))
+	}
+
+	return result
+}
+
+type LUKSPluginArgs struct {
+	Devices []string `vfilter:"optional,field=devices,doc=A list of devices to check (default all block devices)."`
+}
+
+type LUKSPlugin struct{}
+
+func (self LUKSPlugin) Call(
+	ctx context.Context,
+	scope vfilter.Scope,
+	args *ordereddict.Dict) <-chan vfilter.Row {
+	output_chan := make(chan vfilter.Row)
+
+	go func() {
+		defer close(output_chan)
+
+		err := vql_subsystem.CheckAccess(scope, acls.MACHINE_STATE)
+		if err != nil {
+			scope.Log("luks_volumes: %s", err)
+			return
+		}
+
+		arg := &LUKSPluginArgs{}
+		err = arg_parser.ExtractArgsWithContext(ctx, scope, args, arg)
+		if err != nil {
+			scope.Log("luks_volumes: %s", err)
+			return
+		}
+
+		// We read the raw devices.
+		err = vql_subsystem.CheckAccess(scope, acls.FILESYSTEM_READ)
+		if err != nil {
+			scope.Log("luks_volumes: %s", err)
+			return
+		}
+
+		devices := arg.Devices
+		if len(devices) == 0 {
+			names, _ := ioutil.ReadDir("/sys/class/block")
+			for _, name := range names {
+				devices = append(devices, "/dev/"+name.Name())
+			}
+		}
+
+		mappings := getOpenLUKSMappings("/sys/block")
+
+		for _, device := range devices {
+			row, err := readLUKSDevice(device)
+			if err != nil {
+				continue
+			}
+
+			uuid, _ := row.GetString("UUID")
+			mapped_device, pres := mappings[strings.ToLower(
+				strings.ReplaceAll(uuid, "-", ""))]
+
+			result := ordereddict.NewDict().Set("Device", device)
+			result.MergeFrom(row)
+			result.Set("Unlocked", pres).
+				Set("MappedDevice", mapped_device)
+
+			select {
+			case <-ctx.Done():
+				return
+			case output_chan <- result:
+			}
+		}
+	}()
+
+	return output_chan
+}
+
+func readLUKSDevice(device string) (*ordereddict.Dict, error) {
+	fd, err := os.Open(device)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	return ParseLUKSHeader(fd)
+}
+
+func (self LUKSPlugin) Info(scope vfilter.Scope, type_map *vfilter.TypeMap) *vfilter.PluginInfo {
+	return &vfilter.PluginInfo{
+		Name:    "luks_volumes",
+		Doc:     "Report the LUKS encrypted volumes on the system.",
+		ArgType: type_map.AddType(scope, &LUKSPluginArgs{}),
+	}
+}
+
+func init() {
+	vql_subsystem.RegisterPlugin(&LUKSPlugin{})
+}
@@ -0,0 +1,119 @@
+package linux
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Velocidex/ordereddict"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testUUID = "3f4c1b2a-8d6e-4c1f-9a7b-5e2d0c9b8a71"
+
+func TestLUKS1Header(t *testing.T) {
+	header := luks1Header{
+		Version:  1,
+		KeyBytes: 64,
+	}
+	copy(header.Magic[:], luksMagic)
+	copy(header.CipherName[:], "aes")
+	copy(header.CipherMode[:], "xts-plain64")
+	copy(header.HashSpec[:], "sha256")
+	copy(header.UUID[:], testUUID)
+	header.KeySlots[0].Active = LUKS_KEY_ENABLED
+	header.KeySlots[3].Active = LUKS_KEY_ENABLED
+	header.KeySlots[4].Active = 0x0000DEAD
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, binary.Write(buf, binary.BigEndian, &header))
+
+	row, err := ParseLUKSHeader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, get(row, "Version"))
+	assert.Equal(t, testUUID, get(row, "UUID"))
+	assert.Equal(t, "aes-xts-plain64", get(row, "Cipher"))
+	assert.Equal(t, uint32(512), get(row, "KeySize"))
+	assert.Equal(t, "sha256", get(row, "Hash"))
+	assert.Equal(t, 2, get(row, "ActiveKeySlots"))
+}
+
+func TestLUKS2Header(t *testing.T) {
+	metadata := `{
+  "keyslots": {
+    "0": {"type": "luks2", "key_size": 64},
+    "1": {"type": "luks2", "key_size": 64}
+  },
+  "tokens": {
+    "0": {"type": "systemd-tpm2", "keyslots": ["1"]}
+  },
+  "segments": {
+    "0": {"type": "crypt", "offset": "16777216",
+          "encryption": "aes-xts-plain64", "sector_size": 512}
+  }
+}`
+	header := luks2Header{
+		Version:    2,
+		HeaderSize: 16384,
+	}
+	copy(header.Magic[:], luksMagic)
+	copy(header.Label[:], "data")
+	copy(header.ChecksumAlg[:], "sha256")
+	copy(header.UUID[:], testUUID)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, binary.Write(buf, binary.BigEndian, &header))
+	buf.Write(make([]byte, LUKS2_BINARY_HEADER_SIZE-buf.Len()))
+	buf.WriteString(metadata)
+	buf.Write(make([]byte, 16384-buf.Len()))
+
+	row, err := ParseLUKSHeader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, get(row, "Version"))
+	assert.Equal(t, testUUID, get(row, "UUID"))
+	assert.Equal(t, "data", get(row, "Label"))
+	assert.Equal(t, "aes-xts-plain64", get(row, "Cipher"))
+	assert.Equal(t, 512, get(row, "KeySize"))
+	assert.Equal(t, 2, get(row, "ActiveKeySlots"))
+	assert.Equal(t, []string{"systemd-tpm2"}, get(row, "Tokens"))
+}
+
+func TestNotLUKS(t *testing.T) {
+	_, err := ParseLUKSHeader(bytes.NewReader(make([]byte, 1024)))
+	assert.Error(t, err)
+}
+
+func TestOpenLUKSMappings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "luks")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	write := func(dm, uuid, name string) {
+		path := filepath.Join(dir, dm, "dm")
+		require.NoError(t, os.MkdirAll(path, 0700))
+		require.NoError(t, ioutil.WriteFile(
+			filepath.Join(path, "uuid"), []byte(uuid+"\n"), 0600))
+		require.NoError(t, ioutil.WriteFile(
+			filepath.Join(path, "name"), []byte(name+"\n"), 0600))
+	}
+
+	write("dm-0", "CRYPT-LUKS2-3f4c1b2a8d6e4c1f9a7b5e2d0c9b8a71-luks-3f4c1b2a",
+		"luks-3f4c1b2a")
+	write("dm-1", "LVM-abcdef", "vg-root")
+
+	mappings := getOpenLUKSMappings(dir)
+	assert.Equal(t, map[string]string{
+		"3f4c1b2a8d6e4c1f9a7b5e2d0c9b8a71": "/dev/mapper/luks-3f4c1b2a",
+	}, mappings)
+}
+
+func get(row *ordereddict.Dict, field string) interface{} {
+	value, _ := row.Get(field)
+	return value
+}
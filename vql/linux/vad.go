@@ -0,0 +1,72 @@
+// +build linux
+
+package linux
+
+import (
+	"context"
+
+	"github.com/Velocidex/ordereddict"
+	"www.velocidex.com/golang/velociraptor/accessors/process"
+	"www.velocidex.com/golang/velociraptor/acls"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/vfilter"
+	"www.velocidex.com/golang/vfilter/arg_parser"
+)
+
+type VADPluginArgs struct {
+	Pid uint64 `vfilter:"required,field=pid,doc=The PID to inspect."`
+}
+
+type VADPlugin struct{}
+
+func (self VADPlugin) Call(
+	ctx context.Context,
+	scope vfilter.Scope,
+	args *ordereddict.Dict) <-chan vfilter.Row {
+	output_chan := make(chan vfilter.Row)
+
+	go func() {
+		defer close(output_chan)
+
+		err := vql_subsystem.CheckAccess(scope, acls.MACHINE_STATE)
+		if err != nil {
+			scope.Log("vad: %s", err)
+			return
+		}
+
+		arg := &VADPluginArgs{}
+		err = arg_parser.ExtractArgsWithContext(ctx, scope, args, arg)
+		if err != nil {
+			scope.Log("vad: %s", err)
+			return
+		}
+
+		regions, err := process.GetMemoryRegions(arg.Pid)
+		if err != nil {
+			scope.Log("vad: %s", err)
+			return
+		}
+
+		for _, region := range regions {
+			select {
+			case <-ctx.Done():
+				return
+			case output_chan <- region:
+			}
+		}
+	}()
+
+	return output_chan
+}
+
+func (self VADPlugin) Info(scope vfilter.Scope, type_map *vfilter.TypeMap) *vfilter.PluginInfo {
+	return &vfilter.PluginInfo{
+		Name:    "vad",
+		Doc:     "Enumerate process memory regions.",
+		ArgType: type_map.AddType(scope, &VADPluginArgs{}),
+	}
+}
+
+func init() {
+	vql_subsystem.RegisterPlugin(&VADPlugin{})
+}
@@ -19,12 +19,14 @@ package networking
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/Velocidex/ordereddict"
 	"www.velocidex.com/golang/velociraptor/accessors"
 	"www.velocidex.com/golang/velociraptor/acls"
 	"www.velocidex.com/golang/velociraptor/artifacts"
 	"www.velocidex.com/golang/velociraptor/uploads"
+	"www.velocidex.com/golang/velociraptor/utils"
 	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
 	"www.velocidex.com/golang/velociraptor/vql/functions"
 	"www.velocidex.com/golang/vfilter"
@@ -81,7 +83,8 @@ func (self *UploadFunction) Call(ctx context.Context,
 		}
 	}
 
-	file, err := accessor.OpenWithOSPath(arg.File)
+	file, stat, used_accessor, err := openForUpload(
+		scope, arg.Accessor, accessor, arg.File)
 	if err != nil {
 		scope.Log("upload: Unable to open %s: %s",
 			arg.File, err.Error())
@@ -91,13 +94,6 @@ func (self *UploadFunction) Call(ctx context.Context,
 	}
 	defer file.Close()
 
-	stat, err := accessor.LstatWithOSPath(arg.File)
-	if err != nil {
-		scope.Log("upload: Unable to stat %s: %v",
-			arg.File, err)
-		return vfilter.Null{}
-	}
-
 	mtime, err := functions.TimeFromAny(scope, arg.Mtime)
 	if err != nil {
 		mtime = stat.ModTime()
@@ -119,9 +115,56 @@ func (self *UploadFunction) Call(ctx context.Context,
 			Error: err.Error(),
 		}
 	}
+	upload_response.Accessor = used_accessor
 	return upload_response
 }
 
+// Open the file and stat it. On Windows, files in use by the system
+// (e.g. NTUSER.DAT or event logs) can not be opened through the API
+// so we fall back to parsing the raw NTFS filesystem. In that case
+// we return the name of the accessor actually used.
+func openForUpload(scope vfilter.Scope,
+	accessor_name string, accessor accessors.FileSystemAccessor,
+	path *accessors.OSPath) (
+	accessors.ReadSeekCloser, accessors.FileInfo, string, error) {
+
+	file, err := accessor.OpenWithOSPath(path)
+	if err == nil {
+		stat, err := accessor.LstatWithOSPath(path)
+		if err != nil {
+			file.Close()
+			return nil, nil, "", err
+		}
+		return file, stat, "", nil
+	}
+
+	if accessor_name != "file" || !utils.IsFileLocked(err) {
+		return nil, nil, "", err
+	}
+
+	ntfs_accessor, err1 := accessors.GetAccessor("ntfs", scope)
+	if err1 != nil {
+		return nil, nil, "", err
+	}
+
+	ntfs_path := accessors.WindowsNTFSPathFromOSPath(path)
+	file, err1 = ntfs_accessor.OpenWithOSPath(ntfs_path)
+	if err1 != nil {
+		return nil, nil, "", fmt.Errorf(
+			"%v, unable to fall back to ntfs parsing: %w", err, err1)
+	}
+
+	stat, err1 := ntfs_accessor.LstatWithOSPath(ntfs_path)
+	if err1 != nil {
+		file.Close()
+		return nil, nil, "", err1
+	}
+
+	scope.Log("upload: %v is locked, reading it with the ntfs accessor",
+		path.String())
+	return file, stat, "ntfs", nil
+}
+
 func (self UploadFunction) Info(scope vfilter.Scope, type_map *vfilter.TypeMap) *vfilter.FunctionInfo {
 	return &vfilter.FunctionInfo{
 		Name: "upload",
@@ -187,7 +230,8 @@ func (self *UploadDirectoryFunction) Call(ctx context.Context,
 		}
 	}
 
-	file, err := accessor.OpenWithOSPath(arg.File)
+	file, stat, used_accessor, err := openForUpload(
+		scope, arg.Accessor, accessor, arg.File)
 	if err != nil {
 		scope.Log("upload_directory: Unable to open %s: %s",
 			arg.File.String(), err.Error())
@@ -197,13 +241,6 @@ func (self *UploadDirectoryFunction) Call(ctx context.Context,
 	}
 	defer file.Close()
 
-	stat, err := accessor.LstatWithOSPath(arg.File)
-	if err != nil {
-		scope.Log("upload_directory: Unable to stat %s: %v",
-			arg.File.String(), err)
-		return vfilter.Null{}
-	}
-
 	if stat.IsDir() {
 		return vfilter.Null{}
 	}
@@ -230,6 +267,7 @@ func (self *UploadDirectoryFunction) Call(ctx context.Context,
 			Error: err.Error(),
 		}
 	}
+	upload_response.Accessor = used_accessor
 	return upload_response
 }
 
@@ -0,0 +1,52 @@
+package networking
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/Velocidex/ordereddict"
+	"github.com/stretchr/testify/assert"
+	"www.velocidex.com/golang/velociraptor/accessors"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+
+	_ "www.velocidex.com/golang/velociraptor/accessors/file"
+)
+
+func TestOpenForUpload(t *testing.T) {
+	fd, err := ioutil.TempFile("", "upload")
+	assert.NoError(t, err)
+	_, err = fd.Write([]byte("hello"))
+	assert.NoError(t, err)
+	fd.Close()
+	defer os.Remove(fd.Name())
+
+	scope := vql_subsystem.MakeScope().AppendVars(ordereddict.NewDict().
+		Set(vql_subsystem.ACL_MANAGER_VAR, vql_subsystem.NullACLManager{}))
+	defer scope.Close()
+
+	accessor, err := accessors.GetAccessor("file", scope)
+	assert.NoError(t, err)
+
+	path, err := accessor.ParsePath(fd.Name())
+	assert.NoError(t, err)
+
+	// Files which are not locked are read with the requested
+	// accessor.
+	file, stat, used_accessor, err := openForUpload(
+		scope, "file", accessor, path)
+	assert.NoError(t, err)
+	assert.Equal(t, "", used_accessor)
+	assert.Equal(t, int64(5), stat.Size())
+	file.Close()
+
+	// Other errors do not fall back to the ntfs accessor.
+	missing, err := accessor.ParsePath(fd.Name() + ".missing")
+	assert.NoError(t, err)
+
+	_, _, used_accessor, err = openForUpload(
+		scope, "file", accessor, missing)
+	assert.Error(t, err)
+	assert.Equal(t, "", used_accessor)
+	assert.NotContains(t, err.Error(), "ntfs")
+}
@@ -20,7 +20,9 @@
 // using the windows API. It is now possible to read authenticode
 // certificates and cat files using the parse_pkcs7() and the
 // parse_pe() vql functions. Those will return more information, but
-// do not perform the verification against the root store.
+// do not perform the verification against the root store. The
+// verify_signature() plugin verifies signatures without using the
+// OS APIs.
 package authenticode
 
 import (
@@ -0,0 +1,156 @@
+package authenticode
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/Velocidex/ordereddict"
+	"github.com/Velocidex/pkcs7"
+	"www.velocidex.com/golang/go-pe"
+	"www.velocidex.com/golang/velociraptor/accessors"
+	"www.velocidex.com/golang/vfilter"
+)
+
+const (
+	// Catalogs are stored in a directory per GUID below CatRoot.
+	MAX_CATALOG_DEPTH = 3
+
+	MAX_CATALOG_SIZE = 50 * 1024 * 1024
+)
+
+type catalogFile struct {
+	path *accessors.OSPath
+	p7   *pkcs7.PKCS7
+}
+
+// An index of the file hashes listed in all the catalog files in a
+// directory. The index is only built when first needed since
+// reading all the catalogs is expensive.
+type catalogIndex struct {
+	scope    vfilter.Scope
+	accessor accessors.FileSystemAccessor
+	dir      *accessors.OSPath
+
+	once sync.Once
+
+	mu sync.Mutex
+
+	// Maps lower case hex hashes to the catalog path.
+	hashes map[string]*accessors.OSPath
+
+	// Parsed catalogs which matched previous lookups.
+	parsed map[string]*catalogFile
+}
+
+func newCatalogIndex(scope vfilter.Scope,
+	accessor accessors.FileSystemAccessor,
+	dir *accessors.OSPath) *catalogIndex {
+	return &catalogIndex{
+		scope:    scope,
+		accessor: accessor,
+		dir:      dir,
+		hashes:   make(map[string]*accessors.OSPath),
+		parsed:   make(map[string]*catalogFile),
+	}
+}
+
+// Find the catalog which lists any of the hashes.
+func (self *catalogIndex) Lookup(
+	ctx context.Context, hashes ...string) (*catalogFile, error) {
+	self.once.Do(func() {
+		self.walk(ctx, self.dir, 0)
+	})
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	for _, hash := range hashes {
+		path, pres := self.hashes[strings.ToLower(hash)]
+		if !pres {
+			continue
+		}
+
+		key := path.String()
+		catalog, pres := self.parsed[key]
+		if pres {
+			return catalog, nil
+		}
+
+		p7, _, err := self.readCatalog(path)
+		if err != nil {
+			return nil, err
+		}
+
+		catalog = &catalogFile{path: path, p7: p7}
+		self.parsed[key] = catalog
+		return catalog, nil
+	}
+
+	return nil, os.ErrNotExist
+}
+
+func (self *catalogIndex) walk(
+	ctx context.Context, dir *accessors.OSPath, depth int) {
+	if depth > MAX_CATALOG_DEPTH {
+		return
+	}
+
+	children, err := self.accessor.ReadDirWithOSPath(dir)
+	if err != nil {
+		return
+	}
+
+	for _, child := range children {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if child.IsDir() {
+			self.walk(ctx, child.OSPath(), depth+1)
+			continue
+		}
+
+		if !strings.HasSuffix(strings.ToLower(child.Name()), ".cat") {
+			continue
+		}
+
+		_, parsed, err := self.readCatalog(child.OSPath())
+		if err != nil {
+			continue
+		}
+
+		members, _ := parsed.Get("CertificateTrustList")
+		entries, _ := members.([]*ordereddict.Dict)
+		for _, entry := range entries {
+			hash, _ := entry.GetString("Hash")
+			if hash != "" {
+				self.hashes[strings.ToLower(hash)] = child.OSPath()
+			}
+		}
+	}
+}
+
+func (self *catalogIndex) readCatalog(path *accessors.OSPath) (
+	*pkcs7.PKCS7, *ordereddict.Dict, error) {
+	fd, err := self.accessor.OpenWithOSPath(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer fd.Close()
+
+	data, err := ioutil.ReadAll(io.LimitReader(fd, MAX_CATALOG_SIZE))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p7, err := pkcs7.Parse(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return p7, pe.PKCS7ToOrderedDict(p7), nil
+}
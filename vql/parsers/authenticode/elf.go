@@ -0,0 +1,80 @@
+package authenticode
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/Velocidex/pkcs7"
+)
+
+// ELF files have no standard signature format, but Linux kernel
+// modules have a PKCS7 signature appended to the file:
+// <module> <PKCS7 signature> <module_signature struct> <magic>
+// Reference: https://www.kernel.org/doc/html/latest/admin-guide/module-signing.html
+const (
+	MODULE_SIGNATURE_MAGIC = "~Module signature appended~\n"
+
+	// sizeof(struct module_signature)
+	MODULE_SIGNATURE_INFO_SIZE = 12
+
+	PKEY_ID_PKCS7 = 2
+
+	MAX_MODULE_SIZE = 100 * 1024 * 1024
+)
+
+func (self *signatureVerifier) verifyELF(
+	reader io.ReaderAt, size int64, result *SignatureResult) {
+	result.Format = "ELF"
+
+	trailer_size := int64(len(MODULE_SIGNATURE_MAGIC) + MODULE_SIGNATURE_INFO_SIZE)
+	if size < trailer_size {
+		return
+	}
+
+	trailer := make([]byte, trailer_size)
+	_, err := reader.ReadAt(trailer, size-trailer_size)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return
+	}
+
+	if string(trailer[MODULE_SIGNATURE_INFO_SIZE:]) != MODULE_SIGNATURE_MAGIC {
+		return
+	}
+
+	result.Signed = true
+	result.Source = "embedded"
+
+	id_type := trailer[2]
+	sig_len := int64(binary.BigEndian.Uint32(trailer[8:]))
+	module_len := size - trailer_size - sig_len
+
+	if id_type != PKEY_ID_PKCS7 {
+		result.setError(errors.New("Unsupported module signature type"))
+		return
+	}
+
+	if module_len < 0 || size > MAX_MODULE_SIZE {
+		result.setError(errors.New("Invalid module signature length"))
+		return
+	}
+
+	data := make([]byte, size-trailer_size)
+	_, err = reader.ReadAt(data, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		result.setError(err)
+		return
+	}
+
+	p7, err := pkcs7.Parse(data[module_len:])
+	if err != nil {
+		result.setError(err)
+		return
+	}
+
+	// The signature is detached and covers the module directly so
+	// the hash is checked as part of the signature.
+	p7.Content = data[:module_len]
+	self.verifyPKCS7(p7, result)
+	result.HashMatches = result.SignatureValid
+}
@@ -0,0 +1,286 @@
+package authenticode
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"debug/macho"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/Velocidex/pkcs7"
+)
+
+// Mach-O code signatures are stored in a blob pointed to by the
+// LC_CODE_SIGNATURE load command. The CodeDirectory holds the hash of
+// each page of the file, and the CMS signature signs the
+// CodeDirectory. Reference:
+// https://opensource.apple.com/source/xnu/xnu-7195.81.3/osfmk/kern/cs_blobs.h
+const (
+	LC_CODE_SIGNATURE = 0x1d
+
+	CSMAGIC_EMBEDDED_SIGNATURE = 0xfade0cc0
+	CSMAGIC_CODEDIRECTORY      = 0xfade0c02
+	CSMAGIC_BLOBWRAPPER        = 0xfade0b01
+
+	CSSLOT_CODEDIRECTORY = 0
+	CSSLOT_SIGNATURESLOT = 0x10000
+
+	MAX_CODE_SIGNATURE_SIZE = 10 * 1024 * 1024
+)
+
+func isMachO(magic []byte) bool {
+	switch binary.BigEndian.Uint32(magic) {
+	case 0xfeedface, 0xfeedfacf, 0xcefaedfe, 0xcffaedfe, 0xcafebabe:
+		return true
+	}
+	return false
+}
+
+func (self *signatureVerifier) verifyMachO(
+	reader io.ReaderAt, result *SignatureResult) {
+	// Universal binaries contain a Mach-O file per architecture -
+	// all of them must be signed.
+	fat, err := macho.NewFatFile(reader)
+	if err == nil {
+		for i, arch := range fat.Arches {
+			slice := io.NewSectionReader(
+				reader, int64(arch.Offset), int64(arch.Size))
+
+			if i == 0 {
+				self.verifyMachOSlice(slice, arch.File, result)
+				continue
+			}
+
+			slice_result := &SignatureResult{}
+			self.verifyMachOSlice(slice, arch.File, slice_result)
+
+			result.Signed = result.Signed && slice_result.Signed
+			result.HashMatches = result.HashMatches && slice_result.HashMatches
+			result.SignatureValid = result.SignatureValid &&
+				slice_result.SignatureValid
+			result.ChainValid = result.ChainValid && slice_result.ChainValid
+			if slice_result.Error != "" {
+				result.setError(errors.New(slice_result.Error))
+			}
+		}
+		return
+	}
+
+	file, err := macho.NewFile(reader)
+	if err != nil {
+		return
+	}
+
+	self.verifyMachOSlice(reader, file, result)
+}
+
+func (self *signatureVerifier) verifyMachOSlice(
+	reader io.ReaderAt, file *macho.File, result *SignatureResult) {
+	result.Format = "MachO"
+
+	var offset, size uint32
+	for _, load := range file.Loads {
+		raw := load.Raw()
+		if len(raw) >= 16 &&
+			file.ByteOrder.Uint32(raw) == LC_CODE_SIGNATURE {
+			offset = file.ByteOrder.Uint32(raw[8:])
+			size = file.ByteOrder.Uint32(raw[12:])
+		}
+	}
+
+	if size == 0 {
+		return
+	}
+
+	if size > MAX_CODE_SIGNATURE_SIZE {
+		result.setError(errors.New("Code signature too large"))
+		return
+	}
+
+	super_blob := make([]byte, size)
+	_, err := reader.ReadAt(super_blob, int64(offset))
+	if err != nil && !errors.Is(err, io.EOF) {
+		result.setError(err)
+		return
+	}
+
+	code_directory, cms, err := parseSuperBlob(super_blob)
+	if err != nil {
+		result.setError(err)
+		return
+	}
+
+	result.Signed = true
+	result.Source = "embedded"
+	result.ProgramName = codeDirectoryIdentifier(code_directory)
+
+	err = checkCodeDirectory(reader, code_directory)
+	if err != nil {
+		result.setError(err)
+	} else {
+		result.HashMatches = true
+	}
+
+	// Ad-hoc signatures only protect the integrity of the file.
+	if len(cms) == 0 {
+		result.Source = "adhoc"
+		result.setError(errors.New("Ad-hoc signature has no signer"))
+		return
+	}
+
+	p7, err := pkcs7.Parse(cms)
+	if err != nil {
+		result.setError(err)
+		return
+	}
+
+	// The CMS signature is detached and signs the CodeDirectory.
+	p7.Content = code_directory
+	self.verifyPKCS7(p7, result)
+}
+
+// Returns the CodeDirectory and the CMS signature blobs.
+func parseSuperBlob(blob []byte) (code_directory []byte, cms []byte, err error) {
+	if len(blob) < 12 ||
+		binary.BigEndian.Uint32(blob) != CSMAGIC_EMBEDDED_SIGNATURE {
+		return nil, nil, errors.New("Invalid code signature")
+	}
+
+	count := int(binary.BigEndian.Uint32(blob[8:]))
+	for i := 0; i < count; i++ {
+		index := 12 + i*8
+		if index+8 > len(blob) {
+			break
+		}
+
+		slot := binary.BigEndian.Uint32(blob[index:])
+		sub_blob := subBlob(blob, binary.BigEndian.Uint32(blob[index+4:]))
+		if sub_blob == nil {
+			continue
+		}
+
+		magic := binary.BigEndian.Uint32(sub_blob)
+		switch {
+		case slot == CSSLOT_CODEDIRECTORY && magic == CSMAGIC_CODEDIRECTORY:
+			code_directory = sub_blob
+
+		case slot == CSSLOT_SIGNATURESLOT && magic == CSMAGIC_BLOBWRAPPER:
+			cms = sub_blob[8:]
+		}
+	}
+
+	if code_directory == nil {
+		return nil, nil, errors.New("No CodeDirectory found")
+	}
+
+	return code_directory, cms, nil
+}
+
+// Each blob starts with a magic and its length.
+func subBlob(blob []byte, offset uint32) []byte {
+	if uint64(offset)+8 > uint64(len(blob)) {
+		return nil
+	}
+
+	length := binary.BigEndian.Uint32(blob[offset+4:])
+	if length < 8 || uint64(offset)+uint64(length) > uint64(len(blob)) {
+		return nil
+	}
+
+	return blob[offset : offset+length]
+}
+
+func codeDirectoryIdentifier(code_directory []byte) string {
+	if len(code_directory) < 24 {
+		return ""
+	}
+
+	offset := binary.BigEndian.Uint32(code_directory[20:])
+	if offset >= uint32(len(code_directory)) {
+		return ""
+	}
+
+	identifier := code_directory[offset:]
+	end := bytes.IndexByte(identifier, 0)
+	if end >= 0 {
+		identifier = identifier[:end]
+	}
+	return string(identifier)
+}
+
+// Check the hash of each page against the CodeDirectory.
+func checkCodeDirectory(reader io.ReaderAt, code_directory []byte) error {
+	if len(code_directory) < 40 {
+		return errors.New("CodeDirectory too short")
+	}
+
+	hash_offset := uint64(binary.BigEndian.Uint32(code_directory[16:]))
+	code_slots := uint64(binary.BigEndian.Uint32(code_directory[28:]))
+	code_limit := int64(binary.BigEndian.Uint32(code_directory[32:]))
+	hash_size := uint64(code_directory[36])
+	hash_type := code_directory[37]
+	page_shift := code_directory[39]
+	if page_shift > 20 {
+		return fmt.Errorf("Unsupported page size 2^%v", page_shift)
+	}
+
+	// A page size of 0 means the whole file is a single page.
+	page_size := int64(1) << page_shift
+	if page_shift == 0 {
+		page_size = code_limit
+	}
+
+	if page_size > MAX_CODE_SIGNATURE_SIZE {
+		return fmt.Errorf("Unsupported page size %v", page_size)
+	}
+
+	var new_hash func() hash.Hash
+	switch hash_type {
+	case 1:
+		new_hash = sha1.New
+	case 2, 3:
+		new_hash = sha256.New
+	case 4:
+		new_hash = sha512.New384
+	default:
+		return fmt.Errorf("Unsupported hash type %v", hash_type)
+	}
+
+	if hash_offset+code_slots*hash_size > uint64(len(code_directory)) ||
+		hash_size > uint64(new_hash().Size()) {
+		return errors.New("CodeDirectory hashes out of range")
+	}
+
+	buf := make([]byte, page_size)
+	for i := uint64(0); i < code_slots; i++ {
+		start := int64(i) * page_size
+		end := start + page_size
+		if end > code_limit {
+			end = code_limit
+		}
+		if start >= end {
+			return errors.New("CodeDirectory slots exceed code limit")
+		}
+
+		page := buf[:end-start]
+		n, err := reader.ReadAt(page, start)
+		if n < len(page) {
+			return fmt.Errorf("Reading page %v: %v", i, err)
+		}
+
+		h := new_hash()
+		_, _ = h.Write(page)
+
+		expected := code_directory[hash_offset+i*hash_size:][:hash_size]
+		if !bytes.Equal(h.Sum(nil)[:hash_size], expected) {
+			return fmt.Errorf("Hash mismatch for page %v", i)
+		}
+	}
+
+	return nil
+}
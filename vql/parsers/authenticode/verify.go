@@ -0,0 +1,441 @@
+/*
+   Velociraptor - Hunting Evil
+   Copyright (C) 2019 Velocidex Innovations.
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package authenticode
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"io"
+	"math/big"
+	"runtime"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	"github.com/Velocidex/pkcs7"
+	"www.velocidex.com/golang/go-pe"
+	"www.velocidex.com/golang/velociraptor/accessors"
+	"www.velocidex.com/golang/velociraptor/acls"
+	"www.velocidex.com/golang/velociraptor/constants"
+	"www.velocidex.com/golang/velociraptor/crypto"
+	"www.velocidex.com/golang/velociraptor/utils"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/velociraptor/vql/readers"
+	"www.velocidex.com/golang/vfilter"
+	"www.velocidex.com/golang/vfilter/arg_parser"
+)
+
+const (
+	TRUSTED   = "trusted"
+	UNTRUSTED = "untrusted"
+	UNSIGNED  = "unsigned"
+
+	// The signature is present but can not be verified (e.g. the
+	// signer certificate is not included).
+	UNKNOWN = "unknown"
+)
+
+// The result of verifying a single file. The columns are the same
+// for all file formats so results can be stacked.
+type SignatureResult struct {
+	OSPath  *accessors.OSPath
+	Format  string
+	Signed  bool
+	Source  string
+	Catalog string
+
+	Subject      string
+	Issuer       string
+	SerialNumber string
+	ProgramName  string
+	Timestamp    vfilter.Any
+
+	// The signed hash matches the file content.
+	HashMatches bool
+
+	// The signature was made by the signer certificate.
+	SignatureValid bool
+
+	// The signer certificate chains to a trusted root.
+	ChainValid bool
+
+	Trusted string
+	Error   string
+}
+
+func (self *SignatureResult) setError(err error) {
+	if self.Error == "" && err != nil {
+		self.Error = err.Error()
+	}
+}
+
+type VerifySignatureArgs struct {
+	Files      []string `vfilter:"required,field=files,doc=The files to verify."`
+	Accessor   string   `vfilter:"optional,field=accessor,doc=The accessor to use."`
+	RootCerts  string   `vfilter:"optional,field=root_ca,doc=Additional PEM encoded root certificates to trust."`
+	CatalogDir string   `vfilter:"optional,field=catalog_dir,doc=A directory containing catalog (.cat) files to check unsigned PE files against (Default C:/Windows/System32/CatRoot on Windows)."`
+}
+
+type VerifySignaturePlugin struct{}
+
+func (self VerifySignaturePlugin) Call(
+	ctx context.Context,
+	scope vfilter.Scope,
+	args *ordereddict.Dict) <-chan vfilter.Row {
+	output_chan := make(chan vfilter.Row)
+
+	go func() {
+		defer close(output_chan)
+
+		err := vql_subsystem.CheckAccess(scope, acls.MACHINE_STATE)
+		if err != nil {
+			scope.Log("verify_signature: %s", err)
+			return
+		}
+
+		arg := &VerifySignatureArgs{}
+		err = arg_parser.ExtractArgsWithContext(ctx, scope, args, arg)
+		if err != nil {
+			scope.Log("verify_signature: %v", err)
+			return
+		}
+
+		err = vql_subsystem.CheckFilesystemAccess(scope, arg.Accessor)
+		if err != nil {
+			scope.Log("verify_signature: %v", err)
+			return
+		}
+
+		accessor, err := accessors.GetAccessor(arg.Accessor, scope)
+		if err != nil {
+			scope.Log("verify_signature: %v", err)
+			return
+		}
+
+		verifier, err := newSignatureVerifier(scope, arg, accessor)
+		if err != nil {
+			scope.Log("verify_signature: %v", err)
+			return
+		}
+
+		for _, filename := range arg.Files {
+			os_path, err := accessor.ParsePath(filename)
+			if err != nil {
+				scope.Log("verify_signature: %v", err)
+				continue
+			}
+
+			result, err := verifier.Verify(ctx, os_path)
+			if err != nil {
+				scope.Log("verify_signature: %v: %v", filename, err)
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case output_chan <- result:
+			}
+		}
+	}()
+
+	return output_chan
+}
+
+func (self VerifySignaturePlugin) Info(
+	scope vfilter.Scope, type_map *vfilter.TypeMap) *vfilter.PluginInfo {
+	return &vfilter.PluginInfo{
+		Name: "verify_signature",
+		Doc: "Verify the code signatures of PE, Mach-O and ELF " +
+			"files without relying on the OS APIs.",
+		ArgType: type_map.AddType(scope, &VerifySignatureArgs{}),
+	}
+}
+
+// Verify a single local file outside of a query (e.g. the client
+// checking its own binary).
+func VerifyFile(ctx context.Context, filename string) (*SignatureResult, error) {
+	scope := vql_subsystem.MakeScope()
+	defer scope.Close()
+
+	arg := &VerifySignatureArgs{Accessor: "file"}
+	accessor, err := accessors.GetAccessor(arg.Accessor, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	verifier, err := newSignatureVerifier(scope, arg, accessor)
+	if err != nil {
+		return nil, err
+	}
+
+	os_path, err := accessor.ParsePath(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return verifier.Verify(ctx, os_path)
+}
+
+type signatureVerifier struct {
+	scope    vfilter.Scope
+	accessor accessors.FileSystemAccessor
+
+	accessor_name string
+	roots         *x509.CertPool
+	catalogs      *catalogIndex
+}
+
+func newSignatureVerifier(
+	scope vfilter.Scope, arg *VerifySignatureArgs,
+	accessor accessors.FileSystemAccessor) (*signatureVerifier, error) {
+
+	// On Windows and MacOS the system pool uses the platform
+	// verifier, which knows about the code signing roots.
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+		crypto.AddPublicRoots(roots)
+	}
+
+	if arg.RootCerts != "" &&
+		!roots.AppendCertsFromPEM([]byte(arg.RootCerts)) {
+		return nil, errors.New("Unable to add root certs")
+	}
+
+	catalog_dir := arg.CatalogDir
+	if catalog_dir == "" && runtime.GOOS == "windows" {
+		catalog_dir = "C:/Windows/System32/CatRoot"
+	}
+
+	result := &signatureVerifier{
+		scope:         scope,
+		accessor:      accessor,
+		accessor_name: arg.Accessor,
+		roots:         roots,
+	}
+
+	if catalog_dir != "" {
+		dir, err := accessor.ParsePath(catalog_dir)
+		if err != nil {
+			return nil, err
+		}
+		result.catalogs = newCatalogIndex(scope, accessor, dir)
+	}
+
+	return result, nil
+}
+
+func (self *signatureVerifier) Verify(
+	ctx context.Context, os_path *accessors.OSPath) (*SignatureResult, error) {
+	stat, err := self.accessor.LstatWithOSPath(os_path)
+	if err != nil {
+		return nil, err
+	}
+
+	lru_size := vql_subsystem.GetIntFromRow(
+		self.scope, self.scope, constants.BINARY_CACHE_SIZE)
+	reader, err := readers.NewPagedReader(
+		self.scope, self.accessor_name, os_path, int(lru_size))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	result := &SignatureResult{
+		OSPath:    os_path,
+		Format:    "Unknown",
+		Timestamp: vfilter.Null{},
+		Trusted:   UNSIGNED,
+	}
+
+	magic := make([]byte, 4)
+	_, err = reader.ReadAt(magic, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	switch {
+	case string(magic[:2]) == "MZ":
+		self.verifyPE(ctx, reader, result)
+
+	case isMachO(magic):
+		self.verifyMachO(reader, result)
+
+	case string(magic) == "\x7fELF":
+		self.verifyELF(reader, stat.Size(), result)
+	}
+
+	if result.Signed && result.Trusted == UNSIGNED {
+		result.Trusted = UNTRUSTED
+		if result.HashMatches && result.SignatureValid && result.ChainValid {
+			result.Trusted = TRUSTED
+		}
+	}
+
+	return result, nil
+}
+
+func (self *signatureVerifier) verifyPE(
+	ctx context.Context, reader io.ReaderAt, result *SignatureResult) {
+	pe_file, err := pe.NewPEFile(reader)
+	if err != nil {
+		return
+	}
+
+	result.Format = "PE"
+	hashes := pe_file.CalcHashToDict()
+
+	p7, err := pe.ParseAuthenticode(pe_file)
+	if err == nil {
+		result.Signed = true
+		result.Source = "embedded"
+		result.HashMatches, _ = hashes.GetBool("HashMatches")
+		if !result.HashMatches {
+			result.setError(errors.New("Authenticode hash does not match"))
+		}
+		self.verifyPKCS7(p7, result)
+		return
+	}
+
+	// Maybe the file is signed in a catalog.
+	if self.catalogs == nil {
+		return
+	}
+
+	catalog, err := self.catalogs.Lookup(ctx,
+		utils.GetString(hashes, "SHA1"),
+		utils.GetString(hashes, "SHA256"))
+	if err != nil {
+		return
+	}
+
+	result.Signed = true
+	result.Source = "catalog"
+	result.Catalog = catalog.path.String()
+
+	// The catalog lists the hash of this file.
+	result.HashMatches = true
+	self.verifyPKCS7(catalog.p7, result)
+}
+
+// Check the signature itself and that the signer chains to a trusted
+// root. The content signed must already be set in the p7.
+func (self *signatureVerifier) verifyPKCS7(
+	p7 *pkcs7.PKCS7, result *SignatureResult) {
+	signer := pe.PKCS7ToOrderedDict(p7)
+	result.Subject = utils.GetString(signer, "Signer.Subject")
+	result.Issuer = utils.GetString(signer, "Signer.IssuerName")
+	result.SerialNumber = utils.GetString(signer, "Signer.SerialNumber")
+	if result.ProgramName == "" {
+		result.ProgramName = utils.GetString(
+			signer, "Signer.AuthenticatedAttributes.ProgramName")
+	}
+
+	// Prefer the time stamp from the counter signature.
+	signing_time, ok := utils.GetAny(signer,
+		"Signer.UnauthenticatedAttributes.CounterSignature."+
+			"AuthenticatedAttributes.SigningTime").(*time.Time)
+	if !ok || signing_time == nil {
+		signing_time, ok = utils.GetAny(signer,
+			"Signer.AuthenticatedAttributes.SigningTime").(*time.Time)
+	}
+
+	if !ok || signing_time == nil {
+		signing_time, ok = getRFC3161Timestamp(p7)
+	}
+
+	verification_time := time.Now()
+	if ok && signing_time != nil {
+		result.Timestamp = *signing_time
+		verification_time = *signing_time
+	}
+
+	cert := p7.GetOnlySigner()
+	if cert == nil {
+		result.Trusted = UNKNOWN
+		result.setError(errors.New("Signer certificate not included"))
+		return
+	}
+
+	err := p7.Verify()
+	if err != nil {
+		result.setError(err)
+	} else {
+		result.SignatureValid = true
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, c := range p7.Certificates {
+		intermediates.AddCert(c)
+	}
+
+	_, err = cert.Verify(x509.VerifyOptions{
+		Roots:         self.roots,
+		Intermediates: intermediates,
+		CurrentTime:   verification_time,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	})
+	if err != nil {
+		result.setError(err)
+	} else {
+		result.ChainValid = true
+	}
+}
+
+// Modern signatures are time stamped using an RFC3161 token stored in
+// an unauthenticated attribute.
+var oidRFC3161Timestamp = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 3, 3, 1}
+
+type tstInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint asn1.RawValue
+	SerialNumber   *big.Int
+	GenTime        time.Time `asn1:"generalized"`
+}
+
+func getRFC3161Timestamp(p7 *pkcs7.PKCS7) (*time.Time, bool) {
+	for _, signer := range p7.Signers {
+		for _, attr := range signer.UnauthenticatedAttributes {
+			if !attr.Type.Equal(oidRFC3161Timestamp) {
+				continue
+			}
+
+			token, err := pkcs7.Parse(attr.Value.Bytes)
+			if err != nil {
+				continue
+			}
+
+			info := &tstInfo{}
+			_, err = asn1.Unmarshal(token.Content, info)
+			if err != nil {
+				continue
+			}
+
+			return &info.GenTime, true
+		}
+	}
+	return nil, false
+}
+
+func init() {
+	vql_subsystem.RegisterPlugin(&VerifySignaturePlugin{})
+}
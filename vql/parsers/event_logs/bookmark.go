@@ -0,0 +1,436 @@
+package event_logs
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	"www.velocidex.com/golang/evtx"
+	"www.velocidex.com/golang/velociraptor/accessors"
+	"www.velocidex.com/golang/velociraptor/acls"
+	"www.velocidex.com/golang/velociraptor/constants"
+	"www.velocidex.com/golang/velociraptor/utils"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	vfilter "www.velocidex.com/golang/vfilter"
+	"www.velocidex.com/golang/vfilter/arg_parser"
+)
+
+// The bookmark records how far we got in each event log. It is
+// persisted to disk so that when the client restarts we continue from
+// where we left off - no events are sent twice and none are skipped.
+type EvtxBookmark struct {
+	Filename string `json:"Filename"`
+
+	// The last record ID we emitted.
+	LastRecordID uint64 `json:"LastRecordID"`
+
+	// The time of the last emitted event (seconds since epoch).
+	LastEventTime int64 `json:"LastEventTime"`
+
+	// Total number of events emitted from this log.
+	EventCount uint64 `json:"EventCount"`
+
+	// Records which were lost because the log rolled over before
+	// we could read them.
+	MissedRecords uint64 `json:"MissedRecords"`
+
+	// When the bookmark was last written (seconds since epoch).
+	Updated int64 `json:"Updated"`
+}
+
+// A set of bookmarks stored in a single file.
+type bookmarkFile struct {
+	mu sync.Mutex
+
+	path      string
+	bookmarks map[string]*EvtxBookmark
+}
+
+func (self *bookmarkFile) Get(filename string) (*EvtxBookmark, bool) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	bookmark, pres := self.bookmarks[filename]
+	if !pres {
+		return &EvtxBookmark{Filename: filename}, false
+	}
+	result := *bookmark
+	return &result, true
+}
+
+func (self *bookmarkFile) Set(bookmark *EvtxBookmark) error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	record := *bookmark
+	record.Updated = time.Now().Unix()
+	self.bookmarks[bookmark.Filename] = &record
+
+	return self.flush()
+}
+
+// Write the bookmarks atomically so a crash does not leave a
+// truncated file behind.
+func (self *bookmarkFile) flush() error {
+	serialized, err := json.MarshalIndent(self.bookmarks, "", " ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(self.path), 0700)
+	if err != nil {
+		return err
+	}
+
+	tmp_path := self.path + ".tmp"
+	err = ioutil.WriteFile(tmp_path, serialized, 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp_path, self.path)
+}
+
+func openBookmarkFile(path string) (*bookmarkFile, error) {
+	result := &bookmarkFile{
+		path:      path,
+		bookmarks: make(map[string]*EvtxBookmark),
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return result, nil
+		}
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &result.bookmarks)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+type _WatchEvtxBookmarkPluginArgs struct {
+	Filenames []string `vfilter:"required,field=filename,doc=A list of event log files to watch."`
+	Accessor  string   `vfilter:"optional,field=accessor,doc=The accessor to use."`
+	Database  string   `vfilter:"optional,field=messagedb,doc=A Message database from https://github.com/Velocidex/evtx-data."`
+	Bookmark  string   `vfilter:"required,field=bookmark,doc=A local file to store the bookmarks in."`
+	FromStart bool     `vfilter:"optional,field=from_start,doc=If there is no bookmark for a log, start from its first event (default start from the end)."`
+}
+
+type _WatchEvtxBookmarkPlugin struct{}
+
+func (self _WatchEvtxBookmarkPlugin) Call(
+	ctx context.Context,
+	scope vfilter.Scope,
+	args *ordereddict.Dict) <-chan vfilter.Row {
+	output_chan := make(chan vfilter.Row)
+
+	go func() {
+		defer close(output_chan)
+
+		arg := &_WatchEvtxBookmarkPluginArgs{}
+		err := arg_parser.ExtractArgsWithContext(ctx, scope, args, arg)
+		if err != nil {
+			scope.Log("watch_evtx_bookmark: %s", err.Error())
+			return
+		}
+
+		err = vql_subsystem.CheckFilesystemAccess(scope, arg.Accessor)
+		if err != nil {
+			scope.Log("watch_evtx_bookmark: %s", err)
+			return
+		}
+
+		// We write the bookmark file.
+		err = vql_subsystem.CheckAccess(scope, acls.FILESYSTEM_WRITE)
+		if err != nil {
+			scope.Log("watch_evtx_bookmark: %s", err)
+			return
+		}
+
+		accessor, err := accessors.GetAccessor(arg.Accessor, scope)
+		if err != nil {
+			scope.Log("watch_evtx_bookmark: %v", err)
+			return
+		}
+
+		bookmarks, err := openBookmarkFile(arg.Bookmark)
+		if err != nil {
+			scope.Log("watch_evtx_bookmark: Unable to read bookmarks %v: %v",
+				arg.Bookmark, err)
+			return
+		}
+
+		var resolver evtx.MessageResolver
+		if arg.Database != "" {
+			resolver, err = evtx.NewDBResolver(arg.Database)
+		} else {
+			resolver, err = evtx.GetNativeResolver()
+		}
+		if err != nil {
+			scope.Log("watch_evtx_bookmark: %s", err.Error())
+			return
+		}
+		vql_subsystem.GetRootScope(scope).AddDestructor(resolver.Close)
+
+		frequency := vql_subsystem.GetIntFromRow(
+			scope, scope, constants.EVTX_FREQUENCY)
+		if frequency == 0 {
+			frequency = 3
+		}
+
+		// Logs without a bookmark start at the current end of the
+		// log unless we were asked to forward the existing events.
+		for _, filename := range arg.Filenames {
+			_, pres := bookmarks.Get(filename)
+			if pres || arg.FromStart {
+				continue
+			}
+
+			bookmark := &EvtxBookmark{
+				Filename:     filename,
+				LastRecordID: findLastRecordID(filename, accessor),
+			}
+			err := bookmarks.Set(bookmark)
+			if err != nil {
+				scope.Log("watch_evtx_bookmark: %v", err)
+				return
+			}
+		}
+
+		for {
+			for _, filename := range arg.Filenames {
+				err := forwardEvents(ctx, scope, filename, accessor,
+					bookmarks, resolver, output_chan)
+				if err != nil {
+					scope.Log("watch_evtx_bookmark: %v: %v", filename, err)
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-time.After(time.Duration(frequency) * time.Second):
+			}
+		}
+	}()
+
+	return output_chan
+}
+
+func (self _WatchEvtxBookmarkPlugin) Info(scope vfilter.Scope, type_map *vfilter.TypeMap) *vfilter.PluginInfo {
+	return &vfilter.PluginInfo{
+		Name: "watch_evtx_bookmark",
+		Doc: "Watch an EVTX file and stream events from it, keeping a " +
+			"persistent bookmark so events are not missed or repeated " +
+			"across restarts.",
+		ArgType: type_map.AddType(scope, &_WatchEvtxBookmarkPluginArgs{}),
+	}
+}
+
+// Emit all the events after the bookmark and advance it. The bookmark
+// is advanced only for events that were actually emitted, so if the
+// query is cancelled midway we resume from the right place.
+func forwardEvents(
+	ctx context.Context,
+	scope vfilter.Scope,
+	filename string,
+	accessor accessors.FileSystemAccessor,
+	bookmarks *bookmarkFile,
+	resolver evtx.MessageResolver,
+	output_chan chan vfilter.Row) error {
+
+	defer utils.RecoverVQL(scope)
+
+	bookmark, _ := bookmarks.Get(filename)
+	last_record_id := bookmark.LastRecordID
+
+	// Always save the bookmark when we leave.
+	defer func() {
+		if bookmark.LastRecordID != last_record_id {
+			err := bookmarks.Set(bookmark)
+			if err != nil {
+				scope.Log("watch_evtx_bookmark: %v", err)
+			}
+		}
+	}()
+
+	fd, err := accessor.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	chunks, err := getSortedChunks(fd)
+	if err != nil {
+		return err
+	}
+
+	// Check if the log rolled over past our bookmark.
+	if len(chunks) > 0 && bookmark.LastRecordID > 0 {
+		first := chunks[0].Header.FirstEventRecID
+		if first > bookmark.LastRecordID+1 {
+			missed := first - bookmark.LastRecordID - 1
+			scope.Log("watch_evtx_bookmark: %v: %v records were lost "+
+				"because the log rolled over", filename, missed)
+			bookmark.MissedRecords += missed
+			bookmark.LastRecordID = first - 1
+		}
+	}
+
+	for _, chunk := range chunks {
+		if chunk.Header.LastEventRecID <= bookmark.LastRecordID {
+			continue
+		}
+
+		records, _ := chunk.Parse(int(bookmark.LastRecordID + 1))
+		for _, record := range records {
+			event_map, ok := record.Event.(*ordereddict.Dict)
+			if !ok {
+				continue
+			}
+
+			event, pres := ordereddict.GetMap(event_map, "Event")
+			if !pres {
+				continue
+			}
+
+			if resolver != nil {
+				event.Set("Message", evtx.ExpandMessage(event, resolver))
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil
+
+			case output_chan <- event:
+			}
+
+			bookmark.LastRecordID = record.Header.RecordID
+			bookmark.LastEventTime = fileTimeToUnix(record.Header.FileTime)
+			bookmark.EventCount++
+		}
+	}
+
+	return nil
+}
+
+// Chunks in a circular log are not stored in order so sort them by
+// record ID.
+func getSortedChunks(fd accessors.ReadSeekCloser) ([]*evtx.Chunk, error) {
+	chunks, err := evtx.GetChunks(fd)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*evtx.Chunk, 0, len(chunks))
+	for _, c := range chunks {
+		if c != nil && c.Header.LastEventRecID > 0 {
+			result = append(result, c)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Header.FirstEventRecID <
+			result[j].Header.FirstEventRecID
+	})
+
+	return result, nil
+}
+
+func findLastRecordID(
+	filename string, accessor accessors.FileSystemAccessor) uint64 {
+	fd, err := accessor.Open(filename)
+	if err != nil {
+		return 0
+	}
+	defer fd.Close()
+
+	chunks, err := getSortedChunks(fd)
+	if err != nil || len(chunks) == 0 {
+		return 0
+	}
+
+	return chunks[len(chunks)-1].Header.LastEventRecID
+}
+
+// Convert a Windows FILETIME to seconds since the epoch.
+func fileTimeToUnix(filetime uint64) int64 {
+	return int64(filetime/10000000) - 11644473600
+}
+
+type _EvtxBookmarksPluginArgs struct {
+	Bookmark string `vfilter:"required,field=bookmark,doc=The bookmark file written by watch_evtx_bookmark()."`
+}
+
+type _EvtxBookmarksPlugin struct{}
+
+func (self _EvtxBookmarksPlugin) Call(
+	ctx context.Context,
+	scope vfilter.Scope,
+	args *ordereddict.Dict) <-chan vfilter.Row {
+	output_chan := make(chan vfilter.Row)
+
+	go func() {
+		defer close(output_chan)
+
+		arg := &_EvtxBookmarksPluginArgs{}
+		err := arg_parser.ExtractArgsWithContext(ctx, scope, args, arg)
+		if err != nil {
+			scope.Log("evtx_bookmarks: %s", err.Error())
+			return
+		}
+
+		err = vql_subsystem.CheckAccess(scope, acls.FILESYSTEM_READ)
+		if err != nil {
+			scope.Log("evtx_bookmarks: %s", err)
+			return
+		}
+
+		bookmarks, err := openBookmarkFile(arg.Bookmark)
+		if err != nil {
+			scope.Log("evtx_bookmarks: %v", err)
+			return
+		}
+
+		filenames := make([]string, 0, len(bookmarks.bookmarks))
+		for k := range bookmarks.bookmarks {
+			filenames = append(filenames, k)
+		}
+		sort.Strings(filenames)
+
+		for _, filename := range filenames {
+			select {
+			case <-ctx.Done():
+				return
+			case output_chan <- bookmarks.bookmarks[filename]:
+			}
+		}
+	}()
+
+	return output_chan
+}
+
+func (self _EvtxBookmarksPlugin) Info(scope vfilter.Scope, type_map *vfilter.TypeMap) *vfilter.PluginInfo {
+	return &vfilter.PluginInfo{
+		Name:    "evtx_bookmarks",
+		Doc:     "Show the bookmarks kept by watch_evtx_bookmark().",
+		ArgType: type_map.AddType(scope, &_EvtxBookmarksPluginArgs{}),
+	}
+}
+
+func init() {
+	vql_subsystem.RegisterPlugin(&_WatchEvtxBookmarkPlugin{})
+	vql_subsystem.RegisterPlugin(&_EvtxBookmarksPlugin{})
+}
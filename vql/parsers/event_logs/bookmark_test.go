@@ -0,0 +1,141 @@
+package event_logs
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"www.velocidex.com/golang/velociraptor/constants"
+	"www.velocidex.com/golang/velociraptor/utils"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	vfilter "www.velocidex.com/golang/vfilter"
+
+	_ "www.velocidex.com/golang/velociraptor/accessors/file"
+)
+
+type BookmarkTestSuite struct {
+	suite.Suite
+
+	tmpdir   string
+	filename string
+	bookmark string
+}
+
+func (self *BookmarkTestSuite) SetupTest() {
+	tmpdir, err := ioutil.TempDir("", "evtx_bookmark")
+	require.NoError(self.T(), err)
+
+	self.tmpdir = tmpdir
+	self.bookmark = filepath.Join(tmpdir, "bookmarks.json")
+	self.filename, err = filepath.Abs(
+		"../../../artifacts/testdata/files/EID4769_Kerbroasting.evtx")
+	require.NoError(self.T(), err)
+}
+
+func (self *BookmarkTestSuite) TearDownTest() {
+	os.RemoveAll(self.tmpdir)
+}
+
+// Run the plugin and collect up to count events. Stops when no more
+// events arrive.
+func (self *BookmarkTestSuite) watch(
+	from_start bool, count int) (result []uint64) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	scope := vql_subsystem.MakeScope().AppendVars(
+		ordereddict.NewDict().Set(constants.EVTX_FREQUENCY, 1).
+			Set(vql_subsystem.ACL_MANAGER_VAR, vql_subsystem.NullACLManager{}))
+	scope.SetLogger(log.New(os.Stderr, "", 0))
+	defer scope.Close()
+
+	output_chan := _WatchEvtxBookmarkPlugin{}.Call(ctx, scope,
+		ordereddict.NewDict().
+			Set("filename", []string{self.filename}).
+			Set("bookmark", self.bookmark).
+			Set("from_start", from_start))
+
+	// Wait for the plugin to exit so the bookmark is written. Events
+	// which race with the cancellation are still emitted so count
+	// them too.
+	defer func() {
+		cancel()
+		for row := range output_chan {
+			result = append(result, recordID(scope, row))
+		}
+	}()
+
+	for len(result) < count {
+		select {
+		case row, ok := <-output_chan:
+			if !ok {
+				return result
+			}
+			result = append(result, recordID(scope, row))
+
+		case <-time.After(500 * time.Millisecond):
+			return result
+		}
+	}
+
+	return result
+}
+
+func (self *BookmarkTestSuite) getBookmark() *EvtxBookmark {
+	bookmarks, err := openBookmarkFile(self.bookmark)
+	require.NoError(self.T(), err)
+
+	bookmark, pres := bookmarks.Get(self.filename)
+	assert.True(self.T(), pres)
+	return bookmark
+}
+
+func (self *BookmarkTestSuite) TestFromStart() {
+	all_events := self.watch(true, 1000)
+	assert.True(self.T(), len(all_events) > 2)
+
+	bookmark := self.getBookmark()
+	assert.Equal(self.T(), all_events[len(all_events)-1], bookmark.LastRecordID)
+	assert.Equal(self.T(), uint64(len(all_events)), bookmark.EventCount)
+
+	// Restarting does not resend any events.
+	assert.Equal(self.T(), 0, len(self.watch(true, 1000)))
+}
+
+func (self *BookmarkTestSuite) TestResume() {
+	first := self.watch(true, 2)
+	assert.True(self.T(), len(first) >= 2)
+
+	// We continue exactly after the last event we emitted.
+	rest := self.watch(true, 1000)
+	assert.True(self.T(), len(rest) > 0)
+	assert.Equal(self.T(), first[len(first)-1]+1, rest[0])
+
+	bookmark := self.getBookmark()
+	assert.Equal(self.T(), uint64(len(first)+len(rest)), bookmark.EventCount)
+	assert.Equal(self.T(), uint64(0), bookmark.MissedRecords)
+}
+
+func (self *BookmarkTestSuite) TestStartAtEnd() {
+	// Without a bookmark we start at the end of the log.
+	assert.Equal(self.T(), 0, len(self.watch(false, 1000)))
+	assert.True(self.T(), self.getBookmark().LastRecordID > 0)
+}
+
+func recordID(scope vfilter.Scope, row vfilter.Row) uint64 {
+	system, _ := scope.Associative(row, "System")
+	id, _ := scope.Associative(system, "EventRecordID")
+	result, _ := utils.ToInt64(id)
+	return uint64(result)
+}
+
+func TestBookmarks(t *testing.T) {
+	suite.Run(t, &BookmarkTestSuite{})
+}
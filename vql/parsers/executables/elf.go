@@ -0,0 +1,86 @@
+package executables
+
+import (
+	"debug/elf"
+	"io"
+
+	"www.velocidex.com/golang/velociraptor/accessors"
+)
+
+func parseELF(reader io.ReaderAt, os_path *accessors.OSPath) (*ExecutableInfo, error) {
+	file, err := elf.NewFile(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	result := newExecutableInfo(os_path, "ELF")
+	result.Machine = file.Machine.String()
+	result.Type = file.Type.String()
+	result.EntryPoint = file.Entry
+
+	for _, section := range file.Sections {
+		if section.Type == elf.SHT_NULL {
+			continue
+		}
+
+		result.Sections = append(result.Sections, &Section{
+			Name:           section.Name,
+			FileOffset:     int64(section.Offset),
+			Size:           int64(section.Size),
+			VirtualAddress: section.Addr,
+			Perm:           elfSectionPerm(section.Flags),
+		})
+	}
+
+	libraries, err := file.ImportedLibraries()
+	if err == nil && libraries != nil {
+		result.Libraries = libraries
+	}
+
+	imports, err := file.ImportedSymbols()
+	if err == nil {
+		for _, symbol := range imports {
+			name := symbol.Name
+			if symbol.Library != "" {
+				name = symbol.Library + "!" + name
+			}
+			result.Imports = append(result.Imports, name)
+		}
+	}
+
+	symbols, err := file.DynamicSymbols()
+	if err == nil {
+		for _, symbol := range symbols {
+			if symbol.Section == elf.SHN_UNDEF || symbol.Name == "" {
+				continue
+			}
+
+			switch elf.ST_BIND(symbol.Info) {
+			case elf.STB_GLOBAL, elf.STB_WEAK:
+			default:
+				continue
+			}
+
+			switch elf.ST_TYPE(symbol.Info) {
+			case elf.STT_FUNC, elf.STT_OBJECT:
+				result.Exports = append(result.Exports, symbol.Name)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func elfSectionPerm(flags elf.SectionFlag) string {
+	perm := []byte("---")
+	if flags&elf.SHF_ALLOC != 0 {
+		perm[0] = 'r'
+	}
+	if flags&elf.SHF_WRITE != 0 {
+		perm[1] = 'w'
+	}
+	if flags&elf.SHF_EXECINSTR != 0 {
+		perm[2] = 'x'
+	}
+	return string(perm)
+}
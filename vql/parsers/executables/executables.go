@@ -0,0 +1,208 @@
+/*
+   Velociraptor - Hunting Evil
+   Copyright (C) 2019 Velocidex Innovations.
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Extract metadata from PE, ELF and Mach-O executables. The same
+// columns are emitted for all formats so a single artifact can
+// triage binaries from any platform.
+package executables
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/Velocidex/ordereddict"
+	"www.velocidex.com/golang/velociraptor/accessors"
+	"www.velocidex.com/golang/velociraptor/constants"
+	"www.velocidex.com/golang/velociraptor/utils"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/velociraptor/vql/readers"
+	"www.velocidex.com/golang/vfilter"
+	"www.velocidex.com/golang/vfilter/arg_parser"
+)
+
+type Section struct {
+	Name           string
+	FileOffset     int64
+	Size           int64
+	VirtualAddress uint64
+	Perm           string
+}
+
+type ExecutableInfo struct {
+	OSPath     *accessors.OSPath
+	Format     string
+	Machine    string
+	Type       string
+	EntryPoint uint64
+
+	// The link time for PE files.
+	Compiled vfilter.Any
+
+	Sections  []*Section
+	Libraries []string
+	Imports   []string
+	Exports   []string
+	ImpHash   string
+
+	// PE files only.
+	VersionInformation vfilter.Any
+	RichHeader         vfilter.Any
+}
+
+func newExecutableInfo(os_path *accessors.OSPath, format string) *ExecutableInfo {
+	return &ExecutableInfo{
+		OSPath:             os_path,
+		Format:             format,
+		Compiled:           vfilter.Null{},
+		Sections:           []*Section{},
+		Libraries:          []string{},
+		Imports:            []string{},
+		Exports:            []string{},
+		VersionInformation: vfilter.Null{},
+		RichHeader:         vfilter.Null{},
+	}
+}
+
+type ParseExecutableArgs struct {
+	Files    []string `vfilter:"required,field=files,doc=The executables to parse."`
+	Accessor string   `vfilter:"optional,field=accessor,doc=The accessor to use."`
+}
+
+type ParseExecutablePlugin struct{}
+
+func (self ParseExecutablePlugin) Call(
+	ctx context.Context,
+	scope vfilter.Scope,
+	args *ordereddict.Dict) <-chan vfilter.Row {
+	output_chan := make(chan vfilter.Row)
+
+	go func() {
+		defer close(output_chan)
+
+		arg := &ParseExecutableArgs{}
+		err := arg_parser.ExtractArgsWithContext(ctx, scope, args, arg)
+		if err != nil {
+			scope.Log("parse_executable: %v", err)
+			return
+		}
+
+		err = vql_subsystem.CheckFilesystemAccess(scope, arg.Accessor)
+		if err != nil {
+			scope.Log("parse_executable: %v", err)
+			return
+		}
+
+		accessor, err := accessors.GetAccessor(arg.Accessor, scope)
+		if err != nil {
+			scope.Log("parse_executable: %v", err)
+			return
+		}
+
+		for _, filename := range arg.Files {
+			os_path, err := accessor.ParsePath(filename)
+			if err != nil {
+				scope.Log("parse_executable: %v", err)
+				continue
+			}
+
+			results, err := parseExecutable(scope, arg.Accessor, os_path)
+			if err != nil {
+				// Non executable files are silently skipped.
+				continue
+			}
+
+			for _, result := range results {
+				select {
+				case <-ctx.Done():
+					return
+				case output_chan <- result:
+				}
+			}
+		}
+	}()
+
+	return output_chan
+}
+
+func (self ParseExecutablePlugin) Info(
+	scope vfilter.Scope, type_map *vfilter.TypeMap) *vfilter.PluginInfo {
+	return &vfilter.PluginInfo{
+		Name:    "parse_executable",
+		Doc:     "Extract metadata from PE, ELF and Mach-O executables.",
+		ArgType: type_map.AddType(scope, &ParseExecutableArgs{}),
+	}
+}
+
+var notExecutableError = errors.New("Not an executable")
+
+// Universal Mach-O binaries produce a result for each architecture.
+func parseExecutable(scope vfilter.Scope,
+	accessor string, os_path *accessors.OSPath) (
+	results []*ExecutableInfo, err error) {
+	defer func() {
+		recovered := utils.RecoverVQL(scope)
+		if recovered != nil {
+			err = recovered
+		}
+	}()
+
+	lru_size := vql_subsystem.GetIntFromRow(scope, scope, constants.BINARY_CACHE_SIZE)
+	reader, err := readers.NewPagedReader(scope, accessor, os_path, int(lru_size))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	magic := make([]byte, 4)
+	n, err := reader.ReadAt(magic, 0)
+	if n < len(magic) {
+		if err == nil || errors.Is(err, io.EOF) {
+			err = notExecutableError
+		}
+		return nil, err
+	}
+
+	switch {
+	case string(magic[:2]) == "MZ":
+		result, err := parsePE(reader, os_path)
+		if err != nil {
+			return nil, err
+		}
+		return []*ExecutableInfo{result}, nil
+
+	case string(magic) == "\x7fELF":
+		result, err := parseELF(reader, os_path)
+		if err != nil {
+			return nil, err
+		}
+		return []*ExecutableInfo{result}, nil
+	}
+
+	switch binary.BigEndian.Uint32(magic) {
+	case 0xfeedface, 0xfeedfacf, 0xcefaedfe, 0xcffaedfe, 0xcafebabe:
+		return parseMachO(reader, os_path)
+	}
+
+	return nil, notExecutableError
+}
+
+func init() {
+	vql_subsystem.RegisterPlugin(&ParseExecutablePlugin{})
+}
@@ -0,0 +1,101 @@
+package executables
+
+import (
+	"debug/macho"
+	"io"
+
+	"www.velocidex.com/golang/velociraptor/accessors"
+)
+
+const (
+	LC_MAIN = 0x80000028
+
+	N_EXT  = 0x01
+	N_TYPE = 0x0e
+	N_SECT = 0x0e
+)
+
+func parseMachO(reader io.ReaderAt, os_path *accessors.OSPath) (
+	[]*ExecutableInfo, error) {
+	fat, err := macho.NewFatFile(reader)
+	if err == nil {
+		results := make([]*ExecutableInfo, 0, len(fat.Arches))
+		for _, arch := range fat.Arches {
+			results = append(results, parseMachOFile(arch.File, os_path))
+		}
+		return results, nil
+	}
+
+	file, err := macho.NewFile(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*ExecutableInfo{parseMachOFile(file, os_path)}, nil
+}
+
+func parseMachOFile(file *macho.File, os_path *accessors.OSPath) *ExecutableInfo {
+	result := newExecutableInfo(os_path, "MachO")
+	result.Machine = file.Cpu.String()
+	result.Type = file.Type.String()
+
+	for _, load := range file.Loads {
+		raw := load.Raw()
+		if len(raw) >= 16 && file.ByteOrder.Uint32(raw) == LC_MAIN {
+			// The entry point is stored as a file offset.
+			result.EntryPoint = file.ByteOrder.Uint64(raw[8:])
+		}
+	}
+
+	segment_perms := make(map[string]string)
+	for _, load := range file.Loads {
+		segment, ok := load.(*macho.Segment)
+		if ok {
+			segment_perms[segment.Name] = machoPerm(segment.Prot)
+		}
+	}
+
+	for _, section := range file.Sections {
+		result.Sections = append(result.Sections, &Section{
+			Name:           section.Seg + "," + section.Name,
+			FileOffset:     int64(section.Offset),
+			Size:           int64(section.Size),
+			VirtualAddress: section.Addr,
+			Perm:           segment_perms[section.Seg],
+		})
+	}
+
+	libraries, err := file.ImportedLibraries()
+	if err == nil && libraries != nil {
+		result.Libraries = libraries
+	}
+
+	imports, err := file.ImportedSymbols()
+	if err == nil && imports != nil {
+		result.Imports = imports
+	}
+
+	if file.Symtab != nil {
+		for _, symbol := range file.Symtab.Syms {
+			if symbol.Type&N_EXT != 0 && symbol.Type&N_TYPE == N_SECT {
+				result.Exports = append(result.Exports, symbol.Name)
+			}
+		}
+	}
+
+	return result
+}
+
+func machoPerm(prot uint32) string {
+	perm := []byte("---")
+	if prot&1 != 0 {
+		perm[0] = 'r'
+	}
+	if prot&2 != 0 {
+		perm[1] = 'w'
+	}
+	if prot&4 != 0 {
+		perm[2] = 'x'
+	}
+	return string(perm)
+}
@@ -0,0 +1,175 @@
+package executables
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+
+	pe "www.velocidex.com/golang/go-pe"
+	"www.velocidex.com/golang/velociraptor/accessors"
+)
+
+const (
+	IMAGE_FILE_DLL = 0x2000
+
+	// The rich header lives in the DOS stub so it can not be larger
+	// than this.
+	MAX_DOS_STUB_SIZE = 4096
+)
+
+const (
+	richMarker = "Rich"
+	dansMarker = 0x536e6144 // "DanS"
+)
+
+type RichEntry struct {
+	ProductId uint16
+	Build     uint16
+	Count     uint32
+}
+
+// The rich header records the tools used to build the binary. It is
+// obfuscated with a key derived from the DOS header. The hash is the
+// MD5 of the decoded header and is often used to cluster binaries
+// built in the same environment.
+type RichHeader struct {
+	Key     string
+	Hash    string
+	Entries []*RichEntry
+}
+
+func parsePE(reader io.ReaderAt, os_path *accessors.OSPath) (*ExecutableInfo, error) {
+	pe_file, err := pe.NewPEFile(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	result := newExecutableInfo(os_path, "PE")
+	result.Machine = pe_file.FileHeader.Machine
+	result.Compiled = pe_file.FileHeader.TimeDateStamp
+	result.Imports = pe_file.Imports()
+	result.Exports = pe_file.Exports()
+	result.ImpHash = pe_file.ImpHash()
+	result.VersionInformation = pe_file.VersionInformation()
+
+	result.Type = "Executable"
+	if pe_file.FileHeader.Characteristics&IMAGE_FILE_DLL != 0 {
+		result.Type = "DLL"
+	}
+
+	for _, section := range pe_file.Sections {
+		result.Sections = append(result.Sections, &Section{
+			Name:           section.Name,
+			FileOffset:     section.FileOffset,
+			Size:           section.Size,
+			VirtualAddress: section.VMA,
+			Perm:           section.Perm,
+		})
+	}
+
+	seen := make(map[string]bool)
+	for _, imp := range result.Imports {
+		dll := strings.SplitN(imp, "!", 2)[0]
+		if !seen[dll] {
+			seen[dll] = true
+			result.Libraries = append(result.Libraries, dll)
+		}
+	}
+
+	pe_header_offset, entry_point, err := parsePEHeaderOffsets(reader)
+	if err == nil {
+		result.EntryPoint = entry_point
+
+		rich_header, err := parseRichHeader(reader, pe_header_offset)
+		if err == nil {
+			result.RichHeader = rich_header
+		}
+	}
+
+	return result, nil
+}
+
+// Returns the offset of the PE header and the RVA of the entry point.
+func parsePEHeaderOffsets(reader io.ReaderAt) (int64, uint64, error) {
+	buf := make([]byte, 4)
+	_, err := reader.ReadAt(buf, 0x3c)
+	if err != nil {
+		return 0, 0, err
+	}
+	pe_header_offset := int64(binary.LittleEndian.Uint32(buf))
+
+	// The optional header follows the PE signature and the file
+	// header. AddressOfEntryPoint is at the same offset for PE32
+	// and PE32+.
+	_, err = reader.ReadAt(buf, pe_header_offset+4+20+16)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return pe_header_offset, uint64(binary.LittleEndian.Uint32(buf)), nil
+}
+
+func parseRichHeader(reader io.ReaderAt, pe_header_offset int64) (*RichHeader, error) {
+	if pe_header_offset <= 0 || pe_header_offset > MAX_DOS_STUB_SIZE {
+		return nil, fmt.Errorf("Invalid PE header offset %v", pe_header_offset)
+	}
+
+	stub := make([]byte, pe_header_offset)
+	_, err := reader.ReadAt(stub, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// The header is terminated by "Rich" followed by the key.
+	rich_offset := -1
+	for i := 0x40; i+8 <= len(stub); i += 4 {
+		if string(stub[i:i+4]) == richMarker {
+			rich_offset = i
+			break
+		}
+	}
+	if rich_offset < 0 {
+		return nil, fmt.Errorf("No rich header")
+	}
+
+	key := binary.LittleEndian.Uint32(stub[rich_offset+4:])
+
+	// Walk backwards to find the start of the header.
+	dans_offset := -1
+	for i := rich_offset - 4; i >= 0x40; i -= 4 {
+		if binary.LittleEndian.Uint32(stub[i:])^key == dansMarker {
+			dans_offset = i
+			break
+		}
+	}
+	if dans_offset < 0 {
+		return nil, fmt.Errorf("No rich header")
+	}
+
+	clear_data := make([]byte, rich_offset-dans_offset)
+	for i := 0; i < len(clear_data); i += 4 {
+		binary.LittleEndian.PutUint32(clear_data[i:],
+			binary.LittleEndian.Uint32(stub[dans_offset+i:])^key)
+	}
+
+	result := &RichHeader{
+		Key:     fmt.Sprintf("%#08x", key),
+		Hash:    fmt.Sprintf("%x", md5.Sum(clear_data)),
+		Entries: []*RichEntry{},
+	}
+
+	// The DanS marker is followed by 3 padding words, then pairs of
+	// (comp id, count).
+	for i := 16; i+8 <= len(clear_data); i += 8 {
+		comp_id := binary.LittleEndian.Uint32(clear_data[i:])
+		result.Entries = append(result.Entries, &RichEntry{
+			ProductId: uint16(comp_id >> 16),
+			Build:     uint16(comp_id),
+			Count:     binary.LittleEndian.Uint32(clear_data[i+4:]),
+		})
+	}
+
+	return result, nil
+}
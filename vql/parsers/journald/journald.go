@@ -0,0 +1,225 @@
+package journald
+
+import (
+	"context"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	"www.velocidex.com/golang/velociraptor/accessors"
+	"www.velocidex.com/golang/velociraptor/utils"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/vfilter"
+	"www.velocidex.com/golang/vfilter/arg_parser"
+)
+
+type _ParseJournaldPluginArgs struct {
+	Filenames []string `vfilter:"required,field=filename,doc=A list of journal files to parse."`
+	Accessor  string   `vfilter:"optional,field=accessor,doc=The accessor to use."`
+	Cursor    string   `vfilter:"optional,field=cursor,doc=Only emit entries after this cursor (as emitted in the __CURSOR column)."`
+}
+
+type _ParseJournaldPlugin struct{}
+
+func (self _ParseJournaldPlugin) Call(
+	ctx context.Context,
+	scope vfilter.Scope,
+	args *ordereddict.Dict) <-chan vfilter.Row {
+	output_chan := make(chan vfilter.Row)
+
+	go func() {
+		defer close(output_chan)
+
+		arg := &_ParseJournaldPluginArgs{}
+		err := arg_parser.ExtractArgsWithContext(ctx, scope, args, arg)
+		if err != nil {
+			scope.Log("parse_journald: %v", err)
+			return
+		}
+
+		err = vql_subsystem.CheckFilesystemAccess(scope, arg.Accessor)
+		if err != nil {
+			scope.Log("parse_journald: %v", err)
+			return
+		}
+
+		accessor, err := accessors.GetAccessor(arg.Accessor, scope)
+		if err != nil {
+			scope.Log("parse_journald: %v", err)
+			return
+		}
+
+		var cursor *Cursor
+		if arg.Cursor != "" {
+			cursor, err = ParseCursor(arg.Cursor)
+			if err != nil {
+				scope.Log("parse_journald: %v", err)
+				return
+			}
+		}
+
+		for _, filename := range arg.Filenames {
+			_, err := readJournal(ctx, scope, accessor, filename, cursor,
+				output_chan)
+			if err != nil {
+				scope.Log("parse_journald: %v: %v", filename, err)
+			}
+		}
+	}()
+
+	return output_chan
+}
+
+func (self _ParseJournaldPlugin) Info(scope vfilter.Scope, type_map *vfilter.TypeMap) *vfilter.PluginInfo {
+	return &vfilter.PluginInfo{
+		Name:    "parse_journald",
+		Doc:     "Parse a systemd journal file.",
+		ArgType: type_map.AddType(scope, &_ParseJournaldPluginArgs{}),
+	}
+}
+
+type _WatchJournaldPlugin struct{}
+
+func (self _WatchJournaldPlugin) Call(
+	ctx context.Context,
+	scope vfilter.Scope,
+	args *ordereddict.Dict) <-chan vfilter.Row {
+	output_chan := make(chan vfilter.Row)
+
+	go func() {
+		defer close(output_chan)
+
+		arg := &_ParseJournaldPluginArgs{}
+		err := arg_parser.ExtractArgsWithContext(ctx, scope, args, arg)
+		if err != nil {
+			scope.Log("watch_journald: %v", err)
+			return
+		}
+
+		err = vql_subsystem.CheckFilesystemAccess(scope, arg.Accessor)
+		if err != nil {
+			scope.Log("watch_journald: %v", err)
+			return
+		}
+
+		accessor, err := accessors.GetAccessor(arg.Accessor, scope)
+		if err != nil {
+			scope.Log("watch_journald: %v", err)
+			return
+		}
+
+		// Each file keeps its own cursor. Without a cursor we start
+		// tailing from the current end of the journal.
+		cursors := make(map[string]*Cursor)
+		for _, filename := range arg.Filenames {
+			if arg.Cursor != "" {
+				cursor, err := ParseCursor(arg.Cursor)
+				if err != nil {
+					scope.Log("watch_journald: %v", err)
+					return
+				}
+				cursors[filename] = cursor
+				continue
+			}
+
+			cursor, err := findLastCursor(accessor, filename)
+			if err != nil {
+				scope.Log("watch_journald: %v: %v", filename, err)
+			}
+			cursors[filename] = cursor
+		}
+
+		for {
+			for _, filename := range arg.Filenames {
+				cursor, err := readJournal(ctx, scope, accessor, filename,
+					cursors[filename], output_chan)
+				if err != nil {
+					scope.Log("watch_journald: %v: %v", filename, err)
+				}
+				if cursor != nil {
+					cursors[filename] = cursor
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-time.After(3 * time.Second):
+			}
+		}
+	}()
+
+	return output_chan
+}
+
+func (self _WatchJournaldPlugin) Info(scope vfilter.Scope, type_map *vfilter.TypeMap) *vfilter.PluginInfo {
+	return &vfilter.PluginInfo{
+		Name: "watch_journald",
+		Doc: "Watch a systemd journal file and stream new entries " +
+			"from it. Entries are tracked using cursors.",
+		ArgType: type_map.AddType(scope, &_ParseJournaldPluginArgs{}),
+	}
+}
+
+// Emit all the entries after the cursor and return the cursor of the
+// last entry emitted (or the original cursor if there were none).
+func readJournal(
+	ctx context.Context,
+	scope vfilter.Scope,
+	accessor accessors.FileSystemAccessor,
+	filename string, cursor *Cursor,
+	output_chan chan vfilter.Row) (*Cursor, error) {
+
+	defer utils.RecoverVQL(scope)
+
+	fd, err := accessor.Open(filename)
+	if err != nil {
+		return cursor, err
+	}
+	defer fd.Close()
+
+	reader, err := NewJournalReader(utils.MakeReaderAtter(fd))
+	if err != nil {
+		return cursor, err
+	}
+
+	last_cursor := cursor
+	err = reader.Entries(cursor, func(
+		entry *ordereddict.Dict, entry_cursor *Cursor) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case output_chan <- entry:
+			last_cursor = entry_cursor
+			return true
+		}
+	})
+
+	return last_cursor, err
+}
+
+func findLastCursor(
+	accessor accessors.FileSystemAccessor, filename string) (*Cursor, error) {
+	fd, err := accessor.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	reader, err := NewJournalReader(utils.MakeReaderAtter(fd))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cursor{
+		SeqnumId: reader.seqnum_id,
+		Seqnum:   reader.Header.TailEntrySeqnum,
+		BootId:   reader.bootId(),
+		Realtime: reader.Header.TailEntryRealtime,
+	}, nil
+}
+
+func init() {
+	vql_subsystem.RegisterPlugin(&_ParseJournaldPlugin{})
+	vql_subsystem.RegisterPlugin(&_WatchJournaldPlugin{})
+}
@@ -0,0 +1,456 @@
+// Parse systemd journal files.
+
+// The journal file format is described in
+// https://systemd.io/JOURNAL_FILE_FORMAT/
+//
+// A journal file contains a header followed by a sequence of
+// objects. Each log entry is an ENTRY object which refers to a number
+// of DATA objects, each containing a single FIELD=value pair. The
+// entries are listed in order in a chain of ENTRY_ARRAY objects.
+
+package journald
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	"github.com/ulikunitz/xz"
+)
+
+const (
+	OBJECT_DATA        = 1
+	OBJECT_ENTRY       = 3
+	OBJECT_ENTRY_ARRAY = 6
+
+	OBJECT_COMPRESSED_XZ   = 1
+	OBJECT_COMPRESSED_LZ4  = 2
+	OBJECT_COMPRESSED_ZSTD = 4
+
+	HEADER_INCOMPATIBLE_COMPACT = 16
+
+	OBJECT_HEADER_SIZE = 16
+	ENTRY_HEADER_SIZE  = OBJECT_HEADER_SIZE + 48
+
+	// Do not trust objects larger than this.
+	MAX_OBJECT_SIZE = 16 * 1024 * 1024
+)
+
+var (
+	journalSignature = []byte("LPKSHHRH")
+)
+
+type JournalHeader struct {
+	Signature            [8]byte
+	CompatibleFlags      uint32
+	IncompatibleFlags    uint32
+	State                uint8
+	Reserved             [7]byte
+	FileId               [16]byte
+	MachineId            [16]byte
+	TailEntryBootId      [16]byte
+	SeqnumId             [16]byte
+	HeaderSize           uint64
+	ArenaSize            uint64
+	DataHashTableOffset  uint64
+	DataHashTableSize    uint64
+	FieldHashTableOffset uint64
+	FieldHashTableSize   uint64
+	TailObjectOffset     uint64
+	NObjects             uint64
+	NEntries             uint64
+	TailEntrySeqnum      uint64
+	HeadEntrySeqnum      uint64
+	EntryArrayOffset     uint64
+	HeadEntryRealtime    uint64
+	TailEntryRealtime    uint64
+	TailEntryMonotonic   uint64
+}
+
+type objectHeader struct {
+	Type     uint8
+	Flags    uint8
+	Reserved [6]byte
+	Size     uint64
+}
+
+type entryHeader struct {
+	objectHeader
+	Seqnum    uint64
+	Realtime  uint64
+	Monotonic uint64
+	BootId    [16]byte
+	XorHash   uint64
+}
+
+// A journal cursor identifies an entry in the journal. It uses the
+// same format as journalctl so cursors can be used interchangeably.
+type Cursor struct {
+	SeqnumId  string
+	Seqnum    uint64
+	BootId    string
+	Monotonic uint64
+	Realtime  uint64
+	XorHash   uint64
+}
+
+func (self *Cursor) String() string {
+	return fmt.Sprintf("s=%s;i=%x;b=%s;m=%x;t=%x;x=%x",
+		self.SeqnumId, self.Seqnum, self.BootId,
+		self.Monotonic, self.Realtime, self.XorHash)
+}
+
+// Is the entry described by seqnum_id, seqnum and realtime after this
+// cursor? Sequence numbers are only comparable within the same
+// sequence number space, otherwise we fall back to the timestamp.
+func (self *Cursor) Before(seqnum_id string, seqnum, realtime uint64) bool {
+	if self.SeqnumId == seqnum_id {
+		return seqnum > self.Seqnum
+	}
+	return realtime > self.Realtime
+}
+
+func ParseCursor(cursor string) (*Cursor, error) {
+	result := &Cursor{}
+	for _, part := range strings.Split(cursor, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("Invalid cursor %v", cursor)
+		}
+
+		var err error
+		switch kv[0] {
+		case "s":
+			result.SeqnumId = kv[1]
+		case "b":
+			result.BootId = kv[1]
+		case "i":
+			result.Seqnum, err = strconv.ParseUint(kv[1], 16, 64)
+		case "m":
+			result.Monotonic, err = strconv.ParseUint(kv[1], 16, 64)
+		case "t":
+			result.Realtime, err = strconv.ParseUint(kv[1], 16, 64)
+		case "x":
+			result.XorHash, err = strconv.ParseUint(kv[1], 16, 64)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Invalid cursor %v: %w", cursor, err)
+		}
+	}
+
+	if result.SeqnumId == "" && result.Realtime == 0 {
+		return nil, fmt.Errorf("Invalid cursor %v", cursor)
+	}
+
+	return result, nil
+}
+
+type JournalReader struct {
+	reader  io.ReaderAt
+	Header  JournalHeader
+	compact bool
+
+	seqnum_id string
+}
+
+func NewJournalReader(reader io.ReaderAt) (*JournalReader, error) {
+	result := &JournalReader{reader: reader}
+	err := binary.Read(io.NewSectionReader(reader, 0, 1024),
+		binary.LittleEndian, &result.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(result.Header.Signature[:], journalSignature) {
+		return nil, errors.New("Not a journal file")
+	}
+
+	result.compact = result.Header.IncompatibleFlags&
+		HEADER_INCOMPATIBLE_COMPACT != 0
+	result.seqnum_id = fmt.Sprintf("%x", result.Header.SeqnumId)
+
+	return result, nil
+}
+
+// Walk the entry arrays and call cb with the offset of each entry in
+// order. Stop when cb returns false.
+func (self *JournalReader) walkEntryOffsets(cb func(offset uint64) bool) error {
+	item_size := uint64(8)
+	if self.compact {
+		item_size = 4
+	}
+
+	remaining := self.Header.NEntries
+	array_offset := self.Header.EntryArrayOffset
+	for array_offset != 0 && remaining > 0 {
+		header := objectHeader{}
+		err := self.readStruct(array_offset, &header)
+		if err != nil {
+			return err
+		}
+
+		if header.Type != OBJECT_ENTRY_ARRAY ||
+			header.Size < OBJECT_HEADER_SIZE+8 ||
+			header.Size > MAX_OBJECT_SIZE {
+			return fmt.Errorf("Invalid entry array at %#x", array_offset)
+		}
+
+		buf := make([]byte, header.Size-OBJECT_HEADER_SIZE)
+		_, err = self.reader.ReadAt(buf, int64(array_offset+OBJECT_HEADER_SIZE))
+		if err != nil {
+			return err
+		}
+
+		next_array := binary.LittleEndian.Uint64(buf)
+		items := buf[8:]
+		for i := uint64(0); i+item_size <= uint64(len(items)) && remaining > 0; i += item_size {
+			var offset uint64
+			if self.compact {
+				offset = uint64(binary.LittleEndian.Uint32(items[i:]))
+			} else {
+				offset = binary.LittleEndian.Uint64(items[i:])
+			}
+
+			// Unused slots at the end of the array.
+			if offset == 0 {
+				break
+			}
+
+			remaining--
+			if !cb(offset) {
+				return nil
+			}
+		}
+
+		array_offset = next_array
+	}
+
+	return nil
+}
+
+// Call cb for every entry after the cursor (or all entries if cursor
+// is nil).
+func (self *JournalReader) Entries(
+	cursor *Cursor, cb func(entry *ordereddict.Dict, cursor *Cursor) bool) error {
+	return self.walkEntryOffsets(func(offset uint64) bool {
+		header := entryHeader{}
+		err := self.readStruct(offset, &header)
+		if err != nil || header.Type != OBJECT_ENTRY {
+			return true
+		}
+
+		if cursor != nil &&
+			!cursor.Before(self.seqnum_id, header.Seqnum, header.Realtime) {
+			return true
+		}
+
+		entry_cursor := &Cursor{
+			SeqnumId:  self.seqnum_id,
+			Seqnum:    header.Seqnum,
+			BootId:    fmt.Sprintf("%x", header.BootId),
+			Monotonic: header.Monotonic,
+			Realtime:  header.Realtime,
+			XorHash:   header.XorHash,
+		}
+
+		entry, err := self.parseEntry(offset, &header, entry_cursor)
+		if err != nil {
+			return true
+		}
+
+		return cb(entry, entry_cursor)
+	})
+}
+
+func (self *JournalReader) parseEntry(
+	offset uint64, header *entryHeader,
+	cursor *Cursor) (*ordereddict.Dict, error) {
+	if header.Size < ENTRY_HEADER_SIZE || header.Size > MAX_OBJECT_SIZE {
+		return nil, fmt.Errorf("Invalid entry at %#x", offset)
+	}
+
+	result := ordereddict.NewDict().
+		Set("__CURSOR", cursor.String()).
+		Set("__REALTIME_TIMESTAMP", time.Unix(0, int64(header.Realtime)*1000).UTC()).
+		Set("__MONOTONIC_TIMESTAMP", header.Monotonic).
+		Set("__SEQNUM", header.Seqnum).
+		Set("_BOOT_ID", cursor.BootId)
+
+	buf := make([]byte, header.Size-ENTRY_HEADER_SIZE)
+	_, err := self.reader.ReadAt(buf, int64(offset+ENTRY_HEADER_SIZE))
+	if err != nil {
+		return nil, err
+	}
+
+	item_size := 16
+	if self.compact {
+		item_size = 4
+	}
+
+	for i := 0; i+item_size <= len(buf); i += item_size {
+		var data_offset uint64
+		if self.compact {
+			data_offset = uint64(binary.LittleEndian.Uint32(buf[i:]))
+		} else {
+			data_offset = binary.LittleEndian.Uint64(buf[i:])
+		}
+
+		payload, err := self.readData(data_offset)
+		if err != nil {
+			continue
+		}
+
+		kv := bytes.SplitN(payload, []byte("="), 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		// Fields may be repeated - keep the first one.
+		key := string(kv[0])
+		_, pres := result.Get(key)
+		if !pres {
+			result.Set(key, string(kv[1]))
+		}
+	}
+
+	return result, nil
+}
+
+// Read the payload of a data object.
+func (self *JournalReader) readData(offset uint64) ([]byte, error) {
+	header := objectHeader{}
+	err := self.readStruct(offset, &header)
+	if err != nil {
+		return nil, err
+	}
+
+	// The payload follows hash, next_hash_offset,
+	// next_field_offset, entry_offset, entry_array_offset and
+	// n_entries. Compact files have two extra 32 bit fields.
+	payload_offset := uint64(OBJECT_HEADER_SIZE + 48)
+	if self.compact {
+		payload_offset += 8
+	}
+
+	if header.Type != OBJECT_DATA ||
+		header.Size < payload_offset || header.Size > MAX_OBJECT_SIZE {
+		return nil, fmt.Errorf("Invalid data object at %#x", offset)
+	}
+
+	buf := make([]byte, header.Size-payload_offset)
+	_, err = self.reader.ReadAt(buf, int64(offset+payload_offset))
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case header.Flags&OBJECT_COMPRESSED_XZ != 0:
+		reader, err := xz.NewReader(bytes.NewReader(buf))
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.ReadAll(io.LimitReader(reader, MAX_OBJECT_SIZE))
+
+	case header.Flags&OBJECT_COMPRESSED_LZ4 != 0:
+		return decompressLZ4(buf)
+
+	case header.Flags&OBJECT_COMPRESSED_ZSTD != 0:
+		return nil, errors.New("zstd compression is not supported")
+	}
+
+	return buf, nil
+}
+
+func (self *JournalReader) readStruct(offset uint64, target interface{}) error {
+	return binary.Read(
+		io.NewSectionReader(self.reader, int64(offset), int64(binary.Size(target))),
+		binary.LittleEndian, target)
+}
+
+// Journald stores LZ4 data as a 64 bit uncompressed size followed by
+// a raw LZ4 block.
+func decompressLZ4(buf []byte) ([]byte, error) {
+	if len(buf) < 8 {
+		return nil, errors.New("LZ4: Short buffer")
+	}
+
+	size := binary.LittleEndian.Uint64(buf)
+	if size > MAX_OBJECT_SIZE {
+		return nil, errors.New("LZ4: Buffer too large")
+	}
+
+	src := buf[8:]
+	dst := make([]byte, 0, size)
+
+	for i := 0; i < len(src); {
+		token := src[i]
+		i++
+
+		// Literals
+		literals := int(token >> 4)
+		if literals == 15 {
+			for i < len(src) {
+				b := src[i]
+				i++
+				literals += int(b)
+				if b != 255 {
+					break
+				}
+			}
+		}
+		if i+literals > len(src) {
+			return nil, errors.New("LZ4: Corrupt literals")
+		}
+		dst = append(dst, src[i:i+literals]...)
+		i += literals
+
+		// The last sequence has no match.
+		if i >= len(src) {
+			break
+		}
+
+		if i+2 > len(src) {
+			return nil, errors.New("LZ4: Corrupt offset")
+		}
+		match_offset := int(src[i]) | int(src[i+1])<<8
+		i += 2
+		if match_offset == 0 || match_offset > len(dst) {
+			return nil, errors.New("LZ4: Invalid offset")
+		}
+
+		match_length := int(token&0xf) + 4
+		if token&0xf == 15 {
+			for i < len(src) {
+				b := src[i]
+				i++
+				match_length += int(b)
+				if b != 255 {
+					break
+				}
+			}
+		}
+
+		if uint64(len(dst)+match_length) > size {
+			return nil, errors.New("LZ4: Output too large")
+		}
+
+		// Matches may overlap the output so copy byte by byte.
+		start := len(dst) - match_offset
+		for j := 0; j < match_length; j++ {
+			dst = append(dst, dst[start+j])
+		}
+	}
+
+	return dst, nil
+}
+
+func (self *JournalReader) bootId() string {
+	return fmt.Sprintf("%x", self.Header.TailEntryBootId)
+}
@@ -0,0 +1,146 @@
+package journald
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/Velocidex/ordereddict"
+	"github.com/stretchr/testify/assert"
+)
+
+// Build a minimal uncompressed journal file containing the entries.
+func buildJournal(seqnum_id byte, first_seqnum uint64, entries [][]string) []byte {
+	buf := &bytes.Buffer{}
+	header := JournalHeader{
+		HeaderSize: uint64(binary.Size(JournalHeader{})),
+		NEntries:   uint64(len(entries)),
+	}
+	copy(header.Signature[:], journalSignature)
+	header.SeqnumId[0] = seqnum_id
+	binary.Write(buf, binary.LittleEndian, &header)
+
+	align := func() {
+		for buf.Len()%8 != 0 {
+			buf.WriteByte(0)
+		}
+	}
+
+	entry_offsets := []uint64{}
+	for i, fields := range entries {
+		data_offsets := []uint64{}
+		for _, field := range fields {
+			align()
+			data_offsets = append(data_offsets, uint64(buf.Len()))
+			binary.Write(buf, binary.LittleEndian, &objectHeader{
+				Type: OBJECT_DATA,
+				Size: uint64(OBJECT_HEADER_SIZE + 48 + len(field)),
+			})
+			buf.Write(make([]byte, 48))
+			buf.WriteString(field)
+		}
+
+		align()
+		entry_offsets = append(entry_offsets, uint64(buf.Len()))
+		entry := entryHeader{
+			objectHeader: objectHeader{
+				Type: OBJECT_ENTRY,
+				Size: uint64(ENTRY_HEADER_SIZE + 16*len(data_offsets)),
+			},
+			Seqnum:    first_seqnum + uint64(i),
+			Realtime:  1600000000000000 + uint64(i)*1000000,
+			Monotonic: uint64(i),
+		}
+		binary.Write(buf, binary.LittleEndian, &entry)
+		for _, offset := range data_offsets {
+			binary.Write(buf, binary.LittleEndian, offset)
+			binary.Write(buf, binary.LittleEndian, uint64(0))
+		}
+	}
+
+	align()
+	array_offset := uint64(buf.Len())
+	binary.Write(buf, binary.LittleEndian, &objectHeader{
+		Type: OBJECT_ENTRY_ARRAY,
+		Size: uint64(OBJECT_HEADER_SIZE + 8 + 8*len(entry_offsets)),
+	})
+	binary.Write(buf, binary.LittleEndian, uint64(0))
+	for _, offset := range entry_offsets {
+		binary.Write(buf, binary.LittleEndian, offset)
+	}
+
+	result := buf.Bytes()
+	binary.LittleEndian.PutUint64(
+		result[binary.Size(JournalHeader{})-32:], array_offset)
+	return result
+}
+
+func readAll(t *testing.T, data []byte, cursor *Cursor) (
+	[]*ordereddict.Dict, []*Cursor) {
+	reader, err := NewJournalReader(bytes.NewReader(data))
+	assert.NoError(t, err)
+
+	entries := []*ordereddict.Dict{}
+	cursors := []*Cursor{}
+	err = reader.Entries(cursor, func(
+		entry *ordereddict.Dict, entry_cursor *Cursor) bool {
+		entries = append(entries, entry)
+		cursors = append(cursors, entry_cursor)
+		return true
+	})
+	assert.NoError(t, err)
+
+	return entries, cursors
+}
+
+func TestJournalReader(t *testing.T) {
+	data := buildJournal(1, 10, [][]string{
+		{"MESSAGE=Started session", "_PID=1"},
+		{"MESSAGE=Accepted password", "_COMM=sshd"},
+		{"MESSAGE=Stopped session", "_PID=1"},
+	})
+
+	entries, cursors := readAll(t, data, nil)
+	assert.Equal(t, 3, len(entries))
+
+	message, _ := entries[1].GetString("MESSAGE")
+	assert.Equal(t, "Accepted password", message)
+
+	comm, _ := entries[1].GetString("_COMM")
+	assert.Equal(t, "sshd", comm)
+
+	seqnum, _ := entries[2].Get("__SEQNUM")
+	assert.Equal(t, uint64(12), seqnum)
+
+	// Resume from the first entry's cursor.
+	cursor, err := ParseCursor(cursors[0].String())
+	assert.NoError(t, err)
+	assert.Equal(t, cursors[0], cursor)
+
+	entries, _ = readAll(t, data, cursor)
+	assert.Equal(t, 2, len(entries))
+	message, _ = entries[0].GetString("MESSAGE")
+	assert.Equal(t, "Accepted password", message)
+
+	// A cursor from a different sequence number space falls back to
+	// timestamps.
+	other := buildJournal(2, 100, [][]string{
+		{"MESSAGE=One"}, {"MESSAGE=Two"}, {"MESSAGE=Three"},
+	})
+	entries, _ = readAll(t, other, cursors[1])
+	assert.Equal(t, 1, len(entries))
+	message, _ = entries[0].GetString("MESSAGE")
+	assert.Equal(t, "Three", message)
+}
+
+func TestDecompressLZ4(t *testing.T) {
+	// "abcabcabcabc" as a literal "abc" followed by a 9 byte match
+	// at offset 3.
+	block := []byte{
+		12, 0, 0, 0, 0, 0, 0, 0,
+		0x35, 'a', 'b', 'c', 3, 0,
+	}
+	result, err := decompressLZ4(block)
+	assert.NoError(t, err)
+	assert.Equal(t, "abcabcabcabc", string(result))
+}
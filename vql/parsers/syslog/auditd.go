@@ -15,15 +15,38 @@ import (
 	"github.com/elastic/go-libaudit/auparse"
 	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
 	"www.velocidex.com/golang/vfilter"
+	"www.velocidex.com/golang/vfilter/arg_parser"
 )
 
+type AuditdPluginArgs struct {
+	Filenames  []string `vfilter:"required,field=filename,doc=A list of log files to parse."`
+	Accessor   string   `vfilter:"optional,field=accessor,doc=The accessor to use."`
+	BufferSize int      `vfilter:"optional,field=buffer_size,doc=Maximum size of line buffer."`
+	ResolveIds bool     `vfilter:"optional,field=resolve_ids,doc=Resolve numeric user and group IDs to names (like ausearch -i)."`
+	Raw        bool     `vfilter:"optional,field=raw,doc=Also include the interpreted fields of each record making up the event."`
+}
+
+// The args passed to the line scanner.
+func (self *AuditdPluginArgs) scannerArgs() *ordereddict.Dict {
+	return ordereddict.NewDict().
+		Set("filename", self.Filenames).
+		Set("accessor", self.Accessor).
+		Set("buffer_size", self.BufferSize)
+}
+
+// An auditd event together with the records it was assembled from.
+type auditdEvent struct {
+	*aucoalesce.Event
+	Records []map[string]interface{} `json:"records"`
+}
+
 type AuditdPlugin struct{}
 
 func (self AuditdPlugin) Info(scope vfilter.Scope, type_map *vfilter.TypeMap) *vfilter.PluginInfo {
 	return &vfilter.PluginInfo{
 		Name:    "parse_auditd",
 		Doc:     "Parse log files generated by auditd.",
-		ArgType: type_map.AddType(scope, &ScannerPluginArgs{}),
+		ArgType: type_map.AddType(scope, &AuditdPluginArgs{}),
 	}
 }
 
@@ -35,8 +58,15 @@ func (self AuditdPlugin) Call(
 	go func() {
 		defer close(output_chan)
 
+		arg := &AuditdPluginArgs{}
+		err := arg_parser.ExtractArgsWithContext(ctx, scope, args, arg)
+		if err != nil {
+			scope.Log("parse_auditd: %v", err)
+			return
+		}
+
 		reassembler, err := libaudit.NewReassembler(5, 2*time.Second,
-			&streamHandler{scope: scope, ctx: ctx, output_chan: output_chan})
+			newStreamHandler(ctx, scope, arg, output_chan))
 		if err != nil {
 			scope.Log("parse_auditd: %v", err)
 			return
@@ -61,7 +91,7 @@ func (self AuditdPlugin) Call(
 		}()
 
 		scanner := ScannerPlugin{}
-		for row := range scanner.Call(ctx, scope, args) {
+		for row := range scanner.Call(ctx, scope, arg.scannerArgs()) {
 			line, pres := scope.Associative(row, "Line")
 			if !pres {
 				continue
@@ -81,6 +111,30 @@ type streamHandler struct {
 	scope       vfilter.Scope
 	ctx         context.Context
 	output_chan chan vfilter.Row
+
+	resolve_ids bool
+	raw         bool
+	users       *aucoalesce.UserCache
+	groups      *aucoalesce.GroupCache
+}
+
+func newStreamHandler(
+	ctx context.Context, scope vfilter.Scope,
+	arg *AuditdPluginArgs, output_chan chan vfilter.Row) *streamHandler {
+	result := &streamHandler{
+		scope:       scope,
+		ctx:         ctx,
+		output_chan: output_chan,
+		resolve_ids: arg.ResolveIds,
+		raw:         arg.Raw,
+	}
+
+	if arg.ResolveIds {
+		result.users = aucoalesce.NewUserCache(time.Minute)
+		result.groups = aucoalesce.NewGroupCache(time.Minute)
+	}
+
+	return result
 }
 
 func (self *streamHandler) ReassemblyComplete(msgs []*auparse.AuditMessage) {
@@ -97,11 +151,25 @@ func (self *streamHandler) outputMultipleMessages(
 	if err != nil {
 		return
 	}
+
+	if self.resolve_ids {
+		aucoalesce.ResolveIDsFromCaches(event, self.users, self.groups)
+	}
+
+	var row vfilter.Row = event
+	if self.raw {
+		records := make([]map[string]interface{}, 0, len(msgs))
+		for _, msg := range msgs {
+			records = append(records, msg.ToMapStr())
+		}
+		row = &auditdEvent{Event: event, Records: records}
+	}
+
 	select {
 	case <-ctx.Done():
 		return
 
-	case self.output_chan <- event:
+	case self.output_chan <- row:
 	}
 }
 
@@ -111,7 +179,7 @@ func (self WatchAuditdPlugin) Info(scope vfilter.Scope, type_map *vfilter.TypeMa
 	return &vfilter.PluginInfo{
 		Name:    "watch_auditd",
 		Doc:     "Watch log files generated by auditd.",
-		ArgType: type_map.AddType(scope, &ScannerPluginArgs{}),
+		ArgType: type_map.AddType(scope, &AuditdPluginArgs{}),
 	}
 }
 
@@ -123,8 +191,15 @@ func (self WatchAuditdPlugin) Call(
 	go func() {
 		defer close(output_chan)
 
+		arg := &AuditdPluginArgs{}
+		err := arg_parser.ExtractArgsWithContext(ctx, scope, args, arg)
+		if err != nil {
+			scope.Log("watch_auditd: %v", err)
+			return
+		}
+
 		reassembler, err := libaudit.NewReassembler(5, 2*time.Second,
-			&streamHandler{scope: scope, ctx: ctx, output_chan: output_chan})
+			newStreamHandler(ctx, scope, arg, output_chan))
 		if err != nil {
 			scope.Log("watch_auditd: %v", err)
 			return
@@ -149,7 +224,7 @@ func (self WatchAuditdPlugin) Call(
 		}()
 
 		scanner := _WatchSyslogPlugin{}
-		for row := range scanner.Call(ctx, scope, args) {
+		for row := range scanner.Call(ctx, scope, arg.scannerArgs()) {
 			line, pres := scope.Associative(row, "Line")
 			if !pres {
 				continue
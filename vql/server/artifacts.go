@@ -25,6 +25,8 @@ import (
 
 	"github.com/Velocidex/ordereddict"
 	"www.velocidex.com/golang/velociraptor/acls"
+	"www.velocidex.com/golang/velociraptor/allowlist"
+	"www.velocidex.com/golang/velociraptor/approvals"
 	"www.velocidex.com/golang/velociraptor/artifacts"
 	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
 	"www.velocidex.com/golang/velociraptor/json"
@@ -36,17 +38,22 @@ import (
 )
 
 type ScheduleCollectionFunctionArg struct {
-	ClientId     string      `vfilter:"required,field=client_id,doc=The client id to schedule a collection on"`
-	Artifacts    []string    `vfilter:"required,field=artifacts,doc=A list of artifacts to collect"`
-	Env          vfilter.Any `vfilter:"optional,field=env,doc=Parameters to apply to the artifact (an alternative to a full spec)"`
-	Spec         vfilter.Any `vfilter:"optional,field=spec,doc=Parameters to apply to the artifacts"`
-	Timeout      uint64      `vfilter:"optional,field=timeout,doc=Set query timeout (default 10 min)"`
-	OpsPerSecond float64     `vfilter:"optional,field=ops_per_sec,doc=Set query ops_per_sec value"`
-	CpuLimit     float64     `vfilter:"optional,field=cpu_limit,doc=Set query cpu_limit value"`
-	IopsLimit    float64     `vfilter:"optional,field=iops_limit,doc=Set query iops_limit value"`
-	MaxRows      uint64      `vfilter:"optional,field=max_rows,doc=Max number of rows to fetch"`
-	MaxBytes     uint64      `vfilter:"optional,field=max_bytes,doc=Max number of bytes to upload"`
-	Urgent       bool        `vfilter:"optional,field=urgent,doc=Set the collection as urgent - skips other queues collections on the client."`
+	ClientId      string      `vfilter:"required,field=client_id,doc=The client id to schedule a collection on"`
+	Artifacts     []string    `vfilter:"required,field=artifacts,doc=A list of artifacts to collect"`
+	Env           vfilter.Any `vfilter:"optional,field=env,doc=Parameters to apply to the artifact (an alternative to a full spec)"`
+	Spec          vfilter.Any `vfilter:"optional,field=spec,doc=Parameters to apply to the artifacts"`
+	Timeout       uint64      `vfilter:"optional,field=timeout,doc=Set query timeout (default 10 min)"`
+	OpsPerSecond  float64     `vfilter:"optional,field=ops_per_sec,doc=Set query ops_per_sec value"`
+	CpuLimit      float64     `vfilter:"optional,field=cpu_limit,doc=Set query cpu_limit value"`
+	IopsLimit     float64     `vfilter:"optional,field=iops_limit,doc=Set query iops_limit value"`
+	MaxRows       uint64      `vfilter:"optional,field=max_rows,doc=Max number of rows to fetch"`
+	MaxBytes      uint64      `vfilter:"optional,field=max_bytes,doc=Max number of bytes to upload"`
+	MaxMemory     uint64      `vfilter:"optional,field=max_memory,doc=Abort the queries when they use more than this many bytes of memory"`
+	Urgent        bool        `vfilter:"optional,field=urgent,doc=Set the collection as urgent - skips other queues collections on the client."`
+	ApprovalId    string      `vfilter:"optional,field=approval_id,doc=An approval granted by a second user for collections using dangerous plugins"`
+	SamplePercent float64     `vfilter:"optional,field=sample_percent,doc=Only return this percentage of rows from each source"`
+	SampleRows    uint64      `vfilter:"optional,field=sample_rows,doc=Only return the first N rows from each source"`
+	ContinueFrom  string      `vfilter:"optional,field=continue_from,doc=Resume a flow on this client which was truncated by its row limit. The sources must return rows in the same order each time"`
 }
 
 type ScheduleCollectionFunction struct{}
@@ -112,7 +119,17 @@ func (self *ScheduleCollectionFunction) Call(ctx context.Context,
 		Timeout:        arg.Timeout,
 		MaxRows:        arg.MaxRows,
 		MaxUploadBytes: arg.MaxBytes,
+		MaxMemory:      arg.MaxMemory,
 		Urgent:         arg.Urgent,
+		ApprovalId:     arg.ApprovalId,
+		SamplePercent:  float32(arg.SamplePercent),
+		SampleRows:     arg.SampleRows,
+	}
+
+	if arg.ContinueFrom != "" {
+		request.ContinueFrom = &flows_proto.FlowContinuation{
+			FlowId: arg.ContinueFrom,
+		}
 	}
 
 	if arg.Spec == nil {
@@ -132,6 +149,19 @@ func (self *ScheduleCollectionFunction) Call(ctx context.Context,
 		return vfilter.Null{}
 	}
 
+	err = allowlist.CheckClient(config_obj, request.ClientId, request.Artifacts)
+	if err != nil {
+		scope.Log("collect_client: %v", err)
+		return vfilter.Null{}
+	}
+
+	err = approvals.CheckCollection(config_obj, repository,
+		request.Creator, request)
+	if err != nil {
+		scope.Log("collect_client: %v", err)
+		return vfilter.Null{}
+	}
+
 	result := &flows_proto.ArtifactCollectorResponse{Request: request}
 	acl_manager, ok := artifacts.GetACLManager(scope)
 	if !ok {
@@ -11,6 +11,7 @@ import (
 	"www.velocidex.com/golang/velociraptor/datastore"
 	"www.velocidex.com/golang/velociraptor/file_store"
 	"www.velocidex.com/golang/velociraptor/file_store/api"
+	"www.velocidex.com/golang/velociraptor/legal_hold"
 	"www.velocidex.com/golang/velociraptor/paths"
 	"www.velocidex.com/golang/velociraptor/services"
 	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
@@ -59,6 +60,14 @@ func (self DeleteClientPlugin) Call(ctx context.Context,
 			return
 		}
 
+		if arg.ReallyDoIt {
+			err = legal_hold.CheckClientHolds(config_obj, arg.ClientId)
+			if err != nil {
+				scope.Log("client_delete: %v", err)
+				return
+			}
+		}
+
 		file_store_factory := file_store.GetFileStore(config_obj)
 		client_path_manager := paths.NewClientPathManager(arg.ClientId)
 
@@ -173,6 +182,13 @@ func reallyDeleteClient(ctx context.Context,
 		return err
 	}
 
+	// Remember the pool before the record is removed.
+	ephemeral_pool := ""
+	info, err := client_info_manager.Get(arg.ClientId)
+	if err == nil {
+		ephemeral_pool = info.EphemeralPool
+	}
+
 	client_info_manager.Remove(arg.ClientId)
 
 	indexer, err := services.GetIndexer(config_obj)
@@ -208,6 +224,9 @@ func reallyDeleteClient(ctx context.Context,
 		keywords = append(keywords, "host:"+client_info.OsInfo.Hostname)
 		keywords = append(keywords, "host:"+client_info.OsInfo.Fqdn)
 	}
+	if ephemeral_pool != "" {
+		keywords = append(keywords, services.EPHEMERAL_POOL_PREFIX+ephemeral_pool)
+	}
 	for _, keyword := range keywords {
 		err = indexer.UnsetIndex(arg.ClientId, keyword)
 		if err != nil && errors.Is(err, os.ErrNotExist) {
@@ -4,14 +4,11 @@ package clients
 
 import (
 	"context"
-	"errors"
-	"os"
 
 	"github.com/Velocidex/ordereddict"
 	"www.velocidex.com/golang/velociraptor/acls"
 	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
-	"www.velocidex.com/golang/velociraptor/datastore"
-	"www.velocidex.com/golang/velociraptor/paths"
+	"www.velocidex.com/golang/velociraptor/services/client_info"
 	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
 	"www.velocidex.com/golang/vfilter"
 	"www.velocidex.com/golang/vfilter/arg_parser"
@@ -50,21 +47,12 @@ func (self *ClientMetadataFunction) Call(ctx context.Context,
 		return vfilter.Null{}
 	}
 
-	client_path_manager := paths.NewClientPathManager(arg.ClientId)
-	db, err := datastore.GetDB(config_obj)
+	result, err := client_info.GetMetadata(config_obj, arg.ClientId)
 	if err != nil {
 		scope.Log("client_metadata: %s", err.Error())
 		return vfilter.Null{}
 	}
 
-	result := &api_proto.ClientMetadata{}
-	err = db.GetSubject(config_obj,
-		client_path_manager.Metadata(), result)
-	if err != nil && !errors.Is(err, os.ErrNotExist) {
-		scope.Log("client_metadata: %s", err.Error())
-		return vfilter.Null{}
-	}
-
 	result_dict := ordereddict.NewDict()
 	for _, item := range result.Items {
 		result_dict.Set(item.Key, item.Value)
@@ -127,13 +115,6 @@ func (self *ClientSetMetadataFunction) Call(ctx context.Context,
 		return vfilter.Null{}
 	}
 
-	client_path_manager := paths.NewClientPathManager(client_id)
-	db, err := datastore.GetDB(config_obj)
-	if err != nil {
-		scope.Log("client_set_metadata: %s", err.Error())
-		return vfilter.Null{}
-	}
-
 	result := &api_proto.ClientMetadata{ClientId: client_id}
 
 	for _, key := range expanded_args.Keys() {
@@ -153,8 +134,7 @@ func (self *ClientSetMetadataFunction) Call(ctx context.Context,
 			Key: key, Value: value})
 	}
 
-	err = db.SetSubject(config_obj,
-		client_path_manager.Metadata(), result)
+	err = client_info.SetMetadata(config_obj, result)
 	if err != nil {
 		scope.Log("client_set_metadata: %s", err.Error())
 		return vfilter.Null{}
@@ -0,0 +1,114 @@
+package server
+
+// Push a config override to a client. The override is signed with
+// the CA key so the client can verify it came from the server, and
+// the client restarts to apply it. Each override replaces the
+// previous one so send all the settings which should be changed.
+
+import (
+	"context"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	"github.com/sirupsen/logrus"
+	"www.velocidex.com/golang/velociraptor/acls"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/constants"
+	crypto_proto "www.velocidex.com/golang/velociraptor/crypto/proto"
+	crypto_utils "www.velocidex.com/golang/velociraptor/crypto/utils"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/services"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/vfilter"
+	"www.velocidex.com/golang/vfilter/arg_parser"
+)
+
+type ClientConfigOverrideFunctionArgs struct {
+	ClientId           string   `vfilter:"required,field=client_id,doc=The client to update."`
+	MaxPoll            uint64   `vfilter:"optional,field=max_poll,doc=Maximum poll interval in seconds."`
+	MaxPollStd         uint64   `vfilter:"optional,field=max_poll_std,doc=Random jitter added to the poll interval in seconds."`
+	MaxUploadSize      uint64   `vfilter:"optional,field=max_upload_size,doc=Largest upload message size in bytes."`
+	MaxMemoryHardLimit uint64   `vfilter:"optional,field=max_memory_hard_limit,doc=The nanny restarts the client above this memory use."`
+	Concurrency        uint64   `vfilter:"optional,field=concurrency,doc=Number of queries the client runs at the same time."`
+	ExtraServerUrls    []string `vfilter:"optional,field=extra_server_urls,doc=Server urls to try after the ones in the client config."`
+}
+
+type ClientConfigOverrideFunction struct{}
+
+func (self *ClientConfigOverrideFunction) Call(ctx context.Context,
+	scope vfilter.Scope,
+	args *ordereddict.Dict) vfilter.Any {
+
+	err := vql_subsystem.CheckAccess(scope, acls.MACHINE_STATE)
+	if err != nil {
+		scope.Log("client_config_override: %s", err)
+		return vfilter.Null{}
+	}
+
+	arg := &ClientConfigOverrideFunctionArgs{}
+	err = arg_parser.ExtractArgsWithContext(ctx, scope, args, arg)
+	if err != nil {
+		scope.Log("client_config_override: %s", err.Error())
+		return vfilter.Null{}
+	}
+
+	config_obj, ok := vql_subsystem.GetServerConfig(scope)
+	if !ok {
+		scope.Log("Command can only run on the server")
+		return vfilter.Null{}
+	}
+
+	override := &config_proto.ClientConfigOverride{
+		Version:            uint64(time.Now().UnixNano()),
+		MaxPoll:            arg.MaxPoll,
+		MaxPollStd:         arg.MaxPollStd,
+		MaxUploadSize:      arg.MaxUploadSize,
+		MaxMemoryHardLimit: arg.MaxMemoryHardLimit,
+		Concurrency:        arg.Concurrency,
+		ExtraServerUrls:    arg.ExtraServerUrls,
+	}
+
+	err = crypto_utils.SignClientConfigOverride(config_obj.CA, override)
+	if err != nil {
+		scope.Log("client_config_override: %s", err.Error())
+		return vfilter.Null{}
+	}
+
+	client_manager, err := services.GetClientInfoManager(config_obj)
+	if err != nil {
+		scope.Log("client_config_override: %s", err.Error())
+		return vfilter.Null{}
+	}
+
+	err = client_manager.QueueMessageForClient(arg.ClientId,
+		&crypto_proto.VeloMessage{
+			UpdateConfig: override,
+			SessionId:    constants.MONITORING_WELL_KNOWN_FLOW,
+		}, true, nil)
+	if err != nil {
+		scope.Log("client_config_override: %s", err.Error())
+		return vfilter.Null{}
+	}
+
+	logging.GetLogger(config_obj, &logging.Audit).
+		WithFields(logrus.Fields{
+			"user":     vql_subsystem.GetPrincipal(scope),
+			"client":   arg.ClientId,
+			"override": override,
+		}).Info("ClientConfigOverride")
+
+	return override
+}
+
+func (self ClientConfigOverrideFunction) Info(
+	scope vfilter.Scope, type_map *vfilter.TypeMap) *vfilter.FunctionInfo {
+	return &vfilter.FunctionInfo{
+		Name:    "client_config_override",
+		Doc:     "Push signed client config changes to a client. The client restarts to apply them.",
+		ArgType: type_map.AddType(scope, &ClientConfigOverrideFunctionArgs{}),
+	}
+}
+
+func init() {
+	vql_subsystem.RegisterFunction(&ClientConfigOverrideFunction{})
+}
@@ -2,6 +2,7 @@ package downloads
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
@@ -15,6 +16,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"www.velocidex.com/golang/velociraptor/acls"
 	"www.velocidex.com/golang/velociraptor/actions"
+	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
 	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
 	"www.velocidex.com/golang/velociraptor/file_store"
 	"www.velocidex.com/golang/velociraptor/file_store/api"
@@ -36,10 +38,20 @@ type CreateFlowDownloadArgs struct {
 	ClientId string `vfilter:"required,field=client_id,doc=Client ID to export."`
 	FlowId   string `vfilter:"required,field=flow_id,doc=The flow id to export."`
 	Wait     bool   `vfilter:"optional,field=wait,doc=If set we wait for the download to complete before returning."`
-	Type     string `vfilter:"optional,field=type,doc=Type of download to create (e.g. 'report') default a full zip file."`
+	Type     string `vfilter:"optional,field=type,doc=Type of download to create (e.g. 'report', 'pdf' or 'stix') default a full zip file."`
 	Template string `vfilter:"optional,field=template,doc=Report template to use (defaults to Reporting.Default)."`
 	Password string `vfilter:"optional,field=password,doc=An optional password to encrypt the collection zip."`
 	Format   string `vfilter:"optional,field=format,doc=Format to export (csv,json) defaults to both."`
+
+	TemplateParameters *ordereddict.Dict `vfilter:"optional,field=template_parameters,doc=Override the template's parameters (e.g. Logo or Locale)."`
+
+	MaxRows   uint64      `vfilter:"optional,field=max_rows,doc=Only export this many rows from each result set."`
+	MaxBytes  uint64      `vfilter:"optional,field=max_bytes,doc=Stop exporting once this many bytes are written."`
+	Start     vfilter.Any `vfilter:"optional,field=start,doc=Only export the collection if it was created after this time."`
+	End       vfilter.Any `vfilter:"optional,field=end,doc=Only export the collection if it was created before this time."`
+	TimeField string      `vfilter:"optional,field=time_field,doc=If set, only export rows where this column is between start and end."`
+
+	Justification string `vfilter:"optional,field=justification,doc=Why the data is exported. This is recorded in the audit log and the export."`
 }
 
 type CreateFlowDownload struct{}
@@ -71,11 +83,20 @@ func (self *CreateFlowDownload) Call(ctx context.Context,
 		arg.Template = "Reporting.Default"
 	}
 
+	principal := vql_subsystem.GetPrincipal(scope)
+	auditExport(config_obj, principal, arg.Justification,
+		logrus.Fields{
+			"client_id": arg.ClientId,
+			"flow_id":   arg.FlowId,
+			"type":      arg.Type,
+		})
+
 	switch arg.Type {
-	case "report":
+	case "report", "pdf":
 		result, err := CreateFlowReport(
 			config_obj, scope, arg.FlowId, arg.ClientId,
-			arg.Template, arg.Wait)
+			arg.Template, arg.TemplateParameters,
+			arg.Type == "pdf", arg.Wait)
 		if err != nil {
 			scope.Log("create_flow_download: %s", err)
 			return vfilter.Null{}
@@ -89,8 +110,35 @@ func (self *CreateFlowDownload) Call(ctx context.Context,
 			return vfilter.Null{}
 		}
 
+		options, err := NewExportOptions(scope, arg.MaxRows, arg.MaxBytes,
+			arg.Start, arg.End, arg.TimeField)
+		if err != nil {
+			scope.Log("create_flow_download: %v", err)
+			return vfilter.Null{}
+		}
+		options.Principal = principal
+		options.Justification = arg.Justification
+
 		result, err := createDownloadFile(config_obj, write_csv,
-			arg.FlowId, arg.ClientId, arg.Password, arg.Wait)
+			arg.FlowId, arg.ClientId, arg.Password, arg.Wait, options)
+		if err != nil {
+			scope.Log("create_flow_download: %s", err)
+			return vfilter.Null{}
+		}
+		return result
+
+	case "stix":
+		options, err := NewExportOptions(scope, arg.MaxRows, arg.MaxBytes,
+			arg.Start, arg.End, arg.TimeField)
+		if err != nil {
+			scope.Log("create_flow_download: %v", err)
+			return vfilter.Null{}
+		}
+		options.Principal = principal
+		options.Justification = arg.Justification
+
+		result, err := CreateFlowSTIXBundle(config_obj, scope,
+			arg.FlowId, arg.ClientId, arg.Wait, options)
 		if err != nil {
 			scope.Log("create_flow_download: %s", err)
 			return vfilter.Null{}
@@ -119,6 +167,17 @@ type CreateHuntDownloadArgs struct {
 	Format       string `vfilter:"optional,field=format,doc=Format to export (csv,json) defaults to both."`
 	Filename     string `vfilter:"optional,field=base,doc=Base filename to write to."`
 	Password     string `vfilter:"optional,field=password,doc=An optional password to encrypt the collection zip."`
+
+	MaxRows   uint64      `vfilter:"optional,field=max_rows,doc=Only export this many rows from each result set."`
+	MaxBytes  uint64      `vfilter:"optional,field=max_bytes,doc=Stop exporting once this many bytes are written."`
+	Start     vfilter.Any `vfilter:"optional,field=start,doc=Only export collections created after this time."`
+	End       vfilter.Any `vfilter:"optional,field=end,doc=Only export collections created before this time."`
+	TimeField string      `vfilter:"optional,field=time_field,doc=If set, only export rows where this column is between start and end."`
+
+	Timeline       string   `vfilter:"optional,field=timeline,doc=Also export a timeline of the results (jsonl for Plaso compatible json lines or tln)."`
+	TimelineFields []string `vfilter:"optional,field=timeline_fields,doc=Columns holding the event times (default any column containing a timestamp)."`
+
+	Justification string `vfilter:"optional,field=justification,doc=Why the data is exported. This is recorded in the audit log and the export."`
 }
 
 type CreateHuntDownload struct{}
@@ -152,10 +211,29 @@ func (self *CreateHuntDownload) Call(ctx context.Context,
 		return vfilter.Null{}
 	}
 
+	timeline, err := newTimelineExporter(arg.Timeline, arg.TimelineFields)
+	if err != nil {
+		scope.Log("create_hunt_download: %v", err)
+		return vfilter.Null{}
+	}
+
+	options, err := NewExportOptions(scope, arg.MaxRows, arg.MaxBytes,
+		arg.Start, arg.End, arg.TimeField)
+	if err != nil {
+		scope.Log("create_hunt_download: %v", err)
+		return vfilter.Null{}
+	}
+
+	options.Principal = vql_subsystem.GetPrincipal(scope)
+	options.Justification = arg.Justification
+	auditExport(config_obj, options.Principal, arg.Justification,
+		logrus.Fields{"hunt_id": arg.HuntId})
+
 	result, err := createHuntDownloadFile(
 		ctx, config_obj, scope, arg.HuntId,
 		write_json, write_csv,
-		arg.Wait, arg.OnlyCombined, arg.Filename, arg.Password)
+		arg.Wait, arg.OnlyCombined, arg.Filename, arg.Password,
+		timeline, options)
 	if err != nil {
 		scope.Log("create_hunt_download: %s", err)
 		return vfilter.Null{}
@@ -176,11 +254,25 @@ func createDownloadFile(
 	config_obj *config_proto.Config,
 	write_csv bool,
 	flow_id, client_id, password string,
-	wait bool) (api.FSPathSpec, error) {
+	wait bool, options *ExportOptions) (api.FSPathSpec, error) {
 	if client_id == "" || flow_id == "" {
 		return nil, errors.New("Client Id and Flow Id should be specified.")
 	}
 
+	launcher, err := services.GetLauncher(config_obj)
+	if err != nil {
+		return nil, err
+	}
+	flow_details, err := launcher.GetFlowDetails(config_obj, client_id, flow_id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !options.includeCollection(flow_details.Context.CreateTime) {
+		return nil, fmt.Errorf(
+			"Flow %v was not created within the requested time range", flow_id)
+	}
+
 	hostname := services.GetHostname(config_obj, client_id)
 	flow_path_manager := paths.NewFlowPathManager(client_id, flow_id)
 	download_file := flow_path_manager.GetDownloadsFile(hostname, password != "")
@@ -212,15 +304,6 @@ func createDownloadFile(
 	lock_file.Write([]byte("X"))
 	lock_file.Close()
 
-	launcher, err := services.GetLauncher(config_obj)
-	if err != nil {
-		return nil, err
-	}
-	flow_details, err := launcher.GetFlowDetails(config_obj, client_id, flow_id)
-	if err != nil {
-		return nil, err
-	}
-
 	// Do these first to ensure errors are returned if the zip file
 	// is not writable.
 	zip_writer := cryptozip.NewWriter(fd)
@@ -254,7 +337,13 @@ func createDownloadFile(
 		defer cancel()
 
 		err := downloadFlowToZip(ctx, config_obj, write_csv, password,
-			client_id, hostname, flow_id, zip_writer)
+			client_id, hostname, flow_id, zip_writer, options)
+		if err != nil {
+			logger := logging.GetLogger(config_obj, &logging.GUIComponent)
+			logger.Error("downloadFlowToZip: %v", err)
+		}
+
+		err = writeExportOptions(zip_writer, password, options)
 		if err != nil {
 			logger := logging.GetLogger(config_obj, &logging.GUIComponent)
 			logger.Error("downloadFlowToZip: %v", err)
@@ -276,7 +365,8 @@ func downloadFlowToZip(
 	client_id string,
 	hostname string,
 	flow_id string,
-	zip_writer *cryptozip.Writer) error {
+	zip_writer *cryptozip.Writer,
+	options *ExportOptions) error {
 
 	launcher, err := services.GetLauncher(config_obj)
 	if err != nil {
@@ -288,6 +378,9 @@ func downloadFlowToZip(
 	}
 	file_store_factory := file_store.GetFileStore(config_obj)
 
+	scope := vql_subsystem.MakeScope()
+	defer scope.Close()
+
 	copier := func(upload_name api.FSPathSpec) error {
 
 		reader, err := file_store_factory.ReadFile(upload_name)
@@ -296,6 +389,13 @@ func downloadFlowToZip(
 		}
 		defer reader.Close()
 
+		// Files which do not fit in the export are skipped
+		// rather than truncated.
+		stat, stat_err := reader.Stat()
+		if stat_err == nil && !options.reserve(uint64(stat.Size())) {
+			return nil
+		}
+
 		// Clean the name so it makes a reasonable zip member.
 		file_member_name := path_specs.CleanPathForZip(
 			upload_name, client_id, hostname)
@@ -304,6 +404,10 @@ func downloadFlowToZip(
 			return err
 		}
 
+		if stat_err != nil {
+			f = options.Writer(f)
+		}
+
 		_, err = utils.Copy(ctx, f, reader)
 		if err != nil {
 			logger := logging.GetLogger(config_obj, &logging.GUIComponent)
@@ -337,7 +441,14 @@ func downloadFlowToZip(
 
 		rs_path, err := path_manager.GetPathForWriting()
 		if err == nil {
-			err = copier(rs_path)
+			if options.filtersRows() {
+				err = writeFilteredResultSet(ctx, scope,
+					file_store_factory, zip_writer,
+					path_specs.CleanPathForZip(rs_path, client_id, hostname),
+					password, path_manager.Path(), options)
+			} else {
+				err = copier(rs_path)
+			}
 			if err != nil {
 				return err
 			}
@@ -359,10 +470,9 @@ func downloadFlowToZip(
 			if err != nil {
 				return err
 			}
-			scope := vql_subsystem.MakeScope()
 			csv_writer := csv.GetCSVAppender(
-				config_obj, scope, f, true /* write_headers */)
-			for row := range reader.Rows(ctx) {
+				config_obj, scope, options.Writer(f), true /* write_headers */)
+			for row := range options.filterRows(ctx, scope, reader.Rows(ctx)) {
 				csv_writer.Write(row)
 			}
 			csv_writer.Close()
@@ -404,7 +514,9 @@ func createHuntDownloadFile(
 	hunt_id string,
 	write_json, write_csv bool,
 	wait, only_combined bool,
-	base_filename, password string) (api.FSPathSpec, error) {
+	base_filename, password string,
+	timeline *timelineExporter,
+	options *ExportOptions) (api.FSPathSpec, error) {
 	if hunt_id == "" {
 		return nil, errors.New("Hunt Id should be specified.")
 	}
@@ -494,6 +606,15 @@ func createHuntDownloadFile(
 		sub_ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
 		defer cancel()
 
+		defer func() {
+			err := writeExportOptions(zip_writer, password, options)
+			if err != nil {
+				logger.Error("CreateHuntDownload: %v", err)
+			}
+		}()
+
+		flows := getHuntFlows(sub_ctx, scope, hunt_id, options)
+
 		// Export aggregate CSV and JSON files for all clients.
 		for _, artifact_source := range hunt_details.ArtifactSources {
 			artifact, source := paths.SplitFullSourceName(
@@ -536,7 +657,7 @@ func createHuntDownloadFile(
 
 			err = StoreVQLAsCSVAndJsonFile(sub_ctx, config_obj,
 				subscope, query, write_csv, write_json,
-				csv_tmpfile, json_tmpfile)
+				csv_tmpfile, json_tmpfile, options)
 			if err != nil {
 				report_err(err)
 				continue
@@ -554,6 +675,15 @@ func createHuntDownloadFile(
 				}
 				defer reader.Close()
 
+				stat, err := reader.Stat()
+				if err != nil {
+					return err
+				}
+
+				if !options.reserve(uint64(stat.Size())) {
+					return exportSizeExceededError
+				}
+
 				// Clean the name so it makes a reasonable zip member.
 				f, err := createZipMember(zip_writer,
 					path_specs.CleanPathForZip(output_name, "", ""), password)
@@ -590,35 +720,28 @@ func createHuntDownloadFile(
 			}
 		}
 
+		if timeline != nil {
+			err := writeHuntTimeline(sub_ctx, scope, zip_writer,
+				password, hunt_details, timeline, options)
+			if err != nil {
+				logger.Error("CreateHuntDownload: %v", err)
+			}
+		}
+
 		// If the user only asked for combined results do not
 		// export specific flow.
 		if only_combined {
 			return
 		}
 
-		subscope := scope.Copy()
-		subscope.AppendVars(ordereddict.NewDict().
-			Set("HuntId", hunt_id))
-		defer subscope.Close()
-
-		query := "SELECT Flow.session_id AS FlowId, ClientId " +
-			"FROM hunt_flows(hunt_id=HuntId)"
-		vql, _ := vfilter.Parse(query)
-
-		query_log := actions.QueryLog.AddQuery(query)
-		defer query_log.Close()
-
-		for row := range vql.Eval(sub_ctx, subscope) {
-			flow_id := vql_subsystem.GetStringFromRow(scope, row, "FlowId")
-			client_id := vql_subsystem.GetStringFromRow(scope, row, "ClientId")
-			if flow_id == "" || client_id == "" {
-				continue
-			}
+		for _, flow := range flows {
+			flow_id := flow.flow_id
+			client_id := flow.client_id
 
 			hostname := services.GetHostname(config_obj, client_id)
 			err := downloadFlowToZip(
 				sub_ctx, config_obj, write_csv, password, client_id, hostname,
-				flow_id, zip_writer)
+				flow_id, zip_writer, options)
 			if err != nil {
 				logging.GetLogger(config_obj, &logging.FrontendComponent).
 					WithFields(logrus.Fields{
@@ -638,6 +761,33 @@ func createHuntDownloadFile(
 	return download_file, nil
 }
 
+// All the hunt's artifacts go into a single timeline member.
+func writeHuntTimeline(
+	ctx context.Context,
+	scope vfilter.Scope,
+	zip_writer *cryptozip.Writer,
+	password string,
+	hunt_details *api_proto.Hunt,
+	timeline *timelineExporter,
+	options *ExportOptions) error {
+	f, err := createZipMember(zip_writer, timeline.MemberName(), password)
+	if err != nil {
+		return err
+	}
+	out := options.Writer(f)
+
+	for _, artifact_source := range hunt_details.ArtifactSources {
+		artifact, source := paths.SplitFullSourceName(artifact_source)
+		err := timeline.WriteHuntResults(ctx, scope, hunt_details.HuntId,
+			artifact, source, out, options)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func StoreVQLAsCSVAndJsonFile(
 	ctx context.Context,
 	config_obj *config_proto.Config,
@@ -646,7 +796,8 @@ func StoreVQLAsCSVAndJsonFile(
 	write_csv bool,
 	write_json bool,
 	csv_fd io.Writer,
-	json_fd io.Writer) error {
+	json_fd io.Writer,
+	options *ExportOptions) error {
 
 	query_log := actions.QueryLog.AddQuery(query)
 	defer query_log.Close()
@@ -662,7 +813,20 @@ func StoreVQLAsCSVAndJsonFile(
 	sub_ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	for row := range vql.Eval(sub_ctx, scope) {
+	rows := make(chan *ordereddict.Dict)
+	go func() {
+		defer close(rows)
+
+		for row := range vql.Eval(sub_ctx, scope) {
+			select {
+			case <-sub_ctx.Done():
+				return
+			case rows <- vfilter.RowToDict(sub_ctx, scope, row):
+			}
+		}
+	}()
+
+	for row := range options.filterRows(sub_ctx, scope, rows) {
 		if write_csv {
 			csv_writer.Write(row)
 		}
@@ -686,6 +850,136 @@ func StoreVQLAsCSVAndJsonFile(
 	return nil
 }
 
+// Result sets are normally copied verbatim, but when rows are
+// filtered they are rewritten one row at a time.
+func writeFilteredResultSet(
+	ctx context.Context,
+	scope vfilter.Scope,
+	file_store_factory api.FileStore,
+	zip_writer *cryptozip.Writer,
+	file_member_name, password string,
+	result_set_path api.FSPathSpec,
+	options *ExportOptions) error {
+
+	reader, err := result_sets.NewResultSetReader(
+		file_store_factory, result_set_path)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	f, err := createZipMember(zip_writer, file_member_name, password)
+	if err != nil {
+		return err
+	}
+	out := options.Writer(f)
+
+	for row := range options.filterRows(ctx, scope, reader.Rows(ctx)) {
+		serialized, err := json.Marshal(row)
+		if err != nil {
+			continue
+		}
+
+		// Rows which do not fit are dropped - the options record
+		// that the export was truncated.
+		_, _ = out.Write(append(serialized, '\n'))
+	}
+
+	return nil
+}
+
+type huntFlow struct {
+	flow_id, client_id string
+}
+
+// Get the hunt's flows which should be exported. When a time range
+// is given, the combined results are also restricted to these flows.
+func getHuntFlows(ctx context.Context, scope vfilter.Scope,
+	hunt_id string, options *ExportOptions) []huntFlow {
+	subscope := scope.Copy()
+	subscope.AppendVars(ordereddict.NewDict().
+		Set("HuntId", hunt_id))
+	defer subscope.Close()
+
+	query := "SELECT Flow.session_id AS FlowId, ClientId, " +
+		"Flow.create_time AS CreateTime " +
+		"FROM hunt_flows(hunt_id=HuntId)"
+	vql, _ := vfilter.Parse(query)
+
+	query_log := actions.QueryLog.AddQuery(query)
+	defer query_log.Close()
+
+	result := []huntFlow{}
+	collections := make(map[string]bool)
+	for row := range vql.Eval(ctx, subscope) {
+		flow_id := vql_subsystem.GetStringFromRow(scope, row, "FlowId")
+		client_id := vql_subsystem.GetStringFromRow(scope, row, "ClientId")
+		if flow_id == "" || client_id == "" {
+			continue
+		}
+
+		create_time := vql_subsystem.GetIntFromRow(scope, row, "CreateTime")
+		if !options.includeCollection(create_time) {
+			continue
+		}
+
+		collections[flow_id] = true
+		result = append(result, huntFlow{
+			flow_id:   flow_id,
+			client_id: client_id,
+		})
+	}
+
+	if options.hasTimeRange() {
+		options.collections = collections
+	}
+
+	return result
+}
+
+func writeExportOptions(zip_writer *cryptozip.Writer,
+	password string, options *ExportOptions) error {
+	if options.Justification != "" {
+		err := writeZipJSON(zip_writer, "ExportAcknowledgment", password,
+			options.Acknowledgment())
+		if err != nil {
+			return err
+		}
+	}
+
+	if !options.IsSet() {
+		return nil
+	}
+
+	return writeZipJSON(zip_writer, "ExportOptions", password, options.ToDict())
+}
+
+func writeZipJSON(zip_writer *cryptozip.Writer,
+	file_member_name, password string, item *ordereddict.Dict) error {
+	f, err := createZipMember(zip_writer, file_member_name, password)
+	if err != nil {
+		return err
+	}
+
+	serialized, err := item.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(serialized)
+	return err
+}
+
+// Exports may contain personal data so we record who exported what
+// and why.
+func auditExport(config_obj *config_proto.Config,
+	principal, justification string, fields logrus.Fields) {
+	fields["user"] = principal
+	fields["justification"] = justification
+	logging.GetLogger(config_obj, &logging.Audit).
+		WithFields(fields).Info("ExportData")
+}
+
 func createZipMember(zip_writer *cryptozip.Writer, file_member_name, password string) (
 	io.Writer, error) {
 	if password == "" {
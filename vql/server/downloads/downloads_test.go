@@ -0,0 +1,16 @@
+package downloads
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"www.velocidex.com/golang/velociraptor/file_store/test_utils"
+)
+
+type DownloadsTestSuite struct {
+	test_utils.TestSuite
+}
+
+func TestDownloads(t *testing.T) {
+	suite.Run(t, &DownloadsTestSuite{})
+}
@@ -0,0 +1,260 @@
+package downloads
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	"www.velocidex.com/golang/velociraptor/utils"
+	"www.velocidex.com/golang/velociraptor/vql/functions"
+	"www.velocidex.com/golang/vfilter"
+)
+
+var exportSizeExceededError = errors.New("Export size limit exceeded")
+
+// Options to restrict an export to a subset of the collected
+// data. This allows exporting only the data scoped by a request
+// instead of everything that was collected.
+type ExportOptions struct {
+	// Maximum number of rows exported from each result set.
+	MaxRows uint64
+
+	// Maximum number of (uncompressed) bytes in the export.
+	MaxBytes uint64
+
+	// Only export collections created within this time range. If
+	// TimeField is set, rows are also filtered by that column.
+	StartTime time.Time
+	EndTime   time.Time
+	TimeField string
+
+	// Why the user is exporting the data. Exports may contain
+	// personal data so this is recorded for accountability.
+	Principal     string
+	Justification string
+
+	// When exporting a hunt, only rows from these collections are
+	// included in the combined results.
+	collections map[string]bool
+
+	mu        sync.Mutex
+	written   uint64
+	truncated bool
+	full      bool
+}
+
+func NewExportOptions(scope vfilter.Scope,
+	max_rows, max_bytes uint64,
+	start, end vfilter.Any, time_field string) (*ExportOptions, error) {
+	result := &ExportOptions{
+		MaxRows:   max_rows,
+		MaxBytes:  max_bytes,
+		TimeField: time_field,
+	}
+
+	if !utils.IsNil(start) {
+		start_time, err := functions.TimeFromAny(scope, start)
+		if err != nil {
+			return nil, err
+		}
+		result.StartTime = start_time
+	}
+
+	if !utils.IsNil(end) {
+		end_time, err := functions.TimeFromAny(scope, end)
+		if err != nil {
+			return nil, err
+		}
+		result.EndTime = end_time
+	}
+
+	if result.TimeField != "" && !result.hasTimeRange() {
+		return nil, errors.New("time_field requires a start or end time")
+	}
+
+	return result, nil
+}
+
+func (self *ExportOptions) hasTimeRange() bool {
+	return !self.StartTime.IsZero() || !self.EndTime.IsZero()
+}
+
+// Result sets can only be copied verbatim when we do not need to
+// look at each row.
+func (self *ExportOptions) filtersRows() bool {
+	return self.MaxRows > 0 || self.TimeField != "" || self.collections != nil
+}
+
+// Only write a description of the options when they are used so
+// unrestricted exports are unchanged.
+func (self *ExportOptions) IsSet() bool {
+	return self.filtersRows() || self.hasTimeRange() || self.MaxBytes > 0
+}
+
+func (self *ExportOptions) inTimeRange(t time.Time) bool {
+	if !self.StartTime.IsZero() && t.Before(self.StartTime) {
+		return false
+	}
+
+	if !self.EndTime.IsZero() && t.After(self.EndTime) {
+		return false
+	}
+
+	return true
+}
+
+// Collection times are stored in microseconds.
+func (self *ExportOptions) includeCollection(create_time uint64) bool {
+	if !self.hasTimeRange() {
+		return true
+	}
+	return self.inTimeRange(time.Unix(0, int64(create_time)*1000))
+}
+
+func (self *ExportOptions) includeRow(
+	scope vfilter.Scope, row *ordereddict.Dict) bool {
+	if self.collections != nil {
+		flow_id, _ := row.GetString("FlowId")
+		if !self.collections[flow_id] {
+			return false
+		}
+	}
+
+	if self.TimeField == "" {
+		return true
+	}
+
+	value, pres := row.Get(self.TimeField)
+	if !pres {
+		return false
+	}
+
+	t, err := functions.TimeFromAny(scope, value)
+	if err != nil {
+		return false
+	}
+
+	return self.inTimeRange(t)
+}
+
+// Filter the rows from a result set according to the options. The
+// input is always drained so callers can stop reading at any time.
+func (self *ExportOptions) filterRows(ctx context.Context,
+	scope vfilter.Scope,
+	in <-chan *ordereddict.Dict) <-chan *ordereddict.Dict {
+	output_chan := make(chan *ordereddict.Dict)
+
+	go func() {
+		defer close(output_chan)
+
+		count := uint64(0)
+		for row := range in {
+			if self.MaxRows > 0 && count >= self.MaxRows {
+				self.setTruncated()
+				continue
+			}
+
+			if self.isFull() || !self.includeRow(scope, row) {
+				continue
+			}
+
+			count++
+			select {
+			case <-ctx.Done():
+				return
+			case output_chan <- row:
+			}
+		}
+	}()
+
+	return output_chan
+}
+
+func (self *ExportOptions) setTruncated() {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	self.truncated = true
+}
+
+func (self *ExportOptions) isFull() bool {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	return self.full
+}
+
+// Reserve space in the export. Once the limit is reached nothing
+// else is written so the export is always a prefix of the full
+// export.
+func (self *ExportOptions) reserve(size uint64) bool {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	if self.full {
+		return false
+	}
+
+	if self.MaxBytes > 0 && self.written+size > self.MaxBytes {
+		self.truncated = true
+		self.full = true
+		return false
+	}
+
+	self.written += size
+	return true
+}
+
+// Wrap a zip member so writes count against the export size.
+func (self *ExportOptions) Writer(fd io.Writer) io.Writer {
+	return &limitedWriter{fd: fd, options: self}
+}
+
+// Describes how the export was scoped so the recipient can tell
+// exactly what subset of the data they received.
+func (self *ExportOptions) ToDict() *ordereddict.Dict {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	result := ordereddict.NewDict().
+		Set("MaxRows", self.MaxRows).
+		Set("MaxBytes", self.MaxBytes).
+		Set("StartTime", vfilter.Null{}).
+		Set("EndTime", vfilter.Null{}).
+		Set("TimeField", self.TimeField).
+		Set("TotalBytes", self.written).
+		Set("Truncated", self.truncated)
+
+	if !self.StartTime.IsZero() {
+		result.Set("StartTime", self.StartTime.UTC())
+	}
+
+	if !self.EndTime.IsZero() {
+		result.Set("EndTime", self.EndTime.UTC())
+	}
+
+	return result
+}
+
+// Describes who exported the data and why.
+func (self *ExportOptions) Acknowledgment() *ordereddict.Dict {
+	return ordereddict.NewDict().
+		Set("Principal", self.Principal).
+		Set("Justification", self.Justification).
+		Set("Time", time.Now().UTC())
+}
+
+type limitedWriter struct {
+	fd      io.Writer
+	options *ExportOptions
+}
+
+func (self *limitedWriter) Write(buf []byte) (int, error) {
+	if !self.options.reserve(uint64(len(buf))) {
+		return 0, exportSizeExceededError
+	}
+	return self.fd.Write(buf)
+}
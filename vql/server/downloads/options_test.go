@@ -0,0 +1,73 @@
+package downloads
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+
+	cryptozip "github.com/Velocidex/cryptozip"
+	"github.com/Velocidex/ordereddict"
+	"github.com/stretchr/testify/assert"
+	"www.velocidex.com/golang/velociraptor/json"
+	"www.velocidex.com/golang/velociraptor/utils"
+)
+
+// Returns the members of the zip file written by writeExportOptions.
+func exportOptionsMembers(
+	self *DownloadsTestSuite, options *ExportOptions) map[string]*ordereddict.Dict {
+	buf := &bytes.Buffer{}
+	zip_writer := cryptozip.NewWriter(buf)
+	err := writeExportOptions(zip_writer, "", options)
+	assert.NoError(self.T(), err)
+	assert.NoError(self.T(), zip_writer.Close())
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(self.T(), err)
+
+	result := make(map[string]*ordereddict.Dict)
+	for _, member := range reader.File {
+		fd, err := member.Open()
+		assert.NoError(self.T(), err)
+
+		data, err := ioutil.ReadAll(fd)
+		assert.NoError(self.T(), err)
+		fd.Close()
+
+		item := ordereddict.NewDict()
+		assert.NoError(self.T(), json.Unmarshal(data, item))
+		result[member.Name] = item
+	}
+	return result
+}
+
+func (self *DownloadsTestSuite) TestExportAcknowledgment() {
+	// Unrestricted exports without a justification are unchanged.
+	members := exportOptionsMembers(self, &ExportOptions{})
+	assert.Equal(self.T(), 0, len(members))
+
+	members = exportOptionsMembers(self, &ExportOptions{
+		Principal:     "alice",
+		Justification: "Case 1234",
+	})
+	assert.Equal(self.T(), 1, len(members))
+
+	acknowledgment, pres := members["ExportAcknowledgment"]
+	assert.True(self.T(), pres)
+	assert.Equal(self.T(), "alice", utils.GetString(acknowledgment, "Principal"))
+	assert.Equal(self.T(), "Case 1234",
+		utils.GetString(acknowledgment, "Justification"))
+
+	// Both are written when the export is also restricted.
+	members = exportOptionsMembers(self, &ExportOptions{
+		MaxRows:       10,
+		Principal:     "alice",
+		Justification: "Case 1234",
+	})
+	assert.Equal(self.T(), 2, len(members))
+
+	export_options, pres := members["ExportOptions"]
+	assert.True(self.T(), pres)
+
+	max_rows, _ := export_options.Get("MaxRows")
+	assert.Equal(self.T(), "10", json.MustMarshalString(max_rows))
+}
@@ -0,0 +1,108 @@
+package downloads
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+)
+
+var (
+	// Names of the browser binaries we look for in the path when
+	// no renderer is configured.
+	pdfRenderers = []string{
+		"chromium", "chromium-browser", "google-chrome",
+		"google-chrome-stable", "chrome",
+	}
+
+	pdfRendererNotFoundError = errors.New(
+		"No PDF renderer found: set Defaults.pdf_renderer_path to a Chrome or Chromium binary")
+)
+
+func getPDFRenderer(config_obj *config_proto.Config) (string, []string, error) {
+	if config_obj.Defaults != nil &&
+		config_obj.Defaults.PdfRendererPath != "" {
+		return config_obj.Defaults.PdfRendererPath,
+			config_obj.Defaults.PdfRendererArgs, nil
+	}
+
+	var args []string
+	if config_obj.Defaults != nil {
+		args = config_obj.Defaults.PdfRendererArgs
+	}
+
+	for _, name := range pdfRenderers {
+		path, err := exec.LookPath(name)
+		if err == nil {
+			return path, args, nil
+		}
+	}
+
+	return "", nil, pdfRendererNotFoundError
+}
+
+// Render a HTML report into a PDF using a headless browser. The
+// browser runs with its own profile in a temporary directory which
+// is removed afterwards.
+func renderPDF(
+	ctx context.Context,
+	config_obj *config_proto.Config,
+	html []byte, writer io.Writer) error {
+	renderer, extra_args, err := getPDFRenderer(config_obj)
+	if err != nil {
+		return err
+	}
+
+	tmpdir, err := ioutil.TempDir("", "report")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpdir)
+
+	html_path := filepath.Join(tmpdir, "report.html")
+	pdf_path := filepath.Join(tmpdir, "report.pdf")
+
+	err = ioutil.WriteFile(html_path, html, 0600)
+	if err != nil {
+		return err
+	}
+
+	args := []string{
+		"--headless",
+		"--disable-gpu",
+		"--disable-extensions",
+		"--no-first-run",
+		"--no-pdf-header-footer",
+		"--print-to-pdf-no-header",
+		"--run-all-compositor-stages-before-draw",
+
+		// Give scripts in the report some time to run.
+		"--virtual-time-budget=10000",
+		"--user-data-dir=" + filepath.Join(tmpdir, "profile"),
+		"--print-to-pdf=" + pdf_path,
+	}
+	args = append(args, extra_args...)
+	args = append(args, "file://"+filepath.ToSlash(html_path))
+
+	output, err := exec.CommandContext(ctx, renderer, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("Rendering PDF with %v: %w: %v",
+			renderer, err, string(output))
+	}
+
+	fd, err := os.Open(pdf_path)
+	if err != nil {
+		return fmt.Errorf("Rendering PDF with %v: %w: %v",
+			renderer, err, string(output))
+	}
+	defer fd.Close()
+
+	_, err = io.Copy(writer, fd)
+	return err
+}
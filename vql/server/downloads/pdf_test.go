@@ -0,0 +1,86 @@
+package downloads
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/Velocidex/ordereddict"
+	"github.com/stretchr/testify/assert"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+)
+
+// A fake renderer which copies the html file into the pdf file. The
+// test empties the PATH so it does not rely on it.
+const fakeRenderer = `#!/bin/sh
+for arg in "$@"; do
+  case "$arg" in
+    --print-to-pdf=*) out="${arg#--print-to-pdf=}" ;;
+    file://*) in="${arg#file://}" ;;
+    --fail) echo "renderer failed"; exit 1 ;;
+  esac
+done
+/bin/cp "$in" "$out"
+`
+
+func (self *DownloadsTestSuite) TestPDFRenderer() {
+	if runtime.GOOS == "windows" {
+		self.T().Skip("Fake renderer requires a shell")
+	}
+
+	tmpdir, err := ioutil.TempDir("", "pdf_test")
+	assert.NoError(self.T(), err)
+	defer os.RemoveAll(tmpdir)
+
+	old_path := os.Getenv("PATH")
+	defer os.Setenv("PATH", old_path)
+
+	// Nothing configured and nothing in the path.
+	os.Setenv("PATH", tmpdir)
+	config_obj := &config_proto.Config{}
+	_, _, err = getPDFRenderer(config_obj)
+	assert.Equal(self.T(), pdfRendererNotFoundError, err)
+
+	// Reports fail before any work is started.
+	scope := vql_subsystem.MakeScope()
+	defer scope.Close()
+
+	_, err = CreateFlowReport(self.ConfigObj, scope, "F.1234", "C.1234",
+		"", ordereddict.NewDict(), true /* pdf */, true /* wait */)
+	assert.Equal(self.T(), pdfRendererNotFoundError, err)
+
+	renderer := filepath.Join(tmpdir, "chromium")
+	err = ioutil.WriteFile(renderer, []byte(fakeRenderer), 0700)
+	assert.NoError(self.T(), err)
+
+	// Found in the path with the configured args.
+	config_obj.Defaults = &config_proto.Defaults{
+		PdfRendererArgs: []string{"--no-sandbox"},
+	}
+	path, args, err := getPDFRenderer(config_obj)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), renderer, path)
+	assert.Equal(self.T(), []string{"--no-sandbox"}, args)
+
+	// The configured path takes precedence.
+	config_obj.Defaults.PdfRendererPath = "/usr/bin/true"
+	path, _, err = getPDFRenderer(config_obj)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), "/usr/bin/true", path)
+
+	config_obj.Defaults.PdfRendererPath = renderer
+	out := &bytes.Buffer{}
+	err = renderPDF(context.Background(), config_obj, []byte("<html/>"), out)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), "<html/>", out.String())
+
+	// Errors include the renderer's output.
+	config_obj.Defaults.PdfRendererArgs = []string{"--fail"}
+	err = renderPDF(context.Background(), config_obj, []byte("<html/>"), out)
+	assert.Error(self.T(), err)
+	assert.Contains(self.T(), err.Error(), "renderer failed")
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"io"
 	"sync"
+	"time"
 
+	"github.com/Velocidex/ordereddict"
 	"github.com/pkg/errors"
 	artifacts_proto "www.velocidex.com/golang/velociraptor/artifacts/proto"
 	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
@@ -46,7 +48,8 @@ func WriteFlowReport(
 	scope vfilter.Scope,
 	repository services.Repository,
 	writer io.Writer,
-	flow_id, client_id, template string) error {
+	flow_id, client_id, template string,
+	parameters *ordereddict.Dict) error {
 	html_template_string, err := getHTMLTemplate(config_obj, template, repository)
 	if err != nil {
 		return errors.New(fmt.Sprintf("Artifact %v not found %v\n", template, err))
@@ -122,6 +125,7 @@ func WriteFlowReport(
 		return err
 	}
 
+	template_engine.SetTemplateParameters(parameters)
 	template_engine.SetEnv("parts", parts)
 	template_engine.SetEnv("ClientId", client_id)
 	template_engine.SetEnv("FlowId", flow_id)
@@ -139,11 +143,21 @@ func CreateFlowReport(
 	config_obj *config_proto.Config,
 	scope vfilter.Scope,
 	flow_id, client_id, template string,
-	wait bool) (api.FSPathSpec, error) {
+	parameters *ordereddict.Dict,
+	pdf, wait bool) (api.FSPathSpec, error) {
 
 	hostname := services.GetHostname(config_obj, client_id)
 	flow_path_manager := paths.NewFlowPathManager(client_id, flow_id)
 	download_file := flow_path_manager.GetReportsFile(hostname)
+
+	// Fail early if we can not render the PDF.
+	if pdf {
+		_, _, err := getPDFRenderer(config_obj)
+		if err != nil {
+			return nil, err
+		}
+		download_file = download_file.SetType(api.PATH_TYPE_FILESTORE_DOWNLOAD_PDF)
+	}
 	lock_file_spec := download_file.SetType(api.PATH_TYPE_FILESTORE_LOCK)
 
 	file_store_factory := file_store.GetFileStore(config_obj)
@@ -193,8 +207,27 @@ func CreateFlowReport(
 
 		}()
 
+		if !pdf {
+			err := WriteFlowReport(config_obj, subscope, repository,
+				writer, flow_id, client_id, template, parameters)
+			if err != nil {
+				scope.Log("Writing report: %v", err)
+			}
+			return
+		}
+
+		html := &bytes.Buffer{}
 		err := WriteFlowReport(config_obj, subscope, repository,
-			writer, flow_id, client_id, template)
+			html, flow_id, client_id, template, parameters)
+		if err != nil {
+			scope.Log("Writing report: %v", err)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*600)
+		defer cancel()
+
+		err = renderPDF(ctx, config_obj, html.Bytes(), writer)
 		if err != nil {
 			scope.Log("Writing report: %v", err)
 		}
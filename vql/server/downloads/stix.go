@@ -0,0 +1,419 @@
+package downloads
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/file_store"
+	"www.velocidex.com/golang/velociraptor/file_store/api"
+	"www.velocidex.com/golang/velociraptor/json"
+	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/paths"
+	artifact_paths "www.velocidex.com/golang/velociraptor/paths/artifacts"
+	"www.velocidex.com/golang/velociraptor/result_sets"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/utils"
+	"www.velocidex.com/golang/vfilter"
+)
+
+const (
+	STIX_SPEC_VERSION = "2.1"
+	STIX_TIME_FORMAT  = "2006-01-02T15:04:05.000Z"
+)
+
+var (
+	// Deterministic ids of cyber observables are derived from this
+	// namespace by the STIX 2.1 specification.
+	stixSCONamespace = uuid.MustParse("00abedb4-aa42-466c-9c01-fed23315a9b7")
+
+	hexRegex = regexp.MustCompile("^[0-9a-fA-F]+$")
+
+	// Hash names by the length of their hex digest.
+	stixHashLengths = map[int]string{
+		32: "MD5",
+		40: "SHA-1",
+		64: "SHA-256",
+	}
+)
+
+// Builds a STIX 2.1 bundle from the results of a collection. Every
+// row containing observables (addresses, urls or file hashes)
+// becomes an observed-data object. Each distinct observable becomes
+// an indicator which is sighted on the client by these
+// observed-data.
+type stixBuilder struct {
+	created  string
+	producer string
+	client   string
+	objects  []*ordereddict.Dict
+
+	// Observable id -> The observable
+	observables map[string]*ordereddict.Dict
+
+	// Observable id -> observed-data ids which contain it.
+	observations map[string][]string
+
+	// Observable ids in the order they were first seen.
+	order []string
+}
+
+func newSTIXBuilder(now time.Time, client_id, hostname string) *stixBuilder {
+	self := &stixBuilder{
+		created:      now.UTC().Format(STIX_TIME_FORMAT),
+		observables:  make(map[string]*ordereddict.Dict),
+		observations: make(map[string][]string),
+	}
+
+	self.producer = stixId("identity", "Velociraptor")
+	self.objects = append(self.objects, self.newObject(
+		"identity", self.producer).
+		Set("name", "Velociraptor").
+		Set("identity_class", "system"))
+
+	if hostname == "" {
+		hostname = client_id
+	}
+
+	self.client = stixId("identity", client_id)
+	self.objects = append(self.objects, self.newObject(
+		"identity", self.client).
+		Set("name", hostname).
+		Set("identity_class", "system").
+		Set("x_velociraptor_client_id", client_id))
+
+	return self
+}
+
+func (self *stixBuilder) newObject(stix_type, id string) *ordereddict.Dict {
+	return ordereddict.NewDict().
+		Set("type", stix_type).
+		Set("spec_version", STIX_SPEC_VERSION).
+		Set("id", id).
+		Set("created_by_ref", self.producer).
+		Set("created", self.created).
+		Set("modified", self.created)
+}
+
+// Add a result row observed at the time given.
+func (self *stixBuilder) AddRow(
+	artifact string, observed time.Time, row *ordereddict.Dict) {
+	refs := []string{}
+	for _, observable := range getSTIXObservables(row) {
+		id, _ := observable.GetString("id")
+		_, pres := self.observables[id]
+		if !pres {
+			self.observables[id] = observable
+			self.order = append(self.order, id)
+		}
+
+		if !utils.InString(refs, id) {
+			refs = append(refs, id)
+		}
+	}
+
+	if len(refs) == 0 {
+		return
+	}
+
+	observed_str := observed.UTC().Format(STIX_TIME_FORMAT)
+	observed_data_id := "observed-data--" + uuid.New().String()
+	self.objects = append(self.objects, self.newObject(
+		"observed-data", observed_data_id).
+		Set("first_observed", observed_str).
+		Set("last_observed", observed_str).
+		Set("number_observed", 1).
+		Set("object_refs", refs).
+		Set("x_velociraptor_artifact", artifact))
+
+	for _, id := range refs {
+		self.observations[id] = append(self.observations[id], observed_data_id)
+	}
+}
+
+func (self *stixBuilder) Bundle() *ordereddict.Dict {
+	objects := append([]*ordereddict.Dict{}, self.objects...)
+
+	for _, id := range self.order {
+		observable := self.observables[id]
+		objects = append(objects, observable)
+
+		indicator_id := "indicator--" + uuid.New().String()
+		objects = append(objects, self.newObject("indicator", indicator_id).
+			Set("name", stixObservableName(observable)).
+			Set("indicator_types", []string{"unknown"}).
+			Set("pattern", stixPattern(observable)).
+			Set("pattern_type", "stix").
+			Set("valid_from", self.created))
+
+		observed_data_refs := self.observations[id]
+		objects = append(objects, self.newObject(
+			"sighting", "sighting--"+uuid.New().String()).
+			Set("sighting_of_ref", indicator_id).
+			Set("observed_data_refs", observed_data_refs).
+			Set("where_sighted_refs", []string{self.client}).
+			Set("count", len(observed_data_refs)))
+	}
+
+	return ordereddict.NewDict().
+		Set("type", "bundle").
+		Set("id", "bundle--"+uuid.New().String()).
+		Set("objects", objects)
+}
+
+// Ids of cyber observables are derived from their contributing
+// properties so the same observable always has the same id.
+func stixId(stix_type string, contributing interface{}) string {
+	serialized, _ := json.Marshal(contributing)
+	return stix_type + "--" + uuid.NewSHA1(stixSCONamespace, serialized).String()
+}
+
+func getSTIXObservables(row *ordereddict.Dict) []*ordereddict.Dict {
+	result := []*ordereddict.Dict{}
+	hashes := make(map[string]interface{})
+
+	for _, key := range row.Keys() {
+		value, _ := row.Get(key)
+		switch value := value.(type) {
+		case *ordereddict.Dict:
+			// Nested objects like the output of hash() describe
+			// the same file.
+			result = append(result, getSTIXObservables(value)...)
+
+		case string:
+			if addSTIXHash(hashes, key, value) {
+				continue
+			}
+
+			observable := getSTIXValueObservable(value)
+			if observable != nil {
+				result = append(result, observable)
+			}
+		}
+	}
+
+	if len(hashes) > 0 {
+		result = append(result, ordereddict.NewDict().
+			Set("type", "file").
+			Set("spec_version", STIX_SPEC_VERSION).
+			Set("id", stixId("file", map[string]interface{}{"hashes": hashes})).
+			Set("hashes", hashes))
+	}
+
+	return result
+}
+
+// Only columns which are named like hashes are considered so other
+// hex strings are not mistaken for them.
+func addSTIXHash(hashes map[string]interface{}, key, value string) bool {
+	lower_key := strings.ToLower(key)
+	if !strings.Contains(lower_key, "hash") &&
+		!strings.Contains(lower_key, "md5") &&
+		!strings.Contains(lower_key, "sha") {
+		return false
+	}
+
+	name, pres := stixHashLengths[len(value)]
+	if !pres || !hexRegex.MatchString(value) {
+		return false
+	}
+
+	hashes[name] = strings.ToLower(value)
+	return true
+}
+
+func getSTIXValueObservable(value string) *ordereddict.Dict {
+	var stix_type string
+
+	ip := net.ParseIP(value)
+	switch {
+	case ip != nil && (ip.IsLoopback() || ip.IsUnspecified()):
+		return nil
+
+	case ip != nil && ip.To4() != nil:
+		stix_type = "ipv4-addr"
+
+	case ip != nil:
+		stix_type = "ipv6-addr"
+
+	case strings.HasPrefix(value, "http://") ||
+		strings.HasPrefix(value, "https://"):
+		stix_type = "url"
+
+	default:
+		return nil
+	}
+
+	return ordereddict.NewDict().
+		Set("type", stix_type).
+		Set("spec_version", STIX_SPEC_VERSION).
+		Set("id", stixId(stix_type, map[string]interface{}{"value": value})).
+		Set("value", value)
+}
+
+func stixObservableName(observable *ordereddict.Dict) string {
+	value, pres := observable.GetString("value")
+	if pres {
+		return value
+	}
+
+	hashes, _ := observable.Get("hashes")
+	hash_map, _ := hashes.(map[string]interface{})
+	for _, name := range []string{"SHA-256", "SHA-1", "MD5"} {
+		hash, pres := hash_map[name]
+		if pres {
+			return fmt.Sprintf("%v", hash)
+		}
+	}
+	return ""
+}
+
+func stixPattern(observable *ordereddict.Dict) string {
+	stix_type, _ := observable.GetString("type")
+	value, pres := observable.GetString("value")
+	if pres {
+		return fmt.Sprintf("[%s:value = '%s']", stix_type, stixEscape(value))
+	}
+
+	hashes, _ := observable.Get("hashes")
+	hash_map, _ := hashes.(map[string]interface{})
+	names := make([]string, 0, len(hash_map))
+	for name := range hash_map {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	terms := []string{}
+	for _, name := range names {
+		terms = append(terms, fmt.Sprintf("file:hashes.'%s' = '%v'",
+			name, hash_map[name]))
+	}
+	return "[" + strings.Join(terms, " OR ") + "]"
+}
+
+func stixEscape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+}
+
+// Write the collection's observables as a STIX bundle so they can
+// be shared with threat intelligence platforms.
+func CreateFlowSTIXBundle(
+	config_obj *config_proto.Config,
+	scope vfilter.Scope,
+	flow_id, client_id string,
+	wait bool, options *ExportOptions) (api.FSPathSpec, error) {
+	if client_id == "" || flow_id == "" {
+		return nil, errors.New("Client Id and Flow Id should be specified.")
+	}
+
+	launcher, err := services.GetLauncher(config_obj)
+	if err != nil {
+		return nil, err
+	}
+	flow_details, err := launcher.GetFlowDetails(config_obj, client_id, flow_id)
+	if err != nil {
+		return nil, err
+	}
+
+	if flow_details == nil || flow_details.Context == nil {
+		return nil, errors.New("Invalid flow object")
+	}
+
+	if !options.includeCollection(flow_details.Context.CreateTime) {
+		return nil, fmt.Errorf(
+			"Flow %v was not created within the requested time range", flow_id)
+	}
+
+	hostname := services.GetHostname(config_obj, client_id)
+	flow_path_manager := paths.NewFlowPathManager(client_id, flow_id)
+	download_file := flow_path_manager.GetSTIXFile(hostname)
+	lock_file_spec := download_file.SetType(api.PATH_TYPE_FILESTORE_LOCK)
+
+	file_store_factory := file_store.GetFileStore(config_obj)
+	writer, err := file_store_factory.WriteFile(download_file)
+	if err != nil {
+		return nil, err
+	}
+	err = writer.Truncate()
+	if err != nil {
+		writer.Close()
+		return nil, err
+	}
+
+	lock_file, err := file_store_factory.WriteFile(lock_file_spec)
+	if err != nil {
+		writer.Close()
+		return nil, err
+	}
+	lock_file.Close()
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		defer writer.Close()
+		defer func() {
+			err := file_store_factory.Delete(lock_file_spec)
+			if err != nil {
+				logger := logging.GetLogger(config_obj, &logging.GUIComponent)
+				logger.Error("Failed to bind to remove lock file for %v: %v",
+					download_file, err)
+			}
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*600)
+		defer cancel()
+
+		// Rows do not carry the time they were collected so we
+		// use the collection's time.
+		observed := time.Unix(0, int64(flow_details.Context.CreateTime)*1000)
+		builder := newSTIXBuilder(time.Now(), client_id, hostname)
+
+		for _, artifact := range flow_details.Context.ArtifactsWithResults {
+			path_manager, err := artifact_paths.NewArtifactPathManager(
+				config_obj, client_id, flow_id, artifact)
+			if err != nil {
+				scope.Log("Writing STIX bundle: %v", err)
+				continue
+			}
+
+			reader, err := result_sets.NewResultSetReader(
+				file_store_factory, path_manager.Path())
+			if err != nil {
+				continue
+			}
+
+			for row := range options.filterRows(ctx, scope, reader.Rows(ctx)) {
+				builder.AddRow(artifact, observed, row)
+			}
+			reader.Close()
+		}
+
+		serialized, err := json.Marshal(builder.Bundle())
+		if err != nil {
+			scope.Log("Writing STIX bundle: %v", err)
+			return
+		}
+
+		_, err = options.Writer(writer).Write(serialized)
+		if err != nil {
+			scope.Log("Writing STIX bundle: %v", err)
+		}
+	}()
+
+	if wait {
+		wg.Wait()
+	}
+
+	return download_file, nil
+}
@@ -0,0 +1,190 @@
+package downloads
+
+import (
+	"io/ioutil"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	"github.com/stretchr/testify/assert"
+	"www.velocidex.com/golang/velociraptor/datastore"
+	"www.velocidex.com/golang/velociraptor/file_store"
+	flows_proto "www.velocidex.com/golang/velociraptor/flows/proto"
+	"www.velocidex.com/golang/velociraptor/json"
+	"www.velocidex.com/golang/velociraptor/paths"
+	artifact_paths "www.velocidex.com/golang/velociraptor/paths/artifacts"
+	"www.velocidex.com/golang/velociraptor/result_sets"
+	"www.velocidex.com/golang/velociraptor/services"
+	"www.velocidex.com/golang/velociraptor/utils"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+)
+
+const (
+	testMD5    = "d41d8cd98f00b204e9800998ecf8427e"
+	testSHA256 = "E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855"
+)
+
+// Objects of the bundle by type.
+func stixObjects(bundle *ordereddict.Dict) map[string][]*ordereddict.Dict {
+	result := make(map[string][]*ordereddict.Dict)
+	objects, _ := bundle.Get("objects")
+	for _, object := range objects.([]*ordereddict.Dict) {
+		stix_type := utils.GetString(object, "type")
+		result[stix_type] = append(result[stix_type], object)
+	}
+	return result
+}
+
+func (self *DownloadsTestSuite) TestSTIXObservables() {
+	observables := getSTIXObservables(ordereddict.NewDict().
+		Set("RemoteAddr", "10.1.1.1").
+		Set("LocalAddr", "127.0.0.1").
+		Set("Url", "https://www.example.com/a'b").
+		Set("Name", "not an observable").
+		Set("Hash", ordereddict.NewDict().
+			Set("MD5", testMD5).
+			Set("SHA256", testSHA256)).
+		// Hex strings are only hashes in hash columns.
+		Set("Serial", testMD5))
+
+	assert.Equal(self.T(), 3, len(observables))
+	assert.Equal(self.T(), "ipv4-addr", utils.GetString(observables[0], "type"))
+	assert.Equal(self.T(), "url", utils.GetString(observables[1], "type"))
+	assert.Equal(self.T(), "file", utils.GetString(observables[2], "type"))
+
+	// Ids are derived from the value so they are stable.
+	again := getSTIXValueObservable("10.1.1.1")
+	assert.Equal(self.T(), utils.GetString(observables[0], "id"),
+		utils.GetString(again, "id"))
+
+	assert.Equal(self.T(), "[url:value = 'https://www.example.com/a\\'b']",
+		stixPattern(observables[1]))
+	assert.Equal(self.T(), "[file:hashes.'MD5' = '"+testMD5+"' OR "+
+		"file:hashes.'SHA-256' = 'e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855']",
+		stixPattern(observables[2]))
+	assert.Equal(self.T(),
+		"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		stixObservableName(observables[2]))
+
+	ipv6 := getSTIXValueObservable("2001:db8::1")
+	assert.Equal(self.T(), "ipv6-addr", utils.GetString(ipv6, "type"))
+}
+
+func (self *DownloadsTestSuite) TestSTIXBundle() {
+	now := time.Unix(1600000000, 0)
+	builder := newSTIXBuilder(now, "C.1", "host1")
+
+	builder.AddRow("Custom.Netstat", now, ordereddict.NewDict().
+		Set("RemoteAddr", "10.1.1.1"))
+	builder.AddRow("Custom.Netstat", now, ordereddict.NewDict().
+		Set("RemoteAddr", "10.1.1.1").
+		Set("Url", "http://www.example.com/"))
+
+	// Rows without observables are skipped.
+	builder.AddRow("Custom.Netstat", now, ordereddict.NewDict().
+		Set("Name", "foo"))
+
+	objects := stixObjects(builder.Bundle())
+	assert.Equal(self.T(), 2, len(objects["identity"]))
+	assert.Equal(self.T(), 2, len(objects["observed-data"]))
+	assert.Equal(self.T(), 1, len(objects["ipv4-addr"]))
+	assert.Equal(self.T(), 1, len(objects["url"]))
+	assert.Equal(self.T(), 2, len(objects["indicator"]))
+	assert.Equal(self.T(), 2, len(objects["sighting"]))
+
+	// The address was seen in both rows.
+	sighting := objects["sighting"][0]
+	count, _ := sighting.Get("count")
+	assert.Equal(self.T(), 2, count)
+
+	client, _ := sighting.Get("where_sighted_refs")
+	assert.Equal(self.T(), []string{utils.GetString(objects["identity"][1], "id")},
+		client)
+	assert.Equal(self.T(), "host1", utils.GetString(objects["identity"][1], "name"))
+	assert.Equal(self.T(), "2020-09-13T12:26:40.000Z",
+		utils.GetString(objects["observed-data"][0], "first_observed"))
+}
+
+func (self *DownloadsTestSuite) TestCreateFlowSTIXBundle() {
+	client_id := "C.1"
+	flow_id := "F.STIX"
+	artifact := "Custom.Netstat"
+
+	// The path manager finds the artifact type in the global
+	// repository.
+	manager, err := services.GetRepositoryManager(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	repository, err := manager.GetGlobalRepository(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	_, err = repository.LoadYaml(`
+name: Custom.Netstat
+type: CLIENT
+`, true, true)
+	assert.NoError(self.T(), err)
+
+	db, err := datastore.GetDB(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	err = db.SetSubject(self.ConfigObj,
+		paths.NewFlowPathManager(client_id, flow_id).Path(),
+		&flows_proto.ArtifactCollectorContext{
+			ClientId:             client_id,
+			SessionId:            flow_id,
+			CreateTime:           1600000000 * 1000000,
+			State:                flows_proto.ArtifactCollectorContext_FINISHED,
+			ArtifactsWithResults: []string{artifact},
+		})
+	assert.NoError(self.T(), err)
+
+	path_manager, err := artifact_paths.NewArtifactPathManager(
+		self.ConfigObj, client_id, flow_id, artifact)
+	assert.NoError(self.T(), err)
+
+	rs_writer, err := result_sets.NewResultSetWriter(
+		file_store.GetFileStore(self.ConfigObj), path_manager.Path(),
+		nil, utils.SyncCompleter, true /* truncate */)
+	assert.NoError(self.T(), err)
+
+	for _, addr := range []string{"10.1.1.1", "10.1.1.2", "10.1.1.3"} {
+		rs_writer.Write(ordereddict.NewDict().Set("RemoteAddr", addr))
+	}
+	rs_writer.Close()
+
+	scope := vql_subsystem.MakeScope()
+	defer scope.Close()
+
+	// Only the first two rows are exported.
+	path, err := CreateFlowSTIXBundle(self.ConfigObj, scope,
+		flow_id, client_id, true /* wait */, &ExportOptions{MaxRows: 2})
+	assert.NoError(self.T(), err)
+
+	fd, err := file_store.GetFileStore(self.ConfigObj).ReadFile(path)
+	assert.NoError(self.T(), err)
+	defer fd.Close()
+
+	data, err := ioutil.ReadAll(fd)
+	assert.NoError(self.T(), err)
+
+	bundle := ordereddict.NewDict()
+	err = json.Unmarshal(data, bundle)
+	assert.NoError(self.T(), err)
+	assert.Equal(self.T(), "bundle", utils.GetString(bundle, "type"))
+
+	objects, _ := bundle.Get("objects")
+	addresses := []string{}
+	for _, object := range objects.([]interface{}) {
+		object_dict, ok := object.(*ordereddict.Dict)
+		if ok && utils.GetString(object_dict, "type") == "ipv4-addr" {
+			addresses = append(addresses, utils.GetString(object_dict, "value"))
+		}
+	}
+	assert.Equal(self.T(), []string{"10.1.1.1", "10.1.1.2"}, addresses)
+
+	// Collections outside the time range are refused.
+	_, err = CreateFlowSTIXBundle(self.ConfigObj, scope,
+		flow_id, client_id, true /* wait */, &ExportOptions{
+			StartTime: time.Unix(1700000000, 0),
+		})
+	assert.Error(self.T(), err)
+}
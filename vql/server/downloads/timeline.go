@@ -0,0 +1,264 @@
+package downloads
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	"www.velocidex.com/golang/velociraptor/actions"
+	"www.velocidex.com/golang/velociraptor/json"
+	"www.velocidex.com/golang/velociraptor/utils"
+	"www.velocidex.com/golang/velociraptor/vql/functions"
+	"www.velocidex.com/golang/vfilter"
+)
+
+const (
+	// One JSON object per line with the fields used by Plaso's
+	// json_line output module.
+	TIMELINE_JSONL = "jsonl"
+
+	// The five field Time|Source|Host|User|Description format.
+	TIMELINE_TLN = "tln"
+)
+
+// Columns added by hunt_results() which describe where the row came
+// from rather than the event itself.
+var timelineBookkeepingColumns = []string{"ClientId", "FlowId", "Fqdn"}
+
+// Converts hunt results into a timeline. Every timestamp in a row
+// produces a separate event so a row with several times (e.g. MACB
+// times of a file) appears several times in the timeline. Events
+// are written in result order - timeline tools sort them on import.
+type timelineExporter struct {
+	format string
+
+	// Only these columns are considered timestamps. When empty, any
+	// column holding a time is used.
+	fields []string
+}
+
+func newTimelineExporter(format string, fields []string) (
+	*timelineExporter, error) {
+	switch format {
+	case "":
+		return nil, nil
+
+	case TIMELINE_JSONL, TIMELINE_TLN:
+		return &timelineExporter{format: format, fields: fields}, nil
+
+	default:
+		return nil, errors.New("Unknown timeline format: either 'jsonl' or 'tln'.")
+	}
+}
+
+func (self *timelineExporter) MemberName() string {
+	return "Timeline." + self.format
+}
+
+type timelineEvent struct {
+	Time      time.Time
+	Desc      string
+	Source    string
+	ClientId  string
+	Hostname  string
+	Message   string
+	EventData *ordereddict.Dict
+}
+
+// Write the timeline events for all the hunt results of the
+// artifact.
+func (self *timelineExporter) WriteHuntResults(
+	ctx context.Context, scope vfilter.Scope,
+	hunt_id, artifact, source string,
+	out io.Writer, options *ExportOptions) error {
+	subscope := scope.Copy()
+	subscope.AppendVars(ordereddict.NewDict().
+		Set("Artifact", artifact).
+		Set("HuntId", hunt_id).
+		Set("Source", source))
+	defer subscope.Close()
+
+	query := "SELECT * FROM hunt_results(" +
+		"hunt_id=HuntId, artifact=Artifact, source=Source)"
+
+	query_log := actions.QueryLog.AddQuery(query)
+	defer query_log.Close()
+
+	vql, err := vfilter.Parse(query)
+	if err != nil {
+		return err
+	}
+
+	sub_ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	rows := make(chan *ordereddict.Dict)
+	go func() {
+		defer close(rows)
+
+		for row := range vql.Eval(sub_ctx, subscope) {
+			select {
+			case <-sub_ctx.Done():
+				return
+			case rows <- vfilter.RowToDict(sub_ctx, subscope, row):
+			}
+		}
+	}()
+
+	name := artifact
+	if source != "" {
+		name += "/" + source
+	}
+
+	for row := range options.filterRows(sub_ctx, subscope, rows) {
+		for _, event := range self.getEvents(subscope, name, row) {
+			_, err := out.Write(self.formatEvent(event))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (self *timelineExporter) getEvents(scope vfilter.Scope,
+	source string, row *ordereddict.Dict) []*timelineEvent {
+	client_id, _ := row.GetString("ClientId")
+	hostname, _ := row.GetString("Fqdn")
+
+	// The event data is the row without the bookkeeping columns.
+	event_data := ordereddict.NewDict()
+	for _, key := range row.Keys() {
+		if utils.InString(timelineBookkeepingColumns, key) {
+			continue
+		}
+		value, _ := row.Get(key)
+		event_data.Set(key, value)
+	}
+	message := timelineMessage(event_data)
+
+	result := []*timelineEvent{}
+	for _, key := range event_data.Keys() {
+		if len(self.fields) > 0 && !utils.InString(self.fields, key) {
+			continue
+		}
+
+		value, _ := event_data.Get(key)
+		t, ok := self.getTime(scope, value)
+		if !ok {
+			continue
+		}
+
+		result = append(result, &timelineEvent{
+			Time:      t.UTC(),
+			Desc:      key,
+			Source:    source,
+			ClientId:  client_id,
+			Hostname:  hostname,
+			Message:   message,
+			EventData: event_data,
+		})
+	}
+
+	return result
+}
+
+// Explicitly selected columns may hold any time representation but
+// when guessing we only accept real timestamps so that numbers and
+// strings are not mistaken for times.
+func (self *timelineExporter) getTime(
+	scope vfilter.Scope, value interface{}) (time.Time, bool) {
+	var t time.Time
+
+	if len(self.fields) > 0 {
+		parsed, err := functions.TimeFromAny(scope, value)
+		if err != nil {
+			return t, false
+		}
+		t = parsed
+
+	} else {
+		switch value := value.(type) {
+		case time.Time:
+			t = value
+
+		case *time.Time:
+			t = *value
+
+		case string:
+			parsed, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return t, false
+			}
+			t = parsed
+
+		default:
+			return t, false
+		}
+	}
+
+	// Unset timestamps are not events.
+	if t.IsZero() || t.Unix() <= 0 {
+		return t, false
+	}
+
+	return t, true
+}
+
+func (self *timelineExporter) formatEvent(event *timelineEvent) []byte {
+	if self.format == TIMELINE_TLN {
+		return []byte(fmt.Sprintf("%d|%s|%s||%s\n",
+			event.Time.Unix(),
+			tlnEscape(event.Source),
+			tlnEscape(event.Hostname),
+			tlnEscape(event.Desc+": "+event.Message)))
+	}
+
+	row := ordereddict.NewDict().
+		Set("__container_type__", "event").
+		Set("datetime", event.Time.Format(time.RFC3339Nano)).
+		Set("timestamp", event.Time.UnixNano()/1000).
+		Set("timestamp_desc", event.Desc).
+		Set("data_type", "velociraptor:"+event.Source).
+		Set("source_short", "LOG").
+		Set("source_long", event.Source).
+		Set("hostname", event.Hostname).
+		Set("client_id", event.ClientId).
+		Set("message", event.Message).
+		Set("event_data", event.EventData)
+
+	serialized, err := json.Marshal(row)
+	if err != nil {
+		return nil
+	}
+	return append(serialized, '\n')
+}
+
+// A short single line summary of the row.
+func timelineMessage(row *ordereddict.Dict) string {
+	parts := make([]string, 0, row.Len())
+	for _, key := range row.Keys() {
+		value, _ := row.Get(key)
+		str, ok := value.(string)
+		if !ok {
+			serialized, err := json.Marshal(value)
+			if err != nil {
+				continue
+			}
+			str = string(serialized)
+		}
+		parts = append(parts, key+": "+str)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// TLN fields are separated by pipes and events by lines.
+func tlnEscape(field string) string {
+	return strings.NewReplacer("|", "_", "\n", " ", "\r", " ").
+		Replace(field)
+}
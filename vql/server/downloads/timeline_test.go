@@ -0,0 +1,75 @@
+package downloads
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	"github.com/stretchr/testify/assert"
+	"www.velocidex.com/golang/velociraptor/json"
+	"www.velocidex.com/golang/velociraptor/utils"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+)
+
+func (self *DownloadsTestSuite) TestTimelineEvents() {
+	scope := vql_subsystem.MakeScope()
+	defer scope.Close()
+
+	_, err := newTimelineExporter("xml", nil)
+	assert.Error(self.T(), err)
+
+	exporter, err := newTimelineExporter("", nil)
+	assert.NoError(self.T(), err)
+	assert.Nil(self.T(), exporter)
+
+	mtime := time.Unix(1600000000, 0)
+	row := ordereddict.NewDict().
+		Set("ClientId", "C.1").
+		Set("FlowId", "F.1").
+		Set("Fqdn", "host1").
+		Set("OSPath", "C:\\a|b.exe").
+		Set("Mtime", mtime).
+		Set("Btime", mtime.Add(time.Hour).Format(time.RFC3339)).
+		Set("Atime", time.Time{}).
+		Set("Size", 1600000000)
+
+	// Every timestamp makes an event. Unset times and numbers are
+	// not events.
+	exporter, err = newTimelineExporter(TIMELINE_JSONL, nil)
+	assert.NoError(self.T(), err)
+
+	events := exporter.getEvents(scope, "Custom.Files", row)
+	assert.Equal(self.T(), 2, len(events))
+	assert.Equal(self.T(), "Mtime", events[0].Desc)
+	assert.Equal(self.T(), "Btime", events[1].Desc)
+	assert.Equal(self.T(), mtime.Add(time.Hour).UTC(), events[1].Time)
+	assert.Equal(self.T(), "host1", events[0].Hostname)
+
+	// The bookkeeping columns are not part of the event data.
+	assert.Equal(self.T(), []string{"OSPath", "Mtime", "Btime", "Atime", "Size"},
+		events[0].EventData.Keys())
+
+	serialized := exporter.formatEvent(events[0])
+	parsed := ordereddict.NewDict()
+	assert.NoError(self.T(), json.Unmarshal(serialized, parsed))
+	assert.Equal(self.T(), "2020-09-13T12:26:40Z", utils.GetString(parsed, "datetime"))
+	assert.Equal(self.T(), "Mtime", utils.GetString(parsed, "timestamp_desc"))
+	assert.Equal(self.T(), "velociraptor:Custom.Files",
+		utils.GetString(parsed, "data_type"))
+	assert.Equal(self.T(), "C.1", utils.GetString(parsed, "client_id"))
+
+	// Selected fields may hold any representation of a time.
+	exporter, err = newTimelineExporter(TIMELINE_TLN, []string{"Size"})
+	assert.NoError(self.T(), err)
+
+	events = exporter.getEvents(scope, "Custom.Files", row)
+	assert.Equal(self.T(), 1, len(events))
+	assert.Equal(self.T(), mtime.UTC(), events[0].Time)
+
+	// Pipes and new lines would break the TLN format.
+	line := string(exporter.formatEvent(events[0]))
+	assert.True(self.T(), strings.HasPrefix(line,
+		"1600000000|Custom.Files|host1||Size: OSPath: C:\\a_b.exe; "), line)
+	assert.Equal(self.T(), 1, strings.Count(line, "\n"))
+	assert.Equal(self.T(), 4, strings.Count(line, "|"))
+}
@@ -30,7 +30,9 @@ import (
 	"www.velocidex.com/golang/velociraptor/datastore"
 	"www.velocidex.com/golang/velociraptor/file_store"
 	"www.velocidex.com/golang/velociraptor/file_store/path_specs"
+	"www.velocidex.com/golang/velociraptor/legal_hold"
 	"www.velocidex.com/golang/velociraptor/paths"
+	"www.velocidex.com/golang/velociraptor/utils"
 	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
 	"www.velocidex.com/golang/vfilter"
 	"www.velocidex.com/golang/vfilter/arg_parser"
@@ -74,6 +76,14 @@ func (self *DeleteFileStore) Call(ctx context.Context,
 	file_store_factory := file_store.GetFileStore(config_obj)
 
 	vfs_path := arg.VFSPath.Reduce(ctx)
+
+	// Evidence under legal hold must not be removed.
+	err = legal_hold.CheckPathHold(config_obj, getPathComponents(vfs_path))
+	if err != nil {
+		scope.Log("file_store_delete: %v", err)
+		return vfilter.Null{}
+	}
+
 	switch t := vfs_path.(type) {
 	case *path_specs.DSPathSpec:
 		err = db.DeleteSubject(config_obj, t)
@@ -114,6 +124,18 @@ func (self *DeleteFileStore) Call(ctx context.Context,
 	return vfs_path
 }
 
+func getPathComponents(vfs_path vfilter.Any) []string {
+	switch t := vfs_path.(type) {
+	case interface{ Components() []string }:
+		return t.Components()
+
+	case string:
+		return utils.SplitComponents(strings.TrimPrefix(
+			strings.TrimPrefix(t, "ds:"), "fs:"))
+	}
+	return nil
+}
+
 func (self DeleteFileStore) Info(scope vfilter.Scope, type_map *vfilter.TypeMap) *vfilter.FunctionInfo {
 	return &vfilter.FunctionInfo{
 		Name:    "file_store_delete",
@@ -5,11 +5,13 @@ import (
 
 	"github.com/Velocidex/ordereddict"
 	"www.velocidex.com/golang/velociraptor/acls"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
 	"www.velocidex.com/golang/velociraptor/file_store"
 	"www.velocidex.com/golang/velociraptor/file_store/api"
 	"www.velocidex.com/golang/velociraptor/file_store/path_specs"
 	"www.velocidex.com/golang/velociraptor/paths"
 	"www.velocidex.com/golang/velociraptor/result_sets"
+	"www.velocidex.com/golang/velociraptor/services"
 	"www.velocidex.com/golang/velociraptor/utils"
 	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
 	"www.velocidex.com/golang/vfilter"
@@ -19,6 +21,8 @@ import (
 type UploadsPluginsArgs struct {
 	ClientId string `vfilter:"optional,field=client_id,doc=The client id to extract"`
 	FlowId   string `vfilter:"optional,field=flow_id,doc=A flow ID (client or server artifacts)"`
+	HuntId   string `vfilter:"optional,field=hunt_id,doc=List uploads from all the flows in this hunt"`
+	FileType string `vfilter:"optional,field=file_type,doc=Only show uploads of this type: a MIME type, a MIME prefix (e.g. image/) or a short name (e.g. pe, elf, pdf)"`
 }
 
 type UploadsPlugins struct{}
@@ -56,45 +60,87 @@ func (self UploadsPlugins) Call(
 			return
 		}
 
-		flow_path_manager := paths.NewFlowPathManager(arg.ClientId, arg.FlowId)
-		file_store_factory := file_store.GetFileStore(config_obj)
-		reader, err := result_sets.NewResultSetReader(
-			file_store_factory, flow_path_manager.UploadMetadata())
+		if arg.HuntId == "" {
+			listUploads(ctx, config_obj, scope, arg.ClientId, arg.FlowId,
+				arg.FileType, false, output_chan)
+			return
+		}
+
+		hunt_dispatcher, err := services.GetHuntDispatcher(config_obj)
 		if err != nil {
 			scope.Log("uploads: %v", err)
 			return
 		}
-		defer reader.Close()
-
-		for row := range reader.Rows(ctx) {
-			vfs_path, pres := row.GetString("vfs_path")
-			if !pres {
-				continue
-			}
-
-			// Each row is the full filestore path of the upload.
-			pathspec := path_specs.NewUnsafeFilestorePath(
-				utils.SplitComponents(vfs_path)...).
-				SetType(api.PATH_TYPE_FILESTORE_ANY)
-
-			row.Update("vfs_path", pathspec)
-
-			select {
-			case <-ctx.Done():
-				return
-			case output_chan <- row:
-			}
+
+		for flow_details := range hunt_dispatcher.GetFlows(
+			ctx, config_obj, scope, arg.HuntId, 0) {
+			listUploads(ctx, config_obj, scope,
+				flow_details.Context.ClientId,
+				flow_details.Context.SessionId,
+				arg.FileType, true, output_chan)
 		}
 	}()
 
 	return output_chan
 }
 
+func listUploads(
+	ctx context.Context,
+	config_obj *config_proto.Config,
+	scope vfilter.Scope,
+	client_id, flow_id, file_type string, with_flow bool,
+	output_chan chan vfilter.Row) {
+
+	flow_path_manager := paths.NewFlowPathManager(client_id, flow_id)
+	file_store_factory := file_store.GetFileStore(config_obj)
+	reader, err := result_sets.NewResultSetReader(
+		file_store_factory, flow_path_manager.UploadMetadata())
+	if err != nil {
+		scope.Log("uploads: %v", err)
+		return
+	}
+	defer reader.Close()
+
+	for row := range reader.Rows(ctx) {
+		vfs_path, pres := row.GetString("vfs_path")
+		if !pres {
+			continue
+		}
+
+		// Uploads made before content types were recorded
+		// never match a type filter.
+		content_type, _ := row.GetString("content_type")
+		if !utils.MatchContentType(content_type, file_type) {
+			continue
+		}
+
+		// Each row is the full filestore path of the upload.
+		pathspec := path_specs.NewUnsafeFilestorePath(
+			utils.SplitComponents(vfs_path)...).
+			SetType(api.PATH_TYPE_FILESTORE_ANY)
+
+		row.Update("vfs_path", pathspec)
+
+		// Hunts span many flows so say where each upload
+		// came from.
+		if with_flow {
+			row.Set("ClientId", client_id).
+				Set("FlowId", flow_id)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case output_chan <- row:
+		}
+	}
+}
+
 func (self UploadsPlugins) Info(
 	scope vfilter.Scope, type_map *vfilter.TypeMap) *vfilter.PluginInfo {
 	return &vfilter.PluginInfo{
 		Name:    "uploads",
-		Doc:     "Retrieve information about a flow's uploads.",
+		Doc:     "Retrieve information about a flow's or hunt's uploads.",
 		ArgType: type_map.AddType(scope, &UploadsPluginsArgs{}),
 	}
 }
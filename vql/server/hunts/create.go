@@ -48,9 +48,13 @@ type ScheduleHuntFunctionArg struct {
 	IopsLimit     float64          `vfilter:"optional,field=iops_limit,doc=Set query ops_per_sec value"`
 	MaxRows       uint64           `vfilter:"optional,field=max_rows,doc=Max number of rows to fetch"`
 	MaxBytes      uint64           `vfilter:"optional,field=max_bytes,doc=Max number of bytes to upload"`
+	MaxMemory     uint64           `vfilter:"optional,field=max_memory,doc=Abort the queries when they use more than this many bytes of memory"`
 	Pause         bool             `vfilter:"optional,field=pause,doc=If specified the new hunt will be in the paused state"`
 	IncludeLabels []string         `vfilter:"optional,field=include_labels,doc=If specified only include these labels"`
 	ExcludeLabels []string         `vfilter:"optional,field=exclude_labels,doc=If specified exclude these labels"`
+	SamplePercent float64          `vfilter:"optional,field=sample_percent,doc=Only return this percentage of rows from each client"`
+	SampleRows    uint64           `vfilter:"optional,field=sample_rows,doc=Only return the first N rows from each client"`
+	ApprovalId    string           `vfilter:"optional,field=approval_id,doc=An approval granted by a second user for hunts using dangerous plugins"`
 }
 
 type ScheduleHuntFunction struct{}
@@ -115,6 +119,10 @@ func (self *ScheduleHuntFunction) Call(ctx context.Context,
 		Timeout:        arg.Timeout,
 		MaxRows:        arg.MaxRows,
 		MaxUploadBytes: arg.MaxBytes,
+		MaxMemory:      arg.MaxMemory,
+		SamplePercent:  float32(arg.SamplePercent),
+		SampleRows:     arg.SampleRows,
+		ApprovalId:     arg.ApprovalId,
 	}
 
 	err = tools.AddSpecProtobuf(config_obj, repository, scope,
@@ -6,6 +6,7 @@ import (
 	"github.com/Velocidex/ordereddict"
 	"www.velocidex.com/golang/velociraptor/acls"
 	api_proto "www.velocidex.com/golang/velociraptor/api/proto"
+	"www.velocidex.com/golang/velociraptor/legal_hold"
 	"www.velocidex.com/golang/velociraptor/services"
 	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
 	"www.velocidex.com/golang/vfilter"
@@ -59,6 +60,14 @@ func (self DeleteHuntPlugin) Call(ctx context.Context,
 			scope.Log("hunt_delete: %s", err)
 			return
 		}
+
+		if arg.ReallyDoIt {
+			err = legal_hold.CheckHuntHold(config_obj, arg.HuntId)
+			if err != nil {
+				scope.Log("hunt_delete: %v", err)
+				return
+			}
+		}
 		for flow_details := range hunt_dispatcher.GetFlows(
 			ctx, config_obj, scope, arg.HuntId, 0) {
 
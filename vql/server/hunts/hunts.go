@@ -130,10 +130,11 @@ func (self HuntsPlugin) Info(scope vfilter.Scope, type_map *vfilter.TypeMap) *vf
 }
 
 type HuntResultsPluginArgs struct {
-	Artifact string `vfilter:"optional,field=artifact,doc=The artifact to retrieve"`
-	Source   string `vfilter:"optional,field=source,doc=An optional source within the artifact."`
-	HuntId   string `vfilter:"required,field=hunt_id,doc=The hunt id to read."`
-	Brief    bool   `vfilter:"optional,field=brief,doc=If set we return less columns."`
+	Artifact   string `vfilter:"optional,field=artifact,doc=The artifact to retrieve"`
+	Source     string `vfilter:"optional,field=source,doc=An optional source within the artifact."`
+	HuntId     string `vfilter:"required,field=hunt_id,doc=The hunt id to read."`
+	Brief      bool   `vfilter:"optional,field=brief,doc=If set we return less columns."`
+	ClientInfo bool   `vfilter:"optional,field=client_info,doc=If set add the client's hostname, labels and OS to each row."`
 }
 
 type HuntResultsPlugin struct{}
@@ -266,6 +267,10 @@ func (self HuntResultsPlugin) Call(
 				if api_client.OsInfo != nil {
 					row.Set("Fqdn", api_client.OsInfo.Fqdn)
 				}
+
+				if arg.ClientInfo {
+					addClientInfo(row, api_client)
+				}
 				select {
 				case <-ctx.Done():
 					return
@@ -278,6 +283,26 @@ func (self HuntResultsPlugin) Call(
 	return output_chan
 }
 
+// Join the client's metadata to the row so callers do not need to
+// look up each client id themselves.
+func addClientInfo(row *ordereddict.Dict, api_client *api_proto.ApiClient) {
+	hostname := ""
+	os := ""
+	if api_client.OsInfo != nil {
+		hostname = api_client.OsInfo.Hostname
+		os = api_client.OsInfo.System
+	}
+
+	labels := api_client.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+
+	row.Set("Hostname", hostname).
+		Set("Labels", labels).
+		Set("OS", os)
+}
+
 func (self HuntResultsPlugin) Info(scope vfilter.Scope, type_map *vfilter.TypeMap) *vfilter.PluginInfo {
 	return &vfilter.PluginInfo{
 		Name:    "hunt_results",
@@ -34,6 +34,7 @@ type CollectPluginArgs struct {
 	Report              string      `vfilter:"optional,field=report,doc=A path to write the report on."`
 	Args                vfilter.Any `vfilter:"optional,field=args,doc=Optional parameters."`
 	Password            string      `vfilter:"optional,field=password,doc=An optional password to encrypt the collection zip."`
+	PublicKey           string      `vfilter:"optional,field=public_key,doc=Encrypt the collection zip with a random password which is only stored encrypted with this PEM encoded RSA public key or certificate."`
 	Format              string      `vfilter:"optional,field=format,doc=Output format (csv, jsonl)."`
 	ArtifactDefinitions vfilter.Any `vfilter:"optional,field=artifact_definitions,doc=Optional additional custom artifacts."`
 	Template            string      `vfilter:"optional,field=template,doc=The name of a template artifact (i.e. one which has report of type HTML)."`
@@ -43,6 +44,8 @@ type CollectPluginArgs struct {
 	IopsLimit           float64     `vfilter:"optional,field=iops_limit,doc=Set query iops_limit value"`
 	ProgressTimeout     float64     `vfilter:"optional,field=progress_timeout,doc=If no progress is detected in this many seconds, we terminate the query and output debugging information"`
 	Timeout             float64     `vfilter:"optional,field=timeout,doc=Total amount of time in seconds, this collection will take. Collection is cancelled when timeout is exceeded."`
+
+	TemplateParameters *ordereddict.Dict `vfilter:"optional,field=template_parameters,doc=Override the template's parameters (e.g. Logo or Locale)."`
 }
 
 type CollectPlugin struct{}
@@ -194,6 +197,19 @@ func (self CollectPlugin) Call(
 			return
 		}
 
+		// Track the progress of collections into a container.
+		if container != nil {
+			names := []string{}
+			for _, vql_request := range vql_requests {
+				for _, query := range vql_request.Query {
+					if query.Name != "" {
+						names = append(names, query.Name)
+					}
+				}
+			}
+			CollectorProgress.AddCollection(arg.Output, container, names)
+		}
+
 		// Run each collection separately, one after the other.
 		for _, vql_request := range vql_requests {
 
@@ -253,8 +269,10 @@ func (self CollectPlugin) Call(
 					continue
 				}
 
+				CollectorProgress.Start(query.Name)
 				err = container.StoreArtifact(
 					config_obj, subctx, subscope, query, arg.Format)
+				CollectorProgress.Finish(query.Name, err)
 				if err != nil {
 					subscope.Log("collect: %v", err)
 					return
@@ -279,15 +297,29 @@ func makeContainer(
 	repository services.Repository,
 	arg *CollectPluginArgs) (
 	container *reporting.Container, closer func(), err error) {
+	scope.Log("Setting compression level to %v", arg.Level)
+
 	// Should we encrypt it?
-	if arg.Password != "" {
-		scope.Log("Will password protect container")
-	}
+	switch {
+	case arg.PublicKey != "":
+		if arg.Password != "" {
+			return nil, nil, errors.New(
+				"Only one of password or public_key may be set")
+		}
 
-	scope.Log("Setting compression level to %v", arg.Level)
+		// The password is never known to the collector.
+		scope.Log("Will encrypt container with public key")
+		container, err = reporting.NewEscrowedContainer(
+			config_obj, arg.Output, arg.PublicKey, arg.Level)
 
-	container, err = reporting.NewContainer(
-		config_obj, arg.Output, arg.Password, arg.Level)
+	default:
+		if arg.Password != "" {
+			scope.Log("Will password protect container")
+		}
+
+		container, err = reporting.NewContainer(
+			config_obj, arg.Output, arg.Password, arg.Level)
+	}
 	if err != nil {
 		return nil, nil, err
 	}
@@ -299,9 +331,26 @@ func makeContainer(
 		if container.IsClosed() {
 			return
 		}
+
+		// Record the collection details so the container can
+		// be imported into the server later.
+		hostname, _ := os.Hostname()
+		err := container.WriteMetadata(&reporting.ContainerMetadata{
+			Hostname:  hostname,
+			Timestamp: time.Now().Unix(),
+			Artifacts: arg.Artifacts,
+		})
+		if err != nil {
+			scope.Log("collect: writing metadata: %v", err)
+		}
 		container.Close()
 
-		if arg.Report != "" {
+		// A plain text report would reveal what the encrypted
+		// container holds.
+		if arg.Report != "" && container.IsEncrypted() {
+			scope.Log("Not producing a report for an encrypted container")
+
+		} else if arg.Report != "" {
 			scope.Log("Producing collection report at %v", arg.Report)
 
 			// Open the archive back up again. // TODO: Support password.
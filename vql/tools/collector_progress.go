@@ -0,0 +1,204 @@
+package tools
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	PROGRESS_PENDING = "pending"
+	PROGRESS_RUNNING = "running"
+	PROGRESS_DONE    = "done"
+	PROGRESS_ERROR   = "error"
+)
+
+// Where the collection is written. Usually the container.
+type CollectionStats interface {
+	RowsWritten() int64
+	BytesWritten() int64
+}
+
+type ArtifactProgress struct {
+	Name     string
+	State    string
+	Rows     int64
+	Bytes    int64
+	Errors   []string
+	Started  time.Time
+	Finished time.Time
+
+	// Container counters when this artifact started.
+	start_rows  int64
+	start_bytes int64
+}
+
+func (self ArtifactProgress) Duration(now time.Time) time.Duration {
+	switch self.State {
+	case PROGRESS_PENDING:
+		return 0
+	case PROGRESS_RUNNING:
+		return now.Sub(self.Started)
+	}
+	return self.Finished.Sub(self.Started)
+}
+
+// Tracks the progress of collections into a container so it can be
+// displayed (e.g. by the offline collector's progress display).
+// Artifacts are collected one after the other so the container
+// counters can be attributed to the running artifact.
+type CollectionProgress struct {
+	mu sync.Mutex
+
+	started   time.Time
+	stats     CollectionStats
+	artifacts []*ArtifactProgress
+	current   *ArtifactProgress
+	output    string
+}
+
+// Progress of all collections with an output container in this
+// process.
+var CollectorProgress = &CollectionProgress{}
+
+// Register the artifacts about to be collected into the container.
+func (self *CollectionProgress) AddCollection(
+	output string, stats CollectionStats, names []string) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	if self.started.IsZero() {
+		self.started = time.Now()
+	}
+	self.stats = stats
+	self.output = output
+
+	for _, name := range names {
+		self.artifacts = append(self.artifacts, &ArtifactProgress{
+			Name:  name,
+			State: PROGRESS_PENDING,
+		})
+	}
+}
+
+func (self *CollectionProgress) find(name string) *ArtifactProgress {
+	for _, artifact := range self.artifacts {
+		if artifact.Name == name && artifact.State == PROGRESS_PENDING {
+			return artifact
+		}
+	}
+	return nil
+}
+
+func (self *CollectionProgress) Start(name string) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	artifact := self.find(name)
+	if artifact == nil {
+		return
+	}
+
+	artifact.State = PROGRESS_RUNNING
+	artifact.Started = time.Now()
+	if self.stats != nil {
+		artifact.start_rows = self.stats.RowsWritten()
+		artifact.start_bytes = self.stats.BytesWritten()
+	}
+	self.current = artifact
+}
+
+func (self *CollectionProgress) Finish(name string, err error) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	artifact := self.current
+	if artifact == nil || artifact.Name != name {
+		return
+	}
+
+	self.update(artifact)
+	artifact.Finished = time.Now()
+	artifact.State = PROGRESS_DONE
+	if err != nil {
+		artifact.Errors = append(artifact.Errors, err.Error())
+	}
+	if len(artifact.Errors) > 0 {
+		artifact.State = PROGRESS_ERROR
+	}
+	self.current = nil
+}
+
+// Attribute an error message to the running artifact.
+func (self *CollectionProgress) AddError(message string) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	if self.current != nil {
+		self.current.Errors = append(self.current.Errors, message)
+	}
+}
+
+func (self *CollectionProgress) update(artifact *ArtifactProgress) {
+	if self.stats != nil {
+		artifact.Rows = self.stats.RowsWritten() - artifact.start_rows
+		artifact.Bytes = self.stats.BytesWritten() - artifact.start_bytes
+	}
+}
+
+type ProgressSnapshot struct {
+	Artifacts []ArtifactProgress
+	Output    string
+	Elapsed   time.Duration
+	Bytes     int64
+	Completed int
+
+	// Estimated from the average time taken by completed
+	// artifacts. 0 if it can not be estimated yet.
+	ETA time.Duration
+}
+
+func (self *CollectionProgress) Snapshot() *ProgressSnapshot {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	now := time.Now()
+	result := &ProgressSnapshot{
+		Output: self.output,
+	}
+	if !self.started.IsZero() {
+		result.Elapsed = now.Sub(self.started)
+	}
+	if self.stats != nil {
+		result.Bytes = self.stats.BytesWritten()
+	}
+
+	if self.current != nil {
+		self.update(self.current)
+	}
+
+	var completed_time time.Duration
+	for _, artifact := range self.artifacts {
+		switch artifact.State {
+		case PROGRESS_DONE, PROGRESS_ERROR:
+			result.Completed++
+			completed_time += artifact.Duration(now)
+		}
+		result.Artifacts = append(result.Artifacts, *artifact)
+	}
+
+	remaining := len(self.artifacts) - result.Completed
+	if result.Completed > 0 && remaining > 0 {
+		average := completed_time / time.Duration(result.Completed)
+		result.ETA = average * time.Duration(remaining)
+
+		// The running artifact is already partially done.
+		if self.current != nil {
+			result.ETA -= self.current.Duration(now)
+			if result.ETA < 0 {
+				result.ETA = 0
+			}
+		}
+	}
+
+	return result
+}
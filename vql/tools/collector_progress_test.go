@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testStats struct {
+	rows, bytes int64
+}
+
+func (self *testStats) RowsWritten() int64  { return self.rows }
+func (self *testStats) BytesWritten() int64 { return self.bytes }
+
+func TestCollectionProgress(t *testing.T) {
+	stats := &testStats{}
+	progress := &CollectionProgress{}
+	progress.AddCollection("out.zip", stats, []string{"A", "B", "C"})
+
+	snapshot := progress.Snapshot()
+	require.Equal(t, 3, len(snapshot.Artifacts))
+	assert.Equal(t, PROGRESS_PENDING, snapshot.Artifacts[0].State)
+	assert.Equal(t, 0, snapshot.Completed)
+
+	// Counters are attributed to the running artifact.
+	progress.Start("A")
+	stats.rows, stats.bytes = 10, 1000
+	snapshot = progress.Snapshot()
+	assert.Equal(t, PROGRESS_RUNNING, snapshot.Artifacts[0].State)
+	assert.Equal(t, int64(10), snapshot.Artifacts[0].Rows)
+
+	progress.Finish("A", nil)
+
+	progress.Start("B")
+	progress.AddError("Something failed")
+	stats.rows, stats.bytes = 15, 1500
+	progress.Finish("B", errors.New("Query failed"))
+
+	// Errors are not attributed when nothing is running.
+	progress.AddError("Ignored")
+
+	snapshot = progress.Snapshot()
+	assert.Equal(t, 2, snapshot.Completed)
+	assert.Equal(t, int64(1500), snapshot.Bytes)
+	assert.Equal(t, "out.zip", snapshot.Output)
+
+	assert.Equal(t, PROGRESS_DONE, snapshot.Artifacts[0].State)
+	assert.Equal(t, int64(10), snapshot.Artifacts[0].Rows)
+
+	assert.Equal(t, PROGRESS_ERROR, snapshot.Artifacts[1].State)
+	assert.Equal(t, int64(5), snapshot.Artifacts[1].Rows)
+	assert.Equal(t, int64(500), snapshot.Artifacts[1].Bytes)
+	assert.Equal(t, []string{"Something failed", "Query failed"},
+		snapshot.Artifacts[1].Errors)
+
+	assert.Equal(t, PROGRESS_PENDING, snapshot.Artifacts[2].State)
+}
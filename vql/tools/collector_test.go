@@ -15,6 +15,7 @@ import (
 	"www.velocidex.com/golang/velociraptor/file_store/test_utils"
 	"www.velocidex.com/golang/velociraptor/json"
 	"www.velocidex.com/golang/velociraptor/logging"
+	"www.velocidex.com/golang/velociraptor/reporting"
 	"www.velocidex.com/golang/velociraptor/services"
 	"www.velocidex.com/golang/velociraptor/utils"
 	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
@@ -202,6 +203,58 @@ func (self *TestSuite) TestCollectionWithArtifacts() {
 	goldie.Assert(self.T(), "TestCollectionWithArtifacts", serialized)
 }
 
+// The template's parameters control the report branding and locale.
+func (self *TestSuite) TestCollectionReportTemplateParameters() {
+	output_file, err := ioutil.TempFile(os.TempDir(), "zip")
+	assert.NoError(self.T(), err)
+	output_file.Close()
+	defer os.Remove(output_file.Name())
+
+	report_file, err := ioutil.TempFile(os.TempDir(), "html")
+	assert.NoError(self.T(), err)
+	report_file.Close()
+	defer os.Remove(report_file.Name())
+
+	builder := services.ScopeBuilder{
+		Config:     self.ConfigObj,
+		ACLManager: vql_subsystem.NullACLManager{},
+		Logger:     logging.NewPlainLogger(self.ConfigObj, &logging.FrontendComponent),
+		Env:        ordereddict.NewDict(),
+	}
+
+	manager, err := services.GetRepositoryManager(self.ConfigObj)
+	assert.NoError(self.T(), err)
+
+	scope := manager.BuildScope(builder)
+	defer scope.Close()
+
+	args := ordereddict.NewDict().
+		Set("artifacts", []string{"Custom.TestArtifactDependent"}).
+		Set("artifact_definitions", CustomTestArtifactDependent).
+		Set("output", output_file.Name()).
+		Set("report", report_file.Name()).
+		Set("template_parameters", ordereddict.NewDict().
+			Set("Title", "Case <42>").
+			Set("Logo", "https://www.example.com/logo.png").
+			Set("Footer", "**Confidential**").
+			Set("Locale", "de-DE").
+			Set("Translations", `{"de": {"Artifacts Collected": "Gesammelte Artefakte"}}`))
+
+	for range (CollectPlugin{}).Call(context.Background(), scope, args) {
+	}
+
+	report_data, err := ioutil.ReadFile(report_file.Name())
+	assert.NoError(self.T(), err)
+
+	report := string(report_data)
+	assert.Contains(self.T(), report, `<html lang="de-DE">`)
+	assert.Contains(self.T(), report, "<title>Case &lt;42&gt;</title>")
+	assert.Contains(self.T(), report, `<img src="https://www.example.com/logo.png" class="logo"/>`)
+	assert.Contains(self.T(), report, "<strong>Confidential</strong>")
+	assert.Contains(self.T(), report, "Gesammelte Artefakte")
+	assert.NotContains(self.T(), report, "Artifacts Collected")
+}
+
 func (self *TestSuite) TestCollectionWithTypes() {
 	output_file, err := ioutil.TempFile(os.TempDir(), "zip")
 	assert.NoError(self.T(), err)
@@ -298,6 +351,17 @@ func openZipFile(name string) (*ordereddict.Dict, error) {
 			return nil, err
 		}
 
+		// The hostname and timestamp change between runs.
+		if f.Name == reporting.CONTAINER_METADATA {
+			metadata := &reporting.ContainerMetadata{}
+			err = json.Unmarshal(serialized, metadata)
+			if err != nil {
+				return nil, err
+			}
+			result.Set(f.Name, metadata.Artifacts)
+			continue
+		}
+
 		rows, err := utils.ParseJsonToDicts(serialized)
 		if err != nil {
 			result.Set(f.Name, string(serialized))
@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/Velocidex/ordereddict"
+	"www.velocidex.com/golang/velociraptor/acls"
+	"www.velocidex.com/golang/velociraptor/reporting"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/vfilter"
+	"www.velocidex.com/golang/vfilter/arg_parser"
+)
+
+type CompareContainersArgs struct {
+	First       string `vfilter:"required,field=first,doc=The path to the earlier collection container."`
+	Second      string `vfilter:"required,field=second,doc=The path to the later collection container."`
+	ChangedOnly bool   `vfilter:"optional,field=changed_only,doc=Only emit members which were added, removed or modified."`
+}
+
+type CompareContainersPlugin struct{}
+
+func (self CompareContainersPlugin) Call(
+	ctx context.Context,
+	scope vfilter.Scope,
+	args *ordereddict.Dict) <-chan vfilter.Row {
+	output_chan := make(chan vfilter.Row)
+
+	go func() {
+		defer close(output_chan)
+
+		err := vql_subsystem.CheckAccess(scope, acls.FILESYSTEM_READ)
+		if err != nil {
+			scope.Log("compare_containers: %s", err)
+			return
+		}
+
+		arg := &CompareContainersArgs{}
+		err = arg_parser.ExtractArgsWithContext(ctx, scope, args, arg)
+		if err != nil {
+			scope.Log("compare_containers: %v", err)
+			return
+		}
+
+		comparison, err := reporting.CompareContainers(
+			ctx, arg.First, arg.Second)
+		if err != nil {
+			scope.Log("compare_containers: %v", err)
+			return
+		}
+
+		if !comparison.SameHost {
+			scope.Log("compare_containers: Containers were collected from "+
+				"different hosts (%v and %v)", comparison.First.Hostname,
+				comparison.Second.Hostname)
+		}
+
+		scope.Log("compare_containers: %v added, %v removed, %v modified, "+
+			"%v unchanged", comparison.Added, comparison.Removed,
+			comparison.Modified, comparison.Unchanged)
+
+		for _, member := range comparison.Members {
+			if arg.ChangedOnly && member.Change == reporting.CHANGE_UNCHANGED {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case output_chan <- member:
+			}
+		}
+	}()
+
+	return output_chan
+}
+
+func (self CompareContainersPlugin) Info(scope vfilter.Scope, type_map *vfilter.TypeMap) *vfilter.PluginInfo {
+	return &vfilter.PluginInfo{
+		Name: "compare_containers",
+		Doc: "Compare two collection containers from the same host and " +
+			"report the members and results which changed.",
+		ArgType: type_map.AddType(scope, &CompareContainersArgs{}),
+	}
+}
+
+func init() {
+	vql_subsystem.RegisterPlugin(&CompareContainersPlugin{})
+}
@@ -0,0 +1,177 @@
+package dns
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/Velocidex/ordereddict"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/vfilter"
+	"www.velocidex.com/golang/vfilter/arg_parser"
+	"www.velocidex.com/golang/vfilter/types"
+)
+
+type _DNSTrackerHistoryArgs struct {
+	Name   string `vfilter:"optional,field=name,doc=Only show this domain and its subdomains."`
+	Regex  string `vfilter:"optional,field=regex,doc=Only show names matching this regex."`
+	Answer string `vfilter:"optional,field=answer,doc=Only show names with an answer matching this regex (e.g. an IP address)."`
+}
+
+type _DNSTrackerHistory struct{}
+
+func (self _DNSTrackerHistory) Info(scope vfilter.Scope, type_map *vfilter.TypeMap) *vfilter.PluginInfo {
+	return &vfilter.PluginInfo{
+		Name:    "dns_tracker_history",
+		Doc:     "List the names recently resolved on this host from the DNS tracker.",
+		ArgType: type_map.AddType(scope, &_DNSTrackerHistoryArgs{}),
+	}
+}
+
+func (self _DNSTrackerHistory) Call(
+	ctx context.Context, scope types.Scope,
+	args *ordereddict.Dict) <-chan types.Row {
+
+	output_chan := make(chan types.Row)
+
+	go func() {
+		defer close(output_chan)
+
+		arg := &_DNSTrackerHistoryArgs{}
+		err := arg_parser.ExtractArgsWithContext(ctx, scope, args, arg)
+		if err != nil {
+			scope.Log("dns_tracker_history: %v", err)
+			return
+		}
+
+		tracker := GetGlobalTracker()
+		if tracker == nil {
+			scope.Log("dns_tracker_history: No DNS tracker is installed. " +
+				"Enable a DNS tracking event artifact such as Windows.Events.TrackDNS")
+			return
+		}
+
+		matcher, err := newMatcher(arg)
+		if err != nil {
+			scope.Log("dns_tracker_history: %v", err)
+			return
+		}
+
+		for _, entry := range tracker.Entries() {
+			if !matcher.Match(entry) {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case output_chan <- entry:
+			}
+		}
+	}()
+
+	return output_chan
+}
+
+type matcher struct {
+	name   string
+	regex  *regexp.Regexp
+	answer *regexp.Regexp
+}
+
+func (self *matcher) Match(entry *DNSEntry) bool {
+	if self.name != "" && entry.Name != self.name &&
+		!strings.HasSuffix(entry.Name, "."+self.name) {
+		return false
+	}
+
+	if self.regex != nil && !self.regex.MatchString(entry.Name) {
+		return false
+	}
+
+	if self.answer != nil {
+		for _, a := range entry.Answers {
+			if self.answer.MatchString(a) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+func newMatcher(arg *_DNSTrackerHistoryArgs) (*matcher, error) {
+	result := &matcher{
+		name: strings.TrimSuffix(strings.ToLower(arg.Name), "."),
+	}
+
+	var err error
+	if arg.Regex != "" {
+		result.regex, err = regexp.Compile("(?i)" + arg.Regex)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if arg.Answer != "" {
+		result.answer, err = regexp.Compile("(?i)" + arg.Answer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// Stream new resolutions as they are added to the tracker.
+type _DNSTrackerUpdates struct{}
+
+func (self _DNSTrackerUpdates) Info(scope vfilter.Scope, type_map *vfilter.TypeMap) *vfilter.PluginInfo {
+	return &vfilter.PluginInfo{
+		Name: "dns_tracker_updates",
+		Doc:  "Get the resolutions as they are added to the global DNS tracker.",
+	}
+}
+
+func (self _DNSTrackerUpdates) Call(
+	ctx context.Context, scope types.Scope,
+	args *ordereddict.Dict) <-chan types.Row {
+
+	output_chan := make(chan types.Row)
+
+	go func() {
+		defer close(output_chan)
+
+		tracker := GetGlobalTracker()
+		if tracker == nil {
+			scope.Log("dns_tracker_updates: No DNS tracker is installed.")
+			return
+		}
+
+		update_notifications := tracker.Updates()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case update, ok := <-update_notifications:
+				if !ok {
+					return
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case output_chan <- update:
+				}
+			}
+		}
+	}()
+
+	return output_chan
+}
+
+func init() {
+	vql_subsystem.RegisterPlugin(&_DNSTrackerHistory{})
+	vql_subsystem.RegisterPlugin(&_DNSTrackerUpdates{})
+}
@@ -0,0 +1,289 @@
+/*
+  The DNS tracker keeps a history of recently resolved names on the
+  client.
+
+  It is fed by an event query (e.g. ETW DNS client events) and keeps
+  the most recent resolutions in memory so other artifacts can ask
+  "has this host resolved evil.com recently" without needing a packet
+  capture or a monitoring artifact that uploads every DNS query to the
+  server.
+*/
+
+package dns
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	"www.velocidex.com/golang/velociraptor/utils"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/vfilter"
+	"www.velocidex.com/golang/vfilter/arg_parser"
+	"www.velocidex.com/golang/vfilter/types"
+)
+
+var (
+	mu sync.Mutex
+
+	// A global tracker that is installed with dns_tracker(). It is
+	// nil when no tracker is running.
+	g_tracker *DNSTracker
+	clock     utils.Clock = &utils.RealClock{}
+)
+
+func GetGlobalTracker() *DNSTracker {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return g_tracker
+}
+
+func SetClock(c utils.Clock) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	clock = c
+}
+
+func getClock() utils.Clock {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return clock
+}
+
+// A single resolution as emitted by the update query.
+type DNSUpdate struct {
+	Name    string    `vfilter:"required,field=name,doc=The name that was resolved."`
+	Type    string    `vfilter:"optional,field=type,doc=The type of the query (e.g. A, AAAA)."`
+	Answers []string  `vfilter:"optional,field=answers,doc=The answers received. Multiple answers may be separated by ;"`
+	Time    time.Time `vfilter:"optional,field=time,doc=When the query was made (default now)."`
+}
+
+type DNSEntry struct {
+	Name      string
+	Type      string
+	Answers   []string
+	FirstSeen time.Time
+	LastSeen  time.Time
+	Count     int64
+}
+
+type DNSTracker struct {
+	mu       sync.Mutex
+	entries  map[string]*DNSEntry
+	max_size int
+	max_age  time.Duration
+
+	// Any interested parties will receive notifications of new
+	// resolutions.
+	update_notifications chan *DNSEntry
+}
+
+func (self *DNSTracker) Add(update *DNSUpdate) {
+	name := strings.TrimSuffix(strings.ToLower(
+		strings.TrimSpace(update.Name)), ".")
+	if name == "" {
+		return
+	}
+
+	now := update.Time
+	if now.IsZero() {
+		now = getClock().Now()
+	}
+	now = now.UTC()
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	key := name + "|" + update.Type
+	entry, pres := self.entries[key]
+	if !pres {
+		entry = &DNSEntry{
+			Name:      name,
+			Type:      update.Type,
+			FirstSeen: now,
+		}
+		self.entries[key] = entry
+	}
+
+	entry.LastSeen = now
+	entry.Count++
+	entry.Answers = mergeAnswers(entry.Answers, update.Answers)
+
+	self.expire(now)
+
+	// Do not block at all - we can not wait to update our model.
+	if self.update_notifications != nil {
+		entry_copy := *entry
+		select {
+		case self.update_notifications <- &entry_copy:
+		default:
+		}
+	}
+}
+
+// Remove entries that are too old and the least recently seen
+// entries if the tracker is too large. Called with the lock held.
+func (self *DNSTracker) expire(now time.Time) {
+	if self.max_age > 0 {
+		for k, v := range self.entries {
+			if now.Sub(v.LastSeen) > self.max_age {
+				delete(self.entries, k)
+			}
+		}
+	}
+
+	if self.max_size <= 0 || len(self.entries) <= self.max_size {
+		return
+	}
+
+	keys := make([]string, 0, len(self.entries))
+	for k := range self.entries {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return self.entries[keys[i]].LastSeen.Before(
+			self.entries[keys[j]].LastSeen)
+	})
+
+	for _, k := range keys[:len(keys)-self.max_size] {
+		delete(self.entries, k)
+	}
+}
+
+// Returns a copy of all the entries, most recent first.
+func (self *DNSTracker) Entries() []*DNSEntry {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	self.expire(getClock().Now().UTC())
+
+	result := make([]*DNSEntry, 0, len(self.entries))
+	for _, v := range self.entries {
+		entry_copy := *v
+		result = append(result, &entry_copy)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].LastSeen.After(result[j].LastSeen)
+	})
+
+	return result
+}
+
+func (self *DNSTracker) Updates() chan *DNSEntry {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	if self.update_notifications == nil {
+		self.update_notifications = make(chan *DNSEntry)
+	}
+
+	return self.update_notifications
+}
+
+func (self *DNSTracker) doUpdateQuery(
+	ctx context.Context, scope vfilter.Scope,
+	vql types.StoredQuery) {
+
+	for row := range vql.Eval(ctx, scope) {
+		update := &DNSUpdate{}
+		err := arg_parser.ExtractArgsWithContext(ctx, scope,
+			vfilter.RowToDict(ctx, scope, row), update)
+		if err != nil {
+			scope.Log("dns_tracker: update query error: %v", err)
+			continue
+		}
+		self.Add(update)
+	}
+}
+
+func NewDNSTracker(max_size int, max_age time.Duration) *DNSTracker {
+	return &DNSTracker{
+		entries:  make(map[string]*DNSEntry),
+		max_size: max_size,
+		max_age:  max_age,
+	}
+}
+
+// Answers are often reported as a single string separated by ;
+// (e.g. "type: 5 example.com;1.2.3.4;")
+func mergeAnswers(existing []string, answers []string) []string {
+	for _, answer := range answers {
+		for _, a := range strings.Split(answer, ";") {
+			a = strings.TrimSpace(a)
+			if a != "" && !utils.InString(existing, a) {
+				existing = append(existing, a)
+			}
+		}
+	}
+	return existing
+}
+
+type _InstallDNSTrackerArgs struct {
+	UpdateQuery vfilter.StoredQuery `vfilter:"required,field=update_query,doc=An event query that produces a row for each DNS resolution."`
+	MaxSize     int64               `vfilter:"optional,field=max_size,doc=Maximum number of names to remember (default 10000)."`
+	MaxAge      int64               `vfilter:"optional,field=max_age,doc=Forget names not seen for this many seconds (default 7 days)."`
+}
+
+type _InstallDNSTracker struct{}
+
+func (self _InstallDNSTracker) Call(ctx context.Context,
+	scope vfilter.Scope,
+	args *ordereddict.Dict) vfilter.Any {
+
+	arg := &_InstallDNSTrackerArgs{}
+	err := arg_parser.ExtractArgsWithContext(ctx, scope, args, arg)
+	if err != nil {
+		scope.Log("dns_tracker: %v", err)
+		return false
+	}
+
+	if arg.MaxSize == 0 {
+		arg.MaxSize = 10000
+	}
+
+	if arg.MaxAge == 0 {
+		arg.MaxAge = 7 * 24 * 60 * 60
+	}
+
+	tracker := NewDNSTracker(int(arg.MaxSize),
+		time.Duration(arg.MaxAge)*time.Second)
+
+	go tracker.doUpdateQuery(ctx, scope, arg.UpdateQuery)
+
+	// Register this tracker as a global tracker.
+	mu.Lock()
+	g_tracker = tracker
+	mu.Unlock()
+
+	// When this query is done we remove the tracker.
+	vql_subsystem.GetRootScope(scope).AddDestructor(func() {
+		mu.Lock()
+		if g_tracker == tracker {
+			g_tracker = nil
+		}
+		mu.Unlock()
+
+		scope.Log("Uninstalling DNS tracker.")
+	})
+
+	return true
+}
+
+func (self *_InstallDNSTracker) Info(scope vfilter.Scope, type_map *vfilter.TypeMap) *vfilter.FunctionInfo {
+	return &vfilter.FunctionInfo{
+		Name:    "dns_tracker",
+		Doc:     "Install a global DNS resolution tracker.",
+		ArgType: type_map.AddType(scope, &_InstallDNSTrackerArgs{}),
+	}
+}
+
+func init() {
+	vql_subsystem.RegisterFunction(&_InstallDNSTracker{})
+}
@@ -0,0 +1,79 @@
+package dns
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"www.velocidex.com/golang/velociraptor/utils"
+)
+
+func TestDNSTracker(t *testing.T) {
+	mock_clock := &utils.MockClock{MockNow: time.Unix(1600000000, 0)}
+	SetClock(mock_clock)
+	defer SetClock(&utils.RealClock{})
+
+	tracker := NewDNSTracker(2, time.Hour)
+	tracker.Add(&DNSUpdate{
+		Name:    "www.Evil.com.",
+		Type:    "1",
+		Answers: []string{"1.2.3.4;::ffff:1.2.3.4;"},
+	})
+
+	mock_clock.Sleep(time.Minute)
+	tracker.Add(&DNSUpdate{
+		Name:    "www.evil.com",
+		Type:    "1",
+		Answers: []string{"1.2.3.4", "5.6.7.8"},
+	})
+
+	entries := tracker.Entries()
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "www.evil.com", entries[0].Name)
+	assert.Equal(t, int64(2), entries[0].Count)
+	assert.Equal(t, []string{"1.2.3.4", "::ffff:1.2.3.4", "5.6.7.8"},
+		entries[0].Answers)
+	assert.Equal(t, time.Minute, entries[0].LastSeen.Sub(entries[0].FirstSeen))
+
+	// The least recently seen name is dropped when the tracker is
+	// full.
+	mock_clock.Sleep(time.Minute)
+	tracker.Add(&DNSUpdate{Name: "example.com"})
+	mock_clock.Sleep(time.Minute)
+	tracker.Add(&DNSUpdate{Name: "notevil.com"})
+
+	entries = tracker.Entries()
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "notevil.com", entries[0].Name)
+	assert.Equal(t, "example.com", entries[1].Name)
+
+	// Old names are forgotten.
+	mock_clock.Sleep(2 * time.Hour)
+	assert.Equal(t, 0, len(tracker.Entries()))
+}
+
+func TestDNSTrackerMatcher(t *testing.T) {
+	entry := &DNSEntry{
+		Name:    "www.evil.com",
+		Answers: []string{"1.2.3.4"},
+	}
+
+	for _, c := range []struct {
+		arg     _DNSTrackerHistoryArgs
+		matches bool
+	}{
+		{_DNSTrackerHistoryArgs{}, true},
+		{_DNSTrackerHistoryArgs{Name: "evil.com"}, true},
+		{_DNSTrackerHistoryArgs{Name: "www.evil.com"}, true},
+		{_DNSTrackerHistoryArgs{Name: "notevil.com"}, false},
+		{_DNSTrackerHistoryArgs{Name: "vil.com"}, false},
+		{_DNSTrackerHistoryArgs{Regex: "EVIL"}, true},
+		{_DNSTrackerHistoryArgs{Answer: `^1\.2\.3\.4$`}, true},
+		{_DNSTrackerHistoryArgs{Answer: `5\.6`}, false},
+	} {
+		arg := c.arg
+		m, err := newMatcher(&arg)
+		assert.NoError(t, err)
+		assert.Equal(t, c.matches, m.Match(entry), "%v", c.arg)
+	}
+}
@@ -0,0 +1,286 @@
+/*
+  Capture network traffic on the endpoint.
+
+  We use dumpcap (shipped with Wireshark and available on most
+  platforms) to do the actual capture because it supports BPF
+  filters, writes pcapng and rotates its output files. The capture is
+  written into a ring of files in a temporary directory - as each file
+  is rotated out it is emitted as a row so the artifact can upload it
+  while the capture is still running. This way a long capture is
+  delivered incrementally and a client crash only loses the last
+  file.
+*/
+
+package pcap
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	"www.velocidex.com/golang/velociraptor/acls"
+	"www.velocidex.com/golang/velociraptor/artifacts"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/vfilter"
+	"www.velocidex.com/golang/vfilter/arg_parser"
+)
+
+const (
+	DEFAULT_DURATION    = 60
+	DEFAULT_MAX_SIZE    = 100 * 1024 * 1024
+	DEFAULT_ROTATE_SIZE = 10 * 1024 * 1024
+
+	// Never capture for longer than this.
+	MAX_DURATION = 24 * 60 * 60
+)
+
+type PacketCaptureArgs struct {
+	Interface    string `vfilter:"optional,field=interface,doc=The interface to capture on (default the first interface)."`
+	Filter       string `vfilter:"optional,field=filter,doc=A BPF filter expression (e.g. 'tcp port 443')."`
+	Duration     int64  `vfilter:"optional,field=duration,doc=Stop the capture after this many seconds (default 60)."`
+	MaxSize      int64  `vfilter:"optional,field=max_size,doc=Stop the capture after this many bytes (default 100Mb)."`
+	RotateSize   int64  `vfilter:"optional,field=rotate_size,doc=Start a new file after this many bytes (default 10Mb)."`
+	RotatePeriod int64  `vfilter:"optional,field=rotate_period,doc=Start a new file after this many seconds."`
+	Dumpcap      string `vfilter:"optional,field=dumpcap,doc=Path to the dumpcap binary (default search the path). Requires the EXECVE permission."`
+}
+
+type PacketCapturePlugin struct{}
+
+func (self PacketCapturePlugin) Call(
+	ctx context.Context,
+	scope vfilter.Scope,
+	args *ordereddict.Dict) <-chan vfilter.Row {
+	output_chan := make(chan vfilter.Row)
+
+	go func() {
+		defer close(output_chan)
+
+		err := vql_subsystem.CheckAccess(scope, acls.NETWORK_CAPTURE)
+		if err != nil {
+			scope.Log("packet_capture: %v", err)
+			return
+		}
+
+		// Running dumpcap is an execve so it is subject to the
+		// same configuration. On the client, read only mode sets
+		// PreventExecve.
+		config_obj, ok := artifacts.GetConfig(scope)
+		if ok && config_obj.PreventExecve {
+			scope.Log("packet_capture: Not allowed to execve by configuration.")
+			return
+		}
+
+		server_config_obj, ok := vql_subsystem.GetServerConfig(scope)
+		if ok && acls.IsReadOnlyMode(server_config_obj) {
+			scope.Log("packet_capture: Not allowed to execve in read only mode.")
+			return
+		}
+
+		arg := &PacketCaptureArgs{}
+		err = arg_parser.ExtractArgsWithContext(ctx, scope, args, arg)
+		if err != nil {
+			scope.Log("packet_capture: %v", err)
+			return
+		}
+
+		// Running an arbitrary binary needs the EXECVE permission.
+		if arg.Dumpcap != "" {
+			err = vql_subsystem.CheckAccess(scope, acls.EXECVE)
+			if err != nil {
+				scope.Log("packet_capture: dumpcap: %v", err)
+				return
+			}
+		}
+
+		dir, err := ioutil.TempDir("", "pcap")
+		if err != nil {
+			scope.Log("packet_capture: %v", err)
+			return
+		}
+
+		// The files are removed when the query is done so they
+		// must be uploaded by then.
+		err = scope.AddDestructor(func() {
+			os.RemoveAll(dir)
+		})
+		if err != nil {
+			os.RemoveAll(dir)
+			scope.Log("packet_capture: %v", err)
+			return
+		}
+
+		dumpcap := arg.Dumpcap
+		if dumpcap == "" {
+			dumpcap = "dumpcap"
+		}
+
+		// Kill the capture a bit after the duration in case
+		// dumpcap does not exit by itself.
+		duration := getDuration(arg)
+		sub_ctx, cancel := context.WithTimeout(ctx,
+			time.Duration(duration+10)*time.Second)
+		defer cancel()
+
+		command := exec.CommandContext(sub_ctx, dumpcap,
+			buildArgs(arg, filepath.Join(dir, "capture.pcapng"))...)
+		output := &strings.Builder{}
+		command.Stderr = output
+
+		err = command.Start()
+		if err != nil {
+			scope.Log("packet_capture: %v", err)
+			return
+		}
+
+		done := make(chan error)
+		go func() {
+			done <- command.Wait()
+		}()
+
+		emitted := make(map[string]bool)
+		emit := func(final bool) bool {
+			for _, file := range completedFiles(dir, emitted, final) {
+				emitted[file.Name()] = true
+				select {
+				case <-ctx.Done():
+					return false
+				case output_chan <- ordereddict.NewDict().
+					Set("Sequence", len(emitted)).
+					Set("Path", filepath.Join(dir, file.Name())).
+					Set("Size", file.Size()):
+				}
+			}
+			return true
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case err := <-done:
+				if err != nil {
+					scope.Log("packet_capture: %v: %v", err,
+						strings.TrimSpace(output.String()))
+				}
+				emit(true)
+				return
+
+			case <-time.After(time.Second):
+				if !emit(false) {
+					return
+				}
+			}
+		}
+	}()
+
+	return output_chan
+}
+
+func getDuration(arg *PacketCaptureArgs) int64 {
+	duration := arg.Duration
+	if duration <= 0 {
+		duration = DEFAULT_DURATION
+	}
+	if duration > MAX_DURATION {
+		duration = MAX_DURATION
+	}
+	return duration
+}
+
+// Build the dumpcap command line. Dumpcap writes a ring of files
+// named after the output file with a sequence number and timestamp.
+func buildArgs(arg *PacketCaptureArgs, output string) []string {
+	max_size := arg.MaxSize
+	if max_size <= 0 {
+		max_size = DEFAULT_MAX_SIZE
+	}
+
+	rotate_size := arg.RotateSize
+	if rotate_size <= 0 {
+		rotate_size = DEFAULT_ROTATE_SIZE
+	}
+	if rotate_size > max_size {
+		rotate_size = max_size
+	}
+
+	// Dumpcap can not limit the total size so we limit the number
+	// of files instead.
+	max_files := max_size / rotate_size
+	if max_files < 1 {
+		max_files = 1
+	}
+
+	result := []string{"-q", "-n"}
+	if arg.Interface != "" {
+		result = append(result, "-i", arg.Interface)
+	}
+	if arg.Filter != "" {
+		result = append(result, "-f", arg.Filter)
+	}
+
+	// Sizes are specified in kB
+	result = append(result,
+		"-b", fmt.Sprintf("filesize:%d", (rotate_size+1023)/1024))
+	if arg.RotatePeriod > 0 {
+		result = append(result,
+			"-b", fmt.Sprintf("duration:%d", arg.RotatePeriod))
+	}
+
+	result = append(result,
+		"-a", fmt.Sprintf("duration:%d", getDuration(arg)),
+		"-a", fmt.Sprintf("files:%d", max_files),
+		"-w", output)
+
+	return result
+}
+
+// Files are complete once dumpcap moved on to the next file. When
+// the capture is finished all the files are complete.
+func completedFiles(dir string, emitted map[string]bool,
+	final bool) []os.FileInfo {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	// Dumpcap names files with an increasing sequence number so
+	// they sort in order.
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name() < files[j].Name()
+	})
+
+	// The last file is still being written.
+	if !final && len(files) > 0 {
+		files = files[:len(files)-1]
+	}
+
+	result := []os.FileInfo{}
+	for _, file := range files {
+		if !emitted[file.Name()] {
+			result = append(result, file)
+		}
+	}
+	return result
+}
+
+func (self PacketCapturePlugin) Info(
+	scope vfilter.Scope, type_map *vfilter.TypeMap) *vfilter.PluginInfo {
+	return &vfilter.PluginInfo{
+		Name: "packet_capture",
+		Doc: "Capture network traffic into rotated pcapng files using dumpcap. " +
+			"Requires the NETWORK_CAPTURE permission.",
+		ArgType: type_map.AddType(scope, &PacketCaptureArgs{}),
+	}
+}
+
+func init() {
+	vql_subsystem.RegisterPlugin(&PacketCapturePlugin{})
+}
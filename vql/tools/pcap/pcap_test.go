@@ -0,0 +1,101 @@
+package pcap
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Velocidex/ordereddict"
+	"github.com/stretchr/testify/assert"
+	acl_proto "www.velocidex.com/golang/velociraptor/acls/proto"
+	config_proto "www.velocidex.com/golang/velociraptor/config/proto"
+	"www.velocidex.com/golang/velociraptor/constants"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+)
+
+func TestBuildArgs(t *testing.T) {
+	assert.Equal(t, []string{
+		"-q", "-n", "-i", "eth0", "-f", "tcp port 443",
+		"-b", "filesize:1024", "-b", "duration:30",
+		"-a", "duration:120", "-a", "files:5",
+		"-w", "out.pcapng"},
+		buildArgs(&PacketCaptureArgs{
+			Interface:    "eth0",
+			Filter:       "tcp port 443",
+			Duration:     120,
+			MaxSize:      5 * 1024 * 1024,
+			RotateSize:   1024 * 1024,
+			RotatePeriod: 30,
+		}, "out.pcapng"))
+
+	// Defaults are applied and the duration is capped.
+	assert.Equal(t, []string{
+		"-q", "-n", "-b", "filesize:10240",
+		"-a", "duration:86400", "-a", "files:10",
+		"-w", "out.pcapng"},
+		buildArgs(&PacketCaptureArgs{
+			Duration: 1000000,
+		}, "out.pcapng"))
+}
+
+func TestCompletedFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pcap_test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{
+		"capture_00002_20220101120100.pcapng",
+		"capture_00001_20220101120000.pcapng",
+	} {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte("x"), 0600)
+		assert.NoError(t, err)
+	}
+
+	emitted := make(map[string]bool)
+
+	// The last file is still being written to.
+	files := completedFiles(dir, emitted, false)
+	assert.Equal(t, 1, len(files))
+	assert.Equal(t, "capture_00001_20220101120000.pcapng", files[0].Name())
+	emitted[files[0].Name()] = true
+
+	assert.Equal(t, 0, len(completedFiles(dir, emitted, false)))
+
+	// When the capture is done all the files are complete.
+	files = completedFiles(dir, emitted, true)
+	assert.Equal(t, 1, len(files))
+	assert.Equal(t, "capture_00002_20220101120100.pcapng", files[0].Name())
+}
+
+func TestExecveRestrictions(t *testing.T) {
+	run := func(client_config *config_proto.ClientConfig,
+		args *ordereddict.Dict) string {
+		log_buffer := &strings.Builder{}
+		scope := vql_subsystem.MakeScope().AppendVars(ordereddict.NewDict().
+			Set(constants.SCOPE_CONFIG, client_config).
+			Set(vql_subsystem.ACL_MANAGER_VAR, &vql_subsystem.ServerACLManager{
+				Token: &acl_proto.ApiClientACL{NetworkCapture: true},
+			}))
+		defer scope.Close()
+		scope.SetLogger(log.New(log_buffer, "", 0))
+
+		for range (PacketCapturePlugin{}).Call(
+			context.Background(), scope, args) {
+			t.Fatalf("No rows expected")
+		}
+		return log_buffer.String()
+	}
+
+	// dumpcap is not run when execve is prevented.
+	assert.Contains(t, run(&config_proto.ClientConfig{PreventExecve: true},
+		ordereddict.NewDict()), "Not allowed to execve")
+
+	// Running another binary needs EXECVE.
+	assert.Contains(t, run(&config_proto.ClientConfig{},
+		ordereddict.NewDict().Set("dumpcap", "/bin/false")),
+		"Permission denied")
+}
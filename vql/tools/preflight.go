@@ -0,0 +1,308 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Velocidex/ordereddict"
+	"github.com/dustin/go-humanize"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/process"
+	"www.velocidex.com/golang/velociraptor/acls"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/vfilter"
+	"www.velocidex.com/golang/vfilter/arg_parser"
+)
+
+const (
+	PREFLIGHT_ERROR   = "error"
+	PREFLIGHT_WARNING = "warning"
+
+	// Warn if there is less than this free when the output size is
+	// not known.
+	PREFLIGHT_MIN_FREE_SPACE = 100 * 1024 * 1024
+
+	// Security products quarantine files shortly after they are
+	// written so wait a bit before reading the test file back.
+	preflight_av_delay = time.Second
+)
+
+// Process names of common security products which are known to slow
+// down or block collections.
+var preflightSecurityProducts = map[string]string{
+	"msmpeng.exe":                  "Microsoft Defender",
+	"mssense.exe":                  "Microsoft Defender for Endpoint",
+	"csfalconservice.exe":          "CrowdStrike Falcon",
+	"sentinelagent.exe":            "SentinelOne",
+	"cb.exe":                       "VMware Carbon Black",
+	"repmgr.exe":                   "VMware Carbon Black Cloud",
+	"cylancesvc.exe":               "Cylance",
+	"ccsvchst.exe":                 "Symantec Endpoint Protection",
+	"mcshield.exe":                 "McAfee",
+	"savservice.exe":               "Sophos",
+	"ekrn.exe":                     "ESET",
+	"avp.exe":                      "Kaspersky",
+	"xagt.exe":                     "Trellix (FireEye) HX",
+	"falcon-sensor":                "CrowdStrike Falcon",
+	"sentinelone-agent":            "SentinelOne",
+	"cbagentd":                     "VMware Carbon Black",
+	"com.crowdstrike.falcon.agent": "CrowdStrike Falcon",
+}
+
+type PreflightPluginArgs struct {
+	Output        string `vfilter:"required,field=output,doc=The path the collection will be written to."`
+	EstimatedSize uint64 `vfilter:"optional,field=estimated_size,doc=The estimated size of the collection in bytes."`
+	RequireAdmin  bool   `vfilter:"optional,field=require_admin,doc=Check that we are running with administrator privileges."`
+}
+
+type PreflightPlugin struct{}
+
+func (self PreflightPlugin) Call(
+	ctx context.Context,
+	scope vfilter.Scope,
+	args *ordereddict.Dict) <-chan vfilter.Row {
+	output_chan := make(chan vfilter.Row)
+
+	go func() {
+		defer close(output_chan)
+
+		// We write a test file into the destination.
+		err := vql_subsystem.CheckAccess(scope, acls.FILESYSTEM_WRITE)
+		if err != nil {
+			scope.Log("collector_preflight: %s", err)
+			return
+		}
+
+		arg := &PreflightPluginArgs{}
+		err = arg_parser.ExtractArgsWithContext(ctx, scope, args, arg)
+		if err != nil {
+			scope.Log("collector_preflight: %v", err)
+			return
+		}
+
+		// The output may be a directory or a file path (or prefix)
+		// within a directory.
+		directory := arg.Output
+		stat, err := os.Stat(directory)
+		if err != nil || !stat.IsDir() {
+			directory = filepath.Dir(directory)
+		}
+		directory, _ = filepath.Abs(directory)
+
+		for _, result := range []*preflightResult{
+			checkAdminPrivileges(arg.RequireAdmin),
+			checkWriteAccess(ctx, directory),
+			checkFreeSpace(directory, arg.EstimatedSize),
+			checkSecurityProducts(ctx),
+		} {
+			if result == nil {
+				continue
+			}
+
+			if !result.Passed {
+				scope.Log("collector_preflight: %v check failed: %v",
+					result.Check, result.Message)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case output_chan <- result.toDict():
+			}
+		}
+	}()
+
+	return output_chan
+}
+
+type preflightResult struct {
+	Check    string
+	Passed   bool
+	Severity string
+	Message  string
+}
+
+func (self *preflightResult) toDict() *ordereddict.Dict {
+	return ordereddict.NewDict().
+		Set("Check", self.Check).
+		Set("Passed", self.Passed).
+		Set("Severity", self.Severity).
+		Set("Message", self.Message)
+}
+
+func checkAdminPrivileges(require_admin bool) *preflightResult {
+	if !require_admin {
+		return nil
+	}
+
+	if vql_subsystem.IsAdmin() {
+		return &preflightResult{
+			Check:   "Admin",
+			Passed:  true,
+			Message: "Running with administrator privileges",
+		}
+	}
+
+	return &preflightResult{
+		Check:    "Admin",
+		Severity: PREFLIGHT_ERROR,
+		Message: "The collector is not running with administrator " +
+			"privileges. On Windows right click the collector and select " +
+			"'Run as administrator', otherwise run it with sudo.",
+	}
+}
+
+// Write a test file and read it back after a short delay. If it
+// disappeared or can not be read a security product probably
+// quarantined it.
+func checkWriteAccess(ctx context.Context, directory string) *preflightResult {
+	fd, err := ioutil.TempFile(directory, "preflight")
+	if err != nil {
+		return &preflightResult{
+			Check:    "WriteAccess",
+			Severity: PREFLIGHT_ERROR,
+			Message: fmt.Sprintf("Unable to write to %v: %v. Run the "+
+				"collector from a writable directory or choose a different "+
+				"output directory.", directory, err),
+		}
+	}
+	filename := fd.Name()
+	defer os.Remove(filename)
+
+	data := make([]byte, 1024)
+	_, _ = rand.Read(data)
+	_, err = fd.Write(data)
+	fd.Close()
+	if err != nil {
+		return &preflightResult{
+			Check:    "WriteAccess",
+			Severity: PREFLIGHT_ERROR,
+			Message: fmt.Sprintf("Unable to write to %v: %v. Run the "+
+				"collector from a writable directory or choose a different "+
+				"output directory.", directory, err),
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-time.After(preflight_av_delay):
+	}
+
+	read_back, err := ioutil.ReadFile(filename)
+	if err != nil || !bytes.Equal(read_back, data) {
+		return &preflightResult{
+			Check:    "WriteAccess",
+			Severity: PREFLIGHT_ERROR,
+			Message: fmt.Sprintf("A test file written to %v was removed "+
+				"or blocked. Antivirus software may be interfering; add an "+
+				"exclusion for this directory and the collector binary.",
+				directory),
+		}
+	}
+
+	return &preflightResult{
+		Check:   "WriteAccess",
+		Passed:  true,
+		Message: fmt.Sprintf("Able to write to %v", directory),
+	}
+}
+
+func checkFreeSpace(directory string, estimated_size uint64) *preflightResult {
+	usage, err := disk.Usage(directory)
+	if err != nil {
+		return &preflightResult{
+			Check:    "FreeSpace",
+			Severity: PREFLIGHT_WARNING,
+			Message: fmt.Sprintf("Unable to determine free space on %v: %v",
+				directory, err),
+		}
+	}
+
+	if estimated_size > 0 && usage.Free < estimated_size {
+		return &preflightResult{
+			Check:    "FreeSpace",
+			Severity: PREFLIGHT_ERROR,
+			Message: fmt.Sprintf("Only %v free on %v but the collection is "+
+				"estimated to need %v. Free some space or write the "+
+				"collection to a different drive.",
+				humanize.Bytes(usage.Free), directory,
+				humanize.Bytes(estimated_size)),
+		}
+	}
+
+	if estimated_size == 0 && usage.Free < PREFLIGHT_MIN_FREE_SPACE {
+		return &preflightResult{
+			Check:    "FreeSpace",
+			Severity: PREFLIGHT_WARNING,
+			Message: fmt.Sprintf("Only %v free on %v. The collection may "+
+				"run out of space.", humanize.Bytes(usage.Free), directory),
+		}
+	}
+
+	return &preflightResult{
+		Check:  "FreeSpace",
+		Passed: true,
+		Message: fmt.Sprintf("%v free on %v",
+			humanize.Bytes(usage.Free), directory),
+	}
+}
+
+func checkSecurityProducts(ctx context.Context) *preflightResult {
+	processes, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil
+	}
+
+	found := make(map[string]bool)
+	var products []string
+	for _, proc := range processes {
+		name, err := proc.NameWithContext(ctx)
+		if err != nil {
+			continue
+		}
+
+		product, pres := preflightSecurityProducts[strings.ToLower(name)]
+		if pres && !found[product] {
+			found[product] = true
+			products = append(products, product)
+		}
+	}
+
+	if len(products) == 0 {
+		return &preflightResult{
+			Check:   "SecurityProducts",
+			Passed:  true,
+			Message: "No known security products detected",
+		}
+	}
+
+	// This is only a warning since most collections work fine.
+	return &preflightResult{
+		Check:    "SecurityProducts",
+		Severity: PREFLIGHT_WARNING,
+		Message: fmt.Sprintf("Detected %v. If the collection is slow or "+
+			"files are missing add an exclusion for the collector binary "+
+			"and output directory.", strings.Join(products, ", ")),
+	}
+}
+
+func (self PreflightPlugin) Info(scope vfilter.Scope, type_map *vfilter.TypeMap) *vfilter.PluginInfo {
+	return &vfilter.PluginInfo{
+		Name: "collector_preflight",
+		Doc: "Check the environment is suitable for an offline " +
+			"collection before starting it.",
+		ArgType: type_map.AddType(scope, &PreflightPluginArgs{}),
+	}
+}
+
+func init() {
+	vql_subsystem.RegisterPlugin(&PreflightPlugin{})
+}
@@ -0,0 +1,41 @@
+package tools
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreflightChecks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "preflight")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	result := checkWriteAccess(context.Background(), dir)
+	assert.True(t, result.Passed, result.Message)
+
+	// The test file is removed again.
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(files))
+
+	result = checkWriteAccess(context.Background(),
+		filepath.Join(dir, "does_not_exist"))
+	assert.False(t, result.Passed)
+	assert.Equal(t, PREFLIGHT_ERROR, result.Severity)
+
+	result = checkFreeSpace(dir, 1024)
+	assert.True(t, result.Passed, result.Message)
+
+	// Nobody has an exabyte free.
+	result = checkFreeSpace(dir, 1<<60)
+	assert.False(t, result.Passed)
+	assert.Equal(t, PREFLIGHT_ERROR, result.Severity)
+
+	assert.Nil(t, checkAdminPrivileges(false))
+}
@@ -67,8 +67,10 @@ func (self *RekeyFunction) Call(ctx context.Context,
 		return vfilter.Null{}
 	}
 
-	// Update the write back.
+	// Update the write back. Rekeying always gives the client a
+	// new id, even if it was derived from the hardware.
 	writeback.PrivateKey = string(pem)
+	writeback.ClientId = ""
 	err = config.UpdateWriteback(config_obj, writeback)
 	if err != nil {
 		scope.Log("rekey: %v", err)
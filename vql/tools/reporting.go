@@ -116,6 +116,7 @@ func produceReport(
 		return err
 	}
 
+	template_engine.SetTemplateParameters(arg.TemplateParameters)
 	template_engine.SetEnv("main", main)
 	template_engine.SetEnv("parts", parts)
 
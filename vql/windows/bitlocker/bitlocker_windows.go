@@ -0,0 +1,359 @@
+// +build windows
+
+package bitlocker
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/Velocidex/ordereddict"
+	ole "github.com/go-ole/go-ole"
+	"github.com/go-ole/go-ole/oleutil"
+	"www.velocidex.com/golang/velociraptor/acls"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/velociraptor/vql/windows/wmi"
+	"www.velocidex.com/golang/vfilter"
+)
+
+const (
+	NAMESPACE = "ROOT\\CIMV2\\Security\\MicrosoftVolumeEncryption"
+
+	// KeyProtectorType for a numerical (recovery) password.
+	KEY_PROTECTOR_NUMERICAL_PASSWORD = 3
+
+	// S_FALSE is returned by CoInitializeEx if it was already called
+	// on this thread.
+	S_FALSE = 0x00000001
+)
+
+var (
+	lock sync.Mutex
+
+	protectionStatus = map[int64]string{
+		0: "Off",
+		1: "On",
+		2: "Unknown",
+	}
+
+	conversionStatus = map[int64]string{
+		0: "FullyDecrypted",
+		1: "FullyEncrypted",
+		2: "EncryptionInProgress",
+		3: "DecryptionInProgress",
+		4: "EncryptionPaused",
+		5: "DecryptionPaused",
+	}
+
+	encryptionMethod = map[int64]string{
+		0: "None",
+		1: "AES_128_WITH_DIFFUSER",
+		2: "AES_256_WITH_DIFFUSER",
+		3: "AES_128",
+		4: "AES_256",
+		5: "HARDWARE_ENCRYPTION",
+		6: "XTS_AES_128",
+		7: "XTS_AES_256",
+	}
+
+	volumeType = map[int64]string{
+		0: "OperatingSystem",
+		1: "FixedData",
+		2: "RemovableData",
+	}
+)
+
+func lookup(row *ordereddict.Dict, field string, names map[int64]string) string {
+	value, pres := row.Get(field)
+	if !pres {
+		return ""
+	}
+
+	var number int64
+	switch t := value.(type) {
+	case int32:
+		number = int64(t)
+	case uint32:
+		number = int64(t)
+	case int64:
+		number = t
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+
+	name, pres := names[number]
+	if !pres {
+		return fmt.Sprintf("Unknown (%d)", number)
+	}
+	return name
+}
+
+func getStatus() ([]vfilter.Row, error) {
+	volumes, err := wmi.Query(
+		"SELECT * FROM Win32_EncryptableVolume", NAMESPACE)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]vfilter.Row, 0, len(volumes))
+	for _, row := range volumes {
+		device_id, _ := row.GetString("DeviceID")
+		drive_letter, _ := row.GetString("DriveLetter")
+		persistent_id, _ := row.GetString("PersistentVolumeID")
+		initialized, _ := row.GetBool("IsVolumeInitializedForProtection")
+
+		result = append(result, ordereddict.NewDict().
+			Set("DriveLetter", drive_letter).
+			Set("DeviceID", device_id).
+			Set("PersistentVolumeID", persistent_id).
+			Set("VolumeType", lookup(row, "VolumeType", volumeType)).
+			Set("ProtectionStatus",
+				lookup(row, "ProtectionStatus", protectionStatus)).
+			Set("ConversionStatus",
+				lookup(row, "ConversionStatus", conversionStatus)).
+			Set("EncryptionMethod",
+				lookup(row, "EncryptionMethod", encryptionMethod)).
+			Set("InitializedForProtection", initialized))
+	}
+
+	return result, nil
+}
+
+// Call a WMI method on the object and return the out parameters.
+func execMethod(item *ole.IDispatch, method string,
+	args *ordereddict.Dict) (*ole.IDispatch, error) {
+
+	methods_raw, err := oleutil.GetProperty(item, "Methods_")
+	if err != nil {
+		return nil, err
+	}
+	methods := methods_raw.ToIDispatch()
+	defer methods.Release()
+
+	method_raw, err := oleutil.CallMethod(methods, "Item", method)
+	if err != nil {
+		return nil, err
+	}
+	method_obj := method_raw.ToIDispatch()
+	defer method_obj.Release()
+
+	in_class_raw, err := oleutil.GetProperty(method_obj, "InParameters")
+	if err != nil {
+		return nil, err
+	}
+	in_class := in_class_raw.ToIDispatch()
+	defer in_class.Release()
+
+	in_params_raw, err := oleutil.CallMethod(in_class, "SpawnInstance_")
+	if err != nil {
+		return nil, err
+	}
+	in_params := in_params_raw.ToIDispatch()
+	defer in_params.Release()
+
+	for _, k := range args.Keys() {
+		v, _ := args.Get(k)
+		_, err = oleutil.PutProperty(in_params, k, v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out_raw, err := oleutil.CallMethod(item, "ExecMethod_", method, in_params)
+	if err != nil {
+		return nil, err
+	}
+	out := out_raw.ToIDispatch()
+
+	return_value, err := oleutil.GetProperty(out, "ReturnValue")
+	if err == nil {
+		defer func() {
+			_ = return_value.Clear()
+		}()
+
+		code := fmt.Sprintf("%v", return_value.Value())
+		if code != "0" {
+			out.Release()
+			return nil, fmt.Errorf("%v failed with %v", method, code)
+		}
+	}
+
+	return out, nil
+}
+
+func getRecoveryPasswords(volume *ole.IDispatch) ([]vfilter.Row, error) {
+	var result []vfilter.Row
+
+	drive_letter := ""
+	drive_letter_raw, err := oleutil.GetProperty(volume, "DriveLetter")
+	if err == nil {
+		drive_letter, _ = drive_letter_raw.Value().(string)
+		_ = drive_letter_raw.Clear()
+	}
+
+	device_id := ""
+	device_id_raw, err := oleutil.GetProperty(volume, "DeviceID")
+	if err == nil {
+		device_id, _ = device_id_raw.Value().(string)
+		_ = device_id_raw.Clear()
+	}
+
+	protectors, err := execMethod(volume, "GetKeyProtectors",
+		ordereddict.NewDict().
+			Set("KeyProtectorType", int32(KEY_PROTECTOR_NUMERICAL_PASSWORD)))
+	if err != nil {
+		return nil, err
+	}
+	defer protectors.Release()
+
+	ids_raw, err := oleutil.GetProperty(protectors, "VolumeKeyProtectorID")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = ids_raw.Clear()
+	}()
+
+	// Volumes without protectors return a NULL here.
+	if ids_raw.VT&ole.VT_ARRAY == 0 {
+		return nil, nil
+	}
+
+	for _, id := range ids_raw.ToArray().ToStringArray() {
+		password, err := execMethod(volume, "GetKeyProtectorNumericalPassword",
+			ordereddict.NewDict().Set("VolumeKeyProtectorID", id))
+		if err != nil {
+			return nil, err
+		}
+
+		numerical_password := ""
+		numerical_password_raw, err := oleutil.GetProperty(
+			password, "NumericalPassword")
+		if err == nil {
+			numerical_password, _ = numerical_password_raw.Value().(string)
+			_ = numerical_password_raw.Clear()
+		}
+		password.Release()
+
+		result = append(result, ordereddict.NewDict().
+			Set("DriveLetter", drive_letter).
+			Set("DeviceID", device_id).
+			Set("KeyProtectorID", id).
+			Set("RecoveryPassword", numerical_password))
+	}
+
+	return result, nil
+}
+
+func getRecoveryKeys(scope vfilter.Scope) ([]vfilter.Row, error) {
+	lock.Lock()
+	defer lock.Unlock()
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	err := ole.CoInitializeEx(0, ole.COINIT_MULTITHREADED)
+	if err != nil {
+		oleCode := err.(*ole.OleError).Code()
+		if oleCode != ole.S_OK && oleCode != S_FALSE {
+			return nil, err
+		}
+	}
+	defer ole.CoUninitialize()
+
+	unknown, err := oleutil.CreateObject("WbemScripting.SWbemLocator")
+	if err != nil {
+		return nil, err
+	} else if unknown == nil {
+		return nil, wmi.ErrNilCreateObject
+	}
+	defer unknown.Release()
+
+	locator, err := unknown.QueryInterface(ole.IID_IDispatch)
+	if err != nil {
+		return nil, err
+	}
+	defer locator.Release()
+
+	service_raw, err := oleutil.CallMethod(locator, "ConnectServer", nil, NAMESPACE)
+	if err != nil {
+		return nil, err
+	}
+	service := service_raw.ToIDispatch()
+	defer service.Release()
+
+	volumes_raw, err := oleutil.CallMethod(service, "ExecQuery",
+		"SELECT * FROM Win32_EncryptableVolume")
+	if err != nil {
+		return nil, err
+	}
+	volumes := volumes_raw.ToIDispatch()
+	defer volumes.Release()
+
+	var result []vfilter.Row
+	err = oleutil.ForEach(volumes, func(v *ole.VARIANT) error {
+		volume := v.ToIDispatch()
+		defer volume.Release()
+
+		rows, err := getRecoveryPasswords(volume)
+		if err != nil {
+			scope.Log("bitlocker_recovery_keys: %v", err)
+			return nil
+		}
+		result = append(result, rows...)
+		return nil
+	})
+
+	return result, err
+}
+
+func init() {
+	vql_subsystem.RegisterPlugin(
+		&vfilter.GenericListPlugin{
+			PluginName: "bitlocker_status",
+			Function: func(
+				ctx context.Context,
+				scope vfilter.Scope,
+				args *ordereddict.Dict) []vfilter.Row {
+
+				err := vql_subsystem.CheckAccess(scope, acls.MACHINE_STATE)
+				if err != nil {
+					scope.Log("bitlocker_status: %s", err)
+					return nil
+				}
+
+				result, err := getStatus()
+				if err != nil {
+					scope.Log("bitlocker_status: %v", err)
+				}
+				return result
+			},
+			Doc: "Report the BitLocker encryption state of each volume.",
+		})
+
+	vql_subsystem.RegisterPlugin(
+		&vfilter.GenericListPlugin{
+			PluginName: "bitlocker_recovery_keys",
+			Function: func(
+				ctx context.Context,
+				scope vfilter.Scope,
+				args *ordereddict.Dict) []vfilter.Row {
+
+				// Recovery passwords unlock the disk so they are
+				// protected by their own permission.
+				err := vql_subsystem.CheckAccess(scope, acls.READ_SECRETS)
+				if err != nil {
+					scope.Log("bitlocker_recovery_keys: %s", err)
+					return nil
+				}
+
+				result, err := getRecoveryKeys(scope)
+				if err != nil {
+					scope.Log("bitlocker_recovery_keys: %v", err)
+				}
+				return result
+			},
+			Doc: "Retrieve the BitLocker recovery passwords of each volume. " +
+				"Requires the READ_SECRETS permission.",
+		})
+}
@@ -0,0 +1,4 @@
+package bitlocker
+
+// Plugins to report the BitLocker state of volumes and retrieve the
+// recovery passwords escrowed on the endpoint.
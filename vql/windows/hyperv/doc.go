@@ -0,0 +1,3 @@
+package hyperv
+
+// Plugins to enumerate the Hyper-V virtual machines on a host.
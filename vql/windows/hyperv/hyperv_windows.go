@@ -0,0 +1,195 @@
+// +build windows
+
+package hyperv
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Velocidex/ordereddict"
+	"www.velocidex.com/golang/velociraptor/accessors/vss"
+	"www.velocidex.com/golang/velociraptor/acls"
+	vql_subsystem "www.velocidex.com/golang/velociraptor/vql"
+	"www.velocidex.com/golang/velociraptor/vql/windows/wmi"
+	"www.velocidex.com/golang/vfilter"
+)
+
+const (
+	NAMESPACE = "ROOT\\virtualization\\v2"
+
+	// Msvm_StorageAllocationSettingData ResourceType for virtual
+	// hard disks.
+	RESOURCE_TYPE_LOGICAL_DISK = 31
+)
+
+var (
+	// Msvm_ComputerSystem EnabledState
+	enabledState = map[int64]string{
+		0:     "Unknown",
+		2:     "Running",
+		3:     "Off",
+		4:     "ShuttingDown",
+		6:     "Saved",
+		9:     "Paused",
+		10:    "Starting",
+		32768: "Paused",
+		32769: "Saved",
+		32770: "Starting",
+		32773: "Saving",
+		32774: "Stopping",
+		32776: "Pausing",
+		32777: "Resuming",
+	}
+)
+
+func getInt(row *ordereddict.Dict, field string) int64 {
+	value, _ := row.Get(field)
+	switch t := value.(type) {
+	case int32:
+		return int64(t)
+	case uint32:
+		return int64(t)
+	case uint16:
+		return int64(t)
+	case int64:
+		return t
+	case uint64:
+		return int64(t)
+	}
+	return 0
+}
+
+func getStrings(row *ordereddict.Dict, field string) []string {
+	var result []string
+	value, _ := row.Get(field)
+	items, _ := value.([]interface{})
+	for _, item := range items {
+		str, ok := item.(string)
+		if ok {
+			result = append(result, str)
+		}
+	}
+	return result
+}
+
+// Settings and resources are linked to their VM by an InstanceID of
+// the form Microsoft:<VM GUID> or Microsoft:<VM GUID>\<resource>
+func getVMGuid(instance_id string) string {
+	instance_id = strings.TrimPrefix(instance_id, "Microsoft:")
+	return strings.ToUpper(strings.SplitN(instance_id, "\\", 2)[0])
+}
+
+func getVMs() ([]vfilter.Row, error) {
+	systems, err := wmi.Query(
+		"SELECT Name, ElementName, EnabledState, HealthState, "+
+			"InstallDate, OnTimeInMilliseconds, TimeOfLastStateChange, "+
+			"ProcessID FROM Msvm_ComputerSystem "+
+			"WHERE Caption = 'Virtual Machine'", NAMESPACE)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make(map[string]*ordereddict.Dict)
+	rows, err := wmi.Query(
+		"SELECT InstanceID, ConfigurationDataRoot, ConfigurationFile, "+
+			"VirtualSystemSubType, Version, Notes "+
+			"FROM Msvm_VirtualSystemSettingData "+
+			"WHERE VirtualSystemType = 'Microsoft:Hyper-V:System:Realized'",
+		NAMESPACE)
+	if err == nil {
+		for _, row := range rows {
+			instance_id, _ := row.GetString("InstanceID")
+			settings[getVMGuid(instance_id)] = row
+		}
+	}
+
+	disks := make(map[string][]string)
+	rows, err = wmi.Query(fmt.Sprintf(
+		"SELECT InstanceID, HostResource "+
+			"FROM Msvm_StorageAllocationSettingData "+
+			"WHERE ResourceType = %d", RESOURCE_TYPE_LOGICAL_DISK), NAMESPACE)
+	if err == nil {
+		for _, row := range rows {
+			instance_id, _ := row.GetString("InstanceID")
+			guid := getVMGuid(instance_id)
+			disks[guid] = append(disks[guid], getStrings(row, "HostResource")...)
+		}
+	}
+
+	result := make([]vfilter.Row, 0, len(systems))
+	for _, row := range systems {
+		guid, _ := row.GetString("Name")
+		name, _ := row.GetString("ElementName")
+		install_date, _ := row.GetString("InstallDate")
+		last_state_change, _ := row.GetString("TimeOfLastStateChange")
+
+		created, _ := vss.ParseWMIDateTime(install_date)
+		state_changed, _ := vss.ParseWMIDateTime(last_state_change)
+
+		state, pres := enabledState[getInt(row, "EnabledState")]
+		if !pres {
+			state = fmt.Sprintf("Unknown (%d)", getInt(row, "EnabledState"))
+		}
+
+		vm := ordereddict.NewDict().
+			Set("Name", name).
+			Set("GUID", guid).
+			Set("State", state).
+			Set("ProcessID", getInt(row, "ProcessID")).
+			Set("UptimeSeconds", getInt(row, "OnTimeInMilliseconds")/1000).
+			Set("Created", created).
+			Set("LastStateChange", state_changed)
+
+		setting, pres := settings[strings.ToUpper(guid)]
+		if pres {
+			sub_type, _ := setting.GetString("VirtualSystemSubType")
+			version, _ := setting.GetString("Version")
+			config_root, _ := setting.GetString("ConfigurationDataRoot")
+			config_file, _ := setting.GetString("ConfigurationFile")
+			notes := getStrings(setting, "Notes")
+
+			generation := 1
+			if strings.HasSuffix(sub_type, "SubType:2") {
+				generation = 2
+			}
+
+			vm.Set("Generation", generation).
+				Set("Version", version).
+				Set("ConfigurationDataRoot", config_root).
+				Set("ConfigurationFile", config_file).
+				Set("Notes", strings.Join(notes, "\n"))
+		}
+
+		vm.Set("Disks", disks[strings.ToUpper(guid)])
+		result = append(result, vm)
+	}
+
+	return result, nil
+}
+
+func init() {
+	vql_subsystem.RegisterPlugin(
+		&vfilter.GenericListPlugin{
+			PluginName: "hyperv_vms",
+			Function: func(
+				ctx context.Context,
+				scope vfilter.Scope,
+				args *ordereddict.Dict) []vfilter.Row {
+
+				err := vql_subsystem.CheckAccess(scope, acls.MACHINE_STATE)
+				if err != nil {
+					scope.Log("hyperv_vms: %s", err)
+					return nil
+				}
+
+				result, err := getVMs()
+				if err != nil {
+					scope.Log("hyperv_vms: %v", err)
+				}
+				return result
+			},
+			Doc: "List the Hyper-V virtual machines on the host with " +
+				"their state, configuration and virtual disks.",
+		})
+}
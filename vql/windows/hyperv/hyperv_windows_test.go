@@ -0,0 +1,47 @@
+// +build windows
+
+package hyperv
+
+import (
+	"testing"
+
+	"github.com/Velocidex/ordereddict"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetVMGuid(t *testing.T) {
+	for _, test := range []struct {
+		instance_id, guid string
+	}{
+		{"Microsoft:5c4b3b2a-1111-2222-3333-444455556666",
+			"5C4B3B2A-1111-2222-3333-444455556666"},
+		{`Microsoft:5C4B3B2A-1111-2222-3333-444455556666\83F8638B-8DCA-4152-9EDA-2CA8B33039B4\0\0\D`,
+			"5C4B3B2A-1111-2222-3333-444455556666"},
+		{"5c4b3b2a-1111-2222-3333-444455556666",
+			"5C4B3B2A-1111-2222-3333-444455556666"},
+	} {
+		assert.Equal(t, test.guid, getVMGuid(test.instance_id))
+	}
+}
+
+func TestWMIFields(t *testing.T) {
+	row := ordereddict.NewDict().
+		Set("EnabledState", uint16(2)).
+		Set("ProcessID", uint32(1234)).
+		Set("OnTimeInMilliseconds", uint64(5000)).
+		Set("Name", "VM").
+		Set("HostResource", []interface{}{`C:\vm\disk.vhdx`, 1, `C:\vm\data.vhdx`})
+
+	assert.Equal(t, int64(2), getInt(row, "EnabledState"))
+	assert.Equal(t, int64(1234), getInt(row, "ProcessID"))
+	assert.Equal(t, int64(5000), getInt(row, "OnTimeInMilliseconds"))
+
+	// Missing fields and other types are 0.
+	assert.Equal(t, int64(0), getInt(row, "Name"))
+	assert.Equal(t, int64(0), getInt(row, "Missing"))
+
+	// Only the strings are returned.
+	assert.Equal(t, []string{`C:\vm\disk.vhdx`, `C:\vm\data.vhdx`},
+		getStrings(row, "HostResource"))
+	assert.Nil(t, getStrings(row, "Name"))
+}
@@ -3,6 +3,8 @@ package plugins
 import (
 	_ "www.velocidex.com/golang/velociraptor/accessors"
 	_ "www.velocidex.com/golang/velociraptor/accessors/collector"
+	_ "www.velocidex.com/golang/velociraptor/accessors/cloud"
+	_ "www.velocidex.com/golang/velociraptor/accessors/container"
 	_ "www.velocidex.com/golang/velociraptor/accessors/data"
 	_ "www.velocidex.com/golang/velociraptor/accessors/file"
 	_ "www.velocidex.com/golang/velociraptor/accessors/file_store"
@@ -14,5 +16,9 @@ import (
 	_ "www.velocidex.com/golang/velociraptor/accessors/raw_registry"
 	_ "www.velocidex.com/golang/velociraptor/accessors/registry"
 	_ "www.velocidex.com/golang/velociraptor/accessors/sparse"
+	_ "www.velocidex.com/golang/velociraptor/accessors/ssh"
+	_ "www.velocidex.com/golang/velociraptor/accessors/vss"
+	_ "www.velocidex.com/golang/velociraptor/accessors/winrm"
+	_ "www.velocidex.com/golang/velociraptor/accessors/wsl"
 	_ "www.velocidex.com/golang/velociraptor/accessors/zip"
 )
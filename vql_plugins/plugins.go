@@ -33,9 +33,13 @@ import (
 	_ "www.velocidex.com/golang/velociraptor/vql/parsers/csv"
 	_ "www.velocidex.com/golang/velociraptor/vql/parsers/ese"
 	_ "www.velocidex.com/golang/velociraptor/vql/parsers/event_logs"
+	_ "www.velocidex.com/golang/velociraptor/vql/parsers/executables"
+	_ "www.velocidex.com/golang/velociraptor/vql/parsers/journald"
 	_ "www.velocidex.com/golang/velociraptor/vql/parsers/syslog"
 	_ "www.velocidex.com/golang/velociraptor/vql/parsers/usn"
 	_ "www.velocidex.com/golang/velociraptor/vql/protocols"
 	_ "www.velocidex.com/golang/velociraptor/vql/tools"
+	_ "www.velocidex.com/golang/velociraptor/vql/tools/dns"
+	_ "www.velocidex.com/golang/velociraptor/vql/tools/pcap"
 	_ "www.velocidex.com/golang/velociraptor/vql/tools/process"
 )
@@ -19,8 +19,10 @@ package plugins
 
 import (
 	_ "www.velocidex.com/golang/velociraptor/vql/windows"
+	_ "www.velocidex.com/golang/velociraptor/vql/windows/bitlocker"
 	_ "www.velocidex.com/golang/velociraptor/vql/windows/etw"
 	_ "www.velocidex.com/golang/velociraptor/vql/windows/filesystems"
+	_ "www.velocidex.com/golang/velociraptor/vql/windows/hyperv"
 	_ "www.velocidex.com/golang/velociraptor/vql/windows/process"
 	_ "www.velocidex.com/golang/velociraptor/vql/windows/registry"
 	_ "www.velocidex.com/golang/velociraptor/vql/windows/wmi"